@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+
+	"github.com/Azure/draft/pkg/addons"
+	"github.com/Azure/draft/pkg/appconfig"
+	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/consts"
+	dryrunpkg "github.com/Azure/draft/pkg/dryrun"
+	"github.com/Azure/draft/pkg/filematches"
+	"github.com/Azure/draft/pkg/prompts"
+	"github.com/Azure/draft/pkg/reporeader"
+	"github.com/Azure/draft/pkg/reporeader/readers"
+	"github.com/Azure/draft/pkg/templatewriter"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+const (
+	appConfigMapFile = "app-config.yaml"
+	appSecretFile    = "app-secrets.yaml"
+)
+
+type appConfigCmd struct {
+	dest      string
+	appName   string
+	namespace string
+	// groups are the key groups to import, prompted for one by one when empty
+	groups         []string
+	templateWriter templatewriter.TemplateWriter
+}
+
+func newAppConfigCmd() *cobra.Command {
+	ac := &appConfigCmd{}
+
+	cmd := &cobra.Command{
+		Use:   "app-config [flags]",
+		Short: "Generates a ConfigMap and Secret from the application's config files",
+		Long: `This command reads the keys of the application's config files (Spring Boot application.properties and
+application.yaml, ASP.NET Core appsettings.json and .env files), asks which key groups to import and writes them to a
+ConfigMap, with the keys that look like passwords, tokens or connection strings split out into a Secret whose values
+are left empty. The deployment reads both through envFrom, using the environment variable names each framework maps
+to its config keys.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ac.run(cmd.OutOrStdout())
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&ac.dest, "destination", "d", currentDirDefaultFlagValue, "specify the path to the project directory")
+	f.StringVarP(&ac.appName, "app", "a", emptyDefaultFlagValue, "specify the application name the ConfigMap and Secret are named after")
+	f.StringVar(&ac.namespace, "namespace", emptyDefaultFlagValue, "specify the namespace of the ConfigMap and Secret, left to the deployment tool when not set")
+	f.StringArrayVar(&ac.groups, "group", []string{}, "import this key group without prompting, repeat for several (ex: --group spring.datasource)")
+
+	ac.templateWriter = &writers.LocalFSWriter{}
+
+	return cmd
+}
+
+func (ac *appConfigCmd) run(out io.Writer) error {
+	dest, err := checkDestination(ac.dest)
+	if err != nil {
+		return err
+	}
+	ac.dest = dest
+
+	deployType, err := filematches.FindDraftDeploymentFiles(ac.dest)
+	if err != nil {
+		return err
+	}
+	deploymentDir, ok := consts.DeploymentFilePaths[deployType]
+	if !ok {
+		return fmt.Errorf("app config is not supported for the %s deployment type", deployType)
+	}
+
+	keys, err := appconfig.Discover(&readers.LocalFSReader{Root: ac.dest})
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		log.Info("no application config files with keys were found")
+		return nil
+	}
+
+	selected, err := ac.selectKeys(appconfig.GroupKeys(keys))
+	if err != nil {
+		return err
+	}
+	if len(selected) == 0 {
+		log.Info("no key groups were selected, nothing to generate")
+		return nil
+	}
+
+	if ac.appName == "" {
+		variable := config.BuilderVar{Name: "APPNAME", Description: "the application name the ConfigMap and Secret are named after"}
+		if ac.appName, err = prompts.RunDefaultableStringPrompt(variable, strings.ToLower(filepath.Base(ac.dest)), nil, nil, nil); err != nil {
+			return err
+		}
+	}
+	configMap, secret, err := appconfig.Manifests(ac.appName, ac.namespace, selected)
+	if err != nil {
+		return err
+	}
+
+	var dryRunRecorder *dryrunpkg.DryRunRecorder
+	// the deployment is edited in place, only the ConfigMap and Secret are confirmed before being overwritten
+	deploymentWriter, templateWriter := ac.templateWriter, ac.templateWriter
+	if dryRun {
+		dryRunRecorder = dryrunpkg.NewDryRunRecorder()
+		deploymentWriter, templateWriter = dryRunRecorder, dryRunRecorder
+	} else {
+		deploymentWriter = withUncommittedChangesCheck(deploymentWriter, ac.dest)
+		templateWriter = withOverwritePolicy(deploymentWriter)
+	}
+
+	var envFrom []addons.EnvFromSource
+	outputDir := filepath.Join(ac.dest, deploymentDir)
+	if configMap != nil {
+		if err := templateWriter.WriteFile(filepath.Join(outputDir, appConfigMapFile), configMap); err != nil {
+			return err
+		}
+		envFrom = append(envFrom, addons.EnvFromSource{ConfigMapRef: appconfig.ConfigMapName(ac.appName)})
+	}
+	if secret != nil {
+		if err := templateWriter.WriteFile(filepath.Join(outputDir, appSecretFile), secret); err != nil {
+			return err
+		}
+		envFrom = append(envFrom, addons.EnvFromSource{SecretRef: appconfig.SecretName(ac.appName)})
+	}
+	addonConfig := &addons.AddonConfig{DeploymentEnvFrom: envFrom}
+	if err := addonConfig.AddDeploymentEnvFrom(ac.dest, nil, deploymentWriter); err != nil {
+		return err
+	}
+
+	writeAppConfigSummary(out, selected, ac.appName)
+	if secret != nil {
+		log.Infof("--> set the values of the %s Secret outside source control before deploying", appconfig.SecretName(ac.appName))
+	}
+	if deployType == "kustomize" {
+		log.Infof("--> add %s and %s to the resources of %s/kustomization.yaml", appConfigMapFile, appSecretFile, deploymentDir)
+	}
+
+	if dryRun {
+		dryRunText, err := json.MarshalIndent(dryRunRecorder.DryRunInfo, "", TWO_SPACES)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(dryRunText))
+		if dryRunFile != "" {
+			log.Printf("writing dry run info to file %s", dryRunFile)
+			return os.WriteFile(dryRunFile, dryRunText, 0644)
+		}
+	}
+	return nil
+}
+
+// selectKeys returns the keys of the groups passed with --group, or asks whether to import each group
+func (ac *appConfigCmd) selectKeys(groups []appconfig.Group) ([]reporeader.ConfigKey, error) {
+	var selected []reporeader.ConfigKey
+	if len(ac.groups) > 0 {
+		unknown := make(map[string]bool)
+		for _, name := range ac.groups {
+			unknown[name] = true
+		}
+		for _, group := range groups {
+			if slices.Contains(ac.groups, group.Name) {
+				selected = append(selected, group.Keys...)
+				delete(unknown, group.Name)
+			}
+		}
+		if len(unknown) > 0 {
+			names := maps.Keys(unknown)
+			sort.Strings(names)
+			return nil, fmt.Errorf("unknown key groups: %s", strings.Join(names, ", "))
+		}
+		return selected, nil
+	}
+
+	for _, group := range groups {
+		selection := &promptui.Select{
+			Label: fmt.Sprintf("Import %s from %s (%s)?", group.Name, group.Source, strings.Join(group.KeyNames(), ", ")),
+			Items: []string{"yes", "no"},
+		}
+		_, response, err := prompts.RunSelect(selection)
+		if err != nil {
+			return nil, err
+		}
+		if response == "yes" {
+			selected = append(selected, group.Keys...)
+		}
+	}
+	return selected, nil
+}
+
+func writeAppConfigSummary(out io.Writer, keys []reporeader.ConfigKey, appName string) {
+	for _, key := range keys {
+		target := appconfig.ConfigMapName(appName)
+		if appconfig.IsSecret(key.Name) {
+			target = appconfig.SecretName(appName)
+		}
+		fmt.Fprintf(out, "%-40s -> %s %s\n", key.Name, target, key.EnvName)
+	}
+}
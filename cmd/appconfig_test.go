@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunAppConfig(t *testing.T) {
+	dest := t.TempDir()
+	deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myapp
+spec:
+  template:
+    spec:
+      containers:
+        - name: myapp
+          image: myapp:latest
+`
+	assert.Nil(t, os.MkdirAll(filepath.Join(dest, "manifests"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dest, "manifests", "deployment.yaml"), []byte(deployment), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dest, "application.properties"),
+		[]byte("server.port=8080\nspring.datasource.url=jdbc:postgresql://db/app\nspring.datasource.password=changeme\nlogging.level.root=INFO\n"), 0644))
+
+	oldSkip := skipDestinationCheck
+	skipDestinationCheck = true
+	defer func() { skipDestinationCheck = oldSkip }()
+
+	ac := newAppConfigCmd()
+	assert.Nil(t, ac.Flags().Set("destination", dest))
+	assert.Nil(t, ac.Flags().Set("app", "myapp"))
+	assert.Nil(t, ac.Flags().Set("group", "spring.datasource"))
+	assert.Nil(t, ac.Flags().Set("group", "server"))
+	var out bytes.Buffer
+	ac.SetOut(&out)
+	assert.Nil(t, ac.RunE(ac, nil))
+
+	configMap, err := os.ReadFile(filepath.Join(dest, "manifests", appConfigMapFile))
+	assert.Nil(t, err)
+	assert.Contains(t, string(configMap), "  SERVER_PORT: \"8080\"\n")
+	assert.Contains(t, string(configMap), "  SPRING_DATASOURCE_URL: jdbc:postgresql://db/app\n")
+	assert.NotContains(t, string(configMap), "LOGGING")
+
+	secret, err := os.ReadFile(filepath.Join(dest, "manifests", appSecretFile))
+	assert.Nil(t, err)
+	assert.Contains(t, string(secret), "  SPRING_DATASOURCE_PASSWORD: \"\"\n")
+	assert.NotContains(t, string(secret), "changeme")
+
+	updated, err := os.ReadFile(filepath.Join(dest, "manifests", "deployment.yaml"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(updated), `          envFrom:
+            - configMapRef:
+                name: myapp-config
+            - secretRef:
+                name: myapp-secrets
+`)
+	assert.Contains(t, out.String(), "spring.datasource.password")
+
+	ac = newAppConfigCmd()
+	assert.Nil(t, ac.Flags().Set("destination", dest))
+	assert.Nil(t, ac.Flags().Set("app", "myapp"))
+	assert.Nil(t, ac.Flags().Set("group", "missing"))
+	assert.ErrorContains(t, ac.RunE(ac, nil), "unknown key groups: missing")
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/apply"
+	"github.com/Azure/draft/pkg/prompts"
+)
+
+// applyDeploymentFiles and currentKubeContext are replaced in tests
+var (
+	applyDeploymentFiles = apply.Apply
+	currentKubeContext   = apply.CurrentContext
+)
+
+// applyDeployment deploys the generated deployment files to the cluster of the current kubeconfig context after
+// confirming it, and waits for the rollout, so a first deployment doesn't need a pipeline
+func (cc *createCmd) applyDeployment() error {
+	deployType := strings.ToLower(cc.savedConfig.DeployType)
+	if deployType == "" {
+		deployType = strings.ToLower(cc.deployType)
+	}
+	if deployType == "" {
+		return errors.New("--apply: the deployment type of the existing deployment files isn't known, pass it with --deploy-type")
+	}
+
+	kubeContext, err := currentKubeContext()
+	if err != nil {
+		return fmt.Errorf("--apply: %w", err)
+	}
+	// --apply is the confirmation in non-interactive mode
+	if !prompts.NonInteractive() {
+		selection := &promptui.Select{
+			Label: fmt.Sprintf("Deploy the %s files to the cluster of context %s? The image they reference must already be pushed", deployType, kubeContext),
+			Items: []string{"no", "yes"},
+		}
+		_, selectResponse, err := prompts.RunSelect(selection)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(selectResponse, "yes") {
+			log.Info("--> Not deploying, the files are left for a pipeline to deploy")
+			return nil
+		}
+	}
+
+	log.Infof("--- Deploying to %s ---", kubeContext)
+	resources, err := applyDeploymentFiles(context.Background(), apply.Options{
+		DeployType: deployType,
+		Dest:       cc.dest,
+		Namespace:  cc.deploymentNamespace(),
+		Timeout:    cc.applyTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("deploying to %s: %w", kubeContext, err)
+	}
+	log.Infof("--> Deployed %d resources to %s", len(resources), kubeContext)
+	return nil
+}
+
+// deploymentNamespace returns the NAMESPACE the deployment files were generated with, empty when they weren't
+func (cc *createCmd) deploymentNamespace() string {
+	for _, variable := range cc.savedConfig.DeployVariables {
+		if variable.Name == "NAMESPACE" {
+			return variable.Value
+		}
+	}
+	return ""
+}
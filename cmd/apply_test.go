@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/apply"
+	"github.com/Azure/draft/pkg/prompts"
+)
+
+func TestApplyDeployment(t *testing.T) {
+	prompts.SetNonInteractive(true)
+	defer prompts.SetNonInteractive(false)
+	currentKubeContext = func() (string, error) { return "dev", nil }
+	var applied apply.Options
+	applyDeploymentFiles = func(ctx context.Context, opts apply.Options) ([]apply.Resource, error) {
+		applied = opts
+		return []apply.Resource{{Kind: "Deployment", Name: "app"}}, nil
+	}
+	defer func() {
+		currentKubeContext = apply.CurrentContext
+		applyDeploymentFiles = apply.Apply
+	}()
+
+	cc := &createCmd{dest: "app", deployType: "Helm", applyTimeout: apply.DefaultTimeout}
+	assert.Nil(t, cc.applyDeployment())
+	assert.Equal(t, apply.Options{DeployType: "helm", Dest: "app", Timeout: apply.DefaultTimeout}, applied)
+
+	cc.savedConfig = CreateConfig{DeployType: "kustomize", DeployVariables: []UserInputs{{Name: "NAMESPACE", Value: "web"}}}
+	assert.Nil(t, cc.applyDeployment())
+	assert.Equal(t, "kustomize", applied.DeployType)
+	assert.Equal(t, "web", applied.Namespace)
+
+	assert.ErrorContains(t, (&createCmd{}).applyDeployment(), "pass it with --deploy-type")
+
+	currentKubeContext = func() (string, error) { return "", errors.New("no current context is set in the kubeconfig") }
+	assert.ErrorContains(t, cc.applyDeployment(), "--apply: no current context")
+}
@@ -5,10 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"golang.org/x/exp/maps"
-	"gopkg.in/yaml.v3"
 
 	"github.com/Azure/draft/pkg/reporeader"
 	"github.com/Azure/draft/pkg/reporeader/readers"
@@ -16,16 +19,23 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/Azure/draft/pkg/appconfig"
+	"github.com/Azure/draft/pkg/apply"
 	"github.com/Azure/draft/pkg/config"
 	"github.com/Azure/draft/pkg/deployments"
+	"github.com/Azure/draft/pkg/draft"
 	dryrunpkg "github.com/Azure/draft/pkg/dryrun"
 	"github.com/Azure/draft/pkg/filematches"
+	"github.com/Azure/draft/pkg/imagetag"
 	"github.com/Azure/draft/pkg/languages"
+	"github.com/Azure/draft/pkg/languages/defaults"
 	"github.com/Azure/draft/pkg/linguist"
+	"github.com/Azure/draft/pkg/mirror"
+	"github.com/Azure/draft/pkg/overwrite"
 	"github.com/Azure/draft/pkg/prompts"
+	"github.com/Azure/draft/pkg/secrets"
 	"github.com/Azure/draft/pkg/templatewriter"
 	"github.com/Azure/draft/pkg/templatewriter/writers"
-	"github.com/Azure/draft/template"
 )
 
 // ErrNoLanguageDetected is raised when `draft create` does not detect source
@@ -34,6 +44,12 @@ var ErrNoLanguageDetected = errors.New("no supported languages were detected")
 var flagVariablesMap = make(map[string]string)
 
 const LANGUAGE_VARIABLE = "LANGUAGE"
+
+// DRAFT_VERSION_VARIABLE is set to the running draft version for the draft.sh/template-version annotation
+const DRAFT_VERSION_VARIABLE = draft.DraftVersionVariable
+
+// significantLanguagePercent is the share of a project above which a detected language is offered as a pack choice
+const significantLanguagePercent = 25.0
 const TWO_SPACES = "  "
 
 // Flag defaults
@@ -49,16 +65,86 @@ type createCmd struct {
 	dockerfileOnly    bool
 	deploymentOnly    bool
 	skipFileDetection bool
+	fullDetection     bool
+	inspectCluster    bool
 	flagVariables     []string
+	// environments are the comma separated environments of the kustomize overlays, set as ENVIRONMENTS
+	environments string
+	// autoscaling scales the deployment with a HorizontalPodAutoscaler, prompting for its bounds and cpu target
+	autoscaling bool
+	// sidecars and initContainers declare the additional containers of the pod, set as SIDECARS and INITCONTAINERS
+	sidecars       []string
+	initContainers []string
+	// nonInteractive replaces every prompt with flag, config or default values, failing when a value is missing
+	nonInteractive bool
+	// templateVersions are the template versions pinned with --template-version, keyed by artifact
+	templateVersionFlags []string
+	templateVersions     map[string]string
+	// templateDir is a directory of custom language and deployment packs, laid out like draft's template directory, or
+	// the OCI reference of one
+	templateDir string
+	// packs are OCI references of packs to pull, laid out like templateDir
+	packs        []string
+	refreshPacks bool
+	// templateSourceDirs are templateDir and the directories of the pulled packs
+	templateSourceDirs []string
 
 	createConfigPath string
 	createConfig     *CreateConfig
+	// noSavedConfig skips loading and saving the answers of the previous run in savedCreateConfigPath
+	noSavedConfig bool
+	// savedConfig collects the language, deployment type and variables of this run for savedCreateConfigPath
+	savedConfig CreateConfig
+	// merge merges the template changes into existing files instead of asking whether to overwrite them
+	merge bool
+	// patch layers the generated Deployment over existing manifests with kustomize patches instead of asking whether
+	// to overwrite them
+	patch bool
+	// previousGeneration is the generation manifest of the previous run, nil when there is none
+	previousGeneration *dryrunpkg.DryRunInfo
+	// generation records the variables, template versions and files of this run for generationManifestPath
+	generation *dryrunpkg.DryRunRecorder
+	// mergeWriter merges into existing files while its Base is set to the files as previousGeneration generated them
+	mergeWriter *writers.MergeWriter
+	mergeBase   map[string][]byte
 
 	supportedLangs *languages.Languages
+	// dockerfileInputs are the variables the Dockerfile was generated with, used to keep the deployment in sync
+	dockerfileInputs map[string]string
+	// clusterDefaults are the variable defaults found by --inspect-cluster
+	clusterDefaults map[string]string
+	// imageTagStrategy is the imagetag strategy IMAGETAG defaults to the tag of the commit of dest by
+	imageTagStrategy string
+	// imageMirror pulls the images of the generated files through the registry mirror, nil when there is none
+	imageMirror *mirror.Mirror
+	// secretVariables are the names of the secret variables of the language and deployment configs
+	secretVariables []string
 
 	templateWriter           templatewriter.TemplateWriter
 	templateVariableRecorder config.TemplateVariableRecorder
 	repoReader               reporeader.RepoReader
+	// detection is what was read from the repo while detecting its language, nil when the language was given
+	detection *repoDetection
+
+	// githubRepo is the OWNER/REPO the generated files are committed to through the GitHub API instead of written to
+	// dest, on githubBranch created from githubBase
+	githubRepo   string
+	githubBranch string
+	githubBase   string
+	// githubFiles collects the generated files to commit to githubRepo
+	githubFiles *writers.FileMapWriter
+	// source is the GitHub repository read through sourceReader to detect the language and the variable defaults,
+	// instead of dest
+	source       string
+	sourceReader *readers.GitHubReader
+	// apply deploys the generated files to the cluster of the current kubeconfig context, waiting up to applyTimeout
+	// for their rollout
+	apply        bool
+	applyTimeout time.Duration
+	// staged holds back the generated files until the summary of the answers and files is confirmed, or assumeYes is
+	// set
+	staged    *writers.StagedWriter
+	assumeYes bool
 }
 
 func newCreateCmd() *cobra.Command {
@@ -69,24 +155,67 @@ func newCreateCmd() *cobra.Command {
 		Short: "Add minimum required files to the directory",
 		Long:  "This command will add the minimum required files to the local directory for your Kubernetes deployment.",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if cc.apply && (cc.githubRepo != "" || cc.dockerfileOnly) {
+				return errors.New("--apply deploys the deployment files written to the destination, it can't be used with --github-repo or --dockerfile-only")
+			}
+			if cc.source != "" {
+				if err := cc.useSource(); err != nil {
+					return err
+				}
+			}
+			if cc.githubRepo != "" {
+				cleanup, err := cc.useGitHubRepo()
+				if err != nil {
+					return err
+				}
+				defer cleanup()
+			}
 			if err := cc.initConfig(); err != nil {
 				return err
 			}
+			if cc.githubRepo != "" {
+				if err := cc.validateGitHubRepoLanguage(); err != nil {
+					return err
+				}
+			}
 			return cc.run()
 		},
 	}
 
 	f := cmd.Flags()
 
-	f.StringVarP(&cc.createConfigPath, "create-config", "c", emptyDefaultFlagValue, "specify the path to the configuration file")
+	f.StringVarP(&cc.createConfigPath, "create-config", "c", emptyDefaultFlagValue, "specify the path to the configuration file (yaml, json or toml)")
+	f.BoolVar(&cc.noSavedConfig, "no-saved-config", false, "neither load the answers of the previous run from .draft/create-config.yaml nor save the answers of this run there")
 	f.StringVarP(&cc.appName, "app", "a", emptyDefaultFlagValue, "specify the name of the helm release")
-	f.StringVarP(&cc.lang, "language", "l", emptyDefaultFlagValue, "specify the language used to create the Kubernetes deployment")
+	f.StringVarP(&cc.lang, "language", "l", emptyDefaultFlagValue, "specify the language used to create the Kubernetes deployment, or a comma separated preference order used when multiple languages are detected")
 	f.StringVarP(&cc.dest, "destination", "d", currentDirDefaultFlagValue, "specify the path to the project directory")
 	f.StringVarP(&cc.deployType, "deploy-type", "", emptyDefaultFlagValue, "specify deployement type (eg. helm, kustomize, manifests)")
 	f.BoolVar(&cc.dockerfileOnly, "dockerfile-only", false, "only create Dockerfile in the project directory")
 	f.BoolVar(&cc.deploymentOnly, "deployment-only", false, "only create deployment files in the project directory")
 	f.BoolVar(&cc.skipFileDetection, "skip-file-detection", false, "skip file detection step")
+	f.BoolVar(&cc.fullDetection, "full-detection", false, "classify every file by its contents when detecting the language, instead of going by the file names and manifests alone when one language clearly dominates")
+	f.BoolVar(&cc.merge, "merge", false, "merge the template changes into an existing Dockerfile and deployment files, keeping the edits made to them since they were generated, instead of asking whether to overwrite them")
+	f.BoolVar(&cc.patch, "patch", false, "keep existing Kubernetes manifests and layer the labels, probes and image of the generated Deployment over their Deployments with kustomize patches, instead of asking whether to overwrite them")
+	f.BoolVar(&cc.nonInteractive, "non-interactive", false, "never prompt: use --variable values, --create-config values and variable defaults, and fail with the list of variables that have none")
+	f.BoolVar(&cc.nonInteractive, "no-prompt", false, "alias for --non-interactive")
+	f.BoolVar(&cc.inspectCluster, "inspect-cluster", false, "inspect the cluster of the current kubeconfig context to default class names such as GATEWAYCLASSNAME to what is installed")
 	f.StringArrayVarP(&cc.flagVariables, "variable", "", []string{}, "pass additional variables using repeated --variable flag")
+	f.StringVar(&cc.environments, "environments", emptyDefaultFlagValue, "generate a kustomize base and one overlay per environment of this comma separated list (ex: dev,staging,prod), sets the ENVIRONMENTS variable")
+	f.BoolVar(&cc.autoscaling, "autoscaling", false, "scale the deployment on cpu utilization with a HorizontalPodAutoscaler, prompting for its minimum and maximum replicas and target cpu utilization, sets the AUTOSCALINGENABLED variable")
+	f.StringVar(&cc.imageTagStrategy, "image-tag-strategy", emptyDefaultFlagValue, "specify how the image of the deployment files is tagged, the same as the workflows of generate-workflow: "+strings.Join(imagetag.Names(), ", ")+" of the commit of the destination (defaults to "+imagetag.Default+"), sets the IMAGETAG variable")
+	f.StringArrayVar(&cc.sidecars, "sidecar", []string{}, "run a container next to the application in its pod, repeatable, as NAME=IMAGE followed by ,port=PORT and ,mount=VOLUME:PATH fields (ex: --sidecar logs=fluent/fluent-bit:3.0,mount=logs:/var/log/app), sets the SIDECARS variable")
+	f.StringArrayVar(&cc.initContainers, "init-container", []string{}, "run a container to completion before the application starts, such as a database migration, repeatable, in the format of --sidecar, sets the INITCONTAINERS variable")
+	f.StringVar(&cc.templateDir, "template-dir", emptyDefaultFlagValue, "load additional language and deployment packs from the dockerfiles and deployments directories of this directory, or of an OCI reference pushed with draft template push, replacing embedded packs with the same name")
+	f.StringArrayVar(&cc.packs, "pack", []string{}, "pull additional language and deployment packs from an OCI registry, laid out like --template-dir (ex: --pack oci://myregistry.azurecr.io/draft-packs/rust:v1)")
+	f.BoolVar(&cc.refreshPacks, "refresh-packs", false, "pull --pack references again instead of using the locally cached packs")
+	f.StringArrayVar(&cc.templateVersionFlags, "template-version", []string{}, "generate an artifact from a pinned template version listed by 'draft template list --versions' (ex: --template-version dockerfile=1.0.0 --template-version deployment=1.0.0)")
+	f.StringVar(&cc.githubRepo, "github-repo", emptyDefaultFlagValue, "commit the generated files to a branch of this GitHub repository (OWNER/REPO) through the GitHub API with the gh cli's login, instead of writing them to the destination; requires --language")
+	f.StringVar(&cc.githubBranch, "github-branch", "draft", "specify the branch of --github-repo to commit to, created from --github-base when it doesn't exist")
+	f.StringVar(&cc.githubBase, "github-base", emptyDefaultFlagValue, "specify the branch a new --github-branch starts from, the repository's default branch when not set")
+	f.BoolVar(&cc.apply, "apply", false, "deploy the generated helm, kustomize or manifests files to the cluster of the current kubeconfig context after confirming it, and wait for their rollout")
+	f.DurationVar(&cc.applyTimeout, "apply-timeout", apply.DefaultTimeout, "specify how long --apply waits for the rollout of the deployed workloads")
+	f.BoolVarP(&cc.assumeYes, "yes", "y", false, "write the generated files without showing a summary of the answers and files and asking for confirmation")
+	f.StringVar(&cc.source, "source", emptyDefaultFlagValue, "detect the language and read the variable defaults from this GitHub repository (ex: github.com/org/repo or github.com/org/repo@branch) through the GitHub API with the gh cli's login, instead of from the destination")
 
 	return cmd
 }
@@ -94,21 +223,38 @@ func newCreateCmd() *cobra.Command {
 func (cc *createCmd) initConfig() error {
 	if cc.createConfigPath != "" {
 		log.Debug("loading config")
-		configBytes, err := os.ReadFile(cc.createConfigPath)
+		cfg, err := loadCreateConfig(cc.createConfigPath)
 		if err != nil {
 			return err
 		}
-
-		var cfg CreateConfig
-		if err = yaml.Unmarshal(configBytes, &cfg); err != nil {
+		if err := cfg.revealSecrets(secrets.IdentityPath(secretsIdentity)); err != nil {
 			return err
 		}
-		cc.createConfig = &cfg
+		cc.createConfig = cfg
 		return nil
 	}
 
-	//TODO: create a config for the user and save it for subsequent uses
-	cc.createConfig = &CreateConfig{}
+	savedPath := filepath.Join(cc.dest, savedCreateConfigPath)
+	if cc.noSavedConfig {
+		cc.createConfig = &CreateConfig{}
+		return nil
+	}
+	cfg, err := loadSavedCreateConfig(savedPath)
+	if err != nil {
+		return err
+	}
+	if cfg.LanguageType != "" || cfg.DeployType != "" {
+		log.Infof("--> Using the answers saved in %s, pass --no-saved-config to answer again", savedPath)
+	}
+	// the language and deployment type passed as flags replace the saved ones along with their variables
+	if cc.lang != "" && !strings.EqualFold(cc.lang, cfg.LanguageType) {
+		cfg.LanguageType, cfg.LanguageVariables = "", nil
+	}
+	if cc.deployType != "" && !strings.EqualFold(cc.deployType, cfg.DeployType) {
+		cfg.DeployType, cfg.DeployVariables = "", nil
+	}
+	cc.createConfig = cfg
+	cc.savedConfig = *cfg
 
 	return nil
 }
@@ -116,13 +262,55 @@ func (cc *createCmd) initConfig() error {
 func (cc *createCmd) run() error {
 	log.Debugf("config: %s", cc.createConfigPath)
 
+	// set first so that the destination check doesn't prompt either
+	prompts.SetNonInteractive(cc.nonInteractive)
+	if cc.nonInteractive && overwritePolicy == overwrite.Prompt {
+		// existing files fail like with --interactive=false unless --force or --never-overwrite is passed
+		overwritePolicy = overwrite.Fail
+	}
+
+	var err error
+	if cc.githubRepo == "" {
+		if cc.dest, err = checkDestination(cc.dest); err != nil {
+			return err
+		}
+	}
+
 	for _, flagVar := range cc.flagVariables {
 		flagVarName, flagVarValue, ok := strings.Cut(flagVar, "=")
 		if !ok {
 			return fmt.Errorf("invalid variable format: %s", flagVar)
 		}
 		flagVariablesMap[flagVarName] = flagVarValue
-		log.Debugf("flag variable %s=%s", flagVarName, flagVarValue)
+		// only the name is logged, which variables are secrets isn't known until the templates are read
+		log.Debugf("flag variable %s", flagVarName)
+	}
+	if cc.environments != "" {
+		flagVariablesMap[deployments.EnvironmentsVariable] = cc.environments
+	}
+	if cc.autoscaling {
+		flagVariablesMap[deployments.AutoscalingEnabledVariable] = "true"
+	}
+	if len(cc.sidecars) > 0 {
+		flagVariablesMap[deployments.SidecarsVariable] = strings.Join(cc.sidecars, ";")
+	}
+	if len(cc.initContainers) > 0 {
+		flagVariablesMap[deployments.InitContainersVariable] = strings.Join(cc.initContainers, ";")
+	}
+
+	saveHistory := usePromptHistory(cc.dest)
+	defer saveHistory()
+	prompts.SetVariableValidators(promptValidators(cc.dest))
+
+	cc.templateVersions, err = parseTemplateVersions(cc.templateVersionFlags, dockerfileArtifact, deploymentArtifact)
+	if err != nil {
+		return err
+	}
+
+	if cc.inspectCluster && !cc.dockerfileOnly {
+		if cc.clusterDefaults, err = inspectClusterDefaults(); err != nil {
+			return err
+		}
 	}
 
 	var dryRunRecorder *dryrunpkg.DryRunRecorder
@@ -131,18 +319,81 @@ func (cc *createCmd) run() error {
 		cc.templateVariableRecorder = dryRunRecorder
 		cc.templateWriter = dryRunRecorder
 	} else {
-		cc.templateWriter = &writers.LocalFSWriter{}
+		cc.generation = dryrunpkg.NewDryRunRecorder()
+		cc.templateVariableRecorder = cc.generation
+		var fileWriter templatewriter.TemplateWriter
+		if cc.githubRepo != "" {
+			cc.githubFiles = &writers.FileMapWriter{}
+			cc.staged = &writers.StagedWriter{Writer: cc.githubFiles}
+			fileWriter = cc.staged
+		} else {
+			cc.staged = &writers.StagedWriter{Writer: &writers.LocalFSWriter{}}
+			fileWriter = withUncommittedChangesCheck(cc.staged, cc.dest)
+		}
+		cc.templateWriter = &writers.MultiWriter{Writers: []templatewriter.TemplateWriter{fileWriter, cc.generation}}
+		if cc.skipFileDetection {
+			// without file detection there is no per-artifact confirmation, so each existing file is confirmed instead
+			cc.templateWriter = withOverwritePolicy(cc.templateWriter)
+		}
+	}
+	if cc.previousGeneration, err = loadGenerationManifest(cc.dest); err != nil {
+		log.Warnf("not merging with the previously generated files: %s", err)
+	}
+	cc.mergeWriter = &writers.MergeWriter{Writer: cc.templateWriter}
+	cc.templateWriter = cc.mergeWriter
+	if cc.sourceReader != nil {
+		cc.repoReader = cc.sourceReader
+	} else {
+		cc.repoReader = &readers.LocalFSReader{Root: cc.dest}
+	}
+	if cc.templateWriter, err = withPolicyMetadata(withNormalizedYAML(cc.templateWriter, cc.templateVersions[deploymentArtifact])); err != nil {
+		return err
+	}
+	if cc.templateWriter, cc.imageMirror, err = withImageMirror(cc.templateWriter); err != nil {
+		return err
 	}
-	cc.repoReader = &readers.LocalFSReader{}
+	var capturedFiles *writers.FileMapWriter
+	cc.templateWriter, capturedFiles = withDependencyReport(cc.templateWriter)
 
 	detectedLangDraftConfig, languageName, err := cc.detectLanguage()
 	if err != nil {
 		return err
 	}
 
+	if strictVariables {
+		if err := cc.validateFlagVariables(detectedLangDraftConfig); err != nil {
+			return err
+		}
+	}
+
 	err = cc.createFiles(detectedLangDraftConfig, languageName)
+	if err == nil && !dryRun {
+		var confirmed bool
+		if confirmed, err = cc.writeStagedFiles(); err == nil && !confirmed {
+			log.Info("--> Not writing the generated files, run draft create again to change the answers")
+			return nil
+		}
+	}
+	if err == nil {
+		err = writeDependencyReport(capturedFiles)
+	}
+	if err == nil && !dryRun && cc.githubRepo != "" {
+		err = cc.commitToGitHubRepo()
+	}
+	if err == nil && !dryRun && cc.apply {
+		err = cc.applyDeployment()
+	}
+	if err == nil && !dryRun && !cc.noSavedConfig {
+		cc.saveConfig()
+	}
+	if err == nil && !dryRun && cc.githubRepo == "" {
+		cc.saveGeneration()
+	}
 	if dryRun {
 		cc.templateVariableRecorder.Record(LANGUAGE_VARIABLE, languageName)
+		if err := secrets.ProtectValues(dryRunRecorder.DryRunInfo.Variables, cc.secretVariables, secrets.IdentityPath(secretsIdentity), redactSecrets); err != nil {
+			return err
+		}
 		dryRunText, err := json.MarshalIndent(dryRunRecorder.DryRunInfo, "", TWO_SPACES)
 		if err != nil {
 			return err
@@ -159,6 +410,61 @@ func (cc *createCmd) run() error {
 	return err
 }
 
+// summaryReader returns the files the summary marks as replaced: those of --github-repo, or the local ones when nil
+func (cc *createCmd) summaryReader() reporeader.RepoReader {
+	if cc.githubRepo == "" {
+		return nil
+	}
+	return cc.githubRepoReader()
+}
+
+// writeStagedFiles shows the resolved variables and the generated files, and writes the files once confirmed. It
+// returns whether they were written.
+func (cc *createCmd) writeStagedFiles() (bool, error) {
+	paths := cc.staged.Paths()
+	if len(paths) == 0 {
+		return true, nil
+	}
+	confirmed, err := confirmSummary(cc.dest, cc.generation.DryRunInfo.Variables, cc.secretVariables, paths, cc.summaryReader, cc.assumeYes)
+	if err != nil || !confirmed {
+		return false, err
+	}
+	return true, cc.staged.Commit()
+}
+
+// validateFlagVariables checks that every --variable name is defined by the language or deployment config.
+// When the deployment type has not been chosen yet, names defined by any deployment type are accepted.
+func (cc *createCmd) validateFlagVariables(langConfig *config.DraftConfig) error {
+	configs := []*config.DraftConfig{}
+	if !cc.deploymentOnly {
+		configs = append(configs, langConfig)
+	}
+	if !cc.dockerfileOnly {
+		d, err := cc.loadDeployments()
+		if err != nil {
+			return err
+		}
+		deployTypes := d.DeployTypes()
+		if cc.createConfig.DeployType != "" {
+			deployTypes = []string{strings.ToLower(cc.createConfig.DeployType)}
+		} else if cc.deployType != "" {
+			deployTypes = []string{cc.deployType}
+		}
+		for _, deployType := range deployTypes {
+			deployConfig, err := d.GetConfig(deployType)
+			if err != nil {
+				return err
+			}
+			configs = append(configs, deployConfig)
+		}
+	}
+
+	if err := config.ValidateVariableNames(maps.Keys(flagVariablesMap), configs...); err != nil {
+		return fmt.Errorf("--strict-variables: %w", err)
+	}
+	return nil
+}
+
 // detectLanguage detects the language used in a project destination directory
 // It returns the DraftConfig for that language and the name of the language
 func (cc *createCmd) detectLanguage() (*config.DraftConfig, string, error) {
@@ -166,12 +472,14 @@ func (cc *createCmd) detectLanguage() (*config.DraftConfig, string, error) {
 	hasGoMod := false
 	var langs []*linguist.Language
 	var err error
+	languagePreferences := strings.Split(cc.lang, ",")
 	if cc.createConfig.LanguageType == "" {
-		if cc.lang != "" {
+		if cc.lang != "" && len(languagePreferences) == 1 {
 			cc.createConfig.LanguageType = cc.lang
 		} else {
 			log.Info("--- Detecting Language ---")
-			langs, err = linguist.ProcessDir(cc.dest)
+			cc.detection = cc.detectRepo()
+			langs, err = cc.detection.langs, cc.detection.langsErr
 			log.Debugf("linguist.ProcessDir(%v) result:\n\nError: %v", cc.dest, err)
 			if err != nil {
 				return nil, "", fmt.Errorf("there was an error detecting the language: %s", err)
@@ -182,27 +490,14 @@ func (cc *createCmd) detectLanguage() (*config.DraftConfig, string, error) {
 				if lang.Language == "Go" {
 					hasGo = true
 
-					selection := &promptui.Select{
-						Label: "Linguist detected Go, do you use Go Modules?",
-						Items: []string{"yes", "no"},
-					}
-
-					_, selectResponse, err := selection.Run()
+					hasGoMod, err = cc.usesGoModules()
 					if err != nil {
 						return nil, "", err
 					}
-
-					hasGoMod = strings.EqualFold(selectResponse, "yes")
 				}
 
 				if lang.Language == "Java" {
-
-					selection := &promptui.Select{
-						Label: "Linguist detected Java, are you using maven or gradle?",
-						Items: []string{"gradle", "maven", "gradlew"},
-					}
-
-					_, selectResponse, err := selection.Run()
+					selectResponse, err := cc.javaBuildTool()
 					if err != nil {
 						return nil, "", err
 					}
@@ -217,13 +512,14 @@ func (cc *createCmd) detectLanguage() (*config.DraftConfig, string, error) {
 
 			log.Debugf("detected %d langs", len(langs))
 
-			if len(langs) == 0 {
-				return nil, "", ErrNoLanguageDetected
-			}
 		}
 	}
 
-	cc.supportedLangs = languages.CreateLanguagesFromEmbedFS(template.Dockerfiles, cc.dest)
+	supportedLangs, err := cc.loadLanguages()
+	if err != nil {
+		return nil, "", err
+	}
+	cc.supportedLangs = supportedLangs
 
 	if cc.createConfig.LanguageType != "" {
 		log.Debug("using configuration language")
@@ -236,21 +532,198 @@ func (cc *createCmd) detectLanguage() (*config.DraftConfig, string, error) {
 		return langConfig, lowerLang, nil
 	}
 
+	var candidates []languageCandidate
 	for _, lang := range langs {
-		detectedLang := linguist.Alias(lang)
+		detectedLang := linguist.RuntimeAlias(linguist.Alias(lang), cc.dest)
 		log.Infof("--> Draft detected %s (%f%%)\n", detectedLang.Language, detectedLang.Percent)
 		lowerLang := strings.ToLower(detectedLang.Language)
+		if pack, ok := cc.functionsVariant(lowerLang); ok {
+			candidates = append(candidates, languageCandidate{pack: pack, language: detectedLang.Language, percent: detectedLang.Percent})
+			continue
+		}
 		if cc.supportedLangs.ContainsLanguage(lowerLang) {
 			if lowerLang == "go" && hasGo && hasGoMod {
 				log.Debug("detected go and go module")
 				lowerLang = "gomodule"
 			}
-			langConfig := cc.supportedLangs.GetConfig(lowerLang)
-			return langConfig, lowerLang, nil
+			lowerLang = cc.springBootVariant(lowerLang)
+			candidates = append(candidates, languageCandidate{pack: lowerLang, language: detectedLang.Language, percent: detectedLang.Percent})
+			continue
 		}
 		log.Infof("--> Could not find a pack for %s. Trying to find the next likely language match...", detectedLang.Language)
 	}
-	return nil, "", ErrNoLanguageDetected
+	if len(candidates) == 0 {
+		candidates = cc.manifestCandidates()
+	}
+	if len(candidates) == 0 {
+		return nil, "", ErrNoLanguageDetected
+	}
+
+	selected, err := selectLanguageCandidate(mergeLanguageCandidates(candidates), languagePreferences)
+	if err != nil {
+		return nil, "", err
+	}
+	langConfig := cc.supportedLangs.GetConfig(selected.pack)
+	return langConfig, selected.pack, nil
+}
+
+// manifestCandidates returns the packs of the dependency and build files of a repo linguist found no source files of
+// a supported language in, such as a new project with only its package.json
+func (cc *createCmd) manifestCandidates() []languageCandidate {
+	manifestMatches := languages.DetectFromManifests(cc.repoReader)
+	var candidates []languageCandidate
+	for _, match := range manifestMatches {
+		log.Infof("--> Draft detected %s from %s\n", match.Language, match.File)
+		pack, ok := cc.functionsVariant(match.Pack)
+		if !ok {
+			pack = cc.springBootVariant(match.Pack)
+		}
+		if !cc.supportedLangs.ContainsLanguage(pack) {
+			log.Infof("--> Could not find the %s pack. Trying to find the next likely language match...", pack)
+			continue
+		}
+		// the files tell nothing of how much of the repo is in each language, so they are equally likely
+		candidates = append(candidates, languageCandidate{pack: pack, language: match.Language, percent: 100 / float64(len(manifestMatches))})
+	}
+	return candidates
+}
+
+// usesGoModules asks whether the detected Go project uses go modules, or looks for its go.mod in non-interactive mode
+func (cc *createCmd) usesGoModules() (bool, error) {
+	if prompts.NonInteractive() {
+		return cc.repoFileExists("go.mod"), nil
+	}
+
+	selection := &promptui.Select{
+		Label: "Linguist detected Go, do you use Go Modules?",
+		Items: []string{"yes", "no"},
+	}
+
+	_, selectResponse, err := prompts.RunSelect(selection)
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(selectResponse, "yes"), nil
+}
+
+// javaBuildTool asks whether the detected Java project builds with gradle, maven or gradlew, or looks for their build
+// files in non-interactive mode
+func (cc *createCmd) javaBuildTool() (string, error) {
+	if prompts.NonInteractive() {
+		for _, buildFile := range []struct{ name, tool string }{
+			{"gradlew", "gradlew"},
+			{"build.gradle", "gradle"},
+			{"build.gradle.kts", "gradle"},
+			{"pom.xml", "maven"},
+		} {
+			if cc.repoFileExists(buildFile.name) {
+				return buildFile.tool, nil
+			}
+		}
+		return "", errors.New("no gradlew, build.gradle or pom.xml found for the detected Java project, pass --language gradlew, gradle or java in non-interactive mode")
+	}
+
+	selection := &promptui.Select{
+		Label: "Linguist detected Java, are you using maven or gradle?",
+		Items: []string{"gradle", "maven", "gradlew"},
+	}
+
+	_, selectResponse, err := prompts.RunSelect(selection)
+	return selectResponse, err
+}
+
+// languageCandidate is a detected language that has a Dockerfile pack
+type languageCandidate struct {
+	pack     string
+	language string
+	percent  float64
+}
+
+// mergeLanguageCandidates merges the candidates resolving to the same pack, such as Go and Go Module both resolving to
+// gomodule, adding up their percentages, and orders them from most to least likely
+func mergeLanguageCandidates(candidates []languageCandidate) []languageCandidate {
+	var merged []languageCandidate
+	index := make(map[string]int)
+	for _, c := range candidates {
+		if i, ok := index[c.pack]; ok {
+			merged[i].percent += c.percent
+			continue
+		}
+		index[c.pack] = len(merged)
+		merged = append(merged, c)
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].percent > merged[j].percent })
+	return merged
+}
+
+// selectLanguageCandidate picks the pack to use from the candidates, ordered by linguist from most to least likely.
+// When more than one candidate reaches significantLanguagePercent, the first one matching the --language preference
+// order is used, or the user is asked to choose instead of silently picking the top match.
+func selectLanguageCandidate(candidates []languageCandidate, preferences []string) (languageCandidate, error) {
+	var significant []languageCandidate
+	for _, c := range candidates {
+		if c.percent >= significantLanguagePercent {
+			significant = append(significant, c)
+		}
+	}
+	if len(significant) <= 1 {
+		return candidates[0], nil
+	}
+
+	for _, preference := range preferences {
+		preference = strings.TrimSpace(preference)
+		for _, c := range significant {
+			if preference != "" && (strings.EqualFold(c.pack, preference) || strings.EqualFold(c.language, preference)) {
+				log.Infof("--> Using %s from the --language preference order", c.pack)
+				return c, nil
+			}
+		}
+	}
+
+	if prompts.NonInteractive() {
+		packs := make([]string, len(significant))
+		for i, c := range significant {
+			packs[i] = c.pack
+		}
+		return languageCandidate{}, fmt.Errorf("draft detected multiple languages, pass --language to choose one of %s in non-interactive mode", strings.Join(packs, ", "))
+	}
+
+	selected, err := prompts.Select("Draft detected multiple languages, which pack would you like to use?", significant, &prompts.SelectOpt[languageCandidate]{
+		Field: func(c languageCandidate) string {
+			return fmt.Sprintf("%s (%.2f%%)", c.pack, c.percent)
+		},
+	})
+	if err != nil {
+		return languageCandidate{}, fmt.Errorf("selecting language: %w", err)
+	}
+	return selected, nil
+}
+
+// springBootVariant returns the Spring Boot layered jar pack for lowerLang when the repo applies the Spring Boot plugin
+func (cc *createCmd) springBootVariant(lowerLang string) string {
+	variant, ok := defaults.SpringBootPackVariant(lowerLang)
+	if !ok || cc.repoReader == nil || !cc.supportedLangs.ContainsLanguage(variant) {
+		return lowerLang
+	}
+	if !cc.isSpringBootProject() {
+		return lowerLang
+	}
+	log.Infof("--> Draft detected Spring Boot, using the %s pack", variant)
+	return variant
+}
+
+// functionsVariant returns the Azure Functions pack for lowerLang when the repo is an Azure Functions app, so it is not
+// treated as a plain web app of that language
+func (cc *createCmd) functionsVariant(lowerLang string) (string, bool) {
+	variant, ok := defaults.AzureFunctionsPackVariant(lowerLang)
+	if !ok || cc.repoReader == nil || !cc.supportedLangs.ContainsLanguage(variant) {
+		return "", false
+	}
+	if !cc.isAzureFunctionsProject() {
+		return "", false
+	}
+	log.Infof("--> Draft detected Azure Functions, using the %s pack", variant)
+	return variant, true
 }
 
 func (cc *createCmd) generateDockerfile(langConfig *config.DraftConfig, lowerLang string) error {
@@ -259,47 +732,39 @@ func (cc *createCmd) generateDockerfile(langConfig *config.DraftConfig, lowerLan
 		return errors.New("supported languages were loaded incorrectly")
 	}
 
+	if templateVersion := cc.templateVersions[dockerfileArtifact]; templateVersion != "" {
+		if err := cc.supportedLangs.UseVersion(lowerLang, templateVersion); err != nil {
+			return err
+		}
+		langConfig = cc.supportedLangs.GetConfig(lowerLang)
+	}
+
 	// Extract language-specific defaults from repo
-	extractedValues, err := cc.supportedLangs.ExtractDefaults(lowerLang, cc.repoReader)
+	extractedValues, err := cc.extractDefaults(lowerLang)
 	if err != nil {
 		return err
 	}
 
-	// Check for existing duplicate defualts
-	for k, v := range extractedValues {
-		variableExists := false
-		for i, varD := range langConfig.VariableDefaults {
-			if k == varD.Name {
-				variableExists = true
-				langConfig.VariableDefaults[i].Value = v
-				break
-			}
-		}
-		if !variableExists {
-			langConfig.VariableDefaults = append(langConfig.VariableDefaults, config.BuilderVarDefault{
-				Name:  k,
-				Value: v,
-			})
-		}
-	}
+	draft.ApplyExtractedDefaults(langConfig, extractedValues)
+
+	cc.secretVariables = append(cc.secretVariables, langConfig.SecretVariableNames()...)
 
 	var inputs map[string]string
 	if cc.createConfig.LanguageVariables == nil {
-		inputs, err = prompts.RunPromptsFromConfigWithSkips(langConfig, maps.Keys(flagVariablesMap))
+		inputs, err = prompts.RunPromptsFromConfigWithValues(langConfig, flagVariablesMap)
 		if err != nil {
-			return err
+			return withVariableHint(err)
 		}
 	} else {
-		inputs, err = validateConfigInputsToPrompts(langConfig.Variables, cc.createConfig.LanguageVariables, langConfig.VariableDefaults)
+		inputs, err = validateConfigInputsToPrompts(langConfig.Variables, withFlagVariables(cc.createConfig.LanguageVariables), langConfig.VariableDefaults)
 		if err != nil {
 			return err
 		}
 	}
 
 	if cc.templateVariableRecorder != nil {
-		for k, v := range inputs {
-			cc.templateVariableRecorder.Record(k, v)
-		}
+		langConfig.RecordVariables(cc.templateVariableRecorder, inputs)
+		cc.recordTemplateVersion(dockerfileArtifact, langConfig.Version)
 	}
 
 	maps.Copy(inputs, flagVariablesMap)
@@ -307,20 +772,44 @@ func (cc *createCmd) generateDockerfile(langConfig *config.DraftConfig, lowerLan
 	if err = cc.supportedLangs.CreateDockerfileForLanguage(lowerLang, inputs, cc.templateWriter); err != nil {
 		return fmt.Errorf("there was an error when creating the Dockerfile for language %s: %w", cc.createConfig.LanguageType, err)
 	}
+	cc.dockerfileInputs = inputs
+	cc.savedConfig.LanguageType = lowerLang
+	cc.savedConfig.LanguageVariables = newUserInputs(inputs, langConfig.Variables)
 
 	log.Info("--> Creating Dockerfile...\n")
 	return err
 }
 
+// applyImageTagDefault defaults IMAGETAG to the tag of the image built from the commit of dest by the
+// --image-tag-strategy, the tag the workflows of generate-workflow push it with
+func (cc *createCmd) applyImageTagDefault(deployConfig *config.DraftConfig) error {
+	strategy, err := imagetag.Get(cc.imageTagStrategy)
+	if err != nil {
+		return err
+	}
+	tag := imagetag.TagForDir(strategy, cc.dest)
+	if deployConfig.SetVariableDefault("IMAGETAG", tag) {
+		log.Debugf("defaulting IMAGETAG to %s by the image tag strategy", tag)
+	}
+	return nil
+}
+
 func (cc *createCmd) createDeployment() error {
 	log.Info("--- Deployment File Creation ---")
-	d := deployments.CreateDeploymentsFromEmbedFS(template.Deployments, cc.dest)
+	d, err := cc.loadDeployments()
+	if err != nil {
+		return err
+	}
 	var deployType string
 	var customInputs map[string]string
-	var err error
+	// explicitVariables are the values set with --variable or in the config file rather than by the template defaults
+	explicitVariables := maps.Clone(flagVariablesMap)
 
 	if cc.createConfig.DeployType != "" {
 		deployType = strings.ToLower(cc.createConfig.DeployType)
+		if err := cc.pinDeploymentVersion(d, deployType); err != nil {
+			return err
+		}
 		deployConfig, err := d.GetConfig(deployType)
 		if err != nil {
 			return err
@@ -328,19 +817,31 @@ func (cc *createCmd) createDeployment() error {
 		if deployConfig == nil {
 			return errors.New("invalid deployment type")
 		}
-		customInputs, err = validateConfigInputsToPrompts(deployConfig.Variables, cc.createConfig.DeployVariables, deployConfig.VariableDefaults)
+		applyClusterDefaults(deployConfig, cc.clusterDefaults)
+		if err := cc.applyImageTagDefault(deployConfig); err != nil {
+			return err
+		}
+		draft.ApplyDockerfileDefaults(deployConfig, cc.dockerfileInputs)
+		cc.secretVariables = append(cc.secretVariables, deployConfig.SecretVariableNames()...)
+		customInputs, err = validateConfigInputsToPrompts(deployConfig.Variables, withFlagVariables(cc.createConfig.DeployVariables), deployConfig.VariableDefaults)
 		if err != nil {
 			return err
 		}
+		for _, input := range withFlagVariables(cc.createConfig.DeployVariables) {
+			explicitVariables[input.Name] = input.Value
+		}
 
 	} else {
 		if cc.deployType == "" {
+			if prompts.NonInteractive() {
+				return fmt.Errorf("--deploy-type is required in non-interactive mode, one of: %s", strings.Join(deployTypeItems(d), ", "))
+			}
 			selection := &promptui.Select{
-				Label: "Select k8s Deployment Type",
-				Items: []string{"helm", "kustomize", "manifests"},
+				Label: "Select Deployment Type",
+				Items: deployTypeItems(d),
 			}
 
-			_, deployType, err = selection.Run()
+			_, deployType, err = prompts.RunSelect(selection)
 			if err != nil {
 				return err
 			}
@@ -348,29 +849,187 @@ func (cc *createCmd) createDeployment() error {
 			deployType = cc.deployType
 		}
 
+		if err := cc.pinDeploymentVersion(d, deployType); err != nil {
+			return err
+		}
 		deployConfig, err := d.GetConfig(deployType)
 		if err != nil {
 			return err
 		}
-		customInputs, err = prompts.RunPromptsFromConfigWithSkips(deployConfig, maps.Keys(flagVariablesMap))
-		if err != nil {
+		applyClusterDefaults(deployConfig, cc.clusterDefaults)
+		if err := cc.applyImageTagDefault(deployConfig); err != nil {
 			return err
 		}
+		draft.ApplyDockerfileDefaults(deployConfig, cc.dockerfileInputs)
+		if cc.autoscaling {
+			deployments.PromptAutoscaling(deployConfig)
+		}
+		cc.secretVariables = append(cc.secretVariables, deployConfig.SecretVariableNames()...)
+		customInputs, err = prompts.RunPromptsFromConfigWithValues(deployConfig, flagVariablesMap)
+		if err != nil {
+			return withVariableHint(err)
+		}
 	}
 
+	customInputs[DRAFT_VERSION_VARIABLE] = VERSION
+	maps.Copy(customInputs, draft.DeploymentValues(cc.dockerfileInputs))
 	maps.Copy(customInputs, flagVariablesMap)
+	if err := deployments.ResolveResourcePreset(customInputs, explicitVariables); err != nil {
+		return err
+	}
+	if cc.imageMirror != nil {
+		// the application's image is pushed to its own registry, not pulled from a public one
+		cc.imageMirror.Keep(customInputs["IMAGENAME"])
+	}
 
 	if cc.templateVariableRecorder != nil {
-		for k, v := range customInputs {
-			cc.templateVariableRecorder.Record(k, v)
+		deployConfig, err := d.GetConfig(deployType)
+		if err != nil {
+			return err
 		}
+		deployConfig.RecordVariables(cc.templateVariableRecorder, customInputs)
+		cc.recordTemplateVersion(deploymentArtifact, deployConfig.Version)
 	}
 
 	log.Infof("--> Creating %s Kubernetes resources...\n", deployType)
 
+	deployConfig, err := d.GetConfig(deployType)
+	if err != nil {
+		return err
+	}
+	if cc.environments != "" && !slices.Contains(deployConfig.VariableNames(), deployments.EnvironmentsVariable) {
+		return fmt.Errorf("--environments only applies to the kustomize deployment type, not %s", deployType)
+	}
+	if cc.autoscaling && !slices.Contains(deployConfig.VariableNames(), deployments.AutoscalingEnabledVariable) {
+		return fmt.Errorf("--autoscaling only applies to the helm, kustomize and manifests deployment types, not %s", deployType)
+	}
+	if len(cc.sidecars)+len(cc.initContainers) > 0 && !slices.Contains(deployConfig.VariableNames(), deployments.SidecarsVariable) {
+		return fmt.Errorf("--sidecar and --init-container only apply to the helm, kustomize and manifests deployment types, not %s", deployType)
+	}
+	cc.savedConfig.DeployType = deployType
+	cc.savedConfig.DeployVariables = newUserInputs(customInputs, deployConfig.Variables)
+
 	return d.CopyDeploymentFiles(deployType, customInputs, cc.templateWriter)
 }
 
+// withVariableHint adds how to pass the variables listed by a prompts.MissingVariablesError to err
+func withVariableHint(err error) error {
+	var missing *prompts.MissingVariablesError
+	if errors.As(err, &missing) {
+		return fmt.Errorf("%w\npass them with --variable NAME=value or in a --create-config file", err)
+	}
+	return err
+}
+
+// saveConfig saves the answers of this run for the next one. Failing to save them doesn't fail the files that were
+// created, so it is only logged.
+func (cc *createCmd) saveConfig() {
+	savedPath := filepath.Join(cc.dest, savedCreateConfigPath)
+	if err := saveCreateConfig(savedPath, &cc.savedConfig, cc.secretVariables); err != nil {
+		log.Warnf("not saving the answers to %s: %s", savedPath, err)
+		return
+	}
+	log.Infof("--> Saved the answers to %s, the next draft create uses them without prompting", savedPath)
+}
+
+// existingFilesAction asks whether to keep, overwrite or merge the existing files described by name, or to patch them
+// when canPatch is set. Merging is offered when a previous run recorded the files it generated, and chosen without
+// asking with --merge, as patching is with --patch.
+func (cc *createCmd) existingFilesAction(name string, canPatch bool) (overwrite.Action, error) {
+	if canPatch && cc.patch {
+		return overwrite.Patch, nil
+	}
+	var offered []overwrite.Action
+	if cc.previousGeneration == nil {
+		if cc.merge {
+			log.Warnf("--> --merge needs the %s recorded when the files were generated, %s has none", generationManifestPath, cc.dest)
+		}
+	} else if cc.merge {
+		return overwrite.Merge, nil
+	} else {
+		offered = append(offered, overwrite.Merge)
+	}
+	if canPatch {
+		offered = append(offered, overwrite.Patch)
+	}
+	if len(offered) == 0 {
+		replace, err := overwritePolicy.Confirm(name)
+		if err != nil || !replace {
+			return overwrite.Keep, err
+		}
+		return overwrite.Replace, nil
+	}
+	return overwritePolicy.ChooseAction(name, offered...)
+}
+
+// withMerge runs generate with the generated files merged into the existing ones when merge is set
+func (cc *createCmd) withMerge(merge bool, generate func() error) error {
+	if !merge {
+		return generate()
+	}
+	if cc.mergeBase == nil {
+		base, err := renderGeneration(cc.dest, cc.previousGeneration)
+		if err != nil {
+			return err
+		}
+		cc.mergeBase = base
+	}
+	cc.mergeWriter.Base = cc.mergeBase
+	defer func() { cc.mergeWriter.Base = nil }()
+	return generate()
+}
+
+// saveGeneration records the variables and template versions of this run in generationManifestPath for merging
+// template changes later. Failing to save it doesn't fail the files that were created, so it is only logged.
+func (cc *createCmd) saveGeneration() {
+	if len(cc.generation.DryRunInfo.FilesToWrite) == 0 {
+		return
+	}
+	if cc.savedConfig.LanguageType != "" {
+		cc.generation.Record(LANGUAGE_VARIABLE, cc.savedConfig.LanguageType)
+	}
+	if err := saveGenerationManifest(cc.dest, cc.previousGeneration, cc.generation.DryRunInfo, cc.secretVariables); err != nil {
+		log.Warnf("not recording the generated files in %s: %s", generationManifestPath, err)
+	}
+}
+
+// suggestAppConfig points to draft update app-config when the project has application config files, whose keys the
+// generated deployment doesn't set yet
+func (cc *createCmd) suggestAppConfig() {
+	keys, err := appconfig.Discover(&readers.LocalFSReader{Root: cc.dest})
+	if err != nil {
+		log.Debugf("not reading the application config: %s", err)
+		return
+	}
+	if len(keys) == 0 {
+		return
+	}
+	groups := appconfig.GroupKeys(keys)
+	log.Infof("--> Found %d application config keys in %d groups, run 'draft update app-config' to generate a ConfigMap and Secret for them", len(keys), len(groups))
+}
+
+// templateVersionRecorder is a TemplateVariableRecorder that also records the template version of each artifact
+type templateVersionRecorder interface {
+	RecordTemplateVersion(artifact, templateVersion string)
+}
+
+// recordTemplateVersion records the version of the template artifact was generated from, so that draft update
+// regenerate can tell files modified by the user from files the template generated
+func (cc *createCmd) recordTemplateVersion(artifact, templateVersion string) {
+	if recorder, ok := cc.templateVariableRecorder.(templateVersionRecorder); ok && templateVersion != "" {
+		recorder.RecordTemplateVersion(artifact, templateVersion)
+	}
+}
+
+// pinDeploymentVersion switches deployType to the template version pinned with --template-version, if any
+func (cc *createCmd) pinDeploymentVersion(d *deployments.Deployments, deployType string) error {
+	templateVersion := cc.templateVersions[deploymentArtifact]
+	if templateVersion == "" {
+		return nil
+	}
+	return d.UseVersion(deployType, templateVersion)
+}
+
 func (cc *createCmd) createFiles(detectedLang *config.DraftConfig, lowerLang string) error {
 	// does no further checks without file detection
 
@@ -400,19 +1059,15 @@ func (cc *createCmd) createFiles(detectedLang *config.DraftConfig, lowerLang str
 		return err
 	}
 
-	// prompts user for dockerfile re-creation
+	// asks whether to recreate or merge the dockerfile, following the overwrite policy
+	var mergeDockerfile bool
 	if hasDockerFile && !cc.deploymentOnly {
-		selection := &promptui.Select{
-			Label: "We found Dockerfile in the directory, would you like to recreate the Dockerfile?",
-			Items: []string{"yes", "no"},
-		}
-
-		_, selectResponse, err := selection.Run()
+		action, err := cc.existingFilesAction("Dockerfile", false)
 		if err != nil {
 			return err
 		}
-
-		hasDockerFile = strings.EqualFold(selectResponse, "no")
+		hasDockerFile = action == overwrite.Keep
+		mergeDockerfile = action == overwrite.Merge
 	}
 
 	if cc.deploymentOnly {
@@ -420,40 +1075,47 @@ func (cc *createCmd) createFiles(detectedLang *config.DraftConfig, lowerLang str
 	} else if hasDockerFile {
 		log.Info("--> Found Dockerfile in local directory, skipping Dockerfile creation...")
 	} else if !cc.deploymentOnly {
-		err := cc.generateDockerfile(detectedLang, lowerLang)
+		err := cc.withMerge(mergeDockerfile, func() error {
+			return cc.generateDockerfile(detectedLang, lowerLang)
+		})
 		if err != nil {
 			return err
 		}
 	}
 
-	// prompts user for deployment re-creation
+	// asks whether to recreate, merge or patch the deployment files, following the overwrite policy
+	var mergeDeployment, patchDeployment bool
 	if hasDeploymentFiles && !cc.dockerfileOnly {
-		selection := &promptui.Select{
-			Label: "We found deployment files in the directory, would you like to create new deployment files?",
-			Items: []string{"yes", "no"},
-		}
-
-		_, selectResponse, err := selection.Run()
+		action, err := cc.existingFilesAction("deployment files", true)
 		if err != nil {
 			return err
 		}
-
-		hasDeploymentFiles = strings.EqualFold(selectResponse, "no")
+		hasDeploymentFiles = action == overwrite.Keep
+		mergeDeployment = action == overwrite.Merge
+		patchDeployment = action == overwrite.Patch
 	}
 
 	if cc.dockerfileOnly {
 		log.Info("--> --dockerfile-only=true, skipping deployment file creation...")
 	} else if hasDeploymentFiles {
 		log.Info("--> Found deployment directory in local directory, skipping deployment file creation...")
+	} else if patchDeployment {
+		if err := cc.createDeploymentPatches(); err != nil {
+			return err
+		}
 	} else if !cc.dockerfileOnly {
-		err := cc.createDeployment()
+		err := cc.withMerge(mergeDeployment, cc.createDeployment)
 		if err != nil {
 			return err
 		}
+		cc.suggestAppConfig()
+	}
+	if len(cc.mergeWriter.Conflicted) > 0 {
+		log.Warnf("--> Resolve the conflicts marked between <<<<<<< and >>>>>>> in %s", strings.Join(cc.mergeWriter.Conflicted, ", "))
 	}
 
 	log.Info("Draft has successfully created deployment resources for your project 😃")
-	log.Info("Use 'draft setup-gh' to set up Github OIDC.")
+	log.Info("Use 'draft setup-oidc' to set up Github OIDC.")
 
 	return nil
 }
@@ -462,6 +1124,18 @@ func init() {
 	rootCmd.AddCommand(newCreateCmd())
 }
 
+// withFlagVariables returns the config values with the --variable values replacing them, so the config's defaults
+// can reference them
+func withFlagVariables(inputs []UserInputs) []UserInputs {
+	merged := append([]UserInputs{}, inputs...)
+	names := maps.Keys(flagVariablesMap)
+	sort.Strings(names)
+	for _, name := range names {
+		merged = append(merged, UserInputs{Name: name, Value: flagVariablesMap[name]})
+	}
+	return merged
+}
+
 func validateConfigInputsToPrompts(required []config.BuilderVar, provided []UserInputs, defaults []config.BuilderVarDefault) (map[string]string, error) {
 	customInputs := make(map[string]string)
 
@@ -472,24 +1146,35 @@ func validateConfigInputsToPrompts(required []config.BuilderVar, provided []User
 
 	// fill in missing vars using variable default references
 	for _, variableDefault := range defaults {
-		if customInputs[variableDefault.Name] == "" && variableDefault.ReferenceVar != "" {
+		if customInputs[variableDefault.Name] == "" && variableDefault.ReferenceVar != "" && customInputs[variableDefault.ReferenceVar] != "" {
 			log.Debugf("variable %s is empty, using default referenceVar value from %s", variableDefault.Name, variableDefault.ReferenceVar)
 			customInputs[variableDefault.Name] = customInputs[variableDefault.ReferenceVar]
 		}
 	}
 
-	// fill in missing vars using variable default values
+	// fill in missing vars using variable default values, an empty default still providing the variable
 	for _, variableDefault := range defaults {
 		if customInputs[variableDefault.Name] == "" && variableDefault.Value != "" {
 			log.Debugf("setting default value for %s to %s", variableDefault.Name, variableDefault.Value)
 			customInputs[variableDefault.Name] = variableDefault.Value
+		} else if customInputs[variableDefault.Name] == "" && variableDefault.ReferenceVar != "" {
+			return nil, fmt.Errorf("config variable %s defaults to the value of %s, which has none", variableDefault.Name, variableDefault.ReferenceVar)
+		} else if _, ok := customInputs[variableDefault.Name]; !ok {
+			customInputs[variableDefault.Name] = ""
 		}
 	}
 
 	for _, variable := range required {
-		if _, ok := customInputs[variable.Name]; !ok {
+		value, ok := customInputs[variable.Name]
+		if !ok {
 			return nil, fmt.Errorf("config missing required variable: %s with description: %s", variable.Name, variable.Description)
 		}
+		if value == "" {
+			continue
+		}
+		if err := variable.ValidateValue(value); err != nil {
+			return nil, err
+		}
 	}
 
 	return customInputs, nil
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -9,22 +10,27 @@ import (
 	"strings"
 	"testing"
 
+	"filippo.io/age"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/deployments"
 	"github.com/Azure/draft/pkg/languages"
 	"github.com/Azure/draft/pkg/linguist"
+	"github.com/Azure/draft/pkg/prompts"
 	"github.com/Azure/draft/pkg/reporeader"
+	"github.com/Azure/draft/pkg/reporeader/readers"
+	"github.com/Azure/draft/pkg/secrets"
 	"github.com/Azure/draft/pkg/templatewriter/writers"
 	"github.com/Azure/draft/template"
 )
 
 func TestRun(t *testing.T) {
 	testCreateConfig := CreateConfig{LanguageVariables: []UserInputs{{Name: "PORT", Value: "8080"}}, DeployVariables: []UserInputs{{Name: "PORT", Value: "8080"}, {Name: "APPNAME", Value: "testingCreateCommand"}}}
-	flagVariablesMap = map[string]string{"PORT": "8080", "APPNAME": "testingCreateCommand", "VERSION": "1.18", "SERVICEPORT": "8080", "NAMESPACE": "testNamespace", "IMAGENAME": "testImage", "IMAGETAG": "latest"}
+	flagVariablesMap = map[string]string{"PORT": "8080", "APPNAME": "testingCreateCommand", "VERSION": "1.18", "SERVICEPORT": "8080", "NAMESPACE": "testNamespace", "IMAGENAME": "testImage", "IMAGETAG": "latest", "INGRESSEXTERNAL": "true"}
 	mockCC := createCmd{dest: "./..", createConfig: &testCreateConfig, templateWriter: &writers.LocalFSWriter{}}
-	deployTypes := []string{"helm", "kustomize", "manifests"}
+	deployTypes := []string{"helm", "kustomize", "manifests", "containerapp", "appservice"}
 	oldDockerfile, _ := ioutil.ReadFile("./../Dockerfile")
 	oldDockerignore, _ := ioutil.ReadFile("./../.dockerignore")
 
@@ -75,6 +81,7 @@ func TestRun(t *testing.T) {
 		os.RemoveAll("./../base")
 		os.RemoveAll("./../overlays")
 		os.RemoveAll("./../manifests")
+		os.RemoveAll("./../azure")
 
 		//deployment variables passed through createConfig
 		mockCC.createConfig.DeployType = deployType
@@ -93,6 +100,7 @@ func TestRun(t *testing.T) {
 		os.RemoveAll("./../base")
 		os.RemoveAll("./../overlays")
 		os.RemoveAll("./../manifests")
+		os.RemoveAll("./../azure")
 	}
 }
 
@@ -118,7 +126,7 @@ func TestRunCreateDockerfileWithRepoReader(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	assert.Contains(t, string(dockerFileContent), "CMD [\"main.py\"]")
+	assert.Contains(t, string(dockerFileContent), "CMD [\"python\",\"main.py\"]")
 
 	err = os.Remove("Dockerfile")
 	if err != nil {
@@ -141,6 +149,46 @@ func TestInitConfig(t *testing.T) {
 	assert.True(t, mockCC.createConfig != nil)
 }
 
+func TestInitConfigFormats(t *testing.T) {
+	for _, configPath := range []string{"./../test/templates/config.json", "./../test/templates/config.toml"} {
+		t.Run(configPath, func(t *testing.T) {
+			mockCC := &createCmd{createConfigPath: configPath}
+
+			err := mockCC.initConfig()
+			assert.Nil(t, err)
+			assert.Equal(t, "kustomize", mockCC.createConfig.DeployType)
+			assert.Equal(t, "go", mockCC.createConfig.LanguageType)
+			assert.Equal(t, []UserInputs{{Name: "PORT", Value: "8080"}}, mockCC.createConfig.DeployVariables)
+			assert.Equal(t, []UserInputs{{Name: "PORT", Value: "8080"}}, mockCC.createConfig.LanguageVariables)
+		})
+	}
+
+	mockCC := &createCmd{createConfigPath: "./../test/templates/config.yaml.json"}
+	assert.NotNil(t, mockCC.initConfig())
+}
+
+func TestInitConfigEncryptedValues(t *testing.T) {
+	t.Setenv(secrets.IdentityEnv, "")
+	identity, err := age.GenerateX25519Identity()
+	assert.Nil(t, err)
+	dir := t.TempDir()
+	identityPath := filepath.Join(dir, "key.txt")
+	assert.Nil(t, os.WriteFile(identityPath, []byte(identity.String()), 0600))
+
+	encrypted, err := secrets.Encrypt("hunter2", identity.Recipient())
+	assert.Nil(t, err)
+	configPath := filepath.Join(dir, "config.yaml")
+	assert.Nil(t, os.WriteFile(configPath, []byte("deployType: manifests\ndeployVariables:\n  - name: DBPASSWORD\n    value: "+encrypted+"\n"), 0644))
+
+	mockCC := &createCmd{createConfigPath: configPath}
+	assert.NotNil(t, mockCC.initConfig(), "encrypted values need an identity")
+
+	secretsIdentity = identityPath
+	defer func() { secretsIdentity = "" }()
+	assert.Nil(t, mockCC.initConfig())
+	assert.Equal(t, []UserInputs{{Name: "DBPASSWORD", Value: "hunter2"}}, mockCC.createConfig.DeployVariables)
+}
+
 func TestValidateConfigInputsToPromptsPass(t *testing.T) {
 	required := []config.BuilderVar{
 		{Name: "REQUIRED_PROVIDED"},
@@ -172,6 +220,268 @@ func TestValidateConfigInputsToPromptsMissing(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestValidateConfigInputsToPromptsReferences(t *testing.T) {
+	oldFlagVariablesMap := flagVariablesMap
+	defer func() { flagVariablesMap = oldFlagVariablesMap }()
+
+	required := []config.BuilderVar{{Name: "PORT"}, {Name: "SERVICEPORT"}}
+	defaults := []config.BuilderVarDefault{{Name: "SERVICEPORT", ReferenceVar: "PORT"}}
+
+	// the references read the --variable values too
+	flagVariablesMap = map[string]string{"PORT": "8080"}
+	vars, err := validateConfigInputsToPrompts(required, withFlagVariables(nil), defaults)
+	assert.Nil(t, err)
+	assert.Equal(t, "8080", vars["SERVICEPORT"])
+
+	flagVariablesMap = map[string]string{}
+	_, err = validateConfigInputsToPrompts(required, []UserInputs{{Name: "PORT"}}, defaults)
+	assert.EqualError(t, err, "config variable SERVICEPORT defaults to the value of PORT, which has none")
+}
+
+func TestValidateConfigInputsToPromptsRules(t *testing.T) {
+	required := []config.BuilderVar{
+		{Name: "APPNAME", Pattern: "[a-z][a-z0-9-]*"},
+		{Name: "TIER", AllowedValues: []string{"web", "worker"}},
+	}
+	defaults := []config.BuilderVarDefault{{Name: "TIER", Value: "web"}}
+
+	vars, err := validateConfigInputsToPrompts(required, []UserInputs{{Name: "APPNAME", Value: "my-app"}}, defaults)
+	assert.Nil(t, err)
+	assert.Equal(t, "web", vars["TIER"])
+
+	_, err = validateConfigInputsToPrompts(required, []UserInputs{{Name: "APPNAME", Value: "My_App"}}, defaults)
+	assert.EqualError(t, err, `invalid value "My_App" for APPNAME, must match [a-z][a-z0-9-]*`)
+
+	_, err = validateConfigInputsToPrompts(required, []UserInputs{{Name: "APPNAME", Value: "my-app"}, {Name: "TIER", Value: "batch"}}, defaults)
+	assert.EqualError(t, err, `invalid value "batch" for TIER, must be one of: web, worker`)
+}
+
+func TestSelectLanguageCandidate(t *testing.T) {
+	candidates := []languageCandidate{
+		{pack: "javascript", language: "TypeScript", percent: 45},
+		{pack: "gomodule", language: "Go", percent: 40},
+		{pack: "python", language: "Python", percent: 5},
+	}
+
+	selected, err := selectLanguageCandidate(candidates[1:], nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "gomodule", selected.pack, "a single significant language is used without prompting")
+
+	selected, err = selectLanguageCandidate(candidates, []string{"python", "go", "typescript"})
+	assert.Nil(t, err)
+	assert.Equal(t, "gomodule", selected.pack, "insignificant languages are skipped in the preference order")
+
+	selected, err = selectLanguageCandidate(candidates, []string{" GOMODULE "})
+	assert.Nil(t, err)
+	assert.Equal(t, "gomodule", selected.pack)
+}
+
+func TestValidateFlagVariables(t *testing.T) {
+	oldFlagVariablesMap := flagVariablesMap
+	defer func() { flagVariablesMap = oldFlagVariablesMap }()
+
+	langConfig := &config.DraftConfig{Variables: []config.BuilderVar{{Name: "PORT"}}}
+	mockCC := &createCmd{createConfig: &CreateConfig{}, deployType: "helm", dest: "."}
+
+	flagVariablesMap = map[string]string{"PORT": "80", "APPNAME": "app", "RESOURCEPRESET": "small"}
+	assert.Nil(t, mockCC.validateFlagVariables(langConfig))
+
+	flagVariablesMap = map[string]string{"APPNAM": "app"}
+	err := mockCC.validateFlagVariables(langConfig)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "did you mean APPNAME?")
+
+	mockCC.dockerfileOnly = true
+	flagVariablesMap = map[string]string{"APPNAME": "app"}
+	assert.NotNil(t, mockCC.validateFlagVariables(langConfig))
+}
+
+func TestLoadLanguagesTemplateDir(t *testing.T) {
+	templateDir := t.TempDir()
+	packDir := filepath.Join(templateDir, "dockerfiles", "cobol")
+	assert.Nil(t, os.MkdirAll(packDir, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(packDir, "draft.yaml"), []byte("language: cobol\nversion: \"0.1.0\"\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(packDir, "Dockerfile"), []byte("FROM cobol\n"), 0644))
+
+	mockCC := &createCmd{dest: "out"}
+	l, err := mockCC.loadLanguages()
+	assert.Nil(t, err)
+	assert.False(t, l.ContainsLanguage("cobol"))
+
+	mockCC = &createCmd{dest: "out", templateDir: templateDir}
+	l, err = mockCC.loadLanguages()
+	assert.Nil(t, err)
+	assert.True(t, l.ContainsLanguage("cobol"))
+	assert.True(t, l.ContainsLanguage("go"))
+	d, err := mockCC.loadDeployments()
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, embeddedDeployTypes, d.DeployTypes())
+
+	mockCC = &createCmd{dest: "out", templateDir: filepath.Join(templateDir, "missing")}
+	_, err = mockCC.loadLanguages()
+	assert.NotNil(t, err)
+}
+
+func TestLoadDeploymentsTemplateDir(t *testing.T) {
+	templateDir := t.TempDir()
+	packDir := filepath.Join(templateDir, "deployments", "knative")
+	assert.Nil(t, os.MkdirAll(packDir, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(packDir, "draft.yaml"), []byte("version: \"0.1.0\"\nvariables:\n  - name: \"APPNAME\"\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(packDir, "service.yaml"), []byte("name: {{APPNAME}}\n"), 0644))
+
+	mockCC := &createCmd{dest: "out", templateDir: templateDir}
+	d, err := mockCC.loadDeployments()
+	assert.Nil(t, err)
+	deployConfig, err := d.GetConfig("knative")
+	assert.Nil(t, err)
+	assert.Equal(t, "0.1.0", deployConfig.Version)
+	assert.Equal(t, append(append([]string{}, embeddedDeployTypes...), "knative"), deployTypeItems(d))
+
+	w := &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("knative", map[string]string{"APPNAME": "app"}, w))
+	assert.Equal(t, "name: app\n", string(w.FileMap["out/service.yaml"]))
+}
+
+func TestCreateNonInteractive(t *testing.T) {
+	prompts.SetNonInteractive(true)
+	defer prompts.SetNonInteractive(false)
+	oldFlagVariablesMap := flagVariablesMap
+	defer func() { flagVariablesMap = oldFlagVariablesMap }()
+
+	dest := t.TempDir()
+	flagVariablesMap = map[string]string{"PORT": "8080"}
+	mockCC := &createCmd{dest: dest, createConfig: &CreateConfig{}, templateWriter: &writers.FileMapWriter{FileMap: map[string][]byte{}}}
+	err := mockCC.createDeployment()
+	assert.ErrorContains(t, err, "--deploy-type is required in non-interactive mode, one of: helm, kustomize, manifests")
+
+	mockCC.deployType = "manifests"
+	err = mockCC.createDeployment()
+	var missing *prompts.MissingVariablesError
+	assert.True(t, errors.As(err, &missing))
+	assert.ErrorContains(t, err, "\n  APPNAME: ")
+	assert.ErrorContains(t, err, "pass them with --variable NAME=value")
+
+	flagVariablesMap = map[string]string{"PORT": "8080", "APPNAME": "app", "NAMESPACE": "default", "IMAGENAME": "app", "IMAGETAG": "latest", "SERVICEPORT": "80"}
+	assert.Nil(t, mockCC.createDeployment())
+
+	usesGoModules, err := mockCC.usesGoModules()
+	assert.Nil(t, err)
+	assert.False(t, usesGoModules)
+	assert.Nil(t, os.WriteFile(filepath.Join(dest, "go.mod"), []byte("module app\n"), 0644))
+	usesGoModules, err = mockCC.usesGoModules()
+	assert.Nil(t, err)
+	assert.True(t, usesGoModules)
+
+	_, err = mockCC.javaBuildTool()
+	assert.ErrorContains(t, err, "pass --language gradlew, gradle or java")
+	assert.Nil(t, os.WriteFile(filepath.Join(dest, "pom.xml"), []byte("<project/>\n"), 0644))
+	buildTool, err := mockCC.javaBuildTool()
+	assert.Nil(t, err)
+	assert.Equal(t, "maven", buildTool)
+
+	_, err = selectLanguageCandidate([]languageCandidate{{pack: "javascript", percent: 45}, {pack: "gomodule", percent: 40}}, nil)
+	assert.ErrorContains(t, err, "pass --language to choose one of javascript, gomodule")
+}
+
+func TestDetectGoModule(t *testing.T) {
+	prompts.SetNonInteractive(true)
+	defer prompts.SetNonInteractive(false)
+
+	// linguist detects both Go and Go Module, which resolve to the same pack
+	dest := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dest, "go.mod"), []byte("module app\n\ngo 1.22\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dest, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644))
+	cc := &createCmd{dest: dest, createConfig: &CreateConfig{}, repoReader: &readers.LocalFSReader{Root: dest}}
+	_, lang, err := cc.detectLanguage()
+	assert.Nil(t, err)
+	assert.Equal(t, "gomodule", lang)
+
+	merged := mergeLanguageCandidates([]languageCandidate{
+		{pack: "javascript", language: "JavaScript", percent: 40},
+		{pack: "gomodule", language: "Go", percent: 35},
+		{pack: "gomodule", language: "gomodule", percent: 25},
+	})
+	assert.Equal(t, []languageCandidate{
+		{pack: "gomodule", language: "Go", percent: 60},
+		{pack: "javascript", language: "JavaScript", percent: 40},
+	}, merged)
+}
+
+func TestDetectLanguageFromManifests(t *testing.T) {
+	prompts.SetNonInteractive(true)
+	defer prompts.SetNonInteractive(false)
+
+	dest := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dest, "package.json"), []byte(`{"name": "app"}`), 0644))
+	cc := &createCmd{dest: dest, createConfig: &CreateConfig{}, repoReader: &readers.LocalFSReader{Root: dest}}
+	_, lang, err := cc.detectLanguage()
+	assert.Nil(t, err)
+	assert.Equal(t, "javascript", lang)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dest, "Cargo.toml"), []byte("[package]\nname = \"app\"\n"), 0644))
+	cc = &createCmd{dest: dest, createConfig: &CreateConfig{}, repoReader: &readers.LocalFSReader{Root: dest}}
+	_, _, err = cc.detectLanguage()
+	assert.ErrorContains(t, err, "pass --language to choose one of javascript, rust")
+
+	dest = t.TempDir()
+	cc = &createCmd{dest: dest, createConfig: &CreateConfig{}, repoReader: &readers.LocalFSReader{Root: dest}}
+	_, _, err = cc.detectLanguage()
+	assert.ErrorIs(t, err, ErrNoLanguageDetected)
+}
+
+func TestCreateEnvironments(t *testing.T) {
+	prompts.SetNonInteractive(true)
+	defer prompts.SetNonInteractive(false)
+	oldFlagVariablesMap := flagVariablesMap
+	defer func() { flagVariablesMap = oldFlagVariablesMap }()
+
+	flagVariablesMap = map[string]string{"PORT": "8080", "APPNAME": "app", "NAMESPACE": "default", "IMAGENAME": "app", "SERVICEPORT": "80", "ENVIRONMENTS": "dev,prod", "ENVIRONMENTREPLICAS": "prod=3"}
+	w := &writers.FileMapWriter{FileMap: map[string][]byte{}}
+	mockCC := &createCmd{dest: "out", deployType: "manifests", environments: "dev,prod", createConfig: &CreateConfig{}, templateWriter: w}
+	assert.ErrorContains(t, mockCC.createDeployment(), "--environments only applies to the kustomize deployment type, not manifests")
+
+	mockCC.deployType = "kustomize"
+	assert.Nil(t, mockCC.createDeployment())
+	assert.Contains(t, w.FileMap, "out/overlays/dev/kustomization.yaml")
+	assert.Contains(t, string(w.FileMap["out/overlays/prod/deployment.yaml"]), "replicas: 3\n")
+	assert.NotContains(t, w.FileMap, "out/overlays/production/kustomization.yaml")
+}
+
+func TestCreateAutoscaling(t *testing.T) {
+	prompts.SetNonInteractive(true)
+	defer prompts.SetNonInteractive(false)
+	oldFlagVariablesMap := flagVariablesMap
+	defer func() { flagVariablesMap = oldFlagVariablesMap }()
+
+	flagVariablesMap = map[string]string{"PORT": "8080", "APPNAME": "app", "NAMESPACE": "default", "IMAGENAME": "app", "SERVICEPORT": "80", "AUTOSCALINGENABLED": "true", "AUTOSCALINGMAXREPLICAS": "4"}
+	w := &writers.FileMapWriter{FileMap: map[string][]byte{}}
+	mockCC := &createCmd{dest: "out", deployType: "manifests", autoscaling: true, createConfig: &CreateConfig{}, templateWriter: w}
+	assert.Nil(t, mockCC.createDeployment())
+	assert.Contains(t, string(w.FileMap["out/manifests/hpa.yaml"]), "maxReplicas: 4\n")
+	assert.NotContains(t, string(w.FileMap["out/manifests/deployment.yaml"]), "replicas:")
+
+	mockCC = &createCmd{dest: "out", deployType: "containerapp", autoscaling: true, createConfig: &CreateConfig{}, templateWriter: w}
+	flagVariablesMap["AZURECONTAINERREGISTRY"] = "myregistry"
+	assert.ErrorContains(t, mockCC.createDeployment(), "--autoscaling only applies to the helm, kustomize and manifests deployment types, not containerapp")
+}
+
+func TestCreateSidecars(t *testing.T) {
+	prompts.SetNonInteractive(true)
+	defer prompts.SetNonInteractive(false)
+	oldFlagVariablesMap := flagVariablesMap
+	defer func() { flagVariablesMap = oldFlagVariablesMap }()
+
+	flagVariablesMap = map[string]string{"PORT": "8080", "APPNAME": "app", "NAMESPACE": "default", "IMAGENAME": "app", "SERVICEPORT": "80", "SIDECARS": "logs=fluent/fluent-bit:3.0"}
+	w := &writers.FileMapWriter{FileMap: map[string][]byte{}}
+	mockCC := &createCmd{dest: "out", deployType: "kustomize", sidecars: []string{"logs=fluent/fluent-bit:3.0"}, createConfig: &CreateConfig{}, templateWriter: w}
+	assert.Nil(t, mockCC.createDeployment())
+	assert.Contains(t, string(w.FileMap["out/base/deployment.yaml"]), "- name: logs\n          image: fluent/fluent-bit:3.0\n")
+
+	mockCC = &createCmd{dest: "out", deployType: "containerapp", initContainers: []string{"migrate=migrate:v1"}, createConfig: &CreateConfig{}, templateWriter: w}
+	flagVariablesMap["AZURECONTAINERREGISTRY"] = "myregistry"
+	assert.ErrorContains(t, mockCC.createDeployment(), "--sidecar and --init-container only apply to the helm, kustomize and manifests deployment types, not containerapp")
+}
+
 func (mcc *createCmd) mockDetectLanguage() (*config.DraftConfig, string, error) {
 	hasGo := false
 	hasGoMod := false
@@ -237,14 +547,27 @@ func TestDefaultValues(t *testing.T) {
 	assert.Equal(t, currentDirDefaultFlagValue, ".")
 }
 
+// getAllDeploymentFiles returns the files of the deployment template at src that are rendered by default,
+// leaving out the optional files declared in its draft.yaml
 func getAllDeploymentFiles(src string) (error, []string) {
 	deploymentFiles := []string{}
-	err := filepath.Walk(src,
+	deployConfig, err := deployments.CreateDeploymentsFromEmbedFS(template.Deployments, ".").GetConfig(filepath.Base(src))
+	if err != nil {
+		return err, nil
+	}
+	err = filepath.Walk(src,
 		func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
 			filePath := strings.ReplaceAll(path, src, "./..")
+			relPath, err := filepath.Rel(src, path)
+			if err != nil {
+				return err
+			}
+			if !deployConfig.IsFileEnabled(filepath.ToSlash(relPath), map[string]string{}) {
+				return nil
+			}
 			if info.Name() != "draft.yaml" {
 				deploymentFiles = append(deploymentFiles, filePath)
 			}
@@ -252,3 +575,43 @@ func getAllDeploymentFiles(src string) (error, []string) {
 		})
 	return err, deploymentFiles
 }
+
+func TestSavedCreateConfig(t *testing.T) {
+	prompts.SetNonInteractive(true)
+	defer prompts.SetNonInteractive(false)
+	oldFlagVariablesMap := flagVariablesMap
+	defer func() { flagVariablesMap = oldFlagVariablesMap }()
+	t.Setenv(secrets.IdentityEnv, "")
+
+	dest := t.TempDir()
+	mockCC := &createCmd{dest: dest, deployType: "manifests", templateWriter: &writers.FileMapWriter{FileMap: map[string][]byte{}}}
+	assert.Nil(t, mockCC.initConfig())
+	assert.Equal(t, &CreateConfig{}, mockCC.createConfig)
+
+	flagVariablesMap = map[string]string{"PORT": "8080", "APPNAME": "app", "NAMESPACE": "default", "IMAGENAME": "app", "IMAGETAG": "latest", "SERVICEPORT": "80"}
+	assert.Nil(t, mockCC.createDeployment())
+	mockCC.saveConfig()
+	assert.FileExists(t, filepath.Join(dest, ".draft", "create-config.yaml"))
+
+	flagVariablesMap = map[string]string{}
+	rerunCC := &createCmd{dest: dest}
+	assert.Nil(t, rerunCC.initConfig())
+	assert.Equal(t, "manifests", rerunCC.createConfig.DeployType)
+	assert.Contains(t, rerunCC.createConfig.DeployVariables, UserInputs{Name: "APPNAME", Value: "app"})
+	assert.Contains(t, rerunCC.createConfig.DeployVariables, UserInputs{Name: "PORT", Value: "8080"})
+	for _, input := range rerunCC.createConfig.DeployVariables {
+		assert.NotEqual(t, DRAFT_VERSION_VARIABLE, input.Name)
+	}
+
+	otherTypeCC := &createCmd{dest: dest, deployType: "helm"}
+	assert.Nil(t, otherTypeCC.initConfig())
+	assert.Equal(t, "", otherTypeCC.createConfig.DeployType)
+	assert.Nil(t, otherTypeCC.createConfig.DeployVariables)
+
+	ignoredCC := &createCmd{dest: dest, noSavedConfig: true}
+	assert.Nil(t, ignoredCC.initConfig())
+	assert.Equal(t, &CreateConfig{}, ignoredCC.createConfig)
+
+	err := saveCreateConfig(filepath.Join(dest, "secret.yaml"), &CreateConfig{DeployVariables: []UserInputs{{Name: "DBPASSWORD", Value: "hunter2"}}}, []string{"DBPASSWORD"})
+	assert.ErrorContains(t, err, "would be saved in plain text")
+}
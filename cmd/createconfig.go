@@ -1,13 +1,148 @@
 package cmd
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/secrets"
+)
+
 type CreateConfig struct {
-	DeployType        string       `yaml:"deployType"`
-	LanguageType      string       `yaml:"languageType"`
-	DeployVariables   []UserInputs `yaml:"deployVariables"`
-	LanguageVariables []UserInputs `yaml:"languageVariables"`
+	DeployType        string       `yaml:"deployType" json:"deployType" toml:"deployType"`
+	LanguageType      string       `yaml:"languageType" json:"languageType" toml:"languageType"`
+	DeployVariables   []UserInputs `yaml:"deployVariables" json:"deployVariables" toml:"deployVariables"`
+	LanguageVariables []UserInputs `yaml:"languageVariables" json:"languageVariables" toml:"languageVariables"`
 }
 
+// savedCreateConfigPath is where draft create saves its answers for the next run, relative to the destination
+var savedCreateConfigPath = filepath.Join(".draft", "create-config.yaml")
+
 type UserInputs struct {
-	Name  string `yaml:"name"`
-	Value string `yaml:"value"`
+	Name  string `yaml:"name" json:"name" toml:"name"`
+	Value string `yaml:"value" json:"value" toml:"value"`
+}
+
+// loadCreateConfig reads a create config file. The format is detected from the file extension:
+// .json and .toml files are decoded as JSON and TOML, anything else as YAML.
+func loadCreateConfig(path string) (*CreateConfig, error) {
+	configBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg CreateConfig
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(configBytes, &cfg)
+	case ".toml":
+		err = toml.Unmarshal(configBytes, &cfg)
+	default:
+		err = yaml.Unmarshal(configBytes, &cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing create config %s: %w", path, err)
+	}
+
+	return &cfg, nil
+}
+
+// loadSavedCreateConfig loads the answers saved by a previous draft create, or an empty config when there are none
+func loadSavedCreateConfig(path string) (*CreateConfig, error) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return &CreateConfig{}, nil
+	}
+	cfg, err := loadCreateConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w, pass --no-saved-config to ignore it", err)
+	}
+	if err := cfg.revealSecrets(secrets.IdentityPath(secretsIdentity)); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// saveCreateConfig writes cfg as yaml to path, encrypting or redacting the values of secretVariables like dry run
+// files
+func saveCreateConfig(path string, cfg *CreateConfig, secretVariables []string) error {
+	saved := *cfg
+	var err error
+	if saved.LanguageVariables, err = protectUserInputs(cfg.LanguageVariables, secretVariables); err != nil {
+		return err
+	}
+	if saved.DeployVariables, err = protectUserInputs(cfg.DeployVariables, secretVariables); err != nil {
+		return err
+	}
+
+	content, err := yaml.Marshal(&saved)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+// protectUserInputs returns a copy of inputs with the secret variables encrypted, or left out with --redact
+func protectUserInputs(inputs []UserInputs, secretVariables []string) ([]UserInputs, error) {
+	if inputs == nil {
+		return nil, nil
+	}
+	values := make(map[string]string, len(inputs))
+	for _, input := range inputs {
+		values[input.Name] = input.Value
+	}
+	if err := secrets.ProtectValues(values, secretVariables, secrets.IdentityPath(secretsIdentity), redactSecrets); err != nil {
+		return nil, err
+	}
+	protected := make([]UserInputs, 0, len(inputs))
+	for _, input := range inputs {
+		if value, ok := values[input.Name]; ok {
+			protected = append(protected, UserInputs{Name: input.Name, Value: value})
+		}
+	}
+	return protected, nil
+}
+
+// newUserInputs returns the values of variables that are set in values, sorted by name
+func newUserInputs(values map[string]string, variables []config.BuilderVar) []UserInputs {
+	names := make([]string, 0, len(variables))
+	for _, variable := range variables {
+		names = append(names, variable.Name)
+	}
+	sort.Strings(names)
+	inputs := make([]UserInputs, 0, len(names))
+	for _, name := range names {
+		if value, ok := values[name]; ok {
+			inputs = append(inputs, UserInputs{Name: name, Value: value})
+		}
+	}
+	return inputs
+}
+
+// revealSecrets decrypts the encrypted variable values of the create config in place
+func (cfg *CreateConfig) revealSecrets(identityPath string) error {
+	for _, inputs := range [][]UserInputs{cfg.LanguageVariables, cfg.DeployVariables} {
+		values := make(map[string]string, len(inputs))
+		for _, input := range inputs {
+			values[input.Name] = input.Value
+		}
+		if err := secrets.RevealValues(values, identityPath); err != nil {
+			return err
+		}
+		for i := range inputs {
+			inputs[i].Value = values[inputs[i].Name]
+		}
+	}
+	return nil
 }
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/dependencies"
+	"github.com/Azure/draft/pkg/templatewriter"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+// withDependencyReport returns a TemplateWriter that also captures the generated files when --dependency-report is set,
+// along with the capturing writer to pass to writeDependencyReport
+func withDependencyReport(templateWriter templatewriter.TemplateWriter) (templatewriter.TemplateWriter, *writers.FileMapWriter) {
+	if dependencyReportFile == "" {
+		return templateWriter, nil
+	}
+	captured := &writers.FileMapWriter{}
+	return &writers.MultiWriter{Writers: []templatewriter.TemplateWriter{templateWriter, captured}}, captured
+}
+
+// writeDependencyReport writes the external dependencies of the captured files to the --dependency-report file
+func writeDependencyReport(captured *writers.FileMapWriter) error {
+	if captured == nil {
+		return nil
+	}
+
+	report, err := dependencies.NewReport(captured.FileMap, VERSION)
+	if err != nil {
+		return fmt.Errorf("creating dependency report: %w", err)
+	}
+	reportBytes, err := json.MarshalIndent(report, "", TWO_SPACES)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("writing dependency report to file %s", dependencyReportFile)
+	return os.WriteFile(dependencyReportFile, reportBytes, 0644)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/osutil"
+	"github.com/Azure/draft/pkg/prompts"
+)
+
+// checkDestination resolves a git: prefixed destination and asks for confirmation before files are written to
+// a destination outside of a git repository, the home directory or the filesystem root. In non-interactive mode such a
+// destination fails unless --skip-destination-check is passed.
+func checkDestination(dest string) (string, error) {
+	resolved, err := osutil.ResolveDestination(dest)
+	if err != nil {
+		return "", err
+	}
+	if resolved != dest {
+		log.Debugf("resolved destination %s to %s", dest, resolved)
+	}
+
+	if skipDestinationCheck {
+		return resolved, nil
+	}
+
+	reason, err := osutil.UnsafeDestinationReason(resolved)
+	if err != nil {
+		return "", err
+	}
+	if reason == "" {
+		return resolved, nil
+	}
+	if prompts.NonInteractive() {
+		return "", fmt.Errorf("the destination %s, pass --skip-destination-check to write files there in non-interactive mode", reason)
+	}
+
+	selection := &promptui.Select{
+		Label: fmt.Sprintf("The destination %s, are you sure you want to write files there?", reason),
+		Items: []string{"no", "yes"},
+	}
+	_, selectResponse, err := prompts.RunSelect(selection)
+	if err != nil {
+		return "", fmt.Errorf("confirming destination (%s), pass --skip-destination-check to skip this check: %w", reason, err)
+	}
+	if !strings.EqualFold(selectResponse, "yes") {
+		return "", fmt.Errorf("aborted writing files to unsafe destination: %s", reason)
+	}
+
+	return resolved, nil
+}
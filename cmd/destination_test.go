@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/prompts"
+)
+
+func TestCheckDestinationNonInteractive(t *testing.T) {
+	prompts.SetNonInteractive(true)
+	defer prompts.SetNonInteractive(false)
+	dest := t.TempDir()
+
+	_, err := checkDestination(dest)
+	assert.ErrorContains(t, err, "is not inside a git repository, pass --skip-destination-check")
+
+	oldSkip := skipDestinationCheck
+	skipDestinationCheck = true
+	defer func() { skipDestinationCheck = oldSkip }()
+	resolved, err := checkDestination(dest)
+	assert.Nil(t, err)
+	assert.Equal(t, dest, resolved)
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/term"
+
+	"github.com/Azure/draft/pkg/languages"
+	"github.com/Azure/draft/pkg/languages/defaults"
+	"github.com/Azure/draft/pkg/linguist"
+	"github.com/Azure/draft/pkg/progress"
+	"github.com/Azure/draft/pkg/prompts"
+)
+
+// repoDetection is what draft create reads from the repo before its first prompt: the languages linguist detects,
+// the frameworks that have their own packs and the defaults of every extractor
+type repoDetection struct {
+	langs          []*linguist.Language
+	langsErr       error
+	springBoot     bool
+	azureFunctions bool
+	defaults       *languages.ExtractedDefaults
+}
+
+// detectRepo runs language detection, framework detection and default extraction concurrently, since on large repos
+// each walks many files, drawing their overall progress on a terminal and logging how long each took
+func (cc *createCmd) detectRepo() *repoDetection {
+	var out io.Writer
+	if !prompts.NonInteractive() && !log.IsLevelEnabled(log.DebugLevel) && term.IsTerminal(int(os.Stderr.Fd())) {
+		out = os.Stderr
+	}
+	bar := progress.New(out, "Reading the repo")
+
+	d := &repoDetection{}
+	var wg sync.WaitGroup
+	run := func(name string, fn func(phase *progress.Phase)) {
+		phase := bar.Phase(name)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer phase.Done()
+			fn(phase)
+		}()
+	}
+	run("language detection", func(phase *progress.Phase) {
+		if cc.sourceReader != nil {
+			d.langs, d.langsErr = cc.detectSourceLanguages()
+			return
+		}
+		if cc.fullDetection {
+			d.langs, d.langsErr = linguist.ProcessDirWithProgress(cc.dest, phase.Update)
+			return
+		}
+		d.langs, d.langsErr = linguist.ProcessDirFast(cc.dest, phase.Update)
+	})
+	if cc.repoReader != nil {
+		run("framework detection", func(*progress.Phase) {
+			d.springBoot = defaults.IsSpringBootProject(cc.repoReader)
+			d.azureFunctions = defaults.IsAzureFunctionsProject(cc.repoReader)
+		})
+		run("default extraction", func(*progress.Phase) {
+			d.defaults = languages.ExtractAllDefaults(cc.repoReader)
+		})
+	}
+	wg.Wait()
+
+	log.Infof("--> Draft read the repo (%s)", progress.String(bar.Finish()))
+	return d
+}
+
+// repoFileExists returns whether the file name is in the root of the repo
+func (cc *createCmd) repoFileExists(name string) bool {
+	if cc.sourceReader != nil {
+		return cc.sourceReader.Exists(name)
+	}
+	_, err := os.Stat(path.Join(cc.dest, name))
+	return err == nil
+}
+
+// isSpringBootProject returns whether the repo applies the Spring Boot plugin
+func (cc *createCmd) isSpringBootProject() bool {
+	if cc.detection != nil {
+		return cc.detection.springBoot
+	}
+	return defaults.IsSpringBootProject(cc.repoReader)
+}
+
+// isAzureFunctionsProject returns whether the repo is an Azure Functions app
+func (cc *createCmd) isAzureFunctionsProject() bool {
+	if cc.detection != nil {
+		return cc.detection.azureFunctions
+	}
+	return defaults.IsAzureFunctionsProject(cc.repoReader)
+}
+
+// extractDefaults returns the defaults the extractors of lowerLang read from the repo, reusing the ones detectRepo
+// read when it ran
+func (cc *createCmd) extractDefaults(lowerLang string) (map[string]string, error) {
+	if cc.detection != nil && cc.detection.defaults != nil {
+		return cc.detection.defaults.For(lowerLang)
+	}
+	return cc.supportedLangs.ExtractDefaults(lowerLang, cc.repoReader)
+}
@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/Azure/draft/pkg/deployments"
+	dryrunpkg "github.com/Azure/draft/pkg/dryrun"
+	"github.com/Azure/draft/pkg/languages"
+	"github.com/Azure/draft/pkg/secrets"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+const (
+	driftUnchanged = "unchanged"
+	driftModified  = "modified"
+	driftMissing   = "missing"
+)
+
+type diffCmd struct {
+	dest           string
+	descriptorPath string
+	deployType     string
+	ref            string
+	flagVariables  []string
+	patch          bool
+	exitCode       bool
+}
+
+// fileDrift is the difference between a freshly rendered file and the existing copy of it
+type fileDrift struct {
+	Path    string
+	Status  string
+	Added   int
+	Removed int
+	Diff    string
+}
+
+func newDiffCmd() *cobra.Command {
+	dc := &diffCmd{}
+
+	cmd := &cobra.Command{
+		Use:   "diff [flags]",
+		Short: "Compares the files draft would generate now with the existing ones",
+		Long: `This command renders the current templates with the variables stored in a dry run descriptor
+(written by 'draft create --dry-run --dry-run-file') and summarizes how each rendered file differs from the
+copy in the working tree, or in a git ref when --ref is set.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return dc.run(cmd.OutOrStdout())
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&dc.dest, "destination", "d", currentDirDefaultFlagValue, "specify the path to the project directory")
+	f.StringVar(&dc.descriptorPath, "descriptor", emptyDefaultFlagValue, "specify the dry run json file holding the variables the files were generated with")
+	f.StringVar(&dc.deployType, "deploy-type", emptyDefaultFlagValue, "specify the deployment type, detected from the descriptor's files when not set")
+	f.StringVar(&dc.ref, "ref", emptyDefaultFlagValue, "compare against the files in this git ref instead of the working tree")
+	f.StringArrayVarP(&dc.flagVariables, "variable", "", []string{}, "override a stored variable (ex: --variable PORT=8080)")
+	f.BoolVar(&dc.patch, "patch", false, "print a unified diff of every changed file")
+	f.BoolVar(&dc.exitCode, "exit-code", false, "exit with an error when any file differs")
+
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newDiffCmd())
+}
+
+func (dc *diffCmd) run(out io.Writer) error {
+	if dc.descriptorPath == "" {
+		return errors.New("--descriptor is required")
+	}
+	descriptor, err := loadDescriptor(dc.descriptorPath)
+	if err != nil {
+		return err
+	}
+
+	variables := make(map[string]string)
+	for k, v := range descriptor.Variables {
+		variables[k] = v
+	}
+	for _, flagVar := range dc.flagVariables {
+		flagVarName, flagVarValue, ok := strings.Cut(flagVar, "=")
+		if !ok {
+			return fmt.Errorf("invalid variable format: %s", flagVar)
+		}
+		variables[flagVarName] = flagVarValue
+	}
+
+	deployType := dc.deployType
+	if deployType == "" {
+		deployType = detectDescriptorDeployType(descriptor.FilesToWrite)
+	}
+
+	rendered, err := renderFromVariables(dc.dest, variables[LANGUAGE_VARIABLE], deployType, variables)
+	if err != nil {
+		return err
+	}
+
+	drifts, err := dc.compare(rendered)
+	if err != nil {
+		return err
+	}
+
+	changed := writeDriftSummary(out, drifts, dc.patch)
+	if changed > 0 && dc.exitCode {
+		return fmt.Errorf("%d file(s) differ from the current templates", changed)
+	}
+	return nil
+}
+
+func loadDescriptor(path string) (*dryrunpkg.DryRunInfo, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading descriptor: %w", err)
+	}
+
+	var descriptor dryrunpkg.DryRunInfo
+	if err := json.Unmarshal(content, &descriptor); err != nil {
+		return nil, fmt.Errorf("parsing descriptor %s: %w", path, err)
+	}
+	if len(descriptor.Variables) == 0 {
+		return nil, fmt.Errorf("descriptor %s has no variables", path)
+	}
+	if err := secrets.RevealValues(descriptor.Variables, secrets.IdentityPath(secretsIdentity)); err != nil {
+		return nil, err
+	}
+	return &descriptor, nil
+}
+
+// detectDescriptorDeployType returns the deployment type whose files were written, or an empty string if none were
+func detectDescriptorDeployType(files []string) string {
+	for _, f := range files {
+		f = filepath.ToSlash(f)
+		switch {
+		case strings.HasSuffix(f, "charts/Chart.yaml"):
+			return "helm"
+		case strings.HasSuffix(f, "base/kustomization.yaml"):
+			return "kustomize"
+		case strings.HasSuffix(f, "manifests/deployment.yaml"):
+			return "manifests"
+		case strings.HasSuffix(f, "azure/containerapp.yaml"):
+			return "containerapp"
+		case strings.HasSuffix(f, "azure/appsettings.json"):
+			return "appservice"
+		}
+	}
+	return ""
+}
+
+// renderFromVariables renders the Dockerfile for lang and the deployment files for deployType into memory,
+// skipping either when it is empty
+func renderFromVariables(dest, lang, deployType string, variables map[string]string) (map[string][]byte, error) {
+	return renderTemplateVersions(dest, lang, deployType, nil, variables)
+}
+
+// renderTemplateVersions is renderFromVariables with the template of each artifact in templateVersions pinned to
+// its version
+func renderTemplateVersions(dest, lang, deployType string, templateVersions, variables map[string]string) (map[string][]byte, error) {
+	if lang == "" && deployType == "" {
+		return nil, errors.New("the descriptor has no LANGUAGE variable and no deployment type was found, pass --deploy-type")
+	}
+
+	fileMapWriter := &writers.FileMapWriter{}
+	if lang != "" {
+		l := languages.CreateLanguagesFromEmbedFS(template.Dockerfiles, dest)
+		if templateVersion := templateVersions[dockerfileArtifact]; templateVersion != "" {
+			if err := l.UseVersion(lang, templateVersion); err != nil {
+				return nil, err
+			}
+		}
+		if err := l.CreateDockerfileForLanguage(lang, copyVariables(variables), fileMapWriter); err != nil {
+			return nil, fmt.Errorf("rendering Dockerfile for %s: %w", lang, err)
+		}
+	}
+	if deployType != "" {
+		d := deployments.CreateDeploymentsFromEmbedFS(template.Deployments, dest)
+		if templateVersion := templateVersions[deploymentArtifact]; templateVersion != "" {
+			if err := d.UseVersion(deployType, templateVersion); err != nil {
+				return nil, err
+			}
+		}
+		if err := d.CopyDeploymentFiles(deployType, copyVariables(variables), fileMapWriter); err != nil {
+			return nil, fmt.Errorf("rendering %s deployment files: %w", deployType, err)
+		}
+	}
+	return fileMapWriter.FileMap, nil
+}
+
+func copyVariables(variables map[string]string) map[string]string {
+	c := make(map[string]string, len(variables))
+	for k, v := range variables {
+		c[k] = v
+	}
+	return c
+}
+
+// compare diffs every rendered file against the existing copy in the working tree or git ref
+func (dc *diffCmd) compare(rendered map[string][]byte) ([]fileDrift, error) {
+	paths := make([]string, 0, len(rendered))
+	for p := range rendered {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	drifts := make([]fileDrift, 0, len(paths))
+	for _, p := range paths {
+		rel, err := filepath.Rel(dc.dest, p)
+		if err != nil {
+			return nil, err
+		}
+
+		existing, found, err := dc.readExisting(rel)
+		if err != nil {
+			return nil, err
+		}
+		drift := fileDrift{Path: rel, Status: driftMissing}
+		if found {
+			drift = diffFile(rel, existing, rendered[p])
+		}
+		drifts = append(drifts, drift)
+	}
+	return drifts, nil
+}
+
+// readExisting reads rel, relative to the destination, from the working tree or from the git ref
+func (dc *diffCmd) readExisting(rel string) ([]byte, bool, error) {
+	if dc.ref == "" {
+		content, err := os.ReadFile(filepath.Join(dc.dest, rel))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+		return content, err == nil, err
+	}
+
+	// ./ makes git resolve the path relative to the destination instead of the repository root
+	gitCmd := exec.Command("git", "show", dc.ref+":./"+filepath.ToSlash(rel))
+	gitCmd.Dir = dc.dest
+	content, err := gitCmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && strings.Contains(string(exitErr.Stderr), "does not exist") {
+			return nil, false, nil
+		}
+		if exitErr != nil {
+			log.Printf("%s\n", exitErr.Stderr)
+		}
+		return nil, false, fmt.Errorf("reading %s from %s: %w", rel, dc.ref, err)
+	}
+	return content, true, nil
+}
+
+func diffFile(path string, existing, rendered []byte) fileDrift {
+	drift := fileDrift{Path: path, Status: driftUnchanged}
+	if string(existing) == string(rendered) {
+		return drift
+	}
+
+	drift.Status = driftModified
+	existingLines := difflib.SplitLines(string(existing))
+	renderedLines := difflib.SplitLines(string(rendered))
+	matcher := difflib.NewMatcher(existingLines, renderedLines)
+	for _, op := range matcher.GetOpCodes() {
+		switch op.Tag {
+		case 'r':
+			drift.Removed += op.I2 - op.I1
+			drift.Added += op.J2 - op.J1
+		case 'd':
+			drift.Removed += op.I2 - op.I1
+		case 'i':
+			drift.Added += op.J2 - op.J1
+		}
+	}
+
+	drift.Diff, _ = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        existingLines,
+		B:        renderedLines,
+		FromFile: "a/" + path,
+		ToFile:   "b/" + path,
+		Context:  3,
+	})
+	return drift
+}
+
+// writeDriftSummary prints one line per file and returns the number of files that differ
+func writeDriftSummary(out io.Writer, drifts []fileDrift, patch bool) int {
+	changed := 0
+	for _, drift := range drifts {
+		switch drift.Status {
+		case driftModified:
+			changed++
+			fmt.Fprintf(out, "%-9s %s (+%d -%d)\n", drift.Status, drift.Path, drift.Added, drift.Removed)
+		case driftMissing:
+			changed++
+			fmt.Fprintf(out, "%-9s %s\n", drift.Status, drift.Path)
+		default:
+			fmt.Fprintf(out, "%-9s %s\n", drift.Status, drift.Path)
+		}
+	}
+	fmt.Fprintf(out, "%d of %d file(s) differ from the current templates\n", changed, len(drifts))
+
+	if patch {
+		for _, drift := range drifts {
+			if drift.Diff != "" {
+				fmt.Fprint(out, "\n"+drift.Diff)
+			}
+		}
+	}
+	return changed
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	dryrunpkg "github.com/Azure/draft/pkg/dryrun"
+)
+
+func TestRunDiff(t *testing.T) {
+	dest := t.TempDir()
+	variables := map[string]string{
+		LANGUAGE_VARIABLE: "go",
+		"PORT":            "8080",
+		"APPNAME":         "testapp",
+		"SERVICEPORT":     "80",
+		"NAMESPACE":       "default",
+		"IMAGENAME":       "testimage",
+		"IMAGETAG":        "latest",
+		"VERSION":         "1.20",
+	}
+
+	rendered, err := renderFromVariables(dest, "go", "manifests", variables)
+	assert.Nil(t, err)
+	for p, content := range rendered {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.Nil(t, os.WriteFile(p, content, 0644))
+	}
+
+	descriptor, err := json.Marshal(dryrunpkg.DryRunInfo{
+		Variables:    variables,
+		FilesToWrite: []string{filepath.Join(dest, "manifests", "deployment.yaml")},
+	})
+	assert.Nil(t, err)
+	descriptorPath := filepath.Join(t.TempDir(), "dryrun.json")
+	assert.Nil(t, os.WriteFile(descriptorPath, descriptor, 0644))
+
+	dc := &diffCmd{dest: dest, descriptorPath: descriptorPath, exitCode: true}
+	var out bytes.Buffer
+	assert.Nil(t, dc.run(&out))
+	assert.Contains(t, out.String(), "0 of ")
+	assert.Contains(t, out.String(), "unchanged manifests/deployment.yaml")
+
+	assert.Nil(t, os.Remove(filepath.Join(dest, "Dockerfile")))
+	dc.flagVariables = []string{"PORT=9090"}
+	dc.patch = true
+	out.Reset()
+	assert.NotNil(t, dc.run(&out))
+	assert.Contains(t, out.String(), "missing   Dockerfile")
+	assert.Contains(t, out.String(), "modified  manifests/deployment.yaml (+1 -1)")
+	assert.Contains(t, out.String(), "+++ b/manifests/deployment.yaml")
+}
+
+func TestDetectDescriptorDeployType(t *testing.T) {
+	assert.Equal(t, "helm", detectDescriptorDeployType([]string{"Dockerfile", "charts/Chart.yaml"}))
+	assert.Equal(t, "kustomize", detectDescriptorDeployType([]string{"base/kustomization.yaml"}))
+	assert.Equal(t, "manifests", detectDescriptorDeployType([]string{"./manifests/deployment.yaml"}))
+	assert.Equal(t, "", detectDescriptorDeployType([]string{"Dockerfile"}))
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/manifoldco/promptui"
@@ -9,11 +10,13 @@ import (
 	"github.com/spf13/cobra"
 	"golang.org/x/exp/maps"
 
+	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/imagetag"
 	"github.com/Azure/draft/pkg/prompts"
+	"github.com/Azure/draft/pkg/providers"
 	"github.com/Azure/draft/pkg/templatewriter"
 	"github.com/Azure/draft/pkg/templatewriter/writers"
 	"github.com/Azure/draft/pkg/workflows"
-	"github.com/Azure/draft/template"
 )
 
 type generateWorkflowCmd struct {
@@ -22,6 +25,21 @@ type generateWorkflowCmd struct {
 	deployType     string
 	flagVariables  []string
 	templateWriter templatewriter.TemplateWriter
+	// provider is the CI provider to generate for, github, gitlab, azdo or generic
+	provider string
+
+	chartOverrides       []string
+	chartOverrideFormat  string
+	templateVersionFlags []string
+
+	createPR bool
+	prBranch string
+	prBase   string
+
+	// gitHubVariables makes the workflow read the resources it deploys to from GitHub variables, of gitHubEnvironment
+	// unless it's empty
+	gitHubVariables   bool
+	gitHubEnvironment string
 }
 
 var flagValuesMap map[string]string
@@ -31,21 +49,73 @@ func newGenerateWorkflowCmd() *cobra.Command {
 	gwCmd := &generateWorkflowCmd{}
 	gwCmd.dest = ""
 	var cmd = &cobra.Command{
-		Use:   "generate-workflow [flags]",
-		Short: "Generates a Github workflow for automatic build and deploy to AKS",
+		Use:     "generate-workflow [flags]",
+		Aliases: []string{"generate-pipeline"},
+		Short:   "Generates a Github workflow, GitLab pipeline or Azure Pipeline for automatic build and deploy to AKS, Azure Container Apps or App Service",
 		Long: `This command will generate a Github workflow to build and deploy an application containerized 
-with draft on AKS. This command assumes the 'setup-gh' command has been run properly.`,
+with draft on AKS, Azure Container Apps or Azure App Service. This command assumes the 'setup-oidc' command has been run properly.
+With --provider gitlab it generates a GitLab CI pipeline deploying to AKS or to the cluster of a KUBECONFIG CI/CD variable instead,
+and with --provider azdo an Azure Pipeline with ACR build and AKS deploy stages.
+With --provider generic it generates a Github workflow pushing to any registry with a username and password and deploying
+to any cluster with a kubeconfig, from repository secrets, for clusters on premises or of other clouds.
+With --cloud aws it generates a Github workflow pushing to Amazon ECR and deploying to EKS with an IAM role assumed through OIDC,
+and with --cloud gcp one pushing to Google Artifact Registry and deploying to GKE through Workload Identity Federation.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			flagValuesMap = make(map[string]string)
+			gwCmd.workflowConfig.Provider = gwCmd.provider
 			if cmd.Flags().NFlag() != 0 {
 				flagValuesMap = gwCmd.workflowConfig.SetFlagValuesToMap()
 			}
-			log.Info("--> Generating Github workflow")
-			if err := gwCmd.generateWorkflows(gwCmd.dest, gwCmd.deployType, gwCmd.flagVariables, gwCmd.templateWriter, flagValuesMap); err != nil {
+			dest, err := checkDestination(gwCmd.dest)
+			if err != nil {
+				return err
+			}
+			gwCmd.dest = dest
+
+			if err := gwCmd.validateProvider(); err != nil {
+				return err
+			}
+			saveHistory := usePromptHistory(gwCmd.dest)
+			defer saveHistory()
+			validators := promptValidators(gwCmd.dest)
+			if cloud := gwCmd.workflowConfig.Cloud; cloud == workflows.CloudAWS || cloud == workflows.CloudGCP {
+				if cloud == workflows.CloudAWS {
+					awsPromptValidators(validators)
+				} else {
+					gcpPromptValidators(validators)
+				}
+				// the registries and clusters of the cloud are offered unless another picker was chosen
+				if resourcePickerSource == "" {
+					if err := configureResourcePicker(cloud); err != nil {
+						return err
+					}
+				}
+			}
+			prompts.SetVariableValidators(validators)
+
+			log.Infof("--> Generating %s", gwCmd.artifactName())
+			// the image of the production deployment files is set in place, those files aren't generated again
+			isProductionDeployment := func(path string) bool { return workflows.IsProductionDeployment(gwCmd.dest, path) }
+			gwCmd.templateWriter = withOverwritePolicyExcept(withUncommittedChangesCheck(gwCmd.templateWriter, gwCmd.dest), isProductionDeployment)
+			templateVersions, err := parseTemplateVersions(gwCmd.templateVersionFlags, workflowArtifact)
+			if err != nil {
+				return err
+			}
+			if gwCmd.templateWriter, err = withPolicyMetadata(withNormalizedYAML(gwCmd.templateWriter, templateVersions[workflowArtifact])); err != nil {
+				return err
+			}
+			if gwCmd.createPR {
+				return gwCmd.generateWorkflowPullRequest(flagValuesMap)
+			}
+			templateWriter, capturedFiles := withDependencyReport(gwCmd.templateWriter)
+			if err := gwCmd.generateWorkflows(gwCmd.dest, gwCmd.deployType, gwCmd.flagVariables, templateWriter, flagValuesMap); err != nil {
+				return err
+			}
+			if err := writeDependencyReport(capturedFiles); err != nil {
 				return err
 			}
 
-			log.Info("Draft has successfully generated a Github workflow for your project 😃")
+			log.Infof("Draft has successfully generated a %s for your project 😃", gwCmd.artifactName())
 
 			return nil
 		},
@@ -55,12 +125,25 @@ with draft on AKS. This command assumes the 'setup-gh' command has been run prop
 	f.StringVarP(&gwCmd.workflowConfig.AksClusterName, "cluster-name", "c", emptyDefaultFlagValue, "specify the AKS cluster name")
 	f.StringVarP(&gwCmd.workflowConfig.AcrName, "registry-name", "r", emptyDefaultFlagValue, "specify the Azure container registry name")
 	f.StringVar(&gwCmd.workflowConfig.ContainerName, "container-name", emptyDefaultFlagValue, "specify the container image name")
-	f.StringVarP(&gwCmd.workflowConfig.ResourceGroupName, "resource-group", "g", emptyDefaultFlagValue, "specify the Azure resource group of your AKS cluster")
+	f.StringVar(&gwCmd.workflowConfig.AppName, "app-name", emptyDefaultFlagValue, "specify the Azure Container App or App Service web app name for the containerapp and appservice deployment types")
+	f.StringVarP(&gwCmd.workflowConfig.ResourceGroupName, "resource-group", "g", emptyDefaultFlagValue, "specify the Azure resource group of your AKS cluster, container app or web app")
 	f.StringVarP(&gwCmd.dest, "destination", "d", currentDirDefaultFlagValue, "specify the path to the project directory")
 	f.StringVarP(&gwCmd.workflowConfig.BranchName, "branch", "b", emptyDefaultFlagValue, "specify the Github branch to automatically deploy from")
 	f.StringVar(&gwCmd.deployType, "deploy-type", emptyDefaultFlagValue, "specify the type of deployment")
+	f.StringVar(&gwCmd.workflowConfig.Cloud, "cloud", workflows.CloudAzure, "specify the cloud to deploy to: azure, or aws or gcp to deploy to EKS or GKE with the helm, kustomize or manifests deployment types")
+	f.StringVar(&gwCmd.provider, "provider", workflows.ProviderGitHub, "specify the CI provider to generate for: github generates a Github workflow, gitlab a .gitlab-ci.yml and azdo an azure-pipelines.yml, and generic a Github workflow deploying to any registry and cluster with the credentials of repository secrets, the latter three for the helm, kustomize and manifests deployment types")
 	f.StringArrayVarP(&gwCmd.flagVariables, "variable", "", []string{}, "pass additional variables")
 	f.StringVarP(&gwCmd.workflowConfig.BuildContextPath, "build-context-path", "x", emptyDefaultFlagValue, "specify the docker build context path")
+	f.StringVar(&gwCmd.workflowConfig.RebuildSchedule, "rebuild-schedule", emptyDefaultFlagValue, "also generate a workflow that rebuilds and redeploys on this cron schedule when a base image in the Dockerfile is updated, for example \"0 6 * * 1\"")
+	f.StringVar(&gwCmd.workflowConfig.ImageTagStrategy, "image-tag-strategy", emptyDefaultFlagValue, "specify how the workflow tags the images it builds, the same as the production deployment files: "+strings.Join(imagetag.Names(), ", ")+" (defaults to "+imagetag.Default+")")
+	f.StringArrayVar(&gwCmd.chartOverrides, "chart-override", []string{}, "set a helm value in the generated helm workflow as key=value, for example image.tag=abc")
+	f.StringVar(&gwCmd.chartOverrideFormat, "chart-override-format", workflows.ChartOverrideFormatSet, "how chart overrides are applied: set passes them to helm as --set arguments, file merges them into the chart override values file")
+	f.StringArrayVar(&gwCmd.templateVersionFlags, "template-version", []string{}, "generate the workflow from a pinned template version listed by 'draft template list --versions' (ex: --template-version workflow=1.0.0)")
+	f.BoolVar(&gwCmd.createPR, "create-pr", false, "commit the workflow to a new branch and open a pull request with the gh cli instead of only writing it to disk")
+	f.StringVar(&gwCmd.prBranch, "pr-branch", "draft/generate-workflow", "specify the branch to create for --create-pr")
+	f.StringVar(&gwCmd.prBase, "pr-base", emptyDefaultFlagValue, "specify the base branch of the pull request for --create-pr (defaults to the repository default branch)")
+	f.BoolVar(&gwCmd.gitHubVariables, "github-variables", false, "read the registry, cluster and other resources the workflow deploys to from GitHub variables such as vars.CLUSTER_NAME, and write "+workflows.GitHubVariablesScriptPath+" setting them with the gh cli")
+	f.StringVar(&gwCmd.gitHubEnvironment, "github-environment", emptyDefaultFlagValue, "deploy every job of the workflow to this GitHub environment and read the variables of --github-variables from it, implies --github-variables")
 	gwCmd.templateWriter = &writers.LocalFSWriter{}
 	return cmd
 }
@@ -74,39 +157,202 @@ func (gwc *generateWorkflowCmd) generateWorkflows(dest string, deployType string
 		return fmt.Errorf("flagValuesMap is nil")
 	}
 	var err error
+	var flagVariableNames []string
 	for _, flagVar := range flagVariables {
 		flagVarName, flagVarValue, ok := strings.Cut(flagVar, "=")
 		if !ok {
 			return fmt.Errorf("invalid variable format: %s", flagVar)
 		}
 		flagValuesMap[flagVarName] = flagVarValue
-		log.Debugf("flag variable %s=%s", flagVarName, flagVarValue)
+		flagVariableNames = append(flagVariableNames, flagVarName)
+		// only the name is logged, which variables are secrets isn't known until the templates are read
+		log.Debugf("flag variable %s", flagVarName)
+	}
+
+	if schedule := flagValuesMap[workflows.RebuildScheduleVariable]; schedule != "" {
+		if err := workflows.ValidateCronSchedule(schedule); err != nil {
+			return err
+		}
+	}
+	if err := imagetag.Validate(flagValuesMap[workflows.ImageTagStrategyVariable]); err != nil {
+		return err
+	}
+
+	provider := gwc.provider
+	if provider == "" {
+		provider = workflows.ProviderGitHub
+	}
+	workflow, err := workflows.CreateWorkflowsForCloud(provider, gwc.workflowConfig.Cloud, dest)
+	if err != nil {
+		return err
 	}
 
 	if deployType == "" {
+		deployTypes := []string{"helm", "kustomize", "manifests", "containerapp", "appservice"}
+		if provider != workflows.ProviderGitHub || gwc.workflowConfig.Cloud != workflows.CloudAzure {
+			deployTypes = workflow.DeployTypes()
+			sort.Strings(deployTypes)
+		}
 		selection := &promptui.Select{
-			Label: "Select k8s Deployment Type",
-			Items: []string{"helm", "kustomize", "manifests"},
+			Label: "Select Deployment Type",
+			Items: deployTypes,
 		}
 
-		_, deployType, err = selection.Run()
+		_, deployType, err = prompts.RunSelect(selection)
 		if err != nil {
 			return err
 		}
 	}
+	templateVersions, err := parseTemplateVersions(gwc.templateVersionFlags, workflowArtifact)
+	if err != nil {
+		return err
+	}
+	if templateVersion := templateVersions[workflowArtifact]; templateVersion != "" {
+		if err := workflow.UseVersion(deployType, templateVersion); err != nil {
+			return err
+		}
+	}
+	if len(gwc.chartOverrides) > 0 {
+		chartOverrides, err := workflows.ParseChartOverrides(gwc.chartOverrides)
+		if err != nil {
+			return err
+		}
+		if err := workflow.SetChartOverrides(chartOverrides, gwc.chartOverrideFormat); err != nil {
+			return err
+		}
+	}
+	if gwc.gitHubVariables || gwc.gitHubEnvironment != "" {
+		if err := workflow.SetGitHubVariables(gwc.gitHubEnvironment); err != nil {
+			return err
+		}
+		log.Infof("Run sh %s to set the GitHub variables the workflow reads", workflows.GitHubVariablesScriptPath)
+	}
 
-	workflow := workflows.CreateWorkflowsFromEmbedFS(template.Workflows, dest)
 	workflowConfig, err := workflow.GetConfig(deployType)
 	if err != nil {
 		return fmt.Errorf("get config: %w", err)
 	}
 
-	customInputs, err := prompts.RunPromptsFromConfigWithSkips(workflowConfig, maps.Keys(flagValuesMap))
+	if strictVariables {
+		if err := config.ValidateVariableNames(flagVariableNames, workflowConfig); err != nil {
+			return fmt.Errorf("--strict-variables: %w", err)
+		}
+	}
+
+	customInputs, err := prompts.RunPromptsFromConfigWithValues(workflowConfig, flagValuesMap)
 	if err != nil {
 		return err
 	}
 
 	maps.Copy(customInputs, flagValuesMap)
 
+	gwc.deployType = deployType
 	return workflow.CreateWorkflowFiles(deployType, customInputs, templateWriter)
+}
+
+// validateProvider rejects the options that only apply to Github workflows when generating a GitLab or Azure DevOps
+// pipeline, and those that only apply to Azure when deploying to AWS, Google Cloud or with the generic workflow
+func (gwc *generateWorkflowCmd) validateProvider() error {
+	switch cloud := gwc.workflowConfig.Cloud; cloud {
+	case workflows.CloudAzure:
+	case workflows.CloudAWS, workflows.CloudGCP:
+		if gwc.provider != workflows.ProviderGitHub {
+			return fmt.Errorf("--cloud %s only generates Github workflows and can't be used with --provider %s", cloud, gwc.provider)
+		}
+		if gwc.workflowConfig.ResourceGroupName != "" || gwc.workflowConfig.AppName != "" {
+			return fmt.Errorf("--resource-group and --app-name name Azure resources and can't be used with --cloud %s", cloud)
+		}
+		if gwc.workflowConfig.RebuildSchedule != "" {
+			return fmt.Errorf("--rebuild-schedule can't be used with --cloud %s", cloud)
+		}
+	default:
+		return fmt.Errorf("invalid cloud %q, must be %s, %s or %s", cloud, workflows.CloudAzure, workflows.CloudAWS, workflows.CloudGCP)
+	}
+	if gwc.provider == workflows.ProviderGeneric {
+		if gwc.workflowConfig.AksClusterName != "" || gwc.workflowConfig.ResourceGroupName != "" || gwc.workflowConfig.AppName != "" {
+			return fmt.Errorf("--cluster-name, --resource-group and --app-name name Azure resources and can't be used with --provider %s, which deploys to the cluster of the KUBECONFIG secret", gwc.provider)
+		}
+		if gwc.workflowConfig.RebuildSchedule != "" {
+			return fmt.Errorf("--rebuild-schedule can't be used with --provider %s", gwc.provider)
+		}
+		return nil
+	}
+	if gwc.provider != workflows.ProviderGitLab && gwc.provider != workflows.ProviderAzureDevOps {
+		return nil
+	}
+	if gwc.createPR {
+		return fmt.Errorf("--create-pr opens a Github pull request and can't be used with --provider %s", gwc.provider)
+	}
+	if gwc.workflowConfig.RebuildSchedule != "" {
+		return fmt.Errorf("--rebuild-schedule can't be used with --provider %s, add a scheduled trigger to the pipeline instead", gwc.provider)
+	}
+	if gwc.gitHubVariables || gwc.gitHubEnvironment != "" {
+		return fmt.Errorf("--github-variables and --github-environment can't be used with --provider %s, use its CI/CD variables instead", gwc.provider)
+	}
+	return nil
+}
+
+// artifactName returns what generate-workflow generates for the provider, for log messages
+func (gwc *generateWorkflowCmd) artifactName() string {
+	switch gwc.provider {
+	case workflows.ProviderGitLab:
+		return "GitLab pipeline"
+	case workflows.ProviderAzureDevOps:
+		return "Azure Pipeline"
+	}
+	return "Github workflow"
+}
+
+// generateWorkflowPullRequest generates the workflow on a new branch and opens a pull request for it,
+// since pushing directly to the default branch is blocked in many organizations. When anything fails after the
+// branch is created, the original branch is checked out again and the new branch deleted.
+func (gwc *generateWorkflowCmd) generateWorkflowPullRequest(flagValuesMap map[string]string) (err error) {
+	if !providers.HasGhCli() || !providers.IsLoggedInToGh() {
+		if err := providers.LogInToGh(); err != nil {
+			return err
+		}
+	}
+
+	originalBranch, err := workflows.CreatePullRequestBranch(gwc.dest, gwc.prBranch)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if abandonErr := workflows.AbandonPullRequestBranch(gwc.dest, gwc.prBranch, originalBranch); abandonErr != nil {
+			log.Warnf("cleaning up branch %s: %s", gwc.prBranch, abandonErr)
+		}
+	}()
+
+	fileMapWriter := &writers.FileMapWriter{}
+	templateWriter := &writers.MultiWriter{Writers: []templatewriter.TemplateWriter{gwc.templateWriter, fileMapWriter}}
+	if err := gwc.generateWorkflows(gwc.dest, gwc.deployType, gwc.flagVariables, templateWriter, flagValuesMap); err != nil {
+		return err
+	}
+	if dependencyReportFile != "" {
+		if err := writeDependencyReport(fileMapWriter); err != nil {
+			return err
+		}
+	}
+
+	var extraPaths []string
+	if productionPath := workflows.ProductionDeploymentPath(gwc.deployType, gwc.dest); productionPath != "" {
+		extraPaths = append(extraPaths, productionPath)
+	}
+	prURL, err := workflows.OpenPullRequest(workflows.PullRequestOptions{
+		Dest:       gwc.dest,
+		Branch:     gwc.prBranch,
+		Base:       gwc.prBase,
+		Title:      fmt.Sprintf("Add %s deployment workflow generated by draft", gwc.deployType),
+		Files:      fileMapWriter.FileMap,
+		ExtraPaths: extraPaths,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Draft has successfully opened a pull request with a Github workflow for your project 😃 %s", prURL)
+	return nil
 }
\ No newline at end of file
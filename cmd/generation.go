@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	dryrunpkg "github.com/Azure/draft/pkg/dryrun"
+	"github.com/Azure/draft/pkg/secrets"
+)
+
+// generationManifestPath is where draft create records the variables and template versions it generated files with,
+// in the dry run descriptor format, so that later runs can merge template changes into files edited since
+var generationManifestPath = filepath.Join(".draft", "generation.json")
+
+// loadGenerationManifest loads the generation manifest of dest, returning nil when there is none
+func loadGenerationManifest(dest string) (*dryrunpkg.DryRunInfo, error) {
+	manifestPath := filepath.Join(dest, generationManifestPath)
+	if _, err := os.Stat(manifestPath); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return loadDescriptor(manifestPath)
+}
+
+// saveGenerationManifest saves generation over previous, keeping what previous recorded for the artifacts generation
+// didn't generate. Secret variables are encrypted with the --secrets-identity, or left out without one.
+func saveGenerationManifest(dest string, previous, generation *dryrunpkg.DryRunInfo, secretVariables []string) error {
+	manifest := dryrunpkg.DryRunInfo{
+		Variables:        make(map[string]string),
+		TemplateVersions: make(map[string]string),
+		FileHashes:       make(map[string]string),
+	}
+	files := make(map[string]bool)
+	for _, info := range []*dryrunpkg.DryRunInfo{previous, generation} {
+		if info == nil {
+			continue
+		}
+		for name, value := range info.Variables {
+			manifest.Variables[name] = value
+		}
+		for artifact, templateVersion := range info.TemplateVersions {
+			manifest.TemplateVersions[artifact] = templateVersion
+		}
+		for file, hash := range info.FileHashes {
+			manifest.FileHashes[file] = hash
+		}
+		for _, file := range info.FilesToWrite {
+			files[file] = true
+		}
+	}
+	for file := range files {
+		manifest.FilesToWrite = append(manifest.FilesToWrite, file)
+	}
+	sort.Strings(manifest.FilesToWrite)
+	if generation != nil {
+		// the files just written are hashed as generated, those written elsewhere such as to GitHub are skipped
+		for _, file := range generation.FilesToWrite {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				continue
+			}
+			if rel, ok := relativeToDest(dest, file); ok {
+				manifest.FileHashes[rel] = fileHash(content)
+			}
+		}
+	}
+
+	identityPath := secrets.IdentityPath(secretsIdentity)
+	if err := secrets.ProtectValues(manifest.Variables, secretVariables, identityPath, redactSecrets || identityPath == ""); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(manifest, "", TWO_SPACES)
+	if err != nil {
+		return err
+	}
+
+	manifestPath := filepath.Join(dest, generationManifestPath)
+	if err := os.MkdirAll(filepath.Dir(manifestPath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath, content, 0644)
+}
+
+// renderGeneration renders the files as the generation manifest's template versions and variables generated them,
+// keyed by cleaned path
+func renderGeneration(dest string, manifest *dryrunpkg.DryRunInfo) (map[string][]byte, error) {
+	rendered, err := renderTemplateVersions(dest, manifest.Variables[LANGUAGE_VARIABLE], detectDescriptorDeployType(manifest.FilesToWrite), manifest.TemplateVersions, manifest.Variables)
+	if err != nil {
+		return nil, fmt.Errorf("rendering the files recorded in %s: %w", generationManifestPath, err)
+	}
+	base := make(map[string][]byte, len(rendered))
+	for p, content := range rendered {
+		base[filepath.Clean(p)] = content
+	}
+	return base, nil
+}
+
+// fileHash returns the hash of content recorded in the generation manifest
+func fileHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// relativeToDest returns the slash separated path of file relative to dest, and false when file is outside of dest
+func relativeToDest(dest, file string) (string, bool) {
+	absDest, err := filepath.Abs(dest)
+	if err != nil {
+		return "", false
+	}
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(absDest, absFile)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// unchangedGeneratedFiles returns the absolute paths of the files the generation manifest of dest records, whose
+// content is still as it was generated. Its variables aren't revealed, so no secrets identity is needed.
+func unchangedGeneratedFiles(dest string) map[string]bool {
+	content, err := os.ReadFile(filepath.Join(dest, generationManifestPath))
+	if err != nil {
+		return nil
+	}
+	var manifest dryrunpkg.DryRunInfo
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil
+	}
+	unchanged := make(map[string]bool)
+	for rel, hash := range manifest.FileHashes {
+		file, err := filepath.Abs(filepath.Join(dest, filepath.FromSlash(rel)))
+		if err != nil {
+			continue
+		}
+		if content, err := os.ReadFile(file); err == nil && fileHash(content) == hash {
+			unchanged[file] = true
+		}
+	}
+	return unchanged
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	dryrunpkg "github.com/Azure/draft/pkg/dryrun"
+	"github.com/Azure/draft/pkg/prompts"
+	"github.com/Azure/draft/pkg/secrets"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+func TestCreateMergesIntoEditedFiles(t *testing.T) {
+	prompts.SetNonInteractive(true)
+	defer prompts.SetNonInteractive(false)
+	oldFlagVariablesMap := flagVariablesMap
+	defer func() { flagVariablesMap = oldFlagVariablesMap }()
+	t.Setenv(secrets.IdentityEnv, "")
+
+	dest := t.TempDir()
+	variables := map[string]string{"PORT": "8080", "APPNAME": "app", "NAMESPACE": "default", "IMAGENAME": "app", "IMAGETAG": "latest", "SERVICEPORT": "80"}
+	deploymentPath := filepath.Join(dest, "manifests", "deployment.yaml")
+	previous := &dryrunpkg.DryRunInfo{
+		Variables:        variables,
+		FilesToWrite:     []string{deploymentPath},
+		TemplateVersions: map[string]string{deploymentArtifact: "1.0.0"},
+	}
+	assert.Nil(t, saveGenerationManifest(dest, nil, previous, nil))
+
+	// the files as the previous run generated them, with an edit prepended to the deployment
+	manifest, err := loadGenerationManifest(dest)
+	assert.Nil(t, err)
+	base, err := renderGeneration(dest, manifest)
+	assert.Nil(t, err)
+	for p, content := range base {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.Nil(t, os.WriteFile(p, content, 0644))
+	}
+	edited := "# owned by team-a\n" + string(base[deploymentPath])
+	assert.Nil(t, os.WriteFile(deploymentPath, []byte(edited), 0644))
+
+	files := &writers.FileMapWriter{FileMap: map[string][]byte{}}
+	cc := &createCmd{dest: dest, deployType: "manifests", merge: true, noSavedConfig: true, previousGeneration: manifest}
+	cc.mergeWriter = &writers.MergeWriter{Writer: files}
+	cc.templateWriter = cc.mergeWriter
+	assert.Nil(t, cc.initConfig())
+	flagVariablesMap = variables
+	assert.Nil(t, cc.withMerge(true, cc.createDeployment))
+
+	latestVariables := copyVariables(variables)
+	latestVariables[DRAFT_VERSION_VARIABLE] = VERSION
+	latest, err := renderFromVariables(dest, "", "manifests", latestVariables)
+	assert.Nil(t, err)
+	assert.NotEqual(t, string(base[deploymentPath]), string(latest[deploymentPath]))
+	assert.Equal(t, "# owned by team-a\n"+string(latest[deploymentPath]), string(files.FileMap[deploymentPath]))
+	assert.Empty(t, cc.mergeWriter.Conflicted)
+	assert.Nil(t, cc.mergeWriter.Base)
+
+	assert.Nil(t, saveGenerationManifest(dest, manifest, &dryrunpkg.DryRunInfo{
+		Variables:        map[string]string{LANGUAGE_VARIABLE: "go"},
+		TemplateVersions: map[string]string{dockerfileArtifact: "1.0.0"},
+	}, nil))
+	saved, err := loadGenerationManifest(dest)
+	assert.Nil(t, err)
+	assert.Equal(t, "go", saved.Variables[LANGUAGE_VARIABLE])
+	assert.Equal(t, "app", saved.Variables["APPNAME"])
+	assert.Equal(t, map[string]string{dockerfileArtifact: "1.0.0", deploymentArtifact: "1.0.0"}, saved.TemplateVersions)
+}
+
+func TestUnchangedGeneratedFiles(t *testing.T) {
+	dest := t.TempDir()
+	dockerfile, deployment := filepath.Join(dest, "Dockerfile"), filepath.Join(dest, "manifests", "deployment.yaml")
+	assert.Nil(t, os.MkdirAll(filepath.Dir(deployment), 0755))
+	assert.Nil(t, os.WriteFile(dockerfile, []byte("FROM golang\n"), 0644))
+	assert.Nil(t, os.WriteFile(deployment, []byte("kind: Deployment\n"), 0644))
+	assert.Nil(t, saveGenerationManifest(dest, nil, &dryrunpkg.DryRunInfo{
+		Variables:    map[string]string{"APPNAME": "app"},
+		FilesToWrite: []string{dockerfile, deployment},
+	}, nil))
+
+	manifest, err := loadGenerationManifest(dest)
+	assert.Nil(t, err)
+	assert.Len(t, manifest.FileHashes, 2)
+	assert.Contains(t, manifest.FileHashes, "manifests/deployment.yaml")
+
+	assert.Nil(t, os.WriteFile(deployment, []byte("kind: Deployment\n# edited\n"), 0644))
+	absDockerfile, err := filepath.Abs(dockerfile)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]bool{absDockerfile: true}, unchangedGeneratedFiles(dest))
+
+	// a later run generating other files keeps the hashes of the earlier ones
+	assert.Nil(t, saveGenerationManifest(dest, manifest, &dryrunpkg.DryRunInfo{}, nil))
+	assert.Equal(t, map[string]bool{absDockerfile: true}, unchangedGeneratedFiles(dest))
+}
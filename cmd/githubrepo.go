@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/githubrepo"
+	"github.com/Azure/draft/pkg/linguist"
+	"github.com/Azure/draft/pkg/reporeader/readers"
+)
+
+// githubRepoCommitMessage is the message of the commit draft create --github-repo makes
+const githubRepoCommitMessage = "Add the Dockerfile and deployment files generated by draft"
+
+// newGitHubRepoClient is replaced in tests
+var newGitHubRepoClient = githubrepo.NewClient
+
+// useGitHubRepo generates into an empty temporary directory instead of a local clone of --github-repo, so that
+// nothing is read from or written to the working directory, and returns the function removing it
+func (cc *createCmd) useGitHubRepo() (func(), error) {
+	if err := githubrepo.ValidateRepo(cc.githubRepo); err != nil {
+		return nil, fmt.Errorf("--github-repo: %w", err)
+	}
+	if cc.githubBranch == "" {
+		return nil, errors.New("--github-branch cannot be empty")
+	}
+	if cc.merge {
+		return nil, errors.New("--merge needs a local clone and can't be used with --github-repo")
+	}
+	if cc.patch {
+		return nil, errors.New("--patch needs a local clone and can't be used with --github-repo")
+	}
+
+	dir, err := os.MkdirTemp("", "draft-github-repo")
+	if err != nil {
+		return nil, err
+	}
+	cc.dest = dir
+	// the repository's files are neither read for detection nor for the answers of a previous run
+	cc.skipFileDetection = true
+	cc.noSavedConfig = true
+	return func() {
+		if err := os.RemoveAll(dir); err != nil {
+			log.Debugf("removing %s: %s", dir, err)
+		}
+	}, nil
+}
+
+// validateGitHubRepoLanguage checks the language is given, since it can't be detected without a local clone unless
+// the repository is read with --source
+func (cc *createCmd) validateGitHubRepoLanguage() error {
+	if cc.sourceReader != nil || cc.createConfig.LanguageType != "" || (cc.lang != "" && !strings.Contains(cc.lang, ",")) {
+		return nil
+	}
+	return errors.New("--github-repo can't detect the language of the repository, pass it with --language, in a --create-config file, or read the repository with --source")
+}
+
+// commitToGitHubRepo commits the generated files, relative to the repository root, to --github-branch
+func (cc *createCmd) commitToGitHubRepo() error {
+	files := make(map[string][]byte, len(cc.githubFiles.FileMap))
+	for p, content := range cc.githubFiles.FileMap {
+		rel, err := filepath.Rel(cc.dest, p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = content
+	}
+
+	sha, err := newGitHubRepoClient().Commit(githubrepo.CommitOptions{
+		Repo:    cc.githubRepo,
+		Branch:  cc.githubBranch,
+		Base:    cc.githubBase,
+		Message: githubRepoCommitMessage,
+		Files:   files,
+	})
+	if err != nil {
+		return fmt.Errorf("committing to %s: %w", cc.githubRepo, err)
+	}
+	log.Infof("--> Committed %d files to branch %s of %s (%s)", len(files), cc.githubBranch, cc.githubRepo, sha)
+	return nil
+}
+
+// githubRepoReader reads the files of --github-repo the commit replaces, those of --github-branch or of the branch it
+// is created from when it doesn't exist yet
+func (cc *createCmd) githubRepoReader() *readers.GitHubReader {
+	reader := &readers.GitHubReader{Repo: cc.githubRepo, Ref: cc.githubBranch, Client: newGitHubRepoClient()}
+	if _, err := reader.Files(); err != nil {
+		log.Debugf("reading branch %s of %s, reading the branch it is created from instead: %s", cc.githubBranch, cc.githubRepo, err)
+		return &readers.GitHubReader{Repo: cc.githubRepo, Ref: cc.githubBase, Client: newGitHubRepoClient()}
+	}
+	return reader
+}
+
+// useSource reads the repository of --source, given as github.com/OWNER/REPO with an optional @REF, through the GitHub
+// API to detect its language and the defaults of its variables, instead of reading the destination
+func (cc *createCmd) useSource() error {
+	source := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(cc.source, "https://"), "http://"), "/")
+	repo, ok := strings.CutPrefix(source, "github.com/")
+	if !ok {
+		return fmt.Errorf("--source: invalid source %q, must be github.com/OWNER/REPO", cc.source)
+	}
+	repo, ref, _ := strings.Cut(repo, "@")
+	repo = strings.TrimSuffix(repo, ".git")
+	if err := githubrepo.ValidateRepo(repo); err != nil {
+		return fmt.Errorf("--source: %w", err)
+	}
+
+	cc.sourceReader = &readers.GitHubReader{Repo: repo, Ref: ref, Client: newGitHubRepoClient()}
+	// the destination isn't the repository, so its files are not the answers of a previous run
+	cc.noSavedConfig = true
+	return nil
+}
+
+// detectSourceLanguages detects the languages of the --source repository from its file tree, reading only the files
+// whose language can't be told from their name
+func (cc *createCmd) detectSourceLanguages() ([]*linguist.Language, error) {
+	tree, err := cc.sourceReader.Files()
+	if err != nil {
+		return nil, err
+	}
+	files := make([]linguist.File, len(tree))
+	for i, file := range tree {
+		files[i] = linguist.File{Path: file.Path, Size: file.Size}
+	}
+	return linguist.ProcessFiles(files, cc.sourceReader.ReadFile)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/githubrepo"
+	"github.com/Azure/draft/pkg/prompts"
+)
+
+func TestCreateGitHubRepo(t *testing.T) {
+	defer prompts.SetNonInteractive(false)
+	oldOverwritePolicy := overwritePolicy
+	defer func() { overwritePolicy = oldOverwritePolicy }()
+	oldFlagVariablesMap := flagVariablesMap
+	defer func() { flagVariablesMap = oldFlagVariablesMap }()
+	flagVariablesMap = map[string]string{}
+
+	var tree map[string]interface{}
+	newGitHubRepoClient = func() *githubrepo.Client {
+		return &githubrepo.Client{Run: func(stdin []byte, args ...string) ([]byte, error) {
+			switch args[2] + " " + args[3] {
+			case "GET repos/owner/app/git/ref/heads/draft":
+				return []byte(`{"object": {"sha": "head"}}`), nil
+			case "POST repos/owner/app/git/trees":
+				assert.Nil(t, json.Unmarshal(stdin, &tree))
+			}
+			return []byte(`{"sha": "commit", "tree": {"sha": "tree"}}`), nil
+		}}
+	}
+	defer func() { newGitHubRepoClient = githubrepo.NewClient }()
+
+	cc := &createCmd{githubRepo: "owner/app", githubBranch: "draft", deployType: "manifests", nonInteractive: true,
+		flagVariables: []string{"APPNAME=app", "NAMESPACE=default", "IMAGENAME=app", "SERVICEPORT=80", "PORT=80", "VERSION=1.22"}}
+	cleanup, err := cc.useGitHubRepo()
+	assert.Nil(t, err)
+	dir := cc.dest
+	assert.Nil(t, cc.initConfig())
+	assert.ErrorContains(t, cc.validateGitHubRepoLanguage(), "pass it with --language")
+	cc.lang = "go"
+	assert.Nil(t, cc.validateGitHubRepoLanguage())
+	assert.Nil(t, cc.run())
+
+	paths := map[string]bool{}
+	for _, entry := range tree["tree"].([]interface{}) {
+		paths[entry.(map[string]interface{})["path"].(string)] = true
+	}
+	assert.True(t, paths["Dockerfile"])
+	assert.True(t, paths["manifests/deployment.yaml"])
+	entries, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	for _, entry := range entries {
+		assert.NotEqual(t, "Dockerfile", entry.Name(), "nothing is written to the temporary destination")
+	}
+
+	cleanup()
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = (&createCmd{githubRepo: "app", githubBranch: "draft"}).useGitHubRepo()
+	assert.ErrorContains(t, err, "--github-repo: invalid GitHub repository")
+	_, err = (&createCmd{githubRepo: "owner/app", githubBranch: "draft", merge: true}).useGitHubRepo()
+	assert.ErrorContains(t, err, "--merge needs a local clone")
+}
+
+func TestCreateSource(t *testing.T) {
+	newGitHubRepoClient = func() *githubrepo.Client {
+		return &githubrepo.Client{Run: func(stdin []byte, args ...string) ([]byte, error) {
+			switch args[3] {
+			case "repos/org/app/git/trees/v2?recursive=1":
+				return []byte(`{"tree": [{"path": "go.mod", "type": "blob", "size": 20}, {"path": "main.go", "type": "blob", "size": 400}]}`), nil
+			}
+			return nil, errors.New("gh: Not Found (HTTP 404)")
+		}}
+	}
+	defer func() { newGitHubRepoClient = githubrepo.NewClient }()
+
+	cc := &createCmd{source: "https://github.com/org/app.git@v2"}
+	assert.Nil(t, cc.useSource())
+	assert.Equal(t, "org/app", cc.sourceReader.Repo)
+	assert.Equal(t, "v2", cc.sourceReader.Ref)
+	assert.True(t, cc.noSavedConfig)
+
+	langs, err := cc.detectSourceLanguages()
+	assert.Nil(t, err)
+	assert.Equal(t, "Go", langs[0].Language)
+	assert.True(t, cc.repoFileExists("go.mod"))
+	assert.False(t, cc.repoFileExists("pom.xml"))
+	assert.Nil(t, cc.validateGitHubRepoLanguage(), "the language of the source is detected")
+
+	assert.ErrorContains(t, (&createCmd{source: "gitlab.com/org/app"}).useSource(), "must be github.com/OWNER/REPO")
+	assert.ErrorContains(t, (&createCmd{source: "github.com/app"}).useSource(), "--source: invalid GitHub repository")
+}
+
+func TestGitHubRepoSummary(t *testing.T) {
+	newGitHubRepoClient = func() *githubrepo.Client {
+		return &githubrepo.Client{Run: func(stdin []byte, args ...string) ([]byte, error) {
+			switch args[3] {
+			case "repos/owner/app/git/trees/main?recursive=1":
+				return []byte(`{"tree": [{"path": "Dockerfile", "type": "blob", "size": 20}]}`), nil
+			}
+			return nil, errors.New("gh: Not Found (HTTP 404)")
+		}}
+	}
+	defer func() { newGitHubRepoClient = githubrepo.NewClient }()
+
+	// the branch doesn't exist yet, so the files it is created from are replaced
+	cc := &createCmd{githubRepo: "owner/app", githubBranch: "draft", githubBase: "main", dest: t.TempDir()}
+	reader := cc.githubRepoReader()
+	assert.Equal(t, "main", reader.Ref)
+
+	var out bytes.Buffer
+	assert.Nil(t, writeSummary(&out, cc.dest, nil, nil,
+		[]string{filepath.Join(cc.dest, "Dockerfile"), filepath.Join(cc.dest, ".dockerignore")}, cc.summaryReader()))
+	assert.Equal(t, `FILE           ACTION
+Dockerfile     overwrite
+.dockerignore  create
+`, out.String())
+}
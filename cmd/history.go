@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/prompts"
+)
+
+// promptHistoryPath is where the values answered to the prompts are kept for the next runs, relative to the destination
+var promptHistoryPath = filepath.Join(".draft", "history.yaml")
+
+// usePromptHistory offers the values previously answered to the prompts for the project in dest as choices, unless
+// --no-history is set. The returned func saves the values answered in this run, except in dry runs.
+func usePromptHistory(dest string) func() {
+	if noHistory {
+		prompts.SetHistory(nil)
+		return func() {}
+	}
+
+	path := filepath.Join(dest, promptHistoryPath)
+	h, err := prompts.LoadHistory(path)
+	if err != nil {
+		log.Warnf("not offering previously used values: %s", err)
+		return func() {}
+	}
+	prompts.SetHistory(h)
+	return func() {
+		prompts.SetHistory(nil)
+		if dryRun {
+			return
+		}
+		if err := h.Save(path); err != nil {
+			log.Warnf("not saving the used values to %s: %s", path, err)
+		}
+	}
+}
@@ -3,21 +3,20 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/Azure/draft/pkg/config"
 	"github.com/Azure/draft/pkg/deployments"
 	"github.com/Azure/draft/pkg/languages"
+	"github.com/Azure/draft/pkg/reports"
 	"github.com/Azure/draft/template"
 )
 
-type Format string
-
-const (
-	JSON Format = "json"
-)
-
 type infoCmd struct {
 	format string
 	info   *draftInfo
@@ -27,12 +26,62 @@ type infoCmd struct {
 type draftConfigInfo struct {
 	Name                  string              `json:"name"`
 	DisplayName           string              `json:"displayName,omitempty"`
+	Version               string              `json:"version,omitempty"`
 	VariableExampleValues map[string][]string `json:"variableExampleValues,omitempty"`
+	Variables             []variableInfo      `json:"variables,omitempty"`
+}
+
+// variableInfo describes a variable of a draft.yaml and its default, for tools building their own prompts
+type variableInfo struct {
+	Name           string   `json:"name"`
+	Description    string   `json:"description,omitempty"`
+	Type           string   `json:"type,omitempty"`
+	Stage          string   `json:"stage,omitempty"`
+	Default        *string  `json:"default,omitempty"`
+	DefaultFrom    string   `json:"defaultFrom,omitempty"`
+	PromptDisabled bool     `json:"promptDisabled,omitempty"`
+	AllowedValues  []string `json:"allowedValues,omitempty"`
+	Min            *int     `json:"min,omitempty"`
+	Max            *int     `json:"max,omitempty"`
+	Secret         bool     `json:"secret,omitempty"`
 }
 
 type draftInfo struct {
 	SupportedLanguages       []draftConfigInfo `json:"supportedLanguages"`
 	SupportedDeploymentTypes []string          `json:"supportedDeploymentTypes"`
+	DeploymentTypes          []draftConfigInfo `json:"deploymentTypes"`
+}
+
+// newDraftConfigInfo describes the draft.yaml of the language or deployment type name
+func newDraftConfigInfo(name string, draftConfig *config.DraftConfig) draftConfigInfo {
+	info := draftConfigInfo{
+		Name:                  name,
+		DisplayName:           draftConfig.DisplayName,
+		Version:               draftConfig.Version,
+		VariableExampleValues: draftConfig.GetVariableExampleValues(),
+	}
+	for _, variable := range draftConfig.Variables {
+		v := variableInfo{
+			Name:          variable.Name,
+			Description:   variable.Description,
+			Type:          variable.VarType,
+			Stage:         variable.Stage,
+			AllowedValues: variable.AllowedValues,
+			Min:           variable.Min,
+			Max:           variable.Max,
+			Secret:        variable.VarType == "secret",
+		}
+		for _, variableDefault := range draftConfig.VariableDefaults {
+			if variableDefault.Name == variable.Name {
+				value := variableDefault.Value
+				v.Default = &value
+				v.DefaultFrom = variableDefault.ReferenceVar
+				v.PromptDisabled = variableDefault.IsPromptDisabled
+			}
+		}
+		info.Variables = append(info.Variables, v)
+	}
+	return info
 }
 
 func newInfoCmd() *cobra.Command {
@@ -40,49 +89,90 @@ func newInfoCmd() *cobra.Command {
 	var cmd = &cobra.Command{
 		Use:   "info",
 		Short: "Prints draft supported values in machine-readable format",
-		Long:  `This command prints information about the current draft environment and supported values such as supported dockerfile languages and deployment manifest types.`,
+		Long:  `This command prints information about the current draft environment and supported values such as supported dockerfile languages and deployment manifest types, with their variables and defaults.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := ic.run(); err != nil {
+			if err := ic.run(cmd.OutOrStdout()); err != nil {
 				return err
 			}
 			return nil
 		},
 	}
 	f := cmd.Flags()
-	f.StringVarP(&ic.format, "format", "f", ".", "specify the format to print draft information in (json, yaml, etc)")
+	f.StringVarP(&ic.format, "format", "f", ".", "specify the format to print draft information in (json or markdown)")
 
 	return cmd
 }
 
-func (ic *infoCmd) run() error {
+func (ic *infoCmd) run(out io.Writer) error {
 	log.Debugf("getting supported languages")
 	l := languages.CreateLanguagesFromEmbedFS(template.Dockerfiles, "")
 	d := deployments.CreateDeploymentsFromEmbedFS(template.Deployments, "")
 
 	languagesInfo := make([]draftConfigInfo, 0)
 	for _, lang := range l.Names() {
-		langConfig := l.GetConfig(lang)
-		newConfig := draftConfigInfo{
-			Name:                  lang,
-			DisplayName:           langConfig.DisplayName,
-			VariableExampleValues: langConfig.GetVariableExampleValues(),
+		languagesInfo = append(languagesInfo, newDraftConfigInfo(lang, l.GetConfig(lang)))
+	}
+
+	deployTypesInfo := make([]draftConfigInfo, 0)
+	for _, deployType := range d.DeployTypes() {
+		deployConfig, err := d.GetConfig(deployType)
+		if err != nil {
+			return fmt.Errorf("getting the config of deployment type %s: %w", deployType, err)
 		}
-		languagesInfo = append(languagesInfo, newConfig)
+		deployTypesInfo = append(deployTypesInfo, newDraftConfigInfo(deployType, deployConfig))
 	}
 
 	ic.info = &draftInfo{
 		SupportedLanguages:       languagesInfo,
 		SupportedDeploymentTypes: d.DeployTypes(),
+		DeploymentTypes:          deployTypesInfo,
+	}
+
+	switch reports.Format(strings.ToLower(ic.format)) {
+	case reports.FormatMarkdown:
+		return ic.info.writeMarkdown(out)
+	case reports.FormatSARIF:
+		return fmt.Errorf("draft info has no findings to report in sarif format, use json or markdown")
 	}
 
 	infoText, err := json.MarshalIndent(ic.info, "", "  ")
 	if err != nil {
 		return fmt.Errorf("could not marshal draft info into json: %w", err)
 	}
-	fmt.Println(string(infoText))
+	fmt.Fprintln(out, string(infoText))
 	return nil
 }
 
+// writeMarkdown writes the supported languages and deployment types as markdown tables for docs
+func (di *draftInfo) writeMarkdown(out io.Writer) error {
+	languagesTable := reports.MarkdownTable{Headers: []string{"Language", "Display Name", "Variable Example Values"}}
+	for _, lang := range di.SupportedLanguages {
+		variableNames := make([]string, 0, len(lang.VariableExampleValues))
+		for name := range lang.VariableExampleValues {
+			variableNames = append(variableNames, name)
+		}
+		sort.Strings(variableNames)
+
+		examples := make([]string, len(variableNames))
+		for i, name := range variableNames {
+			examples[i] = fmt.Sprintf("%s: %s", name, strings.Join(lang.VariableExampleValues[name], ", "))
+		}
+		languagesTable.Rows = append(languagesTable.Rows, []string{lang.Name, lang.DisplayName, strings.Join(examples, "\n")})
+	}
+
+	deployTypesTable := reports.MarkdownTable{Headers: []string{"Deployment Type"}}
+	for _, deployType := range di.SupportedDeploymentTypes {
+		deployTypesTable.Rows = append(deployTypesTable.Rows, []string{deployType})
+	}
+
+	fmt.Fprint(out, "## Supported Languages\n\n")
+	if err := reports.WriteMarkdownTable(out, languagesTable); err != nil {
+		return err
+	}
+	fmt.Fprint(out, "\n## Supported Deployment Types\n\n")
+	return reports.WriteMarkdownTable(out, deployTypesTable)
+}
+
 func init() {
 	rootCmd.AddCommand(newInfoCmd())
 }
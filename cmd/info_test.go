@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInfoFormats(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ic := &infoCmd{format: "."}
+	assert.Nil(t, ic.run(buf))
+	var info draftInfo
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &info))
+	assert.Contains(t, info.SupportedDeploymentTypes, "helm")
+	assert.Len(t, info.DeploymentTypes, len(info.SupportedDeploymentTypes))
+	for _, lang := range info.SupportedLanguages {
+		if lang.Name != "go" {
+			continue
+		}
+		assert.NotEmpty(t, lang.Version)
+		for _, variable := range lang.Variables {
+			if variable.Name == "HARDENED" {
+				assert.Equal(t, "bool", variable.Type)
+				assert.Equal(t, "true", *variable.Default)
+			}
+		}
+	}
+
+	buf.Reset()
+	ic = &infoCmd{format: "markdown"}
+	assert.Nil(t, ic.run(buf))
+	assert.Contains(t, buf.String(), "## Supported Languages\n\n| Language | Display Name | Variable Example Values |\n")
+	assert.Contains(t, buf.String(), "| helm |\n")
+
+	ic = &infoCmd{format: "sarif"}
+	assert.NotNil(t, ic.run(buf))
+}
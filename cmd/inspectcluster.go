@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/clusterinfo"
+	"github.com/Azure/draft/pkg/config"
+)
+
+// inspectClusterDefaults inspects the cluster of the current kubeconfig context for --inspect-cluster and returns the
+// template variable defaults tailored to it
+func inspectClusterDefaults() (map[string]string, error) {
+	log.Info("--> Inspecting the cluster of the current kubeconfig context...")
+	info, err := clusterinfo.InspectCurrentContext(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("--inspect-cluster: %w", err)
+	}
+
+	log.Infof("--> Cluster %s: ingress classes [%s], storage classes [%s], gateway classes [%s], cert-manager installed: %t",
+		info.Context, classNames(info.IngressClasses), classNames(info.StorageClasses), classNames(info.GatewayClasses), info.CertManager)
+	return info.Defaults(), nil
+}
+
+func classNames(classes []clusterinfo.Class) string {
+	names := make([]string, len(classes))
+	for i, c := range classes {
+		names[i] = c.Name
+		if c.Default {
+			names[i] += " (default)"
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// applyClusterDefaults sets the defaults found by --inspect-cluster on the variables draftConfig defines
+func applyClusterDefaults(draftConfig *config.DraftConfig, clusterDefaults map[string]string) {
+	names := make([]string, 0, len(clusterDefaults))
+	for name := range clusterDefaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if draftConfig.SetVariableDefault(name, clusterDefaults[name]) {
+			log.Debugf("defaulting %s to %s from the inspected cluster", name, clusterDefaults[name])
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Azure/draft/pkg/scaffold"
+	"github.com/Azure/draft/pkg/templatewriter"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+type languagesAddCmd struct {
+	templateDir  string
+	displayName  string
+	deployment   bool
+	goldenDir    string
+	extractorDir string
+
+	templateWriter templatewriter.TemplateWriter
+}
+
+func newLanguagesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "languages",
+		Short: "Helps contributing language and deployment packs",
+	}
+	cmd.AddCommand(newLanguagesAddCmd())
+	return cmd
+}
+
+func newLanguagesAddCmd() *cobra.Command {
+	la := &languagesAddCmd{}
+	cmd := &cobra.Command{
+		Use:   "add NAME [flags]",
+		Short: "Scaffolds a new language or deployment pack",
+		Long: `This command scaffolds the skeleton of a new language pack, or of a deployment pack with --deployment, in a
+template directory laid out like draft's: a draft.yaml with typed variables and template files using them, to fill in
+where marked TODO. It also writes golden files rendered from the pack's defaults, compared to the pack's output by
+the golden test of draft's template directory, and with --extractor-dir a stub extractor reading the pack's variables
+from the repo, with its test.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return la.run(args[0], cmd.OutOrStdout())
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&la.templateDir, "template-dir", currentDirDefaultFlagValue, "specify the template directory to add the pack to (ex: ./template in a clone of draft)")
+	f.StringVar(&la.displayName, "display-name", emptyDefaultFlagValue, "specify the display name of a language pack, derived from its name when not set")
+	f.BoolVar(&la.deployment, "deployment", false, "scaffold a deployment pack instead of a language pack")
+	f.StringVar(&la.goldenDir, "golden-dir", emptyDefaultFlagValue, "specify the directory of the golden files, testdata/golden in the template directory when not set")
+	f.StringVar(&la.extractorDir, "extractor-dir", emptyDefaultFlagValue, "also scaffold an extractor for the language pack in this Go package directory (ex: ./pkg/languages/defaults)")
+
+	la.templateWriter = &writers.LocalFSWriter{}
+
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newLanguagesCmd())
+}
+
+func (la *languagesAddCmd) run(name string, out io.Writer) error {
+	if err := scaffold.ValidateName(name); err != nil {
+		return err
+	}
+	if la.deployment && la.extractorDir != "" {
+		return errors.New("--extractor-dir only applies to language packs")
+	}
+	displayName := la.displayName
+	if displayName == "" {
+		displayName = scaffold.DisplayName(name)
+	}
+
+	packsDir, scaffoldPack, render := scaffold.LanguagesDir, scaffold.LanguagePack, scaffold.RenderLanguageGolden
+	if la.deployment {
+		packsDir, scaffoldPack, render = scaffold.DeploymentsDir, scaffold.DeploymentPack, scaffold.RenderDeploymentGolden
+	}
+	packDir := filepath.Join(la.templateDir, packsDir, name)
+	if _, err := os.Stat(packDir); err == nil {
+		return fmt.Errorf("pack %s already exists", packDir)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	packFiles, err := scaffoldPack(name, displayName)
+	if err != nil {
+		return err
+	}
+	if err := la.writeFiles(la.templateDir, packFiles, out); err != nil {
+		return err
+	}
+
+	// the golden files are rendered from the pack just written, so they start out matching it
+	golden, err := render(os.DirFS(la.templateDir), name)
+	if err != nil {
+		return fmt.Errorf("rendering the golden files of %s: %w", name, err)
+	}
+	goldenDir := la.goldenDir
+	if goldenDir == "" {
+		goldenDir = filepath.Join(la.templateDir, "testdata", "golden")
+	}
+	if err := la.writeFiles(filepath.Join(goldenDir, packsDir, name), golden, out); err != nil {
+		return err
+	}
+
+	if la.extractorDir != "" {
+		extractorFiles, err := scaffold.Extractor(name, filepath.Base(filepath.Clean(la.extractorDir)))
+		if err != nil {
+			return err
+		}
+		if err := la.writeFiles(la.extractorDir, extractorFiles, out); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Register the extractor in the extractors of pkg/languages for draft create to use it\n")
+	}
+
+	fmt.Fprintf(out, "Fill in the TODOs of %s, then rerun the golden test with -update to regenerate its golden files\n", packDir)
+	return nil
+}
+
+// writeFiles writes files, keyed by slash separated paths, under dir in a stable order
+func (la *languagesAddCmd) writeFiles(dir string, files map[string][]byte, out io.Writer) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := withOverwritePolicy(la.templateWriter)
+	for _, name := range names {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		if err := w.EnsureDirectory(filepath.Dir(filePath)); err != nil {
+			return err
+		}
+		if err := w.WriteFile(filePath, files[name]); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Wrote %s\n", filePath)
+	}
+	return nil
+}
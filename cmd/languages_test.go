@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/languages"
+)
+
+func TestRunLanguagesAdd(t *testing.T) {
+	templateDir := t.TempDir()
+	extractorDir := filepath.Join(t.TempDir(), "defaults")
+
+	la := newLanguagesAddCmd()
+	assert.Nil(t, la.Flags().Set("template-dir", templateDir))
+	assert.Nil(t, la.Flags().Set("extractor-dir", extractorDir))
+	var out bytes.Buffer
+	la.SetOut(&out)
+	assert.Nil(t, la.RunE(la, []string{"my-lang"}))
+
+	l, err := languages.CreateLanguagesFromFS(os.DirFS(templateDir), "")
+	assert.Nil(t, err)
+	assert.True(t, l.ContainsLanguage("my-lang"))
+	assert.Equal(t, "My Lang", l.GetConfig("my-lang").DisplayName)
+
+	golden, err := os.ReadFile(filepath.Join(templateDir, "testdata", "golden", "dockerfiles", "my-lang", "Dockerfile"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(golden), "FROM my-lang:latest AS runtime\n")
+	assert.Contains(t, string(golden), "EXPOSE 80\n")
+
+	for _, name := range []string{"mylang.go", "mylang_test.go"} {
+		file, err := parser.ParseFile(token.NewFileSet(), filepath.Join(extractorDir, name), nil, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, "defaults", file.Name.Name)
+	}
+
+	la = newLanguagesAddCmd()
+	assert.Nil(t, la.Flags().Set("template-dir", templateDir))
+	assert.ErrorContains(t, la.RunE(la, []string{"my-lang"}), "already exists")
+	assert.ErrorContains(t, la.RunE(la, []string{"My_Lang"}), "invalid pack name")
+}
+
+func TestRunLanguagesAddDeployment(t *testing.T) {
+	templateDir := t.TempDir()
+
+	la := newLanguagesAddCmd()
+	assert.Nil(t, la.Flags().Set("template-dir", templateDir))
+	assert.Nil(t, la.Flags().Set("deployment", "true"))
+	la.SetOut(&bytes.Buffer{})
+	assert.Nil(t, la.RunE(la, []string{"my-deploy"}))
+
+	assert.FileExists(t, filepath.Join(templateDir, "deployments", "my-deploy", "draft.yaml"))
+	golden, err := os.ReadFile(filepath.Join(templateDir, "testdata", "golden", "deployments", "my-deploy", "my-deploy", "deployment.yaml"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(golden), "image: myregistry.azurecr.io/my-app:latest\n")
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/Azure/draft/pkg/logger"
+)
+
+const (
+	maxLogFileSize = 10 * 1024 * 1024
+	logFileBackups = 3
+)
+
+var logFile string
+var logFormat string
+
+// runningCommand and commandStart describe the command being run for the fields of the log file
+var runningCommand string
+var commandStart time.Time
+var openLogFile *os.File
+
+// setupLogFile starts writing debug logs with the command as a field to --log-file, leaving the console output as it
+// is. A directory gets one log file per command, such as draft-create.log.
+func setupLogFile(cmd *cobra.Command) error {
+	runningCommand = cmd.CommandPath()
+	commandStart = time.Now()
+	if logFile == "" {
+		return nil
+	}
+
+	formatter, err := logger.NewFileFormatter(logFormat)
+	if err != nil {
+		return err
+	}
+	path := logFile
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, strings.ReplaceAll(runningCommand, " ", "-")+".log")
+	}
+	f, err := logger.OpenRotatingFile(path, maxLogFileSize, logFileBackups)
+	if err != nil {
+		return err
+	}
+	openLogFile = f
+
+	logrus.AddHook(&logger.ConsoleHook{Level: logrus.GetLevel(), Writer: consoleOutput, Formatter: new(logger.CustomFormatter)})
+	logrus.AddHook(&logger.FileHook{Writer: f, Formatter: formatter, Fields: logrus.Fields{"command": runningCommand}})
+	logrus.SetOutput(io.Discard)
+	logrus.SetLevel(logrus.DebugLevel)
+	// only flag names are logged since values such as --variable can hold secrets
+	var flagNames []string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		flagNames = append(flagNames, f.Name)
+	})
+	logrus.WithField("flags", strings.Join(flagNames, ",")).Debugf("running draft %s", VERSION)
+	return nil
+}
+
+// closeLogFile records how long the command took and the error it failed with, if any, and closes --log-file
+func closeLogFile(err error) {
+	if openLogFile == nil {
+		return
+	}
+	entry := logrus.WithField("duration", time.Since(commandStart).String())
+	if err != nil {
+		entry.WithError(err).Debug("command failed")
+	} else {
+		entry.Debug("command finished")
+	}
+	openLogFile.Close()
+	openLogFile = nil
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/mirror"
+	"github.com/Azure/draft/pkg/policy"
+	"github.com/Azure/draft/pkg/templatewriter"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+// withImageMirror wraps templateWriter to pull the base images of generated Dockerfiles and the images of generated
+// Kubernetes resources through the registry mirror of --image-mirror, or of the user config and organization policy.
+// The returned mirror is nil when none is set.
+func withImageMirror(templateWriter templatewriter.TemplateWriter) (templatewriter.TemplateWriter, *mirror.Mirror, error) {
+	var config policy.ImageMirror
+	if paths := policyConfigPaths(); len(paths) > 0 {
+		c, err := policy.Load(paths...)
+		if err != nil {
+			return nil, nil, err
+		}
+		config = c.ImageMirror
+	}
+	if imageMirror != "" {
+		config.Prefix = imageMirror
+	}
+	if config.Prefix == "" {
+		return templateWriter, nil, nil
+	}
+
+	m, err := mirror.New(config.Prefix, config.Registries)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Debugf("pulling the images of %v through %s", m.Registries, m.Prefix)
+	return &writers.PostRenderWriter{Writer: templateWriter, Mutate: m.Rewrite}, m, nil
+}
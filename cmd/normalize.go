@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/Azure/draft/pkg/templatewriter"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/pkg/yamlfmt"
+)
+
+// withNormalizedYAML wraps templateWriter to normalize the formatting of every generated yaml file, so the files
+// pass the strict yaml linters of the repositories they're generated in. Files of a template version pinned with
+// --template-version are left as that version renders them, byte for byte.
+func withNormalizedYAML(templateWriter templatewriter.TemplateWriter, pinnedVersion string) templatewriter.TemplateWriter {
+	if pinnedVersion != "" {
+		return templateWriter
+	}
+	return &writers.PostRenderWriter{Writer: templateWriter, Mutate: yamlfmt.Normalize}
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+func TestWithNormalizedYAML(t *testing.T) {
+	content := []byte("metadata:\n    name: app   \n")
+
+	files := &writers.FileMapWriter{}
+	assert.Nil(t, withNormalizedYAML(files, "").WriteFile("manifests/service.yaml", content))
+	assert.NotEqual(t, string(content), string(files.FileMap["manifests/service.yaml"]))
+
+	// a pinned template version is written as it renders
+	files = &writers.FileMapWriter{}
+	assert.Nil(t, withNormalizedYAML(files, "1.0.0").WriteFile("manifests/service.yaml", content))
+	assert.Equal(t, string(content), string(files.FileMap["manifests/service.yaml"]))
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"github.com/Azure/draft/pkg/overwrite"
+	"github.com/Azure/draft/pkg/templatewriter"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+// overwritePolicy is how every command treats files that already exist, set from --force, --never-overwrite and
+// --interactive
+var overwritePolicy overwrite.Policy
+
+// withOverwritePolicy wraps templateWriter to apply overwritePolicy to each generated file that already exists with
+// different content
+func withOverwritePolicy(templateWriter templatewriter.TemplateWriter) templatewriter.TemplateWriter {
+	return withOverwritePolicyExcept(templateWriter, nil)
+}
+
+// withOverwritePolicyExcept is withOverwritePolicy writing the files for which inPlace returns true without applying
+// overwritePolicy, for the existing files draft edits in place rather than generates
+func withOverwritePolicyExcept(templateWriter templatewriter.TemplateWriter, inPlace func(path string) bool) templatewriter.TemplateWriter {
+	if overwritePolicy == overwrite.Force {
+		return templateWriter
+	}
+	return &writers.OverwriteWriter{Writer: templateWriter, Confirm: overwritePolicy.Confirm, InPlace: inPlace}
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/deployments"
+	"github.com/Azure/draft/pkg/languages"
+	"github.com/Azure/draft/pkg/packs"
+	"github.com/Azure/draft/template"
+)
+
+// embeddedDeployTypes are the embedded deployment types in the order they are offered
+var embeddedDeployTypes = []string{"helm", "kustomize", "manifests", "containerapp", "appservice"}
+
+// templateSources returns --template-dir and the directories of the --pack packs, pulling the packs that aren't cached.
+// A --template-dir that is an OCI reference is pulled like a --pack.
+func (cc *createCmd) templateSources() ([]string, error) {
+	if cc.templateSourceDirs != nil {
+		return cc.templateSourceDirs, nil
+	}
+
+	dirs := []string{}
+	references := cc.packs
+	if strings.HasPrefix(cc.templateDir, packs.OCIScheme) {
+		// a --template-dir pushed with draft template push is pulled like a --pack
+		references = append([]string{cc.templateDir}, references...)
+	} else if cc.templateDir != "" {
+		if _, err := os.Stat(cc.templateDir); err != nil {
+			return nil, fmt.Errorf("--template-dir: %w", err)
+		}
+		dirs = append(dirs, cc.templateDir)
+	}
+	if len(references) > 0 {
+		fetcher, err := packs.NewFetcher()
+		if err != nil {
+			return nil, err
+		}
+		fetcher.Refresh = cc.refreshPacks
+		for _, pack := range references {
+			dir, err := fetcher.Fetch(context.Background(), pack)
+			if err != nil {
+				return nil, err
+			}
+			dirs = append(dirs, dir)
+		}
+	}
+	cc.templateSourceDirs = dirs
+	return dirs, nil
+}
+
+// loadLanguages returns the embedded language packs along with the packs of --template-dir and --pack
+func (cc *createCmd) loadLanguages() (*languages.Languages, error) {
+	supportedLangs := languages.CreateLanguagesFromEmbedFS(template.Dockerfiles, cc.dest)
+	dirs, err := cc.templateSources()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dir := range dirs {
+		if !isDir(filepath.Join(dir, packs.DockerfilesDir)) {
+			continue
+		}
+		customLangs, err := languages.CreateLanguagesFromFS(os.DirFS(dir), cc.dest)
+		if err != nil {
+			return nil, fmt.Errorf("loading language packs from %s: %w", dir, err)
+		}
+		log.Debugf("loaded language packs %v from %s", customLangs.Names(), dir)
+		supportedLangs.AddPacks(customLangs)
+	}
+	return supportedLangs, nil
+}
+
+// loadDeployments returns the embedded deployment types along with the deployment packs of --template-dir and --pack
+func (cc *createCmd) loadDeployments() (*deployments.Deployments, error) {
+	d := deployments.CreateDeploymentsFromEmbedFS(template.Deployments, cc.dest)
+	dirs, err := cc.templateSources()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dir := range dirs {
+		if !isDir(filepath.Join(dir, packs.DeploymentsDir)) {
+			continue
+		}
+		customDeployments, err := deployments.CreateDeploymentsFromFS(os.DirFS(dir), cc.dest)
+		if err != nil {
+			return nil, fmt.Errorf("loading deployment packs from %s: %w", dir, err)
+		}
+		log.Debugf("loaded deployment packs %v from %s", customDeployments.DeployTypes(), dir)
+		d.AddPacks(customDeployments)
+	}
+	return d, nil
+}
+
+// deployTypeItems returns the deployment types to select from, the embedded ones first followed by those of packs
+func deployTypeItems(d *deployments.Deployments) []string {
+	items := append([]string{}, embeddedDeployTypes...)
+	var custom []string
+	for _, deployType := range d.DeployTypes() {
+		embedded := false
+		for _, e := range embeddedDeployTypes {
+			embedded = embedded || e == deployType
+		}
+		if !embedded {
+			custom = append(custom, deployType)
+		}
+	}
+	sort.Strings(custom)
+	return append(items, custom...)
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/patches"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+// patchDeployType is the deployment type whose generated Deployment is layered over existing manifests by --patch
+const patchDeployType = "manifests"
+
+// createDeploymentPatches layers the labels, probes and image of the Deployment of the manifests deployment type over
+// the Deployments and StatefulSets of the existing manifests of the destination as kustomize patches, leaving the
+// manifests as they are
+func (cc *createCmd) createDeploymentPatches() error {
+	deployType := cc.deployType
+	if cc.createConfig.DeployType != "" {
+		deployType = cc.createConfig.DeployType
+	}
+	if deployType != "" && deployType != patchDeployType {
+		return fmt.Errorf("patches layer the %s deployment type over existing manifests, not %s", patchDeployType, deployType)
+	}
+	manifests, err := patches.FindManifests(cc.dest)
+	if err != nil {
+		return err
+	}
+
+	// the generated files are only read for what they add, neither written nor recorded as generated
+	rendered := &writers.FileMapWriter{}
+	templateWriter, recorder := cc.templateWriter, cc.templateVariableRecorder
+	cc.templateWriter, cc.templateVariableRecorder, cc.deployType = rendered, nil, patchDeployType
+	err = cc.createDeployment()
+	cc.templateWriter, cc.templateVariableRecorder = templateWriter, recorder
+	if err != nil {
+		return err
+	}
+	additions, err := patches.AdditionsFrom(rendered.FileMap)
+	if err != nil {
+		return err
+	}
+	files, err := patches.Generate(cc.dest, manifests, additions)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("--> Creating kustomize patches in %s layering the generated Deployment over %s...", patches.Dir, strings.Join(manifests, ", "))
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := filepath.Join(cc.dest, filepath.FromSlash(name))
+		if err := cc.templateWriter.EnsureDirectory(filepath.Dir(p)); err != nil {
+			return err
+		}
+		if err := cc.templateWriter.WriteFile(p, files[name]); err != nil {
+			return err
+		}
+	}
+	log.Infof("--> Deploy the patched manifests with: kubectl apply -k %s", cc.dest)
+	return nil
+}
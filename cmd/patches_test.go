@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/prompts"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+func TestCreateDeploymentPatches(t *testing.T) {
+	prompts.SetNonInteractive(true)
+	defer prompts.SetNonInteractive(false)
+	oldFlagVariablesMap := flagVariablesMap
+	defer func() { flagVariablesMap = oldFlagVariablesMap }()
+
+	dest := t.TempDir()
+	deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: shop
+spec:
+  template:
+    spec:
+      containers:
+        - name: web
+          image: nginx
+`
+	assert.Nil(t, os.MkdirAll(filepath.Join(dest, "k8s"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dest, "k8s", "deployment.yaml"), []byte(deployment), 0644))
+
+	flagVariablesMap = map[string]string{"PORT": "8080", "APPNAME": "app", "NAMESPACE": "default", "IMAGENAME": "acr.io/app", "IMAGETAG": "v1", "SERVICEPORT": "80"}
+	w := &writers.FileMapWriter{}
+	mockCC := &createCmd{dest: dest, createConfig: &CreateConfig{}, templateWriter: w}
+	assert.Nil(t, mockCC.createDeploymentPatches())
+	assert.Equal(t, w, mockCC.templateWriter)
+	assert.Len(t, w.FileMap, 2)
+	assert.Contains(t, string(w.FileMap[filepath.Join(dest, "kustomization.yaml")]), "  - k8s/deployment.yaml\n")
+	patch := string(w.FileMap[filepath.Join(dest, "draft-patches", "deployment-shop.yaml")])
+	assert.Contains(t, patch, "        - name: web\n          image: acr.io/app:v1\n")
+	assert.Contains(t, patch, "readinessProbe:")
+
+	mockCC = &createCmd{dest: dest, deployType: "helm", createConfig: &CreateConfig{}, templateWriter: w}
+	assert.ErrorContains(t, mockCC.createDeploymentPatches(), "not helm")
+}
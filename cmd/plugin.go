@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	dryrunpkg "github.com/Azure/draft/pkg/dryrun"
+	"github.com/Azure/draft/pkg/plugins"
+	"github.com/Azure/draft/pkg/templatewriter"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+const pluginGroupID = "plugins"
+
+// pluginArgs are the draft context flags recognized on a plugin command line. All other
+// arguments are passed through to the plugin untouched.
+type pluginArgs struct {
+	dest      string
+	variables map[string]string
+	dryRun    bool
+	rest      []string
+}
+
+func newPluginCmd(p plugins.Plugin) *cobra.Command {
+	return &cobra.Command{
+		Use:                p.Name,
+		Short:              fmt.Sprintf("Runs the %s plugin (%s)", p.Name, p.Path),
+		Long:               fmt.Sprintf("Runs the external plugin %s. Draft passes --destination, --variable and --dry-run to the plugin as a JSON context on stdin; all other arguments are passed through.", p.Path),
+		GroupID:            pluginGroupID,
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pa, err := parsePluginArgs(args)
+			if err != nil {
+				return err
+			}
+			return runPlugin(cmd, p, pa)
+		},
+	}
+}
+
+func parsePluginArgs(args []string) (*pluginArgs, error) {
+	pa := &pluginArgs{
+		dest:      currentDirDefaultFlagValue,
+		variables: make(map[string]string),
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch name {
+		case "-d", "--destination", "--variable":
+			if !hasValue {
+				if i+1 >= len(args) {
+					return nil, fmt.Errorf("flag needs an argument: %s", name)
+				}
+				i++
+				value = args[i]
+			}
+			if name == "--variable" {
+				varName, varValue, ok := strings.Cut(value, "=")
+				if !ok {
+					return nil, fmt.Errorf("invalid variable format: %s", value)
+				}
+				pa.variables[varName] = varValue
+			} else {
+				pa.dest = value
+			}
+		case "--dry-run":
+			pa.dryRun = !hasValue || value == "true"
+		default:
+			pa.rest = append(pa.rest, arg)
+		}
+	}
+	return pa, nil
+}
+
+func runPlugin(cmd *cobra.Command, p plugins.Plugin, pa *pluginArgs) error {
+	log.Debugf("running plugin %s with args %v", p.Path, pa.rest)
+	result, err := p.Run(cmd.Context(), plugins.Context{
+		Version:     VERSION,
+		Destination: pa.dest,
+		Variables:   pa.variables,
+		DryRun:      pa.dryRun,
+	}, pa.rest, os.Stdout, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range result.Messages {
+		log.Info(msg)
+	}
+
+	var templateWriter templatewriter.TemplateWriter = &writers.LocalFSWriter{}
+	var dryRunRecorder *dryrunpkg.DryRunRecorder
+	if pa.dryRun {
+		dryRunRecorder = dryrunpkg.NewDryRunRecorder()
+		templateWriter = dryRunRecorder
+	}
+
+	if err := plugins.WriteResult(result, pa.dest, templateWriter); err != nil {
+		return fmt.Errorf("writing plugin %s result: %w", p.Name, err)
+	}
+
+	if dryRunRecorder != nil {
+		for _, f := range dryRunRecorder.DryRunInfo.FilesToWrite {
+			fmt.Println(f)
+		}
+	}
+	return nil
+}
+
+// addPluginCommands registers every plugin found on the PATH that does not collide with a builtin command
+func addPluginCommands(root *cobra.Command) {
+	discovered := plugins.Discover(os.Getenv("PATH"))
+	if len(discovered) == 0 {
+		return
+	}
+
+	root.AddGroup(&cobra.Group{ID: pluginGroupID, Title: "Plugin Commands:"})
+	for _, p := range discovered {
+		// help and completion are added lazily by cobra, so they are not found by root.Find yet
+		if p.Name == "help" || p.Name == "completion" {
+			log.Debugf("plugin %s at %s conflicts with a builtin command, skipping", p.Name, p.Path)
+			continue
+		}
+		if existing, _, err := root.Find([]string{p.Name}); err == nil && existing != root {
+			log.Debugf("plugin %s at %s conflicts with a builtin command, skipping", p.Name, p.Path)
+			continue
+		}
+		root.AddCommand(newPluginCmd(p))
+	}
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePluginArgs(t *testing.T) {
+	pa, err := parsePluginArgs([]string{"sub", "-d", "./app", "--variable", "APPNAME=foo", "--variable=PORT=80", "--dry-run", "--other", "x"})
+	assert.Nil(t, err)
+	assert.Equal(t, "./app", pa.dest)
+	assert.Equal(t, map[string]string{"APPNAME": "foo", "PORT": "80"}, pa.variables)
+	assert.True(t, pa.dryRun)
+	assert.Equal(t, []string{"sub", "--other", "x"}, pa.rest)
+
+	pa, err = parsePluginArgs(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, currentDirDefaultFlagValue, pa.dest)
+	assert.False(t, pa.dryRun)
+
+	_, err = parsePluginArgs([]string{"--variable", "NOEQUALS"})
+	assert.NotNil(t, err)
+
+	_, err = parsePluginArgs([]string{"--destination"})
+	assert.NotNil(t, err)
+}
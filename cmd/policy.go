@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/policy"
+	"github.com/Azure/draft/pkg/templatewriter"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+// policyConfigPaths returns the user config, from --config or $HOME/.draft.yaml when it exists, followed by the
+// organization policy file in $DRAFT_POLICY_FILE, so the policy takes precedence
+func policyConfigPaths() []string {
+	var paths []string
+	if cfgFile != "" {
+		paths = append(paths, cfgFile)
+	} else if home, err := os.UserHomeDir(); err == nil {
+		userConfig := filepath.Join(home, ".draft.yaml")
+		if _, err := os.Stat(userConfig); err == nil {
+			paths = append(paths, userConfig)
+		}
+	}
+	if policyFile := os.Getenv(policy.FileEnv); policyFile != "" {
+		paths = append(paths, policyFile)
+	}
+	return paths
+}
+
+// withPolicyMetadata wraps templateWriter to merge the labels and annotations of the user config and organization
+// policy into every generated Kubernetes resource
+func withPolicyMetadata(templateWriter templatewriter.TemplateWriter) (templatewriter.TemplateWriter, error) {
+	paths := policyConfigPaths()
+	if len(paths) == 0 {
+		return templateWriter, nil
+	}
+
+	c, err := policy.Load(paths...)
+	if err != nil {
+		return nil, err
+	}
+	if c.Metadata.IsEmpty() {
+		return templateWriter, nil
+	}
+	log.Debugf("merging %d labels and %d annotations from %v into generated resources", len(c.Metadata.Labels), len(c.Metadata.Annotations), paths)
+	return &writers.PostRenderWriter{Writer: templateWriter, Mutate: c.Metadata.Apply}, nil
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/policy"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+func TestWithPolicyMetadata(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(policy.FileEnv, "")
+
+	files := &writers.FileMapWriter{}
+	templateWriter, err := withPolicyMetadata(files)
+	assert.Nil(t, err)
+	assert.Equal(t, files, templateWriter, "without any config files the writer is unchanged")
+
+	policyFile := filepath.Join(t.TempDir(), "policy.yaml")
+	assert.Nil(t, os.WriteFile(policyFile, []byte("metadata:\n  labels:\n    team: web\n"), 0644))
+	t.Setenv(policy.FileEnv, policyFile)
+
+	templateWriter, err = withPolicyMetadata(files)
+	assert.Nil(t, err)
+	assert.Nil(t, templateWriter.WriteFile("manifests/service.yaml", []byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: app\n")))
+	assert.Equal(t, "apiVersion: v1\nkind: Service\nmetadata:\n  name: app\n  labels:\n    team: \"web\"\n", string(files.FileMap["manifests/service.yaml"]))
+
+	t.Setenv(policy.FileEnv, filepath.Join(t.TempDir(), "missing.yaml"))
+	_, err = withPolicyMetadata(files)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/Azure/draft/pkg/deployments"
+	dryrunpkg "github.com/Azure/draft/pkg/dryrun"
+	"github.com/Azure/draft/pkg/languages"
+	"github.com/Azure/draft/pkg/templatewriter"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+type regenerateCmd struct {
+	dest           string
+	descriptorPath string
+	deployType     string
+	flagVariables  []string
+	templateWriter templatewriter.TemplateWriter
+}
+
+func newRegenerateCmd() *cobra.Command {
+	rc := &regenerateCmd{}
+
+	cmd := &cobra.Command{
+		Use:   "regenerate [flags]",
+		Short: "Regenerates the Dockerfile and deployment files with the latest templates",
+		Long: `This command renders the latest templates with the variables stored in a dry run descriptor (written by
+'draft create --dry-run --dry-run-file') and writes the files that changed. Files still matching what the template
+version recorded in the descriptor generated are updated directly. Files modified since are shown as a diff and only
+overwritten once confirmed, following --force, --never-overwrite and --interactive. The descriptor is then updated
+to the new template versions.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return rc.run(cmd.OutOrStdout())
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&rc.dest, "destination", "d", currentDirDefaultFlagValue, "specify the path to the project directory")
+	f.StringVar(&rc.descriptorPath, "descriptor", emptyDefaultFlagValue, "specify the dry run json file holding the variables the files were generated with")
+	f.StringVar(&rc.deployType, "deploy-type", emptyDefaultFlagValue, "specify the deployment type, detected from the descriptor's files when not set")
+	f.StringArrayVarP(&rc.flagVariables, "variable", "", []string{}, "override a stored variable for this run (ex: --variable PORT=8080)")
+
+	rc.templateWriter = &writers.LocalFSWriter{}
+
+	return cmd
+}
+
+func (rc *regenerateCmd) run(out io.Writer) error {
+	if rc.descriptorPath == "" {
+		return errors.New("--descriptor is required")
+	}
+	dest, err := checkDestination(rc.dest)
+	if err != nil {
+		return err
+	}
+	rc.dest = dest
+
+	descriptor, err := loadDescriptor(rc.descriptorPath)
+	if err != nil {
+		return err
+	}
+	variables := copyVariables(descriptor.Variables)
+	for _, flagVar := range rc.flagVariables {
+		flagVarName, flagVarValue, ok := strings.Cut(flagVar, "=")
+		if !ok {
+			return fmt.Errorf("invalid variable format: %s", flagVar)
+		}
+		variables[flagVarName] = flagVarValue
+	}
+
+	lang := variables[LANGUAGE_VARIABLE]
+	deployType := rc.deployType
+	if deployType == "" {
+		deployType = detectDescriptorDeployType(descriptor.FilesToWrite)
+	}
+
+	// the files as generated by the recorded template versions tell the user's modifications apart from template changes
+	var generated map[string][]byte
+	if len(descriptor.TemplateVersions) > 0 {
+		if generated, err = renderTemplateVersions(rc.dest, lang, deployType, descriptor.TemplateVersions, variables); err != nil {
+			return fmt.Errorf("rendering the recorded template versions: %w", err)
+		}
+	} else {
+		log.Warn("the descriptor has no template versions, every existing file that changes is treated as modified")
+	}
+
+	if _, ok := variables[DRAFT_VERSION_VARIABLE]; ok {
+		variables[DRAFT_VERSION_VARIABLE] = VERSION
+	}
+	latest, err := renderFromVariables(rc.dest, lang, deployType, variables)
+	if err != nil {
+		return err
+	}
+
+	latestVersions, err := currentTemplateVersions(lang, deployType)
+	if err != nil {
+		return err
+	}
+	writeTemplateVersionChanges(out, descriptor.TemplateVersions, latestVersions)
+
+	templateWriter := rc.templateWriter
+	if !dryRun {
+		templateWriter = withUncommittedChangesCheck(templateWriter, rc.dest)
+	}
+	if err := rc.writeChanged(out, latest, generated, templateWriter); err != nil {
+		return err
+	}
+
+	if dryRun {
+		return nil
+	}
+	return updateDescriptor(rc.descriptorPath, latestVersions)
+}
+
+// writeChanged writes the files of latest that differ from the existing ones. Existing files that differ from
+// generated, the output of the template versions they were created with, are confirmed before being overwritten.
+func (rc *regenerateCmd) writeChanged(out io.Writer, latest, generated map[string][]byte, templateWriter templatewriter.TemplateWriter) error {
+	paths := make([]string, 0, len(latest))
+	for p := range latest {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		rel, err := filepath.Rel(rc.dest, p)
+		if err != nil {
+			return err
+		}
+
+		status := "updated"
+		existing, err := os.ReadFile(p)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			status = "created"
+		case err != nil:
+			return err
+		case bytes.Equal(existing, latest[p]):
+			fmt.Fprintf(out, "%-9s %s\n", driftUnchanged, rel)
+			continue
+		case generated == nil || !bytes.Equal(existing, generated[p]):
+			fmt.Fprint(out, diffFile(rel, existing, latest[p]).Diff)
+			if dryRun {
+				fmt.Fprintf(out, "%-9s %s\n", driftModified, rel)
+				continue
+			}
+			overwrite, err := overwritePolicy.Confirm(rel)
+			if err != nil {
+				return err
+			}
+			if !overwrite {
+				fmt.Fprintf(out, "%-9s %s\n", "kept", rel)
+				continue
+			}
+		}
+
+		if !dryRun {
+			if err := templateWriter.EnsureDirectory(filepath.Dir(p)); err != nil {
+				return err
+			}
+			if err := templateWriter.WriteFile(p, latest[p]); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(out, "%-9s %s\n", status, rel)
+	}
+	return nil
+}
+
+// currentTemplateVersions returns the versions of the embedded templates for lang and deployType
+func currentTemplateVersions(lang, deployType string) (map[string]string, error) {
+	templateVersions := make(map[string]string)
+	if lang != "" {
+		if langConfig := languages.CreateLanguagesFromEmbedFS(template.Dockerfiles, "").GetConfig(lang); langConfig != nil && langConfig.Version != "" {
+			templateVersions[dockerfileArtifact] = langConfig.Version
+		}
+	}
+	if deployType != "" {
+		deployConfig, err := deployments.CreateDeploymentsFromEmbedFS(template.Deployments, "").GetConfig(deployType)
+		if err != nil {
+			return nil, err
+		}
+		if deployConfig.Version != "" {
+			templateVersions[deploymentArtifact] = deployConfig.Version
+		}
+	}
+	return templateVersions, nil
+}
+
+func writeTemplateVersionChanges(out io.Writer, recorded, latest map[string]string) {
+	for _, artifact := range []string{dockerfileArtifact, deploymentArtifact} {
+		latestVersion, ok := latest[artifact]
+		if !ok {
+			continue
+		}
+		recordedVersion := recorded[artifact]
+		if recordedVersion == "" {
+			recordedVersion = "unknown"
+		}
+		if recordedVersion == latestVersion {
+			fmt.Fprintf(out, "%s template %s is up to date\n", artifact, latestVersion)
+		} else {
+			fmt.Fprintf(out, "%s template %s -> %s\n", artifact, recordedVersion, latestVersion)
+		}
+	}
+}
+
+// updateDescriptor records templateVersions and the running draft version in the descriptor at path. The stored
+// variables are otherwise left as they are, so encrypted secrets stay encrypted.
+func updateDescriptor(path string, templateVersions map[string]string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading descriptor: %w", err)
+	}
+	var descriptor dryrunpkg.DryRunInfo
+	if err := json.Unmarshal(content, &descriptor); err != nil {
+		return fmt.Errorf("parsing descriptor %s: %w", path, err)
+	}
+
+	descriptor.TemplateVersions = templateVersions
+	if _, ok := descriptor.Variables[DRAFT_VERSION_VARIABLE]; ok {
+		descriptor.Variables[DRAFT_VERSION_VARIABLE] = VERSION
+	}
+	content, err = json.MarshalIndent(descriptor, "", TWO_SPACES)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	dryrunpkg "github.com/Azure/draft/pkg/dryrun"
+	"github.com/Azure/draft/pkg/overwrite"
+)
+
+func TestRunRegenerate(t *testing.T) {
+	dest := t.TempDir()
+	variables := map[string]string{
+		LANGUAGE_VARIABLE: "gomodule",
+		"PORT":            "8080",
+		"APPNAME":         "testapp",
+		"SERVICEPORT":     "80",
+		"NAMESPACE":       "default",
+		"IMAGENAME":       "testimage",
+		"IMAGETAG":        "latest",
+		"VERSION":         "1.20",
+	}
+	recordedVersions := map[string]string{dockerfileArtifact: "1.0.0", deploymentArtifact: "1.0.0"}
+
+	generated, err := renderTemplateVersions(dest, "gomodule", "manifests", recordedVersions, variables)
+	assert.Nil(t, err)
+	for p, content := range generated {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.Nil(t, os.WriteFile(p, content, 0644))
+	}
+	servicePath := filepath.Join(dest, "manifests", "service.yaml")
+	assert.Nil(t, os.WriteFile(servicePath, []byte("# modified by hand\n"), 0644))
+
+	descriptor, err := json.Marshal(dryrunpkg.DryRunInfo{
+		Variables:        variables,
+		FilesToWrite:     []string{filepath.Join(dest, "manifests", "deployment.yaml")},
+		TemplateVersions: recordedVersions,
+	})
+	assert.Nil(t, err)
+	descriptorPath := filepath.Join(t.TempDir(), "dryrun.json")
+	assert.Nil(t, os.WriteFile(descriptorPath, descriptor, 0644))
+
+	oldPolicy, oldSkip := overwritePolicy, skipDestinationCheck
+	overwritePolicy, skipDestinationCheck = overwrite.Never, true
+	defer func() { overwritePolicy, skipDestinationCheck = oldPolicy, oldSkip }()
+
+	rc := newRegenerateCmd()
+	assert.Nil(t, rc.Flags().Set("destination", dest))
+	assert.Nil(t, rc.Flags().Set("descriptor", descriptorPath))
+	var out bytes.Buffer
+	rc.SetOut(&out)
+	assert.Nil(t, rc.RunE(rc, nil))
+
+	latest, err := renderFromVariables(dest, "gomodule", "manifests", variables)
+	assert.Nil(t, err)
+	dockerfile, err := os.ReadFile(filepath.Join(dest, "Dockerfile"))
+	assert.Nil(t, err)
+	assert.Equal(t, string(latest[filepath.Join(dest, "Dockerfile")]), string(dockerfile))
+	service, err := os.ReadFile(servicePath)
+	assert.Nil(t, err)
+	assert.Equal(t, "# modified by hand\n", string(service))
+
+	assert.Contains(t, out.String(), "dockerfile template 1.0.0 -> ")
+	assert.Contains(t, out.String(), "updated   Dockerfile")
+	assert.Contains(t, out.String(), "+++ b/manifests/service.yaml")
+	assert.Contains(t, out.String(), "kept      manifests/service.yaml")
+
+	updated, err := loadDescriptor(descriptorPath)
+	assert.Nil(t, err)
+	latestVersions, err := currentTemplateVersions("gomodule", "manifests")
+	assert.Nil(t, err)
+	assert.Equal(t, latestVersions, updated.TemplateVersions)
+}
@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/Azure/draft/pkg/diagnostics"
+	"github.com/Azure/draft/pkg/filematches"
+	"github.com/Azure/draft/pkg/linguist"
+	"github.com/Azure/draft/pkg/reports"
+)
+
+const newIssueURL = "https://github.com/Azure/draft/issues/new"
+
+// environmentTools are the clis whose versions are checked for the report, with the arguments printing their version
+var environmentTools = map[string][]string{
+	"az":      {"version", "--query", `"azure-cli"`, "-o", "tsv"},
+	"docker":  {"--version"},
+	"gh":      {"--version"},
+	"git":     {"--version"},
+	"helm":    {"version", "--short"},
+	"kubectl": {"version", "--client"},
+}
+
+type reportIssueCmd struct {
+	dest           string
+	output         string
+	descriptorPath string
+}
+
+// issueReport is the sanitized diagnostics bundled by draft report-issue. It holds no variable values or file contents.
+type issueReport struct {
+	Version        string                 `json:"version"`
+	Revision       string                 `json:"revision,omitempty"`
+	GoVersion      string                 `json:"goVersion"`
+	Platform       string                 `json:"platform"`
+	Languages      []reportLanguage       `json:"languages,omitempty"`
+	DeploymentType string                 `json:"deploymentType,omitempty"`
+	VariableNames  []string               `json:"variableNames,omitempty"`
+	LastError      *diagnostics.LastError `json:"lastError,omitempty"`
+	Environment    []environmentCheck     `json:"environment"`
+}
+
+type reportLanguage struct {
+	Language string  `json:"language"`
+	Percent  float64 `json:"percent"`
+}
+
+type environmentCheck struct {
+	Name   string `json:"name"`
+	Found  bool   `json:"found"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func newReportIssueCmd() *cobra.Command {
+	rc := &reportIssueCmd{}
+	var cmd = &cobra.Command{
+		Use:   "report-issue",
+		Short: "Bundles sanitized diagnostics to attach to a GitHub issue",
+		Long: `This command gathers the draft version, the languages and deployment files detected in your project, the
+names (never the values) of the variables in a dry run file, the last error draft failed with and the versions of the
+tools draft uses into a zip file to attach to a new issue. Local paths are replaced with placeholders and nothing is
+sent anywhere.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return rc.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&rc.dest, "destination", "d", ".", "specify the path to the project directory")
+	f.StringVarP(&rc.output, "output", "o", "", "the zip file to write (default is draft-report-<timestamp>.zip)")
+	f.StringVar(&rc.descriptorPath, "descriptor", "", "a dry run json file written with --dry-run-file whose variable names to include")
+
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newReportIssueCmd())
+}
+
+func (rc *reportIssueCmd) run() error {
+	if rc.output == "" {
+		rc.output = fmt.Sprintf("draft-report-%s.zip", time.Now().Format("20060102-150405"))
+	}
+
+	report, err := rc.collect()
+	if err != nil {
+		return err
+	}
+	files, err := report.files()
+	if err != nil {
+		return err
+	}
+
+	var bundle bytes.Buffer
+	if err := diagnostics.WriteBundle(&bundle, files); err != nil {
+		return err
+	}
+	if err := os.WriteFile(rc.output, bundle.Bytes(), 0644); err != nil {
+		return err
+	}
+	log.Infof("Wrote %s, please review it and attach it to a new issue at %s", rc.output, newIssueURL)
+	return nil
+}
+
+func (rc *reportIssueCmd) collect() (*issueReport, error) {
+	sanitizer := diagnostics.NewSanitizer()
+	if absDest, err := filepath.Abs(rc.dest); err == nil {
+		sanitizer.Replace(absDest, "<project>")
+	}
+
+	report := &issueReport{
+		Version:     VERSION,
+		Revision:    getVCSInfoFromRuntime(),
+		GoVersion:   runtime.Version(),
+		Platform:    runtime.GOOS + "/" + runtime.GOARCH,
+		Environment: checkEnvironment(sanitizer),
+	}
+
+	langs, err := linguist.ProcessDir(rc.dest)
+	if err != nil {
+		log.Warnf("unable to detect languages: %s", sanitizer.Sanitize(err.Error()))
+	}
+	for _, lang := range langs {
+		report.Languages = append(report.Languages, reportLanguage{Language: lang.Language, Percent: lang.Percent})
+	}
+	report.DeploymentType, _ = filematches.FindDraftDeploymentFiles(rc.dest)
+
+	if rc.descriptorPath != "" {
+		content, err := os.ReadFile(rc.descriptorPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading descriptor: %w", err)
+		}
+		var descriptor struct {
+			Variables map[string]json.RawMessage `json:"variables"`
+		}
+		if err := json.Unmarshal(content, &descriptor); err != nil {
+			return nil, fmt.Errorf("parsing descriptor %s: %w", rc.descriptorPath, err)
+		}
+		for name := range descriptor.Variables {
+			report.VariableNames = append(report.VariableNames, name)
+		}
+		sort.Strings(report.VariableNames)
+	}
+
+	lastErrorPath, err := diagnostics.LastErrorPath()
+	if err != nil {
+		return nil, err
+	}
+	if report.LastError, err = diagnostics.LoadLastError(lastErrorPath); err != nil {
+		return nil, err
+	}
+	if report.LastError != nil {
+		report.LastError.Error = sanitizer.Sanitize(report.LastError.Error)
+	}
+	return report, nil
+}
+
+// checkEnvironment reports the versions of the tools draft uses and whether prompts can render in the terminal
+func checkEnvironment(sanitizer *diagnostics.Sanitizer) []environmentCheck {
+	names := make([]string, 0, len(environmentTools))
+	for name := range environmentTools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	checks := make([]environmentCheck, 0, len(names)+1)
+	for _, name := range names {
+		check := environmentCheck{Name: name}
+		if _, err := exec.LookPath(name); err == nil {
+			check.Found = true
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			out, err := exec.CommandContext(ctx, name, environmentTools[name]...).Output()
+			cancel()
+			if err != nil {
+				check.Detail = "version check failed: " + err.Error()
+			} else {
+				check.Detail, _, _ = strings.Cut(strings.TrimSpace(string(out)), "\n")
+			}
+			check.Detail = sanitizer.Sanitize(check.Detail)
+		}
+		checks = append(checks, check)
+	}
+
+	checks = append(checks, environmentCheck{
+		Name:   "terminal",
+		Found:  term.IsTerminal(int(os.Stdin.Fd())),
+		Detail: "TERM=" + os.Getenv("TERM"),
+	})
+	return checks
+}
+
+// files renders the report as the files of the bundle, json for tooling and markdown to paste into the issue
+func (r *issueReport) files() (map[string][]byte, error) {
+	reportJson, err := json.MarshalIndent(r, "", TWO_SPACES)
+	if err != nil {
+		return nil, err
+	}
+
+	var summary bytes.Buffer
+	fmt.Fprintf(&summary, "## Draft %s (%s)\n\n", r.Version, r.Platform)
+	if r.Revision != "" {
+		fmt.Fprintf(&summary, "Revision: %s, built with %s\n\n", r.Revision, r.GoVersion)
+	}
+
+	if len(r.Languages) > 0 || r.DeploymentType != "" {
+		project := reports.MarkdownTable{Headers: []string{"Detected", "Value"}}
+		for _, lang := range r.Languages {
+			project.Rows = append(project.Rows, []string{"language", fmt.Sprintf("%s (%.1f%%)", lang.Language, lang.Percent)})
+		}
+		if r.DeploymentType != "" {
+			project.Rows = append(project.Rows, []string{"deployment files", r.DeploymentType})
+		}
+		if err := reports.WriteMarkdownTable(&summary, project); err != nil {
+			return nil, err
+		}
+		summary.WriteString("\n")
+	}
+
+	if len(r.VariableNames) > 0 {
+		fmt.Fprintf(&summary, "Variables: %s\n\n", strings.Join(r.VariableNames, ", "))
+	}
+
+	environment := reports.MarkdownTable{Headers: []string{"Check", "Found", "Detail"}}
+	for _, check := range r.Environment {
+		environment.Rows = append(environment.Rows, []string{check.Name, fmt.Sprint(check.Found), check.Detail})
+	}
+	if err := reports.WriteMarkdownTable(&summary, environment); err != nil {
+		return nil, err
+	}
+
+	if r.LastError != nil {
+		fmt.Fprintf(&summary, "\n### Last error\n\n`%s` failed at %s with draft %s:\n\n```\n%s\n```\n",
+			r.LastError.Command, r.LastError.Time.Format(time.RFC3339), r.LastError.Version, r.LastError.Error)
+	}
+
+	return map[string][]byte{
+		"report.json": reportJson,
+		"summary.md":  summary.Bytes(),
+	}, nil
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/diagnostics"
+)
+
+func TestReportIssue(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	dest := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dest, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644))
+	assert.Nil(t, os.MkdirAll(filepath.Join(dest, "manifests"), 0755))
+	descriptorPath := filepath.Join(t.TempDir(), "draft.json")
+	assert.Nil(t, os.WriteFile(descriptorPath, []byte(`{"variables": {"APPNAME": "my-secret-app", "PORT": "80"}}`), 0644))
+
+	lastErrorPath, err := diagnostics.LastErrorPath()
+	assert.Nil(t, err)
+	absDest, err := filepath.Abs(dest)
+	assert.Nil(t, err)
+	assert.Nil(t, diagnostics.SaveLastError(lastErrorPath, diagnostics.LastError{Command: "draft create", Error: "reading " + absDest + "/Dockerfile: denied", Version: VERSION, Time: time.Now()}))
+
+	output := filepath.Join(t.TempDir(), "report.zip")
+	rc := &reportIssueCmd{dest: dest, output: output, descriptorPath: descriptorPath}
+	assert.Nil(t, rc.run())
+
+	zr, err := zip.OpenReader(output)
+	assert.Nil(t, err)
+	defer zr.Close()
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		assert.Nil(t, err)
+		content, err := io.ReadAll(r)
+		assert.Nil(t, err)
+		files[f.Name] = string(content)
+	}
+	assert.Len(t, files, 2)
+
+	var report issueReport
+	assert.Nil(t, json.Unmarshal([]byte(files["report.json"]), &report))
+	assert.Equal(t, VERSION, report.Version)
+	assert.Equal(t, "manifests", report.DeploymentType)
+	assert.Equal(t, []string{"APPNAME", "PORT"}, report.VariableNames)
+	assert.Equal(t, "Go", report.Languages[0].Language)
+	assert.Equal(t, "reading <project>/Dockerfile: denied", report.LastError.Error)
+	assert.Equal(t, "terminal", report.Environment[len(report.Environment)-1].Name)
+
+	assert.NotContains(t, files["report.json"], "my-secret-app")
+	assert.NotContains(t, files["summary.md"], "my-secret-app")
+	assert.Contains(t, files["summary.md"], "Variables: APPNAME, PORT")
+	assert.Contains(t, files["summary.md"], "### Last error")
+}
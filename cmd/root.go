@@ -1,11 +1,24 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
 	cc "github.com/ivanpirog/coloredcobra"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/Azure/draft/pkg/deployments"
+	"github.com/Azure/draft/pkg/diagnostics"
+	"github.com/Azure/draft/pkg/imagetag"
 	"github.com/Azure/draft/pkg/logger"
+	"github.com/Azure/draft/pkg/netconfig"
+	"github.com/Azure/draft/pkg/overwrite"
+	"github.com/Azure/draft/pkg/prompts"
+	"github.com/Azure/draft/pkg/providers"
+	"github.com/Azure/draft/pkg/workflows"
 )
 
 var cfgFile string
@@ -14,6 +27,24 @@ var provider string
 var silent bool
 var dryRun bool
 var dryRunFile string
+var strictVariables bool
+var dependencyReportFile string
+var skipDestinationCheck bool
+var secretsIdentity string
+var redactSecrets bool
+var resourcePickerSource string
+var advancedPrompts bool
+var forceOverwrite bool
+var neverOverwrite bool
+var interactive bool
+var promptProtocol string
+var noHistory bool
+var offline bool
+var caBundle string
+var imageMirror string
+
+// consoleOutput is where log messages are printed, stderr with the jsonl prompt protocol so stdout only carries it
+var consoleOutput io.Writer = &logger.OutputSplitter{}
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -29,15 +60,36 @@ This will prompt you to create a Dockerfile and deployment files for your projec
 
 For more information, please visit the Draft Github page: https://github.com/Azure/draft.`,
 
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		if verbose {
 			logrus.SetLevel(logrus.DebugLevel)
 		} else if silent {
 			logrus.SetLevel(logrus.ErrorLevel)
 
 		}
-		logrus.SetOutput(&logger.OutputSplitter{})
+		if err := prompts.SetPromptProtocol(promptProtocol, os.Stdin, os.Stdout); err != nil {
+			return err
+		}
+		if promptProtocol == prompts.PromptProtocolJSONL {
+			consoleOutput = os.Stderr
+		}
+		logrus.SetOutput(consoleOutput)
 		logrus.SetFormatter(new(logger.CustomFormatter))
+		if err := setupLogFile(cmd); err != nil {
+			return err
+		}
+		prompts.SetAdvanced(advancedPrompts)
+		providers.SetOffline(offline || providers.OfflineFromEnv())
+		if err := netconfig.SetCABundle(caBundle); err != nil {
+			return fmt.Errorf("--ca-bundle: %w", err)
+		}
+		prompts.SetVariableValidators(promptValidators(currentDirDefaultFlagValue))
+		policy, err := overwrite.FromFlags(forceOverwrite, neverOverwrite, interactive)
+		if err != nil {
+			return err
+		}
+		overwritePolicy = policy
+		return configureResourcePicker(resourcePickerSource)
 	},
 	SilenceErrors: true,
 }
@@ -53,7 +105,31 @@ func Execute() {
 		ExecName: cc.Bold,
 		Flags:    cc.Bold,
 	})
-	cobra.CheckErr(rootCmd.Execute())
+	addPluginCommands(rootCmd)
+	err := rootCmd.Execute()
+	if err != nil {
+		saveLastError(err)
+	}
+	closeLogFile(err)
+	cobra.CheckErr(err)
+}
+
+// saveLastError keeps err for draft report-issue. Only the command path is saved since arguments can hold secrets.
+func saveLastError(err error) {
+	path, pathErr := diagnostics.LastErrorPath()
+	if pathErr != nil {
+		logrus.Debugf("unable to save last error: %s", pathErr)
+		return
+	}
+
+	command := rootCmd.Name()
+	if failedCmd, _, findErr := rootCmd.Find(os.Args[1:]); findErr == nil {
+		command = failedCmd.CommandPath()
+	}
+	lastError := diagnostics.LastError{Command: command, Error: err.Error(), Version: VERSION, Time: time.Now()}
+	if saveErr := diagnostics.SaveLastError(path, lastError); saveErr != nil {
+		logrus.Debugf("unable to save last error: %s", saveErr)
+	}
 }
 
 func init() {
@@ -61,6 +137,89 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose logging")
 	rootCmd.PersistentFlags().StringVarP(&provider, "provider", "p", "azure", "cloud provider")
 	rootCmd.PersistentFlags().BoolVarP(&silent, "silent", "", false, "enable silent logging")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "also write debug logs to this file, rotated when larger than 10MB; a directory gets one file per command")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logger.FormatText, "format of the --log-file entries: text or json")
 	rootCmd.PersistentFlags().BoolVarP(&dryRun, "dry-run", "", false, "enable dry run mode in which no files are written to disk")
 	rootCmd.PersistentFlags().StringVar(&dryRunFile, "dry-run-file", "", "optional file to write dry run summary in json format into (requires --dry-run flag)")
+	rootCmd.PersistentFlags().BoolVar(&strictVariables, "strict-variables", false, "fail when a --variable name is not defined by the selected template")
+	rootCmd.PersistentFlags().StringVar(&dependencyReportFile, "dependency-report", "", "optional file to write a CycloneDX-style json report of the base images, GitHub actions and helm chart dependencies of the generated files into")
+	rootCmd.PersistentFlags().StringVar(&secretsIdentity, "secrets-identity", "", "age identity file used to encrypt secret variables in saved files and decrypt them on load (default is $DRAFT_AGE_IDENTITY)")
+	rootCmd.PersistentFlags().BoolVar(&redactSecrets, "redact", false, "strip secret variables from saved files instead of encrypting them")
+	rootCmd.PersistentFlags().StringVar(&resourcePickerSource, "resource-picker", "", "offer existing cloud resources such as container registries and clusters for selection when prompting: azure, aws, gcp, or a yaml/json file of resources")
+	rootCmd.PersistentFlags().BoolVar(&advancedPrompts, "advanced", false, "also prompt for advanced variables, which otherwise use their defaults")
+	rootCmd.PersistentFlags().BoolVar(&forceOverwrite, "force", false, "overwrite existing files without asking")
+	rootCmd.PersistentFlags().BoolVar(&neverOverwrite, "never-overwrite", false, "keep existing files instead of asking to overwrite them")
+	rootCmd.PersistentFlags().BoolVar(&interactive, "interactive", true, "ask before overwriting existing files; with --interactive=false draft fails on an existing file unless --force or --never-overwrite is passed")
+	rootCmd.PersistentFlags().BoolVar(&noHistory, "no-history", false, "neither offer the values previously answered to prompts from .draft/history.yaml nor record the answers of this run there")
+	rootCmd.PersistentFlags().StringVar(&promptProtocol, "prompt-protocol", prompts.PromptProtocolTerminal, "how prompts are answered: terminal, or jsonl to write each prompt as a json line to stdout and read its answer as a json line from stdin, with logs on stderr")
+	rootCmd.PersistentFlags().BoolVar(&allowDirty, "allow-dirty", false, "let generated files replace files with uncommitted changes in git, which otherwise fails")
+	rootCmd.PersistentFlags().BoolVar(&skipDestinationCheck, "skip-destination-check", false, "skip confirming a --destination outside of a git repository, the home directory or the filesystem root")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "run without the cloud clis: resource names are only checked for their format and resources are entered rather than picked from a list (default is $DRAFT_OFFLINE)")
+	rootCmd.PersistentFlags().StringVar(&caBundle, "ca-bundle", "", "PEM file of additional certificate authorities to trust in outbound connections, such as that of a TLS intercepting proxy; also passed to the az, gh and git clis unless SSL_CERT_FILE, REQUESTS_CA_BUNDLE or GIT_SSL_CAINFO are set")
+	rootCmd.PersistentFlags().StringVar(&imageMirror, "image-mirror", "", "pull the base images of generated Dockerfiles and the images of generated Kubernetes resources from Docker Hub, MCR and the other public registries through this registry mirror prefix, such as registry.corp/proxy/ for registry.corp/proxy/docker.io/library/golang (default is imageMirror.prefix of the config or policy file)")
+}
+
+// promptValidators are the checks of the variables whose answers are validated as soon as they're entered, so a typo
+// is asked again instead of failing the command after every other prompt. The paths and branches of the project in
+// dest are checked against it.
+func promptValidators(dest string) map[string]func(string) error {
+	validators := deployments.VariableValidators()
+	validators["AZURECONTAINERREGISTRY"] = providers.ValidateAcrNameFormat
+	validators["RESOURCEGROUP"] = providers.ValidateAzResourceGroup
+	validators["CLUSTERNAME"] = providers.ValidateAksClusterName
+	validators["CONTAINERNAME"] = workflows.ValidateContainerName
+	validators[workflows.ContainerRegistryVariable] = workflows.ValidateContainerRegistry
+	validators["BUILDCONTEXTPATH"] = workflows.DirectoryValidator("BUILDCONTEXTPATH", dest)
+	validators["BRANCHNAME"] = workflows.BranchValidator(dest, providers.Offline())
+	validators[workflows.RebuildScheduleVariable] = workflows.ValidateCronSchedule
+	validators[workflows.ImageTagStrategyVariable] = imagetag.Validate
+	return validators
+}
+
+// awsPromptValidators replaces the validators of the workflow variables naming Azure resources in validators with
+// those of the AWS workflows, which name ECR and EKS resources
+func awsPromptValidators(validators map[string]func(string) error) {
+	validators["AWSREGION"] = providers.ValidateAwsRegion
+	validators["AWSROLEARN"] = providers.ValidateAwsRoleArn
+	validators[workflows.ECRRegistryVariable] = providers.ValidateEcrRegistry
+	validators["CONTAINERNAME"] = providers.ValidateEcrRepository
+	validators["CLUSTERNAME"] = providers.ValidateEksClusterName
+}
+
+// gcpPromptValidators replaces the validators of the workflow variables naming Azure resources in validators with
+// those of the GCP workflows, which name Artifact Registry and GKE resources
+func gcpPromptValidators(validators map[string]func(string) error) {
+	validators["GCPPROJECT"] = providers.ValidateGcpProjectId
+	validators["GKELOCATION"] = providers.ValidateGkeLocation
+	validators[workflows.ArtifactRegistryVariable] = providers.ValidateArtifactRegistry
+	validators["CLUSTERNAME"] = providers.ValidateGkeClusterName
+}
+
+// configureResourcePicker sets the prompts' resource picker from --resource-picker, which names a cloud cli or a file
+// of resources. The clis aren't used offline.
+func configureResourcePicker(source string) error {
+	switch source {
+	case "":
+		prompts.SetResourcePicker(nil)
+		return nil
+	case "azure", "aws", "gcp":
+		if providers.Offline() {
+			logrus.Infof("--> Not listing %s resources offline, enter them instead", source)
+			prompts.SetResourcePicker(nil)
+			return nil
+		}
+		picker, err := providers.NewResourcePicker(source)
+		if err != nil {
+			return err
+		}
+		prompts.SetResourcePicker(picker)
+		return nil
+	}
+
+	picker, err := prompts.LoadStaticPicker(source)
+	if err != nil {
+		return fmt.Errorf("--resource-picker: %w", err)
+	}
+	prompts.SetResourcePicker(picker)
+	return nil
 }
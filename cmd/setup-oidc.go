@@ -0,0 +1,477 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/subscription/armsubscription"
+	"github.com/Azure/draft/pkg/cred"
+	"github.com/Azure/draft/pkg/prompts"
+	"github.com/manifoldco/promptui"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/Azure/draft/pkg/netconfig"
+	"github.com/Azure/draft/pkg/providers"
+	"github.com/Azure/draft/pkg/spinner"
+	"github.com/Azure/draft/pkg/workflows"
+)
+
+func newSetUpCmd() *cobra.Command {
+	sc := &providers.SetUpCmd{}
+
+	// setup-oidcCmd represents the setup-oidc command, which was setup-gh before it trusted other SCMs than github
+	var cmd = &cobra.Command{
+		Use:     "setup-oidc",
+		Aliases: []string{"setup-gh"},
+		Short:   "Automates the OIDC setup process of Github, GitLab or Azure DevOps",
+		Long: `This command will automate the OIDC setup process by creating an Azure Active Directory 
+application and service principle, and will configure that application to trust github.
+With --scm gitlab it trusts the pipelines of a branch of a GitLab project, and with --scm azdo the pipelines using a
+service connection of an Azure DevOps project, and prints the variables or service connection settings to configure.
+With --provider gcp it creates a Google Cloud service account and a Workload Identity Federation pool trusting github instead.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sc.Provider = provider
+			if providers.Offline() {
+				return fmt.Errorf("%s creates cloud resources with the az or gcloud cli and can't run offline, unset --offline and %s", cmd.CalledAs(), providers.OfflineEnv)
+			}
+			if err := providers.ValidateSCM(sc.SCM); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+
+			if strings.ToLower(sc.Provider) == "gcp" {
+				if sc.SCM != providers.SCMGitHub {
+					return fmt.Errorf("--provider gcp only sets up Workload Identity Federation for github and can't be used with --scm %s", sc.SCM)
+				}
+				return setUpGcp(ctx, sc)
+			}
+
+			azCred, err := cred.GetCred()
+			if err != nil {
+				return fmt.Errorf("getting credentials: %w", err)
+			}
+
+			client, err := armsubscription.NewTenantsClient(azCred, &arm.ClientOptions{
+				ClientOptions: azcore.ClientOptions{Transport: netconfig.HTTPClient()},
+			})
+			if err != nil {
+				return fmt.Errorf("creating tenants client: %w", err)
+			}
+
+			sc.AzClient.AzTenantClient = client
+
+			err = fillSetUpConfig(sc)
+			if err != nil {
+				return fmt.Errorf("filling setup config: %w", err)
+			}
+
+			scmName := scmDisplayNames[sc.SCM]
+			s := spinner.CreateSpinner(fmt.Sprintf("--> Setting up %s OIDC...", scmName))
+			s.Start()
+			err = runProviderSetUp(ctx, sc, s)
+			s.Stop()
+			if err != nil {
+				return err
+			}
+
+			log.Infof("Draft has successfully set up %s OIDC for your project 😃", scmName)
+			switch sc.SCM {
+			case providers.SCMGitLab:
+				log.Infof("Set these CI/CD variables in the settings of GitLab project %s:", sc.Repo)
+				printOIDCSettings(sc)
+				log.Info("Use 'draft generate-workflow --provider gitlab' to generate a GitLab pipeline to build and deploy an application on AKS.")
+			case providers.SCMAzureDevOps:
+				log.Infof("Create an Azure Resource Manager service connection with workload identity federation (manual) in Azure DevOps project %s with these settings:", sc.Repo)
+				printOIDCSettings(sc)
+				log.Infof("Use 'draft generate-workflow --provider azdo --variable AZURESERVICECONNECTION=%s' to generate an Azure Pipeline to build and deploy an application on AKS.", sc.ServiceConnection)
+			default:
+				log.Info("Use 'draft generate-workflow' to generate a Github workflow to build and deploy an application on AKS.")
+			}
+
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&sc.AppName, "app", "a", emptyDefaultFlagValue, "specify the Azure Active Directory application name, or the Google Cloud service account name with --provider gcp")
+	f.StringVarP(&sc.SubscriptionID, "subscription-id", "s", emptyDefaultFlagValue, "specify the Azure subscription ID")
+	f.StringVarP(&sc.ResourceGroupName, "resource-group", "r", emptyDefaultFlagValue, "specify the Azure resource group name")
+	f.StringVarP(&sc.Repo, "gh-repo", "g", emptyDefaultFlagValue, "specify the github repository link")
+	f.StringVar(&sc.SCM, "scm", providers.SCMGitHub, fmt.Sprintf("specify the source control host whose CI jobs are trusted, one of %s", strings.Join(providers.SCMs, ", ")))
+	f.StringVar(&sc.Repo, "repo", emptyDefaultFlagValue, "specify the repo whose CI jobs are trusted, organization/repoName with github, group/project with gitlab and organization/project with azdo (same as --gh-repo)")
+	f.StringVar(&sc.Branch, "branch", emptyDefaultFlagValue, "specify the branch whose pipelines are trusted with --scm gitlab")
+	f.StringVar(&sc.GitLabURL, "gitlab-url", providers.DefaultGitLabURL, "specify the url of the GitLab instance with --scm gitlab")
+	f.StringVar(&sc.ServiceConnection, "service-connection", emptyDefaultFlagValue, "specify the name of the service connection whose pipelines are trusted with --scm azdo")
+	f.StringVarP(&sc.Location, "location", "l", emptyDefaultFlagValue, "specify the Azure region used when the resource group has to be created")
+	f.StringVar(&sc.ProjectID, "project", emptyDefaultFlagValue, "specify the Google Cloud project of the service account with --provider gcp (defaults to the gcloud project)")
+	return cmd
+}
+
+// scmDisplayNames are the names of the SCMs in the messages of setup-oidc
+var scmDisplayNames = map[string]string{
+	providers.SCMGitHub:      "Github",
+	providers.SCMGitLab:      "GitLab",
+	providers.SCMAzureDevOps: "Azure DevOps",
+}
+
+// printOIDCSettings prints the values the CI jobs of GitLab or Azure DevOps sign in with, which aren't set by draft
+func printOIDCSettings(sc *providers.SetUpCmd) {
+	for _, setting := range sc.OIDCSettings() {
+		log.Infof("    %s: %s", setting.Name, setting.Value)
+	}
+}
+
+// setUpGcp sets up Workload Identity Federation between the Github repo and a Google Cloud service account
+func setUpGcp(ctx context.Context, sc *providers.SetUpCmd) error {
+	if err := fillGcpSetUpConfig(sc); err != nil {
+		return fmt.Errorf("filling setup config: %w", err)
+	}
+
+	s := spinner.CreateSpinner("--> Setting up Github OIDC...")
+	s.Start()
+	err := runProviderSetUp(ctx, sc, s)
+	s.Stop()
+	if err != nil {
+		return err
+	}
+
+	log.Info("Draft has successfully set up Github OIDC for your project 😃")
+	log.Info("Use 'draft generate-workflow --cloud gcp' to generate a Github workflow to build and deploy an application on GKE.")
+	return nil
+}
+
+// fillGcpSetUpConfig prompts for the service account name, project and repo of the gcp provider
+func fillGcpSetUpConfig(sc *providers.SetUpCmd) error {
+	if sc.AppName == "" {
+		name, err := prompts.RunPrompt(&promptui.Prompt{
+			Label:    "Enter service account name",
+			Validate: providers.ValidateGcpServiceAccountId,
+		})
+		if err != nil {
+			return err
+		}
+		sc.AppName = name
+	} else if err := providers.ValidateGcpServiceAccountId(sc.AppName); err != nil {
+		return err
+	}
+
+	if sc.ProjectID == "" {
+		project, err := prompts.RunPrompt(&promptui.Prompt{
+			Label:    "Enter Google Cloud project ID",
+			Default:  providers.DefaultGcpProject(),
+			Validate: providers.ValidateGcpProjectId,
+		})
+		if err != nil {
+			return err
+		}
+		sc.ProjectID = project
+	} else if err := providers.ValidateGcpProjectId(sc.ProjectID); err != nil {
+		return err
+	}
+
+	if sc.Repo == "" {
+		sc.Repo = getGhRepo()
+	}
+	if providers.HasGhCli() {
+		// gh may not be logged in yet, so an unknown repo is only a warning
+		checks := &prompts.AsyncChecks{}
+		repo := sc.Repo
+		checks.GoWarning("github repo", func() error {
+			return providers.CheckGhRepo(repo)
+		})
+		return checks.Wait("--> Validating setup configuration...")
+	}
+	return nil
+}
+
+func fillSetUpConfig(sc *providers.SetUpCmd) error {
+	isAzure := strings.ToLower(sc.Provider) == "azure"
+	// az and gh lookups run in the background while the remaining values are prompted for
+	checks := &prompts.AsyncChecks{}
+
+	if sc.AppName == "" {
+		sc.AppName = getAppName()
+	}
+
+	if sc.SubscriptionID == "" {
+		if strings.ToLower(sc.Provider) == "azure" {
+			currentSub, err := providers.GetCurrentAzSubscriptionLabel()
+			if err != nil {
+				return fmt.Errorf("getting current subscription ID: %w", err)
+			}
+
+			subLabels, err := providers.GetAzSubscriptionLabels()
+			if err != nil {
+				return fmt.Errorf("getting subscription labels: %w", err)
+			}
+
+			sc.SubscriptionID, err = GetAzSubscriptionId(subLabels, currentSub)
+			if err != nil {
+				return fmt.Errorf("getting subscription ID: %w", err)
+			}
+		} else {
+			sc.SubscriptionID = getSubscriptionID()
+		}
+	} else if isAzure {
+		subscriptionID := sc.SubscriptionID
+		checks.Go("subscription", func() error {
+			return providers.IsSubscriptionIdValid(subscriptionID)
+		})
+	}
+
+	if sc.ResourceGroupName == "" {
+		sc.ResourceGroupName = getResourceGroup()
+	}
+
+	if isAzure {
+		exists, err := providers.AzResourceGroupExists(sc.SubscriptionID, sc.ResourceGroupName)
+		if err != nil {
+			return fmt.Errorf("looking up resource group %s: %w", sc.ResourceGroupName, err)
+		}
+		if !exists {
+			if err := fillResourceGroupLocation(sc); err != nil {
+				return fmt.Errorf("filling resource group location: %w", err)
+			}
+		}
+	}
+
+	// an empty scm is github, like the default of --scm
+	isGitHub := sc.SCM != providers.SCMGitLab && sc.SCM != providers.SCMAzureDevOps
+	if !isGitHub {
+		if err := fillScmSetUpConfig(sc); err != nil {
+			return err
+		}
+	} else if sc.Repo == "" {
+		sc.Repo = getGhRepo()
+	}
+	if isAzure && isGitHub && providers.HasGhCli() {
+		// gh may not be logged in yet, so an unknown repo is only a warning
+		repo := sc.Repo
+		checks.GoWarning("github repo", func() error {
+			return providers.CheckGhRepo(repo)
+		})
+	}
+
+	return checks.Wait("--> Validating setup configuration...")
+}
+
+// fillScmSetUpConfig prompts for the GitLab project and branch, or the Azure DevOps project and service connection,
+// whose pipelines are trusted
+func fillScmSetUpConfig(sc *providers.SetUpCmd) error {
+	validateRepo := func(repo string) error {
+		return providers.ValidateRepoFormat(sc.SCM, repo)
+	}
+
+	if sc.Repo == "" {
+		label := "Enter gitlab group and project (group/project)"
+		if sc.SCM == providers.SCMAzureDevOps {
+			label = "Enter azure devops organization and project (organization/project)"
+		}
+		repo, err := prompts.RunPrompt(&promptui.Prompt{Label: label, Validate: validateRepo})
+		if err != nil {
+			return err
+		}
+		sc.Repo = repo
+	} else if err := validateRepo(sc.Repo); err != nil {
+		return err
+	}
+
+	if sc.SCM == providers.SCMGitLab {
+		// only the format of the branch is checked, the GitLab project isn't necessarily checked out
+		validateBranchName := workflows.BranchValidator(".", true)
+		if err := providers.ValidateGitLabURL(sc.GitLabURL); err != nil {
+			return err
+		}
+		if sc.Branch == "" {
+			branch, err := prompts.RunPrompt(&promptui.Prompt{
+				Label:    "Enter the branch whose pipelines deploy",
+				Default:  "main",
+				Validate: validateBranchName,
+			})
+			if err != nil {
+				return err
+			}
+			sc.Branch = branch
+		}
+		return validateBranchName(sc.Branch)
+	}
+
+	if sc.ServiceConnection == "" {
+		serviceConnection, err := prompts.RunPrompt(&promptui.Prompt{
+			Label:    "Enter the name of the service connection to create",
+			Validate: providers.ValidateServiceConnectionName,
+		})
+		if err != nil {
+			return err
+		}
+		sc.ServiceConnection = serviceConnection
+	}
+	return providers.ValidateServiceConnectionName(sc.ServiceConnection)
+}
+
+// fillResourceGroupLocation asks whether to create the missing resource group and in which of the
+// subscription's regions, so an unavailable region is rejected before anything is created
+func fillResourceGroupLocation(sc *providers.SetUpCmd) error {
+	selection := &promptui.Select{
+		Label: fmt.Sprintf("Resource group %s was not found, would you like to create it?", sc.ResourceGroupName),
+		Items: []string{"yes", "no"},
+	}
+	_, selectResponse, err := prompts.RunSelect(selection)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(selectResponse, "yes") {
+		return fmt.Errorf("resource group %q not found from subscription %q", sc.ResourceGroupName, sc.SubscriptionID)
+	}
+
+	locations, err := providers.GetAzLocations(sc.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	if sc.Location != "" {
+		sc.Location, err = providers.ValidateAzLocation(sc.Location, locations)
+		if err != nil {
+			return err
+		}
+	} else {
+		location, err := prompts.Select("Please choose the region for the resource group", locations, &prompts.SelectOpt[providers.AzLocation]{
+			Field: func(location providers.AzLocation) string {
+				return location.DisplayName + " (" + location.Name + ")"
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("selecting location: %w", err)
+		}
+		sc.Location = location.Name
+	}
+
+	sc.CreateResourceGroup = true
+	return nil
+}
+
+func runProviderSetUp(ctx context.Context, sc *providers.SetUpCmd, s spinner.Spinner) error {
+	provider := strings.ToLower(sc.Provider)
+	if provider == "azure" {
+		// call azure provider logic
+		return providers.InitiateAzureOIDCFlow(ctx, sc, s)
+
+	} else if provider == "gcp" {
+		return providers.InitiateGCPOIDCFlow(ctx, sc, s)
+	} else {
+		// call logic for user-submitted provider
+		fmt.Printf("The provider is %v\n", sc.Provider)
+	}
+
+	return nil
+}
+
+func getAppName() string {
+	validate := func(input string) error {
+		if input == "" {
+			return errors.New("Invalid app name")
+		}
+		return nil
+	}
+
+	prompt := promptui.Prompt{
+		Label:    "Enter app registration name",
+		Validate: validate,
+	}
+
+	result, err := prompts.RunPrompt(&prompt)
+
+	if err != nil {
+		return err.Error()
+	}
+
+	return result
+}
+
+func getSubscriptionID() string {
+	validate := func(input string) error {
+		if input == "" {
+			return errors.New("Invalid subscription id")
+		}
+		return nil
+	}
+
+	prompt := promptui.Prompt{
+		Label:    "Enter subscription ID",
+		Validate: validate,
+	}
+
+	result, err := prompts.RunPrompt(&prompt)
+
+	if err != nil {
+		return err.Error()
+	}
+
+	return result
+}
+
+func getResourceGroup() string {
+	prompt := promptui.Prompt{
+		Label:    "Enter resource group name",
+		Validate: providers.ValidateResourceGroupNameFormat,
+	}
+
+	result, err := prompts.RunPrompt(&prompt)
+
+	if err != nil {
+		return err.Error()
+	}
+
+	return result
+}
+
+func getGhRepo() string {
+	repoPrompt := promptui.Prompt{
+		Label:    "Enter github organization and repo (organization/repoName)",
+		Validate: providers.ValidateGhRepoFormat,
+	}
+
+	repo, err := prompts.RunPrompt(&repoPrompt)
+	if err != nil {
+		return err.Error()
+	}
+
+	return repo
+}
+
+func getCloudProvider() string {
+	selection := &promptui.Select{
+		Label: "What cloud provider would you like to use?",
+		Items: []string{"azure", "gcp"},
+	}
+
+	_, selectResponse, err := prompts.RunSelect(selection)
+	if err != nil {
+		return err.Error()
+	}
+
+	return selectResponse
+}
+
+func GetAzSubscriptionId(subLabels []providers.SubLabel, currentSub providers.SubLabel) (string, error) {
+	subLabel, err := prompts.Select("Please choose the subscription ID you would like to use", subLabels, &prompts.SelectOpt[providers.SubLabel]{
+		Field: func(subLabel providers.SubLabel) string {
+			return subLabel.Name + " (" + subLabel.ID + ")"
+		},
+		Default: &currentSub,
+	})
+	if err != nil {
+		return "", fmt.Errorf("selecting subscription ID: %w", err)
+	}
+
+	return subLabel.ID, nil
+}
+
+func init() {
+	rootCmd.AddCommand(newSetUpCmd())
+}
@@ -26,3 +26,12 @@ func TestSetUpConfig(t *testing.T) {
 
 	assert.True(t, err == nil)
 }
+
+func TestSetUpOffline(t *testing.T) {
+	providers.SetOffline(true)
+	defer providers.SetOffline(false)
+
+	cmd := newSetUpCmd()
+	err := cmd.RunE(cmd, nil)
+	assert.ErrorContains(t, err, "can't run offline")
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/manifoldco/promptui"
+
+	"github.com/Azure/draft/pkg/prompts"
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+// maskedValue replaces the values of secret variables in the summary
+const maskedValue = "********"
+
+// writeSummary writes a table of the resolved variables, with the values of secretVariables masked, and the files to
+// be written relative to dest, marking those that replace an existing file. The files exist in existing, read relative
+// to dest, or on the local filesystem when existing is nil.
+func writeSummary(out io.Writer, dest string, variables map[string]string, secretVariables []string, paths []string, existing reporeader.RepoReader) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if len(variables) > 0 {
+		fmt.Fprintln(w, "VARIABLE\tVALUE")
+		names := make([]string, 0, len(variables))
+		for name := range variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			value := variables[name]
+			if slices.Contains(secretVariables, name) && value != "" {
+				value = maskedValue
+			}
+			fmt.Fprintf(w, "%s\t%s\n", name, value)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, "FILE\tACTION")
+	for _, path := range paths {
+		rel, err := filepath.Rel(dest, path)
+		inDest := err == nil && !strings.HasPrefix(rel, "..")
+		action := "create"
+		if existing != nil {
+			if inDest && existing.Exists(rel) {
+				action = "overwrite"
+			}
+		} else if _, err := os.Stat(path); err == nil {
+			action = "overwrite"
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if inDest {
+			path = rel
+		}
+		fmt.Fprintf(w, "%s\t%s\n", filepath.ToSlash(path), action)
+	}
+	return w.Flush()
+}
+
+// confirmSummary shows the summary of writeSummary and asks whether to write the files, without asking when
+// assumeYes is set or in non-interactive mode. existing is called for the files the summary is checked against, only
+// once the summary is shown.
+func confirmSummary(dest string, variables map[string]string, secretVariables []string, paths []string, existing func() reporeader.RepoReader, assumeYes bool) (bool, error) {
+	if assumeYes || prompts.NonInteractive() {
+		return true, nil
+	}
+
+	fmt.Println()
+	if err := writeSummary(os.Stdout, dest, variables, secretVariables, paths, existing()); err != nil {
+		return false, err
+	}
+	fmt.Println()
+	selection := &promptui.Select{
+		Label: fmt.Sprintf("Write these %d files?", len(paths)),
+		Items: []string{"yes", "no"},
+	}
+	_, selectResponse, err := prompts.RunSelect(selection)
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(selectResponse, "yes"), nil
+}
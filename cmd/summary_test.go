@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/dryrun"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+func TestWriteSummary(t *testing.T) {
+	dest := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dest, "Dockerfile"), []byte("FROM scratch\n"), 0644))
+
+	var out bytes.Buffer
+	err := writeSummary(&out, dest, map[string]string{"PORT": "8080", "APPNAME": "app", "DBPASSWORD": "hunter2"}, []string{"DBPASSWORD"},
+		[]string{filepath.Join(dest, "Dockerfile"), filepath.Join(dest, "manifests", "deployment.yaml")}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, `VARIABLE    VALUE
+APPNAME     app
+DBPASSWORD  ********
+PORT        8080
+
+FILE                       ACTION
+Dockerfile                 overwrite
+manifests/deployment.yaml  create
+`, out.String())
+}
+
+func TestWriteStagedFiles(t *testing.T) {
+	dest := t.TempDir()
+	generation := dryrun.NewDryRunRecorder()
+	generation.Record("PORT", "8080")
+	cc := &createCmd{dest: dest, generation: generation, staged: &writers.StagedWriter{Writer: &writers.LocalFSWriter{}}, assumeYes: true}
+	path := filepath.Join(dest, "Dockerfile")
+	assert.Nil(t, cc.staged.WriteFile(path, []byte("FROM scratch\n")))
+	assert.NoFileExists(t, path, "nothing is written before the summary is confirmed")
+
+	written, err := cc.writeStagedFiles()
+	assert.Nil(t, err)
+	assert.True(t, written)
+	assert.FileExists(t, path)
+}
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Azure/draft/pkg/deployments"
+	"github.com/Azure/draft/pkg/languages"
+	"github.com/Azure/draft/pkg/packs"
+	"github.com/Azure/draft/pkg/templatelint"
+	"github.com/Azure/draft/pkg/workflows"
+	"github.com/Azure/draft/template"
+)
+
+// Artifacts that can be pinned to a template version with --template-version
+const (
+	dockerfileArtifact = "dockerfile"
+	deploymentArtifact = "deployment"
+	workflowArtifact   = "workflow"
+)
+
+type templateListCmd struct {
+	versions bool
+}
+
+type templatePushCmd struct {
+	templateDir string
+	plainHTTP   bool
+}
+
+type templateLintCmd struct {
+	templateDir string
+}
+
+// templateInfo is a template embedded in draft and its versions
+type templateInfo struct {
+	artifact string
+	name     string
+	versions []string
+}
+
+func newTemplateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "template",
+		Short: "Inspects the templates embedded in draft and distributes template packs",
+	}
+	cmd.AddCommand(newTemplateListCmd())
+	cmd.AddCommand(newTemplatePushCmd())
+	cmd.AddCommand(newTemplateLintCmd())
+	return cmd
+}
+
+func newTemplateListCmd() *cobra.Command {
+	tl := &templateListCmd{}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Lists the embedded Dockerfile, deployment and workflow templates and their versions",
+		Long: `This command lists the templates embedded in draft with their current version. Pass --versions to also
+list the older versions that can be pinned with --template-version to regenerate identical files.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return tl.run(cmd.OutOrStdout())
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&tl.versions, "versions", false, "list every embedded version of each template")
+
+	return cmd
+}
+
+func newTemplatePushCmd() *cobra.Command {
+	tp := &templatePushCmd{}
+	cmd := &cobra.Command{
+		Use:   "push REFERENCE [flags]",
+		Short: "Pushes the language and deployment packs of a template directory to an OCI registry",
+		Long: `This command pushes the dockerfiles and deployments directories of a template directory, laid out like
+draft's, to an OCI registry as an artifact that draft create pulls with --pack or --template-dir (ex: draft template push
+oci://myregistry.azurecr.io/draft-packs/go:1.2.0). The packs are loaded first so that packs whose draft.yaml is
+missing a version or doesn't parse aren't pushed. Log in to the registry with docker login or oras login beforehand.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return tp.run(args[0], cmd.OutOrStdout())
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&tp.templateDir, "template-dir", currentDirDefaultFlagValue, "specify the template directory holding the dockerfiles and deployments directories to push")
+	f.BoolVar(&tp.plainHTTP, "plain-http", false, "talk to the registry over http instead of https")
+
+	return cmd
+}
+
+func newTemplateLintCmd() *cobra.Command {
+	tl := &templateLintCmd{}
+	cmd := &cobra.Command{
+		Use:   "lint [flags]",
+		Short: "Reports the variables of packs that their draft.yaml and template files disagree on",
+		Long: `This command renders each pack of a template directory laid out like draft's, or of the templates embedded in
+draft without --template-dir, and reports the variables declared in its draft.yaml that none of its files use, the
+{{VARIABLE}} placeholders and template fields its files use that its draft.yaml doesn't declare, and the files that
+fail to render with its defaults. The name@version snapshots of older versions are skipped. It exits with an error
+when it reports anything, to run in the CI of a template directory.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return tl.run(cmd.OutOrStdout())
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&tl.templateDir, "template-dir", emptyDefaultFlagValue, "specify the template directory holding the packs to lint, the templates embedded in draft when not set")
+
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newTemplateCmd())
+}
+
+func (tl *templateLintCmd) run(out io.Writer) error {
+	fileSystems := []fs.FS{template.Dockerfiles, template.Deployments, template.Workflows, template.AWSWorkflows,
+		template.GCPWorkflows, template.GenericWorkflows, template.GitLabPipelines, template.AzurePipelines, template.Addons}
+	if tl.templateDir != "" {
+		if !isDir(tl.templateDir) {
+			return fmt.Errorf("template directory %s not found", tl.templateDir)
+		}
+		fileSystems = []fs.FS{os.DirFS(tl.templateDir)}
+	}
+
+	var problems []templatelint.Problem
+	for _, fsys := range fileSystems {
+		p, err := templatelint.LintDir(fsys, templatelint.DraftOptions())
+		if err != nil {
+			return err
+		}
+		problems = append(problems, p...)
+	}
+	for _, problem := range problems {
+		fmt.Fprintln(out, problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d problems in the packs", len(problems))
+	}
+	fmt.Fprintln(out, "No problems found")
+	return nil
+}
+
+func (tp *templatePushCmd) run(pack string, out io.Writer) error {
+	var cfg packs.Config
+	if isDir(filepath.Join(tp.templateDir, packs.DockerfilesDir)) {
+		l, err := languages.CreateLanguagesFromFS(os.DirFS(tp.templateDir), "")
+		if err != nil {
+			return fmt.Errorf("loading language packs from %s: %w", tp.templateDir, err)
+		}
+		cfg.Languages = l.Names()
+		sort.Strings(cfg.Languages)
+		for _, lang := range cfg.Languages {
+			if l.GetConfig(lang).Version == "" {
+				return fmt.Errorf("language pack %s has no version, or its draft.yaml doesn't parse", lang)
+			}
+		}
+	}
+	if isDir(filepath.Join(tp.templateDir, packs.DeploymentsDir)) {
+		d, err := deployments.CreateDeploymentsFromFS(os.DirFS(tp.templateDir), "")
+		if err != nil {
+			return fmt.Errorf("loading deployment packs from %s: %w", tp.templateDir, err)
+		}
+		cfg.DeployTypes = d.DeployTypes()
+		sort.Strings(cfg.DeployTypes)
+		for _, deployType := range cfg.DeployTypes {
+			if c, err := d.GetConfig(deployType); err != nil || c.Version == "" {
+				return fmt.Errorf("deployment pack %s has no version, or its draft.yaml doesn't parse", deployType)
+			}
+		}
+	}
+
+	pusher := &packs.Pusher{PlainHTTP: tp.plainHTTP}
+	pushed, err := pusher.Push(context.Background(), tp.templateDir, pack, cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Pushed %s@%s\n", pack, pushed)
+	if len(cfg.Languages) > 0 {
+		fmt.Fprintf(out, "Language packs: %s\n", strings.Join(cfg.Languages, ", "))
+	}
+	if len(cfg.DeployTypes) > 0 {
+		fmt.Fprintf(out, "Deployment packs: %s\n", strings.Join(cfg.DeployTypes, ", "))
+	}
+	return nil
+}
+
+func (tl *templateListCmd) run(out io.Writer) error {
+	templates, err := listTemplates()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if tl.versions {
+		fmt.Fprintln(w, "ARTIFACT\tNAME\tVERSIONS")
+	} else {
+		fmt.Fprintln(w, "ARTIFACT\tNAME\tVERSION")
+	}
+	for _, t := range templates {
+		versions := t.versions
+		if !tl.versions && len(versions) > 1 {
+			versions = versions[:1]
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", t.artifact, t.name, strings.Join(versions, ", "))
+	}
+	return w.Flush()
+}
+
+// listTemplates returns every embedded template with its versions, newest first
+func listTemplates() ([]templateInfo, error) {
+	l := languages.CreateLanguagesFromEmbedFS(template.Dockerfiles, "")
+	d := deployments.CreateDeploymentsFromEmbedFS(template.Deployments, "")
+	w := workflows.CreateWorkflowsFromEmbedFS(template.Workflows, "")
+
+	var templates []templateInfo
+	add := func(artifact string, names []string, versions func(string) ([]string, error)) error {
+		sort.Strings(names)
+		for _, name := range names {
+			v, err := versions(name)
+			if err != nil {
+				return err
+			}
+			templates = append(templates, templateInfo{artifact: artifact, name: name, versions: v})
+		}
+		return nil
+	}
+
+	if err := add(dockerfileArtifact, l.Names(), l.Versions); err != nil {
+		return nil, err
+	}
+	if err := add(deploymentArtifact, d.DeployTypes(), d.Versions); err != nil {
+		return nil, err
+	}
+	if err := add(workflowArtifact, w.DeployTypes(), w.Versions); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// parseTemplateVersions parses --template-version values of the form artifact=version into a map of artifact to
+// version, accepting only the given artifacts
+func parseTemplateVersions(flagValues []string, artifacts ...string) (map[string]string, error) {
+	templateVersions := make(map[string]string)
+	for _, flagValue := range flagValues {
+		artifact, templateVersion, ok := strings.Cut(flagValue, "=")
+		if !ok || templateVersion == "" {
+			return nil, fmt.Errorf("invalid template version %q, expected artifact=version, for example %s=1.0.0", flagValue, artifacts[0])
+		}
+		valid := false
+		for _, a := range artifacts {
+			valid = valid || a == artifact
+		}
+		if !valid {
+			return nil, fmt.Errorf("invalid template version %q, artifact must be one of: %s", flagValue, strings.Join(artifacts, ", "))
+		}
+		templateVersions[artifact] = templateVersion
+	}
+	return templateVersions, nil
+}
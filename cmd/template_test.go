@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTemplateVersions(t *testing.T) {
+	templateVersions, err := parseTemplateVersions([]string{"dockerfile=1.0.0", "deployment=1.1.0"}, dockerfileArtifact, deploymentArtifact)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"dockerfile": "1.0.0", "deployment": "1.1.0"}, templateVersions)
+
+	for _, invalid := range []string{"1.0.0", "dockerfile=", "workflow=1.0.0"} {
+		_, err = parseTemplateVersions([]string{invalid}, dockerfileArtifact, deploymentArtifact)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestTemplateList(t *testing.T) {
+	var out bytes.Buffer
+	tl := &templateListCmd{versions: true}
+	assert.Nil(t, tl.run(&out))
+	assert.Regexp(t, `ARTIFACT\s+NAME\s+VERSIONS`, out.String())
+	assert.Regexp(t, `dockerfile\s+go\s+1\.1\.0, 1\.0\.0`, out.String())
+	assert.Regexp(t, `workflow\s+helm\s+1\.5\.0, 1\.4\.0, 1\.3\.0, 1\.2\.0, 1\.1\.0, 1\.0\.0`, out.String())
+	assert.Regexp(t, `deployment\s+helm\s+1\.8\.0, 1\.7\.0, 1\.6\.0, 1\.5\.0, 1\.4\.0, 1\.3\.0, 1\.2\.0, 1\.1\.0, 1\.0\.0`, out.String())
+
+	out.Reset()
+	tl.versions = false
+	assert.Nil(t, tl.run(&out))
+	assert.Regexp(t, `workflow\s+helm\s+1\.5\.0\n`, out.String())
+}
+
+func TestTemplatePushInvalidPacks(t *testing.T) {
+	templateDir := t.TempDir()
+	packDir := filepath.Join(templateDir, "dockerfiles", "cobol")
+	assert.Nil(t, os.MkdirAll(packDir, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(packDir, "draft.yaml"), []byte("language: [cobol\n"), 0644))
+
+	tp := &templatePushCmd{templateDir: templateDir}
+	err := tp.run("oci://localhost:5000/draft-packs/cobol:v1", io.Discard)
+	assert.ErrorContains(t, err, "language pack cobol has no version")
+
+	tp.templateDir = t.TempDir()
+	err = tp.run("localhost:5000/draft-packs/cobol:v1", io.Discard)
+	assert.ErrorContains(t, err, "invalid pack")
+
+	// an OCI --template-dir is pulled like a --pack
+	mockCC := &createCmd{dest: "out", templateDir: "oci://"}
+	_, err = mockCC.loadLanguages()
+	assert.ErrorContains(t, err, "invalid pack")
+}
+
+func TestTemplateLint(t *testing.T) {
+	var out bytes.Buffer
+	tl := &templateLintCmd{}
+	assert.Nil(t, tl.run(&out))
+	assert.Equal(t, "No problems found\n", out.String())
+
+	templateDir := t.TempDir()
+	packDir := filepath.Join(templateDir, "dockerfiles", "cobol")
+	assert.Nil(t, os.MkdirAll(packDir, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(packDir, "draft.yaml"), []byte("variables:\n  - name: \"PORT\"\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(packDir, "Dockerfile"), []byte("FROM cobol\nEXPOSE {{PRT}}\n"), 0644))
+
+	out.Reset()
+	tl.templateDir = templateDir
+	assert.ErrorContains(t, tl.run(&out), "found 2 problems")
+	assert.Equal(t, "dockerfiles/cobol/Dockerfile:2: variable PRT is used but not declared in draft.yaml\n"+
+		"dockerfiles/cobol: variable PORT is declared in draft.yaml but never used\n", out.String())
+}
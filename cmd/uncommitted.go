@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/osutil"
+	"github.com/Azure/draft/pkg/templatewriter"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+// allowDirty lets generated files replace files with uncommitted changes, set from --allow-dirty
+var allowDirty bool
+
+// withUncommittedChangesCheck warns about the uncommitted changes below dest and wraps templateWriter to refuse
+// replacing a file that has them, unless --allow-dirty is passed, since regenerating over local edits loses them. The
+// files draft create generated, still as their hashes in the generation manifest record, have no edits to lose and are
+// left out.
+func withUncommittedChangesCheck(templateWriter templatewriter.TemplateWriter, dest string) templatewriter.TemplateWriter {
+	files, err := osutil.UncommittedFiles(dest)
+	if err != nil {
+		log.Debugf("skipping the uncommitted changes check of %s: %s", dest, err)
+		return templateWriter
+	}
+
+	absDest, err := filepath.Abs(dest)
+	if err != nil {
+		log.Debugf("skipping the uncommitted changes check of %s: %s", dest, err)
+		return templateWriter
+	}
+	generated := unchangedGeneratedFiles(dest)
+	uncommitted := make(map[string]bool, len(files))
+	var inDest []string
+	for _, file := range files {
+		if generated[file] {
+			continue
+		}
+		uncommitted[file] = true
+		if rel, err := filepath.Rel(absDest, file); err == nil && !strings.HasPrefix(rel, "..") {
+			inDest = append(inDest, rel)
+		}
+	}
+	if len(inDest) == 0 {
+		return templateWriter
+	}
+	log.Warnf("--> %s has uncommitted changes in: %s", dest, strings.Join(inDest, ", "))
+
+	return &writers.UncommittedWriter{Writer: templateWriter, Files: uncommitted, OnUncommitted: func(path string) error {
+		if allowDirty {
+			log.Warnf("--> Replacing %s, which has uncommitted changes", path)
+			return nil
+		}
+		return fmt.Errorf("%s has uncommitted changes that would be overwritten, commit or stash them first or pass --allow-dirty", path)
+	}}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"embed"
 	"encoding/json"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 	"github.com/Azure/draft/pkg/addons"
 	"github.com/Azure/draft/pkg/config"
 	dryrunpkg "github.com/Azure/draft/pkg/dryrun"
+	"github.com/Azure/draft/pkg/secrets"
 	"github.com/Azure/draft/pkg/templatewriter"
 	"github.com/Azure/draft/pkg/templatewriter/writers"
 	"github.com/Azure/draft/template"
@@ -23,6 +25,7 @@ type updateCmd struct {
 	provider                 string
 	addon                    string
 	flagVariables            []string
+	inspectCluster           bool
 	userInputs               map[string]string
 	templateWriter           templatewriter.TemplateWriter
 	addonFS                  embed.FS
@@ -52,9 +55,13 @@ func newUpdateCmd() *cobra.Command {
 	f.StringVarP(&uc.provider, "provider", "p", "azure", "cloud provider")
 	f.StringVarP(&uc.addon, "addon", "a", "", "addon name")
 	f.StringArrayVarP(&uc.flagVariables, "variable", "", []string{}, "pass a variable non-interactively (ex: --variable foo=bar)")
+	f.BoolVar(&uc.inspectCluster, "inspect-cluster", false, "inspect the cluster of the current kubeconfig context to default class names to what is installed")
 
 	uc.templateWriter = &writers.LocalFSWriter{}
 
+	cmd.AddCommand(newRegenerateCmd())
+	cmd.AddCommand(newAppConfigCmd())
+
 	return cmd
 }
 
@@ -66,8 +73,15 @@ func (uc *updateCmd) run() error {
 			return fmt.Errorf("invalid variable format: %s", flagVar)
 		}
 		flagVariablesMap[flagVarName] = flagVarValue
-		log.Debugf("flag variable %s=%s", flagVarName, flagVarValue)
+		// only the name is logged, which variables are secrets isn't known until the templates are read
+		log.Debugf("flag variable %s", flagVarName)
+	}
+
+	dest, err := checkDestination(uc.dest)
+	if err != nil {
+		return err
 	}
+	uc.dest = dest
 
 	if uc.addon == "" {
 		addon, err := addons.PromptAddon(template.Addons, uc.provider)
@@ -82,24 +96,55 @@ func (uc *updateCmd) run() error {
 		return err
 	}
 
-	uc.userInputs, err = addons.PromptAddonValues(uc.dest, flagVariablesMap, addonConfig)
-	if err != nil {
-		return err
+	var clusterDefaults map[string]string
+	if uc.inspectCluster {
+		if clusterDefaults, err = inspectClusterDefaults(); err != nil {
+			return err
+		}
 	}
-	log.Debugf("addonInputs is: %s", uc.userInputs)
 
 	if dryRun {
 		dryRunRecorder = dryrunpkg.NewDryRunRecorder()
 		uc.templateVariableRecorder = dryRunRecorder
 		uc.templateWriter = dryRunRecorder
-		for k, v := range uc.userInputs {
-			uc.templateVariableRecorder.Record(k, v)
-		}
+	} else {
+		uc.templateWriter = withOverwritePolicy(withUncommittedChangesCheck(uc.templateWriter, uc.dest))
 	}
 
-	err = addons.GenerateAddon(template.Addons, uc.provider, uc.addon, uc.dest, uc.userInputs, uc.templateWriter)
+	if uc.templateWriter, err = withPolicyMetadata(withNormalizedYAML(uc.templateWriter, "")); err != nil {
+		return err
+	}
+	if uc.templateWriter, _, err = withImageMirror(uc.templateWriter); err != nil {
+		return err
+	}
+	var capturedFiles *writers.FileMapWriter
+	uc.templateWriter, capturedFiles = withDependencyReport(uc.templateWriter)
+	result, err := addons.Apply(context.Background(), addons.AddonOptions{
+		Provider:         uc.provider,
+		Addon:            uc.addon,
+		Dest:             uc.dest,
+		Variables:        flagVariablesMap,
+		VariableDefaults: clusterDefaults,
+		StrictVariables:  strictVariables,
+		Prompt:           true,
+		TemplateWriter:   uc.templateWriter,
+	})
+	if err != nil {
+		return err
+	}
+	uc.userInputs = result.Variables
+	log.Debugf("wrote %s", result.Files)
+	if dryRun {
+		addonConfig.DraftConfig.RecordVariables(uc.templateVariableRecorder, uc.userInputs)
+	}
+	if err := writeDependencyReport(capturedFiles); err != nil {
+		return err
+	}
 
 	if dryRun {
+		if err := secrets.ProtectValues(dryRunRecorder.DryRunInfo.Variables, addonConfig.SecretVariableNames(), secrets.IdentityPath(secretsIdentity), redactSecrets); err != nil {
+			return err
+		}
 		dryRunText, err := json.MarshalIndent(dryRunRecorder.DryRunInfo, "", TWO_SPACES)
 		if err != nil {
 			return err
@@ -113,7 +158,7 @@ func (uc *updateCmd) run() error {
 			}
 		}
 	}
-	return err
+	return nil
 }
 
 func init() {
@@ -3,8 +3,11 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"path"
+	"sort"
 
+	"github.com/Azure/draft/pkg/reports"
 	"github.com/Azure/draft/pkg/safeguards"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -13,6 +16,7 @@ import (
 type validateCmd struct {
 	manifestPath    string
 	imagePullSecret bool
+	format          string
 }
 
 func init() {
@@ -38,6 +42,7 @@ func newValidateCmd() *cobra.Command {
 
 	f.StringVarP(&vc.manifestPath, "manifest", "m", "", "'manifest' asks for the path to the manifest")
 	f.BoolVarP(&vc.imagePullSecret, "imagePullSecret", "s", false, "'imagePullSecret' enables the Safeguard that checks for usage of an image pull secret within the manifest(s)")
+	f.StringVarP(&vc.format, "format", "f", string(reports.FormatText), "specify the format to print violations in (text, sarif or markdown), sarif and markdown are printed to stdout")
 
 	return cmd
 }
@@ -47,6 +52,10 @@ func (vc *validateCmd) run(c *cobra.Command) error {
 	if vc.manifestPath == "" {
 		return fmt.Errorf("path to the manifests cannot be empty")
 	}
+	format, err := reports.ParseFormat(vc.format, reports.FormatText, reports.FormatSARIF, reports.FormatMarkdown)
+	if err != nil {
+		return err
+	}
 
 	// AddSafeguardCRIP just adds Container Restricted Image Pulls to the list of safeguards the client will review
 	// against the given manifest
@@ -82,14 +91,31 @@ func (vc *validateCmd) run(c *cobra.Command) error {
 		return err
 	}
 
+	if format != reports.FormatText {
+		findings := violationFindings(manifestFiles, manifestViolations)
+		if err := writeFindings(c.OutOrStdout(), format, fmt.Sprintf("Draft validate results for %s", vc.manifestPath), findings); err != nil {
+			return err
+		}
+		if len(findings) > 0 {
+			c.SilenceUsage = true
+			return fmt.Errorf("violations found")
+		}
+		return nil
+	}
+
 	anyViolationsFound := false
 	for _, v := range manifestViolations {
 		log.Printf("Analyzing %s for violations", v.Name)
 		manifestHasViolations := false
 		// returning the full list of violations after each manifest is checked
-		for file, violations := range v.ObjectViolations {
+		files := make([]string, 0, len(v.ObjectViolations))
+		for file := range v.ObjectViolations {
+			files = append(files, file)
+		}
+		sort.Strings(files)
+		for _, file := range files {
 			log.Printf("  %s:", file)
-			for _, violation := range violations {
+			for _, violation := range v.ObjectViolations[file] {
 				log.Printf("    ❌ %s", violation)
 				anyViolationsFound = true
 				manifestHasViolations = true
@@ -109,3 +135,39 @@ func (vc *validateCmd) run(c *cobra.Command) error {
 
 	return nil
 }
+
+// violationFindings converts safeguard violations into findings located in the manifest file they were found in.
+// Results are returned by GetManifestResults in the same order as manifestFiles.
+func violationFindings(manifestFiles []safeguards.ManifestFile, results []safeguards.ManifestResult) []reports.Finding {
+	var findings []reports.Finding
+	for i, result := range results {
+		file := result.Name
+		if i < len(manifestFiles) {
+			file = manifestFiles[i].Path
+		}
+		for _, v := range result.Violations {
+			ruleID := v.Constraint
+			if ruleID == "" {
+				ruleID = "safeguards"
+			}
+			findings = append(findings, reports.Finding{
+				RuleID:  ruleID,
+				Level:   reports.LevelError,
+				Message: v.Message,
+				File:    file,
+				Object:  v.Object,
+			})
+		}
+	}
+	return findings
+}
+
+func writeFindings(out io.Writer, format reports.Format, title string, findings []reports.Finding) error {
+	switch format {
+	case reports.FormatSARIF:
+		return reports.WriteSARIF(out, VERSION, findings)
+	case reports.FormatMarkdown:
+		return reports.WriteMarkdownFindings(out, title, findings)
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"path"
@@ -8,7 +9,9 @@ import (
 
 	"testing"
 
+	"github.com/Azure/draft/pkg/reports"
 	"github.com/Azure/draft/pkg/safeguards"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -95,3 +98,27 @@ func TestRunValidate(t *testing.T) {
 	numViolations = countTestViolations(v)
 	assert.Greater(t, numViolations, 0)
 }
+
+// TestValidateFindings tests that violations are reported as findings in the manifest they were found in
+func TestValidateFindings(t *testing.T) {
+	manifestPathFileError, _ := filepath.Abs("../pkg/safeguards/tests/all/error/all-error-manifest-1.yaml")
+	manifestFiles, err := safeguards.GetManifestFiles(manifestPathFileError)
+	assert.Nil(t, err)
+	v, err := safeguards.GetManifestResults(context.TODO(), manifestFiles)
+	assert.Nil(t, err)
+
+	findings := violationFindings(manifestFiles, v)
+	assert.Greater(t, len(findings), 0)
+	for _, f := range findings {
+		assert.Equal(t, manifestPathFileError, f.File)
+		assert.NotEmpty(t, f.RuleID)
+		assert.NotEmpty(t, f.Object)
+	}
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, writeFindings(buf, reports.FormatSARIF, "", findings))
+	assert.Contains(t, buf.String(), `"ruleId": "K8sAzureV2ContainerEnforceProbes"`)
+
+	vc := &validateCmd{manifestPath: manifestPathFileError, format: "xml"}
+	assert.ErrorContains(t, vc.run(&cobra.Command{}), "unsupported format")
+}
@@ -1,34 +1,107 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"runtime/debug"
+
+	"github.com/Azure/draft/pkg/linguist"
+	"github.com/Azure/draft/template"
 )
 
 var VERSION = "v0.0.7"
 
+type versionCmd struct {
+	format string
+}
+
+// versionInfo is what draft version reports, for triaging differences between the files generated by two installs
+type versionInfo struct {
+	Version string    `json:"version"`
+	Build   buildInfo `json:"build"`
+	// Templates are the embedded templates and their versions, newest first
+	Templates []templateVersions `json:"templates"`
+	// KubernetesAPIVersions are the apiVersions of the Kubernetes resources the embedded templates generate
+	KubernetesAPIVersions []kubernetesAPIVersion `json:"kubernetesApiVersions"`
+	// LinguistData is a fingerprint of the language detection data embedded in draft
+	LinguistData string `json:"linguistData"`
+}
+
+type buildInfo struct {
+	Revision  string `json:"revision,omitempty"`
+	Time      string `json:"time,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+}
+
+type templateVersions struct {
+	Artifact string   `json:"artifact"`
+	Name     string   `json:"name"`
+	Versions []string `json:"versions"`
+}
+
+type kubernetesAPIVersion struct {
+	APIVersion string   `json:"apiVersion"`
+	Kinds      []string `json:"kinds,omitempty"`
+}
+
+var (
+	apiVersionLine = regexp.MustCompile(`(?m)^apiVersion:\s*["']?([^\s"']+)`)
+	kindLine       = regexp.MustCompile(`(?m)^kind:\s*["']?([^\s"']+)`)
+)
+
 func newVersionCmd() *cobra.Command {
+	vc := &versionCmd{}
 	// versionCmd represents the version command
 	var version = &cobra.Command{
 		Use:   "version",
 		Short: "Get the current version of Draft",
-		Long:  `Returns the running version of Draft`,
+		Long: `Returns the running version of Draft. Pass --format json to also report the build metadata, the versions of
+the embedded templates, the Kubernetes API versions they generate and the version of the language detection data.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-
-			vcsInfo := getVCSInfoFromRuntime()
-
-			fmt.Println("version: ", VERSION)
-			fmt.Println("runtime SHA: ", vcsInfo)
-			return nil
+			return vc.run(cmd.OutOrStdout())
 		},
 	}
+	version.Flags().StringVarP(&vc.format, "format", "f", "text", "specify the format to print the version in (text or json)")
 
 	return version
 
 }
 
+func (vc *versionCmd) run(out io.Writer) error {
+	switch strings.ToLower(vc.format) {
+	case "text":
+		fmt.Fprintln(out, "version: ", VERSION)
+		fmt.Fprintln(out, "runtime SHA: ", getVCSInfoFromRuntime())
+		return nil
+	case "json":
+	default:
+		return fmt.Errorf("invalid format %q, must be text or json", vc.format)
+	}
+
+	info, err := getVersionInfo()
+	if err != nil {
+		return err
+	}
+	infoText, err := json.MarshalIndent(info, "", TWO_SPACES)
+	if err != nil {
+		return fmt.Errorf("could not marshal version info into json: %w", err)
+	}
+	fmt.Fprintln(out, string(infoText))
+	return nil
+}
+
 func getVCSInfoFromRuntime() string {
 	buildInfo, ok := debug.ReadBuildInfo()
 	if !ok {
@@ -44,6 +117,101 @@ func getVCSInfoFromRuntime() string {
 	return ""
 }
 
+func getVersionInfo() (*versionInfo, error) {
+	info := &versionInfo{
+		Version: VERSION,
+		Build: buildInfo{
+			GoVersion: runtime.Version(),
+			Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		},
+		LinguistData: linguist.DataVersion(),
+	}
+	if b, ok := debug.ReadBuildInfo(); ok {
+		for _, kv := range b.Settings {
+			switch kv.Key {
+			case "vcs.revision":
+				info.Build.Revision = kv.Value
+			case "vcs.time":
+				info.Build.Time = kv.Value
+			case "vcs.modified":
+				info.Build.Modified = kv.Value == "true"
+			}
+		}
+	}
+
+	templates, err := listTemplates()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range templates {
+		info.Templates = append(info.Templates, templateVersions{Artifact: t.artifact, Name: t.name, Versions: t.versions})
+	}
+
+	info.KubernetesAPIVersions, err = kubernetesAPIVersions(template.Deployments, template.Addons)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// kubernetesAPIVersions returns the apiVersions and kinds of the resources in the current version of the templates
+// of fsyss. The draft.yaml of the templates and the Chart.yaml of helm charts aren't Kubernetes resources, and
+// apiVersions and kinds chosen by template expressions, such as the workload kind, are left out.
+func kubernetesAPIVersions(fsyss ...fs.FS) ([]kubernetesAPIVersion, error) {
+	kinds := make(map[string]map[string]bool)
+	for _, fsys := range fsyss {
+		err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if strings.Contains(d.Name(), "@") {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			ext := path.Ext(p)
+			if (ext != ".yaml" && ext != ".yml") || d.Name() == "draft.yaml" || d.Name() == "Chart.yaml" {
+				return nil
+			}
+
+			content, err := fs.ReadFile(fsys, p)
+			if err != nil {
+				return err
+			}
+			for _, doc := range strings.Split(string(content), "\n---") {
+				apiVersion := apiVersionLine.FindStringSubmatch(doc)
+				kind := kindLine.FindStringSubmatch(doc)
+				if apiVersion == nil || strings.Contains(apiVersion[1], "{{") {
+					continue
+				}
+				if kinds[apiVersion[1]] == nil {
+					kinds[apiVersion[1]] = make(map[string]bool)
+				}
+				if kind != nil && !strings.Contains(kind[1], "{{") {
+					kinds[apiVersion[1]][kind[1]] = true
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	apiVersions := make([]kubernetesAPIVersion, 0, len(kinds))
+	for apiVersion, kindSet := range kinds {
+		v := kubernetesAPIVersion{APIVersion: apiVersion}
+		for kind := range kindSet {
+			v.Kinds = append(v.Kinds, kind)
+		}
+		sort.Strings(v.Kinds)
+		apiVersions = append(apiVersions, v)
+	}
+	sort.Slice(apiVersions, func(i, j int) bool { return apiVersions[i].APIVersion < apiVersions[j].APIVersion })
+	return apiVersions, nil
+}
+
 func init() {
 	rootCmd.AddCommand(newVersionCmd())
 }
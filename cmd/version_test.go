@@ -1,11 +1,55 @@
 package cmd
 
 import (
-	"github.com/stretchr/testify/assert"
+	"bytes"
+	"encoding/json"
 	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestGetVersionAtRuntime(t *testing.T) {
 	vcsInfo := getVCSInfoFromRuntime()
 	assert.Empty(t, vcsInfo)
 }
+
+func TestVersionFormats(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.Nil(t, (&versionCmd{format: "text"}).run(buf))
+	assert.Contains(t, buf.String(), "version:  "+VERSION+"\n")
+
+	buf.Reset()
+	assert.Nil(t, (&versionCmd{format: "json"}).run(buf))
+	var info versionInfo
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &info))
+	assert.Equal(t, VERSION, info.Version)
+	assert.NotEmpty(t, info.Build.GoVersion)
+	assert.Regexp(t, `^sha256:[0-9a-f]{12}$`, info.LinguistData)
+	templates := map[string][]string{}
+	for _, tv := range info.Templates {
+		templates[tv.Artifact+"/"+tv.Name] = tv.Versions
+	}
+	assert.NotEmpty(t, templates["dockerfile/go"])
+	assert.NotEmpty(t, templates["deployment/helm"])
+	assert.Contains(t, info.KubernetesAPIVersions, kubernetesAPIVersion{APIVersion: "autoscaling/v2", Kinds: []string{"HorizontalPodAutoscaler"}})
+
+	assert.ErrorContains(t, (&versionCmd{format: "yaml"}).run(buf), `invalid format "yaml"`)
+}
+
+func TestKubernetesAPIVersions(t *testing.T) {
+	templates := fstest.MapFS{
+		"manifests/draft.yaml":         {Data: []byte("variables: []\n")},
+		"manifests/service.yaml":       {Data: []byte("apiVersion: v1\nkind: Service\n---\napiVersion: v1\nkind: ConfigMap\n")},
+		"manifests/deployment.yaml":    {Data: []byte("apiVersion: apps/v1\nkind: {{WORKLOADKIND}}\n")},
+		"manifests/templated.yaml":     {Data: []byte("apiVersion: {{APIVERSION}}\nkind: Job\n")},
+		"charts/Chart.yaml":            {Data: []byte("apiVersion: v2\nname: chart\n")},
+		"manifests@1.0.0/ingress.yaml": {Data: []byte("apiVersion: networking.k8s.io/v1beta1\nkind: Ingress\n")},
+	}
+	apiVersions, err := kubernetesAPIVersions(templates)
+	assert.Nil(t, err)
+	assert.Equal(t, []kubernetesAPIVersion{
+		{APIVersion: "apps/v1"},
+		{APIVersion: "v1", Kinds: []string{"ConfigMap", "Service"}},
+	}, apiVersions)
+}
@@ -23,6 +23,8 @@ import (
 type AddonConfig struct {
 	config.DraftConfig  `yaml:",inline"`
 	ReferenceComponents map[string][]referenceResource `yaml:"references"`
+	// DeploymentEnvFrom are the secrets and config maps the addon adds to the envFrom of the application's container
+	DeploymentEnvFrom []EnvFromSource `yaml:"deploymentEnvFrom"`
 
 	deployType string
 }
@@ -50,7 +52,11 @@ func (ac *AddonConfig) GetAddonDestPath(dest string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return path.Join(dest, consts.DeploymentFilePaths[deployType]), err
+	deploymentPath, ok := consts.DeploymentFilePaths[deployType]
+	if !ok {
+		return "", fmt.Errorf("addons are not supported for the %s deployment type", deployType)
+	}
+	return path.Join(dest, deploymentPath), nil
 }
 
 // GetReferenceValueMap extracts k8s object values into a mapping of template strings to k8s object value.
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/fs"
 	"path"
+	"sort"
 	"strings"
 
 	"github.com/manifoldco/promptui"
@@ -41,16 +42,19 @@ func GenerateAddon(addons embed.FS, provider, addon, dest string, userInputs map
 
 	addOnConfig.ApplyDefaultVariables(userInputs)
 
-	if err = osutil.CopyDir(addons, selectedAddonPath, addonDestPath, &addOnConfig.DraftConfig, userInputs, templateWriter); err != nil {
+	if err = osutil.CopyDir(embedutils.WithTemplateDir(addons), selectedAddonPath, addonDestPath, &addOnConfig.DraftConfig, userInputs, templateWriter); err != nil {
 		return err
 	}
 
-	return err
+	if len(addOnConfig.DeploymentEnvFrom) > 0 {
+		return addOnConfig.AddDeploymentEnvFrom(dest, userInputs, templateWriter)
+	}
+	return nil
 }
 
 func GetAddonPath(addons embed.FS, provider, addon string) (string, error) {
 	providerPath := path.Join(parentDirName, strings.ToLower(provider))
-	addonMap, err := embedutils.EmbedFStoMap(addons, providerPath)
+	addonMap, err := embedutils.EmbedFStoMap(embedutils.WithTemplateDir(addons), providerPath)
 	if err != nil {
 		return "", err
 	}
@@ -74,7 +78,7 @@ func GetAddonConfig(addons embed.FS, provider, addon string) (AddonConfig, error
 	addOnConfigPath := path.Join(selectedAddonPath, "draft.yaml")
 	log.Debugf("addOnConfig is: %s", addOnConfigPath)
 
-	configBytes, err := fs.ReadFile(addons, addOnConfigPath)
+	configBytes, err := fs.ReadFile(embedutils.WithTemplateDir(addons), addOnConfigPath)
 	if err != nil {
 		return AddonConfig{}, err
 	}
@@ -88,17 +92,18 @@ func GetAddonConfig(addons embed.FS, provider, addon string) (AddonConfig, error
 
 func PromptAddon(addons embed.FS, provider string) (string, error) {
 	providerPath := path.Join(parentDirName, strings.ToLower(provider))
-	addonMap, err := embedutils.EmbedFStoMap(addons, providerPath)
+	addonMap, err := embedutils.EmbedFStoMap(embedutils.WithTemplateDir(addons), providerPath)
 	if err != nil {
 		return "", err
 	}
 
 	addonNames := maps.Keys(addonMap)
+	sort.Strings(addonNames)
 	prompt := promptui.Select{
 		Label: fmt.Sprintf("Select %s addon", provider),
 		Items: addonNames,
 	}
-	_, addon, err := prompt.Run()
+	_, addon, err := prompts.RunSelect(&prompt)
 	if err != nil {
 		return "", err
 	}
@@ -110,9 +115,8 @@ func PromptAddonValues(dest string, userInputs map[string]string, addOnConfig Ad
 	log.Debugf("getAddonValues: %s", userInputs)
 	var err error
 
-	inputsToSkip := maps.Keys(userInputs)
-	log.Debugf("inputsToSkip: %s", inputsToSkip)
-	promptInputs, err := prompts.RunPromptsFromConfigWithSkips(&addOnConfig.DraftConfig, inputsToSkip)
+	log.Debugf("inputsToSkip: %s", maps.Keys(userInputs))
+	promptInputs, err := prompts.RunPromptsFromConfigWithValues(&addOnConfig.DraftConfig, userInputs)
 	if err != nil {
 		return nil, err
 	}
@@ -121,6 +125,11 @@ func PromptAddonValues(dest string, userInputs map[string]string, addOnConfig Ad
 		userInputs[k] = v
 	}
 
+	return mergeReferenceValues(dest, userInputs, addOnConfig)
+}
+
+// mergeReferenceValues adds the values the addon references from the deployment files in dest to userInputs
+func mergeReferenceValues(dest string, userInputs map[string]string, addOnConfig AddonConfig) (map[string]string, error) {
 	referenceMap, err := addOnConfig.GetReferenceValueMap(dest)
 	if err != nil {
 		return nil, err
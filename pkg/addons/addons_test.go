@@ -3,6 +3,7 @@ package addons
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -67,6 +68,36 @@ func TestGenerateKustomizeAddonSuccess(t *testing.T) {
 	assert.Nil(t, remove())
 }
 
+func TestGenerateSecurityHeadersAddon(t *testing.T) {
+	addonConfig, err := GetAddonConfig(template.Addons, "azure", "security_headers")
+	assert.Nil(t, err)
+	userInputs := map[string]string{
+		"ingress-host":                  "host",
+		"ingress-tls-cert-keyvault-uri": "test.uri",
+		"service-namespace":             "test-namespace",
+		"service-name":                  "test-service",
+		"service-port":                  "80",
+	}
+	addonConfig.ApplyDefaultVariables(userInputs)
+	assert.Equal(t, "20", userInputs["security-rate-limit-rps"])
+
+	dir, remove, err := setUpTempDir("manifests")
+	assert.Nil(t, err)
+	defer remove()
+
+	err = GenerateAddon(template.Addons, "azure", "security_headers", dir, userInputs, &writers.LocalFSWriter{})
+	assert.Nil(t, err)
+	ingress, err := os.ReadFile(filepath.Join(dir, "manifests", "ingress.yaml"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(ingress), `nginx.ingress.kubernetes.io/force-ssl-redirect: "true"`)
+	assert.Contains(t, string(ingress), `more_set_headers "Content-Security-Policy: default-src 'self'";`)
+	assert.Contains(t, string(ingress), `more_set_headers "Strict-Transport-Security: max-age=31536000; includeSubDomains";`)
+
+	userInputs["security-rate-limit-rps"] = "0"
+	err = GenerateAddon(template.Addons, "azure", "security_headers", dir, userInputs, &writers.LocalFSWriter{})
+	assert.ErrorContains(t, err, `invalid value "0" for security-rate-limit-rps`)
+}
+
 func setUpTempDir(deploy string) (dir string, close func() error, err error) {
 	templateWriter := &writers.LocalFSWriter{}
 	dir, err = ioutil.TempDir("", "addonTest")
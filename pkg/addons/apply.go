@@ -0,0 +1,129 @@
+package addons
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
+
+	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/prompts"
+	"github.com/Azure/draft/pkg/templatewriter"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+// AddonOptions configures an Apply of an addon to the deployment files of a project
+type AddonOptions struct {
+	// Provider is the cloud provider of the addon, azure when empty
+	Provider string
+	// Addon is the name of the addon, such as webapp_routing
+	Addon string
+	// Dest is the project directory holding the deployment files the addon references
+	Dest string
+	// Variables are the values of the addon's variables
+	Variables map[string]string
+	// VariableDefaults replace the defaults of the addon's variables, as --inspect-cluster does
+	VariableDefaults map[string]string
+	// StrictVariables rejects Variables the addon does not define
+	StrictVariables bool
+	// Prompt prompts for the variables missing from Variables, as draft update does. Otherwise they take their
+	// defaults and a prompts.MissingVariablesError lists those without one.
+	Prompt bool
+	// TemplateWriter writes the addon's files, a writers.LocalFSWriter when nil
+	TemplateWriter templatewriter.TemplateWriter
+}
+
+// Result is what an Apply of an addon did
+type Result struct {
+	// Files are the paths of the files written, sorted
+	Files []string
+	// Variables are the values used, including the defaults and the values referenced from the deployment files
+	Variables map[string]string
+}
+
+// Apply generates an addon with the same semantics as draft update, without its command line
+func Apply(ctx context.Context, opts AddonOptions) (Result, error) {
+	if opts.Addon == "" {
+		return Result{}, errors.New("no addon given")
+	}
+	provider := opts.Provider
+	if provider == "" {
+		provider = "azure"
+	}
+
+	addonConfig, err := GetAddonConfig(template.Addons, provider, opts.Addon)
+	if err != nil {
+		return Result{}, err
+	}
+
+	defaultNames := maps.Keys(opts.VariableDefaults)
+	sort.Strings(defaultNames)
+	for _, name := range defaultNames {
+		if addonConfig.DraftConfig.SetVariableDefault(name, opts.VariableDefaults[name]) {
+			log.Debugf("defaulting %s to %s", name, opts.VariableDefaults[name])
+		}
+	}
+
+	if opts.StrictVariables {
+		if err := config.ValidateVariableNames(maps.Keys(opts.Variables), &addonConfig.DraftConfig); err != nil {
+			return Result{}, fmt.Errorf("--strict-variables: %w", err)
+		}
+	}
+
+	userInputs := maps.Clone(opts.Variables)
+	if userInputs == nil {
+		userInputs = make(map[string]string)
+	}
+	if opts.Prompt {
+		userInputs, err = PromptAddonValues(opts.Dest, userInputs, addonConfig)
+	} else {
+		userInputs, err = DefaultAddonValues(opts.Dest, userInputs, addonConfig)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	log.Debugf("addonInputs is: %s", userInputs)
+
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	templateWriter := opts.TemplateWriter
+	if templateWriter == nil {
+		templateWriter = &writers.LocalFSWriter{}
+	}
+	written := &writers.FileMapWriter{}
+	templateWriter = &writers.MultiWriter{Writers: []templatewriter.TemplateWriter{templateWriter, written}}
+	if err := GenerateAddon(template.Addons, provider, opts.Addon, opts.Dest, userInputs, templateWriter); err != nil {
+		return Result{}, err
+	}
+
+	files := maps.Keys(written.FileMap)
+	sort.Strings(files)
+	return Result{Files: files, Variables: userInputs}, nil
+}
+
+// DefaultAddonValues is PromptAddonValues without prompting: the variables missing from userInputs take their
+// defaults, and a prompts.MissingVariablesError lists those without one
+func DefaultAddonValues(dest string, userInputs map[string]string, addOnConfig AddonConfig) (map[string]string, error) {
+	missing := &prompts.MissingVariablesError{}
+	for _, variable := range addOnConfig.Variables {
+		if _, ok := userInputs[variable.Name]; ok {
+			continue
+		}
+		if !prompts.HasVariableDefault(variable.Name, addOnConfig.VariableDefaults) {
+			missing.Variables = append(missing.Variables, variable)
+			continue
+		}
+		userInputs[variable.Name] = prompts.GetVariableDefaultValue(variable.Name, addOnConfig.VariableDefaults, userInputs)
+	}
+	if len(missing.Variables) > 0 {
+		return nil, missing
+	}
+
+	return mergeReferenceValues(dest, userInputs, addOnConfig)
+}
@@ -0,0 +1,51 @@
+package addons
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/prompts"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+func TestApply(t *testing.T) {
+	dir, remove, err := setUpTempDir("manifests")
+	assert.Nil(t, err)
+	defer remove()
+
+	variables := map[string]string{
+		"ingress-tls-cert-keyvault-uri": "test.uri",
+		"ingress-use-osm-mtls":          "false",
+		"ingress-host":                  "host",
+	}
+	w := &writers.FileMapWriter{}
+	result, err := Apply(context.Background(), AddonOptions{Addon: "webapp_routing", Dest: dir, Variables: variables, TemplateWriter: w})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "manifests", "ingress.yaml")}, result.Files)
+	assert.Contains(t, w.FileMap, filepath.Join(dir, "manifests", "ingress.yaml"))
+	assert.Equal(t, "host", result.Variables["ingress-host"])
+	assert.Equal(t, "draft", result.Variables["GENERATORLABEL"], "the defaults are among the values used")
+	assert.NotEmpty(t, result.Variables["service-name"], "the references are among the values used")
+	assert.Len(t, variables, 3, "the given variables are not changed")
+
+	_, err = Apply(context.Background(), AddonOptions{Addon: "webapp_routing", Dest: dir, Variables: map[string]string{"ingress-host": "host"}, TemplateWriter: w})
+	var missing *prompts.MissingVariablesError
+	assert.ErrorAs(t, err, &missing)
+	assert.Len(t, missing.Variables, 2)
+
+	variables["ingres-host"] = "typo"
+	_, err = Apply(context.Background(), AddonOptions{Addon: "webapp_routing", Dest: dir, Variables: variables, StrictVariables: true, TemplateWriter: w})
+	assert.ErrorContains(t, err, "--strict-variables")
+
+	_, err = Apply(context.Background(), AddonOptions{Addon: "fakeAddon", Dest: dir, TemplateWriter: w})
+	assert.NotNil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	delete(variables, "ingres-host")
+	_, err = Apply(ctx, AddonOptions{Addon: "webapp_routing", Dest: dir, Variables: variables, TemplateWriter: &writers.FileMapWriter{}})
+	assert.ErrorIs(t, err, context.Canceled)
+}
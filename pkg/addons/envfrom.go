@@ -0,0 +1,109 @@
+package addons
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/consts"
+	"github.com/Azure/draft/pkg/templaterender"
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
+// EnvFromSource is a secret or config map, named with addon variables, whose keys are exposed to the application as
+// environment variables
+type EnvFromSource struct {
+	SecretRef    string `yaml:"secretRef"`
+	ConfigMapRef string `yaml:"configMapRef"`
+}
+
+// AddDeploymentEnvFrom adds the addon's DeploymentEnvFrom sources to the envFrom of the first container of the
+// deployment in dest. Sources the container already has are left as they are.
+func (ac *AddonConfig) AddDeploymentEnvFrom(dest string, userInputs map[string]string, templateWriter templatewriter.TemplateWriter) error {
+	deployType, err := ac.getDeployType(dest)
+	if err != nil {
+		return err
+	}
+	deploymentPath, ok := consts.DeploymentManifestPaths[deployType]
+	if !ok {
+		return fmt.Errorf("addons are not supported for the %s deployment type", deployType)
+	}
+	deploymentPath = path.Join(dest, deploymentPath)
+
+	content, err := os.ReadFile(deploymentPath)
+	if err != nil {
+		return fmt.Errorf("reading deployment: %w", err)
+	}
+
+	sources := make([]EnvFromSource, len(ac.DeploymentEnvFrom))
+	for i, source := range ac.DeploymentEnvFrom {
+		sources[i] = EnvFromSource{
+			SecretRef:    replaceAddonVariables(source.SecretRef, userInputs),
+			ConfigMapRef: replaceAddonVariables(source.ConfigMapRef, userInputs),
+		}
+	}
+	updated, err := addEnvFrom(string(content), sources)
+	if err != nil {
+		return fmt.Errorf("%s: %w", deploymentPath, err)
+	}
+
+	log.Debugf("adding envFrom sources to %s", deploymentPath)
+	return templateWriter.WriteFile(deploymentPath, []byte(updated))
+}
+
+func replaceAddonVariables(s string, userInputs map[string]string) string {
+	return templaterender.Substitute(s, userInputs)
+}
+
+// addEnvFrom adds sources to the envFrom list of the first container in a deployment, creating the list after the
+// container's image when there isn't one. It edits the text rather than parsing it so helm templates can be updated too.
+func addEnvFrom(content string, sources []EnvFromSource) (string, error) {
+	lines := strings.Split(content, "\n")
+
+	insertAt := -1
+	var listIndent string
+	var header []string
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "envFrom:" {
+			insertAt = i + 1
+			listIndent = leadingSpaces(line) + "  "
+			header = nil
+			break
+		}
+		if insertAt == -1 && strings.HasPrefix(strings.TrimSpace(line), "image:") {
+			insertAt = i + 1
+			listIndent = leadingSpaces(line) + "  "
+			header = []string{leadingSpaces(line) + "envFrom:"}
+		}
+	}
+	if insertAt == -1 {
+		return "", fmt.Errorf("no container image found to add envFrom to")
+	}
+
+	var entries []string
+	for _, source := range sources {
+		kind, name := "secretRef", source.SecretRef
+		if name == "" {
+			kind, name = "configMapRef", source.ConfigMapRef
+		}
+		entry := []string{listIndent + "- " + kind + ":", listIndent + "    name: " + name}
+		if strings.Contains(content, strings.Join(entry, "\n")) {
+			continue
+		}
+		entries = append(entries, entry...)
+	}
+	if len(entries) == 0 {
+		return content, nil
+	}
+
+	inserted := append(header, entries...)
+	lines = append(lines[:insertAt], append(inserted, lines[insertAt:]...)...)
+	return strings.Join(lines, "\n"), nil
+}
+
+func leadingSpaces(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " "))]
+}
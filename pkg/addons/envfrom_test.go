@@ -0,0 +1,83 @@
+package addons
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+const envFromDeployment = `spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          image: app:latest
+          env:
+            - name: PORT
+              value: "80"
+`
+
+func TestAddEnvFrom(t *testing.T) {
+	sources := []EnvFromSource{{SecretRef: "app-redis"}, {ConfigMapRef: "app-settings"}}
+	updated, err := addEnvFrom(envFromDeployment, sources)
+	assert.Nil(t, err)
+	assert.Equal(t, `spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          image: app:latest
+          envFrom:
+            - secretRef:
+                name: app-redis
+            - configMapRef:
+                name: app-settings
+          env:
+            - name: PORT
+              value: "80"
+`, updated)
+
+	again, err := addEnvFrom(updated, append(sources, EnvFromSource{SecretRef: "app-storage"}))
+	assert.Nil(t, err)
+	assert.Contains(t, again, `          envFrom:
+            - secretRef:
+                name: app-storage
+            - secretRef:
+                name: app-redis
+`)
+	assert.Equal(t, 1, strings.Count(again, "name: app-redis"))
+
+	_, err = addEnvFrom("kind: Service\n", sources)
+	assert.NotNil(t, err)
+}
+
+func TestGenerateAddonDeploymentEnvFrom(t *testing.T) {
+	userInputs := map[string]string{
+		"azure-resource-group": "my-group",
+		"azure-location":       "westus2",
+		"redis-name":           "my-redis",
+		"redis-sku":            "Basic",
+		"GENERATORLABEL":       "draft",
+		"service-name":         "test-service",
+		"service-namespace":    "test-namespace",
+	}
+	dir, remove, err := setUpTempDir("manifests")
+	assert.Nil(t, err)
+	defer remove()
+
+	assert.Nil(t, GenerateAddon(template.Addons, "azure", "aso_redis_cache", dir, userInputs, &writers.LocalFSWriter{}))
+
+	redis, err := os.ReadFile(path.Join(dir, "manifests", "redis.yaml"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(redis), "azureName: my-redis")
+	deployment, err := os.ReadFile(path.Join(dir, "manifests", "deployment.yaml"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(deployment), "- secretRef:\n")
+	assert.Contains(t, string(deployment), "name: test-service-redis\n")
+}
@@ -0,0 +1,173 @@
+// Package appconfig discovers the configuration keys of Spring, ASP.NET and dotenv applications and renders them as
+// the ConfigMap and Secret the application's deployment reads them from.
+package appconfig
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+// secretKeyWords are the words that mark a key as holding a secret when its name contains one of them
+var secretKeyWords = []string{"password", "passwd", "pwd", "secret", "token", "apikey", "accesskey", "privatekey", "connectionstring", "credential"}
+
+// Extractors returns the extractors of every supported config file format, in the order their keys take precedence
+func Extractors() []reporeader.ConfigExtractor {
+	return []reporeader.ConfigExtractor{
+		&SpringConfigExtractor{},
+		&AppSettingsExtractor{},
+		&DotEnvExtractor{},
+	}
+}
+
+// Discover returns the config keys found by every extractor. When several keys map to the same environment variable
+// only the first one is kept.
+func Discover(r reporeader.RepoReader) ([]reporeader.ConfigKey, error) {
+	var keys []reporeader.ConfigKey
+	seen := make(map[string]bool)
+	for _, extractor := range Extractors() {
+		found, err := extractor.ReadConfig(r)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s config: %w", extractor.GetName(), err)
+		}
+		for _, key := range found {
+			if seen[key.EnvName] {
+				continue
+			}
+			seen[key.EnvName] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// IsSecret reports whether the key name looks like it holds a secret, such as a password, token or connection string
+func IsSecret(name string) bool {
+	lower := strings.NewReplacer("-", "", "_", "", ".", "", ":", "").Replace(strings.ToLower(name))
+	for _, word := range secretKeyWords {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// Group is the keys of one section of a config file
+type Group struct {
+	Name   string
+	Source string
+	Keys   []reporeader.ConfigKey
+}
+
+// KeyNames returns the names of the group's keys
+func (g Group) KeyNames() []string {
+	names := make([]string, len(g.Keys))
+	for i, key := range g.Keys {
+		names[i] = key.Name
+	}
+	return names
+}
+
+// GroupKeys groups keys by their source and group, sorted by source and then group name
+func GroupKeys(keys []reporeader.ConfigKey) []Group {
+	var groups []Group
+	index := make(map[[2]string]int)
+	for _, key := range keys {
+		id := [2]string{key.Source, key.Group}
+		i, ok := index[id]
+		if !ok {
+			i = len(groups)
+			index[id] = i
+			groups = append(groups, Group{Name: key.Group, Source: key.Source})
+		}
+		groups[i].Keys = append(groups[i].Keys, key)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Source != groups[j].Source {
+			return groups[i].Source < groups[j].Source
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// ConfigMapName returns the name of the ConfigMap holding the config of appName
+func ConfigMapName(appName string) string {
+	return appName + "-config"
+}
+
+// SecretName returns the name of the Secret holding the secret config of appName
+func SecretName(appName string) string {
+	return appName + "-secrets"
+}
+
+type manifestMetadata struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels"`
+}
+
+type manifest struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   manifestMetadata  `yaml:"metadata"`
+	Type       string            `yaml:"type,omitempty"`
+	Data       map[string]string `yaml:"data,omitempty"`
+	StringData map[string]string `yaml:"stringData,omitempty"`
+}
+
+// secretHeader is written above the generated Secret, whose values are left empty to keep them out of source control
+const secretHeader = "# The values of this Secret are left empty so they are not committed. Set them when deploying,\n" +
+	"# for example with kubectl create secret generic --from-literal, or replace this file with a secret store.\n"
+
+// Manifests renders the ConfigMap of the keys that are not secrets, keeping their values, and the Secret of the keys
+// that are, with empty values. Either is nil when there are no keys for it.
+func Manifests(appName, namespace string, keys []reporeader.ConfigKey) (configMap, secret []byte, err error) {
+	data := make(map[string]string)
+	secretData := make(map[string]string)
+	for _, key := range keys {
+		if IsSecret(key.Name) {
+			secretData[key.EnvName] = ""
+		} else {
+			data[key.EnvName] = key.Value
+		}
+	}
+
+	metadata := func(name string) manifestMetadata {
+		return manifestMetadata{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{"app.kubernetes.io/name": appName},
+		}
+	}
+	if len(data) > 0 {
+		if configMap, err = marshalManifest(manifest{APIVersion: "v1", Kind: "ConfigMap", Metadata: metadata(ConfigMapName(appName)), Data: data}); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(secretData) > 0 {
+		if secret, err = marshalManifest(manifest{APIVersion: "v1", Kind: "Secret", Metadata: metadata(SecretName(appName)), Type: "Opaque", StringData: secretData}); err != nil {
+			return nil, nil, err
+		}
+		secret = append([]byte(secretHeader), secret...)
+	}
+	return configMap, secret, nil
+}
+
+func marshalManifest(m manifest) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(m); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,131 @@
+package appconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+func TestReadConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		extractor reporeader.ConfigExtractor
+		files     map[string][]byte
+		want      []reporeader.ConfigKey
+	}{
+		{
+			name:      "spring properties",
+			extractor: &SpringConfigExtractor{},
+			files: map[string][]byte{
+				"src/main/resources/application.properties": []byte("# comment\nserver.port=8080\nspring.datasource.url = jdbc:postgresql://db/app\nspring.datasource.password: changeme\nmy-app.feature-flag \\\n  true\n"),
+			},
+			want: []reporeader.ConfigKey{
+				{Name: "my-app.feature-flag", EnvName: "MYAPP_FEATUREFLAG", Value: "true", Group: "my-app", Source: "src/main/resources/application.properties"},
+				{Name: "server.port", EnvName: "SERVER_PORT", Value: "8080", Group: "server", Source: "src/main/resources/application.properties"},
+				{Name: "spring.datasource.password", EnvName: "SPRING_DATASOURCE_PASSWORD", Value: "changeme", Group: "spring.datasource", Source: "src/main/resources/application.properties"},
+				{Name: "spring.datasource.url", EnvName: "SPRING_DATASOURCE_URL", Value: "jdbc:postgresql://db/app", Group: "spring.datasource", Source: "src/main/resources/application.properties"},
+			},
+		},
+		{
+			name:      "spring yaml first document",
+			extractor: &SpringConfigExtractor{},
+			files: map[string][]byte{
+				"application.yaml": []byte("spring:\n  kafka:\n    bootstrap-servers:\n      - kafka:9092\nserver:\n  port: 8080\n---\nserver:\n  port: 9090\n"),
+			},
+			want: []reporeader.ConfigKey{
+				{Name: "server.port", EnvName: "SERVER_PORT", Value: "8080", Group: "server", Source: "application.yaml"},
+				{Name: "spring.kafka.bootstrap-servers[0]", EnvName: "SPRING_KAFKA_BOOTSTRAPSERVERS_0", Value: "kafka:9092", Group: "spring.kafka", Source: "application.yaml"},
+			},
+		},
+		{
+			name:      "appsettings",
+			extractor: &AppSettingsExtractor{},
+			files: map[string][]byte{
+				"api/appsettings.json":             []byte(`{"ConnectionStrings": {"Default": "Server=db"}, "Logging": {"LogLevel": {"Default": "Information"}}, "AllowedHosts": "*", "Origins": ["a", "b"]}`),
+				"api/appsettings.Development.json": []byte(`{"AllowedHosts": "localhost"}`),
+			},
+			want: []reporeader.ConfigKey{
+				{Name: "AllowedHosts", EnvName: "AllowedHosts", Value: "*", Group: "AllowedHosts", Source: "api/appsettings.json"},
+				{Name: "ConnectionStrings:Default", EnvName: "ConnectionStrings__Default", Value: "Server=db", Group: "ConnectionStrings", Source: "api/appsettings.json"},
+				{Name: "Logging:LogLevel:Default", EnvName: "Logging__LogLevel__Default", Value: "Information", Group: "Logging", Source: "api/appsettings.json"},
+				{Name: "Origins:0", EnvName: "Origins__0", Value: "a", Group: "Origins", Source: "api/appsettings.json"},
+				{Name: "Origins:1", EnvName: "Origins__1", Value: "b", Group: "Origins", Source: "api/appsettings.json"},
+			},
+		},
+		{
+			name:      "dotenv",
+			extractor: &DotEnvExtractor{},
+			files: map[string][]byte{
+				".env": []byte("# comment\nexport DB_HOST=localhost\nDB_PASSWORD=\"p#ss word\"\nPORT=3000 # inline\n"),
+			},
+			want: []reporeader.ConfigKey{
+				{Name: "DB_HOST", EnvName: "DB_HOST", Value: "localhost", Group: "DB", Source: ".env"},
+				{Name: "DB_PASSWORD", EnvName: "DB_PASSWORD", Value: "p#ss word", Group: "DB", Source: ".env"},
+				{Name: "PORT", EnvName: "PORT", Value: "3000", Group: "PORT", Source: ".env"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.extractor.ReadConfig(reporeader.FakeRepoReader{Files: tt.files})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDiscoverAndGroup(t *testing.T) {
+	r := reporeader.FakeRepoReader{Files: map[string][]byte{
+		"application.properties": []byte("server.port=8080\n"),
+		".env":                   []byte("SERVER_PORT=9090\nAPI_TOKEN=abc\nAPI_URL=http://api\n"),
+	}}
+	keys, err := Discover(r)
+	assert.Nil(t, err)
+	assert.Len(t, keys, 3)
+
+	groups := GroupKeys(keys)
+	assert.Len(t, groups, 2)
+	assert.Equal(t, "API", groups[0].Name)
+	assert.Equal(t, ".env", groups[0].Source)
+	assert.Equal(t, []string{"API_TOKEN", "API_URL"}, groups[0].KeyNames())
+	assert.Equal(t, "server", groups[1].Name)
+	assert.Equal(t, "8080", groups[1].Keys[0].Value)
+}
+
+func TestIsSecret(t *testing.T) {
+	for _, name := range []string{"spring.datasource.password", "ConnectionStrings:Default", "API_TOKEN", "stripe.api-key", "CLIENT_SECRET"} {
+		assert.True(t, IsSecret(name), name)
+	}
+	for _, name := range []string{"server.port", "Logging:LogLevel:Default", "DB_HOST"} {
+		assert.False(t, IsSecret(name), name)
+	}
+}
+
+func TestManifests(t *testing.T) {
+	configMap, secret, err := Manifests("myapp", "prod", []reporeader.ConfigKey{
+		{Name: "server.port", EnvName: "SERVER_PORT", Value: "8080"},
+		{Name: "spring.datasource.password", EnvName: "SPRING_DATASOURCE_PASSWORD", Value: "changeme"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: myapp-config
+  namespace: prod
+  labels:
+    app.kubernetes.io/name: myapp
+data:
+  SERVER_PORT: "8080"
+`, string(configMap))
+	assert.Contains(t, string(secret), "kind: Secret\n")
+	assert.Contains(t, string(secret), "  name: myapp-secrets\n")
+	assert.Contains(t, string(secret), "  SPRING_DATASOURCE_PASSWORD: \"\"\n")
+	assert.NotContains(t, string(secret), "changeme")
+
+	configMap, secret, err = Manifests("myapp", "", []reporeader.ConfigKey{{Name: "API_TOKEN", EnvName: "API_TOKEN"}})
+	assert.Nil(t, err)
+	assert.Nil(t, configMap)
+	assert.NotContains(t, string(secret), "namespace:")
+}
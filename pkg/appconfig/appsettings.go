@@ -0,0 +1,68 @@
+package appconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+// appSettingsSearchDepth is how many directories deep appsettings.json is looked for, to find projects in a folder
+// without picking up the copies in their build output
+const appSettingsSearchDepth = 1
+
+// AppSettingsExtractor reads the keys of ASP.NET Core appsettings.json files. The environment specific files such
+// as appsettings.Development.json are left out.
+type AppSettingsExtractor struct {
+}
+
+// GetName implements reporeader.ConfigExtractor
+func (*AppSettingsExtractor) GetName() string {
+	return "appsettings"
+}
+
+// ReadConfig implements reporeader.ConfigExtractor
+func (*AppSettingsExtractor) ReadConfig(r reporeader.RepoReader) ([]reporeader.ConfigKey, error) {
+	files, err := r.FindFiles(".", []string{"appsettings.json"}, appSettingsSearchDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []reporeader.ConfigKey
+	for _, source := range files {
+		content, err := r.ReadFile(source)
+		if err != nil {
+			return nil, err
+		}
+		var document interface{}
+		if err := json.Unmarshal(content, &document); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", source, err)
+		}
+
+		values := make(map[string]string)
+		flatten("", document, ":", values)
+		for _, name := range sortedNames(values) {
+			group, _, _ := strings.Cut(name, ":")
+			keys = append(keys, reporeader.ConfigKey{
+				Name: name,
+				// the double underscore is the section separator ASP.NET Core accepts on every platform
+				EnvName: strings.ReplaceAll(name, ":", "__"),
+				Value:   values[name],
+				Group:   group,
+				Source:  source,
+			})
+		}
+	}
+	return keys, nil
+}
+
+func sortedNames(values map[string]string) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,78 @@
+package appconfig
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+// dotEnvFiles are the dotenv files read, the example file being the one committed when .env is ignored
+var dotEnvFiles = []string{".env", ".env.example"}
+
+// DotEnvExtractor reads the variables of .env files, as used by Node.js applications with dotenv
+type DotEnvExtractor struct {
+}
+
+// GetName implements reporeader.ConfigExtractor
+func (*DotEnvExtractor) GetName() string {
+	return "dotenv"
+}
+
+// ReadConfig implements reporeader.ConfigExtractor
+func (*DotEnvExtractor) ReadConfig(r reporeader.RepoReader) ([]reporeader.ConfigKey, error) {
+	var keys []reporeader.ConfigKey
+	for _, source := range dotEnvFiles {
+		if !r.Exists(source) {
+			continue
+		}
+		content, err := r.ReadFile(source)
+		if err != nil {
+			return nil, err
+		}
+		values := parseDotEnv(content)
+		for _, name := range sortedNames(values) {
+			keys = append(keys, reporeader.ConfigKey{
+				Name:    name,
+				EnvName: name,
+				Value:   values[name],
+				Group:   dotEnvGroup(name),
+				Source:  source,
+			})
+		}
+	}
+	return keys, nil
+}
+
+// parseDotEnv parses the KEY=value lines of a dotenv file, unquoting quoted values and dropping trailing comments
+// from unquoted ones
+func parseDotEnv(content []byte) map[string]string {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		} else if i := strings.Index(value, " #"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+		values[name] = value
+	}
+	return values
+}
+
+// dotEnvGroup returns the prefix of name before its first underscore, such as DB for DB_HOST
+func dotEnvGroup(name string) string {
+	group, _, _ := strings.Cut(name, "_")
+	return group
+}
@@ -0,0 +1,159 @@
+package appconfig
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+// springConfigDirs are the directories searched for the application config, in the order Spring Boot packages them
+var springConfigDirs = []string{"src/main/resources", "config", "."}
+
+var springConfigFiles = []string{"application.properties", "application.yaml", "application.yml"}
+
+// SpringConfigExtractor reads the keys of Spring Boot's application.properties and application.yaml files. Profile
+// specific files and documents are left out, the keys of the default profile are the ones every deployment needs.
+type SpringConfigExtractor struct {
+}
+
+// GetName implements reporeader.ConfigExtractor
+func (*SpringConfigExtractor) GetName() string {
+	return "spring"
+}
+
+// ReadConfig implements reporeader.ConfigExtractor
+func (*SpringConfigExtractor) ReadConfig(r reporeader.RepoReader) ([]reporeader.ConfigKey, error) {
+	var keys []reporeader.ConfigKey
+	for _, dir := range springConfigDirs {
+		for _, file := range springConfigFiles {
+			source := path.Join(dir, file)
+			if !r.Exists(source) {
+				continue
+			}
+			content, err := r.ReadFile(source)
+			if err != nil {
+				return nil, err
+			}
+
+			var values map[string]string
+			if strings.HasSuffix(file, ".properties") {
+				values = parseProperties(content)
+			} else if values, err = parseSpringYaml(content); err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", source, err)
+			}
+			for _, name := range sortedNames(values) {
+				keys = append(keys, reporeader.ConfigKey{
+					Name:    name,
+					EnvName: springEnvName(name),
+					Value:   values[name],
+					Group:   springGroup(name),
+					Source:  source,
+				})
+			}
+		}
+		if len(keys) > 0 {
+			return keys, nil
+		}
+	}
+	return keys, nil
+}
+
+// parseProperties parses the key value pairs of a java properties file, separated by =, : or whitespace
+func parseProperties(content []byte) map[string]string {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	var logical string
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if logical == "" && (line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!")) {
+			continue
+		}
+		if strings.HasSuffix(line, `\`) {
+			logical += strings.TrimSuffix(line, `\`)
+			continue
+		}
+		logical += line
+
+		end := strings.IndexAny(logical, "=: \t")
+		if end == -1 {
+			values[logical] = ""
+		} else {
+			value := strings.TrimLeft(logical[end:], " \t")
+			value = strings.TrimPrefix(strings.TrimPrefix(value, "="), ":")
+			values[logical[:end]] = strings.TrimSpace(value)
+		}
+		logical = ""
+	}
+	return values
+}
+
+// parseSpringYaml flattens the first document of a Spring yaml config into dotted keys
+func parseSpringYaml(content []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	var document interface{}
+	if err := yaml.NewDecoder(bytes.NewReader(content)).Decode(&document); err != nil {
+		if errors.Is(err, io.EOF) {
+			return values, nil
+		}
+		return nil, err
+	}
+	flatten("", document, ".", values)
+	return values, nil
+}
+
+// flatten adds the scalar values of node to values, joining the keys of nested maps with sep and indexing lists
+func flatten(prefix string, node interface{}, sep string, values map[string]string) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + sep + key
+	}
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for key, child := range n {
+			flatten(join(key), child, sep, values)
+		}
+	case map[interface{}]interface{}:
+		for key, child := range n {
+			flatten(join(fmt.Sprint(key)), child, sep, values)
+		}
+	case []interface{}:
+		for i, child := range n {
+			// spring indexes lists with brackets, ASP.NET with another section
+			key := fmt.Sprintf("%s[%d]", prefix, i)
+			if sep != "." {
+				key = join(fmt.Sprint(i))
+			}
+			flatten(key, child, sep, values)
+		}
+	case nil:
+		values[prefix] = ""
+	default:
+		values[prefix] = fmt.Sprint(n)
+	}
+}
+
+// springEnvName returns the environment variable Spring Boot's relaxed binding reads name from
+func springEnvName(name string) string {
+	replacer := strings.NewReplacer(".", "_", "-", "", "[", "_", "]", "")
+	return strings.ToUpper(replacer.Replace(name))
+}
+
+// springGroup returns the first segment of name, and the first two for the keys of spring's own namespaces such as
+// spring.datasource
+func springGroup(name string) string {
+	segments := strings.Split(name, ".")
+	if segments[0] == "spring" && len(segments) > 2 {
+		return strings.Join(segments[:2], ".")
+	}
+	return segments[0]
+}
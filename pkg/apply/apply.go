@@ -0,0 +1,303 @@
+// Package apply deploys the files draft generated for a deployment type to the cluster of the current kubeconfig
+// context, the way the generated workflows do: helm charts are rendered with their production values and, like
+// manifests and kustomizations, applied server-side as kubectl apply --server-side does.
+package apply
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// FieldManager owns the fields draft applies, so a later kubectl apply --server-side by the workflows takes them over
+// with --force-conflicts
+const FieldManager = "draft"
+
+// DefaultTimeout is how long Apply waits for the deployed workloads to roll out when Options.Timeout is zero
+const DefaultTimeout = 5 * time.Minute
+
+// pollInterval is how often the rollout of the deployed workloads is checked
+var pollInterval = 2 * time.Second
+
+// Options configures the deployment of generated files
+type Options struct {
+	// DeployType is the deployment type the files were generated for: helm, kustomize or manifests
+	DeployType string
+	// Dest is the directory the files were generated in
+	Dest string
+	// Namespace is the namespace of the resources that don't set one, default when empty
+	Namespace string
+	// Timeout bounds waiting for the workloads to roll out, DefaultTimeout when zero
+	Timeout time.Duration
+}
+
+// Resource is a deployed Kubernetes resource
+type Resource struct {
+	Kind      string
+	Namespace string
+	Name      string
+
+	gvr schema.GroupVersionResource
+}
+
+func (r Resource) String() string {
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s/%s", r.Kind, r.Name)
+	}
+	return fmt.Sprintf("%s/%s in %s", r.Kind, r.Name, r.Namespace)
+}
+
+// CurrentContext returns the name of the current kubeconfig context, the one Apply deploys to
+func CurrentContext() (string, error) {
+	rawConfig, err := kubeconfig().RawConfig()
+	if err != nil {
+		return "", fmt.Errorf("loading kubeconfig: %w", err)
+	}
+	if rawConfig.CurrentContext == "" {
+		return "", errors.New("no current context is set in the kubeconfig")
+	}
+	return rawConfig.CurrentContext, nil
+}
+
+// Apply deploys the files of opts.DeployType in opts.Dest to the cluster of the current kubeconfig context and waits
+// for the Deployments and StatefulSets among them to roll out
+func Apply(ctx context.Context, opts Options) ([]Resource, error) {
+	objects, err := Objects(opts.DeployType, opts.Dest, opts.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	applier, err := NewApplier()
+	if err != nil {
+		return nil, err
+	}
+	resources, err := applier.Apply(ctx, objects, opts.Namespace)
+	if err != nil {
+		return resources, err
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	return resources, applier.WaitForRollout(ctx, resources, timeout)
+}
+
+// Objects returns the resources the files of deployType in dest deploy, namespaces first
+func Objects(deployType, dest, namespace string) ([]*unstructured.Unstructured, error) {
+	var manifests []byte
+	var err error
+	switch deployType {
+	case "helm":
+		manifests, err = renderChart(filepath.Join(dest, "charts"), namespace)
+	case "kustomize":
+		manifests, err = buildKustomization(dest)
+	case "manifests":
+		manifests, err = readManifests(filepath.Join(dest, "manifests"))
+	default:
+		return nil, fmt.Errorf("deploying the %s deployment type is not supported, only helm, kustomize and manifests", deployType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := decodeObjects(manifests)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objects[i].GetKind() == "Namespace" && objects[j].GetKind() != "Namespace"
+	})
+	return objects, nil
+}
+
+// readManifests concatenates the yaml files of dir as documents of one stream
+func readManifests(dir string) ([]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var manifests bytes.Buffer
+	for _, entry := range entries {
+		if entry.IsDir() || !isYAML(entry.Name()) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		manifests.WriteString("\n---\n")
+		manifests.Write(content)
+	}
+	return manifests.Bytes(), nil
+}
+
+// decodeObjects decodes the yaml documents of manifests, skipping empty ones
+func decodeObjects(manifests []byte) ([]*unstructured.Unstructured, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifests), 4096)
+	var objects []*unstructured.Unstructured
+	for {
+		object := &unstructured.Unstructured{}
+		if err := decoder.Decode(&object.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, fmt.Errorf("decoding the manifests: %w", err)
+		}
+		if len(object.Object) == 0 {
+			continue
+		}
+		if object.GetKind() == "" || object.GetName() == "" {
+			return nil, fmt.Errorf("decoding the manifests: a %q resource has no kind or name", object.GetKind())
+		}
+		objects = append(objects, object)
+	}
+}
+
+// Applier applies resources server-side
+type Applier struct {
+	Client dynamic.Interface
+	Mapper meta.RESTMapper
+}
+
+// NewApplier returns an Applier for the cluster of the current kubeconfig context
+func NewApplier() (*Applier, error) {
+	restConfig, err := kubeconfig().ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading kubeconfig: %w", err)
+	}
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &Applier{
+		Client: client,
+		Mapper: restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient)),
+	}, nil
+}
+
+// Apply applies objects in order, in namespace when they're namespaced and don't set one, and returns the resources
+// applied before any error
+func (a *Applier) Apply(ctx context.Context, objects []*unstructured.Unstructured, namespace string) ([]Resource, error) {
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	var resources []Resource
+	for _, object := range objects {
+		gvk := object.GroupVersionKind()
+		mapping, err := a.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return resources, fmt.Errorf("finding the API of %s %s: %w", gvk.Kind, object.GetName(), err)
+		}
+
+		var client dynamic.ResourceInterface = a.Client.Resource(mapping.Resource)
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			if object.GetNamespace() == "" {
+				object.SetNamespace(namespace)
+			}
+			client = a.Client.Resource(mapping.Resource).Namespace(object.GetNamespace())
+		}
+
+		resource := Resource{Kind: gvk.Kind, Namespace: object.GetNamespace(), Name: object.GetName(), gvr: mapping.Resource}
+		data, err := object.MarshalJSON()
+		if err != nil {
+			return resources, err
+		}
+		force := true
+		if _, err := client.Patch(ctx, object.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{FieldManager: FieldManager, Force: &force}); err != nil {
+			return resources, fmt.Errorf("applying %s: %w", resource, err)
+		}
+		log.Infof("--> Applied %s", resource)
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
+
+// WaitForRollout waits until the Deployments and StatefulSets among resources have rolled out their latest spec to
+// every replica, or fails after timeout
+func (a *Applier) WaitForRollout(ctx context.Context, resources []Resource, timeout time.Duration) error {
+	for _, resource := range resources {
+		if resource.Kind != "Deployment" && resource.Kind != "StatefulSet" {
+			continue
+		}
+		log.Infof("--> Waiting for the rollout of %s...", resource)
+		var lastStatus string
+		err := wait.PollUntilContextTimeout(ctx, pollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+			object, err := a.Client.Resource(resource.gvr).Namespace(resource.Namespace).Get(ctx, resource.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			var done bool
+			done, lastStatus = rolledOut(object)
+			return done, nil
+		})
+		if err != nil {
+			if lastStatus != "" {
+				return fmt.Errorf("waiting for the rollout of %s (%s): %w", resource, lastStatus, err)
+			}
+			return fmt.Errorf("waiting for the rollout of %s: %w", resource, err)
+		}
+		log.Infof("--> %s rolled out", resource)
+	}
+	return nil
+}
+
+// rolledOut reports whether the controller of the workload has observed its latest spec and updated every replica to
+// it, with the progress so far otherwise
+func rolledOut(object *unstructured.Unstructured) (bool, string) {
+	generation := object.GetGeneration()
+	observed, _, _ := unstructured.NestedInt64(object.Object, "status", "observedGeneration")
+	if observed < generation {
+		return false, "the controller hasn't seen the latest spec yet"
+	}
+
+	replicas, found, _ := unstructured.NestedInt64(object.Object, "spec", "replicas")
+	if !found {
+		replicas = 1
+	}
+	updated, _, _ := unstructured.NestedInt64(object.Object, "status", "updatedReplicas")
+	ready := "availableReplicas"
+	if object.GetKind() == "StatefulSet" {
+		ready = "readyReplicas"
+	}
+	available, _, _ := unstructured.NestedInt64(object.Object, "status", ready)
+	if updated < replicas || available < replicas {
+		return false, fmt.Sprintf("%d of %d replicas updated, %d ready", updated, replicas, available)
+	}
+	return true, ""
+}
+
+func kubeconfig() clientcmd.ClientConfig {
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{})
+}
+
+// isYAML reports whether name is a yaml file
+func isYAML(name string) bool {
+	return strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml")
+}
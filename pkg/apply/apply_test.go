@@ -0,0 +1,105 @@
+package apply
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/Azure/draft/pkg/draft"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+func TestObjects(t *testing.T) {
+	for _, deployType := range []string{"helm", "kustomize", "manifests"} {
+		t.Run(deployType, func(t *testing.T) {
+			dest := t.TempDir()
+			_, err := draft.GenerateDeployment(context.Background(), draft.DeploymentOptions{
+				DeployType:     deployType,
+				Dest:           dest,
+				Variables:      map[string]string{"APPNAME": "app", "NAMESPACE": "apps", "IMAGENAME": "app", "SERVICEPORT": "80", "PORT": "80"},
+				TemplateWriter: &writers.LocalFSWriter{},
+			})
+			assert.Nil(t, err)
+
+			objects, err := Objects(deployType, dest, "")
+			assert.Nil(t, err)
+			kinds := map[string]bool{}
+			for _, object := range objects {
+				kinds[object.GetKind()] = true
+				if object.GetKind() == "Deployment" {
+					assert.Equal(t, "apps", object.GetNamespace())
+				}
+			}
+			assert.True(t, kinds["Deployment"])
+			assert.True(t, kinds["Service"])
+			if kinds["Namespace"] {
+				assert.Equal(t, "Namespace", objects[0].GetKind(), "namespaces are applied first")
+			}
+		})
+	}
+
+	_, err := Objects("appservice", t.TempDir(), "")
+	assert.ErrorContains(t, err, "deploying the appservice deployment type is not supported")
+}
+
+func TestApplier(t *testing.T) {
+	oldPollInterval := pollInterval
+	pollInterval = time.Millisecond
+	defer func() { pollInterval = oldPollInterval }()
+
+	deploymentsResource := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "app", "namespace": "apps", "generation": int64(2)},
+		"spec":       map[string]interface{}{"replicas": int64(2)},
+		"status":     map[string]interface{}{"observedGeneration": int64(2), "updatedReplicas": int64(2), "availableReplicas": int64(1)},
+	}}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{deploymentsResource: "DeploymentList"}, deployment)
+	var patched []string
+	client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		patched = append(patched, patch.GetNamespace()+"/"+patch.GetName())
+		return true, &unstructured.Unstructured{}, nil
+	})
+
+	a := &Applier{Client: client, Mapper: mapper}
+	namespace := &unstructured.Unstructured{}
+	namespace.SetAPIVersion("v1")
+	namespace.SetKind("Namespace")
+	namespace.SetName("apps")
+	app := &unstructured.Unstructured{}
+	app.SetAPIVersion("apps/v1")
+	app.SetKind("Deployment")
+	app.SetName("app")
+	resources, err := a.Apply(context.Background(), []*unstructured.Unstructured{namespace, app}, "apps")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/apps", "apps/app"}, patched, "the namespace is cluster scoped and the deployment defaults to the namespace")
+	assert.Equal(t, "Deployment/app in apps", resources[1].String())
+
+	err = a.WaitForRollout(context.Background(), resources, 20*time.Millisecond)
+	assert.ErrorContains(t, err, "2 of 2 replicas updated, 1 ready")
+
+	assert.Nil(t, unstructured.SetNestedField(deployment.Object, int64(2), "status", "availableReplicas"))
+	assert.Nil(t, client.Tracker().Update(deploymentsResource, deployment, "apps"))
+	assert.Nil(t, a.WaitForRollout(context.Background(), resources, time.Second))
+
+	unknown := &unstructured.Unstructured{}
+	unknown.SetAPIVersion("example.com/v1")
+	unknown.SetKind("Widget")
+	unknown.SetName("w")
+	_, err = a.Apply(context.Background(), []*unstructured.Unstructured{unknown}, "")
+	assert.ErrorContains(t, err, "finding the API of Widget w")
+}
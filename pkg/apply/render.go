@@ -0,0 +1,87 @@
+package apply
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// chartOverridesFile is the values file of the chart that the helm workflow renders it with
+const chartOverridesFile = "production.yaml"
+
+// kustomizeOverlay is the overlay the kustomize workflow deploys, the base is deployed when it doesn't exist
+const kustomizeOverlay = "overlays/production"
+
+// renderChart renders the chart in chartDir with its production values like helm template does, releasing it under
+// the chart's name in namespace
+func renderChart(chartDir, namespace string) ([]byte, error) {
+	chart, err := loader.Load(chartDir)
+	if err != nil {
+		return nil, fmt.Errorf("loading the chart: %w", err)
+	}
+
+	overrides := chartutil.Values{}
+	overridesPath := filepath.Join(chartDir, chartOverridesFile)
+	if _, err := os.Stat(overridesPath); err == nil {
+		if overrides, err = chartutil.ReadValuesFile(overridesPath); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", overridesPath, err)
+		}
+	}
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	values, err := chartutil.ToRenderValues(chart, overrides, chartutil.ReleaseOptions{
+		Name:      chart.Name(),
+		Namespace: namespace,
+		Revision:  1,
+		IsInstall: true,
+	}, chartutil.DefaultCapabilities)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := engine.Render(chart, values)
+	if err != nil {
+		return nil, fmt.Errorf("rendering the chart: %w", err)
+	}
+
+	names := make([]string, 0, len(rendered))
+	for name := range rendered {
+		if isYAML(name) && path.Base(name)[0] != '_' {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var manifests bytes.Buffer
+	for _, name := range names {
+		manifests.WriteString("\n---\n")
+		manifests.WriteString(rendered[name])
+	}
+	return manifests.Bytes(), nil
+}
+
+// buildKustomization builds the production overlay of the kustomization in dest, or its base when there is none
+func buildKustomization(dest string) ([]byte, error) {
+	dir := filepath.Join(dest, kustomizeOverlay)
+	if _, err := os.Stat(dir); err != nil {
+		dir = filepath.Join(dest, "base")
+	}
+
+	kustomizer := krusty.MakeKustomizer(&krusty.Options{PluginConfig: &types.PluginConfig{}})
+	resources, err := kustomizer.Run(filesys.FileSystemOrOnDisk{}, dir)
+	if err != nil {
+		return nil, fmt.Errorf("building %s: %w", dir, err)
+	}
+	return resources.AsYaml()
+}
@@ -0,0 +1,151 @@
+package clusterinfo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Variables set from the inspected cluster when a template defines them
+const (
+	IngressClassVariable = "INGRESSCLASSNAME"
+	StorageClassVariable = "STORAGECLASSNAME"
+	GatewayClassVariable = "GATEWAYCLASSNAME"
+	CertManagerVariable  = "CERTMANAGERENABLED"
+)
+
+const (
+	defaultIngressClassAnnotation = "ingressclass.kubernetes.io/is-default-class"
+	defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+	certManagerGroupVersion       = "cert-manager.io/v1"
+)
+
+var gatewayClassResource = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gatewayclasses"}
+
+// ClusterInfo is what draft found installed in a cluster
+type ClusterInfo struct {
+	Context        string
+	IngressClasses []Class
+	StorageClasses []Class
+	GatewayClasses []Class
+	CertManager    bool
+}
+
+// Class is an ingress, storage or gateway class of the cluster
+type Class struct {
+	Name    string
+	Default bool
+}
+
+// InspectCurrentContext inspects the cluster of the current kubeconfig context
+func InspectCurrentContext(ctx context.Context) (*ClusterInfo, error) {
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{})
+	rawConfig, err := clientConfig.RawConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading kubeconfig: %w", err)
+	}
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading kubeconfig: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := Inspect(ctx, clientset, dynamicClient)
+	if err != nil {
+		return nil, fmt.Errorf("inspecting cluster of context %s: %w", rawConfig.CurrentContext, err)
+	}
+	info.Context = rawConfig.CurrentContext
+	return info, nil
+}
+
+// Inspect lists the ingress, storage and gateway classes of a cluster and checks whether cert-manager is installed
+func Inspect(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface) (*ClusterInfo, error) {
+	info := &ClusterInfo{}
+
+	ingressClasses, err := clientset.NetworkingV1().IngressClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing ingress classes: %w", err)
+	}
+	for _, c := range ingressClasses.Items {
+		info.IngressClasses = append(info.IngressClasses, Class{Name: c.Name, Default: isDefaultClass(c.Annotations, defaultIngressClassAnnotation)})
+	}
+
+	storageClasses, err := clientset.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing storage classes: %w", err)
+	}
+	for _, c := range storageClasses.Items {
+		info.StorageClasses = append(info.StorageClasses, Class{Name: c.Name, Default: isDefaultClass(c.Annotations, defaultStorageClassAnnotation)})
+	}
+
+	// the Gateway API CRDs are optional, so a missing resource only means there are no gateway classes
+	gatewayClasses, err := dynamicClient.Resource(gatewayClassResource).List(ctx, metav1.ListOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("listing gateway classes: %w", err)
+	}
+	if err == nil {
+		for _, c := range gatewayClasses.Items {
+			info.GatewayClasses = append(info.GatewayClasses, Class{Name: c.GetName()})
+		}
+	}
+
+	_, err = clientset.Discovery().ServerResourcesForGroupVersion(certManagerGroupVersion)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("checking for cert-manager: %w", err)
+	}
+	info.CertManager = err == nil
+
+	for _, classes := range [][]Class{info.IngressClasses, info.StorageClasses, info.GatewayClasses} {
+		sort.Slice(classes, func(i, j int) bool { return classes[i].Name < classes[j].Name })
+	}
+	return info, nil
+}
+
+func isDefaultClass(annotations map[string]string, annotation string) bool {
+	isDefault, _ := strconv.ParseBool(annotations[annotation])
+	return isDefault
+}
+
+// Defaults returns template variable defaults tailored to the cluster. Class variables are only set when the cluster
+// has a default class or exactly one class to choose from.
+func (c *ClusterInfo) Defaults() map[string]string {
+	defaults := map[string]string{CertManagerVariable: strconv.FormatBool(c.CertManager)}
+	for variable, classes := range map[string][]Class{
+		IngressClassVariable: c.IngressClasses,
+		StorageClassVariable: c.StorageClasses,
+		GatewayClassVariable: c.GatewayClasses,
+	} {
+		if name := preferredClass(classes); name != "" {
+			defaults[variable] = name
+		}
+	}
+	return defaults
+}
+
+func preferredClass(classes []Class) string {
+	for _, c := range classes {
+		if c.Default {
+			return c.Name
+		}
+	}
+	if len(classes) == 1 {
+		return classes[0].Name
+	}
+	return ""
+}
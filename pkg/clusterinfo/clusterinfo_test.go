@@ -0,0 +1,55 @@
+package clusterinfo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestInspect(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&networkingv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}},
+		&networkingv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: "webapprouting.kubernetes.azure.com", Annotations: map[string]string{defaultIngressClassAnnotation: "true"}}},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "managed-csi"}},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "azurefile-csi"}},
+	)
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{GroupVersion: certManagerGroupVersion}}
+
+	gatewayClass := &unstructured.Unstructured{}
+	gatewayClass.SetAPIVersion("gateway.networking.k8s.io/v1")
+	gatewayClass.SetKind("GatewayClass")
+	gatewayClass.SetName("istio")
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gatewayClassResource: "GatewayClassList"}, gatewayClass)
+
+	info, err := Inspect(context.Background(), clientset, dynamicClient)
+	assert.Nil(t, err)
+	assert.Equal(t, []Class{{Name: "nginx"}, {Name: "webapprouting.kubernetes.azure.com", Default: true}}, info.IngressClasses)
+	assert.Equal(t, []Class{{Name: "azurefile-csi"}, {Name: "managed-csi"}}, info.StorageClasses)
+	assert.Equal(t, []Class{{Name: "istio"}}, info.GatewayClasses)
+	assert.True(t, info.CertManager)
+
+	assert.Equal(t, map[string]string{
+		IngressClassVariable: "webapprouting.kubernetes.azure.com",
+		GatewayClassVariable: "istio",
+		CertManagerVariable:  "true",
+	}, info.Defaults(), "storage class is not guessed when there is no default and more than one class")
+}
+
+func TestInspectWithoutOptionalComponents(t *testing.T) {
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gatewayClassResource: "GatewayClassList"})
+
+	info, err := Inspect(context.Background(), fake.NewSimpleClientset(), dynamicClient)
+	assert.Nil(t, err)
+	assert.False(t, info.CertManager)
+	assert.Equal(t, map[string]string{CertManagerVariable: "false"}, info.Defaults())
+}
@@ -1,15 +1,29 @@
 package config
 
 import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
 	log "github.com/sirupsen/logrus"
 )
 
 // TODO: remove Name Overrides since we don't need them anymore
 type DraftConfig struct {
 	DisplayName      string              `yaml:"displayName"`
+	// Version is the semantic version of the template, bumped whenever its generated output changes
+	Version          string              `yaml:"version"`
 	NameOverrides    []FileNameOverride  `yaml:"nameOverrides"`
 	Variables        []BuilderVar        `yaml:"variables"`
 	VariableDefaults []BuilderVarDefault `yaml:"variableDefaults"`
+	OptionalFiles    []OptionalFile      `yaml:"optionalFiles"`
+	// TemplateDelimiters are the left and right delimiters of the text/template actions of the template's files, such
+	// as ["[[", "]]"]. The files of templates without them only get their {{VARIABLE}} placeholders substituted.
+	TemplateDelimiters []string `yaml:"templateDelimiters,omitempty"`
 
 	nameOverrideMap map[string]string
 }
@@ -19,11 +33,177 @@ type FileNameOverride struct {
 	Prefix string `yaml:"prefix"`
 }
 
+// OptionalFile is a template file, relative to the template directory, that is only rendered when Variable is "true"
+type OptionalFile struct {
+	Path     string `yaml:"path"`
+	Variable string `yaml:"variable"`
+	// WhenSet renders the file whenever Variable has a value, for variables such as schedules that aren't booleans
+	WhenSet bool `yaml:"whenSet"`
+}
+
+// Prompt stages of a variable. Advanced variables are only prompted for with --advanced and otherwise use their
+// default; variables without a stage are basic.
+const (
+	StageBasic    = "basic"
+	StageAdvanced = "advanced"
+)
+
 type BuilderVar struct {
 	Name             string   `yaml:"name"`
 	Description      string   `yaml:"description"`
 	VarType          string   `yaml:"type"`
 	ExampleValues    []string `yaml:"exampleValues"`
+	// Resource is the type of cloud resource the variable names, offered for selection by the prompts' ResourcePicker
+	Resource         string   `yaml:"resource,omitempty"`
+	// Stage is StageBasic or StageAdvanced, the prompt mode in which the variable is prompted for
+	Stage            string   `yaml:"stage,omitempty"`
+	// Value is the resolved value of the variable, set by callers that render templates without prompting
+	Value            string   `yaml:"value,omitempty"`
+	// AllowedValues are the options of a multiselect variable, or the only values a variable of another type accepts
+	AllowedValues    []string `yaml:"allowedValues,omitempty"`
+	// Min and Max are the inclusive bounds of an int or port variable, within 1-65535 for ports
+	Min              *int     `yaml:"min,omitempty"`
+	Max              *int     `yaml:"max,omitempty"`
+	// Pattern is a regular expression the whole value of the variable must match
+	Pattern          string   `yaml:"pattern,omitempty"`
+	// MinLength and MaxLength are the inclusive bounds of the number of characters of the value
+	MinLength        *int     `yaml:"minLength,omitempty"`
+	MaxLength        *int     `yaml:"maxLength,omitempty"`
+}
+
+// Types of numeric variables, whose values are integers within their Min and Max
+const (
+	VarTypeInt  = "int"
+	VarTypePort = "port"
+)
+
+// Bounds of the values of port variables
+const (
+	MinPort = 1
+	MaxPort = 65535
+)
+
+// VarTypeMultiSelect is the type of variables whose value is any number of their AllowedValues, joined by
+// MultiSelectSeparator
+const VarTypeMultiSelect = "multiselect"
+
+// MultiSelectSeparator separates the selected values of a multiselect variable
+const MultiSelectSeparator = ","
+
+// IsAdvanced returns whether the variable is only prompted for in advanced mode
+func (v BuilderVar) IsAdvanced() bool {
+	return v.Stage == StageAdvanced
+}
+
+// IsMultiSelect returns whether the variable is of the multiselect type
+func (v BuilderVar) IsMultiSelect() bool {
+	return v.VarType == VarTypeMultiSelect
+}
+
+// IsNumeric returns whether the variable is of the int or port type
+func (v BuilderVar) IsNumeric() bool {
+	return v.VarType == VarTypeInt || v.VarType == VarTypePort
+}
+
+// Range returns the inclusive bounds of the values of a numeric variable, nil when it is unbounded on that side
+func (v BuilderVar) Range() (min, max *int) {
+	min, max = v.Min, v.Max
+	if v.VarType == VarTypePort {
+		if min == nil || *min < MinPort {
+			minPort := MinPort
+			min = &minPort
+		}
+		if max == nil || *max > MaxPort {
+			maxPort := MaxPort
+			max = &maxPort
+		}
+	}
+	return min, max
+}
+
+// ValidateNumericValue returns an error when value isn't an integer within the Range of the int or port variable
+func (v BuilderVar) ValidateNumericValue(value string) error {
+	min, max := v.Range()
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || (min != nil && n < *min) || (max != nil && n > *max) {
+		return fmt.Errorf("invalid value %q for %s, must be %s", value, v.Name, describeRange(v.VarType, min, max))
+	}
+	return nil
+}
+
+func describeRange(varType string, min, max *int) string {
+	kind := "an integer"
+	if varType == VarTypePort {
+		kind = "a port number"
+	}
+	switch {
+	case min != nil && max != nil:
+		return fmt.Sprintf("%s from %d to %d", kind, *min, *max)
+	case min != nil:
+		return fmt.Sprintf("%s of at least %d", kind, *min)
+	case max != nil:
+		return fmt.Sprintf("%s of at most %d", kind, *max)
+	}
+	return kind
+}
+
+// SplitMultiSelectValue returns the selected values of the multiselect value, which may be empty
+func SplitMultiSelectValue(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, MultiSelectSeparator) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// ValidateMultiSelectValue returns an error when the value of the multiselect variable selects a value that isn't
+// one of its AllowedValues
+func (v BuilderVar) ValidateMultiSelectValue(value string) error {
+	for _, selected := range SplitMultiSelectValue(value) {
+		if !slices.Contains(v.AllowedValues, selected) {
+			return fmt.Errorf("invalid value %q for %s, must be a %q separated list of: %s", selected, v.Name, MultiSelectSeparator, strings.Join(v.AllowedValues, ", "))
+		}
+	}
+	return nil
+}
+
+// ValidateValue returns an error when value breaks a rule declared by the variable: its Pattern, MinLength and
+// MaxLength, its AllowedValues, and the Range of int and port variables
+func (v BuilderVar) ValidateValue(value string) error {
+	if v.IsMultiSelect() {
+		if err := v.ValidateMultiSelectValue(value); err != nil {
+			return err
+		}
+	} else {
+		if v.IsNumeric() {
+			if err := v.ValidateNumericValue(value); err != nil {
+				return err
+			}
+		}
+		if len(v.AllowedValues) > 0 && !slices.Contains(v.AllowedValues, value) {
+			return fmt.Errorf("invalid value %q for %s, must be one of: %s", value, v.Name, strings.Join(v.AllowedValues, ", "))
+		}
+	}
+
+	length := utf8.RuneCountInString(value)
+	if v.MinLength != nil && length < *v.MinLength {
+		return fmt.Errorf("invalid value %q for %s, must be at least %d characters long", value, v.Name, *v.MinLength)
+	}
+	if v.MaxLength != nil && length > *v.MaxLength {
+		return fmt.Errorf("invalid value %q for %s, must be at most %d characters long", value, v.Name, *v.MaxLength)
+	}
+	if v.Pattern != "" {
+		pattern, err := regexp.Compile(`^(?:` + v.Pattern + `)$`)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q of variable %s: %w", v.Pattern, v.Name, err)
+		}
+		if !pattern.MatchString(value) {
+			return fmt.Errorf("invalid value %q for %s, must match %s", value, v.Name, v.Pattern)
+		}
+	}
+	return nil
 }
 
 type BuilderVarDefault struct {
@@ -43,6 +223,97 @@ func (d *DraftConfig) GetVariableExampleValues() map[string][]string {
 	return variableExampleValues
 }
 
+// SecretVariableNames returns the names of the variables of type "secret", whose values must not be saved in plain text
+func (d *DraftConfig) SecretVariableNames() []string {
+	var names []string
+	for _, variable := range d.Variables {
+		if variable.VarType == "secret" {
+			names = append(names, variable.Name)
+		}
+	}
+	return names
+}
+
+// SetVariableDefault replaces the default value of the variable name, returning false when the config does not
+// define the variable
+func (d *DraftConfig) SetVariableDefault(name, value string) bool {
+	for i, variableDefault := range d.VariableDefaults {
+		if variableDefault.Name == name {
+			d.VariableDefaults[i].Value = value
+			d.VariableDefaults[i].ReferenceVar = ""
+			return true
+		}
+	}
+	for _, variable := range d.Variables {
+		if variable.Name == name {
+			d.VariableDefaults = append(d.VariableDefaults, BuilderVarDefault{Name: name, Value: value})
+			return true
+		}
+	}
+	return false
+}
+
+// VariableValues returns a map of variable names to the values set on the config's variables
+func (d *DraftConfig) VariableValues() map[string]string {
+	values := make(map[string]string)
+	for _, variable := range d.Variables {
+		if variable.Value != "" {
+			values[variable.Name] = variable.Value
+		}
+	}
+	return values
+}
+
+// ValidateVariableValues checks the values of the variables in inputs with ValidateValue. Empty values, such as
+// multiselect variables selecting nothing and unset variables left to the templates, aren't checked.
+func (d *DraftConfig) ValidateVariableValues(inputs map[string]string) error {
+	for _, variable := range d.Variables {
+		value := inputs[variable.Name]
+		if value == "" {
+			continue
+		}
+		if err := variable.ValidateValue(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordVariables records inputs with recorder, along with the selected values of the multiselect variables when
+// recorder is a MultiSelectRecorder
+func (d *DraftConfig) RecordVariables(recorder TemplateVariableRecorder, inputs map[string]string) {
+	names := make([]string, 0, len(inputs))
+	for k := range inputs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		recorder.Record(k, inputs[k])
+	}
+	multiSelectRecorder, ok := recorder.(MultiSelectRecorder)
+	if !ok {
+		return
+	}
+	for _, variable := range d.Variables {
+		if value, set := inputs[variable.Name]; set && variable.IsMultiSelect() {
+			multiSelectRecorder.RecordMultiSelect(variable.Name, SplitMultiSelectValue(value))
+		}
+	}
+}
+
+// IsFileEnabled returns whether the template file at path, relative to the template directory, should be rendered with inputs
+func (d *DraftConfig) IsFileEnabled(path string, inputs map[string]string) bool {
+	for _, optionalFile := range d.OptionalFiles {
+		if optionalFile.Path == path {
+			if optionalFile.WhenSet {
+				return inputs[optionalFile.Variable] != ""
+			}
+			return strings.EqualFold(inputs[optionalFile.Variable], "true")
+		}
+	}
+	return true
+}
+
 func (d *DraftConfig) initNameOverrideMap() {
 	d.nameOverrideMap = make(map[string]string)
 	log.Debug("initializing nameOverrideMap")
@@ -79,3 +350,8 @@ func (d *DraftConfig) ApplyDefaultVariables(customConfig map[string]string) {
 type TemplateVariableRecorder interface {
 	Record(key, value string)
 }
+
+// MultiSelectRecorder is a TemplateVariableRecorder that also records the selected values of multiselect variables
+type MultiSelectRecorder interface {
+	RecordMultiSelect(key string, values []string)
+}
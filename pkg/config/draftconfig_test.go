@@ -0,0 +1,94 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRecorder struct {
+	variables   map[string]string
+	multiSelect map[string][]string
+}
+
+func (r *testRecorder) Record(key, value string) {
+	r.variables[key] = value
+}
+
+func (r *testRecorder) RecordMultiSelect(key string, values []string) {
+	r.multiSelect[key] = values
+}
+
+func TestMultiSelectVariables(t *testing.T) {
+	d := &DraftConfig{Variables: []BuilderVar{
+		{Name: "APPNAME"},
+		{Name: "INGRESSANNOTATIONS", VarType: VarTypeMultiSelect, AllowedValues: []string{"cors", "rewrite", "ssl-redirect"}},
+	}}
+
+	assert.Equal(t, []string{"cors", "ssl-redirect"}, SplitMultiSelectValue(" cors, ,ssl-redirect"))
+	assert.Nil(t, d.ValidateVariableValues(map[string]string{"APPNAME": "any,thing", "INGRESSANNOTATIONS": "cors,rewrite"}))
+	assert.Nil(t, d.ValidateVariableValues(map[string]string{"INGRESSANNOTATIONS": ""}))
+	assert.ErrorContains(t, d.ValidateVariableValues(map[string]string{"INGRESSANNOTATIONS": "cors,hsts"}), `invalid value "hsts" for INGRESSANNOTATIONS`)
+
+	recorder := &testRecorder{variables: map[string]string{}, multiSelect: map[string][]string{}}
+	d.RecordVariables(recorder, map[string]string{"APPNAME": "app", "INGRESSANNOTATIONS": "cors,rewrite"})
+	assert.Equal(t, map[string]string{"APPNAME": "app", "INGRESSANNOTATIONS": "cors,rewrite"}, recorder.variables)
+	assert.Equal(t, map[string][]string{"INGRESSANNOTATIONS": {"cors", "rewrite"}}, recorder.multiSelect)
+}
+
+func TestNumericVariables(t *testing.T) {
+	one, ten := 1, 10
+	replicas := BuilderVar{Name: "REPLICAS", VarType: VarTypeInt, Min: &one, Max: &ten}
+	port := BuilderVar{Name: "PORT", VarType: VarTypePort}
+
+	min, max := port.Range()
+	assert.Equal(t, MinPort, *min)
+	assert.Equal(t, MaxPort, *max)
+	min, max = BuilderVar{Name: "COUNT", VarType: VarTypeInt}.Range()
+	assert.Nil(t, min)
+	assert.Nil(t, max)
+
+	assert.Nil(t, replicas.ValidateNumericValue("10"))
+	assert.EqualError(t, replicas.ValidateNumericValue("11"), `invalid value "11" for REPLICAS, must be an integer from 1 to 10`)
+	assert.EqualError(t, replicas.ValidateNumericValue("two"), `invalid value "two" for REPLICAS, must be an integer from 1 to 10`)
+	assert.Nil(t, port.ValidateNumericValue("8080"))
+	assert.EqualError(t, port.ValidateNumericValue("0"), `invalid value "0" for PORT, must be a port number from 1 to 65535`)
+
+	d := &DraftConfig{Variables: []BuilderVar{replicas, port}}
+	assert.Nil(t, d.ValidateVariableValues(map[string]string{"REPLICAS": "", "PORT": "80"}))
+	assert.ErrorContains(t, d.ValidateVariableValues(map[string]string{"PORT": "70000"}), `invalid value "70000" for PORT`)
+}
+
+func TestDeclaredValidationRules(t *testing.T) {
+	three, eight := 3, 8
+	appName := BuilderVar{Name: "APPNAME", Pattern: "[a-z][a-z0-9-]*", MinLength: &three, MaxLength: &eight}
+	tier := BuilderVar{Name: "TIER", AllowedValues: []string{"web", "worker"}}
+
+	assert.Nil(t, appName.ValidateValue("my-app"))
+	assert.EqualError(t, appName.ValidateValue("ab"), `invalid value "ab" for APPNAME, must be at least 3 characters long`)
+	assert.EqualError(t, appName.ValidateValue("my-application"), `invalid value "my-application" for APPNAME, must be at most 8 characters long`)
+	// the pattern matches the whole value
+	assert.EqualError(t, appName.ValidateValue("my_app"), `invalid value "my_app" for APPNAME, must match [a-z][a-z0-9-]*`)
+	assert.Nil(t, tier.ValidateValue("worker"))
+	assert.EqualError(t, tier.ValidateValue("batch"), `invalid value "batch" for TIER, must be one of: web, worker`)
+	assert.ErrorContains(t, BuilderVar{Name: "HOST", Pattern: "[a-z"}.ValidateValue("host"), `invalid pattern "[a-z" of variable HOST`)
+
+	d := &DraftConfig{Variables: []BuilderVar{appName, tier}}
+	assert.Nil(t, d.ValidateVariableValues(map[string]string{"APPNAME": "my-app", "TIER": ""}))
+	assert.ErrorContains(t, d.ValidateVariableValues(map[string]string{"APPNAME": "my-app", "TIER": "batch"}), `invalid value "batch" for TIER`)
+}
+
+type orderRecorder struct {
+	keys []string
+}
+
+func (r *orderRecorder) Record(key, value string) {
+	r.keys = append(r.keys, key)
+}
+
+func TestRecordVariablesSorted(t *testing.T) {
+	recorder := &orderRecorder{}
+	d := &DraftConfig{}
+	d.RecordVariables(recorder, map[string]string{"PORT": "80", "APPNAME": "app", "NAMESPACE": "default", "IMAGENAME": "app"})
+	assert.Equal(t, []string{"APPNAME", "IMAGENAME", "NAMESPACE", "PORT"}, recorder.keys)
+}
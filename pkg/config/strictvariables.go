@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// VariableNames returns the names of all variables and variable defaults declared in the config
+func (d *DraftConfig) VariableNames() []string {
+	seen := make(map[string]bool)
+	names := make([]string, 0, len(d.Variables)+len(d.VariableDefaults))
+	for _, variable := range d.Variables {
+		if !seen[variable.Name] {
+			seen[variable.Name] = true
+			names = append(names, variable.Name)
+		}
+	}
+	for _, variableDefault := range d.VariableDefaults {
+		if !seen[variableDefault.Name] {
+			seen[variableDefault.Name] = true
+			names = append(names, variableDefault.Name)
+		}
+	}
+	return names
+}
+
+// ValidateVariableNames returns an error listing every name that is not declared by any of the configs,
+// suggesting the closest declared variable name for each
+func ValidateVariableNames(names []string, configs ...*DraftConfig) error {
+	known := make(map[string]bool)
+	for _, c := range configs {
+		if c == nil {
+			continue
+		}
+		for _, name := range c.VariableNames() {
+			known[name] = true
+		}
+	}
+	knownNames := make([]string, 0, len(known))
+	for name := range known {
+		knownNames = append(knownNames, name)
+	}
+	sort.Strings(knownNames)
+
+	sortedNames := append([]string{}, names...)
+	sort.Strings(sortedNames)
+
+	var unknown []string
+	for _, name := range sortedNames {
+		if known[name] {
+			continue
+		}
+		if suggestion := closestName(name, knownNames); suggestion != "" {
+			unknown = append(unknown, fmt.Sprintf("%s (did you mean %s?)", name, suggestion))
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown variables: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// closestName returns the candidate with the smallest case-insensitive edit distance to name, if it is close enough
+// to plausibly be a typo
+func closestName(name string, candidates []string) string {
+	best := ""
+	bestDistance := -1
+	for _, candidate := range candidates {
+		distance := levenshtein(strings.ToUpper(name), strings.ToUpper(candidate))
+		if bestDistance == -1 || distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+	if bestDistance == -1 || bestDistance > len(name)/2 {
+		return ""
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(br)]
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateVariableNames(t *testing.T) {
+	langConfig := &DraftConfig{
+		Variables:        []BuilderVar{{Name: "PORT"}, {Name: "VERSION"}},
+		VariableDefaults: []BuilderVarDefault{{Name: "BUILDERVERSION", Value: "1"}},
+	}
+	workflowConfig := &DraftConfig{
+		Variables: []BuilderVar{{Name: "CONTAINERNAME"}, {Name: "AZURECONTAINERREGISTRY"}},
+	}
+
+	assert.Nil(t, ValidateVariableNames([]string{"PORT", "BUILDERVERSION", "CONTAINERNAME"}, langConfig, workflowConfig, nil))
+	assert.Nil(t, ValidateVariableNames(nil, langConfig))
+
+	err := ValidateVariableNames([]string{"CONTAINER_NAME", "PORT"}, langConfig, workflowConfig)
+	assert.EqualError(t, err, "unknown variables: CONTAINER_NAME (did you mean CONTAINERNAME?)")
+
+	err = ValidateVariableNames([]string{"verison", "SOMETHINGELSEENTIRELY"}, langConfig)
+	assert.EqualError(t, err, "unknown variables: SOMETHINGELSEENTIRELY, verison (did you mean VERSION?)")
+}
+
+func TestVariableNames(t *testing.T) {
+	c := &DraftConfig{
+		Variables:        []BuilderVar{{Name: "A"}, {Name: "B"}},
+		VariableDefaults: []BuilderVarDefault{{Name: "B"}, {Name: "C"}},
+	}
+	assert.Equal(t, []string{"A", "B", "C"}, c.VariableNames())
+}
+
+func TestSetVariableDefault(t *testing.T) {
+	c := &DraftConfig{
+		Variables:        []BuilderVar{{Name: "GATEWAYCLASSNAME"}, {Name: "STORAGECLASSNAME"}, {Name: "IMAGENAME"}},
+		VariableDefaults: []BuilderVarDefault{{Name: "GATEWAYCLASSNAME", Value: "istio"}, {Name: "IMAGENAME", ReferenceVar: "APPNAME"}},
+	}
+
+	assert.True(t, c.SetVariableDefault("GATEWAYCLASSNAME", "envoy"))
+	assert.True(t, c.SetVariableDefault("STORAGECLASSNAME", "managed-csi"))
+	assert.True(t, c.SetVariableDefault("IMAGENAME", "myimage"))
+	assert.False(t, c.SetVariableDefault("INGRESSCLASSNAME", "nginx"))
+
+	assert.Equal(t, []BuilderVarDefault{
+		{Name: "GATEWAYCLASSNAME", Value: "envoy"},
+		{Name: "IMAGENAME", Value: "myimage"},
+		{Name: "STORAGECLASSNAME", Value: "managed-csi"},
+	}, c.VariableDefaults)
+}
@@ -21,3 +21,10 @@ var DeploymentFilePaths = map[string]string{
 	"kustomize": "overlays/production",
 	"manifests": "manifests",
 }
+
+// DeploymentManifestPaths are the files holding the application's Deployment for each deployment type
+var DeploymentManifestPaths = map[string]string{
+	"helm":      "charts/templates/deployment.yaml",
+	"kustomize": "base/deployment.yaml",
+	"manifests": "manifests/deployment.yaml",
+}
@@ -2,7 +2,11 @@ package cred
 
 import (
 	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+
+	"github.com/Azure/draft/pkg/netconfig"
 )
 
 var (
@@ -15,7 +19,9 @@ func GetCred() (*azidentity.DefaultAzureCredential, error) {
 	}
 
 	var err error
-	cred, err = azidentity.NewDefaultAzureCredential(nil)
+	cred, err = azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{
+		ClientOptions: azcore.ClientOptions{Transport: netconfig.HTTPClient()},
+	})
 	if err != nil {
 		return nil, fmt.Errorf("authenticating to Azure: %w", err)
 	}
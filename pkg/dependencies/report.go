@@ -0,0 +1,288 @@
+package dependencies
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	BOMFormat   = "CycloneDX"
+	SpecVersion = "1.5"
+
+	TypeContainer   = "container"
+	TypeApplication = "application"
+
+	// FileProperty is the component property listing the generated files that reference the dependency
+	FileProperty = "draft:file"
+	// KindProperty is the component property describing what kind of dependency the component is
+	KindProperty = "draft:kind"
+)
+
+var workflowUsesRegex = regexp.MustCompile(`^\s*(?:-\s*)?uses:\s*["']?([^\s"'#]+)`)
+
+// Report is a CycloneDX-style bill of materials of the external dependencies referenced by generated files
+type Report struct {
+	BOMFormat   string      `json:"bomFormat"`
+	SpecVersion string      `json:"specVersion"`
+	Metadata    Metadata    `json:"metadata"`
+	Components  []Component `json:"components"`
+}
+
+type Metadata struct {
+	Tools []Tool `json:"tools,omitempty"`
+}
+
+type Tool struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type Component struct {
+	Type       string     `json:"type"`
+	Name       string     `json:"name"`
+	Version    string     `json:"version,omitempty"`
+	PURL       string     `json:"purl,omitempty"`
+	Hashes     []Hash     `json:"hashes,omitempty"`
+	Properties []Property `json:"properties,omitempty"`
+}
+
+type Hash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type Property struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type chartFile struct {
+	Dependencies []struct {
+		Name       string `yaml:"name"`
+		Version    string `yaml:"version"`
+		Repository string `yaml:"repository"`
+	} `yaml:"dependencies"`
+}
+
+// NewReport scans files, a map of generated file paths to their contents, for base images, GitHub actions and helm
+// chart dependencies. Components are deduplicated and sorted so the report is stable across runs.
+func NewReport(files map[string][]byte, toolVersion string) (*Report, error) {
+	components := make(map[string]*Component)
+	add := func(c Component, file string) {
+		key := c.Type + "|" + c.PURL + "|" + c.Name + "|" + c.Version
+		existing, ok := components[key]
+		if !ok {
+			existing = &c
+			components[key] = existing
+		}
+		existing.Properties = append(existing.Properties, Property{Name: FileProperty, Value: file})
+	}
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		content := files[p]
+		switch {
+		case isDockerfile(p):
+			for _, c := range dockerfileComponents(content) {
+				add(c, p)
+			}
+		case isWorkflow(p):
+			for _, c := range workflowComponents(content) {
+				add(c, p)
+			}
+		case path.Base(p) == "Chart.yaml":
+			chartComponents, err := helmComponents(content)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", p, err)
+			}
+			for _, c := range chartComponents {
+				add(c, p)
+			}
+		}
+	}
+
+	report := &Report{
+		BOMFormat:   BOMFormat,
+		SpecVersion: SpecVersion,
+		Metadata:    Metadata{Tools: []Tool{{Name: "draft", Version: toolVersion}}},
+		Components:  make([]Component, 0, len(components)),
+	}
+	for _, c := range components {
+		report.Components = append(report.Components, *c)
+	}
+	sort.Slice(report.Components, func(i, j int) bool {
+		a, b := report.Components[i], report.Components[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version < b.Version
+	})
+	return report, nil
+}
+
+func isDockerfile(p string) bool {
+	base := path.Base(p)
+	return base == "Dockerfile" || strings.HasPrefix(base, "Dockerfile.") || strings.HasSuffix(base, ".dockerfile")
+}
+
+func isWorkflow(p string) bool {
+	ext := path.Ext(p)
+	return strings.Contains(p, ".github/workflows/") && (ext == ".yml" || ext == ".yaml")
+}
+
+// dockerfileComponents returns the images referenced by FROM and COPY --from instructions, skipping build stages
+func dockerfileComponents(content []byte) []Component {
+	var components []Component
+	stages := map[string]bool{"scratch": true}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "FROM":
+			args := fields[1:]
+			for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+				args = args[1:]
+			}
+			if len(args) == 0 {
+				continue
+			}
+			if !stages[strings.ToLower(args[0])] {
+				components = append(components, imageComponent(args[0], "base image"))
+			}
+			if len(args) >= 3 && strings.EqualFold(args[1], "AS") {
+				stages[strings.ToLower(args[2])] = true
+			}
+		case "COPY":
+			for _, arg := range fields[1:] {
+				from, ok := strings.CutPrefix(arg, "--from=")
+				if !ok || stages[strings.ToLower(from)] || !strings.ContainsAny(from, ":/@") {
+					continue
+				}
+				components = append(components, imageComponent(from, "copied image"))
+			}
+		}
+	}
+	return components
+}
+
+// imageComponent splits an image reference into its registry, repository, tag and digest
+func imageComponent(ref, kind string) Component {
+	name := ref
+	digest := ""
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, digest = name[:i], name[i+1:]
+	}
+	tag := ""
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+	if tag == "" && digest == "" {
+		tag = "latest"
+	}
+
+	registry := ""
+	repository := name
+	if first, rest, ok := strings.Cut(name, "/"); ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		registry, repository = first, rest
+	}
+
+	purlVersion := tag
+	if digest != "" {
+		purlVersion = digest
+	}
+	purl := fmt.Sprintf("pkg:docker/%s@%s", repository, purlVersion)
+	var qualifiers []string
+	if registry != "" {
+		qualifiers = append(qualifiers, "repository_url="+registry)
+	}
+	if digest != "" && tag != "" {
+		qualifiers = append(qualifiers, "tag="+tag)
+	}
+	if len(qualifiers) > 0 {
+		purl += "?" + strings.Join(qualifiers, "&")
+	}
+
+	c := Component{
+		Type:       TypeContainer,
+		Name:       name,
+		Version:    tag,
+		PURL:       purl,
+		Properties: []Property{{Name: KindProperty, Value: kind}},
+	}
+	if alg, content, ok := strings.Cut(digest, ":"); ok {
+		c.Hashes = []Hash{{Alg: strings.ToUpper(strings.ReplaceAll(alg, "sha", "SHA-")), Content: content}}
+	}
+	return c
+}
+
+// workflowComponents returns the actions and container images referenced by `uses:` in a GitHub workflow
+func workflowComponents(content []byte) []Component {
+	var components []Component
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		match := workflowUsesRegex.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		uses := match[1]
+		if strings.HasPrefix(uses, "./") {
+			continue
+		}
+		if image, ok := strings.CutPrefix(uses, "docker://"); ok {
+			components = append(components, imageComponent(image, "github action container"))
+			continue
+		}
+		action, ref, _ := strings.Cut(uses, "@")
+		components = append(components, Component{
+			Type:       TypeApplication,
+			Name:       action,
+			Version:    ref,
+			PURL:       fmt.Sprintf("pkg:githubactions/%s@%s", action, ref),
+			Properties: []Property{{Name: KindProperty, Value: "github action"}},
+		})
+	}
+	return components
+}
+
+// helmComponents returns the chart dependencies declared in a helm Chart.yaml
+func helmComponents(content []byte) ([]Component, error) {
+	var chart chartFile
+	if err := yaml.Unmarshal(content, &chart); err != nil {
+		return nil, err
+	}
+
+	components := make([]Component, 0, len(chart.Dependencies))
+	for _, dep := range chart.Dependencies {
+		purl := fmt.Sprintf("pkg:helm/%s@%s", dep.Name, dep.Version)
+		if dep.Repository != "" {
+			purl += "?repository_url=" + dep.Repository
+		}
+		components = append(components, Component{
+			Type:       TypeApplication,
+			Name:       dep.Name,
+			Version:    dep.Version,
+			PURL:       purl,
+			Properties: []Property{{Name: KindProperty, Value: "helm chart dependency"}},
+		})
+	}
+	return components, nil
+}
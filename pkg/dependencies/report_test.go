@@ -0,0 +1,80 @@
+package dependencies
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReport(t *testing.T) {
+	files := map[string][]byte{
+		"app/Dockerfile": []byte(`FROM --platform=linux/amd64 golang:1.22 AS builder
+COPY . .
+FROM builder AS test
+FROM gcr.io/distroless/static@sha256:abc123
+COPY --from=builder /app /app
+COPY --from=ghcr.io/org/tools:v1 /bin/tool /bin/tool
+`),
+		"app/.github/workflows/deploy.yml": []byte(`jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+      - name: login
+        uses: azure/login@v2 # pinned
+      - uses: ./local-action
+      - uses: docker://alpine:3.19
+`),
+		"app/.github/workflows/other.yml": []byte(`      - uses: actions/checkout@v4
+`),
+		"app/charts/Chart.yaml": []byte(`apiVersion: v2
+name: app
+dependencies:
+  - name: redis
+    version: 18.0.0
+    repository: https://charts.bitnami.com/bitnami
+`),
+		"app/charts/values.yaml": []byte("image: nginx:1.0\n"),
+	}
+
+	report, err := NewReport(files, "v1.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, "CycloneDX", report.BOMFormat)
+	assert.Equal(t, []Tool{{Name: "draft", Version: "v1.0.0"}}, report.Metadata.Tools)
+
+	purls := make([]string, 0, len(report.Components))
+	for _, c := range report.Components {
+		purls = append(purls, c.PURL)
+	}
+	assert.Equal(t, []string{
+		"pkg:githubactions/actions/checkout@v4",
+		"pkg:githubactions/azure/login@v2",
+		"pkg:helm/redis@18.0.0?repository_url=https://charts.bitnami.com/bitnami",
+		"pkg:docker/alpine@3.19",
+		"pkg:docker/distroless/static@sha256:abc123?repository_url=gcr.io",
+		"pkg:docker/org/tools@v1?repository_url=ghcr.io",
+		"pkg:docker/golang@1.22",
+	}, purls)
+
+	checkout := report.Components[0]
+	assert.Equal(t, []Property{
+		{Name: KindProperty, Value: "github action"},
+		{Name: FileProperty, Value: "app/.github/workflows/deploy.yml"},
+		{Name: FileProperty, Value: "app/.github/workflows/other.yml"},
+	}, checkout.Properties)
+
+	distroless := report.Components[4]
+	assert.Equal(t, "gcr.io/distroless/static", distroless.Name)
+	assert.Equal(t, "", distroless.Version)
+	assert.Equal(t, []Hash{{Alg: "SHA-256", Content: "abc123"}}, distroless.Hashes)
+}
+
+func TestImageComponent(t *testing.T) {
+	c := imageComponent("localhost:5000/app", "base image")
+	assert.Equal(t, "localhost:5000/app", c.Name)
+	assert.Equal(t, "latest", c.Version)
+	assert.Equal(t, "pkg:docker/app@latest?repository_url=localhost:5000", c.PURL)
+
+	c = imageComponent("mcr.microsoft.com/dotnet/aspnet:8.0@sha256:ff", "base image")
+	assert.Equal(t, "8.0", c.Version)
+	assert.Equal(t, "pkg:docker/dotnet/aspnet@sha256:ff?repository_url=mcr.microsoft.com&tag=8.0", c.PURL)
+}
@@ -0,0 +1,93 @@
+package deployments
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/consts"
+)
+
+// Variables of the HorizontalPodAutoscaler scaling the deployment on cpu utilization
+const (
+	AutoscalingEnabledVariable     = "AUTOSCALINGENABLED"
+	AutoscalingMinReplicasVariable = "AUTOSCALINGMINREPLICAS"
+	AutoscalingMaxReplicasVariable = "AUTOSCALINGMAXREPLICAS"
+	AutoscalingTargetCPUVariable   = "AUTOSCALINGTARGETCPU"
+)
+
+// PromptAutoscaling makes the bounds and target of the HorizontalPodAutoscaler of deployConfig prompted for, with their
+// defaults as the default answers, for when autoscaling is asked for. Deployment types without autoscaling are left
+// alone.
+func PromptAutoscaling(deployConfig *config.DraftConfig) {
+	for i, variableDefault := range deployConfig.VariableDefaults {
+		switch variableDefault.Name {
+		case AutoscalingMinReplicasVariable, AutoscalingMaxReplicasVariable, AutoscalingTargetCPUVariable:
+			deployConfig.VariableDefaults[i].IsPromptDisabled = false
+		}
+	}
+}
+
+// autoscalingEnabled returns whether the deployment is scaled by a HorizontalPodAutoscaler
+func autoscalingEnabled(customInputs map[string]string) bool {
+	return strings.EqualFold(customInputs[AutoscalingEnabledVariable], "true")
+}
+
+// validateAutoscalingVariables checks the HorizontalPodAutoscaler variables when autoscaling is enabled, since bounds
+// the wrong way around are otherwise only reported by the cluster on apply
+func validateAutoscalingVariables(customInputs map[string]string) error {
+	enabled, ok := customInputs[AutoscalingEnabledVariable]
+	if !ok || enabled == "" {
+		return nil
+	}
+	if !strings.EqualFold(enabled, "true") && !strings.EqualFold(enabled, "false") {
+		return fmt.Errorf("invalid %s %q, must be true or false", AutoscalingEnabledVariable, enabled)
+	}
+	if !autoscalingEnabled(customInputs) {
+		return nil
+	}
+
+	if strings.EqualFold(customInputs["KEDAENABLED"], "true") {
+		return fmt.Errorf("KEDAENABLED and %s can't both be true, KEDA and the HorizontalPodAutoscaler would both scale the deployment", AutoscalingEnabledVariable)
+	}
+	minReplicas, err := strconv.Atoi(customInputs[AutoscalingMinReplicasVariable])
+	if err != nil || minReplicas < 1 {
+		return fmt.Errorf("invalid %s %q, must be a positive integer", AutoscalingMinReplicasVariable, customInputs[AutoscalingMinReplicasVariable])
+	}
+	maxReplicas, err := strconv.Atoi(customInputs[AutoscalingMaxReplicasVariable])
+	if err != nil || maxReplicas < minReplicas {
+		return fmt.Errorf("invalid %s %q, must be an integer of at least %s %d", AutoscalingMaxReplicasVariable, customInputs[AutoscalingMaxReplicasVariable], AutoscalingMinReplicasVariable, minReplicas)
+	}
+	if cpu, err := strconv.Atoi(customInputs[AutoscalingTargetCPUVariable]); err != nil || cpu < 1 || cpu > 100 {
+		return fmt.Errorf("invalid %s %q, must be a percentage between 1 and 100", AutoscalingTargetCPUVariable, customInputs[AutoscalingTargetCPUVariable])
+	}
+	return nil
+}
+
+// autoscalingMutator returns a PostRenderWriter mutation handing the replicas of the application's workload over to
+// the HorizontalPodAutoscaler in the plain yaml deployment types: the fixed replicas of the workload and of the
+// overlays' patches are removed, so applying them again doesn't undo the scaling, and the autoscaler is added to the
+// kustomization next to the workload. Helm charts template it themselves.
+func autoscalingMutator(deployType, dest string, customInputs map[string]string) func(string, []byte) ([]byte, error) {
+	workloadPath := path.Join(dest, consts.DeploymentManifestPaths[deployType])
+	return func(filePath string, content []byte) ([]byte, error) {
+		if !autoscalingEnabled(customInputs) {
+			return content, nil
+		}
+		switch {
+		case filePath == workloadPath, isOverlay(dest, filePath) && path.Base(filePath) == path.Base(workloadPath):
+			return mutateYaml(content, func(node *kyaml.RNode) error {
+				return node.PipeE(kyaml.Lookup("spec"), kyaml.Clear("replicas"))
+			})
+		case filePath == path.Join(path.Dir(workloadPath), "kustomization.yaml"):
+			return mutateYaml(content, func(node *kyaml.RNode) error {
+				return node.PipeE(kyaml.LookupCreate(kyaml.SequenceNode, "resources"), kyaml.Append(kyaml.NewStringRNode("hpa.yaml").YNode()))
+			})
+		}
+		return content, nil
+	}
+}
@@ -0,0 +1,116 @@
+package deployments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+func TestValidateAutoscalingVariables(t *testing.T) {
+	inputs := func(enabled, minReplicas, maxReplicas, cpu string) map[string]string {
+		return map[string]string{
+			"AUTOSCALINGENABLED":     enabled,
+			"AUTOSCALINGMINREPLICAS": minReplicas,
+			"AUTOSCALINGMAXREPLICAS": maxReplicas,
+			"AUTOSCALINGTARGETCPU":   cpu,
+		}
+	}
+	keda := inputs("true", "1", "10", "80")
+	keda["KEDAENABLED"] = "true"
+
+	tests := []struct {
+		name    string
+		inputs  map[string]string
+		wantErr string
+	}{
+		{name: "no autoscaling variables", inputs: map[string]string{}},
+		{name: "disabled", inputs: inputs("false", "0", "", "")},
+		{name: "enabled", inputs: inputs("True", "2", "2", "100")},
+		{name: "invalid enabled", inputs: inputs("yes", "1", "10", "80"), wantErr: "invalid AUTOSCALINGENABLED"},
+		{name: "no minimum", inputs: inputs("true", "0", "10", "80"), wantErr: "invalid AUTOSCALINGMINREPLICAS"},
+		{name: "maximum below minimum", inputs: inputs("true", "5", "3", "80"), wantErr: "invalid AUTOSCALINGMAXREPLICAS \"3\", must be an integer of at least AUTOSCALINGMINREPLICAS 5"},
+		{name: "target over 100", inputs: inputs("true", "1", "10", "120"), wantErr: "invalid AUTOSCALINGTARGETCPU"},
+		{name: "with keda", inputs: keda, wantErr: "KEDAENABLED and AUTOSCALINGENABLED can't both be true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAutoscalingVariables(tt.inputs)
+			if tt.wantErr == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCopyDeploymentFilesAutoscaling(t *testing.T) {
+	inputs := func(enabled string) map[string]string {
+		return map[string]string{
+			"APPNAME":                "testapp",
+			"PORT":                   "80",
+			"SERVICEPORT":            "80",
+			"NAMESPACE":              "default",
+			"IMAGENAME":              "testapp",
+			"AUTOSCALINGENABLED":     enabled,
+			"AUTOSCALINGMAXREPLICAS": "5",
+			"AUTOSCALINGTARGETCPU":   "70",
+		}
+	}
+
+	d := CreateDeploymentsFromEmbedFS(template.Deployments, "out")
+	w := &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("manifests", inputs("false"), w))
+	assert.NotContains(t, w.FileMap, "out/manifests/hpa.yaml")
+	assert.Contains(t, string(w.FileMap["out/manifests/deployment.yaml"]), "replicas: 1\n")
+
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("manifests", inputs("true"), w))
+	hpa := string(w.FileMap["out/manifests/hpa.yaml"])
+	assert.Contains(t, hpa, "kind: HorizontalPodAutoscaler")
+	assert.Contains(t, hpa, "kind: Deployment\n    name: testapp")
+	assert.Contains(t, hpa, "minReplicas: 1\n  maxReplicas: 5\n")
+	assert.Contains(t, hpa, "averageUtilization: 70")
+	assert.NotContains(t, string(w.FileMap["out/manifests/deployment.yaml"]), "replicas:")
+
+	kustomizeInputs := inputs("true")
+	kustomizeInputs["ENVIRONMENTS"] = "dev,prod"
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("kustomize", kustomizeInputs, w))
+	assert.Contains(t, w.FileMap, "out/base/hpa.yaml")
+	assert.Contains(t, string(w.FileMap["out/base/kustomization.yaml"]), "- hpa.yaml")
+	assert.NotContains(t, string(w.FileMap["out/base/deployment.yaml"]), "replicas:")
+	for _, env := range []string{"dev", "prod"} {
+		assert.NotContains(t, string(w.FileMap["out/overlays/"+env+"/deployment.yaml"]), "replicas:")
+	}
+
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("helm", inputs("true"), w))
+	assert.Contains(t, string(w.FileMap["out/charts/values.yaml"]), "autoscaling:\n  enabled: true\n  minReplicas: 1\n  maxReplicas: 5\n  targetCPUUtilizationPercentage: 70\n")
+	assert.Contains(t, string(w.FileMap["out/charts/templates/hpa.yaml"]), "kind: HorizontalPodAutoscaler")
+
+	// replicas sharing a ReadWriteOnce volume as they scale out get their own in a StatefulSet
+	persistent := inputs("true")
+	persistent["PERSISTENCEENABLED"] = "true"
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("manifests", persistent, w))
+	assert.Contains(t, string(w.FileMap["out/manifests/hpa.yaml"]), "kind: StatefulSet")
+}
+
+func TestPromptAutoscaling(t *testing.T) {
+	d := CreateDeploymentsFromEmbedFS(template.Deployments, "out")
+	deployConfig, err := d.GetConfig("kustomize")
+	assert.Nil(t, err)
+	PromptAutoscaling(deployConfig)
+	for _, variableDefault := range deployConfig.VariableDefaults {
+		switch variableDefault.Name {
+		case "AUTOSCALINGMINREPLICAS", "AUTOSCALINGMAXREPLICAS", "AUTOSCALINGTARGETCPU":
+			assert.False(t, variableDefault.IsPromptDisabled, variableDefault.Name)
+		case "AUTOSCALINGENABLED", "REPLICAS":
+			assert.True(t, variableDefault.IsPromptDisabled, variableDefault.Name)
+		}
+	}
+}
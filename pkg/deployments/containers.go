@@ -0,0 +1,244 @@
+package deployments
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"github.com/Azure/draft/pkg/consts"
+)
+
+// Variables declaring the additional containers of the application's pod, each a ; separated list of
+// NAME=IMAGE[,port=PORT...][,mount=VOLUME:PATH...]
+const (
+	SidecarsVariable       = "SIDECARS"
+	InitContainersVariable = "INITCONTAINERS"
+	// the containers and shared volume names as yaml flow sequences, for the values of helm charts
+	sidecarsValuesVariable       = "SIDECARSVALUES"
+	initContainersValuesVariable = "INITCONTAINERSVALUES"
+	sharedVolumesValuesVariable  = "SHAREDVOLUMESVALUES"
+)
+
+// extraContainer is a sidecar or init container, marshalled as the container spec of a pod
+type extraContainer struct {
+	Name         string          `json:"name" yaml:"name"`
+	Image        string          `json:"image" yaml:"image"`
+	Ports        []containerPort `json:"ports,omitempty" yaml:"ports,omitempty"`
+	VolumeMounts []volumeMount   `json:"volumeMounts,omitempty" yaml:"volumeMounts,omitempty"`
+}
+
+type containerPort struct {
+	ContainerPort int `json:"containerPort" yaml:"containerPort"`
+}
+
+type volumeMount struct {
+	Name      string `json:"name" yaml:"name"`
+	MountPath string `json:"mountPath" yaml:"mountPath"`
+}
+
+// extraContainers are the parsed SIDECARS and INITCONTAINERS, with the emptyDir volumes they share
+type extraContainers struct {
+	sidecars       []extraContainer
+	initContainers []extraContainer
+	sharedVolumes  []string
+}
+
+// applyContainers validates SIDECARS and INITCONTAINERS and sets the values the helm chart renders them from. Their
+// mounts name the application's own volumes, data when PERSISTENCEENABLED is true and tmp when HARDENED is true, or
+// an emptyDir volume shared by the containers mounting it. Templates without SIDECARS are left alone.
+func applyContainers(customInputs map[string]string) error {
+	if _, ok := customInputs[SidecarsVariable]; !ok {
+		return nil
+	}
+	containers, err := parseExtraContainers(customInputs)
+	if err != nil {
+		return err
+	}
+
+	for variable, value := range map[string]interface{}{
+		sidecarsValuesVariable:       containers.sidecars,
+		initContainersValuesVariable: containers.initContainers,
+		sharedVolumesValuesVariable:  containers.sharedVolumes,
+	} {
+		// json is the flow style of yaml, so the values stay on the line of their key
+		out, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		customInputs[variable] = string(out)
+	}
+	return nil
+}
+
+func parseExtraContainers(customInputs map[string]string) (*extraContainers, error) {
+	containers := &extraContainers{sharedVolumes: []string{}}
+	var err error
+	if containers.sidecars, err = parseContainerList(SidecarsVariable, customInputs[SidecarsVariable]); err != nil {
+		return nil, err
+	}
+	if containers.initContainers, err = parseContainerList(InitContainersVariable, customInputs[InitContainersVariable]); err != nil {
+		return nil, err
+	}
+
+	names := map[string]string{customInputs["APPNAME"]: "the application"}
+	ownVolumes := map[string]bool{
+		storageVolumeName: strings.EqualFold(customInputs[PersistenceEnabledVariable], "true"),
+		tmpVolumeName:     hardened(customInputs),
+	}
+	shared := make(map[string]bool)
+	for _, list := range []struct {
+		variable   string
+		containers []extraContainer
+	}{{SidecarsVariable, containers.sidecars}, {InitContainersVariable, containers.initContainers}} {
+		for _, c := range list.containers {
+			if other, ok := names[c.Name]; ok {
+				return nil, fmt.Errorf("invalid %s: container %s has the name of %s", list.variable, c.Name, other)
+			}
+			names[c.Name] = "container " + c.Name + " of " + list.variable
+
+			for _, mount := range c.VolumeMounts {
+				enabled, own := ownVolumes[mount.Name]
+				switch {
+				case own && !enabled && mount.Name == storageVolumeName:
+					return nil, fmt.Errorf("invalid %s: container %s mounts the %s volume, which only exists when %s is true", list.variable, c.Name, mount.Name, PersistenceEnabledVariable)
+				case own && !enabled:
+					return nil, fmt.Errorf("invalid %s: container %s mounts the %s volume, which only exists when %s is true", list.variable, c.Name, mount.Name, HardenedVariable)
+				case !own && !shared[mount.Name]:
+					shared[mount.Name] = true
+					containers.sharedVolumes = append(containers.sharedVolumes, mount.Name)
+				}
+			}
+		}
+	}
+	return containers, nil
+}
+
+// parseContainerList parses the containers declared by the value of variable
+func parseContainerList(variable, value string) ([]extraContainer, error) {
+	containers := []extraContainer{}
+	for _, declaration := range strings.Split(value, ";") {
+		if declaration = strings.TrimSpace(declaration); declaration == "" {
+			continue
+		}
+		c, err := parseContainer(declaration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", variable, declaration, err)
+		}
+		containers = append(containers, c)
+	}
+	return containers, nil
+}
+
+// parseContainer parses a NAME=IMAGE[,port=PORT...][,mount=VOLUME:PATH...] declaration
+func parseContainer(declaration string) (extraContainer, error) {
+	fields := strings.Split(declaration, ",")
+	name, image, ok := strings.Cut(fields[0], "=")
+	name, image = strings.TrimSpace(name), strings.TrimSpace(image)
+	if !ok || image == "" {
+		return extraContainer{}, fmt.Errorf("must start with NAME=IMAGE")
+	}
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return extraContainer{}, fmt.Errorf("invalid name %q: %s", name, strings.Join(errs, ", "))
+	}
+	if strings.ContainsAny(image, " \t") {
+		return extraContainer{}, fmt.Errorf("invalid image %q", image)
+	}
+
+	c := extraContainer{Name: name, Image: image}
+	for _, field := range fields[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch key {
+		case "port":
+			port, err := strconv.Atoi(value)
+			if err != nil || port < 1 || port > 65535 {
+				return extraContainer{}, fmt.Errorf("invalid port %q, must be a number between 1 and 65535", value)
+			}
+			c.Ports = append(c.Ports, containerPort{ContainerPort: port})
+		case "mount":
+			volume, mountPath, _ := strings.Cut(value, ":")
+			if errs := validation.IsDNS1123Label(volume); len(errs) > 0 {
+				return extraContainer{}, fmt.Errorf("invalid volume %q of mount %q: %s", volume, value, strings.Join(errs, ", "))
+			}
+			if !strings.HasPrefix(mountPath, "/") {
+				return extraContainer{}, fmt.Errorf("invalid mount %q, must be VOLUME:PATH with an absolute path", value)
+			}
+			c.VolumeMounts = append(c.VolumeMounts, volumeMount{Name: volume, MountPath: mountPath})
+		default:
+			return extraContainer{}, fmt.Errorf("unknown field %q, must be port=PORT or mount=VOLUME:PATH", field)
+		}
+	}
+	return c, nil
+}
+
+// validateContainerList checks a SIDECARS or INITCONTAINERS answer on its own, without the other variables its mounts
+// depend on
+func validateContainerList(variable string) func(string) error {
+	return func(value string) error {
+		_, err := parseContainerList(variable, value)
+		return err
+	}
+}
+
+// containersMutator returns a PostRenderWriter mutation adding the sidecars and init containers to the application's
+// workload in the plain yaml deployment types, with the emptyDir volumes they share. Helm charts template them
+// themselves.
+func containersMutator(deployType, dest string, customInputs map[string]string) func(string, []byte) ([]byte, error) {
+	workloadPath := path.Join(dest, consts.DeploymentManifestPaths[deployType])
+	return func(filePath string, content []byte) ([]byte, error) {
+		if filePath != workloadPath || strings.TrimSpace(customInputs[SidecarsVariable]+customInputs[InitContainersVariable]) == "" {
+			return content, nil
+		}
+		containers, err := parseExtraContainers(customInputs)
+		if err != nil {
+			return nil, err
+		}
+		return mutateYaml(content, func(node *kyaml.RNode) error {
+			return addExtraContainers(node, containers)
+		})
+	}
+}
+
+func addExtraContainers(node *kyaml.RNode, containers *extraContainers) error {
+	podSpec, err := node.Pipe(kyaml.Lookup("spec", "template", "spec"))
+	if err != nil || podSpec == nil {
+		return fmt.Errorf("no pod template found in %s %s to add the containers to", node.GetKind(), node.GetName())
+	}
+	for _, list := range []struct {
+		field      string
+		containers []extraContainer
+	}{{"containers", containers.sidecars}, {"initContainers", containers.initContainers}} {
+		for _, c := range list.containers {
+			containerNode, err := toRNode(c)
+			if err != nil {
+				return err
+			}
+			if err := podSpec.PipeE(kyaml.LookupCreate(kyaml.SequenceNode, list.field), kyaml.Append(containerNode.YNode())); err != nil {
+				return err
+			}
+		}
+	}
+	for _, name := range containers.sharedVolumes {
+		volume, err := kyaml.Parse(fmt.Sprintf("name: %s\nemptyDir: {}\n", name))
+		if err != nil {
+			return err
+		}
+		if err := podSpec.PipeE(kyaml.LookupCreate(kyaml.SequenceNode, "volumes"), kyaml.Append(volume.YNode())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toRNode converts v to a yaml node in block style
+func toRNode(v interface{}) (*kyaml.RNode, error) {
+	out, err := kyaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return kyaml.Parse(string(out))
+}
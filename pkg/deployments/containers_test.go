@@ -0,0 +1,109 @@
+package deployments
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+func TestCopyDeploymentFilesContainers(t *testing.T) {
+	inputs := func() map[string]string {
+		return map[string]string{
+			"APPNAME":        "testapp",
+			"PORT":           "80",
+			"SERVICEPORT":    "80",
+			"NAMESPACE":      "default",
+			"IMAGENAME":      "testapp",
+			"SIDECARS":       "logs=fluent/fluent-bit:3.0,port=2020,mount=logs:/var/log/app;cache=redis:7",
+			"INITCONTAINERS": "migrate=myregistry.azurecr.io/migrate:v1,mount=tmp:/tmp",
+		}
+	}
+
+	d := CreateDeploymentsFromEmbedFS(template.Deployments, "out")
+	w := &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("manifests", inputs(), w))
+	deployment := string(w.FileMap["out/manifests/deployment.yaml"])
+	assert.Contains(t, deployment, `        - name: logs
+          image: fluent/fluent-bit:3.0
+          ports:
+            - containerPort: 2020
+          volumeMounts:
+            - name: logs
+              mountPath: /var/log/app
+        - name: cache
+          image: redis:7
+`)
+	assert.Contains(t, deployment, "      initContainers:\n        - name: migrate\n          image: myregistry.azurecr.io/migrate:v1\n")
+	assert.Contains(t, deployment, "        - name: tmp\n          emptyDir: {}\n        - name: logs\n          emptyDir: {}\n", "the shared volume comes after the application's")
+
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("kustomize", inputs(), w))
+	assert.Contains(t, string(w.FileMap["out/base/deployment.yaml"]), "- name: cache\n")
+
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("helm", inputs(), w))
+	values := string(w.FileMap["out/charts/values.yaml"])
+	assert.Contains(t, values, `sharedVolumes: ["logs"]`)
+	// the chart renders them into the pod of the application
+	chart, err := loader.LoadFiles(chartFiles(w.FileMap, "out/charts/"))
+	assert.Nil(t, err)
+	renderValues, err := chartutil.ToRenderValues(chart, nil, chartutil.ReleaseOptions{Name: "testapp", Namespace: "default"}, chartutil.DefaultCapabilities)
+	assert.Nil(t, err)
+	rendered, err := engine.Render(chart, renderValues)
+	assert.Nil(t, err)
+	deployment = rendered["testapp/templates/deployment.yaml"]
+	assert.Contains(t, deployment, "      initContainers:\n        - image: myregistry.azurecr.io/migrate:v1\n")
+	assert.Contains(t, deployment, "        - image: redis:7\n          name: cache\n")
+	assert.Contains(t, deployment, "        - name: logs\n          emptyDir: {}\n")
+
+	// without any, the workload is unchanged
+	none := inputs()
+	none["SIDECARS"], none["INITCONTAINERS"] = "", ""
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("manifests", none, w))
+	assert.NotContains(t, string(w.FileMap["out/manifests/deployment.yaml"]), "initContainers")
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("helm", none, w))
+	assert.Contains(t, string(w.FileMap["out/charts/values.yaml"]), "\nsidecars: []\n")
+
+	for sidecars, err := range map[string]string{
+		"logs":                           `invalid SIDECARS "logs": must start with NAME=IMAGE`,
+		"Logs=fluent-bit":                `invalid name "Logs"`,
+		"logs=fluent-bit,port=0":         `invalid port "0"`,
+		"logs=fluent-bit,mount=logs":     `invalid mount "logs", must be VOLUME:PATH`,
+		"logs=fluent-bit,env=x":          `unknown field "env=x"`,
+		"testapp=fluent-bit":             "container testapp has the name of the application",
+		"migrate=fluent-bit":             "container migrate has the name of container migrate of INITCONTAINERS",
+		"logs=fluent-bit,mount=data:/x":  "mounts the data volume, which only exists when PERSISTENCEENABLED is true",
+		"logs=fluent-bit,mount=tmp:/tmp": "",
+	} {
+		invalid := inputs()
+		invalid["SIDECARS"], invalid["INITCONTAINERS"] = sidecars, "migrate=migrate:v1"
+		if sidecars == "migrate=fluent-bit" {
+			invalid["SIDECARS"], invalid["INITCONTAINERS"] = "", sidecars+";migrate=migrate:v1"
+		}
+		if err == "" {
+			invalid["HARDENED"] = "false"
+			err = "mounts the tmp volume, which only exists when HARDENED is true"
+		}
+		assert.ErrorContains(t, d.CopyDeploymentFiles("manifests", invalid, &writers.FileMapWriter{}), err, sidecars)
+	}
+}
+
+// chartFiles returns the files of the chart generated in dir
+func chartFiles(fileMap map[string][]byte, dir string) []*loader.BufferedFile {
+	var files []*loader.BufferedFile
+	for name, content := range fileMap {
+		if rel, ok := strings.CutPrefix(name, dir); ok {
+			files = append(files, &loader.BufferedFile{Name: rel, Data: content})
+		}
+	}
+	return files
+}
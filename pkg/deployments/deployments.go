@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/fs"
 	"path"
+	"sort"
 
 	"golang.org/x/exp/maps"
 	"gopkg.in/yaml.v3"
@@ -15,6 +16,7 @@ import (
 	"github.com/Azure/draft/pkg/embedutils"
 	"github.com/Azure/draft/pkg/osutil"
 	"github.com/Azure/draft/pkg/templatewriter"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
 )
 
 var (
@@ -27,14 +29,26 @@ type Deployments struct {
 	configs             map[string]*config.DraftConfig
 	dest                string
 	deploymentTemplates fs.FS
+	// packTemplates holds the templates of deployment types added with AddPacks, which take precedence over
+	// deploymentTemplates
+	packTemplates map[string]fs.FS
 }
 
-// DeployTypes returns a slice of the supported deployment types
+// DeployTypes returns the sorted supported deployment types
 func (d *Deployments) DeployTypes() []string {
 	names := maps.Keys(d.deploys)
+	sort.Strings(names)
 	return names
 }
 
+// templatesFor returns the filesystem holding the templates of deployType
+func (d *Deployments) templatesFor(deployType string) fs.FS {
+	if fsys, ok := d.packTemplates[deployType]; ok {
+		return fsys
+	}
+	return d.deploymentTemplates
+}
+
 func (d *Deployments) CopyDeploymentFiles(deployType string, customInputs map[string]string, templateWriter templatewriter.TemplateWriter) error {
 	val, ok := d.deploys[deployType]
 	if !ok {
@@ -43,6 +57,10 @@ func (d *Deployments) CopyDeploymentFiles(deployType string, customInputs map[st
 
 	srcDir := path.Join(parentDirName, val.Name())
 
+	if err := ApplyResourcePreset(customInputs); err != nil {
+		return err
+	}
+
 	deployConfig, ok := d.configs[deployType]
 	if !ok {
 		deployConfig = nil
@@ -50,10 +68,108 @@ func (d *Deployments) CopyDeploymentFiles(deployType string, customInputs map[st
 		deployConfig.ApplyDefaultVariables(customInputs)
 	}
 
-	if err := osutil.CopyDir(d.deploymentTemplates, srcDir, d.dest, deployConfig, customInputs, templateWriter); err != nil {
+	if err := validateGatewayVariables(customInputs); err != nil {
+		return err
+	}
+
+	if err := validateAutoscalingVariables(customInputs); err != nil {
+		return err
+	}
+	if err := validateHardenedVariable(customInputs); err != nil {
+		return err
+	}
+	if err := applyIngress(customInputs); err != nil {
+		return err
+	}
+	if err := applyPersistence(customInputs); err != nil {
+		return err
+	}
+	if err := applyContainers(customInputs); err != nil {
+		return err
+	}
+	environments, err := parseEnvironments(customInputs)
+	if err != nil {
+		return err
+	}
+	// the outermost PostRenderWriter mutates first, so the /tmp volume of the hardening comes after the storage volume
+	// and the volumes shared by the sidecars come last
+	if _, ok := customInputs[SidecarsVariable]; ok && deployType != "helm" {
+		templateWriter = &writers.PostRenderWriter{Writer: templateWriter, Mutate: containersMutator(deployType, d.dest, customInputs)}
+	}
+	if _, ok := customInputs[HardenedVariable]; ok && deployType != "helm" {
+		templateWriter = &writers.PostRenderWriter{Writer: templateWriter, Mutate: hardeningMutator(deployType, d.dest, customInputs)}
+	}
+	if _, ok := customInputs[WorkloadKindVariable]; ok && deployType != "helm" {
+		templateWriter = &writers.PostRenderWriter{Writer: templateWriter, Mutate: persistenceMutator(deployType, d.dest, customInputs)}
+	}
+	if _, ok := customInputs[AutoscalingEnabledVariable]; ok && deployType != "helm" {
+		templateWriter = &writers.PostRenderWriter{Writer: templateWriter, Mutate: autoscalingMutator(deployType, d.dest, customInputs)}
+	}
+	if _, ok := customInputs[IngressTypeVariable]; ok && deployType != "helm" {
+		templateWriter = &writers.PostRenderWriter{Writer: templateWriter, Mutate: ingressMutator(deployType, d.dest, customInputs)}
+	}
+
+	templates := d.templatesFor(deployType)
+	if len(environments) == 0 {
+		if err := osutil.CopyDir(templates, srcDir, d.dest, deployConfig, customInputs, templateWriter); err != nil {
+			return err
+		}
+	} else if err := d.copyEnvironmentFiles(templates, srcDir, deployConfig, customInputs, environments, templateWriter); err != nil {
+		return err
+	}
+
+	return writeValuesSchema(templates, srcDir, d.dest, deployConfig, customInputs, templateWriter)
+}
+
+// copyEnvironmentFiles copies the files of the template in srcDir, rendering its overlay once for each environment
+// with the environment's variables instead
+func (d *Deployments) copyEnvironmentFiles(templates fs.FS, srcDir string, deployConfig *config.DraftConfig, customInputs map[string]string, environments []environment, templateWriter templatewriter.TemplateWriter) error {
+	baseWriter := &writers.FilterWriter{Writer: templateWriter, Skip: func(filePath string) bool { return isOverlay(d.dest, filePath) }}
+	if err := osutil.CopyDir(templates, srcDir, d.dest, deployConfig, customInputs, baseWriter); err != nil {
+		return err
+	}
+	// the overlays are rendered without the variables and optional files of the base, but with its delimiters
+	var overlayConfig *config.DraftConfig
+	if deployConfig != nil {
+		overlayConfig = &config.DraftConfig{TemplateDelimiters: deployConfig.TemplateDelimiters}
+	}
+	for _, env := range environments {
+		overlayDest := path.Join(d.dest, overlaysDir, env.name)
+		if err := templateWriter.EnsureDirectory(overlayDest); err != nil {
+			return err
+		}
+		if err := osutil.CopyDir(templates, path.Join(srcDir, overlayTemplateDir), overlayDest, overlayConfig, env.inputs, templateWriter); err != nil {
+			return fmt.Errorf("generating the overlay of environment %s: %w", env.name, err)
+		}
+	}
+	return nil
+}
+
+// Versions returns the embedded versions of the template for deployType, newest first
+func (d *Deployments) Versions(deployType string) ([]string, error) {
+	if _, ok := d.deploys[deployType]; !ok {
+		return nil, fmt.Errorf("deployment type: %s is not currently supported", deployType)
+	}
+	return embedutils.TemplateVersions(d.templatesFor(deployType), parentDirName, deployType, d.configs[deployType].Version)
+}
+
+// UseVersion makes deployType generate its files from the embedded template at templateVersion
+func (d *Deployments) UseVersion(deployType, templateVersion string) error {
+	val, ok := d.deploys[deployType]
+	if !ok {
+		return fmt.Errorf("deployment type: %s is not currently supported", deployType)
+	}
+	dir, err := embedutils.ResolveVersionedDir(d.templatesFor(deployType), parentDirName, deployType, val, d.configs[deployType].Version, templateVersion)
+	if err != nil {
 		return err
 	}
 
+	d.deploys[deployType] = dir
+	draftConfig, err := d.loadConfig(deployType)
+	if err != nil {
+		return fmt.Errorf("loading version %s of deployment type %s: %w", templateVersion, deployType, err)
+	}
+	d.configs[deployType] = draftConfig
 	return nil
 }
 
@@ -64,7 +180,7 @@ func (d *Deployments) loadConfig(lang string) (*config.DraftConfig, error) {
 	}
 
 	configPath := path.Join(parentDirName, val.Name(), configFileName)
-	configBytes, err := fs.ReadFile(d.deploymentTemplates, configPath)
+	configBytes, err := fs.ReadFile(d.templatesFor(lang), configPath)
 	if err != nil {
 		return nil, err
 	}
@@ -97,18 +213,44 @@ func (d *Deployments) PopulateConfigs() {
 }
 
 func CreateDeploymentsFromEmbedFS(deploymentTemplates embed.FS, dest string) *Deployments {
-	deployMap, err := embedutils.EmbedFStoMap(deploymentTemplates, "deployments")
+	d, err := CreateDeploymentsFromFS(embedutils.WithTemplateDir(deploymentTemplates), dest)
 	if err != nil {
 		log.Fatal(err)
 	}
+	return d
+}
+
+// CreateDeploymentsFromFS loads the deployment types in the deployments directory of deploymentTemplates, which can be
+// an on-disk directory laid out like draft's template directory
+func CreateDeploymentsFromFS(deploymentTemplates fs.FS, dest string) (*Deployments, error) {
+	deployMap, err := embedutils.EmbedFStoMap(deploymentTemplates, parentDirName)
+	if err != nil {
+		return nil, err
+	}
 
 	d := &Deployments{
 		deploys:             deployMap,
 		dest:                dest,
 		configs:             make(map[string]*config.DraftConfig),
 		deploymentTemplates: deploymentTemplates,
+		packTemplates:       make(map[string]fs.FS),
 	}
 	d.PopulateConfigs()
 
-	return d
+	return d, nil
+}
+
+// AddPacks adds the deployment types of other to d, replacing the deployment types of d with the same name
+func (d *Deployments) AddPacks(other *Deployments) {
+	for deployType, dir := range other.deploys {
+		if _, ok := d.deploys[deployType]; ok {
+			log.Debugf("deployment pack %s overrides the embedded deployment type", deployType)
+		}
+		d.deploys[deployType] = dir
+		d.configs[deployType] = other.configs[deployType]
+		if d.packTemplates == nil {
+			d.packTemplates = make(map[string]fs.FS)
+		}
+		d.packTemplates[deployType] = other.templatesFor(deployType)
+	}
 }
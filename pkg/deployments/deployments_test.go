@@ -0,0 +1,85 @@
+package deployments
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/embedutils"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+func TestCopyDeploymentFilesTrackingAnnotations(t *testing.T) {
+	files := map[string]string{
+		"helm":      "out/charts/values.yaml",
+		"kustomize": "out/base/deployment.yaml",
+		"manifests": "out/manifests/deployment.yaml",
+	}
+
+	d := CreateDeploymentsFromEmbedFS(template.Deployments, "out")
+	for deployType, file := range files {
+		t.Run(deployType, func(t *testing.T) {
+			w := &writers.FileMapWriter{}
+			err := d.CopyDeploymentFiles(deployType, map[string]string{
+				"APPNAME":      "testapp",
+				"PORT":         "80",
+				"SERVICEPORT":  "80",
+				"NAMESPACE":    "default",
+				"IMAGENAME":    "testapp",
+				"DRAFTVERSION": "v1.2.3",
+			}, w)
+			assert.Nil(t, err)
+
+			content := string(w.FileMap[file])
+			assert.Contains(t, content, "draft.sh/generated-by: draft")
+			assert.Contains(t, content, `draft.sh/template-version: "v1.2.3"`)
+			assert.Contains(t, content, `draft.sh/workflow-run-url: "unset"`)
+		})
+	}
+}
+
+func TestCopyDeploymentFilesAzureTargets(t *testing.T) {
+	d := CreateDeploymentsFromEmbedFS(template.Deployments, "out")
+
+	w := &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("containerapp", map[string]string{"APPNAME": "testapp", "IMAGENAME": "testapp", "PORT": "8080"}, w))
+	containerApp := string(w.FileMap["out/azure/containerapp.yaml"])
+	assert.Contains(t, containerApp, "name: testapp")
+	assert.Contains(t, containerApp, "targetPort: 8080")
+	assert.Contains(t, containerApp, "image: testapp:latest")
+	assert.Contains(t, containerApp, "external: true")
+
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("appservice", map[string]string{"PORT": "8080"}, w))
+	assert.Contains(t, string(w.FileMap["out/azure/appsettings.json"]), `"value": "8080"`)
+	assert.Len(t, w.FileMap, 1)
+}
+
+func TestDeployTypesSorted(t *testing.T) {
+	d := CreateDeploymentsFromEmbedFS(template.Deployments, "")
+	for i := 0; i < 10; i++ {
+		assert.IsNonDecreasing(t, d.DeployTypes())
+	}
+}
+
+func TestCopyDeploymentFilesTemplateDir(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "deployments", "manifests", "manifests"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "deployments", "manifests", "manifests", "service.yaml"), []byte("kind: Service\nmetadata:\n  name: {{APPNAME}}-edited\n"), 0644))
+	t.Setenv(embedutils.TemplateDirEnv, dir)
+
+	d := CreateDeploymentsFromEmbedFS(template.Deployments, "out")
+	w := &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("manifests", map[string]string{
+		"APPNAME":     "testapp",
+		"PORT":        "80",
+		"SERVICEPORT": "80",
+		"NAMESPACE":   "default",
+		"IMAGENAME":   "testapp",
+	}, w))
+	assert.Equal(t, "kind: Service\nmetadata:\n  name: testapp-edited\n", string(w.FileMap["out/manifests/service.yaml"]))
+	assert.Contains(t, string(w.FileMap["out/manifests/deployment.yaml"]), "name: testapp\n", "the other files are the embedded ones")
+}
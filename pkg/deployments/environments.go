@@ -0,0 +1,141 @@
+package deployments
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Variables of the environments of the kustomize deployment type, each generated as an overlay of the shared base
+const (
+	EnvironmentsVariable          = "ENVIRONMENTS"
+	EnvironmentNamespacesVariable = "ENVIRONMENTNAMESPACES"
+	EnvironmentImageTagsVariable  = "ENVIRONMENTIMAGETAGS"
+	EnvironmentReplicasVariable   = "ENVIRONMENTREPLICAS"
+	// environmentVariable is set to the name of the environment when rendering its overlay
+	environmentVariable = "ENVIRONMENT"
+)
+
+const (
+	overlaysDir = "overlays"
+	// overlayTemplateDir is the overlay of the template rendered once for each environment
+	overlayTemplateDir = "overlays/production"
+)
+
+// environment is an overlay to generate and the variables it is rendered with
+type environment struct {
+	name   string
+	inputs map[string]string
+}
+
+// parseEnvironments returns the environments listed in ENVIRONMENTS, each with the variables of customInputs overridden
+// by its namespace, image tag and number of replicas. Templates without ENVIRONMENTS have none.
+func parseEnvironments(customInputs map[string]string) ([]environment, error) {
+	list, ok := customInputs[EnvironmentsVariable]
+	if !ok {
+		return nil, nil
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid environment %q in %s: %s", name, EnvironmentsVariable, strings.Join(errs, ", "))
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("environment %q is listed twice in %s", name, EnvironmentsVariable)
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%s must list at least one environment", EnvironmentsVariable)
+	}
+
+	environments := make([]environment, len(names))
+	for i, name := range names {
+		environments[i] = environment{name: name, inputs: maps.Clone(customInputs)}
+		environments[i].inputs[environmentVariable] = name
+	}
+	// each environment overrides the variable of the base with its own value, when it has one
+	for _, override := range []struct{ variable, overridden string }{
+		{EnvironmentNamespacesVariable, "NAMESPACE"},
+		{EnvironmentImageTagsVariable, "IMAGETAG"},
+		{EnvironmentReplicasVariable, "REPLICAS"},
+	} {
+		values, err := parseEnvironmentValues(override.variable, customInputs[override.variable], seen)
+		if err != nil {
+			return nil, err
+		}
+		for _, env := range environments {
+			if value, ok := values[env.name]; ok {
+				env.inputs[override.overridden] = value
+			}
+		}
+	}
+	return environments, nil
+}
+
+// parseEnvironmentValues parses the value of the variable name, a comma separated list of env=value pairs of the
+// known environments
+func parseEnvironmentValues(name, value string, known map[string]bool) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		env, envValue, ok := strings.Cut(pair, "=")
+		env, envValue = strings.TrimSpace(env), strings.TrimSpace(envValue)
+		if !ok || envValue == "" {
+			return nil, fmt.Errorf("invalid %s %q, must be environment=value", name, pair)
+		}
+		if !known[env] {
+			return nil, fmt.Errorf("invalid %s %q, %s isn't one of the environments in %s", name, pair, env, EnvironmentsVariable)
+		}
+		switch name {
+		case EnvironmentNamespacesVariable:
+			if errs := validation.IsDNS1123Label(envValue); len(errs) > 0 {
+				return nil, fmt.Errorf("invalid namespace %q of environment %s: %s", envValue, env, strings.Join(errs, ", "))
+			}
+		case EnvironmentReplicasVariable:
+			if replicas, err := strconv.Atoi(envValue); err != nil || replicas < 1 {
+				return nil, fmt.Errorf("invalid number of replicas %q of environment %s, must be a positive integer", envValue, env)
+			}
+		}
+		values[env] = envValue
+	}
+	return values, nil
+}
+
+// maxReplicas returns the largest number of replicas of REPLICAS, of the environments and of the autoscaler, which
+// decides whether replicas share a volume in any of them
+func maxReplicas(customInputs map[string]string) int {
+	replicas, _ := strconv.Atoi(customInputs["REPLICAS"])
+	if autoscalingEnabled(customInputs) {
+		if n, _ := strconv.Atoi(customInputs[AutoscalingMaxReplicasVariable]); n > replicas {
+			replicas = n
+		}
+	}
+	environments, _ := parseEnvironments(customInputs)
+	for _, env := range environments {
+		if n, _ := strconv.Atoi(env.inputs["REPLICAS"]); n > replicas {
+			replicas = n
+		}
+	}
+	return replicas
+}
+
+// isOverlay returns whether filePath is under the overlays directory of dest
+func isOverlay(dest, filePath string) bool {
+	overlays := path.Join(dest, overlaysDir)
+	return filePath == overlays || strings.HasPrefix(filePath, overlays+"/")
+}
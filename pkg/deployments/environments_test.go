@@ -0,0 +1,112 @@
+package deployments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+func TestParseEnvironments(t *testing.T) {
+	inputs := func(environments, namespaces, tags, replicas string) map[string]string {
+		return map[string]string{
+			"NAMESPACE":             "default",
+			"IMAGETAG":              "latest",
+			"REPLICAS":              "1",
+			"ENVIRONMENTS":          environments,
+			"ENVIRONMENTNAMESPACES": namespaces,
+			"ENVIRONMENTIMAGETAGS":  tags,
+			"ENVIRONMENTREPLICAS":   replicas,
+		}
+	}
+	tests := []struct {
+		name    string
+		inputs  map[string]string
+		want    map[string]map[string]string
+		wantErr bool
+	}{
+		{name: "older template", inputs: map[string]string{"NAMESPACE": "default"}},
+		{
+			name:   "defaults of the base",
+			inputs: inputs("production", "", "", ""),
+			want:   map[string]map[string]string{"production": {"NAMESPACE": "default", "IMAGETAG": "latest", "REPLICAS": "1"}},
+		},
+		{
+			name:   "overrides",
+			inputs: inputs("dev, staging,prod", "dev=app-dev,prod=app", "prod=v1.2.0", "staging=2, prod=3"),
+			want: map[string]map[string]string{
+				"dev":     {"NAMESPACE": "app-dev", "IMAGETAG": "latest", "REPLICAS": "1"},
+				"staging": {"NAMESPACE": "default", "IMAGETAG": "latest", "REPLICAS": "2"},
+				"prod":    {"NAMESPACE": "app", "IMAGETAG": "v1.2.0", "REPLICAS": "3"},
+			},
+		},
+		{name: "no environments", inputs: inputs(" , ", "", "", ""), wantErr: true},
+		{name: "invalid environment", inputs: inputs("Prod", "", "", ""), wantErr: true},
+		{name: "duplicate environment", inputs: inputs("dev,dev", "", "", ""), wantErr: true},
+		{name: "unknown environment", inputs: inputs("dev", "", "qa=v1", ""), wantErr: true},
+		{name: "missing value", inputs: inputs("dev", "dev", "", ""), wantErr: true},
+		{name: "invalid namespace", inputs: inputs("dev", "dev=App_Dev", "", ""), wantErr: true},
+		{name: "invalid replicas", inputs: inputs("dev", "", "", "dev=0"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			environments, err := parseEnvironments(tt.inputs)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Len(t, environments, len(tt.want))
+			for _, env := range environments {
+				want, ok := tt.want[env.name]
+				assert.True(t, ok, env.name)
+				assert.Equal(t, env.name, env.inputs["ENVIRONMENT"])
+				for name, value := range want {
+					assert.Equal(t, value, env.inputs[name], "%s of %s", name, env.name)
+				}
+			}
+		})
+	}
+}
+
+func TestCopyDeploymentFilesEnvironments(t *testing.T) {
+	d := CreateDeploymentsFromEmbedFS(template.Deployments, "out")
+	inputs := map[string]string{
+		"APPNAME":               "testapp",
+		"PORT":                  "80",
+		"SERVICEPORT":           "80",
+		"NAMESPACE":             "default",
+		"IMAGENAME":             "testapp",
+		"ENVIRONMENTS":          "dev,prod",
+		"ENVIRONMENTNAMESPACES": "prod=testapp",
+		"ENVIRONMENTIMAGETAGS":  "prod=v1.0.0",
+		"ENVIRONMENTREPLICAS":   "prod=3",
+	}
+
+	w := &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("kustomize", inputs, w))
+	assert.Contains(t, w.FileMap, "out/base/deployment.yaml")
+	assert.NotContains(t, w.FileMap, "out/overlays/production/kustomization.yaml")
+
+	dev := string(w.FileMap["out/overlays/dev/deployment.yaml"])
+	assert.Contains(t, dev, "replicas: 1\n")
+	assert.Contains(t, dev, "image: testapp:latest")
+	assert.Contains(t, string(w.FileMap["out/overlays/dev/kustomization.yaml"]), "namePrefix: dev-\nnamespace: default\n")
+
+	prod := string(w.FileMap["out/overlays/prod/deployment.yaml"])
+	assert.Contains(t, prod, "replicas: 3\n")
+	assert.Contains(t, prod, "image: testapp:v1.0.0")
+	assert.Contains(t, string(w.FileMap["out/overlays/prod/kustomization.yaml"]), "namePrefix: prod-\nnamespace: testapp\n")
+	assert.Contains(t, string(w.FileMap["out/overlays/prod/service.yaml"]), "namespace: testapp")
+
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("kustomize", map[string]string{"APPNAME": "testapp", "PORT": "80", "SERVICEPORT": "80", "IMAGENAME": "testapp"}, w))
+	assert.Contains(t, string(w.FileMap["out/overlays/production/kustomization.yaml"]), "namePrefix: production-\n")
+}
+
+func TestMaxReplicas(t *testing.T) {
+	assert.Equal(t, 2, maxReplicas(map[string]string{"REPLICAS": "2"}))
+	assert.Equal(t, 4, maxReplicas(map[string]string{"REPLICAS": "2", "ENVIRONMENTS": "dev,prod", "ENVIRONMENTREPLICAS": "prod=4"}))
+}
@@ -0,0 +1,47 @@
+package deployments
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const GatewayEnabledVariable = "GATEWAYENABLED"
+
+// validateGatewayVariables checks the Gateway API variables when the Gateway and HTTPRoute are enabled,
+// since an invalid hostname or path is otherwise only reported by the cluster on apply
+func validateGatewayVariables(customInputs map[string]string) error {
+	enabled, ok := customInputs[GatewayEnabledVariable]
+	if !ok || enabled == "" {
+		return nil
+	}
+	if !strings.EqualFold(enabled, "true") && !strings.EqualFold(enabled, "false") {
+		return fmt.Errorf("invalid %s %q, must be true or false", GatewayEnabledVariable, enabled)
+	}
+	if !strings.EqualFold(enabled, "true") {
+		return nil
+	}
+
+	if className := customInputs["GATEWAYCLASSNAME"]; className == "" {
+		return fmt.Errorf("GATEWAYCLASSNAME must be set when %s is true", GatewayEnabledVariable)
+	}
+
+	if err := validateHostname("GATEWAYHOSTNAME", customInputs["GATEWAYHOSTNAME"]); err != nil {
+		return err
+	}
+
+	return validatePath("GATEWAYPATH", customInputs["GATEWAYPATH"])
+}
+
+// validateHostname checks the hostname, which may be a wildcard such as *.example.com, of the variable name
+func validateHostname(name, hostname string) error {
+	if strings.HasPrefix(hostname, "*.") {
+		if errs := validation.IsWildcardDNS1123Subdomain(hostname); len(errs) > 0 {
+			return fmt.Errorf("invalid %s %q: %s", name, hostname, strings.Join(errs, ", "))
+		}
+	} else if errs := validation.IsDNS1123Subdomain(hostname); len(errs) > 0 {
+		return fmt.Errorf("invalid %s %q: %s", name, hostname, strings.Join(errs, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package deployments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+func TestValidateGatewayVariables(t *testing.T) {
+	valid := map[string]string{
+		"GATEWAYENABLED":   "true",
+		"GATEWAYCLASSNAME": "istio",
+		"GATEWAYHOSTNAME":  "app.example.com",
+		"GATEWAYPATH":      "/",
+	}
+	assert.Nil(t, validateGatewayVariables(valid))
+	assert.Nil(t, validateGatewayVariables(map[string]string{}))
+	assert.Nil(t, validateGatewayVariables(map[string]string{"GATEWAYENABLED": "false", "GATEWAYPATH": "bad"}))
+
+	tests := map[string]map[string]string{
+		"not a bool":       {"GATEWAYENABLED": "yes"},
+		"missing class":    {"GATEWAYCLASSNAME": ""},
+		"invalid hostname": {"GATEWAYHOSTNAME": "Not_A_Host"},
+		"invalid path":     {"GATEWAYPATH": "api"},
+	}
+	for name, overrides := range tests {
+		t.Run(name, func(t *testing.T) {
+			inputs := map[string]string{}
+			for k, v := range valid {
+				inputs[k] = v
+			}
+			for k, v := range overrides {
+				inputs[k] = v
+			}
+			assert.NotNil(t, validateGatewayVariables(inputs))
+		})
+	}
+
+	valid["GATEWAYHOSTNAME"] = "*.example.com"
+	assert.Nil(t, validateGatewayVariables(valid))
+}
+
+func TestCopyDeploymentFilesGateway(t *testing.T) {
+	inputs := func(enabled string) map[string]string {
+		return map[string]string{
+			"APPNAME":        "testapp",
+			"PORT":           "80",
+			"SERVICEPORT":    "80",
+			"NAMESPACE":      "default",
+			"IMAGENAME":      "testapp",
+			"GATEWAYENABLED": enabled,
+		}
+	}
+
+	d := CreateDeploymentsFromEmbedFS(template.Deployments, "out")
+	w := &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("manifests", inputs("false"), w))
+	assert.NotContains(t, w.FileMap, "out/manifests/gateway.yaml")
+	assert.NotContains(t, w.FileMap, "out/manifests/httproute.yaml")
+
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("manifests", inputs("true"), w))
+	assert.Contains(t, string(w.FileMap["out/manifests/gateway.yaml"]), "gatewayClassName: istio")
+	assert.Contains(t, string(w.FileMap["out/manifests/httproute.yaml"]), "value: /")
+
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("helm", inputs("true"), w))
+	assert.Contains(t, string(w.FileMap["out/charts/values.yaml"]), "enabled: true")
+	assert.Contains(t, w.FileMap, "out/charts/templates/httproute.yaml")
+}
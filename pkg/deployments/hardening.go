@@ -0,0 +1,109 @@
+package deployments
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"github.com/Azure/draft/pkg/consts"
+)
+
+// HardenedVariable runs the application's pod as a non-root user with a read-only root file system, as the hardened
+// images of the Dockerfile packs support
+const HardenedVariable = "HARDENED"
+
+// tmpVolumeName is the name of the emptyDir volume mounted at /tmp, the only path the hardened images write to
+const tmpVolumeName = "tmp"
+
+// hardenedPodSecurityContext lets the non-root user bind the ports below 1024, such as the default port 80 of the
+// Dockerfile packs
+const hardenedPodSecurityContext = `runAsNonRoot: true
+seccompProfile:
+  type: RuntimeDefault
+sysctls:
+  - name: net.ipv4.ip_unprivileged_port_start
+    value: "0"
+`
+
+const hardenedContainerSecurityContext = `allowPrivilegeEscalation: false
+readOnlyRootFilesystem: true
+capabilities:
+  drop:
+    - ALL
+`
+
+// hardened returns whether the application's pod runs hardened
+func hardened(customInputs map[string]string) bool {
+	return strings.EqualFold(customInputs[HardenedVariable], "true")
+}
+
+// validateHardenedVariable checks HARDENED is a boolean, since the plain yaml deployment types only harden the
+// workload when it is true
+func validateHardenedVariable(customInputs map[string]string) error {
+	value, ok := customInputs[HardenedVariable]
+	if !ok || value == "" {
+		return nil
+	}
+	if !strings.EqualFold(value, "true") && !strings.EqualFold(value, "false") {
+		return fmt.Errorf("invalid %s %q, must be true or false", HardenedVariable, value)
+	}
+	return nil
+}
+
+// hardeningMutator returns a PostRenderWriter mutation hardening the application's workload in the plain yaml
+// deployment types: the security contexts of its pod and container, and the /tmp volume it writes to instead of its
+// read-only root file system. Helm charts template it themselves.
+func hardeningMutator(deployType, dest string, customInputs map[string]string) func(string, []byte) ([]byte, error) {
+	workloadPath := path.Join(dest, consts.DeploymentManifestPaths[deployType])
+	return func(filePath string, content []byte) ([]byte, error) {
+		if filePath != workloadPath || !hardened(customInputs) {
+			return content, nil
+		}
+		return mutateYaml(content, hardenWorkload)
+	}
+}
+
+func hardenWorkload(node *kyaml.RNode) error {
+	podSecurityContext, err := kyaml.Parse(hardenedPodSecurityContext)
+	if err != nil {
+		return err
+	}
+	podSpec, err := node.Pipe(kyaml.Lookup("spec", "template", "spec"))
+	if err != nil || podSpec == nil {
+		return fmt.Errorf("no pod template found in %s %s to harden", node.GetKind(), node.GetName())
+	}
+	if err := podSpec.PipeE(kyaml.SetField("securityContext", podSecurityContext)); err != nil {
+		return err
+	}
+
+	containers, err := podSpec.Pipe(kyaml.Lookup("containers"))
+	if err != nil || containers == nil {
+		return fmt.Errorf("no containers found in %s %s to harden", node.GetKind(), node.GetName())
+	}
+	elements, err := containers.Elements()
+	if err != nil || len(elements) == 0 {
+		return fmt.Errorf("no containers found in %s %s to harden", node.GetKind(), node.GetName())
+	}
+	containerSecurityContext, err := kyaml.Parse(hardenedContainerSecurityContext)
+	if err != nil {
+		return err
+	}
+	if err := elements[0].PipeE(kyaml.SetField("securityContext", containerSecurityContext)); err != nil {
+		return err
+	}
+	volumeMount, err := kyaml.Parse(fmt.Sprintf("name: %s\nmountPath: /tmp\n", tmpVolumeName))
+	if err != nil {
+		return err
+	}
+	if err := elements[0].PipeE(kyaml.LookupCreate(kyaml.SequenceNode, "volumeMounts"), kyaml.Append(volumeMount.YNode())); err != nil {
+		return err
+	}
+
+	volume, err := kyaml.Parse(fmt.Sprintf("name: %s\nemptyDir: {}\n", tmpVolumeName))
+	if err != nil {
+		return err
+	}
+	return podSpec.PipeE(kyaml.LookupCreate(kyaml.SequenceNode, "volumes"), kyaml.Append(volume.YNode()))
+}
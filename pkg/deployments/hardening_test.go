@@ -0,0 +1,53 @@
+package deployments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+func TestCopyDeploymentFilesHardened(t *testing.T) {
+	inputs := func(hardened string) map[string]string {
+		return map[string]string{
+			"APPNAME":     "testapp",
+			"PORT":        "80",
+			"SERVICEPORT": "80",
+			"NAMESPACE":   "default",
+			"IMAGENAME":   "testapp",
+			"HARDENED":    hardened,
+		}
+	}
+
+	d := CreateDeploymentsFromEmbedFS(template.Deployments, "out")
+	w := &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("manifests", inputs("true"), w))
+	deployment := string(w.FileMap["out/manifests/deployment.yaml"])
+	assert.Contains(t, deployment, "      securityContext:\n        runAsNonRoot: true\n        seccompProfile:\n          type: RuntimeDefault\n")
+	assert.Contains(t, deployment, "name: net.ipv4.ip_unprivileged_port_start")
+	assert.Contains(t, deployment, "          securityContext:\n            allowPrivilegeEscalation: false\n            readOnlyRootFilesystem: true\n")
+	assert.Contains(t, deployment, "volumeMounts:\n            - name: tmp\n              mountPath: /tmp\n")
+	assert.Contains(t, deployment, "volumes:\n        - name: tmp\n          emptyDir: {}\n")
+
+	// the /tmp volume is added next to the persistent storage
+	persistent := inputs("true")
+	persistent["PERSISTENCEENABLED"] = "true"
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("kustomize", persistent, w))
+	deployment = string(w.FileMap["out/base/deployment.yaml"])
+	assert.Contains(t, deployment, "- name: data\n")
+	assert.Contains(t, deployment, "- name: tmp\n")
+	assert.Contains(t, deployment, "readOnlyRootFilesystem: true")
+
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("manifests", inputs("false"), w))
+	assert.NotContains(t, string(w.FileMap["out/manifests/deployment.yaml"]), "securityContext")
+
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("helm", inputs("true"), w))
+	assert.Contains(t, string(w.FileMap["out/charts/values.yaml"]), "\nhardened: true\n")
+
+	assert.ErrorContains(t, d.CopyDeploymentFiles("manifests", inputs("yes"), &writers.FileMapWriter{}), `invalid HARDENED "yes", must be true or false`)
+}
@@ -0,0 +1,179 @@
+package deployments
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"github.com/Azure/draft/pkg/consts"
+)
+
+// Variables of the Ingress exposing the service
+const (
+	IngressTypeVariable           = "INGRESSTYPE"
+	IngressHostVariable           = "INGRESSHOST"
+	IngressPathVariable           = "INGRESSPATH"
+	IngressClassNameVariable      = "INGRESSCLASSNAME"
+	IngressTLSSecretVariable      = "INGRESSTLSSECRET"
+	IngressTLSKeyVaultURIVariable = "INGRESSTLSKEYVAULTURI"
+	// ingressEnabledVariable gates the Ingress, generated unless INGRESSTYPE is none
+	ingressEnabledVariable = "INGRESSENABLED"
+	// ingressClassVariable is the class of the Ingress, that of the application routing add-on for app-routing
+	ingressClassVariable = "INGRESSCLASS"
+)
+
+// ProvidedVariables are the variables draft sets from the others when rendering deployment packs, which the packs use
+// without declaring
+var ProvidedVariables = []string{
+	ingressEnabledVariable, ingressClassVariable, sidecarsValuesVariable, initContainersValuesVariable,
+	sharedVolumesValuesVariable, pvcEnabledVariable, environmentVariable,
+}
+
+// ConsumedVariables are the variables of deployment packs draft reads to set others or to patch the rendered files,
+// which the packs declare without using
+var ConsumedVariables = []string{
+	IngressTypeVariable, IngressClassNameVariable, IngressTLSSecretVariable, IngressTLSKeyVaultURIVariable,
+	SidecarsVariable, InitContainersVariable, ResourcePresetVariable, PersistenceEnabledVariable,
+	StorageMountPathVariable, EnvironmentsVariable, EnvironmentNamespacesVariable, EnvironmentImageTagsVariable,
+	EnvironmentReplicasVariable, HardenedVariable,
+}
+
+// Values of INGRESSTYPE
+const (
+	IngressTypeNone       = "none"
+	IngressTypeStandard   = "standard"
+	IngressTypeAppRouting = "app-routing"
+)
+
+// appRoutingIngressClass is the IngressClass of the AKS application routing add-on
+const appRoutingIngressClass = "webapprouting.kubernetes.azure.com"
+
+// keyVaultSecretPrefix prefixes the name of the Ingress to name the Secret the application routing add-on syncs its
+// Key Vault certificate into
+const keyVaultSecretPrefix = "keyvault-"
+
+// applyIngress validates the Ingress variables and resolves INGRESSTYPE into whether the Ingress is generated and its
+// class. Templates without INGRESSTYPE are left alone.
+func applyIngress(customInputs map[string]string) error {
+	ingressType, ok := customInputs[IngressTypeVariable]
+	if !ok {
+		return nil
+	}
+
+	var class string
+	switch strings.ToLower(ingressType) {
+	case IngressTypeNone, "":
+		customInputs[ingressEnabledVariable] = "false"
+		customInputs[ingressClassVariable] = customInputs[IngressClassNameVariable]
+		return nil
+	case IngressTypeStandard:
+		class = customInputs[IngressClassNameVariable]
+		if errs := validation.IsDNS1123Subdomain(class); len(errs) > 0 {
+			return fmt.Errorf("invalid %s %q: %s", IngressClassNameVariable, class, strings.Join(errs, ", "))
+		}
+	case IngressTypeAppRouting:
+		class = appRoutingIngressClass
+	default:
+		return fmt.Errorf("invalid %s %q, must be one of: %s, %s, %s", IngressTypeVariable, ingressType, IngressTypeNone, IngressTypeStandard, IngressTypeAppRouting)
+	}
+
+	if err := validateHostname(IngressHostVariable, customInputs[IngressHostVariable]); err != nil {
+		return err
+	}
+	if err := validatePath(IngressPathVariable, customInputs[IngressPathVariable]); err != nil {
+		return err
+	}
+
+	if secret := customInputs[IngressTLSSecretVariable]; secret != "" {
+		if errs := validation.IsDNS1123Subdomain(secret); len(errs) > 0 {
+			return fmt.Errorf("invalid %s %q: %s", IngressTLSSecretVariable, secret, strings.Join(errs, ", "))
+		}
+	}
+	if keyVaultURI := customInputs[IngressTLSKeyVaultURIVariable]; keyVaultURI != "" {
+		if !strings.EqualFold(ingressType, IngressTypeAppRouting) {
+			return fmt.Errorf("%s only applies to the %s %s", IngressTLSKeyVaultURIVariable, IngressTypeAppRouting, IngressTypeVariable)
+		}
+		if customInputs[IngressTLSSecretVariable] != "" {
+			return fmt.Errorf("%s and %s can't both be set, the certificate is served from one of them", IngressTLSSecretVariable, IngressTLSKeyVaultURIVariable)
+		}
+		if !strings.HasPrefix(keyVaultURI, "https://") {
+			return fmt.Errorf("invalid %s %q, must be the https URI of a Key Vault certificate", IngressTLSKeyVaultURIVariable, keyVaultURI)
+		}
+	}
+
+	customInputs[ingressEnabledVariable] = "true"
+	customInputs[ingressClassVariable] = class
+	return nil
+}
+
+// ingressMutator returns a PostRenderWriter mutation completing the Ingress in the plain yaml deployment types: the
+// TLS of its host and the Key Vault certificate annotation of the application routing add-on, and the Ingress in the
+// kustomization next to the workload. The add-on names the Secret of the certificate after the Ingress, so the
+// overlays, which prefix the name of the Ingress with their environment, patch the Secret of the TLS to match. Helm
+// charts template it themselves.
+func ingressMutator(deployType, dest string, customInputs map[string]string) func(string, []byte) ([]byte, error) {
+	workloadDir := path.Dir(path.Join(dest, consts.DeploymentManifestPaths[deployType]))
+	keyVault := customInputs[IngressTLSKeyVaultURIVariable] != ""
+	return func(filePath string, content []byte) ([]byte, error) {
+		if customInputs[ingressEnabledVariable] != "true" {
+			return content, nil
+		}
+		switch {
+		case filePath == path.Join(workloadDir, "ingress.yaml"):
+			return mutateYaml(content, func(node *kyaml.RNode) error {
+				return addIngressTLS(node, customInputs)
+			})
+		case filePath == path.Join(workloadDir, "kustomization.yaml"):
+			return mutateYaml(content, func(node *kyaml.RNode) error {
+				return node.PipeE(kyaml.LookupCreate(kyaml.SequenceNode, "resources"), kyaml.Append(kyaml.NewStringRNode("ingress.yaml").YNode()))
+			})
+		case keyVault && isOverlay(dest, filePath) && path.Base(filePath) == "kustomization.yaml":
+			return mutateYaml(content, func(node *kyaml.RNode) error {
+				return patchKeyVaultSecret(node, customInputs["APPNAME"])
+			})
+		}
+		return content, nil
+	}
+}
+
+func addIngressTLS(node *kyaml.RNode, customInputs map[string]string) error {
+	secret := customInputs[IngressTLSSecretVariable]
+	if keyVaultURI := customInputs[IngressTLSKeyVaultURIVariable]; keyVaultURI != "" {
+		if err := node.PipeE(kyaml.SetAnnotation("kubernetes.azure.com/tls-cert-keyvault-uri", keyVaultURI)); err != nil {
+			return err
+		}
+		secret = keyVaultSecretPrefix + node.GetName()
+	}
+	if secret == "" {
+		return nil
+	}
+	tls, err := kyaml.Parse(fmt.Sprintf("hosts:\n  - %q\nsecretName: %s\n", customInputs[IngressHostVariable], secret))
+	if err != nil {
+		return err
+	}
+	return node.PipeE(kyaml.LookupCreate(kyaml.SequenceNode, "spec", "tls"), kyaml.Append(tls.YNode()))
+}
+
+// patchKeyVaultSecret patches the Secret of the TLS of the Ingress name in the overlay kustomization node to the one
+// the application routing add-on syncs the certificate of the Ingress into, once the overlay prefixed its name
+func patchKeyVaultSecret(node *kyaml.RNode, name string) error {
+	prefix, err := node.Pipe(kyaml.Lookup("namePrefix"))
+	if err != nil || prefix == nil {
+		return err
+	}
+	patch, err := kyaml.Parse(fmt.Sprintf(`target:
+  kind: Ingress
+  name: %s
+patch: |-
+  - op: replace
+    path: /spec/tls/0/secretName
+    value: %s%s%s
+`, name, keyVaultSecretPrefix, kyaml.GetValue(prefix), name))
+	if err != nil {
+		return err
+	}
+	return node.PipeE(kyaml.LookupCreate(kyaml.SequenceNode, "patches"), kyaml.Append(patch.YNode()))
+}
@@ -0,0 +1,107 @@
+package deployments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+func TestApplyIngress(t *testing.T) {
+	inputs := func(ingressType string, overrides ...string) map[string]string {
+		inputs := map[string]string{
+			"APPNAME":               "testapp",
+			"INGRESSTYPE":           ingressType,
+			"INGRESSHOST":           "testapp.example.com",
+			"INGRESSPATH":           "/",
+			"INGRESSCLASSNAME":      "nginx",
+			"INGRESSTLSSECRET":      "",
+			"INGRESSTLSKEYVAULTURI": "",
+		}
+		for i := 0; i+1 < len(overrides); i += 2 {
+			inputs[overrides[i]] = overrides[i+1]
+		}
+		return inputs
+	}
+
+	tests := []struct {
+		name      string
+		inputs    map[string]string
+		wantClass string
+		wantErr   string
+	}{
+		{name: "none", inputs: inputs("none", "INGRESSHOST", "not a host"), wantClass: "nginx"},
+		{name: "standard", inputs: inputs("standard", "INGRESSCLASSNAME", "traefik"), wantClass: "traefik"},
+		{name: "standard with tls", inputs: inputs("standard", "INGRESSTLSSECRET", "testapp-tls"), wantClass: "nginx"},
+		{name: "app routing", inputs: inputs("App-Routing"), wantClass: "webapprouting.kubernetes.azure.com"},
+		{name: "app routing with key vault", inputs: inputs("app-routing", "INGRESSTLSKEYVAULTURI", "https://myvault.vault.azure.net/certificates/testapp"), wantClass: "webapprouting.kubernetes.azure.com"},
+		{name: "wildcard host", inputs: inputs("standard", "INGRESSHOST", "*.example.com"), wantClass: "nginx"},
+		{name: "invalid type", inputs: inputs("traefik"), wantErr: "invalid INGRESSTYPE"},
+		{name: "invalid host", inputs: inputs("standard", "INGRESSHOST", "Not A Host"), wantErr: "invalid INGRESSHOST"},
+		{name: "invalid path", inputs: inputs("standard", "INGRESSPATH", "api"), wantErr: "invalid INGRESSPATH"},
+		{name: "invalid secret", inputs: inputs("standard", "INGRESSTLSSECRET", "TLS_Secret"), wantErr: "invalid INGRESSTLSSECRET"},
+		{name: "key vault without app routing", inputs: inputs("standard", "INGRESSTLSKEYVAULTURI", "https://myvault.vault.azure.net/certificates/testapp"), wantErr: "INGRESSTLSKEYVAULTURI only applies to the app-routing INGRESSTYPE"},
+		{name: "key vault and secret", inputs: inputs("app-routing", "INGRESSTLSSECRET", "testapp-tls", "INGRESSTLSKEYVAULTURI", "https://myvault.vault.azure.net/certificates/testapp"), wantErr: "can't both be set"},
+		{name: "invalid key vault uri", inputs: inputs("app-routing", "INGRESSTLSKEYVAULTURI", "myvault/testapp"), wantErr: "invalid INGRESSTLSKEYVAULTURI"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := applyIngress(tt.inputs)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantClass, tt.inputs["INGRESSCLASS"])
+			assert.Equal(t, tt.inputs["INGRESSTYPE"] != "none", tt.inputs["INGRESSENABLED"] == "true")
+		})
+	}
+}
+
+func TestCopyDeploymentFilesIngress(t *testing.T) {
+	inputs := func(ingressType string) map[string]string {
+		return map[string]string{
+			"APPNAME":          "testapp",
+			"PORT":             "80",
+			"SERVICEPORT":      "8080",
+			"NAMESPACE":        "default",
+			"IMAGENAME":        "testapp",
+			"INGRESSTYPE":      ingressType,
+			"INGRESSHOST":      "testapp.example.com",
+			"INGRESSTLSSECRET": "testapp-tls",
+		}
+	}
+
+	d := CreateDeploymentsFromEmbedFS(template.Deployments, "out")
+	w := &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("manifests", inputs("none"), w))
+	assert.NotContains(t, w.FileMap, "out/manifests/ingress.yaml")
+
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("manifests", inputs("standard"), w))
+	ingress := string(w.FileMap["out/manifests/ingress.yaml"])
+	assert.Contains(t, ingress, "ingressClassName: nginx")
+	assert.Contains(t, ingress, "- host: \"testapp.example.com\"")
+	assert.Contains(t, ingress, "number: 8080")
+	assert.Contains(t, ingress, "tls:\n    - hosts:\n        - \"testapp.example.com\"\n      secretName: testapp-tls\n")
+
+	appRouting := inputs("app-routing")
+	appRouting["INGRESSTLSSECRET"] = ""
+	appRouting["INGRESSTLSKEYVAULTURI"] = "https://myvault.vault.azure.net/certificates/testapp"
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("kustomize", appRouting, w))
+	ingress = string(w.FileMap["out/base/ingress.yaml"])
+	assert.Contains(t, ingress, "ingressClassName: webapprouting.kubernetes.azure.com")
+	assert.Contains(t, ingress, "kubernetes.azure.com/tls-cert-keyvault-uri: 'https://myvault.vault.azure.net/certificates/testapp'")
+	assert.Contains(t, ingress, "secretName: keyvault-testapp")
+	assert.Contains(t, string(w.FileMap["out/base/kustomization.yaml"]), "- ingress.yaml")
+	// the overlay renames the Ingress, and so the Secret the add-on syncs its certificate into
+	assert.Contains(t, string(w.FileMap["out/overlays/production/kustomization.yaml"]), "value: keyvault-production-testapp")
+
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("helm", inputs("standard"), w))
+	assert.Contains(t, string(w.FileMap["out/charts/values.yaml"]), "ingress:\n  enabled: true\n  className: nginx\n  host: \"testapp.example.com\"\n  path: /\n  tls:\n    secretName: \"testapp-tls\"\n")
+	assert.Contains(t, w.FileMap, "out/charts/templates/ingress.yaml")
+}
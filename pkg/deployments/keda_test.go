@@ -0,0 +1,41 @@
+package deployments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+func TestCopyDeploymentFilesKeda(t *testing.T) {
+	inputs := func(enabled string) map[string]string {
+		return map[string]string{
+			"APPNAME":     "testapp",
+			"PORT":        "80",
+			"SERVICEPORT": "80",
+			"NAMESPACE":   "default",
+			"IMAGENAME":   "testapp",
+			"KEDAENABLED": enabled,
+		}
+	}
+
+	d := CreateDeploymentsFromEmbedFS(template.Deployments, "out")
+	w := &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("manifests", inputs("false"), w))
+	assert.NotContains(t, w.FileMap, "out/manifests/scaledobject.yaml")
+
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("manifests", inputs("true"), w))
+	scaledObject := string(w.FileMap["out/manifests/scaledobject.yaml"])
+	assert.Contains(t, scaledObject, "- type: azure-queue")
+	assert.Contains(t, scaledObject, "connectionFromEnv: AzureWebJobsStorage")
+	assert.Contains(t, scaledObject, "minReplicaCount: 0")
+
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("helm", inputs("true"), w))
+	assert.Contains(t, string(w.FileMap["out/charts/values.yaml"]), "queueName: items")
+	assert.Contains(t, string(w.FileMap["out/charts/templates/deployment.yaml"]), "or .Values.autoscaling.enabled .Values.keda.enabled")
+	assert.Contains(t, w.FileMap, "out/charts/templates/scaledobject.yaml")
+}
@@ -0,0 +1,193 @@
+package deployments
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"github.com/Azure/draft/pkg/consts"
+)
+
+// Variables of the persistent storage scaffolding
+const (
+	PersistenceEnabledVariable = "PERSISTENCEENABLED"
+	StorageSizeVariable        = "STORAGESIZE"
+	StorageClassVariable       = "STORAGECLASSNAME"
+	StorageMountPathVariable   = "STORAGEMOUNTPATH"
+	StorageAccessModeVariable  = "STORAGEACCESSMODE"
+	WorkloadKindVariable       = "WORKLOADKIND"
+	// pvcEnabledVariable gates the standalone PersistentVolumeClaim, which StatefulSets replace with volumeClaimTemplates
+	pvcEnabledVariable = "PVCENABLED"
+)
+
+// Values of WORKLOADKIND
+const (
+	WorkloadKindAuto        = "auto"
+	WorkloadKindDeployment  = "Deployment"
+	WorkloadKindStatefulSet = "StatefulSet"
+)
+
+// storageVolumeName is the name of the volume and volume claim template holding the persistent storage
+const storageVolumeName = "data"
+
+var storageAccessModes = []string{"ReadWriteOnce", "ReadWriteMany", "ReadOnlyMany", "ReadWriteOncePod"}
+
+// applyPersistence validates the storage variables and resolves a WORKLOADKIND of auto to a StatefulSet when more than
+// one replica would share a ReadWriteOnce volume, which a Deployment can't schedule across nodes, and to a Deployment
+// otherwise. Templates without WORKLOADKIND are left alone.
+func applyPersistence(customInputs map[string]string) error {
+	kind, ok := customInputs[WorkloadKindVariable]
+	if !ok {
+		return nil
+	}
+
+	enabled := customInputs[PersistenceEnabledVariable]
+	if !strings.EqualFold(enabled, "true") && !strings.EqualFold(enabled, "false") {
+		return fmt.Errorf("invalid %s %q, must be true or false", PersistenceEnabledVariable, enabled)
+	}
+	persistent := strings.EqualFold(enabled, "true")
+
+	if persistent {
+		if _, err := resource.ParseQuantity(customInputs[StorageSizeVariable]); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", StorageSizeVariable, customInputs[StorageSizeVariable], err)
+		}
+		if mountPath := customInputs[StorageMountPathVariable]; !strings.HasPrefix(mountPath, "/") {
+			return fmt.Errorf("invalid %s %q, must be an absolute path", StorageMountPathVariable, mountPath)
+		}
+		if !isStorageAccessMode(customInputs[StorageAccessModeVariable]) {
+			return fmt.Errorf("invalid %s %q, must be one of: %s", StorageAccessModeVariable, customInputs[StorageAccessModeVariable], strings.Join(storageAccessModes, ", "))
+		}
+	}
+
+	switch {
+	case strings.EqualFold(kind, WorkloadKindDeployment):
+		kind = WorkloadKindDeployment
+	case strings.EqualFold(kind, WorkloadKindStatefulSet):
+		kind = WorkloadKindStatefulSet
+	case strings.EqualFold(kind, WorkloadKindAuto), kind == "":
+		kind = WorkloadKindDeployment
+		replicas := maxReplicas(customInputs)
+		if persistent && strings.HasPrefix(customInputs[StorageAccessModeVariable], "ReadWriteOnce") && replicas > 1 {
+			log.Infof("--> Using a StatefulSet so each of the %d replicas gets its own %s volume", replicas, customInputs[StorageAccessModeVariable])
+			kind = WorkloadKindStatefulSet
+		}
+	default:
+		return fmt.Errorf("invalid %s %q, must be one of: %s, %s, %s", WorkloadKindVariable, kind, WorkloadKindAuto, WorkloadKindDeployment, WorkloadKindStatefulSet)
+	}
+
+	customInputs[WorkloadKindVariable] = kind
+	customInputs[pvcEnabledVariable] = strconv.FormatBool(persistent && kind == WorkloadKindDeployment)
+	return nil
+}
+
+func isStorageAccessMode(mode string) bool {
+	for _, m := range storageAccessModes {
+		if mode == m {
+			return true
+		}
+	}
+	return false
+}
+
+// persistenceMutator returns a PostRenderWriter mutation adding the storage plumbing to the application's workload in
+// the plain yaml deployment types: the volume mount, the claim or volume claim template, the governing service of a
+// StatefulSet, and the claim in the kustomization next to the workload. Helm charts template it themselves.
+func persistenceMutator(deployType, dest string, customInputs map[string]string) func(string, []byte) ([]byte, error) {
+	workloadPath := path.Join(dest, consts.DeploymentManifestPaths[deployType])
+	return func(filePath string, content []byte) ([]byte, error) {
+		switch filePath {
+		case workloadPath:
+			if !strings.EqualFold(customInputs[PersistenceEnabledVariable], "true") && customInputs[WorkloadKindVariable] != WorkloadKindStatefulSet {
+				return content, nil
+			}
+			return mutateYaml(content, func(node *kyaml.RNode) error {
+				return addWorkloadStorage(node, customInputs)
+			})
+		case path.Join(path.Dir(workloadPath), "kustomization.yaml"):
+			if customInputs[pvcEnabledVariable] != "true" {
+				return content, nil
+			}
+			return mutateYaml(content, func(node *kyaml.RNode) error {
+				return node.PipeE(kyaml.LookupCreate(kyaml.SequenceNode, "resources"), kyaml.Append(kyaml.NewStringRNode("pvc.yaml").YNode()))
+			})
+		}
+		return content, nil
+	}
+}
+
+func mutateYaml(content []byte, mutate func(*kyaml.RNode) error) ([]byte, error) {
+	var out bytes.Buffer
+	rw := &kio.ByteReadWriter{Reader: bytes.NewReader(content), Writer: &out, PreserveSeqIndent: true}
+	nodes, err := rw.Read()
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		if err := mutate(node); err != nil {
+			return nil, err
+		}
+	}
+	if err := rw.Write(nodes); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func addWorkloadStorage(node *kyaml.RNode, customInputs map[string]string) error {
+	name := node.GetName()
+	statefulSet := node.GetKind() == WorkloadKindStatefulSet
+	if statefulSet {
+		if err := node.PipeE(kyaml.Lookup("spec"), kyaml.SetField("serviceName", kyaml.NewStringRNode(name))); err != nil {
+			return err
+		}
+	}
+	if !strings.EqualFold(customInputs[PersistenceEnabledVariable], "true") {
+		return nil
+	}
+
+	containers, err := node.Pipe(kyaml.Lookup("spec", "template", "spec", "containers"))
+	if err != nil || containers == nil {
+		return fmt.Errorf("no containers found in %s %s to mount the volume into", node.GetKind(), name)
+	}
+	elements, err := containers.Elements()
+	if err != nil || len(elements) == 0 {
+		return fmt.Errorf("no containers found in %s %s to mount the volume into", node.GetKind(), name)
+	}
+	volumeMount, err := kyaml.Parse(fmt.Sprintf("name: %s\nmountPath: %s\n", storageVolumeName, customInputs[StorageMountPathVariable]))
+	if err != nil {
+		return err
+	}
+	if err := elements[0].PipeE(kyaml.LookupCreate(kyaml.SequenceNode, "volumeMounts"), kyaml.Append(volumeMount.YNode())); err != nil {
+		return err
+	}
+
+	if statefulSet {
+		claimTemplate, err := kyaml.Parse(fmt.Sprintf(`metadata:
+  name: %s
+spec:
+  accessModes:
+    - %s
+  storageClassName: %s
+  resources:
+    requests:
+      storage: %s
+`, storageVolumeName, customInputs[StorageAccessModeVariable], customInputs[StorageClassVariable], customInputs[StorageSizeVariable]))
+		if err != nil {
+			return err
+		}
+		return node.PipeE(kyaml.LookupCreate(kyaml.SequenceNode, "spec", "volumeClaimTemplates"), kyaml.Append(claimTemplate.YNode()))
+	}
+
+	volume, err := kyaml.Parse(fmt.Sprintf("name: %s\npersistentVolumeClaim:\n  claimName: %s-%s\n", storageVolumeName, name, storageVolumeName))
+	if err != nil {
+		return err
+	}
+	return node.PipeE(kyaml.LookupCreate(kyaml.SequenceNode, "spec", "template", "spec", "volumes"), kyaml.Append(volume.YNode()))
+}
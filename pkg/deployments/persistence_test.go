@@ -0,0 +1,102 @@
+package deployments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+func TestApplyPersistence(t *testing.T) {
+	tests := []struct {
+		name     string
+		inputs   map[string]string
+		wantKind string
+		wantPVC  string
+		wantErr  bool
+	}{
+		{name: "older template", inputs: map[string]string{}},
+		{name: "disabled", inputs: map[string]string{"WORKLOADKIND": "auto", "PERSISTENCEENABLED": "false", "REPLICAS": "3"}, wantKind: "Deployment", wantPVC: "false"},
+		{name: "single replica", inputs: persistenceInputs("auto", "ReadWriteOnce", "1"), wantKind: "Deployment", wantPVC: "true"},
+		{name: "shared read write once", inputs: persistenceInputs("auto", "ReadWriteOnce", "3"), wantKind: "StatefulSet", wantPVC: "false"},
+		{name: "read write many", inputs: persistenceInputs("auto", "ReadWriteMany", "3"), wantKind: "Deployment", wantPVC: "true"},
+		{name: "explicit kind", inputs: persistenceInputs("statefulset", "ReadWriteMany", "1"), wantKind: "StatefulSet", wantPVC: "false"},
+		{name: "invalid kind", inputs: persistenceInputs("DaemonSet", "ReadWriteOnce", "1"), wantErr: true},
+		{name: "invalid access mode", inputs: persistenceInputs("auto", "ReadWriteAll", "1"), wantErr: true},
+		{name: "invalid size", inputs: map[string]string{"WORKLOADKIND": "auto", "PERSISTENCEENABLED": "true", "STORAGESIZE": "big", "STORAGEMOUNTPATH": "/data", "STORAGEACCESSMODE": "ReadWriteOnce"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := applyPersistence(tt.inputs)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantKind, tt.inputs["WORKLOADKIND"])
+			assert.Equal(t, tt.wantPVC, tt.inputs["PVCENABLED"])
+		})
+	}
+}
+
+func persistenceInputs(kind, accessMode, replicas string) map[string]string {
+	return map[string]string{
+		"WORKLOADKIND":       kind,
+		"PERSISTENCEENABLED": "true",
+		"STORAGESIZE":        "5Gi",
+		"STORAGECLASSNAME":   "managed-csi",
+		"STORAGEMOUNTPATH":   "/var/lib/app",
+		"STORAGEACCESSMODE":  accessMode,
+		"REPLICAS":           replicas,
+	}
+}
+
+func TestCopyDeploymentFilesPersistence(t *testing.T) {
+	inputs := func(kind string) map[string]string {
+		return map[string]string{
+			"APPNAME":            "testapp",
+			"PORT":               "80",
+			"SERVICEPORT":        "80",
+			"NAMESPACE":          "default",
+			"IMAGENAME":          "testapp",
+			"PERSISTENCEENABLED": "true",
+			"STORAGESIZE":        "5Gi",
+			"STORAGEMOUNTPATH":   "/var/lib/app",
+			"WORKLOADKIND":       kind,
+		}
+	}
+	d := CreateDeploymentsFromEmbedFS(template.Deployments, "out")
+
+	w := &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("manifests", inputs("auto"), w))
+	deployment := string(w.FileMap["out/manifests/deployment.yaml"])
+	assert.Contains(t, deployment, "kind: Deployment")
+	assert.Contains(t, deployment, "volumeMounts:\n            - name: data\n              mountPath: /var/lib/app")
+	assert.Contains(t, deployment, "claimName: testapp-data")
+	assert.Contains(t, string(w.FileMap["out/manifests/pvc.yaml"]), "storage: 5Gi")
+
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("manifests", inputs("StatefulSet"), w))
+	statefulSet := string(w.FileMap["out/manifests/deployment.yaml"])
+	assert.Contains(t, statefulSet, "kind: StatefulSet")
+	assert.Contains(t, statefulSet, "serviceName: testapp")
+	assert.Contains(t, statefulSet, "volumeClaimTemplates:")
+	assert.NotContains(t, statefulSet, "claimName")
+	assert.NotContains(t, w.FileMap, "out/manifests/pvc.yaml")
+
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("kustomize", inputs("auto"), w))
+	assert.Contains(t, string(w.FileMap["out/base/kustomization.yaml"]), "- pvc.yaml")
+	assert.Contains(t, string(w.FileMap["out/base/deployment.yaml"]), "claimName: testapp-data")
+	assert.NotContains(t, string(w.FileMap["out/overlays/production/deployment.yaml"]), "claimName")
+	assert.Contains(t, w.FileMap, "out/base/pvc.yaml")
+
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("helm", inputs("StatefulSet"), w))
+	values := string(w.FileMap["out/charts/values.yaml"])
+	assert.Contains(t, values, "workloadKind: StatefulSet")
+	assert.Contains(t, values, "mountPath: /var/lib/app")
+	assert.Contains(t, w.FileMap, "out/charts/templates/pvc.yaml")
+}
@@ -0,0 +1,148 @@
+package deployments
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	ResourcePresetVariable = "RESOURCEPRESET"
+	// CustomResourcePreset leaves the sizing variables untouched so they can be set individually
+	CustomResourcePreset = "custom"
+)
+
+// ResourcePreset is a curated set of replica count and container resource requests/limits
+type ResourcePreset struct {
+	Replicas      string
+	CPURequest    string
+	CPULimit      string
+	MemoryRequest string
+	MemoryLimit   string
+}
+
+// ResourcePresets are the supported values of the RESOURCEPRESET variable, apart from CustomResourcePreset
+var ResourcePresets = map[string]ResourcePreset{
+	"small": {
+		Replicas:      "1",
+		CPURequest:    "100m",
+		CPULimit:      "250m",
+		MemoryRequest: "128Mi",
+		MemoryLimit:   "256Mi",
+	},
+	"medium": {
+		Replicas:      "2",
+		CPURequest:    "250m",
+		CPULimit:      "500m",
+		MemoryRequest: "256Mi",
+		MemoryLimit:   "512Mi",
+	},
+	"large": {
+		Replicas:      "3",
+		CPURequest:    "500m",
+		CPULimit:      "1",
+		MemoryRequest: "512Mi",
+		MemoryLimit:   "1Gi",
+	},
+}
+
+func (p ResourcePreset) variables() map[string]string {
+	return map[string]string{
+		"REPLICAS":      p.Replicas,
+		"CPUREQUEST":    p.CPURequest,
+		"CPULIMIT":      p.CPULimit,
+		"MEMORYREQUEST": p.MemoryRequest,
+		"MEMORYLIMIT":   p.MemoryLimit,
+	}
+}
+
+// ResourcePresetNames returns the sorted names of the supported presets, including CustomResourcePreset
+func ResourcePresetNames() []string {
+	names := make([]string, 0, len(ResourcePresets)+1)
+	for name := range ResourcePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append(names, CustomResourcePreset)
+}
+
+// ResolveResourcePreset makes the sizing variables set explicitly, such as with --variable, win over the resource
+// preset. Setting any of them without a preset switches RESOURCEPRESET in customInputs to CustomResourcePreset, while
+// setting them along with a preset other than CustomResourcePreset is an error since the preset would overwrite them.
+func ResolveResourcePreset(customInputs, explicit map[string]string) error {
+	var sizing []string
+	for name := range (ResourcePreset{}).variables() {
+		if _, ok := explicit[name]; ok {
+			sizing = append(sizing, name)
+		}
+	}
+	if len(sizing) == 0 {
+		return nil
+	}
+	sort.Strings(sizing)
+
+	presetName, ok := explicit[ResourcePresetVariable]
+	if ok && presetName != "" && strings.ToLower(presetName) != CustomResourcePreset {
+		return fmt.Errorf("%s %q sets %s, set %s=%s to size the deployment with your own values", ResourcePresetVariable, presetName, strings.Join(sizing, ", "), ResourcePresetVariable, CustomResourcePreset)
+	}
+	log.Debugf("using the %s resource preset since %s are set explicitly", CustomResourcePreset, strings.Join(sizing, ", "))
+	customInputs[ResourcePresetVariable] = CustomResourcePreset
+	return nil
+}
+
+// ApplyResourcePreset sets the sizing variables from the preset named by RESOURCEPRESET in customInputs.
+// With the custom preset, or when no preset is set, the sizing variables are left as provided and only validated.
+func ApplyResourcePreset(customInputs map[string]string) error {
+	presetName := strings.ToLower(customInputs[ResourcePresetVariable])
+	if presetName != "" && presetName != CustomResourcePreset {
+		preset, ok := ResourcePresets[presetName]
+		if !ok {
+			return fmt.Errorf("invalid %s %q, must be one of: %s", ResourcePresetVariable, presetName, strings.Join(ResourcePresetNames(), ", "))
+		}
+		for k, v := range preset.variables() {
+			log.Debugf("setting %s to %s from resource preset %s", k, v, presetName)
+			customInputs[k] = v
+		}
+	}
+
+	return validateResourceVariables(customInputs)
+}
+
+// resourceQuantityVariables are the container resource requests and limits, which are Kubernetes quantities
+var resourceQuantityVariables = []string{"CPUREQUEST", "CPULIMIT", "MEMORYREQUEST", "MEMORYLIMIT"}
+
+func validateResourceVariables(customInputs map[string]string) error {
+	if replicas, ok := customInputs["REPLICAS"]; ok && replicas != "" {
+		if err := validateReplicas(replicas); err != nil {
+			return err
+		}
+	}
+	for _, name := range resourceQuantityVariables {
+		value, ok := customInputs[name]
+		if !ok || value == "" {
+			continue
+		}
+		if err := validateQuantity(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateReplicas(replicas string) error {
+	if n, err := strconv.Atoi(replicas); err != nil || n < 0 {
+		return fmt.Errorf("invalid REPLICAS %q, must be a non-negative integer", replicas)
+	}
+	return nil
+}
+
+func validateQuantity(name, value string) error {
+	if _, err := resource.ParseQuantity(value); err != nil {
+		return fmt.Errorf("invalid %s %q: %w", name, value, err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package deployments
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+func TestApplyResourcePreset(t *testing.T) {
+	inputs := map[string]string{ResourcePresetVariable: "Medium", "CPULIMIT": "250m"}
+	assert.Nil(t, ApplyResourcePreset(inputs))
+	assert.Equal(t, "2", inputs["REPLICAS"])
+	assert.Equal(t, "500m", inputs["CPULIMIT"])
+	assert.Equal(t, "512Mi", inputs["MEMORYLIMIT"])
+
+	custom := map[string]string{ResourcePresetVariable: CustomResourcePreset, "REPLICAS": "5", "CPULIMIT": "2"}
+	assert.Nil(t, ApplyResourcePreset(custom))
+	assert.Equal(t, "5", custom["REPLICAS"])
+	assert.Equal(t, "2", custom["CPULIMIT"])
+
+	assert.Nil(t, ApplyResourcePreset(map[string]string{}))
+	assert.NotNil(t, ApplyResourcePreset(map[string]string{ResourcePresetVariable: "huge"}))
+	assert.NotNil(t, ApplyResourcePreset(map[string]string{ResourcePresetVariable: CustomResourcePreset, "REPLICAS": "two"}))
+	assert.NotNil(t, ApplyResourcePreset(map[string]string{ResourcePresetVariable: CustomResourcePreset, "MEMORYLIMIT": "lots"}))
+}
+
+func TestResolveResourcePreset(t *testing.T) {
+	inputs := map[string]string{ResourcePresetVariable: "small", "CPULIMIT": "4", "REPLICAS": "1"}
+	assert.Nil(t, ResolveResourcePreset(inputs, map[string]string{"CPULIMIT": "4"}))
+	assert.Equal(t, CustomResourcePreset, inputs[ResourcePresetVariable])
+	assert.Nil(t, ApplyResourcePreset(inputs))
+	assert.Equal(t, "4", inputs["CPULIMIT"], "the explicit value should win over the preset")
+
+	untouched := map[string]string{ResourcePresetVariable: "large"}
+	assert.Nil(t, ResolveResourcePreset(untouched, map[string]string{ResourcePresetVariable: "large", "APPNAME": "app"}))
+	assert.Equal(t, "large", untouched[ResourcePresetVariable])
+
+	assert.Nil(t, ResolveResourcePreset(map[string]string{}, map[string]string{ResourcePresetVariable: "Custom", "REPLICAS": "3"}))
+	err := ResolveResourcePreset(map[string]string{}, map[string]string{ResourcePresetVariable: "medium", "MEMORYLIMIT": "2Gi", "CPULIMIT": "4"})
+	assert.EqualError(t, err, `RESOURCEPRESET "medium" sets CPULIMIT, MEMORYLIMIT, set RESOURCEPRESET=custom to size the deployment with your own values`)
+}
+
+func TestResourcePresetNames(t *testing.T) {
+	assert.Equal(t, []string{"large", "medium", "small", "custom"}, ResourcePresetNames())
+}
+
+func TestCopyDeploymentFilesWithResourcePreset(t *testing.T) {
+	for _, deployType := range []string{"helm", "kustomize", "manifests"} {
+		w := &writers.FileMapWriter{}
+		d := CreateDeploymentsFromEmbedFS(template.Deployments, "/dest")
+		err := d.CopyDeploymentFiles(deployType, map[string]string{
+			"APPNAME":              "testapp",
+			ResourcePresetVariable: "large",
+		}, w)
+		assert.Nil(t, err, deployType)
+
+		found := false
+		for _, content := range w.FileMap {
+			if strings.Contains(string(content), "512Mi") && strings.Contains(string(content), "1Gi") {
+				found = true
+			}
+		}
+		assert.True(t, found, "%s should render the large preset resources", deployType)
+	}
+}
@@ -0,0 +1,42 @@
+package deployments
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VariableValidators returns the checks of the deployment variables whose values can be validated on their own, keyed
+// by variable name, so prompts can reject an invalid answer as soon as it's entered with
+// prompts.SetVariableValidators rather than when the templates are rendered
+func VariableValidators() map[string]func(string) error {
+	validators := map[string]func(string) error{
+		"REPLICAS":             validateReplicas,
+		"GATEWAYHOSTNAME":      hostnameValidator("GATEWAYHOSTNAME"),
+		"GATEWAYPATH":          pathValidator("GATEWAYPATH"),
+		IngressHostVariable:    hostnameValidator(IngressHostVariable),
+		IngressPathVariable:    pathValidator(IngressPathVariable),
+		SidecarsVariable:       validateContainerList(SidecarsVariable),
+		InitContainersVariable: validateContainerList(InitContainersVariable),
+	}
+	for _, name := range resourceQuantityVariables {
+		name := name
+		validators[name] = func(value string) error { return validateQuantity(name, value) }
+	}
+	return validators
+}
+
+func hostnameValidator(name string) func(string) error {
+	return func(hostname string) error { return validateHostname(name, hostname) }
+}
+
+func pathValidator(name string) func(string) error {
+	return func(path string) error { return validatePath(name, path) }
+}
+
+// validatePath checks the URL path of the variable name is absolute
+func validatePath(name, path string) error {
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("invalid %s %q, must start with /", name, path)
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package deployments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVariableValidators(t *testing.T) {
+	validators := VariableValidators()
+
+	assert.Nil(t, validators["GATEWAYHOSTNAME"]("*.example.com"))
+	assert.ErrorContains(t, validators[IngressHostVariable]("my_host"), `invalid INGRESSHOST "my_host"`)
+	assert.Nil(t, validators[IngressPathVariable]("/api"))
+	assert.EqualError(t, validators["GATEWAYPATH"]("api"), `invalid GATEWAYPATH "api", must start with /`)
+	assert.Nil(t, validators["REPLICAS"]("0"))
+	assert.NotNil(t, validators["REPLICAS"]("-1"))
+	assert.Nil(t, validators["MEMORYLIMIT"]("512Mi"))
+	assert.ErrorContains(t, validators["CPUREQUEST"]("half"), `invalid CPUREQUEST "half"`)
+}
@@ -0,0 +1,223 @@
+package deployments
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
+// Paths of the chart's values file and of the schema generated from it, relative to the deployment type's directory
+const (
+	chartValuesPath       = "charts/values.yaml"
+	chartValuesSchemaPath = "charts/values.schema.json"
+)
+
+// jsonSchemaDraft is the JSON Schema version of the values schema, the latest one helm validates values against
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+var placeholderRegex = regexp.MustCompile(`\{\{([A-Z0-9_]+)\}\}`)
+
+// quotedPlaceholderRegex matches the placeholders of values set as strings
+var quotedPlaceholderRegex = regexp.MustCompile(`["']\{\{([A-Z0-9_]+)\}\}["']`)
+
+// placeholderPrefix replaces the braces of the placeholders of the values template so that it parses as yaml
+const placeholderPrefix = "__draft_variable__"
+
+type jsonSchema struct {
+	Schema      string                 `json:"$schema,omitempty"`
+	Title       string                 `json:"title,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Type        interface{}            `json:"type,omitempty"`
+	Properties  map[string]*jsonSchema `json:"properties,omitempty"`
+	Items       *jsonSchema            `json:"items,omitempty"`
+	Minimum     *int                   `json:"minimum,omitempty"`
+	Maximum     *int                   `json:"maximum,omitempty"`
+	Default     interface{}            `json:"default,omitempty"`
+	Examples    []string               `json:"examples,omitempty"`
+}
+
+// writeValuesSchema writes a values.schema.json next to the values.yaml of the chart in srcDir, if it has one, so
+// that helm and editors validate values files against it
+func writeValuesSchema(templates fs.FS, srcDir, dest string, deployConfig *config.DraftConfig, customInputs map[string]string, templateWriter templatewriter.TemplateWriter) error {
+	values, err := fs.ReadFile(templates, path.Join(srcDir, chartValuesPath))
+	if err != nil {
+		// deployment types without a chart, and charts of packs shipping their own schema, have nothing to generate
+		return nil
+	}
+	if _, err := fs.Stat(templates, path.Join(srcDir, chartValuesSchemaPath)); err == nil {
+		return nil
+	}
+
+	// the other values files of the chart, such as production.yaml, may set typed variables as strings
+	var otherValues [][]byte
+	entries, _ := fs.ReadDir(templates, path.Join(srcDir, path.Dir(chartValuesPath)))
+	for _, entry := range entries {
+		if name := entry.Name(); !entry.IsDir() && path.Ext(name) == ".yaml" && name != path.Base(chartValuesPath) && name != "Chart.yaml" {
+			if content, err := fs.ReadFile(templates, path.Join(srcDir, path.Dir(chartValuesPath), name)); err == nil {
+				otherValues = append(otherValues, content)
+			}
+		}
+	}
+
+	schema, err := valuesSchema(values, otherValues, deployConfig, customInputs)
+	if err != nil {
+		return fmt.Errorf("generating the schema of %s: %w", chartValuesPath, err)
+	}
+	return templateWriter.WriteFile(path.Join(dest, chartValuesSchemaPath), schema)
+}
+
+// valuesSchema returns the JSON Schema of the values template of a chart, whose {{VARIABLE}} placeholders take the
+// type, description, bounds and example values of the variables of deployConfig, and their value in customInputs as
+// default. Other values are typed after their value in the template and described by their comment. Variables set as
+// strings by the other values templates of the chart may also be strings.
+func valuesSchema(valuesTemplate []byte, otherValuesTemplates [][]byte, deployConfig *config.DraftConfig, customInputs map[string]string) ([]byte, error) {
+	variables := make(map[string]config.BuilderVar)
+	if deployConfig != nil {
+		for _, variable := range deployConfig.Variables {
+			variables[variable.Name] = variable
+		}
+	}
+	stringVariables := make(map[string]bool)
+	for _, other := range otherValuesTemplates {
+		for _, match := range quotedPlaceholderRegex.FindAllSubmatch(other, -1) {
+			stringVariables[string(match[1])] = true
+		}
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(placeholderRegex.ReplaceAll(valuesTemplate, []byte(placeholderPrefix+"$1")), &doc); err != nil {
+		return nil, err
+	}
+	schema := &jsonSchema{Type: "object"}
+	if len(doc.Content) > 0 {
+		schema = nodeSchema(doc.Content[0], variables, stringVariables, customInputs)
+	}
+	schema.Schema = jsonSchemaDraft
+	if appName := customInputs["APPNAME"]; appName != "" {
+		schema.Title = fmt.Sprintf("Values of the %s chart", appName)
+	}
+
+	out, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+func nodeSchema(node *yaml.Node, variables map[string]config.BuilderVar, stringVariables map[string]bool, customInputs map[string]string) *jsonSchema {
+	switch node.Kind {
+	case yaml.MappingNode:
+		schema := &jsonSchema{Type: "object", Properties: make(map[string]*jsonSchema)}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			property := nodeSchema(value, variables, stringVariables, customInputs)
+			if property.Description == "" {
+				property.Description = comment(key)
+			}
+			schema.Properties[key.Value] = property
+		}
+		return schema
+	case yaml.SequenceNode:
+		schema := &jsonSchema{Type: "array"}
+		if len(node.Content) > 0 {
+			schema.Items = nodeSchema(node.Content[0], variables, stringVariables, customInputs)
+			schema.Items.Default = nil
+		}
+		return schema
+	case yaml.ScalarNode:
+		if name, ok := strings.CutPrefix(node.Value, placeholderPrefix); ok {
+			schema := variableSchema(name, node.Style != 0, variables, customInputs)
+			if stringVariables[name] && schema.Type != nil && schema.Type != "string" {
+				schema.Type = []string{schema.Type.(string), "string"}
+			}
+			return schema
+		}
+		return scalarSchema(node)
+	}
+	return &jsonSchema{}
+}
+
+// variableSchema returns the schema of a value set to the variable name, a string when the placeholder is quoted
+func variableSchema(name string, quoted bool, variables map[string]config.BuilderVar, customInputs map[string]string) *jsonSchema {
+	variable := variables[name]
+	schema := &jsonSchema{Description: strings.TrimSpace(variable.Description), Examples: variable.ExampleValues}
+	value, hasValue := customInputs[name]
+	switch {
+	case quoted:
+		schema.Type = "string"
+		if hasValue {
+			schema.Default = value
+		}
+	case variable.IsNumeric():
+		schema.Type = "integer"
+		schema.Minimum, schema.Maximum = variable.Range()
+		if n, err := strconv.Atoi(value); err == nil {
+			schema.Default = n
+		}
+	case variable.VarType == "float":
+		schema.Type = "number"
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			schema.Default = f
+		}
+	case variable.VarType == "bool":
+		schema.Type = "boolean"
+		if b, err := strconv.ParseBool(value); err == nil {
+			schema.Default = b
+		}
+	default:
+		// untyped values such as image tags and resource quantities may read as strings or numbers, so their type
+		// isn't constrained
+		if hasValue {
+			var decoded interface{}
+			if err := yaml.Unmarshal([]byte(value), &decoded); err == nil && decoded != nil {
+				schema.Default = decoded
+			}
+		}
+	}
+	return schema
+}
+
+// scalarSchema returns the schema of a literal value of the template, typed after its yaml tag
+func scalarSchema(node *yaml.Node) *jsonSchema {
+	schema := &jsonSchema{}
+	var value interface{}
+	if err := node.Decode(&value); err != nil || value == nil {
+		return schema
+	}
+	switch node.ShortTag() {
+	case "!!str":
+		schema.Type = "string"
+	case "!!int":
+		schema.Type = "integer"
+	case "!!float":
+		schema.Type = "number"
+	case "!!bool":
+		schema.Type = "boolean"
+	}
+	schema.Default = value
+	return schema
+}
+
+// comment returns the comment above or next to the key, without the comment markers
+func comment(key *yaml.Node) string {
+	text := key.HeadComment
+	if text == "" {
+		text = key.LineComment
+	}
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "#")); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, " ")
+}
@@ -0,0 +1,88 @@
+package deployments
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chartutil"
+
+	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+func TestValuesSchema(t *testing.T) {
+	minReplicas := 1
+	deployConfig := &config.DraftConfig{Variables: []config.BuilderVar{
+		{Name: "REPLICAS", Description: "the number of replicas", VarType: "int", Min: &minReplicas},
+		{Name: "PORT", Description: "the port", VarType: "port"},
+		{Name: "ENABLED", Description: "whether it is enabled", VarType: "bool"},
+		{Name: "TAG", Description: "the image tag", ExampleValues: []string{"latest"}},
+	}}
+	valuesTemplate := []byte(`# the number of replicas of the app
+replicaCount: {{REPLICAS}}
+image:
+  tag: {{TAG}}
+  pullPolicy: Always
+service:
+  port: {{PORT}}
+  annotations: {}
+feature:
+  enabled: {{ENABLED}}
+  label: "{{ENABLED}}"
+hosts:
+  - "{{TAG}}"
+`)
+
+	out, err := valuesSchema(valuesTemplate, [][]byte{[]byte("service:\n  port: \"{{PORT}}\"\n")}, deployConfig, map[string]string{
+		"APPNAME": "app", "REPLICAS": "2", "PORT": "8080", "ENABLED": "true", "TAG": "1.0",
+	})
+	assert.Nil(t, err)
+
+	var schema map[string]interface{}
+	assert.Nil(t, json.Unmarshal(out, &schema))
+	assert.Equal(t, "http://json-schema.org/draft-07/schema#", schema["$schema"])
+	assert.Equal(t, "Values of the app chart", schema["title"])
+	properties := schema["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"description": "the number of replicas", "type": "integer", "minimum": 1.0, "default": 2.0}, properties["replicaCount"])
+
+	image := properties["image"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"description": "the image tag", "default": 1.0, "examples": []interface{}{"latest"}}, image["tag"])
+	assert.Equal(t, map[string]interface{}{"type": "string", "default": "Always"}, image["pullPolicy"])
+
+	service := properties["service"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"integer", "string"}, service["port"].(map[string]interface{})["type"])
+	assert.Equal(t, 65535.0, service["port"].(map[string]interface{})["maximum"])
+	assert.Equal(t, map[string]interface{}{"type": "object"}, service["annotations"])
+
+	feature := properties["feature"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, "boolean", feature["enabled"].(map[string]interface{})["type"])
+	assert.Equal(t, map[string]interface{}{"description": "whether it is enabled", "type": "string", "default": "true"}, feature["label"])
+	assert.Equal(t, "string", properties["hosts"].(map[string]interface{})["items"].(map[string]interface{})["type"])
+}
+
+func TestCopyDeploymentFilesValuesSchema(t *testing.T) {
+	d := CreateDeploymentsFromEmbedFS(template.Deployments, "out")
+	inputs := func() map[string]string {
+		return map[string]string{"APPNAME": "testapp", "PORT": "8080", "SERVICEPORT": "80", "NAMESPACE": "default", "IMAGENAME": "testapp", "IMAGETAG": "1.2"}
+	}
+
+	w := &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("helm", inputs(), w))
+	schema := w.FileMap["out/charts/values.schema.json"]
+	assert.NotEmpty(t, schema)
+	// the chart's own values files validate against the schema, as helm checks when installing the chart
+	for _, valuesFile := range []string{"out/charts/values.yaml", "out/charts/production.yaml"} {
+		values, err := chartutil.ReadValues(w.FileMap[valuesFile])
+		assert.Nil(t, err)
+		assert.Nil(t, chartutil.ValidateAgainstSingleSchema(values, schema), valuesFile)
+	}
+	invalid, err := chartutil.ReadValues([]byte("replicaCount: 0\npersistence:\n  enabled: \"yes\"\n"))
+	assert.Nil(t, err)
+	assert.NotNil(t, chartutil.ValidateAgainstSingleSchema(invalid, schema))
+
+	w = &writers.FileMapWriter{}
+	assert.Nil(t, d.CopyDeploymentFiles("manifests", inputs(), w))
+	assert.NotContains(t, w.FileMap, "out/charts/values.schema.json")
+}
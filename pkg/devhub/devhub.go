@@ -0,0 +1,267 @@
+// Package devhub is the supported API of the AKS DevHub integration in the Azure portal. It exposes draft create and
+// generate-workflow as the request and response types DevHub exchanges, rendering files in memory without prompting.
+// The json of these types is a contract: fields may be added but not renamed or removed, which the contract tests in
+// this package check.
+package devhub
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/deployments"
+	"github.com/Azure/draft/pkg/languages"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/pkg/workflows"
+	"github.com/Azure/draft/template"
+)
+
+// APIVersion is the version of the contract implemented by this package
+const APIVersion = "2024-06-01"
+
+// Variable describes a template variable for DevHub to render an input for
+type Variable struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Type is the variable type of draft.yaml, such as string, int, bool, secret or multiselect
+	Type string `json:"type"`
+	// Default is the default value, empty when the variable has none or defaults to ReferenceVariable
+	Default string `json:"default,omitempty"`
+	// ReferenceVariable is the variable whose value is the default of this one
+	ReferenceVariable string   `json:"referenceVariable,omitempty"`
+	ExampleValues     []string `json:"exampleValues,omitempty"`
+	// AllowedValues are the options of a multiselect variable, whose value joins any number of them with commas
+	AllowedValues []string `json:"allowedValues,omitempty"`
+	// Min and Max are the inclusive bounds of an int or port variable
+	Min *int `json:"min,omitempty"`
+	Max *int `json:"max,omitempty"`
+	// Required variables have no default and must be set in a request
+	Required bool `json:"required"`
+	// Advanced variables have a default that most users keep
+	Advanced bool `json:"advanced"`
+}
+
+// TemplateSchema is the variable schema of a language, deployment type or workflow template
+type TemplateSchema struct {
+	Name        string     `json:"name"`
+	DisplayName string     `json:"displayName,omitempty"`
+	Version     string     `json:"version"`
+	Variables   []Variable `json:"variables"`
+}
+
+// SchemaResponse lists the templates DevHub can generate files from
+type SchemaResponse struct {
+	APIVersion      string           `json:"apiVersion"`
+	Languages       []TemplateSchema `json:"languages"`
+	DeploymentTypes []TemplateSchema `json:"deploymentTypes"`
+	Workflows       []TemplateSchema `json:"workflows"`
+}
+
+// CreateRequest generates a Dockerfile, deployment files or both, like draft create
+type CreateRequest struct {
+	// Language is the Dockerfile template to generate, none when empty
+	Language          string            `json:"language,omitempty"`
+	LanguageVariables map[string]string `json:"languageVariables,omitempty"`
+	// DeploymentType is the deployment template to generate, none when empty
+	DeploymentType      string            `json:"deploymentType,omitempty"`
+	DeploymentVariables map[string]string `json:"deploymentVariables,omitempty"`
+	// Destination is the directory of the generated files relative to the repository root, the root when empty
+	Destination string `json:"destination,omitempty"`
+}
+
+// GenerateWorkflowRequest generates the Github workflow of a deployment type, like draft generate-workflow
+type GenerateWorkflowRequest struct {
+	DeploymentType string            `json:"deploymentType"`
+	Variables      map[string]string `json:"variables,omitempty"`
+	// Destination is the directory of the generated files relative to the repository root, the root when empty
+	Destination string `json:"destination,omitempty"`
+}
+
+// FileMapResponse holds the generated files keyed by their path relative to the repository root
+type FileMapResponse struct {
+	APIVersion string            `json:"apiVersion"`
+	Files      map[string]string `json:"files"`
+}
+
+// MissingVariablesError is returned for requests that leave required variables unset
+type MissingVariablesError struct {
+	Template  string
+	Variables []string
+}
+
+func (e *MissingVariablesError) Error() string {
+	return fmt.Sprintf("missing required variables for %s: %s", e.Template, strings.Join(e.Variables, ", "))
+}
+
+// Schemas returns the variable schemas of the embedded language, deployment and workflow templates
+func Schemas() (*SchemaResponse, error) {
+	l := languages.CreateLanguagesFromEmbedFS(template.Dockerfiles, "")
+	d := deployments.CreateDeploymentsFromEmbedFS(template.Deployments, "")
+	w := workflows.CreateWorkflowsFromEmbedFS(template.Workflows, "")
+
+	resp := &SchemaResponse{APIVersion: APIVersion}
+	for _, lang := range l.Names() {
+		resp.Languages = append(resp.Languages, newTemplateSchema(lang, l.GetConfig(lang)))
+	}
+	for _, deployType := range d.DeployTypes() {
+		deployConfig, err := d.GetConfig(deployType)
+		if err != nil {
+			return nil, err
+		}
+		resp.DeploymentTypes = append(resp.DeploymentTypes, newTemplateSchema(deployType, deployConfig))
+	}
+	for _, deployType := range w.DeployTypes() {
+		workflowConfig, err := w.GetConfig(deployType)
+		if err != nil {
+			return nil, err
+		}
+		resp.Workflows = append(resp.Workflows, newTemplateSchema(deployType, workflowConfig))
+	}
+	return resp, nil
+}
+
+// Create renders the files requested by req
+func Create(req CreateRequest) (*FileMapResponse, error) {
+	if req.Language == "" && req.DeploymentType == "" {
+		return nil, fmt.Errorf("a language, a deployment type or both are required")
+	}
+	w := &writers.FileMapWriter{FileMap: make(map[string][]byte)}
+
+	if req.Language != "" {
+		l := languages.CreateLanguagesFromEmbedFS(template.Dockerfiles, req.Destination)
+		langConfig := l.GetConfig(req.Language)
+		if langConfig == nil {
+			return nil, fmt.Errorf("language %s is not supported", req.Language)
+		}
+		inputs, err := resolveVariables(req.Language, langConfig, req.LanguageVariables)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.CreateDockerfileForLanguage(req.Language, inputs, w); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.DeploymentType != "" {
+		d := deployments.CreateDeploymentsFromEmbedFS(template.Deployments, req.Destination)
+		deployConfig, err := d.GetConfig(req.DeploymentType)
+		if err != nil {
+			return nil, err
+		}
+		inputs, err := resolveVariables(req.DeploymentType, deployConfig, req.DeploymentVariables)
+		if err != nil {
+			return nil, err
+		}
+		if err := deployments.ResolveResourcePreset(inputs, req.DeploymentVariables); err != nil {
+			return nil, err
+		}
+		if err := d.CopyDeploymentFiles(req.DeploymentType, inputs, w); err != nil {
+			return nil, err
+		}
+	}
+
+	return newFileMapResponse(w.FileMap), nil
+}
+
+// GenerateWorkflow renders the workflow files requested by req. Unlike draft generate-workflow it leaves the
+// production deployment files alone, so DevHub sets their image in the deployment variables of its CreateRequest.
+func GenerateWorkflow(req GenerateWorkflowRequest) (*FileMapResponse, error) {
+	w := workflows.CreateWorkflowsFromEmbedFS(template.Workflows, "")
+	workflowConfig, err := w.GetConfig(req.DeploymentType)
+	if err != nil {
+		return nil, err
+	}
+	inputs, err := resolveVariables(req.DeploymentType+" workflow", workflowConfig, req.Variables)
+	if err != nil {
+		return nil, err
+	}
+	files, err := w.RenderWorkflowFiles(req.DeploymentType, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	destFiles := make(map[string][]byte, len(files))
+	for filePath, content := range files {
+		destFiles[path.Join(req.Destination, filePath)] = content
+	}
+	return newFileMapResponse(destFiles), nil
+}
+
+// resolveVariables returns the values of provided with the defaults of draftConfig filled in, or a
+// MissingVariablesError naming the variables that have neither. Defaults referencing another variable are filled in
+// last so they pick up its final value.
+func resolveVariables(templateName string, draftConfig *config.DraftConfig, provided map[string]string) (map[string]string, error) {
+	inputs := make(map[string]string, len(provided))
+	for name, value := range provided {
+		inputs[name] = value
+	}
+
+	defaults := make(map[string]config.BuilderVarDefault, len(draftConfig.VariableDefaults))
+	for _, variableDefault := range draftConfig.VariableDefaults {
+		defaults[variableDefault.Name] = variableDefault
+	}
+
+	var missing []string
+	for _, variable := range draftConfig.Variables {
+		if _, ok := defaults[variable.Name]; !ok && inputs[variable.Name] == "" {
+			missing = append(missing, variable.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &MissingVariablesError{Template: templateName, Variables: missing}
+	}
+
+	for _, variableDefault := range draftConfig.VariableDefaults {
+		if inputs[variableDefault.Name] == "" && variableDefault.ReferenceVar == "" {
+			inputs[variableDefault.Name] = variableDefault.Value
+		}
+	}
+	for _, variableDefault := range draftConfig.VariableDefaults {
+		if inputs[variableDefault.Name] == "" && variableDefault.ReferenceVar != "" {
+			inputs[variableDefault.Name] = inputs[variableDefault.ReferenceVar]
+			if inputs[variableDefault.Name] == "" {
+				inputs[variableDefault.Name] = variableDefault.Value
+			}
+		}
+	}
+	return inputs, nil
+}
+
+func newTemplateSchema(name string, draftConfig *config.DraftConfig) TemplateSchema {
+	schema := TemplateSchema{Name: name, DisplayName: draftConfig.DisplayName, Version: draftConfig.Version, Variables: []Variable{}}
+	for _, variable := range draftConfig.Variables {
+		v := Variable{
+			Name:          variable.Name,
+			Description:   variable.Description,
+			Type:          variable.VarType,
+			ExampleValues: variable.ExampleValues,
+			AllowedValues: variable.AllowedValues,
+			Required:      true,
+			Advanced:      variable.IsAdvanced(),
+		}
+		if v.Type == "" {
+			v.Type = "string"
+		}
+		if variable.IsNumeric() {
+			v.Min, v.Max = variable.Range()
+		}
+		for _, variableDefault := range draftConfig.VariableDefaults {
+			if variableDefault.Name == variable.Name {
+				v.Default = variableDefault.Value
+				v.ReferenceVariable = variableDefault.ReferenceVar
+				v.Required = false
+			}
+		}
+		schema.Variables = append(schema.Variables, v)
+	}
+	return schema
+}
+
+func newFileMapResponse(files map[string][]byte) *FileMapResponse {
+	resp := &FileMapResponse{APIVersion: APIVersion, Files: make(map[string]string, len(files))}
+	for filePath, content := range files {
+		resp.Files[filePath] = string(content)
+	}
+	return resp
+}
@@ -0,0 +1,166 @@
+package devhub
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// contractExamples populates every field of the contract types, whose json must match testdata/contract.json
+var contractExamples = map[string]interface{}{
+	"variable": Variable{
+		Name:              "VERSION",
+		Description:       "the version of go used by the application",
+		Type:              "string",
+		Default:           "1.18",
+		ReferenceVariable: "APPNAME",
+		ExampleValues:     []string{"1.18", "1.19"},
+		AllowedValues:     []string{"1.18", "1.19", "1.20"},
+		Required:          false,
+		Advanced:          true,
+	},
+	"numericVariable": Variable{
+		Name:        "PORT",
+		Description: "the port exposed in the application",
+		Type:        "port",
+		Min:         intPtr(1),
+		Max:         intPtr(65535),
+		Required:    true,
+	},
+	"schemaResponse": SchemaResponse{
+		APIVersion:      APIVersion,
+		Languages:       []TemplateSchema{{Name: "go", DisplayName: "Go", Version: "1.0.0", Variables: []Variable{}}},
+		DeploymentTypes: []TemplateSchema{},
+		Workflows:       []TemplateSchema{},
+	},
+	"createRequest": CreateRequest{
+		Language:            "go",
+		LanguageVariables:   map[string]string{"PORT": "8080"},
+		DeploymentType:      "manifests",
+		DeploymentVariables: map[string]string{"APPNAME": "testapp"},
+		Destination:         "src",
+	},
+	"generateWorkflowRequest": GenerateWorkflowRequest{
+		DeploymentType: "helm",
+		Variables:      map[string]string{"CLUSTERNAME": "testcluster"},
+		Destination:    "src",
+	},
+	"fileMapResponse": FileMapResponse{
+		APIVersion: APIVersion,
+		Files:      map[string]string{"Dockerfile": "FROM golang:1.18"},
+	},
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestContract(t *testing.T) {
+	want, err := os.ReadFile("testdata/contract.json")
+	assert.Nil(t, err)
+	got, err := json.MarshalIndent(contractExamples, "", "  ")
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(want), string(got), "the json of the devhub types changed, fields may only be added")
+}
+
+func TestSchemas(t *testing.T) {
+	resp, err := Schemas()
+	assert.Nil(t, err)
+	assert.Equal(t, APIVersion, resp.APIVersion)
+
+	names := func(schemas []TemplateSchema) []string {
+		var n []string
+		for _, s := range schemas {
+			n = append(n, s.Name)
+		}
+		return n
+	}
+	assert.Contains(t, names(resp.Languages), "go")
+	assert.Subset(t, names(resp.DeploymentTypes), []string{"helm", "kustomize", "manifests"})
+	assert.Contains(t, names(resp.Workflows), "helm")
+
+	for _, lang := range resp.Languages {
+		if lang.Name != "go" {
+			continue
+		}
+		assert.Equal(t, "Go", lang.DisplayName)
+		for _, v := range lang.Variables {
+			assert.False(t, v.Required, v.Name)
+			if v.Name == "PORT" {
+				assert.Equal(t, "port", v.Type)
+				assert.Equal(t, "80", v.Default)
+				assert.Equal(t, intPtr(1), v.Min)
+				assert.Equal(t, intPtr(65535), v.Max)
+			}
+		}
+	}
+	for _, workflow := range resp.Workflows {
+		for _, v := range workflow.Variables {
+			if v.Name == "CLUSTERNAME" {
+				assert.True(t, v.Required)
+			}
+		}
+	}
+}
+
+func TestCreate(t *testing.T) {
+	resp, err := Create(CreateRequest{
+		Language:          "go",
+		LanguageVariables: map[string]string{"PORT": "8080"},
+		DeploymentType:    "manifests",
+		DeploymentVariables: map[string]string{
+			"PORT":        "8080",
+			"APPNAME":     "testapp",
+			"SERVICEPORT": "80",
+			"NAMESPACE":   "testnamespace",
+			"IMAGENAME":   "testimage",
+		},
+		Destination: "src",
+	})
+	assert.Nil(t, err)
+	assert.Contains(t, resp.Files["src/Dockerfile"], "EXPOSE 8080")
+	assert.Contains(t, resp.Files["src/Dockerfile"], "golang:1.18")
+	assert.Contains(t, resp.Files["src/manifests/deployment.yaml"], "testimage")
+	assert.Contains(t, resp.Files["src/manifests/deployment.yaml"], "namespace: testnamespace")
+
+	_, err = Create(CreateRequest{})
+	assert.NotNil(t, err)
+	_, err = Create(CreateRequest{Language: "cobol"})
+	assert.NotNil(t, err)
+}
+
+func TestCreateMissingVariables(t *testing.T) {
+	_, err := Create(CreateRequest{DeploymentType: "manifests", DeploymentVariables: map[string]string{"NAMESPACE": "testnamespace"}})
+	var missing *MissingVariablesError
+	assert.True(t, errors.As(err, &missing))
+	assert.Equal(t, "manifests", missing.Template)
+	assert.Equal(t, []string{"APPNAME"}, missing.Variables)
+}
+
+func TestGenerateWorkflow(t *testing.T) {
+	resp, err := GenerateWorkflow(GenerateWorkflowRequest{
+		DeploymentType: "manifests",
+		Variables: map[string]string{
+			"AZURECONTAINERREGISTRY": "testacr",
+			"CONTAINERNAME":          "testcontainer",
+			"RESOURCEGROUP":          "testrg",
+			"CLUSTERNAME":            "testcluster",
+			"BRANCHNAME":             "main",
+		},
+	})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, resp.Files)
+	for filePath, content := range resp.Files {
+		assert.Contains(t, filePath, ".github/workflows/")
+		assert.Contains(t, content, "testcluster")
+		assert.NotContains(t, content, "{{CLUSTERNAME}}")
+	}
+
+	_, err = GenerateWorkflow(GenerateWorkflowRequest{DeploymentType: "manifests"})
+	var missing *MissingVariablesError
+	assert.True(t, errors.As(err, &missing))
+	assert.Contains(t, missing.Variables, "CLUSTERNAME")
+}
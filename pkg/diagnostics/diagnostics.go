@@ -0,0 +1,122 @@
+package diagnostics
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LastError is the most recent error a draft command failed with, kept so it can be attached to an issue report
+type LastError struct {
+	Command string    `json:"command"`
+	Error   string    `json:"error"`
+	Version string    `json:"version"`
+	Time    time.Time `json:"time"`
+}
+
+// LastErrorPath returns the file the last error is saved to in the user cache directory
+func LastErrorPath() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "draft", "last-error.json"), nil
+}
+
+// SaveLastError writes lastError to path, sanitized so it holds no home directory paths
+func SaveLastError(path string, lastError LastError) error {
+	lastError.Command = NewSanitizer().Sanitize(lastError.Command)
+	lastError.Error = NewSanitizer().Sanitize(lastError.Error)
+	content, err := json.MarshalIndent(lastError, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0600)
+}
+
+// LoadLastError reads the last error saved to path, returning nil if no command has failed yet
+func LoadLastError(path string) (*LastError, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var lastError LastError
+	if err := json.Unmarshal(content, &lastError); err != nil {
+		return nil, fmt.Errorf("parsing last error %s: %w", path, err)
+	}
+	return &lastError, nil
+}
+
+// Sanitizer replaces local paths, which can hold user and project names, with placeholders
+type Sanitizer struct {
+	replacements map[string]string
+}
+
+// NewSanitizer returns a Sanitizer replacing the home directory with ~
+func NewSanitizer() *Sanitizer {
+	s := &Sanitizer{replacements: make(map[string]string)}
+	if home, err := os.UserHomeDir(); err == nil {
+		s.Replace(home, "~")
+	}
+	return s
+}
+
+// Replace makes the Sanitizer replace old with placeholder
+func (s *Sanitizer) Replace(old, placeholder string) {
+	if old != "" && old != string(filepath.Separator) {
+		s.replacements[old] = placeholder
+	}
+}
+
+// Sanitize replaces every path registered with Replace in text, longest first so nested paths keep their placeholder
+func (s *Sanitizer) Sanitize(text string) string {
+	olds := make([]string, 0, len(s.replacements))
+	for old := range s.replacements {
+		olds = append(olds, old)
+	}
+	sort.Slice(olds, func(i, j int) bool {
+		if len(olds[i]) != len(olds[j]) {
+			return len(olds[i]) > len(olds[j])
+		}
+		return olds[i] < olds[j]
+	})
+
+	for _, old := range olds {
+		text = strings.ReplaceAll(text, old, s.replacements[old])
+	}
+	return text
+}
+
+// WriteBundle writes files, a map of file names to content, to w as a zip archive
+func WriteBundle(w io.Writer, files map[string][]byte) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	zw := zip.NewWriter(w)
+	for _, name := range names {
+		fw, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(files[name]); err != nil {
+			return fmt.Errorf("writing %s to bundle: %w", name, err)
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,60 @@
+package diagnostics
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizer(t *testing.T) {
+	s := &Sanitizer{replacements: make(map[string]string)}
+	s.Replace("/home/jane", "~")
+	s.Replace("/home/jane/src/app", "<project>")
+	s.Replace("/", "<root>")
+
+	assert.Equal(t, "open <project>/Dockerfile: denied by ~/.config", s.Sanitize("open /home/jane/src/app/Dockerfile: denied by /home/jane/.config"))
+	assert.Equal(t, "/etc/hosts", s.Sanitize("/etc/hosts"))
+}
+
+func TestSaveLoadLastError(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(t.TempDir(), "draft", "last-error.json")
+
+	lastError, err := LoadLastError(path)
+	assert.Nil(t, err)
+	assert.Nil(t, lastError)
+
+	failedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	assert.Nil(t, SaveLastError(path, LastError{Command: "draft create", Error: "reading " + home + "/app/draft.yaml: denied", Version: "v1", Time: failedAt}))
+
+	lastError, err = LoadLastError(path)
+	assert.Nil(t, err)
+	assert.Equal(t, &LastError{Command: "draft create", Error: "reading ~/app/draft.yaml: denied", Version: "v1", Time: failedAt}, lastError)
+
+	assert.Nil(t, os.WriteFile(path, []byte("{"), 0600))
+	_, err = LoadLastError(path)
+	assert.NotNil(t, err)
+}
+
+func TestWriteBundle(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Nil(t, WriteBundle(&buf, map[string][]byte{"summary.md": []byte("# summary"), "report.json": []byte("{}")}))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(t, err)
+	assert.Len(t, zr.File, 2)
+	assert.Equal(t, "report.json", zr.File[0].Name)
+
+	f, err := zr.File[1].Open()
+	assert.Nil(t, err)
+	content, err := io.ReadAll(f)
+	assert.Nil(t, err)
+	assert.Equal(t, "# summary", string(content))
+}
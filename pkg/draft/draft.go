@@ -0,0 +1,256 @@
+// Package draft generates a Dockerfile and deployment files like draft create, for Go programs embedding draft
+// instead of running the cli. Nothing is prompted for: the variables take the given values or their defaults, and a
+// prompts.MissingVariablesError lists those that have neither.
+package draft
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/deployments"
+	"github.com/Azure/draft/pkg/languages"
+	"github.com/Azure/draft/pkg/languages/defaults"
+	"github.com/Azure/draft/pkg/osutil"
+	"github.com/Azure/draft/pkg/packs"
+	"github.com/Azure/draft/pkg/prompts"
+	"github.com/Azure/draft/pkg/reporeader"
+	"github.com/Azure/draft/pkg/templatewriter"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+// Variables the deployment takes from draft rather than from its template's defaults
+const (
+	// DraftVersionVariable is set to the version of draft for the draft.sh/template-version annotation
+	DraftVersionVariable = "DRAFTVERSION"
+	// WebConcurrencyVariable is set from the WORKERS of the Dockerfile so the deployment runs the same number of workers
+	WebConcurrencyVariable = "WEBCONCURRENCY"
+)
+
+// DockerfileOptions configures the generation of a Dockerfile
+type DockerfileOptions struct {
+	// Language is the language pack to generate the Dockerfile from, such as go or python
+	Language string
+	// Dest is the directory the Dockerfile is written to
+	Dest string
+	// Variables are the values of the language pack's variables
+	Variables map[string]string
+	// Templates holds more language packs, laid out like draft's template directory, which replace the embedded packs
+	// of the same name
+	Templates fs.FS
+	// TemplateVersion pins the version of the language pack, the latest when empty
+	TemplateVersion string
+	// RepoReader reads the defaults of the variables from the repo's files, as draft create does, when not nil
+	RepoReader reporeader.RepoReader
+	// TemplateWriter writes the Dockerfile, a writers.LocalFSWriter when nil
+	TemplateWriter templatewriter.TemplateWriter
+}
+
+// DeploymentOptions configures the generation of deployment files
+type DeploymentOptions struct {
+	// DeployType is the deployment pack to generate the files from, such as helm, kustomize or manifests
+	DeployType string
+	// Dest is the directory the deployment files are written to
+	Dest string
+	// Variables are the values of the deployment pack's variables
+	Variables map[string]string
+	// DockerfileVariables are the variables the Dockerfile was generated with, which some deployment defaults follow
+	DockerfileVariables map[string]string
+	// Templates holds more deployment packs, laid out like draft's template directory, which replace the embedded
+	// packs of the same name
+	Templates fs.FS
+	// TemplateVersion pins the version of the deployment pack, the latest when empty
+	TemplateVersion string
+	// DraftVersion is recorded in the draft.sh/template-version annotation, unknown when empty
+	DraftVersion string
+	// TemplateWriter writes the deployment files, a writers.LocalFSWriter when nil
+	TemplateWriter templatewriter.TemplateWriter
+}
+
+// Result is what a generation used
+type Result struct {
+	// Variables are the values the templates were rendered with, including the defaults
+	Variables map[string]string
+	// TemplateVersion is the version of the pack the files were generated from
+	TemplateVersion string
+}
+
+// GenerateDockerfile generates the Dockerfile and .dockerignore of opts.Language
+func GenerateDockerfile(ctx context.Context, opts DockerfileOptions) (Result, error) {
+	l := languages.CreateLanguagesFromEmbedFS(template.Dockerfiles, opts.Dest)
+	if opts.Templates != nil {
+		if _, err := fs.Stat(opts.Templates, packs.DockerfilesDir); err == nil {
+			customLangs, err := languages.CreateLanguagesFromFS(opts.Templates, opts.Dest)
+			if err != nil {
+				return Result{}, fmt.Errorf("loading language packs: %w", err)
+			}
+			l.AddPacks(customLangs)
+		}
+	}
+
+	lang := strings.ToLower(opts.Language)
+	if !l.ContainsLanguage(lang) {
+		return Result{}, fmt.Errorf("language %s is not supported", opts.Language)
+	}
+	if opts.TemplateVersion != "" {
+		if err := l.UseVersion(lang, opts.TemplateVersion); err != nil {
+			return Result{}, err
+		}
+	}
+	langConfig := l.GetConfig(lang)
+
+	if opts.RepoReader != nil {
+		extractedValues, err := l.ExtractDefaults(lang, opts.RepoReader)
+		if err != nil {
+			return Result{}, err
+		}
+		ApplyExtractedDefaults(langConfig, extractedValues)
+	}
+
+	inputs, err := resolveVariables(langConfig, opts.Variables)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	if err := l.CreateDockerfileForLanguage(lang, inputs, templateWriterOrDefault(opts.TemplateWriter)); err != nil {
+		return Result{}, fmt.Errorf("there was an error when creating the Dockerfile for language %s: %w", lang, err)
+	}
+	return Result{Variables: inputs, TemplateVersion: langConfig.Version}, nil
+}
+
+// GenerateDeployment generates the deployment files of opts.DeployType
+func GenerateDeployment(ctx context.Context, opts DeploymentOptions) (Result, error) {
+	d := deployments.CreateDeploymentsFromEmbedFS(template.Deployments, opts.Dest)
+	if opts.Templates != nil {
+		if _, err := fs.Stat(opts.Templates, packs.DeploymentsDir); err == nil {
+			customDeployments, err := deployments.CreateDeploymentsFromFS(opts.Templates, opts.Dest)
+			if err != nil {
+				return Result{}, fmt.Errorf("loading deployment packs: %w", err)
+			}
+			d.AddPacks(customDeployments)
+		}
+	}
+
+	deployType := strings.ToLower(opts.DeployType)
+	if opts.TemplateVersion != "" {
+		if err := d.UseVersion(deployType, opts.TemplateVersion); err != nil {
+			return Result{}, err
+		}
+	}
+	deployConfig, err := d.GetConfig(deployType)
+	if err != nil {
+		return Result{}, err
+	}
+	ApplyDockerfileDefaults(deployConfig, opts.DockerfileVariables)
+
+	inputs, err := resolveVariables(deployConfig, opts.Variables)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := deployments.ResolveResourcePreset(inputs, opts.Variables); err != nil {
+		return Result{}, err
+	}
+	if opts.DraftVersion != "" {
+		inputs[DraftVersionVariable] = opts.DraftVersion
+	}
+	values := DeploymentValues(opts.DockerfileVariables)
+	for name, value := range values {
+		if _, ok := opts.Variables[name]; !ok {
+			inputs[name] = value
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	if err := d.CopyDeploymentFiles(deployType, inputs, templateWriterOrDefault(opts.TemplateWriter)); err != nil {
+		return Result{}, err
+	}
+	return Result{Variables: inputs, TemplateVersion: deployConfig.Version}, nil
+}
+
+// ApplyExtractedDefaults sets the defaults read from a repo's files by the language pack's extractors on langConfig,
+// in sorted order so new defaults are appended the same way on every run
+func ApplyExtractedDefaults(langConfig *config.DraftConfig, extractedValues map[string]string) {
+	for _, k := range osutil.SortedKeys(extractedValues) {
+		v := extractedValues[k]
+		variableExists := false
+		for i, varD := range langConfig.VariableDefaults {
+			if k == varD.Name {
+				variableExists = true
+				langConfig.VariableDefaults[i].Value = v
+				break
+			}
+		}
+		if !variableExists {
+			langConfig.VariableDefaults = append(langConfig.VariableDefaults, config.BuilderVarDefault{
+				Name:  k,
+				Value: v,
+			})
+		}
+	}
+}
+
+// ApplyDockerfileDefaults defaults the deployment's variables to follow the Dockerfile generated with
+// dockerfileVariables: the application, and so image, name to the go module the Dockerfile builds when it isn't the
+// repo root module, and whether the deployment runs hardened to whether the Dockerfile builds a hardened image, since
+// the pod of an image running as root fails to start with runAsNonRoot
+func ApplyDockerfileDefaults(deployConfig *config.DraftConfig, dockerfileVariables map[string]string) {
+	if modulePath := dockerfileVariables[defaults.GoModulePathVariable]; modulePath != "" && modulePath != "." {
+		deployConfig.SetVariableDefault("APPNAME", strings.ToLower(path.Base(modulePath)))
+	}
+	if hardened := dockerfileVariables[languages.HardenedVariable]; hardened != "" {
+		deployConfig.SetVariableDefault(deployments.HardenedVariable, hardened)
+	}
+}
+
+// DeploymentValues returns the values of the deployment's variables that the Dockerfile generated with
+// dockerfileVariables sets, rather than their defaults
+func DeploymentValues(dockerfileVariables map[string]string) map[string]string {
+	values := make(map[string]string)
+	if workers := dockerfileVariables[languages.WorkersVariable]; workers != "" {
+		values[WebConcurrencyVariable] = workers
+	}
+	return values
+}
+
+// resolveVariables returns provided with the defaults of draftConfig filled in, or a prompts.MissingVariablesError
+// listing the variables that have neither, as the prompts do in non-interactive mode
+func resolveVariables(draftConfig *config.DraftConfig, provided map[string]string) (map[string]string, error) {
+	inputs := maps.Clone(provided)
+	if inputs == nil {
+		inputs = make(map[string]string)
+	}
+
+	missing := &prompts.MissingVariablesError{}
+	for _, variable := range draftConfig.Variables {
+		if _, ok := inputs[variable.Name]; ok {
+			continue
+		}
+		if !prompts.HasVariableDefault(variable.Name, draftConfig.VariableDefaults) {
+			missing.Variables = append(missing.Variables, variable)
+			continue
+		}
+		inputs[variable.Name] = prompts.GetVariableDefaultValue(variable.Name, draftConfig.VariableDefaults, inputs)
+	}
+	if len(missing.Variables) > 0 {
+		return nil, missing
+	}
+	return inputs, nil
+}
+
+func templateWriterOrDefault(templateWriter templatewriter.TemplateWriter) templatewriter.TemplateWriter {
+	if templateWriter == nil {
+		return &writers.LocalFSWriter{}
+	}
+	return templateWriter
+}
@@ -0,0 +1,100 @@
+package draft
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/prompts"
+	"github.com/Azure/draft/pkg/reporeader"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+func TestGenerateDockerfile(t *testing.T) {
+	w := &writers.FileMapWriter{}
+	result, err := GenerateDockerfile(context.Background(), DockerfileOptions{
+		Language:       "gomodule",
+		Dest:           "out",
+		Variables:      map[string]string{"PORT": "8080"},
+		RepoReader:     reporeader.FakeRepoReader{Files: map[string][]byte{"api/go.mod": []byte("module app\n")}},
+		TemplateWriter: w,
+	})
+	assert.Nil(t, err)
+	assert.Contains(t, string(w.FileMap["out/Dockerfile"]), "EXPOSE 8080")
+	assert.Equal(t, "api", result.Variables["MODULEPATH"], "the defaults are read from the repo")
+	assert.Equal(t, "true", result.Variables["HARDENED"])
+	assert.NotEmpty(t, result.TemplateVersion)
+
+	_, err = GenerateDockerfile(context.Background(), DockerfileOptions{Language: "cobol", TemplateWriter: w})
+	assert.ErrorContains(t, err, "language cobol is not supported")
+
+	templates := fstest.MapFS{
+		"dockerfiles/cobol/draft.yaml": {Data: []byte("variables:\n  - name: \"PORT\"\n    description: \"the port\"\n")},
+		"dockerfiles/cobol/Dockerfile": {Data: []byte("EXPOSE {{PORT}}\n")},
+	}
+	_, err = GenerateDockerfile(context.Background(), DockerfileOptions{Language: "cobol", Dest: "out", Templates: templates, TemplateWriter: w})
+	var missing *prompts.MissingVariablesError
+	assert.True(t, errors.As(err, &missing))
+	assert.Equal(t, "PORT", missing.Variables[0].Name)
+
+	_, err = GenerateDockerfile(context.Background(), DockerfileOptions{Language: "cobol", Dest: "out", Variables: map[string]string{"PORT": "80"}, Templates: templates, TemplateWriter: w})
+	assert.Nil(t, err)
+	assert.Equal(t, "EXPOSE 80\n", string(w.FileMap["out/Dockerfile"]))
+}
+
+func TestGenerateDeployment(t *testing.T) {
+	w := &writers.FileMapWriter{}
+	variables := map[string]string{"APPNAME": "app", "NAMESPACE": "default", "IMAGENAME": "app", "SERVICEPORT": "80", "PORT": "80"}
+	result, err := GenerateDeployment(context.Background(), DeploymentOptions{
+		DeployType:          "manifests",
+		Dest:                "out",
+		Variables:           variables,
+		DockerfileVariables: map[string]string{"HARDENED": "false", "WORKERS": "3"},
+		DraftVersion:        "v1.0.0",
+		TemplateWriter:      w,
+	})
+	assert.Nil(t, err)
+	assert.Contains(t, w.FileMap, "out/manifests/deployment.yaml")
+	assert.Equal(t, "false", result.Variables["HARDENED"], "the deployment follows the Dockerfile")
+	assert.Equal(t, "3", result.Variables[WebConcurrencyVariable])
+	assert.Equal(t, "v1.0.0", result.Variables[DraftVersionVariable])
+	assert.Len(t, variables, 5, "the given variables are not changed")
+
+	_, err = GenerateDeployment(context.Background(), DeploymentOptions{DeployType: "manifests", TemplateWriter: w})
+	var missing *prompts.MissingVariablesError
+	assert.True(t, errors.As(err, &missing))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = GenerateDeployment(ctx, DeploymentOptions{DeployType: "manifests", Variables: variables, TemplateWriter: &writers.FileMapWriter{}})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestApplyDockerfileDefaults(t *testing.T) {
+	deployConfig := &config.DraftConfig{Variables: []config.BuilderVar{{Name: "APPNAME"}}}
+	ApplyDockerfileDefaults(deployConfig, map[string]string{"MODULEPATH": "."})
+	assert.Empty(t, deployConfig.VariableDefaults)
+
+	ApplyDockerfileDefaults(deployConfig, map[string]string{"MODULEPATH": "services/Orders"})
+	assert.Equal(t, []config.BuilderVarDefault{{Name: "APPNAME", Value: "orders"}}, deployConfig.VariableDefaults)
+
+	deployConfig = &config.DraftConfig{
+		Variables:        []config.BuilderVar{{Name: "HARDENED"}},
+		VariableDefaults: []config.BuilderVarDefault{{Name: "HARDENED", Value: "true"}},
+	}
+	ApplyDockerfileDefaults(deployConfig, map[string]string{})
+	assert.Equal(t, "true", deployConfig.VariableDefaults[0].Value)
+
+	ApplyDockerfileDefaults(deployConfig, map[string]string{"HARDENED": "false"})
+	assert.Equal(t, "false", deployConfig.VariableDefaults[0].Value)
+}
+
+func TestApplyExtractedDefaults(t *testing.T) {
+	langConfig := &config.DraftConfig{VariableDefaults: []config.BuilderVarDefault{{Name: "VERSION", Value: "1.20"}}}
+	ApplyExtractedDefaults(langConfig, map[string]string{"VERSION": "1.21", "PORT": "8080", "MODULEPATH": "."})
+	assert.Equal(t, []config.BuilderVarDefault{{Name: "VERSION", Value: "1.21"}, {Name: "MODULEPATH", Value: "."}, {Name: "PORT", Value: "8080"}}, langConfig.VariableDefaults)
+}
@@ -3,6 +3,13 @@ package dryrun
 type DryRunInfo struct {
 	Variables    map[string]string `json:"variables"`
 	FilesToWrite []string          `json:"filesToWrite"`
+	// MultiSelectVariables holds the selected values of the multiselect variables, which Variables holds joined
+	MultiSelectVariables map[string][]string `json:"multiSelectVariables,omitempty"`
+	// TemplateVersions holds the version of the template each artifact was generated from, keyed by artifact
+	TemplateVersions map[string]string `json:"templateVersions,omitempty"`
+	// FileHashes holds the hash of each written file as it was generated, keyed by its slash separated path relative to
+	// the destination, to tell the generated files apart from files edited since
+	FileHashes map[string]string `json:"fileHashes,omitempty"`
 }
 
 type DryRunRecorder struct {
@@ -22,6 +29,23 @@ func (d *DryRunRecorder) Record(key, value string) {
 	d.DryRunInfo.Variables[key] = value
 }
 
+func (d *DryRunRecorder) RecordMultiSelect(key string, values []string) {
+	if d.DryRunInfo.MultiSelectVariables == nil {
+		d.DryRunInfo.MultiSelectVariables = make(map[string][]string)
+	}
+	if values == nil {
+		values = []string{}
+	}
+	d.DryRunInfo.MultiSelectVariables[key] = values
+}
+
+func (d *DryRunRecorder) RecordTemplateVersion(artifact, templateVersion string) {
+	if d.DryRunInfo.TemplateVersions == nil {
+		d.DryRunInfo.TemplateVersions = make(map[string]string)
+	}
+	d.DryRunInfo.TemplateVersions[artifact] = templateVersion
+}
+
 func NewDryRunRecorder() *DryRunRecorder {
 	return &DryRunRecorder{
 		DryRunInfo: &DryRunInfo{
@@ -1,13 +1,20 @@
 package embedutils
 
 import (
-	"embed"
 	"fmt"
 	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
 )
 
-func EmbedFStoMap(embedFS embed.FS, path string) (map[string]fs.DirEntry, error) {
-	files, err := embedFS.ReadDir(path)
+// VersionSeparator separates the template name from the version in the directory name of an older embedded
+// template version, for example dockerfiles/go@0.9.0
+const VersionSeparator = "@"
+
+func EmbedFStoMap(embedFS fs.FS, path string) (map[string]fs.DirEntry, error) {
+	files, err := fs.ReadDir(embedFS, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to readDir: %w", err)
 	}
@@ -15,7 +22,8 @@ func EmbedFStoMap(embedFS embed.FS, path string) (map[string]fs.DirEntry, error)
 	mapping := make(map[string]fs.DirEntry)
 
 	for _, f := range files {
-		if f.IsDir() {
+		// older template versions are reached through ResolveVersionedDir rather than listed as templates of their own
+		if f.IsDir() && !strings.Contains(f.Name(), VersionSeparator) {
 			mapping[f.Name()] = f
 		}
 	}
@@ -39,3 +47,76 @@ func EmbedFStoMapWithFiles(embedFS fs.FS, path string) (map[string]fs.DirEntry,
 
 	return mapping, nil
 }
+
+// VersionedDirs returns the directories of the older embedded versions of the template name in path, keyed by version
+func VersionedDirs(fsys fs.FS, path, name string) (map[string]fs.DirEntry, error) {
+	files, err := fs.ReadDir(fsys, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to readDir: %w", err)
+	}
+
+	dirs := make(map[string]fs.DirEntry)
+	for _, f := range files {
+		dirName, dirVersion, ok := strings.Cut(f.Name(), VersionSeparator)
+		if f.IsDir() && ok && dirName == name {
+			dirs[dirVersion] = f
+		}
+	}
+	return dirs, nil
+}
+
+// TemplateVersions returns currentVersion and the older embedded versions of the template name in path, newest first
+func TemplateVersions(fsys fs.FS, path, name, currentVersion string) ([]string, error) {
+	dirs, err := VersionedDirs(fsys, path, name)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(dirs)+1)
+	if currentVersion != "" {
+		versions = append(versions, currentVersion)
+	}
+	for v := range dirs {
+		if v != currentVersion {
+			versions = append(versions, v)
+		}
+	}
+	SortVersions(versions)
+	return versions, nil
+}
+
+// ResolveVersionedDir returns the directory of the template name in path at templateVersion. That is current when
+// currentVersion matches templateVersion, and the embedded name@templateVersion directory otherwise.
+func ResolveVersionedDir(fsys fs.FS, path, name string, current fs.DirEntry, currentVersion, templateVersion string) (fs.DirEntry, error) {
+	if templateVersion == currentVersion {
+		return current, nil
+	}
+
+	dirs, err := VersionedDirs(fsys, path, name)
+	if err != nil {
+		return nil, err
+	}
+	dir, ok := dirs[templateVersion]
+	if !ok {
+		versions, _ := TemplateVersions(fsys, path, name, currentVersion)
+		return nil, fmt.Errorf("version %s of template %s is not available, available versions: %s", templateVersion, name, strings.Join(versions, ", "))
+	}
+	return dir, nil
+}
+
+// SortVersions sorts versions newest first, placing versions that aren't semantic versions last
+func SortVersions(versions []string) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		vi, erri := version.NewVersion(versions[i])
+		vj, errj := version.NewVersion(versions[j])
+		switch {
+		case erri != nil && errj != nil:
+			return versions[i] > versions[j]
+		case erri != nil:
+			return false
+		case errj != nil:
+			return true
+		}
+		return vi.GreaterThan(vj)
+	})
+}
@@ -0,0 +1,52 @@
+package embedutils
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateVersions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dockerfiles/go/draft.yaml":        {Data: []byte(`version: "1.2.0"`)},
+		"dockerfiles/go@1.0.0/draft.yaml":  {Data: []byte(`version: "1.0.0"`)},
+		"dockerfiles/go@1.10.0/draft.yaml": {Data: []byte(`version: "1.10.0"`)},
+		"dockerfiles/gomodule/draft.yaml":  {Data: []byte(`version: "1.0.0"`)},
+	}
+
+	versions, err := TemplateVersions(fsys, "dockerfiles", "go", "1.2.0")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.10.0", "1.2.0", "1.0.0"}, versions)
+
+	versions, err = TemplateVersions(fsys, "dockerfiles", "gomodule", "1.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.0.0"}, versions)
+}
+
+func TestResolveVersionedDir(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dockerfiles/go/draft.yaml":       {Data: []byte(`version: "1.2.0"`)},
+		"dockerfiles/go@1.0.0/draft.yaml": {Data: []byte(`version: "1.0.0"`)},
+	}
+	entries, err := VersionedDirs(fsys, "dockerfiles", "go")
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+
+	dir, err := ResolveVersionedDir(fsys, "dockerfiles", "go", nil, "1.2.0", "1.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, "go@1.0.0", dir.Name())
+
+	dir, err = ResolveVersionedDir(fsys, "dockerfiles", "go", entries["1.0.0"], "1.0.0", "1.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, "go@1.0.0", dir.Name(), "the current directory is used when the version matches")
+
+	_, err = ResolveVersionedDir(fsys, "dockerfiles", "go", nil, "1.2.0", "0.9.0")
+	assert.ErrorContains(t, err, "available versions: 1.2.0, 1.0.0")
+}
+
+func TestSortVersions(t *testing.T) {
+	versions := []string{"dev", "1.0.0", "2.0.0-rc.1", "1.9.0", "2.0.0"}
+	SortVersions(versions)
+	assert.Equal(t, []string{"2.0.0", "2.0.0-rc.1", "1.9.0", "1.0.0", "dev"}, versions)
+}
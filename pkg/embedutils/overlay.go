@@ -0,0 +1,170 @@
+package embedutils
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// TemplateDirEnv names a directory laid out like draft's template directory whose files take precedence over the
+// embedded templates file by file, so pack developers can try template changes with an installed draft instead of
+// rebuilding it
+const TemplateDirEnv = "DRAFT_TEMPLATE_DIR"
+
+var warnTemplateDir sync.Once
+
+// WithTemplateDir returns embedded with the files of the directory in $DRAFT_TEMPLATE_DIR laid over it, or embedded
+// itself when the variable isn't set
+func WithTemplateDir(embedded fs.FS) fs.FS {
+	dir := os.Getenv(TemplateDirEnv)
+	if dir == "" {
+		return embedded
+	}
+	warnTemplateDir.Do(func() {
+		log.Warnf("--> Using the files of %s=%s over the embedded templates", TemplateDirEnv, dir)
+	})
+	return &OverlayFS{Upper: os.DirFS(dir), Lower: embedded}
+}
+
+// OverlayFS serves the files of Upper over those of Lower. A file of Upper replaces the file of Lower with the same
+// path, and directories list the files of both, so Upper only needs to hold the files that differ. Only the top level
+// directories of Lower are overlaid, Upper's other directories aren't listed.
+type OverlayFS struct {
+	Upper fs.FS
+	Lower fs.FS
+}
+
+var (
+	_ fs.ReadDirFS  = &OverlayFS{}
+	_ fs.ReadFileFS = &OverlayFS{}
+	_ fs.StatFS     = &OverlayFS{}
+)
+
+func (o *OverlayFS) Open(name string) (fs.File, error) {
+	if !o.overlaid(name) {
+		return o.Lower.Open(name)
+	}
+	info, err := fs.Stat(o.Upper, name)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return o.Lower.Open(name)
+	case err != nil:
+		return nil, err
+	case !info.IsDir():
+		return o.Upper.Open(name)
+	}
+
+	entries, err := o.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	dirFS := o.Upper
+	if isDir(o.Lower, name) {
+		dirFS = o.Lower
+	}
+	dir, err := dirFS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &overlayDir{File: dir, entries: entries}, nil
+}
+
+func (o *OverlayFS) ReadFile(name string) ([]byte, error) {
+	if o.overlaid(name) {
+		if info, err := fs.Stat(o.Upper, name); err == nil && !info.IsDir() {
+			return fs.ReadFile(o.Upper, name)
+		} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return fs.ReadFile(o.Lower, name)
+}
+
+func (o *OverlayFS) Stat(name string) (fs.FileInfo, error) {
+	if o.overlaid(name) {
+		info, err := fs.Stat(o.Upper, name)
+		if err == nil {
+			return info, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return fs.Stat(o.Lower, name)
+}
+
+// ReadDir lists the entries of the directory name in both file systems, those of Upper replacing those of Lower with
+// the same name
+func (o *OverlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	lower, lowerErr := fs.ReadDir(o.Lower, name)
+	if !o.overlaid(name) {
+		return lower, lowerErr
+	}
+	upper, upperErr := fs.ReadDir(o.Upper, name)
+	if upperErr != nil {
+		if errors.Is(upperErr, fs.ErrNotExist) {
+			return lower, lowerErr
+		}
+		return nil, upperErr
+	}
+	if lowerErr != nil && !errors.Is(lowerErr, fs.ErrNotExist) {
+		return nil, lowerErr
+	}
+
+	entries := make(map[string]fs.DirEntry, len(lower)+len(upper))
+	for _, entry := range lower {
+		entries[entry.Name()] = entry
+	}
+	for _, entry := range upper {
+		entries[entry.Name()] = entry
+	}
+	merged := make([]fs.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		merged = append(merged, entry)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name() < merged[j].Name() })
+	return merged, nil
+}
+
+// overlaid returns whether name is within a top level directory of Lower
+func (o *OverlayFS) overlaid(name string) bool {
+	if name == "." || !fs.ValidPath(name) {
+		return false
+	}
+	top, _, _ := strings.Cut(name, "/")
+	return isDir(o.Lower, top)
+}
+
+func isDir(fsys fs.FS, name string) bool {
+	info, err := fs.Stat(fsys, name)
+	return err == nil && info.IsDir()
+}
+
+// overlayDir is a directory of both file systems, listing the merged entries of OverlayFS.ReadDir
+type overlayDir struct {
+	fs.File
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *overlayDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+	return rest[:n], nil
+}
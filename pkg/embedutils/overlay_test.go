@@ -0,0 +1,71 @@
+package embedutils
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverlayFS(t *testing.T) {
+	o := &OverlayFS{
+		Upper: fstest.MapFS{
+			"dockerfiles/go/Dockerfile":    {Data: []byte("FROM golang:edited")},
+			"dockerfiles/go/.dockerignore": {Data: []byte("bin")},
+			"dockerfiles/zig/draft.yaml":   {Data: []byte(`version: "0.1.0"`)},
+			"deployments/helm/draft.yaml":  {Data: []byte("not overlaid")},
+		},
+		Lower: fstest.MapFS{
+			"dockerfiles/go/Dockerfile": {Data: []byte("FROM golang")},
+			"dockerfiles/go/draft.yaml": {Data: []byte(`version: "1.0.0"`)},
+		},
+	}
+
+	content, err := fs.ReadFile(o, "dockerfiles/go/Dockerfile")
+	assert.Nil(t, err)
+	assert.Equal(t, "FROM golang:edited", string(content), "the file of the upper file system takes precedence")
+	content, err = fs.ReadFile(o, "dockerfiles/go/draft.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, `version: "1.0.0"`, string(content), "files missing from the upper file system are read from the lower one")
+
+	entries, err := fs.ReadDir(o, "dockerfiles/go")
+	assert.Nil(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{".dockerignore", "Dockerfile", "draft.yaml"}, names)
+
+	packs, err := EmbedFStoMap(o, "dockerfiles")
+	assert.Nil(t, err)
+	assert.Contains(t, packs, "zig", "packs added by the upper file system are listed")
+
+	// only the top level directories of the lower file system are overlaid
+	entries, err = fs.ReadDir(o, ".")
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	_, err = fs.ReadFile(o, "deployments/helm/draft.yaml")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	info, err := fs.Stat(o, "dockerfiles/go/.dockerignore")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), info.Size())
+	assert.Nil(t, fstest.TestFS(o, "dockerfiles/go/Dockerfile", "dockerfiles/go/draft.yaml", "dockerfiles/zig/draft.yaml"))
+}
+
+func TestWithTemplateDir(t *testing.T) {
+	lower := fstest.MapFS{"dockerfiles/go/Dockerfile": {Data: []byte("FROM golang")}}
+	t.Setenv(TemplateDirEnv, "")
+	assert.Equal(t, lower, WithTemplateDir(lower))
+
+	dir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "dockerfiles", "go"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "dockerfiles", "go", "Dockerfile"), []byte("FROM golang:edited"), 0644))
+	t.Setenv(TemplateDirEnv, dir)
+	content, err := fs.ReadFile(WithTemplateDir(lower), "dockerfiles/go/Dockerfile")
+	assert.Nil(t, err)
+	assert.Equal(t, "FROM golang:edited", string(content))
+}
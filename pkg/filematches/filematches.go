@@ -7,12 +7,16 @@ import (
 	"path/filepath"
 
 	"github.com/instrumenta/kubeval/kubeval"
+
+	"github.com/Azure/draft/pkg/ignore"
 )
 
 type FileMatches struct {
 	dest            string
 	patterns        []string
 	deploymentFiles []string
+	// ignore skips the build output and other files of dest ignored by its .gitignore and .draftignore
+	ignore *ignore.Matcher
 }
 
 func (f *FileMatches) findDeploymentFiles(dest string) error {
@@ -28,6 +32,12 @@ func (f *FileMatches) walkFunc(path string, info os.FileInfo, err error) error {
 		log.Fatal(err)
 		return err
 	}
+	if rel, err := filepath.Rel(f.dest, path); err == nil && f.ignore.Match(rel, info.IsDir()) {
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	}
 	if info.IsDir() {
 		return nil
 	}
@@ -73,6 +83,7 @@ func createK8sFileMatches(dest string) *FileMatches {
 		dest:            dest,
 		patterns:        []string{"*.yaml", "*.yml"},
 		deploymentFiles: []string{},
+		ignore:          ignore.NewMatcher(dest),
 	}
 	err := l.findDeploymentFiles(dest)
 	if err != nil {
@@ -112,6 +123,12 @@ func FindDraftDeploymentFiles(dest string) (deploymentType string, err error) {
 	if _, err := os.Stat(dest + "/manifests"); !os.IsNotExist(err) {
 		return "manifests", nil
 	}
+	if _, err := os.Stat(dest + "/azure/containerapp.yaml"); !os.IsNotExist(err) {
+		return "containerapp", nil
+	}
+	if _, err := os.Stat(dest + "/azure/appsettings.json"); !os.IsNotExist(err) {
+		return "appservice", nil
+	}
 
 	return "", errors.New("no supported deployment files found")
 }
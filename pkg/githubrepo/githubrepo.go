@@ -0,0 +1,271 @@
+// Package githubrepo reads the files of GitHub repositories and commits files to their branches through the GitHub API,
+// without a local clone
+package githubrepo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// fileMode is the git mode of the committed files, regular non-executable files
+const fileMode = "100644"
+
+// RunGh runs the gh cli with args and stdin, returning its stdout. It is replaced in tests.
+type RunGh func(stdin []byte, args ...string) ([]byte, error)
+
+// errNotFound is returned by api for the 404 responses of the GitHub API
+var errNotFound = errors.New("not found")
+
+// CommitOptions configures the commit of files to a branch of a GitHub repository
+type CommitOptions struct {
+	// Repo is the repository as OWNER/REPO
+	Repo string
+	// Branch is the branch to commit to, created from Base when it doesn't exist
+	Branch string
+	// Base is the branch new branches start from, the default branch of the repository when empty
+	Base string
+	// Message is the commit message
+	Message string
+	// Files maps the slash separated paths in the repository to the contents committed there, replacing the files of
+	// the same path on the branch
+	Files map[string][]byte
+}
+
+// Client calls the GitHub API with the authentication of the gh cli
+type Client struct {
+	Run RunGh
+}
+
+// NewClient returns a Client running the gh cli installed on the PATH
+func NewClient() *Client {
+	return &Client{Run: runGh}
+}
+
+func runGh(stdin []byte, args ...string) ([]byte, error) {
+	ghCmd := exec.Command("gh", args...)
+	ghCmd.Stdin = bytes.NewReader(stdin)
+	var stderr bytes.Buffer
+	ghCmd.Stderr = &stderr
+	out, err := ghCmd.Output()
+	if err != nil {
+		return out, fmt.Errorf("gh %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// ValidateRepo checks repo is in the OWNER/REPO form
+func ValidateRepo(repo string) error {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid GitHub repository %q, must be OWNER/REPO", repo)
+	}
+	return nil
+}
+
+// Commit commits opts.Files in a single commit on opts.Branch, and returns the commit's sha
+func (c *Client) Commit(opts CommitOptions) (string, error) {
+	if err := ValidateRepo(opts.Repo); err != nil {
+		return "", err
+	}
+	if opts.Branch == "" {
+		return "", errors.New("branch name cannot be empty")
+	}
+	if len(opts.Files) == 0 {
+		return "", errors.New("no files to commit")
+	}
+	repoPath := "repos/" + opts.Repo
+
+	base := opts.Base
+	if base == "" {
+		var repo struct {
+			DefaultBranch string `json:"default_branch"`
+		}
+		if err := c.api("GET", repoPath, nil, &repo); err != nil {
+			return "", fmt.Errorf("getting the default branch of %s: %w", opts.Repo, err)
+		}
+		base = repo.DefaultBranch
+	}
+
+	var ref struct {
+		Object struct {
+			Sha string `json:"sha"`
+		} `json:"object"`
+	}
+	branchExists := true
+	err := c.api("GET", fmt.Sprintf("%s/git/ref/heads/%s", repoPath, opts.Branch), nil, &ref)
+	if errors.Is(err, errNotFound) {
+		branchExists = false
+		log.Debugf("branch %s of %s not found, creating it from %s", opts.Branch, opts.Repo, base)
+		err = c.api("GET", fmt.Sprintf("%s/git/ref/heads/%s", repoPath, base), nil, &ref)
+	}
+	if err != nil {
+		return "", fmt.Errorf("getting the head of %s: %w", opts.Repo, err)
+	}
+	parent := ref.Object.Sha
+
+	var parentCommit struct {
+		Tree struct {
+			Sha string `json:"sha"`
+		} `json:"tree"`
+	}
+	if err := c.api("GET", fmt.Sprintf("%s/git/commits/%s", repoPath, parent), nil, &parentCommit); err != nil {
+		return "", fmt.Errorf("getting commit %s of %s: %w", parent, opts.Repo, err)
+	}
+
+	type treeEntry struct {
+		Path    string `json:"path"`
+		Mode    string `json:"mode"`
+		Type    string `json:"type"`
+		Content string `json:"content"`
+	}
+	paths := make([]string, 0, len(opts.Files))
+	for p := range opts.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	entries := make([]treeEntry, len(paths))
+	for i, p := range paths {
+		repoFilePath := path.Clean(strings.TrimPrefix(p, "/"))
+		if repoFilePath == "." || strings.HasPrefix(repoFilePath, "../") {
+			return "", fmt.Errorf("invalid path %s in the repository", p)
+		}
+		entries[i] = treeEntry{Path: repoFilePath, Mode: fileMode, Type: "blob", Content: string(opts.Files[p])}
+	}
+
+	var tree struct {
+		Sha string `json:"sha"`
+	}
+	treeRequest := map[string]interface{}{"base_tree": parentCommit.Tree.Sha, "tree": entries}
+	if err := c.api("POST", repoPath+"/git/trees", treeRequest, &tree); err != nil {
+		return "", fmt.Errorf("creating the tree of the commit: %w", err)
+	}
+
+	var commit struct {
+		Sha string `json:"sha"`
+	}
+	commitRequest := map[string]interface{}{"message": opts.Message, "tree": tree.Sha, "parents": []string{parent}}
+	if err := c.api("POST", repoPath+"/git/commits", commitRequest, &commit); err != nil {
+		return "", fmt.Errorf("creating the commit: %w", err)
+	}
+
+	if branchExists {
+		err = c.api("PATCH", fmt.Sprintf("%s/git/refs/heads/%s", repoPath, opts.Branch), map[string]interface{}{"sha": commit.Sha}, nil)
+	} else {
+		err = c.api("POST", repoPath+"/git/refs", map[string]interface{}{"ref": "refs/heads/" + opts.Branch, "sha": commit.Sha}, nil)
+	}
+	if err != nil {
+		return "", fmt.Errorf("updating branch %s: %w", opts.Branch, err)
+	}
+	return commit.Sha, nil
+}
+
+// TreeFile is a file of a repository's tree
+type TreeFile struct {
+	// Path is the slash separated path of the file in the repository
+	Path string
+	Size int
+}
+
+// Tree lists the files of repo at ref, a branch, tag or commit sha, or at the head of the default branch when ref is
+// empty
+func (c *Client) Tree(repo, ref string) ([]TreeFile, error) {
+	if err := ValidateRepo(repo); err != nil {
+		return nil, err
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	var tree struct {
+		Tree []struct {
+			Path string `json:"path"`
+			Type string `json:"type"`
+			Size int    `json:"size"`
+		} `json:"tree"`
+		Truncated bool `json:"truncated"`
+	}
+	if err := c.api("GET", fmt.Sprintf("repos/%s/git/trees/%s?recursive=1", repo, url.PathEscape(ref)), nil, &tree); err != nil {
+		return nil, fmt.Errorf("listing the files of %s: %w", repo, err)
+	}
+	if tree.Truncated {
+		log.Warnf("%s has too many files to list them all, only some of them are read", repo)
+	}
+
+	var files []TreeFile
+	for _, entry := range tree.Tree {
+		if entry.Type == "blob" {
+			files = append(files, TreeFile{Path: entry.Path, Size: entry.Size})
+		}
+	}
+	return files, nil
+}
+
+// ReadFile returns the contents of the file at the slash separated filePath of repo at ref, or at the head of the
+// default branch when ref is empty. A missing file returns an error wrapping fs.ErrNotExist.
+func (c *Client) ReadFile(repo, ref, filePath string) ([]byte, error) {
+	if err := ValidateRepo(repo); err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("repos/%s/contents/%s", repo, (&url.URL{Path: strings.TrimPrefix(filePath, "/")}).EscapedPath())
+	if ref != "" {
+		endpoint += "?ref=" + url.QueryEscape(ref)
+	}
+
+	var content struct {
+		Type     string `json:"type"`
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	err := c.api("GET", endpoint, nil, &content)
+	if errors.Is(err, errNotFound) {
+		return nil, fmt.Errorf("%s of %s: %w", filePath, repo, fs.ErrNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s of %s: %w", filePath, repo, err)
+	}
+	if content.Type != "file" || content.Encoding != "base64" {
+		return nil, fmt.Errorf("reading %s of %s: not a file", filePath, repo)
+	}
+	return base64.StdEncoding.DecodeString(strings.ReplaceAll(content.Content, "\n", ""))
+}
+
+// api calls endpoint of the GitHub API with method, sending request as the json body when it is not nil and decoding
+// the json response into response when it is not nil
+func (c *Client) api(method, endpoint string, request, response interface{}) error {
+	args := []string{"api", "--method", method, endpoint}
+	var stdin []byte
+	if request != nil {
+		body, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+		stdin = body
+		args = append(args, "--input", "-")
+	}
+
+	out, err := c.Run(stdin, args...)
+	if err != nil {
+		if strings.Contains(err.Error(), "HTTP 404") {
+			return fmt.Errorf("%s %s: %w", method, endpoint, errNotFound)
+		}
+		return err
+	}
+	if response == nil {
+		return nil
+	}
+	if err := json.Unmarshal(out, response); err != nil {
+		return fmt.Errorf("decoding the response of %s %s: %w", method, endpoint, err)
+	}
+	return nil
+}
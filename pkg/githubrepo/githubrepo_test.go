@@ -0,0 +1,137 @@
+package githubrepo
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeGh answers the gh api calls from responses, keyed by method and endpoint, and records the calls
+type fakeGh struct {
+	responses map[string]string
+	calls     []string
+	bodies    map[string]map[string]interface{}
+}
+
+func (f *fakeGh) run(stdin []byte, args ...string) ([]byte, error) {
+	call := args[2] + " " + args[3]
+	f.calls = append(f.calls, call)
+	if len(stdin) > 0 {
+		var body map[string]interface{}
+		if err := json.Unmarshal(stdin, &body); err != nil {
+			return nil, err
+		}
+		f.bodies[call] = body
+	}
+	response, ok := f.responses[call]
+	if !ok {
+		return nil, errors.New("gh: Not Found (HTTP 404)")
+	}
+	return []byte(response), nil
+}
+
+func newFakeGh() *fakeGh {
+	return &fakeGh{
+		responses: map[string]string{
+			"GET repos/owner/app":                     `{"default_branch": "main"}`,
+			"GET repos/owner/app/git/ref/heads/main":  `{"object": {"sha": "base"}}`,
+			"GET repos/owner/app/git/commits/base":    `{"tree": {"sha": "basetree"}}`,
+			"GET repos/owner/app/git/commits/head":    `{"tree": {"sha": "headtree"}}`,
+			"POST repos/owner/app/git/trees":          `{"sha": "tree"}`,
+			"POST repos/owner/app/git/commits":        `{"sha": "commit"}`,
+			"POST repos/owner/app/git/refs":           `{}`,
+			"PATCH repos/owner/app/git/refs/heads/ci": `{}`,
+		},
+		bodies: map[string]map[string]interface{}{},
+	}
+}
+
+func TestCommitNewBranch(t *testing.T) {
+	gh := newFakeGh()
+	c := &Client{Run: gh.run}
+	sha, err := c.Commit(CommitOptions{
+		Repo:    "owner/app",
+		Branch:  "draft",
+		Message: "Add Dockerfile",
+		Files:   map[string][]byte{"Dockerfile": []byte("FROM scratch\n"), "manifests/service.yaml": []byte("kind: Service\n")},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "commit", sha)
+	assert.Equal(t, []string{
+		"GET repos/owner/app",
+		"GET repos/owner/app/git/ref/heads/draft",
+		"GET repos/owner/app/git/ref/heads/main",
+		"GET repos/owner/app/git/commits/base",
+		"POST repos/owner/app/git/trees",
+		"POST repos/owner/app/git/commits",
+		"POST repos/owner/app/git/refs",
+	}, gh.calls)
+
+	tree := gh.bodies["POST repos/owner/app/git/trees"]
+	assert.Equal(t, "basetree", tree["base_tree"])
+	entries := tree["tree"].([]interface{})
+	assert.Len(t, entries, 2)
+	assert.Equal(t, map[string]interface{}{"path": "Dockerfile", "mode": "100644", "type": "blob", "content": "FROM scratch\n"}, entries[0])
+	assert.Equal(t, []interface{}{"base"}, gh.bodies["POST repos/owner/app/git/commits"]["parents"])
+	assert.Equal(t, "refs/heads/draft", gh.bodies["POST repos/owner/app/git/refs"]["ref"])
+}
+
+func TestCommitExistingBranch(t *testing.T) {
+	gh := newFakeGh()
+	gh.responses["GET repos/owner/app/git/ref/heads/ci"] = `{"object": {"sha": "head"}}`
+	c := &Client{Run: gh.run}
+	_, err := c.Commit(CommitOptions{Repo: "owner/app", Branch: "ci", Base: "main", Files: map[string][]byte{"Dockerfile": []byte("FROM scratch\n")}})
+	assert.Nil(t, err)
+	assert.Equal(t, "GET repos/owner/app/git/commits/head", gh.calls[1])
+	assert.Equal(t, "headtree", gh.bodies["POST repos/owner/app/git/trees"]["base_tree"])
+	assert.Equal(t, "commit", gh.bodies["PATCH repos/owner/app/git/refs/heads/ci"]["sha"])
+}
+
+func TestCommitErrors(t *testing.T) {
+	c := &Client{Run: newFakeGh().run}
+	files := map[string][]byte{"Dockerfile": []byte("FROM scratch\n")}
+
+	_, err := c.Commit(CommitOptions{Repo: "app", Branch: "draft", Files: files})
+	assert.ErrorContains(t, err, "must be OWNER/REPO")
+
+	_, err = c.Commit(CommitOptions{Repo: "owner/app", Files: files})
+	assert.ErrorContains(t, err, "branch name cannot be empty")
+
+	_, err = c.Commit(CommitOptions{Repo: "owner/app", Branch: "draft"})
+	assert.ErrorContains(t, err, "no files to commit")
+
+	_, err = c.Commit(CommitOptions{Repo: "owner/app", Branch: "draft", Files: map[string][]byte{"../Dockerfile": nil}})
+	assert.ErrorContains(t, err, "invalid path")
+
+	_, err = c.Commit(CommitOptions{Repo: "owner/missing", Branch: "draft", Files: files})
+	assert.True(t, strings.Contains(err.Error(), "getting the default branch of owner/missing"))
+}
+
+func TestTreeAndReadFile(t *testing.T) {
+	gh := newFakeGh()
+	gh.responses["GET repos/owner/app/git/trees/HEAD?recursive=1"] = `{"tree": [
+		{"path": "api", "type": "tree"},
+		{"path": "api/go.mod", "type": "blob", "size": 12},
+		{"path": "main.go", "type": "blob", "size": 40}
+	]}`
+	gh.responses["GET repos/owner/app/contents/api/go.mod?ref=v1"] = `{"type": "file", "encoding": "base64", "content": "bW9kdWxlIGFw\ncAo=\n"}`
+	c := &Client{Run: gh.run}
+
+	files, err := c.Tree("owner/app", "")
+	assert.Nil(t, err)
+	assert.Equal(t, []TreeFile{{Path: "api/go.mod", Size: 12}, {Path: "main.go", Size: 40}}, files)
+
+	content, err := c.ReadFile("owner/app", "v1", "api/go.mod")
+	assert.Nil(t, err)
+	assert.Equal(t, "module app\n", string(content))
+
+	_, err = c.ReadFile("owner/app", "", "missing.txt")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	_, err = c.Tree("app", "")
+	assert.ErrorContains(t, err, "must be OWNER/REPO")
+}
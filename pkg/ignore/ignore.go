@@ -0,0 +1,190 @@
+// Package ignore matches the paths of a repo against the patterns of its .gitignore and .draftignore files, so that
+// build output and vendored directories are left out when draft walks the repo.
+package ignore
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Files are the ignore files read in each directory of the repo. Patterns of .draftignore apply to draft only, such
+// as to leave out sample apps that are committed.
+var Files = []string{".gitignore", ".draftignore"}
+
+// gitDir is always ignored
+const gitDir = ".git"
+
+type pattern struct {
+	// base is the slash separated directory of the ignore file holding the pattern, relative to the root
+	base    string
+	regex   *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// Matcher matches paths relative to a root directory against the ignore files of the root and of the directories
+// they are in, which are read the first time a path in the directory is matched
+type Matcher struct {
+	root     string
+	patterns []pattern
+	read     map[string]bool
+}
+
+// NewMatcher returns a Matcher of the paths under root
+func NewMatcher(root string) *Matcher {
+	return &Matcher{root: root, read: make(map[string]bool)}
+}
+
+// Match returns whether the path rel, relative to the root, is ignored because it or one of its parent directories
+// matches the ignore patterns. isDir tells whether rel is a directory, for patterns that only match directories.
+func (m *Matcher) Match(rel string, isDir bool) bool {
+	rel = filepath.ToSlash(filepath.Clean(rel))
+	if rel == "." || rel == "" {
+		return false
+	}
+	segments := strings.Split(rel, "/")
+	for i := range segments {
+		if m.match(strings.Join(segments[:i+1], "/"), i < len(segments)-1 || isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// match returns whether the last of the patterns of rel's parent directories that matches rel ignores it
+func (m *Matcher) match(rel string, isDir bool) bool {
+	if path.Base(rel) == gitDir {
+		return true
+	}
+	m.readDir(path.Dir(rel))
+
+	ignored := false
+	for _, p := range m.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		sub := rel
+		if p.base != "." {
+			if !strings.HasPrefix(rel, p.base+"/") {
+				continue
+			}
+			sub = strings.TrimPrefix(rel, p.base+"/")
+		}
+		if p.regex.MatchString(sub) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// readDir reads the ignore files of dir and its parents that weren't read yet, parents first so the patterns of
+// deeper files take precedence
+func (m *Matcher) readDir(dir string) {
+	if m.read[dir] {
+		return
+	}
+	if dir != "." {
+		m.readDir(path.Dir(dir))
+	}
+	m.read[dir] = true
+	for _, name := range Files {
+		content, err := os.ReadFile(filepath.Join(m.root, filepath.FromSlash(dir), name))
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				log.Debugf("unable to read %s in %s: %v", name, dir, err)
+			}
+			continue
+		}
+		m.patterns = append(m.patterns, parse(dir, content)...)
+	}
+}
+
+// parse returns the patterns of an ignore file in the directory base
+func parse(base string, content []byte) []pattern {
+	var patterns []pattern
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p := pattern{base: base}
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		// patterns with a slash other than a trailing one are relative to the ignore file's directory, others match
+		// at any depth
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		expr := globToRegex(line)
+		if !anchored && !strings.HasPrefix(line, "**") {
+			expr = "(?:.*/)?" + expr
+		}
+		regex, err := regexp.Compile("^" + expr + "$")
+		if err != nil {
+			log.Debugf("skipping invalid ignore pattern %q: %v", line, err)
+			continue
+		}
+		p.regex = regex
+		patterns = append(patterns, p)
+	}
+	return patterns
+}
+
+// globToRegex translates the gitignore glob pattern to a regular expression, where ** matches any number of
+// directories and * and ? don't match slashes
+func globToRegex(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '\\' && i+1 < len(glob):
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
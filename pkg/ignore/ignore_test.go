@@ -0,0 +1,51 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatcher(t *testing.T) {
+	root := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(root, ".gitignore"), []byte("# build output\ndist/\n/target\n*.log\n!keep.log\nnode_modules\ndocs/**/*.html\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(root, ".draftignore"), []byte("samples/\n"), 0644))
+	assert.Nil(t, os.MkdirAll(filepath.Join(root, "web"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "web", ".gitignore"), []byte("generated.go\n!important.log\n"), 0644))
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{path: "main.go", want: false},
+		{path: "dist", isDir: true, want: true},
+		{path: "dist", want: false},
+		{path: "dist/app.js", want: true},
+		{path: "web/dist/app.js", want: true},
+		{path: "target", isDir: true, want: true},
+		{path: "target/release/app", want: true},
+		{path: "crates/api/target", isDir: true, want: false},
+		{path: "server.log", want: true},
+		{path: "logs/server.log", want: true},
+		{path: "keep.log", want: false},
+		{path: "web/important.log", want: false},
+		{path: "web/node_modules/lib/index.js", want: true},
+		{path: "docs/api/index.html", want: true},
+		{path: "docs/index.html", want: true},
+		{path: "site/docs/index.html", want: false},
+		{path: "samples/app/main.go", want: true},
+		{path: "web/generated.go", want: true},
+		{path: "generated.go", want: false},
+		{path: ".git/config", want: true},
+		{path: ".", isDir: true, want: false},
+	}
+	m := NewMatcher(root)
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, m.Match(filepath.FromSlash(tt.path), tt.isDir))
+		})
+	}
+}
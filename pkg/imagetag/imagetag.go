@@ -0,0 +1,195 @@
+package imagetag
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Strategies built into draft
+const (
+	SHA       = "sha"
+	SemVer    = "semver"
+	Date      = "date"
+	BranchSHA = "branch-sha"
+)
+
+// Default is the strategy of the deployment files and workflows generated without choosing one
+const Default = SHA
+
+// BranchVariable is the environment variable holding the branch a CI job builds, which the workflows set for the
+// Script of their strategy
+const BranchVariable = "IMAGE_TAG_BRANCH"
+
+// FallbackTag is the tag of the deployment files generated outside of a git repository or before its first commit
+const FallbackTag = "latest"
+
+// Commit is the commit an image is built from
+type Commit struct {
+	SHA    string
+	Branch string
+	Time   time.Time
+	// Describe is git describe of the commit against the v* tags, such as v1.2.0-3-gabc1234, or "" without one
+	Describe string
+}
+
+// Strategy tells the tag of the image built from a commit, the same way in the deployment files draft writes and in
+// the CI jobs of the workflows it generates, so that neither drifts from the other
+type Strategy interface {
+	// Tag returns the tag of the image built from commit
+	Tag(commit Commit) string
+	// Script returns the shell command printing the tag of the image built from the commit a CI job checked out, with
+	// the branch it builds in $BranchVariable
+	Script() string
+}
+
+var strategies = map[string]Strategy{
+	SHA:       shaStrategy{},
+	SemVer:    semverStrategy{},
+	Date:      dateStrategy{},
+	BranchSHA: branchSHAStrategy{},
+}
+
+// Register adds a strategy called name, replacing the one of that name
+func Register(name string, strategy Strategy) {
+	strategies[name] = strategy
+}
+
+// Get returns the strategy called name, or the Default one when name is empty
+func Get(name string) (Strategy, error) {
+	if name == "" {
+		name = Default
+	}
+	strategy, ok := strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("invalid image tag strategy %q, must be one of %s", name, strings.Join(Names(), ", "))
+	}
+	return strategy, nil
+}
+
+// Validate checks that name is the name of a strategy
+func Validate(name string) error {
+	_, err := Get(name)
+	return err
+}
+
+// Names returns the sorted names of the strategies
+func Names() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TagForDir returns the tag by strategy of the image built from the commit checked out in the git repository of dir,
+// or FallbackTag when dir has no commit to build
+func TagForDir(strategy Strategy, dir string) string {
+	commit, err := LookupCommit(dir)
+	if err != nil {
+		return FallbackTag
+	}
+	return strategy.Tag(commit)
+}
+
+// LookupCommit returns the commit checked out in the git repository of dir
+func LookupCommit(dir string) (Commit, error) {
+	out, err := git(dir, "show", "-s", "--format=%H %ct", "HEAD")
+	if err != nil {
+		return Commit{}, err
+	}
+	sha, timestamp, _ := strings.Cut(out, " ")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return Commit{}, fmt.Errorf("reading the time of commit %s: %w", sha, err)
+	}
+	commit := Commit{SHA: sha, Time: time.Unix(seconds, 0).UTC()}
+	if branch, err := git(dir, "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		commit.Branch = branch
+	}
+	if describe, err := git(dir, "describe", "--tags", "--match", "v[0-9]*"); err == nil {
+		commit.Describe = describe
+	}
+	return commit, nil
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// shortSHA is the length of the abbreviated commit shas of the tags
+const shortSHA = 12
+
+// maxBranchLength is the length branches are cut to in tags, which registries limit to 128 characters
+const maxBranchLength = 100
+
+// shaStrategy tags images with the full sha of their commit
+type shaStrategy struct{}
+
+func (shaStrategy) Tag(commit Commit) string { return commit.SHA }
+
+func (shaStrategy) Script() string { return "git rev-parse HEAD" }
+
+// semverStrategy tags images with git describe of their commit against the v* tags without the v, such as 1.2.0 for a
+// tagged commit and 1.2.0-3-gabc1234 for a later one, and 0.0.0-SHORTSHA before the first tag
+type semverStrategy struct{}
+
+func (semverStrategy) Tag(commit Commit) string {
+	if commit.Describe != "" {
+		return strings.TrimPrefix(commit.Describe, "v")
+	}
+	return "0.0.0-" + commit.SHA[:shortSHA]
+}
+
+func (semverStrategy) Script() string {
+	// CI jobs check out a single commit without the tags, which are fetched first
+	return fmt.Sprintf(`git fetch --quiet --tags --unshallow origin 2>/dev/null || git fetch --quiet --tags origin 2>/dev/null; git describe --tags --match 'v[0-9]*' 2>/dev/null | sed 's/^v//' | grep . || echo "0.0.0-$(git rev-parse HEAD | cut -c1-%d)"`, shortSHA)
+}
+
+// dateStrategy tags images with the UTC time of their commit, such as 20240131.154502, which sorts in commit order
+type dateStrategy struct{}
+
+const dateLayout = "20060102.150405"
+
+func (dateStrategy) Tag(commit Commit) string { return commit.Time.UTC().Format(dateLayout) }
+
+func (dateStrategy) Script() string {
+	return "TZ=UTC git show -s --format=%cd --date=format-local:%Y%m%d.%H%M%S HEAD"
+}
+
+// branchSHAStrategy tags images with their branch, its characters other than letters, digits, _, . and - replaced by -,
+// and the short sha of their commit, such as feature-login-abc1234def56
+type branchSHAStrategy struct{}
+
+func (branchSHAStrategy) Tag(commit Commit) string {
+	// a detached HEAD has no branch, and tags can't start with -
+	if commit.Branch == "" {
+		return commit.SHA[:shortSHA]
+	}
+	branch := []byte(commit.Branch)
+	for i, c := range branch {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-') {
+			branch[i] = '-'
+		}
+	}
+	if len(branch) > maxBranchLength {
+		branch = branch[:maxBranchLength]
+	}
+	return string(branch) + "-" + commit.SHA[:shortSHA]
+}
+
+func (branchSHAStrategy) Script() string {
+	return fmt.Sprintf(`printf '%%s-%%s' "$(printf '%%s' "$%s" | tr -c 'A-Za-z0-9_.-' '-' | cut -c1-%d)" "$(git rev-parse HEAD | cut -c1-%d)"`, BranchVariable, maxBranchLength, shortSHA)
+}
@@ -0,0 +1,84 @@
+package imagetag
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTag(t *testing.T) {
+	commit := Commit{
+		SHA:    "0123456789abcdef0123456789abcdef01234567",
+		Branch: "feature/Login page",
+		Time:   time.Date(2024, 1, 31, 15, 45, 2, 0, time.UTC),
+	}
+	tags := map[string]string{
+		SHA:       "0123456789abcdef0123456789abcdef01234567",
+		SemVer:    "0.0.0-0123456789ab",
+		Date:      "20240131.154502",
+		BranchSHA: "feature-Login-page-0123456789ab",
+	}
+	for name, tag := range tags {
+		strategy, err := Get(name)
+		assert.Nil(t, err)
+		assert.Equal(t, tag, strategy.Tag(commit), name)
+	}
+
+	commit.Describe = "v1.2.0-3-g0123456"
+	assert.Equal(t, "1.2.0-3-g0123456", semverStrategy{}.Tag(commit))
+	commit.Branch = ""
+	assert.Equal(t, "0123456789ab", branchSHAStrategy{}.Tag(commit))
+	commit.Branch = strings.Repeat("b", 150)
+	assert.Len(t, branchSHAStrategy{}.Tag(commit), maxBranchLength+1+shortSHA)
+}
+
+func TestGet(t *testing.T) {
+	strategy, err := Get("")
+	assert.Nil(t, err)
+	assert.Equal(t, shaStrategy{}, strategy)
+	assert.ErrorContains(t, Validate("build-number"), "must be one of branch-sha, date, semver, sha")
+}
+
+// TestScriptMatchesTag checks that the CI jobs tag the image of a commit as draft does in the deployment files
+func TestScriptMatchesTag(t *testing.T) {
+	for _, tool := range []string{"git", "sh"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s is not installed", tool)
+		}
+	}
+	dir := t.TempDir()
+	assert.Equal(t, FallbackTag, TagForDir(shaStrategy{}, dir))
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=draft", "-c", "user.email=draft@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		assert.Nil(t, err, string(out))
+	}
+	git("init", "-q", "-b", "feature/login")
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM scratch\n"), 0644))
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	check := func() {
+		for _, name := range Names() {
+			strategy, err := Get(name)
+			assert.Nil(t, err)
+			cmd := exec.Command("sh", "-c", strategy.Script())
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(), BranchVariable+"=feature/login")
+			out, err := cmd.Output()
+			assert.Nil(t, err, name)
+			assert.Equal(t, TagForDir(strategy, dir), strings.TrimSpace(string(out)), name)
+		}
+	}
+	check()
+	git("tag", "v1.2.0")
+	check()
+	git("commit", "-q", "--allow-empty", "-m", "fix")
+	check()
+}
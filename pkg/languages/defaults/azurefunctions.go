@@ -0,0 +1,127 @@
+package defaults
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+const (
+	functionsPack       = "azurefunctions"
+	functionsDotnetPack = "azurefunctionsdotnet"
+)
+
+var (
+	functionsPackVariants = map[string]string{
+		"javascript": functionsPack,
+		"typescript": functionsPack,
+		"python":     functionsPack,
+		"powershell": functionsPack,
+		"csharp":     functionsDotnetPack,
+	}
+	// functionsImageTags are the base image tags of the script stacks' current runtime versions
+	functionsImageTags = map[string]string{
+		"node":       "4-node20",
+		"python":     "4-python3.11",
+		"powershell": "4-powershell7.4",
+	}
+	targetFrameworkRegex = regexp.MustCompile(`<TargetFramework>\s*net(\d+\.\d+)\s*</TargetFramework>`)
+)
+
+// AzureFunctionsExtractor reads the worker runtime and .NET version of Azure Functions projects for the Azure
+// Functions packs
+type AzureFunctionsExtractor struct {
+}
+
+// GetName implements reporeader.VariableExtractor
+func (*AzureFunctionsExtractor) GetName() string {
+	return "azurefunctions"
+}
+
+// MatchesLanguage implements reporeader.VariableExtractor
+func (*AzureFunctionsExtractor) MatchesLanguage(lowerlang string) bool {
+	return lowerlang == functionsPack || lowerlang == functionsDotnetPack
+}
+
+// ReadDefaults implements reporeader.VariableExtractor
+func (*AzureFunctionsExtractor) ReadDefaults(r reporeader.RepoReader) (map[string]string, error) {
+	extractedValues := make(map[string]string)
+
+	stack, err := functionsStack(r)
+	if err != nil {
+		return nil, err
+	}
+	if tag, ok := functionsImageTags[stack]; ok {
+		extractedValues["FUNCTIONSSTACK"] = stack
+		extractedValues["FUNCTIONSIMAGETAG"] = tag
+	}
+
+	projects, err := r.FindFiles(".", []string{"*.csproj"}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error finding project files: %v", err)
+	}
+	for _, project := range projects {
+		content, err := r.ReadFile(project)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", project, err)
+		}
+		if m := targetFrameworkRegex.FindSubmatch(content); m != nil {
+			extractedValues["VERSION"] = string(m[1])
+			break
+		}
+	}
+
+	return extractedValues, nil
+}
+
+// functionsStack returns the worker runtime set in local.settings.json, or the one implied by the dependency files in
+// the repo root
+func functionsStack(r reporeader.RepoReader) (string, error) {
+	if r.Exists("local.settings.json") {
+		content, err := r.ReadFile("local.settings.json")
+		if err != nil {
+			return "", fmt.Errorf("error reading local.settings.json: %v", err)
+		}
+		var settings struct {
+			Values map[string]string `json:"Values"`
+		}
+		if err := json.Unmarshal(content, &settings); err != nil {
+			log.Debugf("unable to parse local.settings.json: %v", err)
+		} else if runtime := settings.Values["FUNCTIONS_WORKER_RUNTIME"]; runtime != "" {
+			return runtime, nil
+		}
+	}
+
+	for _, f := range []struct{ file, stack string }{{"package.json", "node"}, {"requirements.txt", "python"}, {"profile.ps1", "powershell"}} {
+		if r.Exists(f.file) {
+			return f.stack, nil
+		}
+	}
+	return "", nil
+}
+
+// IsAzureFunctionsProject returns whether the repo is an Azure Functions app, which has a host.json in its root or a
+// function.json in a function's directory
+func IsAzureFunctionsProject(r reporeader.RepoReader) bool {
+	if r.Exists("host.json") {
+		return true
+	}
+	files, err := r.FindFiles(".", []string{"function.json"}, 1)
+	if err != nil {
+		log.Debugf("unable to detect azure functions: %v", err)
+		return false
+	}
+	return len(files) > 0
+}
+
+// AzureFunctionsPackVariant returns the Azure Functions pack to use in place of lowerLang, if there is one
+func AzureFunctionsPackVariant(lowerLang string) (string, bool) {
+	variant, ok := functionsPackVariants[lowerLang]
+	return variant, ok
+}
+
+var _ reporeader.VariableExtractor = &AzureFunctionsExtractor{}
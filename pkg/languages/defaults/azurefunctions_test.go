@@ -0,0 +1,73 @@
+package defaults
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+func TestAzureFunctionsExtractor_ReadDefaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string][]byte
+		want  map[string]string
+	}{
+		{
+			name: "worker runtime from local settings",
+			files: map[string][]byte{
+				"host.json":           []byte(`{"version": "2.0"}`),
+				"local.settings.json": []byte(`{"IsEncrypted": false, "Values": {"FUNCTIONS_WORKER_RUNTIME": "python"}}`),
+				"package.json":        []byte(`{}`),
+			},
+			want: map[string]string{"FUNCTIONSSTACK": "python", "FUNCTIONSIMAGETAG": "4-python3.11"},
+		},
+		{
+			name: "worker runtime from dependency files",
+			files: map[string][]byte{
+				"host.json":    []byte(`{"version": "2.0"}`),
+				"package.json": []byte(`{}`),
+			},
+			want: map[string]string{"FUNCTIONSSTACK": "node", "FUNCTIONSIMAGETAG": "4-node20"},
+		},
+		{
+			name: "dotnet isolated",
+			files: map[string][]byte{
+				"host.json":           []byte(`{"version": "2.0"}`),
+				"local.settings.json": []byte(`{"Values": {"FUNCTIONS_WORKER_RUNTIME": "dotnet-isolated"}}`),
+				"app.csproj":          []byte(`<PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup>`),
+			},
+			want: map[string]string{"VERSION": "8.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&AzureFunctionsExtractor{}).ReadDefaults(reporeader.FakeRepoReader{Files: tt.files})
+			if err != nil {
+				t.Errorf("ReadDefaults() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadDefaults() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsAzureFunctionsProject(t *testing.T) {
+	if !IsAzureFunctionsProject(reporeader.FakeRepoReader{Files: map[string][]byte{"host.json": []byte(`{}`)}}) {
+		t.Error("expected host.json project to be detected")
+	}
+	if !IsAzureFunctionsProject(reporeader.FakeRepoReader{Files: map[string][]byte{"HttpTrigger/function.json": []byte(`{}`)}}) {
+		t.Error("expected function.json project to be detected")
+	}
+	if IsAzureFunctionsProject(reporeader.FakeRepoReader{Files: map[string][]byte{"package.json": []byte(`{}`)}}) {
+		t.Error("expected plain node project not to be detected")
+	}
+	if variant, ok := AzureFunctionsPackVariant("csharp"); !ok || variant != "azurefunctionsdotnet" {
+		t.Errorf("unexpected variant %s for csharp", variant)
+	}
+	if _, ok := AzureFunctionsPackVariant("go"); ok {
+		t.Error("go should not have an azure functions variant")
+	}
+}
@@ -0,0 +1,63 @@
+package defaults
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+var (
+	// bunEntrypoints are the conventional entrypoints of bun apps, looked for when package.json doesn't name one
+	bunEntrypoints = []string{"index.ts", "src/index.ts", "server.ts", "index.js"}
+)
+
+// BunExtractor reads the entrypoint of a bun app from the module, main or start script of its package.json
+type BunExtractor struct {
+}
+
+// GetName implements reporeader.VariableExtractor
+func (*BunExtractor) GetName() string {
+	return "bun"
+}
+
+// MatchesLanguage implements reporeader.VariableExtractor
+func (*BunExtractor) MatchesLanguage(lowerlang string) bool {
+	return lowerlang == "bun"
+}
+
+// ReadDefaults implements reporeader.VariableExtractor
+func (*BunExtractor) ReadDefaults(r reporeader.RepoReader) (map[string]string, error) {
+	extractedValues := make(map[string]string)
+	if r.Exists("package.json") {
+		content, err := r.ReadFile("package.json")
+		if err != nil {
+			return nil, fmt.Errorf("error reading package.json: %v", err)
+		}
+		var packageJSON struct {
+			Module  string            `json:"module"`
+			Main    string            `json:"main"`
+			Scripts map[string]string `json:"scripts"`
+		}
+		if err := json.Unmarshal(content, &packageJSON); err != nil {
+			log.Debugf("unable to parse package.json: %v", err)
+		} else {
+			for _, entrypoint := range []string{packageJSON.Module, packageJSON.Main, commandEntrypoint(packageJSON.Scripts["start"])} {
+				if entrypoint != "" {
+					extractedValues[EntrypointVariable] = strings.TrimPrefix(entrypoint, "./")
+					return extractedValues, nil
+				}
+			}
+		}
+	}
+
+	if entrypoint := firstExisting(r, bunEntrypoints); entrypoint != "" {
+		extractedValues[EntrypointVariable] = entrypoint
+	}
+	return extractedValues, nil
+}
+
+var _ reporeader.VariableExtractor = &BunExtractor{}
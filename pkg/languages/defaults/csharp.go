@@ -0,0 +1,133 @@
+package defaults
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+// Variables of the csharp pack read from the .csproj
+const (
+	CSharpAssemblyNameVariable   = "ASSEMBLYNAME"
+	CSharpPublishTrimmedVariable = "PUBLISHTRIMMED"
+)
+
+// webSdk is the project SDK of ASP.NET Core apps, preferred over the other projects of the repo root
+const webSdk = "Microsoft.NET.Sdk.Web"
+
+type csproj struct {
+	Sdk            string `xml:"Sdk,attr"`
+	PropertyGroups []struct {
+		TargetFramework  string `xml:"TargetFramework"`
+		TargetFrameworks string `xml:"TargetFrameworks"`
+		AssemblyName     string `xml:"AssemblyName"`
+		PublishTrimmed   string `xml:"PublishTrimmed"`
+	} `xml:"PropertyGroup"`
+}
+
+// CSharpExtractor reads the .NET version, assembly name and trimming of the .csproj in the repo root, preferring an
+// ASP.NET Core project when there are several
+type CSharpExtractor struct {
+}
+
+// GetName implements reporeader.VariableExtractor
+func (*CSharpExtractor) GetName() string {
+	return "csharp"
+}
+
+// MatchesLanguage implements reporeader.VariableExtractor
+func (*CSharpExtractor) MatchesLanguage(lowerlang string) bool {
+	return lowerlang == "csharp"
+}
+
+// ReadDefaults implements reporeader.VariableExtractor
+func (*CSharpExtractor) ReadDefaults(r reporeader.RepoReader) (map[string]string, error) {
+	extractedValues := make(map[string]string)
+	projects, err := r.FindFiles(".", []string{"*.csproj"}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error finding project files: %v", err)
+	}
+	if len(projects) == 0 {
+		return extractedValues, nil
+	}
+	sort.Strings(projects)
+	if len(projects) > 1 {
+		log.Infof("--> Draft detected the projects %s, the Dockerfile builds the project in the repo root", strings.Join(projects, ", "))
+	}
+
+	var project *csproj
+	var projectPath string
+	for _, p := range projects {
+		content, err := r.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", p, err)
+		}
+		parsed := &csproj{}
+		if err := xml.Unmarshal(content, parsed); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", p, err)
+		}
+		if project == nil || (project.Sdk != webSdk && parsed.Sdk == webSdk) {
+			project, projectPath = parsed, p
+		}
+	}
+
+	extractedValues[CSharpAssemblyNameVariable] = strings.TrimSuffix(path.Base(projectPath), ".csproj")
+	var frameworks []string
+	for _, group := range project.PropertyGroups {
+		if group.AssemblyName != "" {
+			extractedValues[CSharpAssemblyNameVariable] = strings.TrimSpace(group.AssemblyName)
+		}
+		if strings.EqualFold(strings.TrimSpace(group.PublishTrimmed), "true") {
+			extractedValues[CSharpPublishTrimmedVariable] = "true"
+		}
+		frameworks = append(frameworks, strings.Split(group.TargetFramework, ";")...)
+		frameworks = append(frameworks, strings.Split(group.TargetFrameworks, ";")...)
+	}
+	if version := newestDotnetVersion(frameworks); version != "" {
+		extractedValues["VERSION"] = version
+	}
+
+	return extractedValues, nil
+}
+
+// newestDotnetVersion returns the newest .NET version, such as 8.0, of the target framework monikers net8.0 or
+// netcoreapp3.1, ignoring .NET Framework and .NET Standard monikers
+func newestDotnetVersion(frameworks []string) string {
+	var newest string
+	var newestMajor, newestMinor int
+	for _, framework := range frameworks {
+		framework = strings.ToLower(strings.TrimSpace(framework))
+		// OS specific monikers such as net8.0-windows build for that OS
+		if i := strings.Index(framework, "-"); i >= 0 {
+			framework = framework[:i]
+		}
+		version, ok := strings.CutPrefix(framework, "netcoreapp")
+		if !ok {
+			if version, ok = strings.CutPrefix(framework, "net"); !ok || !strings.Contains(version, ".") {
+				continue
+			}
+		}
+		majorStr, minorStr, _ := strings.Cut(version, ".")
+		major, err := strconv.Atoi(majorStr)
+		if err != nil {
+			continue
+		}
+		minor, err := strconv.Atoi(minorStr)
+		if err != nil {
+			continue
+		}
+		if newest == "" || major > newestMajor || (major == newestMajor && minor > newestMinor) {
+			newest, newestMajor, newestMinor = version, major, minor
+		}
+	}
+	return newest
+}
+
+var _ reporeader.VariableExtractor = &CSharpExtractor{}
@@ -0,0 +1,71 @@
+package defaults
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+func TestCSharpExtractor_ReadDefaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string][]byte
+		want  map[string]string
+	}{
+		{
+			name: "minimal api",
+			files: map[string][]byte{
+				"Todo.Api.csproj": []byte(`<Project Sdk="Microsoft.NET.Sdk.Web">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <Nullable>enable</Nullable>
+  </PropertyGroup>
+</Project>
+`),
+			},
+			want: map[string]string{"ASSEMBLYNAME": "Todo.Api", "VERSION": "8.0"},
+		},
+		{
+			name: "assembly name, trimming and several target frameworks",
+			files: map[string][]byte{
+				"api.csproj": []byte(`<Project Sdk="Microsoft.NET.Sdk.Web">
+  <PropertyGroup>
+    <TargetFrameworks>net6.0;net8.0;netstandard2.0</TargetFrameworks>
+    <AssemblyName>Api</AssemblyName>
+  </PropertyGroup>
+  <PropertyGroup Condition="'$(Configuration)' == 'Release'">
+    <PublishTrimmed>True</PublishTrimmed>
+  </PropertyGroup>
+</Project>
+`),
+			},
+			want: map[string]string{"ASSEMBLYNAME": "Api", "VERSION": "8.0", "PUBLISHTRIMMED": "true"},
+		},
+		{
+			name: "web project preferred",
+			files: map[string][]byte{
+				"Lib.csproj": []byte(`<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><TargetFramework>net9.0</TargetFramework></PropertyGroup></Project>`),
+				"Web.csproj": []byte(`<Project Sdk="Microsoft.NET.Sdk.Web"><PropertyGroup><TargetFramework>netcoreapp3.1</TargetFramework></PropertyGroup></Project>`),
+			},
+			want: map[string]string{"ASSEMBLYNAME": "Web", "VERSION": "3.1"},
+		},
+		{
+			name:  "no project",
+			files: map[string][]byte{"Program.cs": []byte("")},
+			want:  map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&CSharpExtractor{}).ReadDefaults(reporeader.FakeRepoReader{Files: tt.files})
+			if err != nil {
+				t.Errorf("ReadDefaults() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadDefaults() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package defaults
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+// EntrypointVariable is the variable of the deno and bun packs naming the module that starts the application
+const EntrypointVariable = "ENTRYPOINT"
+
+var (
+	// denoConfigFiles are the config files of deno apps, read in order
+	denoConfigFiles = []string{"deno.json", "deno.jsonc"}
+	// denoEntrypoints are the conventional entrypoints of deno apps, looked for when the start task doesn't name one
+	denoEntrypoints = []string{"main.ts", "mod.ts", "server.ts", "src/main.ts", "main.js"}
+	// scriptExtensions are the extensions of the modules deno and bun run
+	scriptExtensions = []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs"}
+)
+
+// DenoExtractor reads the entrypoint of a deno app from the start task of its deno.json
+type DenoExtractor struct {
+}
+
+// GetName implements reporeader.VariableExtractor
+func (*DenoExtractor) GetName() string {
+	return "deno"
+}
+
+// MatchesLanguage implements reporeader.VariableExtractor
+func (*DenoExtractor) MatchesLanguage(lowerlang string) bool {
+	return lowerlang == "deno"
+}
+
+// ReadDefaults implements reporeader.VariableExtractor
+func (*DenoExtractor) ReadDefaults(r reporeader.RepoReader) (map[string]string, error) {
+	extractedValues := make(map[string]string)
+	for _, configFile := range denoConfigFiles {
+		if !r.Exists(configFile) {
+			continue
+		}
+		content, err := r.ReadFile(configFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", configFile, err)
+		}
+		var denoConfig struct {
+			// Tasks are commands, or objects holding the command in newer versions of deno
+			Tasks map[string]interface{} `json:"tasks"`
+		}
+		if err := json.Unmarshal(stripJSONComments(content), &denoConfig); err != nil {
+			log.Debugf("unable to parse %s: %v", configFile, err)
+			break
+		}
+		task := denoConfig.Tasks["start"]
+		if object, ok := task.(map[string]interface{}); ok {
+			task = object["command"]
+		}
+		if command, ok := task.(string); ok {
+			if entrypoint := commandEntrypoint(command); entrypoint != "" {
+				extractedValues[EntrypointVariable] = entrypoint
+				return extractedValues, nil
+			}
+		}
+		break
+	}
+
+	if entrypoint := firstExisting(r, denoEntrypoints); entrypoint != "" {
+		extractedValues[EntrypointVariable] = entrypoint
+	}
+	return extractedValues, nil
+}
+
+// commandEntrypoint returns the first module a command such as "deno run --allow-net main.ts" runs
+func commandEntrypoint(command string) string {
+	for _, arg := range strings.Fields(command) {
+		arg = strings.TrimPrefix(arg, "./")
+		for _, ext := range scriptExtensions {
+			if path.Ext(arg) == ext {
+				return arg
+			}
+		}
+	}
+	return ""
+}
+
+// firstExisting returns the first of files that exists in the repo
+func firstExisting(r reporeader.RepoReader, files []string) string {
+	for _, file := range files {
+		if r.Exists(file) {
+			return file
+		}
+	}
+	return ""
+}
+
+// stripJSONComments removes the whole line comments of a JSON with comments file such as deno.jsonc
+func stripJSONComments(content []byte) []byte {
+	lines := strings.Split(string(content), "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(line), "//") {
+			kept = append(kept, line)
+		}
+	}
+	return []byte(strings.Join(kept, "\n"))
+}
+
+var _ reporeader.VariableExtractor = &DenoExtractor{}
@@ -0,0 +1,96 @@
+package defaults
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+func TestDenoExtractor_ReadDefaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string][]byte
+		want  map[string]string
+	}{
+		{
+			name: "start task",
+			files: map[string][]byte{
+				"deno.json":     []byte(`{"tasks": {"start": "deno run --allow-net ./src/server.ts"}}`),
+				"src/server.ts": []byte(""),
+			},
+			want: map[string]string{"ENTRYPOINT": "src/server.ts"},
+		},
+		{
+			name: "start task object in deno.jsonc",
+			files: map[string][]byte{
+				"deno.jsonc": []byte("{\n  // tasks run with deno task\n  \"tasks\": {\"start\": {\"command\": \"deno run -A app.ts\"}}\n}\n"),
+			},
+			want: map[string]string{"ENTRYPOINT": "app.ts"},
+		},
+		{
+			name: "conventional entrypoint",
+			files: map[string][]byte{
+				"deno.json": []byte(`{"imports": {"@std/http": "jsr:@std/http@1"}}`),
+				"mod.ts":    []byte(""),
+			},
+			want: map[string]string{"ENTRYPOINT": "mod.ts"},
+		},
+		{
+			name:  "no entrypoint",
+			files: map[string][]byte{"deno.json": []byte(`{}`)},
+			want:  map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&DenoExtractor{}).ReadDefaults(reporeader.FakeRepoReader{Files: tt.files})
+			if err != nil {
+				t.Errorf("ReadDefaults() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadDefaults() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBunExtractor_ReadDefaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string][]byte
+		want  map[string]string
+	}{
+		{
+			name:  "module",
+			files: map[string][]byte{"package.json": []byte(`{"name": "api", "module": "./src/index.ts"}`)},
+			want:  map[string]string{"ENTRYPOINT": "src/index.ts"},
+		},
+		{
+			name:  "start script",
+			files: map[string][]byte{"package.json": []byte(`{"scripts": {"start": "bun run server.tsx"}}`)},
+			want:  map[string]string{"ENTRYPOINT": "server.tsx"},
+		},
+		{
+			name: "conventional entrypoint",
+			files: map[string][]byte{
+				"package.json": []byte(`{"name": "api"}`),
+				"index.ts":     []byte(""),
+			},
+			want: map[string]string{"ENTRYPOINT": "index.ts"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&BunExtractor{}).ReadDefaults(reporeader.FakeRepoReader{Files: tt.files})
+			if err != nil {
+				t.Errorf("ReadDefaults() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadDefaults() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package defaults
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/mod/modfile"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+// Variables of the gomodule pack selecting what to build in multi-module repos
+const (
+	GoModulePathVariable = "MODULEPATH"
+	GoBuildPathVariable  = "BUILDPATH"
+)
+
+// goModuleSearchDepth is how deep in the repo go.mod files are searched for when there is no go.work
+const goModuleSearchDepth = 3
+
+type GoModuleExtractor struct {
+}
+
+// GetName implements reporeader.VariableExtractor
+func (*GoModuleExtractor) GetName() string {
+	return "gomodule"
+}
+
+// MatchesLanguage implements reporeader.VariableExtractor
+func (*GoModuleExtractor) MatchesLanguage(lowerlang string) bool {
+	return lowerlang == "gomodule"
+}
+
+// ReadDefaults implements reporeader.VariableExtractor. It defaults the module to build to the root module, or the
+// first module of the workspace or repo, and the package to build to the module root or its first main package.
+func (*GoModuleExtractor) ReadDefaults(r reporeader.RepoReader) (map[string]string, error) {
+	extractedValues := make(map[string]string)
+
+	modules, err := GoModules(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(modules) == 0 {
+		return extractedValues, nil
+	}
+	if len(modules) > 1 {
+		log.Infof("--> Draft detected the go modules %s, set %s to choose the module to build", strings.Join(modules, ", "), GoModulePathVariable)
+	}
+
+	module := modules[0]
+	for _, m := range modules {
+		if m == "." {
+			module = m
+		}
+	}
+	extractedValues[GoModulePathVariable] = module
+
+	mains, err := goMainPackages(r, module)
+	if err != nil {
+		return nil, err
+	}
+	if len(mains) > 0 {
+		extractedValues[GoBuildPathVariable] = mains[0]
+	}
+	if len(mains) > 1 {
+		log.Infof("--> Draft detected the main packages %s in module %s, set %s to choose the package to build", strings.Join(mains, ", "), module, GoBuildPathVariable)
+	}
+
+	return extractedValues, nil
+}
+
+// GoModules returns the directories of the go modules in the repo, relative to its root. The modules used by a go.work
+// file take precedence over the go.mod files found in the repo.
+func GoModules(r reporeader.RepoReader) ([]string, error) {
+	if r.Exists("go.work") {
+		content, err := r.ReadFile("go.work")
+		if err != nil {
+			return nil, fmt.Errorf("error reading go.work: %v", err)
+		}
+		work, err := modfile.ParseWork("go.work", content, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing go.work: %v", err)
+		}
+		var modules []string
+		for _, use := range work.Use {
+			modules = append(modules, path.Clean(use.Path))
+		}
+		return modules, nil
+	}
+
+	files, err := r.FindFiles(".", []string{"go.mod"}, goModuleSearchDepth)
+	if err != nil {
+		return nil, fmt.Errorf("error finding go.mod files: %v", err)
+	}
+	var modules []string
+	for _, f := range files {
+		dir := path.Dir(filepath.ToSlash(f))
+		if isIgnoredGoDir(dir) {
+			continue
+		}
+		modules = append(modules, dir)
+	}
+	sort.Strings(modules)
+	return modules, nil
+}
+
+// goMainPackages returns the packages of module that have a main.go, relative to the module, with the module root first
+func goMainPackages(r reporeader.RepoReader, module string) ([]string, error) {
+	files, err := r.FindFiles(".", []string{"main.go"}, goModuleSearchDepth+2)
+	if err != nil {
+		return nil, fmt.Errorf("error finding main.go files: %v", err)
+	}
+	var mains []string
+	for _, f := range files {
+		rel := path.Dir(filepath.ToSlash(f))
+		if module != "." {
+			var ok bool
+			if rel, ok = strings.CutPrefix(rel+"/", module+"/"); !ok {
+				continue
+			}
+			rel = path.Clean(strings.TrimSuffix(rel, "/"))
+		}
+		if isIgnoredGoDir(rel) {
+			continue
+		}
+		if rel != "." {
+			rel = "./" + rel
+		}
+		mains = append(mains, rel)
+	}
+	sort.Slice(mains, func(i, j int) bool {
+		if mains[i] == "." || mains[j] == "." {
+			return mains[i] == "."
+		}
+		return mains[i] < mains[j]
+	})
+	return mains, nil
+}
+
+func isIgnoredGoDir(dir string) bool {
+	for _, part := range strings.Split(dir, "/") {
+		if part == "vendor" || part == "testdata" {
+			return true
+		}
+	}
+	return false
+}
+
+var _ reporeader.VariableExtractor = &GoModuleExtractor{}
@@ -0,0 +1,74 @@
+package defaults
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+func TestGoModuleExtractor_ReadDefaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string][]byte
+		want  map[string]string
+	}{
+		{
+			name: "single module",
+			files: map[string][]byte{
+				"go.mod":  []byte("module example.com/app\n"),
+				"main.go": []byte("package main\n"),
+			},
+			want: map[string]string{"MODULEPATH": ".", "BUILDPATH": "."},
+		},
+		{
+			name: "single module with cmd packages",
+			files: map[string][]byte{
+				"go.mod":                []byte("module example.com/app\n"),
+				"cmd/worker/main.go":    []byte("package main\n"),
+				"cmd/server/main.go":    []byte("package main\n"),
+				"vendor/x/cmd/main.go":  []byte("package main\n"),
+				"internal/lib/lib.go":   []byte("package lib\n"),
+				"testdata/tool/main.go": []byte("package main\n"),
+			},
+			want: map[string]string{"MODULEPATH": ".", "BUILDPATH": "./cmd/server"},
+		},
+		{
+			name: "workspace",
+			files: map[string][]byte{
+				"go.work":                      []byte("go 1.22\n\nuse (\n\t./services/api\n\t./libs/shared\n)\n"),
+				"services/api/go.mod":          []byte("module example.com/api\n"),
+				"services/api/cmd/api/main.go": []byte("package main\n"),
+				"libs/shared/go.mod":           []byte("module example.com/shared\n"),
+				"tools/go.mod":                 []byte("module example.com/tools\n"),
+			},
+			want: map[string]string{"MODULEPATH": "services/api", "BUILDPATH": "./cmd/api"},
+		},
+		{
+			name: "nested modules without workspace",
+			files: map[string][]byte{
+				"svc/b/go.mod":  []byte("module example.com/b\n"),
+				"svc/a/go.mod":  []byte("module example.com/a\n"),
+				"svc/a/main.go": []byte("package main\n"),
+			},
+			want: map[string]string{"MODULEPATH": "svc/a", "BUILDPATH": "."},
+		},
+		{
+			name:  "no module",
+			files: map[string][]byte{"main.go": []byte("package main\n")},
+			want:  map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&GoModuleExtractor{}).ReadDefaults(reporeader.FakeRepoReader{Files: tt.files})
+			if err != nil {
+				t.Errorf("ReadDefaults() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadDefaults() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
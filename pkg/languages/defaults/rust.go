@@ -0,0 +1,171 @@
+package defaults
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+// RustBinaryNameVariable is the variable of the rust pack naming the binary target to build
+const RustBinaryNameVariable = "BINARYNAME"
+
+// rustCrateSearchDepth is how deep in the repo the Cargo.toml of workspace members are searched for
+const rustCrateSearchDepth = 3
+
+// rustEditionVersions are the rust versions that editions newer than the pack's default version need
+var rustEditionVersions = map[string]string{
+	"2024": "1.85",
+}
+
+type cargoManifest struct {
+	Package *struct {
+		Name string `toml:"name"`
+		// Edition and RustVersion are tables such as {workspace = true} when inherited from the workspace
+		Edition     interface{} `toml:"edition"`
+		RustVersion interface{} `toml:"rust-version"`
+	} `toml:"package"`
+	Bin []struct {
+		Name string `toml:"name"`
+	} `toml:"bin"`
+	Workspace *struct {
+		Members []string `toml:"members"`
+		Package struct {
+			Edition     string `toml:"edition"`
+			RustVersion string `toml:"rust-version"`
+		} `toml:"package"`
+	} `toml:"workspace"`
+}
+
+// RustExtractor reads the binary to build and the rust version it needs from Cargo.toml, looking through the members
+// of a cargo workspace for a binary when the root manifest doesn't have one
+type RustExtractor struct {
+}
+
+// GetName implements reporeader.VariableExtractor
+func (*RustExtractor) GetName() string {
+	return "rust"
+}
+
+// MatchesLanguage implements reporeader.VariableExtractor
+func (*RustExtractor) MatchesLanguage(lowerlang string) bool {
+	return lowerlang == "rust"
+}
+
+// ReadDefaults implements reporeader.VariableExtractor
+func (*RustExtractor) ReadDefaults(r reporeader.RepoReader) (map[string]string, error) {
+	extractedValues := make(map[string]string)
+	root, err := readCargoManifest(r, "Cargo.toml")
+	if err != nil || root == nil {
+		return extractedValues, err
+	}
+
+	manifest, binaries := root, cargoBinaries(r, ".", root)
+	if len(binaries) == 0 && root.Workspace != nil {
+		members, err := cargoWorkspaceMembers(r, root.Workspace.Members)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			memberManifest, err := readCargoManifest(r, path.Join(member, "Cargo.toml"))
+			if err != nil {
+				return nil, err
+			}
+			if memberBinaries := cargoBinaries(r, member, memberManifest); len(memberBinaries) > 0 {
+				if len(binaries) == 0 {
+					manifest = memberManifest
+				}
+				binaries = append(binaries, memberBinaries...)
+			}
+		}
+	}
+	if len(binaries) > 1 {
+		log.Infof("--> Draft detected the rust binaries %s, set %s to choose the binary to build", strings.Join(binaries, ", "), RustBinaryNameVariable)
+	}
+	if len(binaries) > 0 {
+		extractedValues[RustBinaryNameVariable] = binaries[0]
+	}
+
+	var edition, rustVersion string
+	if manifest.Package != nil {
+		edition, _ = manifest.Package.Edition.(string)
+		rustVersion, _ = manifest.Package.RustVersion.(string)
+	}
+	if root.Workspace != nil {
+		if edition == "" {
+			edition = root.Workspace.Package.Edition
+		}
+		if rustVersion == "" {
+			rustVersion = root.Workspace.Package.RustVersion
+		}
+	}
+	if rustVersion != "" {
+		extractedValues["VERSION"] = rustVersion
+	} else if version, ok := rustEditionVersions[edition]; ok {
+		extractedValues["VERSION"] = version
+	}
+
+	return extractedValues, nil
+}
+
+// readCargoManifest parses the Cargo.toml at manifestPath, returning nil when there is none
+func readCargoManifest(r reporeader.RepoReader, manifestPath string) (*cargoManifest, error) {
+	if !r.Exists(manifestPath) {
+		return nil, nil
+	}
+	content, err := r.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &cargoManifest{}
+	if err := toml.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", manifestPath, err)
+	}
+	return manifest, nil
+}
+
+// cargoBinaries returns the binary targets of the crate in dir: its [[bin]] targets, or the package itself when it
+// has a src/main.rs
+func cargoBinaries(r reporeader.RepoReader, dir string, manifest *cargoManifest) []string {
+	var binaries []string
+	if manifest == nil {
+		return binaries
+	}
+	for _, bin := range manifest.Bin {
+		if bin.Name != "" {
+			binaries = append(binaries, bin.Name)
+		}
+	}
+	if len(binaries) == 0 && manifest.Package != nil && r.Exists(path.Join(dir, "src", "main.rs")) {
+		binaries = append(binaries, manifest.Package.Name)
+	}
+	return binaries
+}
+
+// cargoWorkspaceMembers returns the sorted directories of the crates matching the workspace member patterns
+func cargoWorkspaceMembers(r reporeader.RepoReader, patterns []string) ([]string, error) {
+	manifests, err := r.FindFiles(".", []string{"Cargo.toml"}, rustCrateSearchDepth)
+	if err != nil {
+		return nil, err
+	}
+	var members []string
+	for _, manifest := range manifests {
+		dir := filepath.ToSlash(filepath.Dir(manifest))
+		for _, pattern := range patterns {
+			if matched, err := path.Match(strings.TrimSuffix(pattern, "/"), dir); err != nil {
+				return nil, fmt.Errorf("invalid workspace member %q: %w", pattern, err)
+			} else if matched {
+				members = append(members, dir)
+				break
+			}
+		}
+	}
+	sort.Strings(members)
+	return members, nil
+}
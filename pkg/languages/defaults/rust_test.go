@@ -0,0 +1,69 @@
+package defaults
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+func TestRustExtractor_ReadDefaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string][]byte
+		want  map[string]string
+	}{
+		{
+			name: "package binary",
+			files: map[string][]byte{
+				"Cargo.toml":  []byte("[package]\nname = \"hello\"\nedition = \"2021\"\n"),
+				"src/main.rs": []byte("fn main() {}\n"),
+			},
+			want: map[string]string{"BINARYNAME": "hello"},
+		},
+		{
+			name: "bin targets and rust-version",
+			files: map[string][]byte{
+				"Cargo.toml": []byte("[package]\nname = \"tools\"\nedition = \"2021\"\nrust-version = \"1.74\"\n\n[[bin]]\nname = \"server\"\npath = \"src/server.rs\"\n\n[[bin]]\nname = \"cli\"\npath = \"src/cli.rs\"\n"),
+			},
+			want: map[string]string{"BINARYNAME": "server", "VERSION": "1.74"},
+		},
+		{
+			name: "2024 edition",
+			files: map[string][]byte{
+				"Cargo.toml":  []byte("[package]\nname = \"hello\"\nedition = \"2024\"\n"),
+				"src/main.rs": []byte("fn main() {}\n"),
+			},
+			want: map[string]string{"BINARYNAME": "hello", "VERSION": "1.85"},
+		},
+		{
+			name: "workspace",
+			files: map[string][]byte{
+				"Cargo.toml":               []byte("[workspace]\nmembers = [\"crates/*\", \"services/api\"]\n\n[workspace.package]\nedition = \"2024\"\n"),
+				"crates/core/Cargo.toml":   []byte("[package]\nname = \"core\"\nedition.workspace = true\n"),
+				"crates/core/src/lib.rs":   []byte("pub fn f() {}\n"),
+				"services/api/Cargo.toml":  []byte("[package]\nname = \"api\"\nedition.workspace = true\n"),
+				"services/api/src/main.rs": []byte("fn main() {}\n"),
+				"examples/demo/Cargo.toml": []byte("[package]\nname = \"demo\"\n"),
+			},
+			want: map[string]string{"BINARYNAME": "api", "VERSION": "1.85"},
+		},
+		{
+			name:  "no cargo manifest",
+			files: map[string][]byte{"main.rs": []byte("fn main() {}\n")},
+			want:  map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&RustExtractor{}).ReadDefaults(reporeader.FakeRepoReader{Files: tt.files})
+			if err != nil {
+				t.Errorf("ReadDefaults() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadDefaults() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
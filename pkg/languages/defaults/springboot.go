@@ -0,0 +1,116 @@
+package defaults
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+const (
+	springBootMavenPlugin = "spring-boot-maven-plugin"
+	springBootGroup       = "org.springframework.boot"
+	// legacyLauncherClass is the Spring Boot launcher class used before Spring Boot 3.2
+	legacyLauncherClass = "org.springframework.boot.loader.JarLauncher"
+)
+
+var (
+	mavenBootParentVersionRegex = regexp.MustCompile(`<artifactId>\s*spring-boot-starter-parent\s*</artifactId>\s*<version>\s*([^<\s]+)\s*</version>`)
+	mavenJavaVersionRegex       = regexp.MustCompile(`<java\.version>\s*(\d+)\s*</java\.version>`)
+	gradleBootVersionRegex      = regexp.MustCompile(`id\s*\(?\s*['"]org\.springframework\.boot['"]\s*\)?\s*version\s*['"]([^'"]+)['"]`)
+	gradleJavaVersionRegex      = regexp.MustCompile(`(?:JavaLanguageVersion\.of\(\s*|JavaVersion\.VERSION_|sourceCompatibility\s*=\s*['"]?)(\d+)`)
+	springBootPackVariants      = map[string]string{
+		"java":    "javaspringboot",
+		"gradle":  "gradlespringboot",
+		"gradlew": "gradlespringboot",
+	}
+)
+
+// SpringBootExtractor reads defaults for the Spring Boot layered jar packs from maven and gradle build files
+type SpringBootExtractor struct {
+}
+
+// GetName implements reporeader.VariableExtractor
+func (*SpringBootExtractor) GetName() string {
+	return "springboot"
+}
+
+// MatchesLanguage implements reporeader.VariableExtractor
+func (*SpringBootExtractor) MatchesLanguage(lowerlang string) bool {
+	return lowerlang == "javaspringboot" || lowerlang == "gradlespringboot"
+}
+
+// ReadDefaults implements reporeader.VariableExtractor
+func (*SpringBootExtractor) ReadDefaults(r reporeader.RepoReader) (map[string]string, error) {
+	extractedValues := make(map[string]string)
+	content, isSpringBoot, err := readSpringBootBuildFile(r)
+	if err != nil || !isSpringBoot {
+		return extractedValues, err
+	}
+
+	var bootVersion, javaVersion string
+	if m := mavenBootParentVersionRegex.FindStringSubmatch(content); m != nil {
+		bootVersion = m[1]
+	} else if m := gradleBootVersionRegex.FindStringSubmatch(content); m != nil {
+		bootVersion = m[1]
+	}
+	if m := mavenJavaVersionRegex.FindStringSubmatch(content); m != nil {
+		javaVersion = m[1]
+	} else if m := gradleJavaVersionRegex.FindStringSubmatch(content); m != nil {
+		javaVersion = m[1]
+	}
+
+	if javaVersion != "" {
+		extractedValues["JDKVERSION"] = javaVersion + "-jdk"
+	}
+	if bootVersion != "" {
+		v, err := version.NewVersion(bootVersion)
+		if err != nil {
+			log.Debugf("unable to parse spring boot version %s: %v", bootVersion, err)
+		} else if v.LessThan(version.Must(version.NewVersion("3.2"))) {
+			extractedValues["LAUNCHERCLASS"] = legacyLauncherClass
+		}
+	}
+
+	return extractedValues, nil
+}
+
+// IsSpringBootProject returns whether the maven or gradle build file in the repo root applies the Spring Boot plugin
+func IsSpringBootProject(r reporeader.RepoReader) bool {
+	_, isSpringBoot, err := readSpringBootBuildFile(r)
+	if err != nil {
+		log.Debugf("unable to detect spring boot: %v", err)
+		return false
+	}
+	return isSpringBoot
+}
+
+// SpringBootPackVariant returns the Spring Boot pack to use in place of lowerLang, if there is one
+func SpringBootPackVariant(lowerLang string) (string, bool) {
+	variant, ok := springBootPackVariants[lowerLang]
+	return variant, ok
+}
+
+func readSpringBootBuildFile(r reporeader.RepoReader) (string, bool, error) {
+	files, err := r.FindFiles(".", []string{"pom.xml", "build.gradle", "build.gradle.kts"}, 0)
+	if err != nil {
+		return "", false, fmt.Errorf("error finding build files: %v", err)
+	}
+	for _, file := range files {
+		content, err := r.ReadFile(file)
+		if err != nil {
+			return "", false, fmt.Errorf("error reading %s: %v", file, err)
+		}
+		contentString := string(content)
+		if strings.Contains(contentString, springBootMavenPlugin) || strings.Contains(contentString, springBootGroup) {
+			return contentString, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+var _ reporeader.VariableExtractor = &SpringBootExtractor{}
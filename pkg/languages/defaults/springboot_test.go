@@ -0,0 +1,80 @@
+package defaults
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+func TestSpringBootExtractor_ReadDefaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string][]byte
+		want  map[string]string
+	}{
+		{
+			name: "maven spring boot 3.3",
+			files: map[string][]byte{
+				"pom.xml": []byte(`<parent>
+		<groupId>org.springframework.boot</groupId>
+		<artifactId>spring-boot-starter-parent</artifactId>
+		<version>3.3.0</version>
+	</parent>
+	<properties><java.version>17</java.version></properties>
+	<build><plugins><plugin><artifactId>spring-boot-maven-plugin</artifactId></plugin></plugins></build>`),
+			},
+			want: map[string]string{"JDKVERSION": "17-jdk"},
+		},
+		{
+			name: "maven spring boot 2.7 uses the legacy launcher",
+			files: map[string][]byte{
+				"pom.xml": []byte(`<artifactId>spring-boot-starter-parent</artifactId><version>2.7.18</version>
+	<artifactId>spring-boot-maven-plugin</artifactId>`),
+			},
+			want: map[string]string{"LAUNCHERCLASS": legacyLauncherClass},
+		},
+		{
+			name: "gradle kotlin dsl",
+			files: map[string][]byte{
+				"build.gradle.kts": []byte(`plugins {
+	id("org.springframework.boot") version "3.1.5"
+}
+java { toolchain { languageVersion = JavaLanguageVersion.of(21) } }`),
+			},
+			want: map[string]string{"JDKVERSION": "21-jdk", "LAUNCHERCLASS": legacyLauncherClass},
+		},
+		{
+			name:  "not spring boot",
+			files: map[string][]byte{"pom.xml": []byte(`<project><java.version>17</java.version></project>`)},
+			want:  map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&SpringBootExtractor{}).ReadDefaults(reporeader.FakeRepoReader{Files: tt.files})
+			if err != nil {
+				t.Errorf("ReadDefaults() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadDefaults() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSpringBootProject(t *testing.T) {
+	if !IsSpringBootProject(reporeader.FakeRepoReader{Files: map[string][]byte{"build.gradle": []byte("id 'org.springframework.boot' version '3.2.0'")}}) {
+		t.Error("expected gradle spring boot project to be detected")
+	}
+	if IsSpringBootProject(reporeader.FakeRepoReader{Files: map[string][]byte{"nested/pom.xml": []byte(springBootMavenPlugin)}}) {
+		t.Error("expected only root build files to be considered")
+	}
+	if variant, ok := SpringBootPackVariant("gradlew"); !ok || variant != "gradlespringboot" {
+		t.Errorf("unexpected variant %s for gradlew", variant)
+	}
+	if _, ok := SpringBootPackVariant("python"); ok {
+		t.Error("python should not have a spring boot variant")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io/fs"
 	"path"
+	"sort"
+	"sync"
 
 	"golang.org/x/exp/maps"
 	"gopkg.in/yaml.v3"
@@ -23,16 +25,23 @@ var (
 	parentDirName = "dockerfiles"
 )
 
+// HardenedVariable builds the Dockerfile's image to run as a non-root user with a read-only root file system, on a
+// distroless base where the language has one
+const HardenedVariable = "HARDENED"
+
 type Languages struct {
 	langs               map[string]fs.DirEntry
 	configs             map[string]*config.DraftConfig
 	dest                string
 	dockerfileTemplates fs.FS
+	// packTemplates holds the templates of packs added with AddPacks, which take precedence over dockerfileTemplates
+	packTemplates map[string]fs.FS
 }
 
-// Names returns a slice of the names of the supported languages
+// Names returns the sorted names of the supported languages
 func (l *Languages) Names() []string {
 	names := maps.Keys(l.langs)
+	sort.Strings(names)
 	return names
 }
 
@@ -41,6 +50,14 @@ func (l *Languages) ContainsLanguage(lang string) bool {
 	return ok
 }
 
+// templatesFor returns the filesystem holding the Dockerfile templates of lang
+func (l *Languages) templatesFor(lang string) fs.FS {
+	if fsys, ok := l.packTemplates[lang]; ok {
+		return fsys
+	}
+	return l.dockerfileTemplates
+}
+
 func (l *Languages) CreateDockerfileForLanguage(lang string, customInputs map[string]string, templateWriter templatewriter.TemplateWriter) error {
 	val, ok := l.langs[lang]
 	if !ok {
@@ -56,13 +73,45 @@ func (l *Languages) CreateDockerfileForLanguage(lang string, customInputs map[st
 		draftConfig.ApplyDefaultVariables(customInputs)
 	}
 
-	if err := osutil.CopyDir(l.dockerfileTemplates, srcDir, l.dest, draftConfig, customInputs, templateWriter); err != nil {
+	if err := applyServerFlavor(lang, customInputs); err != nil {
+		return err
+	}
+
+	if err := osutil.CopyDir(l.templatesFor(lang), srcDir, l.dest, draftConfig, customInputs, templateWriter); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// Versions returns the embedded versions of the Dockerfile template for lang, newest first
+func (l *Languages) Versions(lang string) ([]string, error) {
+	if _, ok := l.langs[lang]; !ok {
+		return nil, fmt.Errorf("language %s is not supported", lang)
+	}
+	return embedutils.TemplateVersions(l.templatesFor(lang), parentDirName, lang, l.configs[lang].Version)
+}
+
+// UseVersion makes lang generate its Dockerfile from the embedded template at templateVersion
+func (l *Languages) UseVersion(lang, templateVersion string) error {
+	val, ok := l.langs[lang]
+	if !ok {
+		return fmt.Errorf("language %s is not supported", lang)
+	}
+	dir, err := embedutils.ResolveVersionedDir(l.templatesFor(lang), parentDirName, lang, val, l.configs[lang].Version, templateVersion)
+	if err != nil {
+		return err
+	}
+
+	l.langs[lang] = dir
+	draftConfig, err := l.loadConfig(lang)
+	if err != nil {
+		return fmt.Errorf("loading version %s of language %s: %w", templateVersion, lang, err)
+	}
+	l.configs[lang] = draftConfig
+	return nil
+}
+
 func (l *Languages) loadConfig(lang string) (*config.DraftConfig, error) {
 	val, ok := l.langs[lang]
 	if !ok {
@@ -70,7 +119,7 @@ func (l *Languages) loadConfig(lang string) (*config.DraftConfig, error) {
 	}
 
 	configPath := path.Join(parentDirName, val.Name(), "/draft.yaml")
-	configBytes, err := fs.ReadFile(l.dockerfileTemplates, configPath)
+	configBytes, err := fs.ReadFile(l.templatesFor(lang), configPath)
 	if err != nil {
 		return nil, err
 	}
@@ -103,45 +152,129 @@ func (l *Languages) PopulateConfigs() {
 }
 
 func CreateLanguagesFromEmbedFS(dockerfileTemplates embed.FS, dest string) *Languages {
-	langMap, err := embedutils.EmbedFStoMap(dockerfileTemplates, parentDirName)
+	l, err := CreateLanguagesFromFS(embedutils.WithTemplateDir(dockerfileTemplates), dest)
 	if err != nil {
 		log.Fatal(err)
 	}
+	return l
+}
+
+// CreateLanguagesFromFS loads the language packs in the dockerfiles directory of dockerfileTemplates, which can be an
+// on-disk directory such as os.DirFS("my-templates") laid out like draft's template directory
+func CreateLanguagesFromFS(dockerfileTemplates fs.FS, dest string) (*Languages, error) {
+	langMap, err := embedutils.EmbedFStoMap(dockerfileTemplates, parentDirName)
+	if err != nil {
+		return nil, err
+	}
 
 	l := &Languages{
 		langs:               langMap,
 		dest:                dest,
 		configs:             make(map[string]*config.DraftConfig),
 		dockerfileTemplates: dockerfileTemplates,
+		packTemplates:       make(map[string]fs.FS),
 	}
 	l.PopulateConfigs()
 
-	return l
+	return l, nil
 }
 
-func (l *Languages) ExtractDefaults(lowerLang string, r reporeader.RepoReader) (map[string]string, error) {
-	extractors := []reporeader.VariableExtractor{
+// AddPacks adds the language packs of other to l, replacing the packs of l with the same name
+func (l *Languages) AddPacks(other *Languages) {
+	for lang, dir := range other.langs {
+		if _, ok := l.langs[lang]; ok {
+			log.Debugf("language pack %s overrides the embedded pack", lang)
+		}
+		l.langs[lang] = dir
+		l.configs[lang] = other.configs[lang]
+		if l.packTemplates == nil {
+			l.packTemplates = make(map[string]fs.FS)
+		}
+		l.packTemplates[lang] = other.templatesFor(lang)
+	}
+}
+
+// extractors read the defaults of the variables of the language packs from the repo
+func extractors() []reporeader.VariableExtractor {
+	return []reporeader.VariableExtractor{
 		&defaults.PythonExtractor{},
 		&defaults.GradleExtractor{},
+		&defaults.SpringBootExtractor{},
+		&defaults.AzureFunctionsExtractor{},
+		&defaults.GoModuleExtractor{},
+		&defaults.RustExtractor{},
+		&defaults.CSharpExtractor{},
+		&defaults.DenoExtractor{},
+		&defaults.BunExtractor{},
 	}
-	extractedValues := make(map[string]string)
+}
+
+func (l *Languages) ExtractDefaults(lowerLang string, r reporeader.RepoReader) (map[string]string, error) {
 	if r == nil {
 		log.Debugf("no repo reader provided, returning empty list of defaults")
-		return extractedValues, nil
+		return make(map[string]string), nil
 	}
-	for _, extractor := range extractors {
+	var matching []reporeader.VariableExtractor
+	for _, extractor := range extractors() {
 		if extractor.MatchesLanguage(lowerLang) {
-			newDefaults, err := extractor.ReadDefaults(r)
-			if err != nil {
-				return nil, fmt.Errorf("error reading defaults for language %s: %v", lowerLang, err)
-			}
-			for k, v := range newDefaults {
-				if _, ok := extractedValues[k]; ok {
-					log.Debugf("duplicate default %s for language %s with extractor %s", k, lowerLang, extractor.GetName())
-				}
-				extractedValues[k] = v
-				log.Debugf("extracted default %s=%s with extractor:%s", k, v, extractor.GetName())
+			matching = append(matching, extractor)
+		}
+	}
+	return ExtractAllDefaults(r, matching...).For(lowerLang)
+}
+
+// ExtractedDefaults are the defaults read by extractors, keyed by extractor name, to look up once the language is known
+type ExtractedDefaults struct {
+	extractors []reporeader.VariableExtractor
+	values     map[string]map[string]string
+	errs       map[string]error
+}
+
+// ExtractAllDefaults runs the extractors, every registered one when none are given, concurrently, so the defaults of
+// the repo can be read while its language is still being detected
+func ExtractAllDefaults(r reporeader.RepoReader, extractorsToRun ...reporeader.VariableExtractor) *ExtractedDefaults {
+	if len(extractorsToRun) == 0 {
+		extractorsToRun = extractors()
+	}
+	e := &ExtractedDefaults{
+		extractors: extractorsToRun,
+		values:     make(map[string]map[string]string),
+		errs:       make(map[string]error),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, extractor := range extractorsToRun {
+		wg.Add(1)
+		go func(extractor reporeader.VariableExtractor) {
+			defer wg.Done()
+			values, err := extractor.ReadDefaults(r)
+			mu.Lock()
+			defer mu.Unlock()
+			e.values[extractor.GetName()] = values
+			e.errs[extractor.GetName()] = err
+		}(extractor)
+	}
+	wg.Wait()
+	return e
+}
+
+// For returns the defaults read by the extractors of lowerLang, merged in the order the extractors are registered
+func (e *ExtractedDefaults) For(lowerLang string) (map[string]string, error) {
+	extractedValues := make(map[string]string)
+	for _, extractor := range e.extractors {
+		if !extractor.MatchesLanguage(lowerLang) {
+			continue
+		}
+		if err := e.errs[extractor.GetName()]; err != nil {
+			return nil, fmt.Errorf("error reading defaults for language %s: %v", lowerLang, err)
+		}
+		for k, v := range e.values[extractor.GetName()] {
+			if _, ok := extractedValues[k]; ok {
+				log.Debugf("duplicate default %s for language %s with extractor %s", k, lowerLang, extractor.GetName())
 			}
+			extractedValues[k] = v
+			log.Debugf("extracted default %s=%s with extractor:%s", k, v, extractor.GetName())
 		}
 	}
 
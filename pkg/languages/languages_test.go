@@ -1,10 +1,14 @@
 package languages
 
 import (
+	"io/fs"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/reporeader"
 	"github.com/Azure/draft/pkg/templatewriter/writers"
 	"github.com/Azure/draft/template"
 )
@@ -21,3 +25,122 @@ func TestLanguagesCreateDockerfileFileMap(t *testing.T) {
 	assert.NotNil(t, templateWriter.FileMap)
 	assert.NotNil(t, templateWriter.FileMap["/test/dest/dir/Dockerfile"])
 }
+
+func TestLanguagesUseVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dockerfiles/go/draft.yaml":       {Data: []byte("version: \"1.1.0\"\n")},
+		"dockerfiles/go/Dockerfile":       {Data: []byte("FROM golang:{{VERSION}}\n")},
+		"dockerfiles/go@1.0.0/draft.yaml": {Data: []byte("version: \"1.0.0\"\n")},
+		"dockerfiles/go@1.0.0/Dockerfile": {Data: []byte("FROM golang:{{VERSION}}-alpine\n")},
+	}
+	entries, err := fs.ReadDir(fsys, "dockerfiles")
+	assert.Nil(t, err)
+	l := &Languages{langs: map[string]fs.DirEntry{}, configs: map[string]*config.DraftConfig{}, dest: "out", dockerfileTemplates: fsys}
+	for _, e := range entries {
+		if e.Name() == "go" {
+			l.langs["go"] = e
+		}
+	}
+	l.PopulateConfigs()
+
+	versions, err := l.Versions("go")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.1.0", "1.0.0"}, versions)
+
+	assert.NotNil(t, l.UseVersion("go", "0.1.0"))
+	assert.Nil(t, l.UseVersion("go", "1.0.0"))
+	assert.Equal(t, "1.0.0", l.GetConfig("go").Version)
+
+	w := &writers.FileMapWriter{}
+	assert.Nil(t, l.CreateDockerfileForLanguage("go", map[string]string{"VERSION": "1.22"}, w))
+	assert.Equal(t, "FROM golang:1.22-alpine\n", string(w.FileMap["out/Dockerfile"]))
+}
+
+func TestLanguagesAddPacksFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dockerfiles/cobol/draft.yaml": {Data: []byte("version: \"0.1.0\"\nlanguage: cobol\n")},
+		"dockerfiles/cobol/Dockerfile": {Data: []byte("FROM cobol:{{VERSION}}\n")},
+		"dockerfiles/go/draft.yaml":    {Data: []byte("version: \"9.0.0\"\nlanguage: go\n")},
+		"dockerfiles/go/Dockerfile":    {Data: []byte("FROM internal/golang:{{VERSION}}\n")},
+	}
+	custom, err := CreateLanguagesFromFS(fsys, "out")
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"cobol", "go"}, custom.Names())
+
+	l := CreateLanguagesFromEmbedFS(template.Dockerfiles, "out")
+	l.AddPacks(custom)
+	assert.True(t, l.ContainsLanguage("cobol"))
+	assert.True(t, l.ContainsLanguage("python"))
+	assert.Equal(t, "9.0.0", l.GetConfig("go").Version)
+
+	w := &writers.FileMapWriter{}
+	assert.Nil(t, l.CreateDockerfileForLanguage("go", map[string]string{"VERSION": "1.22"}, w))
+	assert.Equal(t, "FROM internal/golang:1.22\n", string(w.FileMap["out/Dockerfile"]))
+	assert.Nil(t, l.CreateDockerfileForLanguage("cobol", map[string]string{"VERSION": "3"}, w))
+	assert.Equal(t, "FROM cobol:3\n", string(w.FileMap["out/Dockerfile"]))
+
+	_, err = CreateLanguagesFromFS(fstest.MapFS{}, "out")
+	assert.NotNil(t, err)
+}
+
+// TestLanguagesHardenedByDefault checks every embedded pack builds a hardened image unless HARDENED is false
+func TestLanguagesHardenedByDefault(t *testing.T) {
+	l := CreateLanguagesFromEmbedFS(template.Dockerfiles, "out")
+	for _, lang := range l.Names() {
+		t.Run(lang, func(t *testing.T) {
+			draftConfig := l.GetConfig(lang)
+			assert.Contains(t, draftConfig.VariableNames(), HardenedVariable)
+			for _, variable := range draftConfig.Variables {
+				if variable.Name == HardenedVariable {
+					assert.Equal(t, "bool", variable.VarType)
+				}
+			}
+			for _, variableDefault := range draftConfig.VariableDefaults {
+				if variableDefault.Name == HardenedVariable {
+					assert.Equal(t, "true", variableDefault.Value)
+				}
+			}
+
+			for _, value := range []string{"true", "false"} {
+				w := &writers.FileMapWriter{}
+				assert.Nil(t, l.CreateDockerfileForLanguage(lang, map[string]string{HardenedVariable: value}, w))
+				assert.Contains(t, string(w.FileMap["out/Dockerfile"]), "\nFROM hardened-"+value+"\n")
+			}
+		})
+	}
+}
+
+func TestLanguagesNamesSorted(t *testing.T) {
+	l := CreateLanguagesFromEmbedFS(template.Dockerfiles, "")
+	for i := 0; i < 10; i++ {
+		assert.IsNonDecreasing(t, l.Names())
+	}
+}
+
+func TestExtractAllDefaults(t *testing.T) {
+	r := reporeader.FakeRepoReader{Files: map[string][]byte{
+		"Cargo.toml":  []byte("[package]\nname = \"hello\"\nrust-version = \"1.74\"\n"),
+		"src/main.rs": []byte("fn main() {}\n"),
+		"deno.json":   []byte(`{"tasks": {"start": "deno run server.ts"}}`),
+	}}
+
+	extracted := ExtractAllDefaults(r)
+	rust, err := extracted.For("rust")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"BINARYNAME": "hello", "VERSION": "1.74"}, rust)
+	deno, err := extracted.For("deno")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"ENTRYPOINT": "server.ts"}, deno)
+
+	l := CreateLanguagesFromEmbedFS(template.Dockerfiles, "")
+	serial, err := l.ExtractDefaults("rust", r)
+	assert.Nil(t, err)
+	assert.Equal(t, rust, serial)
+
+	// the errors of the extractors of other languages don't fail the language's defaults
+	broken := ExtractAllDefaults(reporeader.FakeRepoReader{Files: map[string][]byte{"Cargo.toml": []byte("[package")}})
+	_, err = broken.For("rust")
+	assert.NotNil(t, err)
+	_, err = broken.For("deno")
+	assert.Nil(t, err)
+}
@@ -0,0 +1,96 @@
+package languages
+
+import (
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+// ManifestMatch is a pack detected from a dependency or build file of a repo
+type ManifestMatch struct {
+	Pack     string
+	Language string
+	// File is the dependency or build file the pack was detected from
+	File string
+}
+
+// manifestPack is a pack and the patterns of the dependency or build files of its projects
+type manifestPack struct {
+	pack     string
+	language string
+	patterns []string
+	// replaces is the pack of a file the projects of the pack have too, such as the package.json of a Bun project
+	replaces string
+}
+
+// manifestPacks are ordered from the most to the least likely pack of a repo with several of their files
+var manifestPacks = []manifestPack{
+	{pack: "gomodule", language: "Go", patterns: []string{"go.mod"}},
+	{pack: "bun", language: "Bun", patterns: []string{"bun.lockb", "bun.lock", "bunfig.toml"}, replaces: "javascript"},
+	{pack: "deno", language: "Deno", patterns: []string{"deno.json", "deno.jsonc"}, replaces: "javascript"},
+	{pack: "javascript", language: "JavaScript", patterns: []string{"package.json"}},
+	{pack: "java", language: "Java", patterns: []string{"pom.xml"}},
+	{pack: "gradlew", language: "Gradle", patterns: []string{"gradlew"}, replaces: "gradle"},
+	{pack: "gradle", language: "Gradle", patterns: []string{"build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"}},
+	{pack: "python", language: "Python", patterns: []string{"requirements.txt", "pyproject.toml", "Pipfile", "setup.py"}},
+	{pack: "rust", language: "Rust", patterns: []string{"Cargo.toml"}},
+	{pack: "csharp", language: "C#", patterns: []string{"*.csproj", "*.sln"}},
+	{pack: "ruby", language: "Ruby", patterns: []string{"Gemfile"}},
+	{pack: "php", language: "PHP", patterns: []string{"composer.json"}},
+	{pack: "swift", language: "Swift", patterns: []string{"Package.swift"}},
+	{pack: "clojure", language: "Clojure", patterns: []string{"project.clj", "deps.edn"}},
+	{pack: "erlang", language: "Erlang", patterns: []string{"rebar.config"}},
+}
+
+// DetectFromManifests returns the packs of the dependency and build files in the root of the repo, such as go.mod,
+// package.json or a .csproj, for the repos linguist finds no source files in, like a new project with only its
+// package.json
+func DetectFromManifests(r reporeader.RepoReader) []ManifestMatch {
+	if r == nil {
+		return nil
+	}
+
+	var matches []ManifestMatch
+	replaced := make(map[string]bool)
+	for _, p := range manifestPacks {
+		if replaced[p.pack] {
+			continue
+		}
+		file, ok := findRootFile(r, p.patterns)
+		if !ok {
+			continue
+		}
+		matches = append(matches, ManifestMatch{Pack: p.pack, Language: p.language, File: file})
+		if p.replaces != "" {
+			replaced[p.replaces] = true
+		}
+	}
+	return matches
+}
+
+// findRootFile returns the first file in the root of the repo matching patterns. Only the patterns with wildcards walk
+// the repo, whose files in subdirectories are also found by the readers of the local file system.
+func findRootFile(r reporeader.RepoReader, patterns []string) (string, bool) {
+	for _, pattern := range patterns {
+		if !strings.ContainsAny(pattern, "*?[") {
+			if r.Exists(pattern) {
+				return pattern, true
+			}
+			continue
+		}
+		files, err := r.FindFiles(".", []string{pattern}, 0)
+		if err != nil {
+			log.Debugf("not finding %s in the repo: %s", pattern, err)
+			continue
+		}
+		for _, file := range files {
+			if filepath.Dir(file) == "." {
+				return file, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,61 @@
+package languages
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+func TestDetectFromManifests(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  []ManifestMatch
+	}{
+		{
+			name:  "node",
+			files: []string{"package.json", "README.md"},
+			want:  []ManifestMatch{{Pack: "javascript", Language: "JavaScript", File: "package.json"}},
+		},
+		{
+			name:  "bun replaces javascript",
+			files: []string{"package.json", "bun.lockb"},
+			want:  []ManifestMatch{{Pack: "bun", Language: "Bun", File: "bun.lockb"}},
+		},
+		{
+			name:  "gradle wrapper replaces gradle",
+			files: []string{"build.gradle.kts", "gradlew"},
+			want:  []ManifestMatch{{Pack: "gradlew", Language: "Gradle", File: "gradlew"}},
+		},
+		{
+			name:  "csproj",
+			files: []string{"Api.csproj"},
+			want:  []ManifestMatch{{Pack: "csharp", Language: "C#", File: "Api.csproj"}},
+		},
+		{
+			name:  "several in order",
+			files: []string{"requirements.txt", "go.mod", "Cargo.toml"},
+			want: []ManifestMatch{
+				{Pack: "gomodule", Language: "Go", File: "go.mod"},
+				{Pack: "python", Language: "Python", File: "requirements.txt"},
+				{Pack: "rust", Language: "Rust", File: "Cargo.toml"},
+			},
+		},
+		{
+			name:  "only in the root",
+			files: []string{"web/package.json", "docs/README.md"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := make(map[string][]byte)
+			for _, file := range tt.files {
+				files[file] = []byte{}
+			}
+			assert.Equal(t, tt.want, DetectFromManifests(reporeader.FakeRepoReader{Files: files}))
+		})
+	}
+	assert.Nil(t, DetectFromManifests(nil))
+}
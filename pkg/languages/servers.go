@@ -0,0 +1,102 @@
+package languages
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	ServerVariable    = "SERVER"
+	WorkersVariable   = "WORKERS"
+	ServerCmdVariable = "SERVERCMD"
+)
+
+// ProvidedVariables are the variables draft sets from the others when rendering language packs, which the packs use
+// without declaring
+var ProvidedVariables = []string{ServerCmdVariable}
+
+// ConsumedVariables are the variables of language packs draft reads to set others, which the packs declare without
+// using
+var ConsumedVariables = []string{ServerVariable, WorkersVariable, "ENTRYPOINT", "APPMODULE"}
+
+// serverCommands maps a language to the process managers its pack supports and the CMD each one runs with
+var serverCommands = map[string]map[string]func(inputs map[string]string) []string{
+	"ruby": {
+		"ruby": func(inputs map[string]string) []string {
+			return []string{"ruby", "app.rb"}
+		},
+		"rackup": func(inputs map[string]string) []string {
+			return []string{"bundle", "exec", "rackup", "--host", "0.0.0.0", "--port", inputs["PORT"]}
+		},
+		"puma": func(inputs map[string]string) []string {
+			return []string{"bundle", "exec", "puma", "--bind", "tcp://0.0.0.0:" + inputs["PORT"], "--workers", inputs[WorkersVariable]}
+		},
+		"unicorn": func(inputs map[string]string) []string {
+			return []string{"bundle", "exec", "unicorn", "--port", inputs["PORT"], "--config-file", "config/unicorn.rb"}
+		},
+	},
+	"python": {
+		"python": func(inputs map[string]string) []string {
+			return []string{"python", inputs["ENTRYPOINT"]}
+		},
+		"gunicorn": func(inputs map[string]string) []string {
+			return []string{"gunicorn", "--bind", "0.0.0.0:" + inputs["PORT"], "--workers", inputs[WorkersVariable], pythonAppModule(inputs)}
+		},
+		"uvicorn": func(inputs map[string]string) []string {
+			return []string{"uvicorn", pythonAppModule(inputs), "--host", "0.0.0.0", "--port", inputs["PORT"], "--workers", inputs[WorkersVariable]}
+		},
+		"gunicorn-uvicorn": func(inputs map[string]string) []string {
+			return []string{"gunicorn", "--bind", "0.0.0.0:" + inputs["PORT"], "--workers", inputs[WorkersVariable], "--worker-class", "uvicorn.workers.UvicornWorker", pythonAppModule(inputs)}
+		},
+	},
+}
+
+// pythonAppModule returns the WSGI/ASGI application, defaulting to the app object of the ENTRYPOINT module
+func pythonAppModule(inputs map[string]string) string {
+	if module := inputs["APPMODULE"]; module != "" {
+		return module
+	}
+	module := strings.TrimSuffix(inputs["ENTRYPOINT"], ".py")
+	module = strings.ReplaceAll(module, "/", ".")
+	return module + ":app"
+}
+
+// ServerNames returns the sorted process managers supported by the pack of lang
+func ServerNames(lang string) []string {
+	names := make([]string, 0, len(serverCommands[lang]))
+	for name := range serverCommands[lang] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyServerFlavor sets SERVERCMD to the exec form CMD of the SERVER selected for lang, unless SERVERCMD is
+// already set. Languages without server flavors are left untouched.
+func applyServerFlavor(lang string, customInputs map[string]string) error {
+	commands, ok := serverCommands[lang]
+	if !ok || customInputs[ServerCmdVariable] != "" {
+		return nil
+	}
+
+	server := strings.ToLower(customInputs[ServerVariable])
+	command, ok := commands[server]
+	if !ok {
+		return fmt.Errorf("invalid %s %q for %s, must be one of: %s", ServerVariable, server, lang, strings.Join(ServerNames(lang), ", "))
+	}
+	if workers, ok := customInputs[WorkersVariable]; ok {
+		if n, err := strconv.Atoi(workers); err != nil || n < 1 {
+			return fmt.Errorf("invalid %s %q, must be a positive integer", WorkersVariable, workers)
+		}
+	}
+
+	cmd, err := json.Marshal(command(customInputs))
+	if err != nil {
+		return err
+	}
+	customInputs[ServerCmdVariable] = string(cmd)
+	return nil
+}
@@ -0,0 +1,57 @@
+package languages
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+func TestApplyServerFlavor(t *testing.T) {
+	tests := []struct {
+		lang     string
+		inputs   map[string]string
+		expected string
+	}{
+		{"ruby", map[string]string{"SERVER": "ruby"}, `["ruby","app.rb"]`},
+		{"ruby", map[string]string{"SERVER": "puma", "PORT": "3000", "WORKERS": "4"}, `["bundle","exec","puma","--bind","tcp://0.0.0.0:3000","--workers","4"]`},
+		{"ruby", map[string]string{"SERVER": "rackup", "PORT": "4567"}, `["bundle","exec","rackup","--host","0.0.0.0","--port","4567"]`},
+		{"python", map[string]string{"SERVER": "python", "ENTRYPOINT": "main.py"}, `["python","main.py"]`},
+		{"python", map[string]string{"SERVER": "gunicorn", "PORT": "80", "WORKERS": "2", "ENTRYPOINT": "src/app.py"}, `["gunicorn","--bind","0.0.0.0:80","--workers","2","src.app:app"]`},
+		{"python", map[string]string{"SERVER": "uvicorn", "PORT": "80", "WORKERS": "3", "APPMODULE": "api:create_app"}, `["uvicorn","api:create_app","--host","0.0.0.0","--port","80","--workers","3"]`},
+		{"python", map[string]string{"SERVERCMD": `["custom"]`, "SERVER": "bogus"}, `["custom"]`},
+	}
+	for _, test := range tests {
+		t.Run(test.lang+" "+test.inputs["SERVER"], func(t *testing.T) {
+			assert.Nil(t, applyServerFlavor(test.lang, test.inputs))
+			assert.Equal(t, test.expected, test.inputs["SERVERCMD"])
+		})
+	}
+
+	err := applyServerFlavor("python", map[string]string{"SERVER": "waitress"})
+	assert.ErrorContains(t, err, "gunicorn, gunicorn-uvicorn, python, uvicorn")
+
+	err = applyServerFlavor("ruby", map[string]string{"SERVER": "puma", "WORKERS": "0"})
+	assert.NotNil(t, err)
+
+	inputs := map[string]string{}
+	assert.Nil(t, applyServerFlavor("go", inputs))
+	assert.Empty(t, inputs)
+}
+
+func TestCreateDockerfileServerFlavor(t *testing.T) {
+	templateWriter := &writers.FileMapWriter{}
+	l := CreateLanguagesFromEmbedFS(template.Dockerfiles, "/test/dest/dir")
+	err := l.CreateDockerfileForLanguage("python", map[string]string{
+		"PORT":       "8000",
+		"ENTRYPOINT": "main.py",
+		"SERVER":     "gunicorn-uvicorn",
+	}, templateWriter)
+
+	assert.Nil(t, err)
+	dockerfile := string(templateWriter.FileMap["/test/dest/dir/Dockerfile"])
+	assert.Contains(t, dockerfile, "ENV WEB_CONCURRENCY 2")
+	assert.Contains(t, dockerfile, `CMD ["gunicorn","--bind","0.0.0.0:8000","--workers","2","--worker-class","uvicorn.workers.UvicornWorker","main:app"]`)
+}
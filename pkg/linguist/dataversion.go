@@ -0,0 +1,26 @@
+package linguist
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+)
+
+// DataVersion returns a fingerprint of the language, vendor and documentation data of github linguist embedded in
+// draft, which changes whenever the data is regenerated from a newer linguist
+func DataVersion() string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+		h.Write([]byte(files[name]))
+		h.Write([]byte{0})
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))[:12]
+}
@@ -0,0 +1,128 @@
+package linguist
+
+import (
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// dominantShare is the share of the bytes of a directory the files of one language must hold, going by their names,
+// for ProcessDirFast to skip reading them
+const dominantShare = 0.8
+
+// manifestLanguages are the languages of the manifest files found in the root of a repo, which settle the language of
+// the extensions shared by several languages, such as .ts for TypeScript and XML
+var manifestLanguages = map[string][]string{
+	"go.mod":           {"Go"},
+	"package.json":     {"JavaScript", "TypeScript"},
+	"tsconfig.json":    {"TypeScript"},
+	"requirements.txt": {"Python"},
+	"pyproject.toml":   {"Python"},
+	"setup.py":         {"Python"},
+	"Pipfile":          {"Python"},
+	"pom.xml":          {"Java", "Kotlin"},
+	"build.gradle":     {"Java", "Kotlin"},
+	"build.gradle.kts": {"Java", "Kotlin"},
+	"Cargo.toml":       {"Rust"},
+	"Gemfile":          {"Ruby"},
+	"composer.json":    {"PHP"},
+	"*.csproj":         {"C#"},
+	"*.sln":            {"C#"},
+	"Package.swift":    {"Swift"},
+	"project.clj":      {"Clojure"},
+	"rebar.config":     {"Erlang"},
+	"mix.exs":          {"Elixir"},
+}
+
+// preferredLanguages are the languages of the extensions shared by several languages that nearly always mean the same
+// one in the repos draft reads
+var preferredLanguages = map[string]string{
+	".md":   "Markdown",
+	".yaml": "YAML",
+}
+
+// ProcessDirFast is ProcessDirWithProgress telling the languages of the files of dirname by their names alone when one
+// language clearly dominates them, which skips reading them on large repos. It falls back to classifying the files by
+// their contents when the names are ambiguous or no language holds most of the bytes.
+func ProcessDirFast(dirname string, progress func(done, total int)) ([]*Language, error) {
+	files, err := listDir(dirname)
+	if err != nil {
+		return nil, err
+	}
+	if langs, ok := dominantLanguages(dirname, files); ok {
+		if progress != nil {
+			progress(len(files), len(files))
+		}
+		return langs, nil
+	}
+	return classifyFiles(files, progress), nil
+}
+
+// dominantLanguages returns the sorted languages of files going by their names and the manifest files of dirname, and
+// whether one of them holds the dominantShare of the bytes of all of files
+func dominantLanguages(dirname string, files []dirFile) ([]*Language, bool) {
+	manifests := rootManifestLanguages(dirname)
+	var (
+		langs          = make(map[string]int)
+		totalSize      int
+		classifiedSize int
+	)
+	for _, file := range files {
+		totalSize += file.size
+		if lang := languageByName(file.path, manifests); lang != "" {
+			langs[lang] += file.size
+			classifiedSize += file.size
+		}
+	}
+
+	var top string
+	for lang, size := range langs {
+		if top == "" || size > langs[top] {
+			top = lang
+		}
+	}
+	if totalSize == 0 || float64(langs[top]) < dominantShare*float64(totalSize) {
+		log.Debugf("no language holds %.0f%% of the bytes going by the file names, classifying the files by their contents", dominantShare*100)
+		return nil, false
+	}
+	log.Debugf("%s holds %.0f%% of the bytes going by the file names, skipping classifying the files by their contents", top, float64(langs[top])/float64(totalSize)*100)
+	return sortedLanguages(langs, classifiedSize), true
+}
+
+// languageByName returns the language of the file at path going by .gitattributes and its name, settling the
+// extensions shared by several languages with the languages of the repo's manifests, or "" when it can't be told
+func languageByName(path string, manifests map[string]bool) string {
+	if lang := isDetectedInGitAttributes(path); lang != "" {
+		return lang
+	}
+	name := filepath.Base(path)
+	if lang := LanguageByFilename(name); lang != "" {
+		return lang
+	}
+	if lang, ok := preferredLanguages[filepath.Ext(name)]; ok {
+		return lang
+	}
+	var match string
+	for _, hint := range LanguageHints(name) {
+		if manifests[hint] {
+			if match != "" {
+				return ""
+			}
+			match = hint
+		}
+	}
+	return match
+}
+
+// rootManifestLanguages returns the languages of the manifest files in the root of dirname
+func rootManifestLanguages(dirname string) map[string]bool {
+	langs := make(map[string]bool)
+	for pattern, manifestLangs := range manifestLanguages {
+		if matches, _ := filepath.Glob(filepath.Join(dirname, pattern)); len(matches) > 0 {
+			for _, lang := range manifestLangs {
+				langs[lang] = true
+			}
+		}
+	}
+	return langs
+}
@@ -0,0 +1,51 @@
+package linguist
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+// File is a file of a repo that isn't on disk, such as one listed by the API of the repo's host
+type File struct {
+	// Path is the slash separated path of the file in the repo
+	Path string
+	Size int
+}
+
+// ProcessFiles returns the sorted languages of files like ProcessDir does for a directory. readFile reads the contents
+// of a file, and is only called for the files whose language can't be told from their name.
+func ProcessFiles(files []File, readFile func(path string) ([]byte, error)) ([]*Language, error) {
+	var (
+		langs     = make(map[string]int)
+		totalSize int
+	)
+	for _, file := range files {
+		if file.Size == 0 || ShouldIgnoreFilename(file.Path) {
+			log.Debugf("%s: empty or ignored, skipping", file.Path)
+			continue
+		}
+
+		lang := LanguageByFilename(file.Path)
+		if lang == "" {
+			contents, err := readFile(file.Path)
+			if err != nil {
+				log.Debugf("unable to classify %s: %v", file.Path, err)
+				continue
+			}
+			// only the start of files is read from disk either
+			if len(contents) > 512 {
+				contents = contents[:512]
+			}
+			if ShouldIgnoreContents(contents) {
+				log.Debugln(file.Path, ": contents should be ignored, skipping")
+				continue
+			}
+			if lang = LanguageByContents(contents, LanguageHints(file.Path)); lang == "" {
+				lang = "(unknown)"
+			}
+		}
+		log.Debugln(file.Path, "is", lang)
+		langs[lang] += file.Size
+		totalSize += file.Size
+	}
+	return sortedLanguages(langs, totalSize), nil
+}
@@ -10,13 +10,15 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/Azure/draft/pkg/ignore"
 	"github.com/Azure/draft/pkg/osutil"
 	log "github.com/sirupsen/logrus"
 )
 
 var (
-	isIgnored                 func(string) bool
+	isIgnored                 func(filename string, isDir bool) bool
 	isDetectedInGitAttributes func(filename string) string
 )
 
@@ -49,46 +51,10 @@ func (s sortableResult) Swap(i, j int) {
 }
 
 func initLinguistAttributes(dir string) error {
-	ignore := []string{}
-	except := []string{}
+	ignored := []string{}
 	detected := make(map[string]string)
 
-	gitignoreExists, err := osutil.Exists(filepath.Join(dir, ".gitignore"))
-	if err != nil {
-		return err
-	}
-	if gitignoreExists {
-		log.Debugln("found .gitignore")
-
-		f, err := os.Open(filepath.Join(dir, ".gitignore"))
-		if err != nil {
-			return err
-		}
-		defer f.Close()
-
-		ignoreScanner := bufio.NewScanner(f)
-		for ignoreScanner.Scan() {
-			var isExcept bool
-			path := strings.TrimSpace(ignoreScanner.Text())
-			// if it's whitespace or a comment
-			if len(path) == 0 || string(path[0]) == "#" {
-				continue
-			}
-			if string(path[0]) == "!" {
-				isExcept = true
-				path = path[1:]
-			}
-			p := strings.Trim(path, string(filepath.Separator))
-			if isExcept {
-				except = append(except, p)
-			} else {
-				ignore = append(ignore, p)
-			}
-		}
-		if err := ignoreScanner.Err(); err != nil {
-			return fmt.Errorf("error reading .gitignore: %v", err)
-		}
-	}
+	gitignore := ignore.NewMatcher(dir)
 
 	gitAttributesExists, err := osutil.Exists(filepath.Join(dir, ".gitattributes"))
 	if err != nil {
@@ -121,7 +87,7 @@ func initLinguistAttributes(dir string) error {
 			attribute := words[1]
 			if strings.HasPrefix(attribute, "linguist-documentation") || strings.HasPrefix(attribute, "linguist-vendored") || strings.HasPrefix(attribute, "linguist-generated") {
 				if !strings.HasSuffix(strings.ToLower(attribute), "false") {
-					ignore = append(ignore, path)
+					ignored = append(ignored, path)
 				}
 			} else if strings.HasPrefix(attribute, "linguist-language") {
 				attr := strings.Split(attribute, "=")
@@ -138,19 +104,17 @@ func initLinguistAttributes(dir string) error {
 		}
 	}
 
-	isIgnored = func(filename string) bool {
-		for _, p := range ignore {
-			cleanPath, err := filepath.Rel(dir, filename)
-			if err != nil {
-				log.Debugf("could not get relative path: %v", err)
-				return false
-			}
+	isIgnored = func(filename string, isDir bool) bool {
+		cleanPath, err := filepath.Rel(dir, filename)
+		if err != nil {
+			log.Debugf("could not get relative path: %v", err)
+			return false
+		}
+		if gitignore.Match(cleanPath, isDir) {
+			return true
+		}
+		for _, p := range ignored {
 			if m, _ := filepath.Match(p, cleanPath); m {
-				for _, e := range except {
-					if m, _ := filepath.Match(e, cleanPath); m {
-						return false
-					}
-				}
 				return true
 			}
 		}
@@ -194,10 +158,28 @@ func fileGetContents(filename string) ([]byte, error) {
 
 // ProcessDir walks through a directory and returns a list of sorted languages within that directory.
 func ProcessDir(dirname string) ([]*Language, error) {
-	var (
-		langs     = make(map[string]int)
-		totalSize int
-	)
+	return ProcessDirWithProgress(dirname, nil)
+}
+
+// ProcessDirWithProgress is ProcessDir reporting the number of files classified out of the total with progress, which
+// is called from several goroutines since the files are classified concurrently
+func ProcessDirWithProgress(dirname string, progress func(done, total int)) ([]*Language, error) {
+	files, err := listDir(dirname)
+	if err != nil {
+		return nil, err
+	}
+	return classifyFiles(files, progress), nil
+}
+
+// dirFile is a file of a directory to classify
+type dirFile struct {
+	path string
+	size int
+}
+
+// listDir returns the files of dirname to classify, leaving out the empty files and those ignored by .gitignore,
+// .draftignore and .gitattributes or by their name
+func listDir(dirname string) ([]dirFile, error) {
 	if err := initLinguistAttributes(dirname); err != nil {
 		return nil, err
 	}
@@ -208,11 +190,13 @@ func ProcessDir(dirname string) ([]*Language, error) {
 	if !exists {
 		return nil, os.ErrNotExist
 	}
+
+	var files []dirFile
 	filepath.Walk(dirname, func(path string, file os.FileInfo, err error) error {
 		size := int(file.Size())
 		log.Debugf("with file: %s", path)
 		log.Debugln(path, "is", size, "bytes")
-		if isIgnored(path) {
+		if isIgnored(path, file.IsDir()) {
 			log.Debugln(path, "is ignored, skipping")
 			if file.IsDir() {
 				return filepath.SkipDir
@@ -234,50 +218,57 @@ func ProcessDir(dirname string) ([]*Language, error) {
 				log.Debugf("%s: filename should be ignored, skipping", path)
 				return nil
 			}
-
-			byGitAttr := isDetectedInGitAttributes(path)
-			if byGitAttr != "" {
-				log.Debugln(path, "got result by .gitattributes: ", byGitAttr)
-				langs[byGitAttr] += size
-				totalSize += size
-				return nil
-			}
-
-			if byName := LanguageByFilename(path); byName != "" {
-				log.Debugln(path, "got result by name: ", byName)
-				langs[byName] += size
-				totalSize += size
-				return nil
-			}
-
-			contents, err := fileGetContents(path)
-			if err != nil {
-				return err
-			}
-
-			if ShouldIgnoreContents(contents) {
-				log.Debugln(path, ": contents should be ignored, skipping")
-				return nil
-			}
-
-			hints := LanguageHints(path)
-			log.Debugf("%s got language hints: %#v\n", path, hints)
-			byData := LanguageByContents(contents, hints)
-
-			if byData != "" {
-				log.Debugln(path, "got result by data: ", byData)
-				langs[byData] += size
-				totalSize += size
-				return nil
-			}
-
-			log.Debugln(path, "got no result!!")
-			langs["(unknown)"] += size
-			totalSize += size
+			files = append(files, dirFile{path: path, size: size})
 		}
 		return nil
 	})
+	return files, nil
+}
+
+// classifyFiles returns the sorted languages of files, reading and classifying them over the CPUs
+func classifyFiles(files []dirFile, progress func(done, total int)) []*Language {
+	var (
+		langs     = make(map[string]int)
+		totalSize int
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		done      int
+		paths     = make(chan string)
+	)
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				lang, size, err := classifyFile(path)
+				if err != nil {
+					log.Debugf("unable to classify %s: %v", path, err)
+				}
+				mu.Lock()
+				if lang != "" {
+					langs[lang] += size
+					totalSize += size
+				}
+				done++
+				if progress != nil {
+					progress(done, len(files))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, file := range files {
+		paths <- file.path
+	}
+	close(paths)
+	wg.Wait()
+
+	return sortedLanguages(langs, totalSize)
+}
 
+// sortedLanguages returns the languages of langs, which holds the total size of each language's files, from the most
+// to the least likely primary language
+func sortedLanguages(langs map[string]int, totalSize int) []*Language {
 	results := []*Language{}
 	for lang, size := range langs {
 		l := &Language{
@@ -289,7 +280,80 @@ func ProcessDir(dirname string) ([]*Language, error) {
 		log.Debugf("language: %s percent: %f color: %s", l.Language, l.Percent, l.Color)
 	}
 	sort.Sort(sort.Reverse(sortableResult(results)))
-	return results, nil
+	return results
+}
+
+// classifyFile returns the language of the file at path and its size, "(unknown)" when it can't be classified and ""
+// when its contents should be ignored
+func classifyFile(path string) (string, int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", 0, err
+	}
+	size := int(info.Size())
+
+	byGitAttr := isDetectedInGitAttributes(path)
+	if byGitAttr != "" {
+		log.Debugln(path, "got result by .gitattributes: ", byGitAttr)
+		return byGitAttr, size, nil
+	}
+
+	if byName := LanguageByFilename(path); byName != "" {
+		log.Debugln(path, "got result by name: ", byName)
+		return byName, size, nil
+	}
+
+	contents, err := fileGetContents(path)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if ShouldIgnoreContents(contents) {
+		log.Debugln(path, ": contents should be ignored, skipping")
+		return "", 0, nil
+	}
+
+	hints := LanguageHints(path)
+	log.Debugf("%s got language hints: %#v\n", path, hints)
+	byData := LanguageByContents(contents, hints)
+
+	if byData != "" {
+		log.Debugln(path, "got result by data: ", byData)
+		return byData, size, nil
+	}
+
+	log.Debugln(path, "got no result!!")
+	return "(unknown)", size, nil
+}
+
+// runtimeConfigFiles are the config files in the repo root marking the TypeScript and JavaScript repos run by
+// runtimes other than node, keyed by the pack of the runtime in the order they are looked for
+var runtimeConfigFiles = []struct {
+	pack  string
+	files []string
+}{
+	{pack: "deno", files: []string{"deno.json", "deno.jsonc"}},
+	{pack: "bun", files: []string{"bunfig.toml", "bun.lock", "bun.lockb"}},
+}
+
+// RuntimeAlias returns the pack of the runtime the TypeScript or JavaScript repo dir is run by, such as deno for a repo
+// with a deno.json, or lang unchanged for other languages and repos run by node
+func RuntimeAlias(lang *Language, dir string) *Language {
+	switch strings.ToLower(lang.Language) {
+	case "typescript", "javascript":
+	default:
+		return lang
+	}
+	for _, runtime := range runtimeConfigFiles {
+		for _, file := range runtime.files {
+			if exists, err := osutil.Exists(filepath.Join(dir, file)); err == nil && exists {
+				log.Debugf("found %s, using the %s pack for %s", file, runtime.pack, lang.Language)
+				lang.Language = runtime.pack
+				return lang
+			}
+		}
+	}
+	return lang
 }
 
 // Alias returns the language name for a given known alias.
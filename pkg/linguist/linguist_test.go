@@ -1,7 +1,9 @@
 package linguist
 
 import (
+	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 )
 
@@ -35,6 +37,109 @@ func TestProcessDir(t *testing.T) {
 	}
 }
 
+func TestProcessDirWithProgress(t *testing.T) {
+	var mu sync.Mutex
+	var calls, lastDone, lastTotal int
+	output, err := ProcessDirWithProgress(appPythonPath, func(done, total int) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		lastDone, lastTotal = done, total
+	})
+	if err != nil {
+		t.Errorf("expected ProcessDirWithProgress() to pass, got %s", err)
+	}
+	if output[0].Language != "Python" {
+		t.Errorf("expected output == 'Python', got '%s'", output[0].Language)
+	}
+	if calls != 2 || lastDone != 2 || lastTotal != 2 {
+		t.Errorf("expected progress to reach 2 of 2 files in 2 calls, got %d of %d in %d calls", lastDone, lastTotal, calls)
+	}
+}
+
+func TestProcessDirFast(t *testing.T) {
+	writeFiles := func(t *testing.T, files map[string]string) string {
+		dir := t.TempDir()
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return dir
+	}
+	testCases := []struct {
+		name         string
+		files        map[string]string
+		expectedLang string
+		fast         bool
+	}{
+		{
+			name:         "one language",
+			files:        map[string]string{"main.go": "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n", "go.mod": "module app\n"},
+			expectedLang: "Go",
+			fast:         true,
+		},
+		{
+			name:         "extension settled by manifest",
+			files:        map[string]string{"index.ts": "export const answer: number = 42;\n", "package.json": "{}"},
+			expectedLang: "TypeScript",
+			fast:         true,
+		},
+		{
+			name:         "no dominant language",
+			files:        map[string]string{"app.py": "print('hello')\n", "index.js": "console.log('hello');\n"},
+			expectedLang: "",
+			fast:         false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, tc.files)
+			files, err := listDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			langs, fast := dominantLanguages(dir, files)
+			if fast != tc.fast {
+				t.Errorf("expected the fast path to be %t, got %t", tc.fast, fast)
+			}
+			if fast && langs[0].Language != tc.expectedLang {
+				t.Errorf("expected output == '%s', got '%s'", tc.expectedLang, langs[0].Language)
+			}
+
+			output, err := ProcessDirFast(dir, nil)
+			if err != nil {
+				t.Errorf("expected ProcessDirFast() to pass, got %s", err)
+			}
+			if len(output) == 0 {
+				t.Errorf("expected ProcessDirFast() to detect languages")
+			}
+		})
+	}
+}
+
+func TestProcessFiles(t *testing.T) {
+	var read []string
+	output, err := ProcessFiles([]File{
+		{Path: "app.py", Size: 300},
+		{Path: "run", Size: 100},
+		{Path: "docs/index.md", Size: 5000},
+		{Path: "empty.go", Size: 0},
+	}, func(path string) ([]byte, error) {
+		read = append(read, path)
+		return []byte("#!/usr/bin/env python3\nprint('hi')\n"), nil
+	})
+	if err != nil {
+		t.Errorf("expected ProcessFiles() to pass, got %s", err)
+	}
+	if len(output) != 1 || output[0].Language != "Python" || output[0].Percent != 100 {
+		t.Errorf("expected only Python, got %v", output)
+	}
+	if len(read) != 1 || read[0] != "run" {
+		t.Errorf("expected only the file without an extension to be read, got %v", read)
+	}
+}
+
 func TestGitAttributes(t *testing.T) {
 	testCases := []struct {
 		path         string
@@ -60,13 +165,29 @@ func TestGitAttributes(t *testing.T) {
 	}
 }
 
+// TestIgnoreFiles checks that the build output listed in .draftignore doesn't outweigh the application's code
+func TestIgnoreFiles(t *testing.T) {
+	output, err := ProcessDir(filepath.Join("testdirs", "app-draftignored"))
+	if err != nil {
+		t.Errorf("expected ProcessDir() to pass, got %s", err)
+	}
+	if output[0].Language != "Python" {
+		t.Errorf("expected output == 'Python', got '%s'", output[0].Language)
+	}
+	for _, lang := range output {
+		if lang.Language == "JavaScript" {
+			t.Errorf("expected the ignored build directory not to be classified")
+		}
+	}
+}
+
 //TestDirectoryIsIgnored checks to see if directory paths such as 'docs/' are ignored from being classified by linguist when added to the "ignore" list.
 func TestDirectoryIsIgnored(t *testing.T) {
 	path := filepath.Join("testdirs", "app-documentation")
 	// populate isIgnored
 	ProcessDir(path)
 	ignorePath := filepath.Join(path, "docs")
-	if !isIgnored(ignorePath) {
+	if !isIgnored(ignorePath, true) {
 		t.Errorf("expected dir '%s' to be ignored", ignorePath)
 	}
 }
@@ -86,3 +207,28 @@ func TestGetAlias(t *testing.T) {
 		}
 	}
 }
+
+func TestRuntimeAlias(t *testing.T) {
+	denoDir, bunDir, nodeDir := t.TempDir(), t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(denoDir, "deno.jsonc"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(bunDir, "bunfig.toml"), []byte(""), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		lang, dir, expected string
+	}{
+		{"TypeScript", denoDir, "deno"},
+		{"JavaScript", bunDir, "bun"},
+		{"TypeScript", nodeDir, "TypeScript"},
+		{"Python", denoDir, "Python"},
+	}
+	for _, tc := range testcases {
+		alias := RuntimeAlias(&Language{Language: tc.lang}, tc.dir)
+		if alias.Language != tc.expected {
+			t.Errorf("Expected alias to be '%s', got '%s'", tc.expected, alias.Language)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+function f0(a, b) { return document.getElementById(a).value + b; }
+function f1(a, b) { return document.getElementById(a).value + b; }
+function f2(a, b) { return document.getElementById(a).value + b; }
+function f3(a, b) { return document.getElementById(a).value + b; }
+function f4(a, b) { return document.getElementById(a).value + b; }
+function f5(a, b) { return document.getElementById(a).value + b; }
+function f6(a, b) { return document.getElementById(a).value + b; }
+function f7(a, b) { return document.getElementById(a).value + b; }
+function f8(a, b) { return document.getElementById(a).value + b; }
+function f9(a, b) { return document.getElementById(a).value + b; }
+function f10(a, b) { return document.getElementById(a).value + b; }
+function f11(a, b) { return document.getElementById(a).value + b; }
+function f12(a, b) { return document.getElementById(a).value + b; }
+function f13(a, b) { return document.getElementById(a).value + b; }
+function f14(a, b) { return document.getElementById(a).value + b; }
+function f15(a, b) { return document.getElementById(a).value + b; }
+function f16(a, b) { return document.getElementById(a).value + b; }
+function f17(a, b) { return document.getElementById(a).value + b; }
+function f18(a, b) { return document.getElementById(a).value + b; }
+function f19(a, b) { return document.getElementById(a).value + b; }
+function f20(a, b) { return document.getElementById(a).value + b; }
+function f21(a, b) { return document.getElementById(a).value + b; }
+function f22(a, b) { return document.getElementById(a).value + b; }
+function f23(a, b) { return document.getElementById(a).value + b; }
+function f24(a, b) { return document.getElementById(a).value + b; }
+function f25(a, b) { return document.getElementById(a).value + b; }
+function f26(a, b) { return document.getElementById(a).value + b; }
+function f27(a, b) { return document.getElementById(a).value + b; }
+function f28(a, b) { return document.getElementById(a).value + b; }
+function f29(a, b) { return document.getElementById(a).value + b; }
+function f30(a, b) { return document.getElementById(a).value + b; }
+function f31(a, b) { return document.getElementById(a).value + b; }
+function f32(a, b) { return document.getElementById(a).value + b; }
+function f33(a, b) { return document.getElementById(a).value + b; }
+function f34(a, b) { return document.getElementById(a).value + b; }
+function f35(a, b) { return document.getElementById(a).value + b; }
+function f36(a, b) { return document.getElementById(a).value + b; }
+function f37(a, b) { return document.getElementById(a).value + b; }
+function f38(a, b) { return document.getElementById(a).value + b; }
+function f39(a, b) { return document.getElementById(a).value + b; }
+function f40(a, b) { return document.getElementById(a).value + b; }
+function f41(a, b) { return document.getElementById(a).value + b; }
+function f42(a, b) { return document.getElementById(a).value + b; }
+function f43(a, b) { return document.getElementById(a).value + b; }
+function f44(a, b) { return document.getElementById(a).value + b; }
+function f45(a, b) { return document.getElementById(a).value + b; }
+function f46(a, b) { return document.getElementById(a).value + b; }
+function f47(a, b) { return document.getElementById(a).value + b; }
+function f48(a, b) { return document.getElementById(a).value + b; }
+function f49(a, b) { return document.getElementById(a).value + b; }
+function f50(a, b) { return document.getElementById(a).value + b; }
+function f51(a, b) { return document.getElementById(a).value + b; }
+function f52(a, b) { return document.getElementById(a).value + b; }
+function f53(a, b) { return document.getElementById(a).value + b; }
+function f54(a, b) { return document.getElementById(a).value + b; }
+function f55(a, b) { return document.getElementById(a).value + b; }
+function f56(a, b) { return document.getElementById(a).value + b; }
+function f57(a, b) { return document.getElementById(a).value + b; }
+function f58(a, b) { return document.getElementById(a).value + b; }
+function f59(a, b) { return document.getElementById(a).value + b; }
@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Log file formats supported by NewFileFormatter
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// NewFileFormatter returns the formatter for log files in format, which is text or json
+func NewFileFormatter(format string) (log.Formatter, error) {
+	switch format {
+	case FormatText:
+		return &log.TextFormatter{DisableColors: true, FullTimestamp: true}, nil
+	case FormatJSON:
+		return &log.JSONFormatter{}, nil
+	}
+	return nil, fmt.Errorf("unsupported log format %q, must be %s or %s", format, FormatText, FormatJSON)
+}
+
+// FileHook writes every log entry, with Fields added, to Writer using Formatter regardless of the console's level
+// and format
+type FileHook struct {
+	Writer    io.Writer
+	Formatter log.Formatter
+	Fields    log.Fields
+}
+
+func (h *FileHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *FileHook) Fire(entry *log.Entry) error {
+	withFields := entry.WithFields(h.Fields)
+	// WithFields drops these, and the formatters rely on them
+	withFields.Level = entry.Level
+	withFields.Message = entry.Message
+	withFields.Time = entry.Time
+	b, err := h.Formatter.Format(withFields)
+	if err != nil {
+		return err
+	}
+	_, err = h.Writer.Write(b)
+	return err
+}
+
+// ConsoleHook writes the log entries at Level and above to Writer using Formatter. It replaces the logger's own output
+// when the logger's level is lowered for a log file.
+type ConsoleHook struct {
+	Level     log.Level
+	Writer    io.Writer
+	Formatter log.Formatter
+}
+
+func (h *ConsoleHook) Levels() []log.Level {
+	return log.AllLevels[:h.Level+1]
+}
+
+func (h *ConsoleHook) Fire(entry *log.Entry) error {
+	b, err := h.Formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	_, err = h.Writer.Write(b)
+	return err
+}
+
+// OpenRotatingFile opens path for appending, creating it readable by its owner only. When it is already larger than
+// maxSize it is first rotated to path.1, shifting older logs up to path.<backups> and removing the oldest.
+func OpenRotatingFile(path string, maxSize int64, backups int) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() >= maxSize {
+		if err := rotate(path, backups); err != nil {
+			return nil, fmt.Errorf("rotating log file %s: %w", path, err)
+		}
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+}
+
+func rotate(path string, backups int) error {
+	if backups < 1 {
+		return os.Remove(path)
+	}
+	if err := os.Remove(fmt.Sprintf("%s.%d", path, backups)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := backups - 1; i >= 1; i-- {
+		if err := os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(path, path+".1")
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFileFormatter(t *testing.T) {
+	formatter, err := NewFileFormatter(FormatJSON)
+	assert.Nil(t, err)
+	assert.IsType(t, &log.JSONFormatter{}, formatter)
+
+	_, err = NewFileFormatter("xml")
+	assert.NotNil(t, err)
+}
+
+func TestHooks(t *testing.T) {
+	var file, console bytes.Buffer
+	logger := log.New()
+	logger.SetOutput(&bytes.Buffer{})
+	logger.SetLevel(log.DebugLevel)
+	logger.AddHook(&FileHook{Writer: &file, Formatter: &log.JSONFormatter{}, Fields: log.Fields{"command": "draft create"}})
+	logger.AddHook(&ConsoleHook{Level: log.InfoLevel, Writer: &console, Formatter: new(CustomFormatter)})
+
+	logger.Debug("detecting language")
+	logger.WithField("duration", "1s").Info("done")
+
+	assert.NotContains(t, console.String(), "detecting language")
+	assert.Contains(t, console.String(), "done")
+
+	lines := bytes.Split(bytes.TrimSpace(file.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+	var entry map[string]string
+	assert.Nil(t, json.Unmarshal(lines[1], &entry))
+	assert.Equal(t, "draft create", entry["command"])
+	assert.Equal(t, "1s", entry["duration"])
+	assert.Equal(t, "info", entry["level"])
+	assert.Equal(t, "done", entry["msg"])
+}
+
+func TestOpenRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "draft.log")
+
+	for _, content := range []string{"first", "second", "third"} {
+		f, err := OpenRotatingFile(path, 1, 1)
+		assert.Nil(t, err)
+		_, err = f.WriteString(content)
+		assert.Nil(t, err)
+		assert.Nil(t, f.Close())
+	}
+
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	current, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "third", string(current))
+	rotated, err := os.ReadFile(path + ".1")
+	assert.Nil(t, err)
+	assert.Equal(t, "second", string(rotated))
+	_, err = os.Stat(path + ".2")
+	assert.True(t, os.IsNotExist(err))
+
+	// files under the size limit are appended to
+	f, err := OpenRotatingFile(path, 100, 1)
+	assert.Nil(t, err)
+	_, err = f.WriteString(" more")
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+	current, err = os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "third more", string(current))
+}
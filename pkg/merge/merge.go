@@ -0,0 +1,125 @@
+// Package merge merges the changes two versions of a file made to a common base, like git merge does for a file.
+package merge
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	conflictStart = "<<<<<<< "
+	conflictSep   = "=======\n"
+	conflictEnd   = ">>>>>>> "
+)
+
+// Result is a merged file and the number of conflicting hunks marked in it
+type Result struct {
+	Content   []byte
+	Conflicts int
+}
+
+// ThreeWay merges the changes ours and theirs made to base line by line. A hunk changed only on one side takes that
+// side's lines, and a hunk both sides changed differently is kept as a conflict between git style markers labelled
+// oursLabel and theirsLabel.
+func ThreeWay(base, ours, theirs []byte, oursLabel, theirsLabel string) Result {
+	baseLines, ourLines, theirLines := splitLines(base), splitLines(ours), splitLines(theirs)
+	ourMatch := matchLines(baseLines, ourLines)
+	theirMatch := matchLines(baseLines, theirLines)
+
+	var sb strings.Builder
+	var result Result
+	b, o, t := 0, 0, 0
+	resolve := func(baseEnd, ourEnd, theirEnd int) {
+		baseHunk, ourHunk, theirHunk := baseLines[b:baseEnd], ourLines[o:ourEnd], theirLines[t:theirEnd]
+		switch {
+		case equal(ourHunk, baseHunk):
+			writeLines(&sb, theirHunk)
+		case equal(theirHunk, baseHunk), equal(ourHunk, theirHunk):
+			writeLines(&sb, ourHunk)
+		default:
+			result.Conflicts++
+			sb.WriteString(conflictStart + oursLabel + "\n")
+			writeLines(&sb, ourHunk)
+			sb.WriteString(conflictSep)
+			writeLines(&sb, theirHunk)
+			sb.WriteString(conflictEnd + theirsLabel + "\n")
+		}
+	}
+
+	// base lines kept by both sides anchor the hunks between them
+	for i := range baseLines {
+		j, k := ourMatch[i], theirMatch[i]
+		if j < o || k < t {
+			continue
+		}
+		resolve(i, j, k)
+		sb.WriteString(baseLines[i])
+		b, o, t = i+1, j+1, k+1
+	}
+	resolve(len(baseLines), len(ourLines), len(theirLines))
+
+	content := sb.String()
+	if !strings.HasSuffix(string(ours), "\n") && !strings.HasSuffix(string(theirs), "\n") {
+		content = strings.TrimSuffix(content, "\n")
+	}
+	result.Content = []byte(content)
+	return result
+}
+
+// HasConflicts reports whether content still holds conflict markers written by ThreeWay
+func HasConflicts(content []byte) bool {
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, conflictStart) || strings.HasPrefix(line, conflictEnd) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// matchLines returns the index of the line of other each line of base was matched with, or -1 if it wasn't
+func matchLines(base, other []string) []int {
+	match := make([]int, len(base))
+	for i := range match {
+		match[i] = -1
+	}
+	for _, block := range difflib.NewMatcher(base, other).GetMatchingBlocks() {
+		for n := 0; n < block.Size; n++ {
+			match[block.A+n] = block.B + n
+		}
+	}
+	return match
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// writeLines writes lines, ending the last one with a newline so markers that follow start on their own line
+func writeLines(sb *strings.Builder, lines []string) {
+	for _, line := range lines {
+		sb.WriteString(line)
+	}
+	if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		sb.WriteString("\n")
+	}
+}
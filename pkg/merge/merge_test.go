@@ -0,0 +1,63 @@
+package merge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThreeWay(t *testing.T) {
+	base := "FROM golang:1.20\nENV PORT=80\nEXPOSE 80\nCMD [\"/app\"]\n"
+	tests := []struct {
+		name          string
+		ours, theirs  string
+		want          string
+		wantConflicts int
+	}{
+		{
+			name:   "user edit and template change in different hunks",
+			ours:   "FROM golang:1.20\nENV PORT=80\nENV GIN_MODE=release\nEXPOSE 80\nCMD [\"/app\"]\n",
+			theirs: "FROM golang:1.22\nENV PORT=80\nEXPOSE 80\nCMD [\"/app\"]\n",
+			want:   "FROM golang:1.22\nENV PORT=80\nENV GIN_MODE=release\nEXPOSE 80\nCMD [\"/app\"]\n",
+		},
+		{
+			name:   "only the template changed",
+			ours:   base,
+			theirs: "FROM golang:1.22\nENV PORT=80\nEXPOSE 80\nUSER nonroot\nCMD [\"/app\"]\n",
+			want:   "FROM golang:1.22\nENV PORT=80\nEXPOSE 80\nUSER nonroot\nCMD [\"/app\"]\n",
+		},
+		{
+			name:   "same change on both sides",
+			ours:   "FROM golang:1.22\nENV PORT=80\nEXPOSE 80\nCMD [\"/app\"]\n",
+			theirs: "FROM golang:1.22\nENV PORT=80\nEXPOSE 80\nCMD [\"/app\"]\n",
+			want:   "FROM golang:1.22\nENV PORT=80\nEXPOSE 80\nCMD [\"/app\"]\n",
+		},
+		{
+			name:          "conflicting changes",
+			ours:          "FROM golang:1.21\nENV PORT=80\nEXPOSE 80\nCMD [\"/app\"]\n",
+			theirs:        "FROM golang:1.22\nENV PORT=80\nEXPOSE 80\nCMD [\"/app\"]\n",
+			want:          "<<<<<<< existing\nFROM golang:1.21\n=======\nFROM golang:1.22\n>>>>>>> template\nENV PORT=80\nEXPOSE 80\nCMD [\"/app\"]\n",
+			wantConflicts: 1,
+		},
+		{
+			name:   "user deleted a line",
+			ours:   "FROM golang:1.20\nEXPOSE 80\nCMD [\"/app\"]\n",
+			theirs: "FROM golang:1.20\nENV PORT=80\nEXPOSE 80\nCMD [\"/app\", \"serve\"]\n",
+			want:   "FROM golang:1.20\nEXPOSE 80\nCMD [\"/app\", \"serve\"]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ThreeWay([]byte(base), []byte(tt.ours), []byte(tt.theirs), "existing", "template")
+			assert.Equal(t, tt.want, string(got.Content))
+			assert.Equal(t, tt.wantConflicts, got.Conflicts)
+			assert.Equal(t, tt.wantConflicts > 0, HasConflicts(got.Content))
+		})
+	}
+}
+
+func TestThreeWayNoTrailingNewline(t *testing.T) {
+	got := ThreeWay([]byte("a\nb"), []byte("a\nb"), []byte("a\nc"), "existing", "template")
+	assert.Equal(t, "a\nc", string(got.Content))
+	assert.Equal(t, 0, got.Conflicts)
+}
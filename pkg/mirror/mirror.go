@@ -0,0 +1,224 @@
+package mirror
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// DefaultRegistries are the public registries whose images are pulled through a mirror unless others are configured
+var DefaultRegistries = []string{"docker.io", "mcr.microsoft.com", "gcr.io", "ghcr.io", "quay.io", "registry.k8s.io", "public.ecr.aws"}
+
+// dockerHub is the registry of the images referenced without one
+const dockerHub = "docker.io"
+
+var prefixRegex = regexp.MustCompile(`^([a-z0-9]([a-z0-9.-]*[a-z0-9])?(:[0-9]+)?)(/[a-z0-9._-]+)*/?$`)
+
+// Mirror rewrites the references to images of its Registries in generated files to pull them through a registry
+// mirror, for networks that block pulling from the public registries. An image is pulled from Prefix followed by its
+// registry and repository, so registry.corp/proxy/ pulls golang:1.22 as registry.corp/proxy/docker.io/library/golang:1.22
+// and mcr.microsoft.com/dotnet/sdk:8.0 as registry.corp/proxy/mcr.microsoft.com/dotnet/sdk:8.0.
+type Mirror struct {
+	Prefix     string
+	Registries []string
+	// keep are the full names of the images never pulled through the mirror, such as that of the application
+	keep map[string]bool
+}
+
+// New returns a Mirror pulling the images of registries, or of the DefaultRegistries when there are none, through
+// prefix, a registry host optionally followed by a path such as registry.corp/proxy/
+func New(prefix string, registries []string) (*Mirror, error) {
+	match := prefixRegex.FindStringSubmatch(prefix)
+	if match == nil || !isRegistryHost(match[1]) {
+		return nil, fmt.Errorf("invalid image mirror %q, must be a registry host optionally followed by a path, such as registry.corp/proxy/", prefix)
+	}
+	if len(registries) == 0 {
+		registries = DefaultRegistries
+	}
+	normalized := make([]string, 0, len(registries))
+	for _, registry := range registries {
+		normalized = append(normalized, normalizeRegistry(registry))
+	}
+	return &Mirror{Prefix: strings.TrimSuffix(prefix, "/") + "/", Registries: normalized, keep: make(map[string]bool)}, nil
+}
+
+// Keep excludes images from the mirror, such as that of the application, which is pushed to a registry of its own
+func (m *Mirror) Keep(images ...string) {
+	for _, image := range images {
+		if image == "" {
+			continue
+		}
+		registry, repository, _ := splitImage(image)
+		m.keep[registry+"/"+repository] = true
+	}
+}
+
+// Image returns ref pulled through the mirror, or ref when it isn't an image of the Registries or is kept
+func (m *Mirror) Image(ref string) string {
+	if ref == "" || strings.ContainsAny(ref, "${} \t") || strings.HasPrefix(ref, m.Prefix) {
+		return ref
+	}
+	registry, repository, suffix := splitImage(ref)
+	name := registry + "/" + repository
+	if repository == "" || m.keep[name] || !m.mirrors(registry) {
+		return ref
+	}
+	return m.Prefix + name + suffix
+}
+
+func (m *Mirror) mirrors(registry string) bool {
+	for _, r := range m.Registries {
+		if r == registry {
+			return true
+		}
+	}
+	return false
+}
+
+// Rewrite pulls the base images of a generated Dockerfile, and the images of the containers of a generated yaml file,
+// through the mirror. Other files, and yaml files that aren't plain yaml such as helm chart templates, are returned
+// unchanged.
+func (m *Mirror) Rewrite(filePath string, content []byte) ([]byte, error) {
+	if isDockerfile(filePath) {
+		return m.rewriteDockerfile(content), nil
+	}
+	ext := strings.ToLower(filepath.Ext(filePath))
+	if ext != ".yaml" && ext != ".yml" {
+		return content, nil
+	}
+
+	var out bytes.Buffer
+	rw := &kio.ByteReadWriter{Reader: bytes.NewReader(content), Writer: &out, PreserveSeqIndent: true}
+	nodes, err := rw.Read()
+	if err != nil {
+		log.Debugf("not pulling the images of %s through the mirror, it isn't plain yaml: %s", filePath, err)
+		return content, nil
+	}
+	changed := false
+	for _, node := range nodes {
+		if m.rewriteImages(node.YNode()) {
+			changed = true
+		}
+	}
+	if !changed {
+		return content, nil
+	}
+	if err := rw.Write(nodes); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// rewriteImages rewrites the string values of the image fields under node, and reports whether any changed. The image
+// of a helm chart is a map of its repository and tag, which is left alone.
+func (m *Mirror) rewriteImages(node *kyaml.Node) bool {
+	changed := false
+	if node.Kind == kyaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "image" && value.Kind == kyaml.ScalarNode && value.Tag == kyaml.NodeTagString {
+				if image := m.Image(value.Value); image != value.Value {
+					value.Value = image
+					changed = true
+				}
+				continue
+			}
+			if m.rewriteImages(value) {
+				changed = true
+			}
+		}
+		return changed
+	}
+	for _, child := range node.Content {
+		if m.rewriteImages(child) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// rewriteDockerfile rewrites the images of the FROM and COPY --from instructions of a Dockerfile, skipping its build
+// stages
+func (m *Mirror) rewriteDockerfile(content []byte) []byte {
+	var out bytes.Buffer
+	stages := map[string]bool{"scratch": true}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) >= 2 {
+			switch strings.ToUpper(fields[0]) {
+			case "FROM":
+				args := fields[1:]
+				for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+					args = args[1:]
+				}
+				if len(args) > 0 && !stages[strings.ToLower(args[0])] {
+					line = strings.Replace(line, args[0], m.Image(args[0]), 1)
+				}
+				if len(args) >= 3 && strings.EqualFold(args[1], "AS") {
+					stages[strings.ToLower(args[2])] = true
+				}
+			case "COPY":
+				for _, arg := range fields[1:] {
+					from, ok := strings.CutPrefix(arg, "--from=")
+					if !ok || stages[strings.ToLower(from)] || !strings.ContainsAny(from, ":/@") {
+						continue
+					}
+					line = strings.Replace(line, arg, "--from="+m.Image(from), 1)
+				}
+			}
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	if !bytes.HasSuffix(content, []byte("\n")) {
+		out.Truncate(out.Len() - 1)
+	}
+	return out.Bytes()
+}
+
+// splitImage splits an image reference into its registry, docker.io when it has none, its repository, under library/
+// for the official images of Docker Hub, and its :tag and @digest
+func splitImage(ref string) (registry, repository, suffix string) {
+	name := ref
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, suffix = name[:i], name[i:]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, suffix = name[:i], name[i:]+suffix
+	}
+	registry, repository = dockerHub, name
+	if first, rest, ok := strings.Cut(name, "/"); ok && isRegistryHost(first) {
+		registry, repository = normalizeRegistry(first), rest
+	}
+	if registry == dockerHub && repository != "" && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	return registry, repository, suffix
+}
+
+// isRegistryHost reports whether the first part of an image name is a registry rather than a Docker Hub namespace
+func isRegistryHost(host string) bool {
+	return strings.ContainsAny(host, ".:") || host == "localhost"
+}
+
+func normalizeRegistry(registry string) string {
+	if registry == "index.docker.io" || registry == "registry-1.docker.io" {
+		return dockerHub
+	}
+	return registry
+}
+
+func isDockerfile(p string) bool {
+	base := path.Base(filepath.ToSlash(p))
+	return base == "Dockerfile" || strings.HasPrefix(base, "Dockerfile.") || strings.HasSuffix(base, ".dockerfile")
+}
@@ -0,0 +1,120 @@
+package mirror
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImage(t *testing.T) {
+	m, err := New("registry.corp/proxy", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "registry.corp/proxy/", m.Prefix)
+	m.Keep("app")
+
+	tests := map[string]string{
+		"golang:1.22":                                       "registry.corp/proxy/docker.io/library/golang:1.22",
+		"fluent/fluent-bit:3.0":                             "registry.corp/proxy/docker.io/fluent/fluent-bit:3.0",
+		"docker.io/library/nginx":                           "registry.corp/proxy/docker.io/library/nginx",
+		"index.docker.io/library/nginx":                     "registry.corp/proxy/docker.io/library/nginx",
+		"mcr.microsoft.com/dotnet/sdk:8.0":                  "registry.corp/proxy/mcr.microsoft.com/dotnet/sdk:8.0",
+		"busybox@sha256:abc":                                "registry.corp/proxy/docker.io/library/busybox@sha256:abc",
+		"registry.corp/proxy/docker.io/library/golang:1.22": "registry.corp/proxy/docker.io/library/golang:1.22",
+		"myacr.azurecr.io/app:v1":                           "myacr.azurecr.io/app:v1",
+		"localhost:5000/tools":                              "localhost:5000/tools",
+		"app:latest":                                        "app:latest",
+		"${BASE_IMAGE}":                                     "${BASE_IMAGE}",
+		":latest":                                           ":latest",
+	}
+	for ref, want := range tests {
+		assert.Equal(t, want, m.Image(ref), ref)
+	}
+
+	m, err = New("registry.corp/proxy/", []string{"mcr.microsoft.com"})
+	assert.Nil(t, err)
+	assert.Equal(t, "golang:1.22", m.Image("golang:1.22"))
+	assert.Equal(t, "registry.corp/proxy/mcr.microsoft.com/dotnet/sdk:8.0", m.Image("mcr.microsoft.com/dotnet/sdk:8.0"))
+
+	for _, invalid := range []string{"", "proxy/", "Registry.corp/", "registry.corp//proxy", "https://registry.corp/proxy"} {
+		_, err := New(invalid, nil)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	m, err := New("registry.corp/proxy/", nil)
+	assert.Nil(t, err)
+	m.Keep("app")
+
+	out, err := m.Rewrite("Dockerfile", []byte(`FROM golang:1.22 AS builder
+COPY . .
+FROM --platform=linux/amd64 gcr.io/distroless/static-debian12 AS hardened-true
+FROM builder AS hardened-false
+FROM hardened-true
+COPY --from=builder /app /app
+COPY --from=mcr.microsoft.com/dotnet/runtime:8.0 /usr/share/dotnet /usr/share/dotnet
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, `FROM registry.corp/proxy/docker.io/library/golang:1.22 AS builder
+COPY . .
+FROM --platform=linux/amd64 registry.corp/proxy/gcr.io/distroless/static-debian12 AS hardened-true
+FROM builder AS hardened-false
+FROM hardened-true
+COPY --from=builder /app /app
+COPY --from=registry.corp/proxy/mcr.microsoft.com/dotnet/runtime:8.0 /usr/share/dotnet /usr/share/dotnet
+`, string(out))
+
+	out, err = m.Rewrite("manifests/deployment.yaml", []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: migrate
+          image: busybox:1.36
+      containers:
+        - name: app
+          image: app:latest
+        - name: logs
+          image: "fluent/fluent-bit:3.0"
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: migrate
+          image: registry.corp/proxy/docker.io/library/busybox:1.36
+      containers:
+        - name: app
+          image: app:latest
+        - name: logs
+          image: "registry.corp/proxy/docker.io/fluent/fluent-bit:3.0"
+`, string(out))
+
+	out, err = m.Rewrite("charts/values.yaml", []byte(`image:
+  repository: nginx
+  tag: "latest"
+sidecars: [{"name": "logs", "image": "fluent/fluent-bit:3.0"}]
+`))
+	assert.Nil(t, err)
+	assert.Contains(t, string(out), "repository: nginx\n")
+	assert.Contains(t, string(out), `"image": "registry.corp/proxy/docker.io/fluent/fluent-bit:3.0"`)
+
+	unchanged := map[string]string{
+		"charts/templates/deployment.yaml": "spec:\n  image: \"{{ .Values.image.repository }}\"\n  {{- with .Values.sidecars }}\n",
+		"manifests/service.yaml":           "apiVersion: v1\nkind: Service\nmetadata:\n  name: app\n",
+		"README.md":                        "FROM golang\n",
+	}
+	for path, content := range unchanged {
+		out, err := m.Rewrite(path, []byte(content))
+		assert.Nil(t, err, path)
+		assert.Equal(t, content, string(out), path)
+	}
+}
@@ -0,0 +1,74 @@
+// Package netconfig configures the outbound connections of draft for corporate networks: the proxy of the HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY environment variables, and the certificate authorities of a custom CA bundle, such as that
+// of a TLS intercepting proxy. The http clients of draft take both from Transport, and the az, gh and git clis it runs
+// read the CA bundle from their environment.
+package netconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// caBundleEnvs are the environment variables the clis draft runs read their CA bundle from: SSL_CERT_FILE for gh and
+// other go programs, GIT_SSL_CAINFO for git and REQUESTS_CA_BUNDLE for the python az cli
+var caBundleEnvs = []string{"SSL_CERT_FILE", "GIT_SSL_CAINFO", "REQUESTS_CA_BUNDLE"}
+
+var rootCAs *x509.CertPool
+
+// SetCABundle trusts the certificate authorities of the PEM file path, along with the system roots, in the
+// connections of Transport. It also points the environment variables of caBundleEnvs that aren't set yet at path,
+// where the clis draft runs use it instead of their default roots. An empty path trusts the system roots only.
+func SetCABundle(path string) error {
+	if path == "" {
+		rootCAs = nil
+		return nil
+	}
+
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		log.Debugf("not trusting the system roots along with %s: %s", path, err)
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no PEM certificates found in %s", path)
+	}
+
+	for _, name := range caBundleEnvs {
+		if os.Getenv(name) != "" {
+			continue
+		}
+		if err := os.Setenv(name, path); err != nil {
+			return err
+		}
+	}
+	rootCAs = pool
+	return nil
+}
+
+// Transport returns an http transport using the proxy of the environment and trusting the CA bundle set with
+// SetCABundle
+func Transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if rootCAs != nil {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.RootCAs = rootCAs
+	}
+	return transport
+}
+
+// HTTPClient returns an http client with the Transport of the proxy and CA bundle
+func HTTPClient() *http.Client {
+	return &http.Client{Transport: Transport()}
+}
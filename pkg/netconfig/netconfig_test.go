@@ -0,0 +1,44 @@
+package netconfig
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetCABundle(t *testing.T) {
+	for _, name := range caBundleEnvs {
+		t.Setenv(name, "")
+	}
+	t.Setenv("GIT_SSL_CAINFO", "/etc/git-ca.pem")
+	defer SetCABundle("")
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	assert.Nil(t, os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600))
+
+	_, err := HTTPClient().Get(server.URL)
+	assert.NotNil(t, err, "the server's certificate isn't trusted without the bundle")
+
+	assert.Nil(t, SetCABundle(bundle))
+	resp, err := HTTPClient().Get(server.URL)
+	assert.Nil(t, err)
+	if err == nil {
+		resp.Body.Close()
+	}
+	assert.Equal(t, bundle, os.Getenv("SSL_CERT_FILE"))
+	assert.Equal(t, bundle, os.Getenv("REQUESTS_CA_BUNDLE"))
+	assert.Equal(t, "/etc/git-ca.pem", os.Getenv("GIT_SSL_CAINFO"), "variables that are already set are kept")
+	assert.NotNil(t, Transport().Proxy)
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	assert.Nil(t, os.WriteFile(notPEM, []byte("not a certificate"), 0600))
+	assert.EqualError(t, SetCABundle(notPEM), "no PEM certificates found in "+notPEM)
+	assert.NotNil(t, SetCABundle(filepath.Join(t.TempDir(), "missing.pem")))
+}
@@ -0,0 +1,83 @@
+package osutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GitDestinationPrefix marks a destination as relative to the root of the enclosing git repository
+const GitDestinationPrefix = "git:"
+
+// ErrNotInGitRepo is returned when no enclosing git repository is found
+var ErrNotInGitRepo = errors.New("not inside a git repository")
+
+// RepoRoot returns the closest directory at or above dir that contains a .git entry
+func RepoRoot(dir string) (string, error) {
+	current, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		if exists, err := Exists(filepath.Join(current, ".git")); err != nil {
+			return "", err
+		} else if exists {
+			return current, nil
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", ErrNotInGitRepo
+		}
+		current = parent
+	}
+}
+
+// ResolveDestination expands a destination with GitDestinationPrefix to a path below the root of the git repository
+// enclosing the working directory. Other destinations are returned unchanged.
+func ResolveDestination(dest string) (string, error) {
+	rel, ok := strings.CutPrefix(dest, GitDestinationPrefix)
+	if !ok {
+		return dest, nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	root, err := RepoRoot(wd)
+	if err != nil {
+		return "", fmt.Errorf("resolving destination %s: %w", dest, err)
+	}
+
+	resolved := filepath.Join(root, rel)
+	if resolved != root && !strings.HasPrefix(resolved, root+string(filepath.Separator)) {
+		return "", fmt.Errorf("destination %s is outside of the git repository %s", dest, root)
+	}
+	return resolved, nil
+}
+
+// UnsafeDestinationReason returns why writing generated files to dest needs confirmation, or an empty string when
+// dest is inside a git repository and is neither the home directory nor the filesystem root
+func UnsafeDestinationReason(dest string) (string, error) {
+	absDest, err := filepath.Abs(dest)
+	if err != nil {
+		return "", err
+	}
+
+	if filepath.Dir(absDest) == absDest {
+		return fmt.Sprintf("%s is the filesystem root", absDest), nil
+	}
+	if home, err := os.UserHomeDir(); err == nil && filepath.Clean(home) == absDest {
+		return fmt.Sprintf("%s is your home directory", absDest), nil
+	}
+	if _, err := RepoRoot(absDest); errors.Is(err, ErrNotInGitRepo) {
+		return fmt.Sprintf("%s is not inside a git repository", absDest), nil
+	} else if err != nil {
+		return "", err
+	}
+
+	return "", nil
+}
@@ -0,0 +1,71 @@
+package osutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoRoot(t *testing.T) {
+	root := t.TempDir()
+	assert.Nil(t, os.Mkdir(filepath.Join(root, ".git"), 0755))
+	nested := filepath.Join(root, "a", "b")
+	assert.Nil(t, os.MkdirAll(nested, 0755))
+
+	found, err := RepoRoot(nested)
+	assert.Nil(t, err)
+	assert.Equal(t, root, found)
+
+	// destinations that do not exist yet still resolve to the enclosing repository
+	found, err = RepoRoot(filepath.Join(nested, "missing"))
+	assert.Nil(t, err)
+	assert.Equal(t, root, found)
+}
+
+func TestResolveDestination(t *testing.T) {
+	root := t.TempDir()
+	assert.Nil(t, os.Mkdir(filepath.Join(root, ".git"), 0755))
+	nested := filepath.Join(root, "src", "app")
+	assert.Nil(t, os.MkdirAll(nested, 0755))
+
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	defer os.Chdir(wd)
+	assert.Nil(t, os.Chdir(nested))
+
+	resolved, err := ResolveDestination("git:deploy/charts")
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(root, "deploy", "charts"), resolved)
+
+	resolved, err = ResolveDestination("git:")
+	assert.Nil(t, err)
+	assert.Equal(t, root, resolved)
+
+	_, err = ResolveDestination("git:../outside")
+	assert.NotNil(t, err)
+
+	resolved, err = ResolveDestination("./local")
+	assert.Nil(t, err)
+	assert.Equal(t, "./local", resolved)
+}
+
+func TestUnsafeDestinationReason(t *testing.T) {
+	repo := t.TempDir()
+	assert.Nil(t, os.Mkdir(filepath.Join(repo, ".git"), 0755))
+
+	reason, err := UnsafeDestinationReason(repo)
+	assert.Nil(t, err)
+	assert.Empty(t, reason)
+
+	reason, err = UnsafeDestinationReason(string(filepath.Separator))
+	assert.Nil(t, err)
+	assert.Contains(t, reason, "filesystem root")
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	reason, err = UnsafeDestinationReason(home)
+	assert.Nil(t, err)
+	assert.Contains(t, reason, "home directory")
+}
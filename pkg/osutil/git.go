@@ -0,0 +1,44 @@
+package osutil
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+)
+
+// UncommittedFiles returns the absolute paths of the files with uncommitted changes in the git repository enclosing
+// dir, untracked files included. It returns ErrNotInGitRepo outside of a git repository.
+func UncommittedFiles(dir string) ([]string, error) {
+	root, err := RepoRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("git", "-C", root, "status", "--porcelain=v1", "-z", "--untracked-files=all")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return parsePorcelainStatus(root, out), nil
+}
+
+// parsePorcelainStatus returns the paths of the entries of 'git status --porcelain=v1 -z' output, joined to root
+func parsePorcelainStatus(root string, out []byte) []string {
+	var files []string
+	entries := bytes.Split(out, []byte{0})
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		files = append(files, filepath.Join(root, filepath.FromSlash(string(entry[3:]))))
+		// renames and copies are followed by the entry of their original path, which is unchanged on disk
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+		}
+	}
+	return files
+}
@@ -0,0 +1,53 @@
+package osutil
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePorcelainStatus(t *testing.T) {
+	out := []byte(" M Dockerfile\x00?? charts/values.yaml\x00R  manifests/new.yaml\x00manifests/old.yaml\x00A  a b.yaml\x00")
+	assert.Equal(t, []string{
+		filepath.Join("/repo", "Dockerfile"),
+		filepath.Join("/repo", "charts", "values.yaml"),
+		filepath.Join("/repo", "manifests", "new.yaml"),
+		filepath.Join("/repo", "a b.yaml"),
+	}, parsePorcelainStatus("/repo", out))
+	assert.Empty(t, parsePorcelainStatus("/repo", nil))
+}
+
+func TestUncommittedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", root, "-c", "user.name=draft", "-c", "user.email=draft@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		assert.Nil(t, err, string(out))
+	}
+	git("init", "-q")
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "Dockerfile"), []byte("FROM scratch\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "README.md"), []byte("readme\n"), 0644))
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	files, err := UncommittedFiles(root)
+	assert.Nil(t, err)
+	assert.Empty(t, files)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "Dockerfile"), []byte("FROM alpine\n"), 0644))
+	assert.Nil(t, os.MkdirAll(filepath.Join(root, "charts"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "charts", "values.yaml"), []byte("{}\n"), 0644))
+	files, err = UncommittedFiles(filepath.Join(root, "charts"))
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{filepath.Join(root, "Dockerfile"), filepath.Join(root, "charts", "values.yaml")}, files)
+
+	_, err = UncommittedFiles(t.TempDir())
+	assert.True(t, errors.Is(err, ErrNotInGitRepo))
+}
@@ -7,12 +7,15 @@ import (
 	"path"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"syscall"
 
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/templaterender"
 	"github.com/Azure/draft/pkg/templatewriter"
 )
 
@@ -78,12 +81,97 @@ func EnsureFile(file string) error {
 	return nil
 }
 
+// UnsubstitutedVariable is a draft variable placeholder left in a rendered file
+type UnsubstitutedVariable struct {
+	File        string
+	Line        int
+	Placeholder string
+}
+
+// UnsubstitutedVariablesError is returned when rendered files still contain draft variable placeholders
+type UnsubstitutedVariablesError struct {
+	Variables []UnsubstitutedVariable
+}
+
+func (e *UnsubstitutedVariablesError) Error() string {
+	var names []string
+	seen := make(map[string]bool)
+	locations := make([]string, 0, len(e.Variables))
+	for _, v := range e.Variables {
+		name := strings.TrimSuffix(strings.TrimPrefix(v.Placeholder, "{{"), "}}")
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+		locations = append(locations, fmt.Sprintf("%s:%d: %s", v.File, v.Line, v.Placeholder))
+	}
+	return fmt.Sprintf("unsubstituted variables %s:\n  %s", strings.Join(names, ", "), strings.Join(locations, "\n  "))
+}
+
+type renderedFile struct {
+	srcPath string
+	path    string
+	content []byte
+	isDir   bool
+	// unsubstituted are the draft variable placeholders left in content
+	unsubstituted []UnsubstitutedVariable
+}
+
+// renderWorkers bounds the number of files CopyDir renders at once
+var renderWorkers = runtime.GOMAXPROCS(0)
+
+// CopyDir renders every file under src with customInputs and writes the result to dest. All files are rendered
+// before anything is written, so an unsubstituted variable in any file fails the copy without partial output. Files
+// are rendered concurrently but written in the order of the walk, since template writers such as the dry run's record
+// the files in the order they're written and needn't be safe for concurrent use.
 func CopyDir(
 	fileSys fs.FS,
 	src, dest string,
 	config *config.DraftConfig,
 	customInputs map[string]string,
 	templateWriter templatewriter.TemplateWriter) error {
+	if config != nil {
+		if err := config.ValidateVariableValues(customInputs); err != nil {
+			return err
+		}
+	}
+
+	var rendered []renderedFile
+	if err := walkDir(fileSys, src, dest, "", config, customInputs, &rendered); err != nil {
+		return err
+	}
+	var delimiters []string
+	if config != nil {
+		delimiters = config.TemplateDelimiters
+	}
+	if err := renderFiles(fileSys, rendered, customInputs, delimiters); err != nil {
+		return err
+	}
+
+	var unsubstituted []UnsubstitutedVariable
+	for _, f := range rendered {
+		unsubstituted = append(unsubstituted, f.unsubstituted...)
+	}
+	if len(unsubstituted) > 0 {
+		return &UnsubstitutedVariablesError{Variables: unsubstituted}
+	}
+
+	for _, f := range rendered {
+		if f.isDir {
+			if err := templateWriter.EnsureDirectory(f.path); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := templateWriter.WriteFile(f.path, f.content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkDir lists the directories and enabled files under src in walk order, without rendering them
+func walkDir(fileSys fs.FS, src, dest, relDir string, config *config.DraftConfig, customInputs map[string]string, rendered *[]renderedFile) error {
 	files, err := fs.ReadDir(fileSys, src)
 	if err != nil {
 		return err
@@ -97,62 +185,81 @@ func CopyDir(
 
 		srcPath := path.Join(src, f.Name())
 		destPath := path.Join(dest, f.Name())
+		relPath := path.Join(relDir, f.Name())
 		log.Debugf("Source path: %s Dest path: %s", srcPath, destPath)
 
+		if config != nil && !config.IsFileEnabled(relPath, customInputs) {
+			log.Debugf("skipping optional file %s", relPath)
+			continue
+		}
+
 		if f.IsDir() {
-			if err = templateWriter.EnsureDirectory(destPath); err != nil {
-				return err
-			}
-			if err = CopyDir(fileSys, srcPath, destPath, config, customInputs, templateWriter); err != nil {
+			*rendered = append(*rendered, renderedFile{srcPath: srcPath, path: destPath, isDir: true})
+			if err = walkDir(fileSys, srcPath, destPath, relPath, config, customInputs, rendered); err != nil {
 				return err
 			}
 		} else {
-			fileContent, err := replaceTemplateVariables(fileSys, srcPath, customInputs)
-			if err != nil {
-				return err
-			}
-
-			if err = checkAllVariablesSubstituted(string(fileContent)); err != nil {
-				return fmt.Errorf("error substituting file %s: %w", srcPath, err)
-			}
+			*rendered = append(*rendered, renderedFile{srcPath: srcPath, path: destPath})
+		}
+	}
+	return nil
+}
 
-			if err = templateWriter.WriteFile(destPath, fileContent); err != nil {
+// renderFiles renders the files of rendered in place with at most renderWorkers at once, returning the first error
+func renderFiles(fileSys fs.FS, rendered []renderedFile, customInputs map[string]string, delimiters []string) error {
+	var g errgroup.Group
+	g.SetLimit(renderWorkers)
+	for i := range rendered {
+		if rendered[i].isDir {
+			continue
+		}
+		// each worker only writes to its own element, so the slice needs no lock
+		f := &rendered[i]
+		g.Go(func() error {
+			content, err := replaceTemplateVariables(fileSys, f.srcPath, customInputs, delimiters)
+			if err != nil {
 				return err
 			}
-		}
+			f.content = content
+			f.unsubstituted = findUnsubstitutedVariables(f.path, string(content))
+			return nil
+		})
 	}
-	return nil
+	return g.Wait()
 }
 
 /*
-	checkAllVariablesSubstituted checks that all draft variables have been substituted.
+	findUnsubstitutedVariables returns every draft variable placeholder left in fileContent along with its line number.
 
-If any draft variables are found, an error is returned.
 Draft variables are defined as a string of non-whitespace characters starting with a non-period character wrapped in double curly braces.
 The non-period first character constraint is used to avoid matching helm template functions.
 */
-func checkAllVariablesSubstituted(fileContent string) error {
-	if unsubstitutedVars := draftVariableRegex.FindAllString(fileContent, -1); len(unsubstitutedVars) > 0 {
-		unsubstitutedVarsString := strings.Join(unsubstitutedVars, ", ")
-		return fmt.Errorf("unsubstituted variable: %s", unsubstitutedVarsString)
+func findUnsubstitutedVariables(filePath, fileContent string) []UnsubstitutedVariable {
+	var found []UnsubstitutedVariable
+	for i, line := range strings.Split(fileContent, "\n") {
+		for _, placeholder := range draftVariableRegex.FindAllString(line, -1) {
+			found = append(found, UnsubstitutedVariable{File: filePath, Line: i + 1, Placeholder: placeholder})
+		}
 	}
-	return nil
+	return found
 }
 
-func replaceTemplateVariables(fileSys fs.FS, srcPath string, customInputs map[string]string) ([]byte, error) {
+// SortedKeys returns the keys of m in byte order, which doesn't depend on the locale
+func SortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func replaceTemplateVariables(fileSys fs.FS, srcPath string, customInputs map[string]string, delimiters []string) ([]byte, error) {
 	file, err := fs.ReadFile(fileSys, srcPath)
 	if err != nil {
 		return nil, err
 	}
-
-	fileString := string(file)
-
-	for oldString, newString := range customInputs {
-		log.Debugf("replacing %s with %s", oldString, newString)
-		fileString = strings.ReplaceAll(fileString, "{{"+oldString+"}}", newString)
-	}
-
-	return []byte(fileString), nil
+	return templaterender.Render(srcPath, file, customInputs, delimiters)
 }
 
 func checkNameOverrides(fileName, srcPath, destPath string, config *config.DraftConfig) string {
@@ -1,12 +1,17 @@
 package osutil
 
 import (
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/config"
 )
 
 func TestExists(t *testing.T) {
@@ -110,9 +115,122 @@ func TestAllVariablesSubstituted(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.String, func(t *testing.T) {
-			err := checkAllVariablesSubstituted(test.String)
-			didError := err != nil
-			assert.Equal(t, test.ExpectError, didError)
+			found := findUnsubstitutedVariables("file", test.String)
+			assert.Equal(t, test.ExpectError, len(found) > 0)
 		})
 	}
 }
+
+type mapWriter struct {
+	FileMap map[string][]byte
+}
+
+func (w *mapWriter) WriteFile(path string, content []byte) error {
+	if w.FileMap == nil {
+		w.FileMap = make(map[string][]byte)
+	}
+	w.FileMap[path] = content
+	return nil
+}
+
+func (w *mapWriter) EnsureDirectory(string) error {
+	return nil
+}
+
+func TestCopyDirUnsubstitutedVariables(t *testing.T) {
+	fileSys := fstest.MapFS{
+		"src/draft.yaml":        {Data: []byte("variables: []")},
+		"src/Dockerfile":        {Data: []byte("FROM {{IMAGE}}\nEXPOSE {{PORT}}\n")},
+		"src/nested/values.txt": {Data: []byte("port: {{PORT}}\nname: {{APPNAME}}\n")},
+	}
+
+	w := &mapWriter{}
+	err := CopyDir(fileSys, "src", "out", nil, map[string]string{"IMAGE": "golang"}, w)
+	var unsubstitutedErr *UnsubstitutedVariablesError
+	assert.True(t, errors.As(err, &unsubstitutedErr))
+	assert.Equal(t, []UnsubstitutedVariable{
+		{File: "out/Dockerfile", Line: 2, Placeholder: "{{PORT}}"},
+		{File: "out/nested/values.txt", Line: 1, Placeholder: "{{PORT}}"},
+		{File: "out/nested/values.txt", Line: 2, Placeholder: "{{APPNAME}}"},
+	}, unsubstitutedErr.Variables)
+	assert.Contains(t, err.Error(), "unsubstituted variables PORT, APPNAME")
+	assert.Contains(t, err.Error(), "out/nested/values.txt:2: {{APPNAME}}")
+	assert.Empty(t, w.FileMap, "no files should be written when a variable is unsubstituted")
+
+	err = CopyDir(fileSys, "src", "out", nil, map[string]string{"IMAGE": "golang", "PORT": "80", "APPNAME": "app"}, w)
+	assert.Nil(t, err)
+	assert.Equal(t, "FROM golang\nEXPOSE 80\n", string(w.FileMap["out/Dockerfile"]))
+	assert.Equal(t, 2, len(w.FileMap))
+}
+
+func TestCopyDirTemplateDelimiters(t *testing.T) {
+	fileSys := fstest.MapFS{
+		"src/values.yaml": {Data: []byte("[[- if isTrue .METRICS ]]\nmetrics:\n  port: [[ .METRICSPORT | default \"9090\" ]]\n[[- end ]]\nimage: {{IMAGE}}\nreplicas: {{ .Values.replicas }}\n")},
+	}
+	draftConfig := &config.DraftConfig{TemplateDelimiters: []string{"[[", "]]"}}
+
+	w := &mapWriter{}
+	assert.Nil(t, CopyDir(fileSys, "src", "out", draftConfig, map[string]string{"IMAGE": "app", "METRICS": "true", "METRICSPORT": ""}, w))
+	assert.Equal(t, "\nmetrics:\n  port: 9090\nimage: app\nreplicas: {{ .Values.replicas }}\n", string(w.FileMap["out/values.yaml"]))
+
+	assert.Nil(t, CopyDir(fileSys, "src", "out", draftConfig, map[string]string{"IMAGE": "app", "METRICS": "false"}, w))
+	assert.Equal(t, "\nimage: app\nreplicas: {{ .Values.replicas }}\n", string(w.FileMap["out/values.yaml"]))
+
+	err := CopyDir(fileSys, "src", "out", draftConfig, map[string]string{"IMAGE": "app"}, w)
+	assert.ErrorContains(t, err, `map has no entry for key "METRICS"`)
+
+	// without delimiters the actions are left as is
+	assert.Nil(t, CopyDir(fileSys, "src", "out", nil, map[string]string{"IMAGE": "app"}, w))
+	assert.Contains(t, string(w.FileMap["out/values.yaml"]), "[[- if isTrue .METRICS ]]")
+}
+
+func TestReplaceTemplateVariablesIsDeterministic(t *testing.T) {
+	fsys := fstest.MapFS{"Dockerfile": {Data: []byte("FROM {{IMAGE}}\nEXPOSE {{PORT}}\n")}}
+	inputs := map[string]string{"IMAGE": "app:{{TAG}}", "PORT": "80", "TAG": "v1"}
+
+	// a value holding another placeholder renders the same however the map iterates
+	for i := 0; i < 20; i++ {
+		content, err := replaceTemplateVariables(fsys, "Dockerfile", inputs, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "FROM app:v1\nEXPOSE 80\n", string(content))
+	}
+	assert.Equal(t, []string{"IMAGE", "PORT", "TAG"}, SortedKeys(inputs))
+}
+
+func TestCopyDirWritesInWalkOrder(t *testing.T) {
+	oldRenderWorkers := renderWorkers
+	defer func() { renderWorkers = oldRenderWorkers }()
+	renderWorkers = 4
+
+	fileSys := fstest.MapFS{}
+	var want []string
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("file%02d.yaml", i)
+		fileSys["templates/"+name] = &fstest.MapFile{Data: []byte("name: {{APPNAME}}")}
+		want = append(want, "out/"+name)
+	}
+	fileSys["templates/sub/nested.yaml"] = &fstest.MapFile{Data: []byte("app: {{APPNAME}}")}
+	want = append(want, "out/sub", "out/sub/nested.yaml")
+
+	w := &orderedWriter{files: map[string][]byte{}}
+	assert.Nil(t, CopyDir(fileSys, "templates", "out", nil, map[string]string{"APPNAME": "app"}, w))
+	assert.Equal(t, want, w.order)
+	assert.Equal(t, "app: app", string(w.files["out/sub/nested.yaml"]))
+}
+
+// orderedWriter records the order files and directories are written in
+type orderedWriter struct {
+	order []string
+	files map[string][]byte
+}
+
+func (w *orderedWriter) WriteFile(path string, content []byte) error {
+	w.order = append(w.order, path)
+	w.files[path] = content
+	return nil
+}
+
+func (w *orderedWriter) EnsureDirectory(path string) error {
+	w.order = append(w.order, path)
+	return nil
+}
@@ -0,0 +1,126 @@
+package overwrite
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+
+	"github.com/Azure/draft/pkg/prompts"
+)
+
+// Policy decides what happens when draft would replace a file that already exists
+type Policy int
+
+const (
+	// Prompt asks whether to overwrite each existing file
+	Prompt Policy = iota
+	// Force overwrites existing files without asking
+	Force
+	// Never keeps existing files and skips writing them
+	Never
+	// Fail returns ErrFileExists instead of prompting, for non-interactive runs that didn't choose --force or --never-overwrite
+	Fail
+)
+
+// ErrFileExists is returned by Confirm under the Fail policy
+var ErrFileExists = errors.New("file already exists")
+
+// FromFlags returns the policy for the --force, --never-overwrite and --interactive flags
+func FromFlags(force, never, interactive bool) (Policy, error) {
+	switch {
+	case force && never:
+		return Prompt, errors.New("can only pass in one of --force and --never-overwrite")
+	case force:
+		return Force, nil
+	case never:
+		return Never, nil
+	case !interactive:
+		return Fail, nil
+	}
+	return Prompt, nil
+}
+
+// Confirm reports whether the existing file or files described by name should be overwritten
+func (p Policy) Confirm(name string) (bool, error) {
+	switch p {
+	case Force:
+		return true, nil
+	case Never:
+		return false, nil
+	case Fail:
+		return false, fmt.Errorf("%w: %s, pass --force to overwrite it or --never-overwrite to keep it", ErrFileExists, name)
+	}
+
+	selection := &promptui.Select{
+		Label: fmt.Sprintf("Found existing %s, would you like to overwrite it?", name),
+		Items: []string{"yes", "no"},
+	}
+	_, selectResponse, err := prompts.RunSelect(selection)
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(selectResponse, "yes"), nil
+}
+
+// Action is what happens to existing files that would be generated again
+type Action int
+
+const (
+	// Keep leaves the existing files as they are
+	Keep Action = iota
+	// Replace overwrites the existing files
+	Replace
+	// Merge applies the template changes to the existing files, keeping the edits made to them since they were generated
+	Merge
+	// Patch keeps the existing files and layers the generated additions over them, such as with kustomize patches
+	Patch
+)
+
+// ChooseAction is Confirm that also offers the actions of offered, such as Merge, for the existing file or files
+// described by name when the Prompt policy asks. The other policies keep or replace them as Confirm does.
+func (p Policy) ChooseAction(name string, offered ...Action) (Action, error) {
+	if p != Prompt {
+		overwrite, err := p.Confirm(name)
+		if err != nil || !overwrite {
+			return Keep, err
+		}
+		return Replace, nil
+	}
+
+	actions := append(append([]Action{Replace}, offered...), Keep)
+	var items, descriptions []string
+	for _, action := range actions {
+		items = append(items, actionItems[action])
+		descriptions = append(descriptions, actionDescriptions[action])
+	}
+	selection := &promptui.Select{
+		Label: fmt.Sprintf("Found existing %s, would you like to %s?", name, joinChoices(descriptions)),
+		Items: items,
+	}
+	i, _, err := prompts.RunSelect(selection)
+	if err != nil {
+		return Keep, err
+	}
+	return actions[i], nil
+}
+
+// actionItems are the answers of the prompt of ChooseAction
+var actionItems = map[Action]string{Replace: "overwrite", Merge: "merge", Patch: "patch", Keep: "keep"}
+
+// actionDescriptions describe the actions in the label of the prompt of ChooseAction
+var actionDescriptions = map[Action]string{
+	Replace: "overwrite it",
+	Merge:   "merge the template changes into it",
+	Patch:   "patch it",
+	Keep:    "keep it",
+}
+
+// joinChoices joins choices as a or b, or a, b or c
+func joinChoices(choices []string) string {
+	if len(choices) < 2 {
+		return strings.Join(choices, "")
+	}
+	return strings.Join(choices[:len(choices)-1], ", ") + " or " + choices[len(choices)-1]
+}
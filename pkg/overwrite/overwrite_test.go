@@ -0,0 +1,66 @@
+package overwrite
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromFlags(t *testing.T) {
+	tests := []struct {
+		force, never, interactive bool
+		want                      Policy
+		wantErr                   bool
+	}{
+		{interactive: true, want: Prompt},
+		{force: true, interactive: true, want: Force},
+		{force: true, want: Force},
+		{never: true, interactive: true, want: Never},
+		{never: true, want: Never},
+		{want: Fail},
+		{force: true, never: true, interactive: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := FromFlags(tt.force, tt.never, tt.interactive)
+		if tt.wantErr {
+			assert.NotNil(t, err)
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	overwrite, err := Force.Confirm("Dockerfile")
+	assert.Nil(t, err)
+	assert.True(t, overwrite)
+
+	overwrite, err = Never.Confirm("Dockerfile")
+	assert.Nil(t, err)
+	assert.False(t, overwrite)
+
+	overwrite, err = Fail.Confirm("Dockerfile")
+	assert.True(t, errors.Is(err, ErrFileExists))
+	assert.False(t, overwrite)
+}
+
+func TestChooseAction(t *testing.T) {
+	action, err := Force.ChooseAction("Dockerfile")
+	assert.Nil(t, err)
+	assert.Equal(t, Replace, action)
+
+	action, err = Never.ChooseAction("Dockerfile")
+	assert.Nil(t, err)
+	assert.Equal(t, Keep, action)
+
+	action, err = Fail.ChooseAction("Dockerfile")
+	assert.True(t, errors.Is(err, ErrFileExists))
+	assert.Equal(t, Keep, action)
+}
+
+func TestJoinChoices(t *testing.T) {
+	assert.Equal(t, "overwrite it or keep it", joinChoices([]string{"overwrite it", "keep it"}))
+	assert.Equal(t, "overwrite it, patch it or keep it", joinChoices([]string{"overwrite it", "patch it", "keep it"}))
+}
@@ -0,0 +1,128 @@
+package packs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	clog "github.com/containerd/log"
+	log "github.com/sirupsen/logrus"
+	"oras.land/oras-go/pkg/content"
+	"oras.land/oras-go/pkg/oras"
+)
+
+// OCIScheme prefixes references of packs pulled from an OCI registry, for example
+// oci://myregistry.azurecr.io/draft-packs/rust:v1
+const OCIScheme = "oci://"
+
+// Directories of a pack, laid out like draft's template directory
+const (
+	DockerfilesDir = "dockerfiles"
+	DeploymentsDir = "deployments"
+)
+
+// Fetcher pulls template packs from OCI registries into a local cache
+type Fetcher struct {
+	// CacheDir holds a directory per pulled reference
+	CacheDir string
+	// Refresh pulls references again even when they are cached, for tags that have been pushed to since
+	Refresh bool
+	// PlainHTTP talks to the registry over http instead of https, for local test registries
+	PlainHTTP bool
+}
+
+// NewFetcher returns a Fetcher caching packs in the draft directory of the user cache directory
+func NewFetcher() (*Fetcher, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("finding the pack cache directory: %w", err)
+	}
+	return &Fetcher{CacheDir: filepath.Join(cacheDir, "draft", "packs")}, nil
+}
+
+// Fetch returns the local directory of the pack at pack, pulling it into the cache unless it is already there. The
+// directory has a dockerfiles directory of language packs, a deployments directory of deployment packs, or both.
+func (f *Fetcher) Fetch(ctx context.Context, pack string) (string, error) {
+	ref, ok := strings.CutPrefix(pack, OCIScheme)
+	if !ok || ref == "" || strings.Contains(ref, "..") {
+		return "", fmt.Errorf("invalid pack %q, must be an OCI reference starting with %s", pack, OCIScheme)
+	}
+
+	dir := filepath.Join(f.CacheDir, cacheKey(ref))
+	if !f.Refresh {
+		if _, err := os.Stat(dir); err == nil {
+			log.Debugf("using cached pack %s from %s", pack, dir)
+			return dir, nil
+		}
+	}
+
+	log.Infof("--> Pulling pack %s...", pack)
+	if err := os.MkdirAll(f.CacheDir, 0755); err != nil {
+		return "", err
+	}
+	pullDir, err := os.MkdirTemp(f.CacheDir, "pull-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(pullDir)
+
+	if err := f.pull(ctx, ref, pullDir); err != nil {
+		return "", fmt.Errorf("pulling pack %s: %w", pack, err)
+	}
+	if err := validatePackDir(pullDir); err != nil {
+		return "", fmt.Errorf("pack %s: %w", pack, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return "", err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return "", err
+	}
+	if err := os.Rename(pullDir, dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+func (f *Fetcher) pull(ctx context.Context, ref, dir string) error {
+	registry, err := content.NewRegistry(content.RegistryOptions{PlainHTTP: f.PlainHTTP})
+	if err != nil {
+		return err
+	}
+	store := content.NewFile(dir)
+	defer store.Close()
+
+	// layers are written to dir under their title, and directories pushed with the oras cli are unpacked
+	_, err = oras.Copy(quietContext(ctx), registry, ref, store, "")
+	return err
+}
+
+// quietContext keeps the warnings containerd logs about media types it doesn't know, such as those of packs, out of
+// the output
+func quietContext(ctx context.Context) context.Context {
+	logger := log.New()
+	logger.SetOutput(log.StandardLogger().Out)
+	logger.SetLevel(log.ErrorLevel)
+	return clog.WithLogger(ctx, log.NewEntry(logger))
+}
+
+func validatePackDir(dir string) error {
+	for _, d := range []string{DockerfilesDir, DeploymentsDir} {
+		if info, err := os.Stat(filepath.Join(dir, d)); err == nil && info.IsDir() {
+			return nil
+		} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return fmt.Errorf("no %s or %s directory found", DockerfilesDir, DeploymentsDir)
+}
+
+// cacheKey turns ref into a relative path that is valid on every platform
+func cacheKey(ref string) string {
+	return filepath.FromSlash(strings.NewReplacer(":", "_", "@", "_").Replace(ref))
+}
@@ -0,0 +1,245 @@
+package packs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/pkg/content"
+)
+
+// testRegistry serves the manifest of each repository:tag or repository:digest in manifests and the blobs they reference
+type testRegistry struct {
+	manifests map[string][]byte
+	blobs     map[digest.Digest][]byte
+	requests  int
+}
+
+func (r *testRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.requests++
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	if req.Method == http.MethodPost || req.Method == http.MethodPut {
+		r.upload(w, req, path)
+		return
+	}
+	if repo, tag, ok := strings.Cut(path, "/manifests/"); ok {
+		manifest, ok := r.manifests[repo+":"+tag]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+		w.Header().Set("Docker-Content-Digest", digest.FromBytes(manifest).String())
+		w.Header().Set("Content-Length", strconv.Itoa(len(manifest)))
+		if req.Method != http.MethodHead {
+			w.Write(manifest)
+		}
+		return
+	}
+	if _, d, ok := strings.Cut(path, "/blobs/"); ok {
+		blob, ok := r.blobs[digest.Digest(d)]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(blob)))
+		w.Write(blob)
+		return
+	}
+	http.NotFound(w, req)
+}
+
+// upload stores the blobs and manifests pushed to the registry, each blob uploaded in a single request
+func (r *testRegistry) upload(w http.ResponseWriter, req *http.Request, path string) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch {
+	case req.Method == http.MethodPost && strings.HasSuffix(path, "/blobs/uploads/"):
+		w.Header().Set("Location", req.URL.Path+"session")
+		w.WriteHeader(http.StatusAccepted)
+	case req.Method == http.MethodPut && strings.HasSuffix(path, "/blobs/uploads/session"):
+		d := digest.Digest(req.URL.Query().Get("digest"))
+		if d != digest.FromBytes(body) {
+			http.Error(w, "digest mismatch", http.StatusBadRequest)
+			return
+		}
+		r.blobs[d] = body
+		w.Header().Set("Docker-Content-Digest", d.String())
+		w.WriteHeader(http.StatusCreated)
+	case req.Method == http.MethodPut && strings.Contains(path, "/manifests/"):
+		repo, tag, _ := strings.Cut(path, "/manifests/")
+		r.manifests[repo+":"+tag] = body
+		r.manifests[repo+":"+digest.FromBytes(body).String()] = body
+		w.Header().Set("Docker-Content-Digest", digest.FromBytes(body).String())
+		w.WriteHeader(http.StatusCreated)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+// push adds an artifact to the registry holding files under the directory layer dir, like 'oras push ref dir/'
+func (r *testRegistry) push(t *testing.T, ref, dir string, files map[string]string) {
+	var tarBuf bytes.Buffer
+	tw := tar.NewWriter(&tarBuf)
+	assert.Nil(t, tw.WriteHeader(&tar.Header{Name: dir + "/", Mode: 0755, Typeflag: tar.TypeDir}))
+	written := map[string]bool{}
+	for name := range files {
+		if parent := path.Dir(name); parent != "." && !written[parent] {
+			written[parent] = true
+			assert.Nil(t, tw.WriteHeader(&tar.Header{Name: dir + "/" + parent + "/", Mode: 0755, Typeflag: tar.TypeDir}))
+		}
+	}
+	for name, data := range files {
+		assert.Nil(t, tw.WriteHeader(&tar.Header{Name: dir + "/" + name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(data))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, tw.Close())
+	var gzBuf bytes.Buffer
+	gw := gzip.NewWriter(&gzBuf)
+	_, err := gw.Write(tarBuf.Bytes())
+	assert.Nil(t, err)
+	assert.Nil(t, gw.Close())
+
+	config := []byte("{}")
+	layer := gzBuf.Bytes()
+	r.blobs[digest.FromBytes(config)] = config
+	r.blobs[digest.FromBytes(layer)] = layer
+	manifest, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    ocispec.Descriptor{MediaType: "application/vnd.unknown.config.v1+json", Digest: digest.FromBytes(config), Size: int64(len(config))},
+		Layers: []ocispec.Descriptor{{
+			MediaType: ocispec.MediaTypeImageLayerGzip,
+			Digest:    digest.FromBytes(layer),
+			Size:      int64(len(layer)),
+			Annotations: map[string]string{
+				ocispec.AnnotationTitle:  dir,
+				content.AnnotationUnpack: "true",
+				content.AnnotationDigest: digest.FromBytes(tarBuf.Bytes()).String(),
+			},
+		}},
+	})
+	assert.Nil(t, err)
+	repo, _, _ := strings.Cut(ref, ":")
+	r.manifests[ref] = manifest
+	r.manifests[repo+":"+digest.FromBytes(manifest).String()] = manifest
+}
+
+func TestFetch(t *testing.T) {
+	registry := &testRegistry{manifests: map[string][]byte{}, blobs: map[digest.Digest][]byte{}}
+	registry.push(t, "draft-packs/rust:v1", "dockerfiles", map[string]string{
+		"rust/draft.yaml": "language: rust\nversion: \"1.0.0\"\n",
+		"rust/Dockerfile": "FROM rust:{{VERSION}}\n",
+	})
+	registry.push(t, "draft-packs/empty:v1", "docs", map[string]string{"README.md": "nothing to see"})
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	f := &Fetcher{CacheDir: t.TempDir(), PlainHTTP: true}
+	dir, err := f.Fetch(context.Background(), "oci://"+host+"/draft-packs/rust:v1")
+	assert.Nil(t, err)
+	dockerfile, err := os.ReadFile(filepath.Join(dir, "dockerfiles", "rust", "Dockerfile"))
+	assert.Nil(t, err)
+	assert.Equal(t, "FROM rust:{{VERSION}}\n", string(dockerfile))
+
+	requests := registry.requests
+	cached, err := f.Fetch(context.Background(), "oci://"+host+"/draft-packs/rust:v1")
+	assert.Nil(t, err)
+	assert.Equal(t, dir, cached)
+	assert.Equal(t, requests, registry.requests)
+
+	f.Refresh = true
+	_, err = f.Fetch(context.Background(), "oci://"+host+"/draft-packs/rust:v1")
+	assert.Nil(t, err)
+	assert.Greater(t, registry.requests, requests)
+
+	_, err = f.Fetch(context.Background(), "oci://"+host+"/draft-packs/empty:v1")
+	assert.ErrorContains(t, err, "no dockerfiles or deployments directory found")
+
+	_, err = f.Fetch(context.Background(), "oci://"+host+"/draft-packs/missing:v1")
+	assert.NotNil(t, err)
+
+	for _, invalid := range []string{host + "/draft-packs/rust:v1", "oci://", "oci://" + host + "/../rust:v1"} {
+		_, err = f.Fetch(context.Background(), invalid)
+		assert.ErrorContains(t, err, "invalid pack")
+	}
+}
+
+func TestPush(t *testing.T) {
+	registry := &testRegistry{manifests: map[string][]byte{}, blobs: map[digest.Digest][]byte{}}
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"dockerfiles/rust/draft.yaml":  "language: rust\nversion: \"1.0.0\"\n",
+		"dockerfiles/rust/Dockerfile":  "FROM rust:{{VERSION}}\n",
+		"deployments/nomad/draft.yaml": "deployType: nomad\nversion: \"1.0.0\"\n",
+		"README.md":                    "not pushed",
+	}
+	for name, data := range files {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(data), 0644))
+	}
+
+	p := &Pusher{PlainHTTP: true}
+	pack := "oci://" + host + "/draft-packs/rust:v1"
+	pushed, err := p.Push(context.Background(), dir, pack, Config{Languages: []string{"rust"}, DeployTypes: []string{"nomad"}})
+	assert.Nil(t, err)
+
+	manifestBytes := registry.manifests["draft-packs/rust:v1"]
+	assert.Equal(t, pushed, digest.FromBytes(manifestBytes))
+	var manifest ocispec.Manifest
+	assert.Nil(t, json.Unmarshal(manifestBytes, &manifest))
+	assert.Equal(t, ConfigMediaType, manifest.Config.MediaType)
+	assert.JSONEq(t, `{"languages":["rust"],"deployTypes":["nomad"]}`, string(registry.blobs[manifest.Config.Digest]))
+	assert.Len(t, manifest.Layers, 2)
+	for _, layer := range manifest.Layers {
+		assert.Equal(t, LayerMediaType, layer.MediaType)
+	}
+
+	// the pushed pack is pulled like any other
+	f := &Fetcher{CacheDir: t.TempDir(), PlainHTTP: true}
+	pulled, err := f.Fetch(context.Background(), pack)
+	assert.Nil(t, err)
+	for name, data := range files {
+		pulledData, err := os.ReadFile(filepath.Join(pulled, name))
+		if name == "README.md" {
+			assert.True(t, os.IsNotExist(err))
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, data, string(pulledData))
+	}
+
+	// the same pack gets the same digest when pushed again
+	again, err := p.Push(context.Background(), dir, pack, Config{Languages: []string{"rust"}, DeployTypes: []string{"nomad"}})
+	assert.Nil(t, err)
+	assert.Equal(t, pushed, again)
+
+	_, err = p.Push(context.Background(), t.TempDir(), pack, Config{})
+	assert.ErrorContains(t, err, "no dockerfiles or deployments directory found")
+	_, err = p.Push(context.Background(), dir, host+"/draft-packs/rust:v1", Config{})
+	assert.ErrorContains(t, err, "invalid pack")
+}
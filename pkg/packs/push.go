@@ -0,0 +1,101 @@
+package packs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	log "github.com/sirupsen/logrus"
+	"oras.land/oras-go/pkg/content"
+	"oras.land/oras-go/pkg/oras"
+)
+
+// Media types of the artifacts pushed by draft. Packs are pulled by their layout rather than their media types, so
+// directories pushed with the oras cli can be pulled as packs too.
+const (
+	// ConfigMediaType is the media type of the Config of a pack
+	ConfigMediaType = "application/vnd.azure.draft.pack.config.v1+json"
+	// LayerMediaType is the media type of the dockerfiles and deployments directories of a pack, each a gzipped tar
+	LayerMediaType = "application/vnd.azure.draft.pack.layer.v1.tar+gzip"
+)
+
+// Config is the config of a pushed pack, describing what it holds
+type Config struct {
+	Languages   []string `json:"languages,omitempty"`
+	DeployTypes []string `json:"deployTypes,omitempty"`
+}
+
+// Pusher pushes template packs to OCI registries
+type Pusher struct {
+	// PlainHTTP talks to the registry over http instead of https, for local test registries
+	PlainHTTP bool
+}
+
+// Push pushes the dockerfiles and deployments directories of dir to the OCI reference pack, along with cfg, and
+// returns the digest of the pushed manifest. Registries are logged into like with docker or oras.
+func (p *Pusher) Push(ctx context.Context, dir, pack string, cfg Config) (digest.Digest, error) {
+	ref, ok := strings.CutPrefix(pack, OCIScheme)
+	if !ok || ref == "" || strings.Contains(ref, "..") {
+		return "", fmt.Errorf("invalid pack %q, must be an OCI reference starting with %s", pack, OCIScheme)
+	}
+	if err := validatePackDir(dir); err != nil {
+		return "", fmt.Errorf("pack %s: %w", dir, err)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	store := content.NewFile(dir)
+	defer store.Close()
+	// stripping the times of the files gives the same digest to the same pack, so pushing it again changes nothing
+	store.Reproducible = true
+
+	var layers []ocispec.Descriptor
+	for _, d := range []string{DockerfilesDir, DeploymentsDir} {
+		if !isDir(filepath.Join(dir, d)) {
+			continue
+		}
+		layer, err := store.Add(d, LayerMediaType, filepath.Join(dir, d))
+		if err != nil {
+			return "", fmt.Errorf("packing %s: %w", d, err)
+		}
+		layers = append(layers, layer)
+	}
+
+	configBytes, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	configDesc := ocispec.Descriptor{MediaType: ConfigMediaType, Digest: digest.FromBytes(configBytes), Size: int64(len(configBytes))}
+	if err := store.Load(configDesc, configBytes); err != nil {
+		return "", err
+	}
+	manifest, manifestDesc, err := content.GenerateManifest(&configDesc, nil, layers...)
+	if err != nil {
+		return "", err
+	}
+	if err := store.StoreManifest(ref, manifestDesc, manifest); err != nil {
+		return "", err
+	}
+
+	registry, err := content.NewRegistry(content.RegistryOptions{PlainHTTP: p.PlainHTTP})
+	if err != nil {
+		return "", err
+	}
+	log.Infof("--> Pushing pack %s...", pack)
+	if _, err := oras.Copy(quietContext(ctx), store, ref, registry, ""); err != nil {
+		return "", fmt.Errorf("pushing pack %s: %w", pack, err)
+	}
+	return manifestDesc.Digest, nil
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
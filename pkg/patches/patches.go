@@ -0,0 +1,365 @@
+// Package patches layers what draft's generated Deployment adds, its labels, probes and image, over the Deployments
+// and StatefulSets of existing Kubernetes manifests as kustomize strategic-merge patches, leaving the manifests
+// themselves untouched.
+package patches
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Azure/draft/pkg/ignore"
+)
+
+// Dir is the directory of the patches, next to the kustomization.yaml listing them in the root of the project
+const Dir = "draft-patches"
+
+// KustomizationFile is the kustomization listing the manifests and the patches
+const KustomizationFile = "kustomization.yaml"
+
+// ErrNoWorkloads is returned by Generate when the manifests have no Deployment or StatefulSet to patch
+var ErrNoWorkloads = errors.New("no Deployment or StatefulSet found in the Kubernetes manifests")
+
+// workloadKinds are the kinds patched
+var workloadKinds = []string{"Deployment", "StatefulSet"}
+
+// resource is the part of a Kubernetes resource the patches are built from
+type resource struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name      string            `yaml:"name"`
+		Namespace string            `yaml:"namespace"`
+		Labels    map[string]string `yaml:"labels"`
+	} `yaml:"metadata"`
+	Spec struct {
+		Template struct {
+			Metadata struct {
+				Labels map[string]string `yaml:"labels"`
+			} `yaml:"metadata"`
+			Spec struct {
+				Containers []container `yaml:"containers"`
+			} `yaml:"spec"`
+		} `yaml:"template"`
+	} `yaml:"spec"`
+}
+
+type container struct {
+	Name           string `yaml:"name"`
+	Image          string `yaml:"image"`
+	LivenessProbe  any    `yaml:"livenessProbe"`
+	ReadinessProbe any    `yaml:"readinessProbe"`
+	Ports          []struct {
+		ContainerPort int `yaml:"containerPort"`
+	} `yaml:"ports"`
+}
+
+func (r *resource) isWorkload() bool {
+	for _, kind := range workloadKinds {
+		if r.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Additions are what draft's generated Deployment adds to existing ones
+type Additions struct {
+	// Labels are the labels of the Deployment, and PodLabels those of its pods
+	Labels    map[string]string
+	PodLabels map[string]string
+	// Container is the name of the generated container, patched in the workloads with a container of that name and
+	// otherwise in their first container
+	Container string
+	Image     string
+	// Port is the container port the liveness and readiness probes get /, like those of the helm chart, 0 for none
+	Port int
+}
+
+// AdditionsFrom returns the Additions of the first Deployment or StatefulSet of the files rendered from a deployment
+// type, such as the manifests deployment type
+func AdditionsFrom(files map[string][]byte) (*Additions, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		resources, err := decode(files[name])
+		if err != nil {
+			return nil, fmt.Errorf("reading generated %s: %w", name, err)
+		}
+		for _, r := range resources {
+			if !r.isWorkload() || len(r.Spec.Template.Spec.Containers) == 0 {
+				continue
+			}
+			c := r.Spec.Template.Spec.Containers[0]
+			additions := &Additions{
+				Labels:    r.Metadata.Labels,
+				PodLabels: r.Spec.Template.Metadata.Labels,
+				Container: c.Name,
+				Image:     c.Image,
+			}
+			if len(c.Ports) > 0 {
+				additions.Port = c.Ports[0].ContainerPort
+			}
+			return additions, nil
+		}
+	}
+	return nil, errors.New("the generated files have no Deployment or StatefulSet")
+}
+
+// FindManifests returns the paths, relative to dest, of the yaml files of dest whose documents are all Kubernetes
+// resources, in path order. The files ignored by .gitignore and .draftignore, the helm charts, the kustomizations and
+// the patches of a previous run are left out.
+func FindManifests(dest string) ([]string, error) {
+	matcher := ignore.NewMatcher(dest)
+	var manifests []string
+	err := filepath.WalkDir(dest, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dest, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if matcher.Match(rel, true) || strings.HasPrefix(d.Name(), ".") || d.Name() == "charts" || rel == Dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := path.Ext(rel)
+		if (ext != ".yaml" && ext != ".yml") || matcher.Match(rel, false) {
+			return nil
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if isManifest(content) {
+			manifests = append(manifests, rel)
+		}
+		return nil
+	})
+	return manifests, err
+}
+
+// isManifest reports whether every document of content is a Kubernetes resource other than a kustomization
+func isManifest(content []byte) bool {
+	resources, err := decode(content)
+	if err != nil || len(resources) == 0 {
+		return false
+	}
+	for _, r := range resources {
+		if r.APIVersion == "" || r.Kind == "" || r.Kind == "Kustomization" {
+			return false
+		}
+	}
+	return true
+}
+
+// decode returns the non-empty documents of content
+func decode(content []byte) ([]resource, error) {
+	var resources []resource
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				return resources, nil
+			}
+			return nil, err
+		}
+		if len(node.Content) == 0 || node.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		var r resource
+		if err := node.Decode(&r); err != nil {
+			return nil, err
+		}
+		resources = append(resources, r)
+	}
+}
+
+// Generate returns the files, relative to dest, layering additions over the Deployments and StatefulSets of the
+// manifests of dest: a strategic-merge patch in Dir for each of them, and a kustomization.yaml in dest listing the
+// manifests as resources and the patches. Labels and probes the workloads already have are kept, so only the image
+// is replaced.
+func Generate(dest string, manifests []string, additions *Additions) (map[string][]byte, error) {
+	if _, err := os.Stat(filepath.Join(dest, KustomizationFile)); err == nil {
+		return nil, fmt.Errorf("%s already has a %s, which the patches would replace", dest, KustomizationFile)
+	}
+
+	files := make(map[string][]byte)
+	var patchFiles []string
+	for _, manifest := range manifests {
+		content, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(manifest)))
+		if err != nil {
+			return nil, err
+		}
+		resources, err := decode(content)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", manifest, err)
+		}
+		for _, r := range resources {
+			if !r.isWorkload() || r.Metadata.Name == "" {
+				continue
+			}
+			patch, err := marshal(newPatch(&r, additions))
+			if err != nil {
+				return nil, err
+			}
+			patchFile := path.Join(Dir, patchName(&r))
+			if _, ok := files[patchFile]; ok {
+				return nil, fmt.Errorf("%s %s is declared more than once in the manifests", r.Kind, r.Metadata.Name)
+			}
+			files[patchFile] = patch
+			patchFiles = append(patchFiles, patchFile)
+		}
+	}
+	if len(patchFiles) == 0 {
+		return nil, ErrNoWorkloads
+	}
+
+	kustomization, err := marshal(kustomization{
+		APIVersion:            "kustomize.config.k8s.io/v1beta1",
+		Kind:                  "Kustomization",
+		Resources:             manifests,
+		PatchesStrategicMerge: patchFiles,
+	})
+	if err != nil {
+		return nil, err
+	}
+	files[KustomizationFile] = kustomization
+	return files, nil
+}
+
+// patchName is the file name of the patch of r, such as deployment-api.yaml, prefixed with its namespace when set
+func patchName(r *resource) string {
+	name := strings.ToLower(r.Kind) + "-" + r.Metadata.Name
+	if r.Metadata.Namespace != "" {
+		name = r.Metadata.Namespace + "-" + name
+	}
+	return name + ".yaml"
+}
+
+type kustomization struct {
+	APIVersion            string   `yaml:"apiVersion"`
+	Kind                  string   `yaml:"kind"`
+	Resources             []string `yaml:"resources"`
+	PatchesStrategicMerge []string `yaml:"patchesStrategicMerge"`
+}
+
+type patch struct {
+	APIVersion string        `yaml:"apiVersion"`
+	Kind       string        `yaml:"kind"`
+	Metadata   patchMetadata `yaml:"metadata"`
+	Spec       struct {
+		Template struct {
+			Metadata *patchMetadata `yaml:"metadata,omitempty"`
+			Spec     struct {
+				Containers []patchContainer `yaml:"containers"`
+			} `yaml:"spec"`
+		} `yaml:"template"`
+	} `yaml:"spec"`
+}
+
+type patchMetadata struct {
+	Name      string            `yaml:"name,omitempty"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+type patchContainer struct {
+	Name           string `yaml:"name"`
+	Image          string `yaml:"image,omitempty"`
+	LivenessProbe  *probe `yaml:"livenessProbe,omitempty"`
+	ReadinessProbe *probe `yaml:"readinessProbe,omitempty"`
+}
+
+type probe struct {
+	HTTPGet struct {
+		Path string `yaml:"path"`
+		Port int    `yaml:"port"`
+	} `yaml:"httpGet"`
+}
+
+// newPatch returns the patch of the workload r adding the labels it doesn't set, the probes its container doesn't have
+// and the image
+func newPatch(r *resource, additions *Additions) *patch {
+	p := &patch{APIVersion: r.APIVersion, Kind: r.Kind}
+	p.Metadata = patchMetadata{Name: r.Metadata.Name, Namespace: r.Metadata.Namespace, Labels: missing(additions.Labels, r.Metadata.Labels)}
+	if podLabels := missing(additions.PodLabels, r.Spec.Template.Metadata.Labels); len(podLabels) > 0 {
+		p.Spec.Template.Metadata = &patchMetadata{Labels: podLabels}
+	}
+
+	// a container without a name can't be matched by a strategic-merge patch, so it gets the generated name
+	target := container{Name: additions.Container}
+	if containers := r.Spec.Template.Spec.Containers; len(containers) > 0 {
+		target = containers[0]
+		for _, c := range containers {
+			if c.Name == additions.Container {
+				target = c
+				break
+			}
+		}
+	}
+	c := patchContainer{Name: target.Name, Image: additions.Image}
+	if additions.Port > 0 {
+		if target.LivenessProbe == nil {
+			c.LivenessProbe = newProbe(additions.Port)
+		}
+		if target.ReadinessProbe == nil {
+			c.ReadinessProbe = newProbe(additions.Port)
+		}
+	}
+	p.Spec.Template.Spec.Containers = []patchContainer{c}
+	return p
+}
+
+func newProbe(port int) *probe {
+	p := &probe{}
+	p.HTTPGet.Path = "/"
+	p.HTTPGet.Port = port
+	return p
+}
+
+// missing returns the labels of additions that labels doesn't set, keeping the values a workload and its selector
+// already rely on
+func missing(additions, labels map[string]string) map[string]string {
+	result := make(map[string]string)
+	for key, value := range additions {
+		if _, ok := labels[key]; !ok {
+			result[key] = value
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// marshal returns v as yaml indented by 2 spaces, like the generated manifests
+func marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,151 @@
+package patches
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: shop
+  labels:
+    app: shop
+spec:
+  selector:
+    matchLabels:
+      app: shop
+  template:
+    metadata:
+      labels:
+        app: shop
+    spec:
+      containers:
+        - name: web
+          image: nginx
+          readinessProbe:
+            tcpSocket:
+              port: 80
+        - name: proxy
+          image: envoy
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: shop
+spec:
+  ports:
+    - port: 80
+`
+
+const generated = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: shopapp
+  labels:
+    app: shopapp
+    kubernetes.azure.com/generator: draft
+spec:
+  template:
+    metadata:
+      labels:
+        app: shopapp
+        version: v1
+    spec:
+      containers:
+        - name: shopapp
+          image: acr.io/shop:v1
+          ports:
+            - containerPort: 8080
+`
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.Nil(t, os.WriteFile(p, []byte(content), 0644))
+	}
+}
+
+func TestFindManifests(t *testing.T) {
+	dest := t.TempDir()
+	writeFiles(t, dest, map[string]string{
+		"manifests/deployment.yaml":          deployment,
+		"manifests/configmap.yml":            "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: shop\n",
+		"manifests/kustomization.yaml":       "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\n",
+		"charts/templates/deployment.yaml":   deployment,
+		".github/workflows/deploy.yaml":      "on: push\n",
+		"draft-patches/deployment-shop.yaml": deployment,
+		"config.yaml":                        "port: 8080\n",
+		"generated/deployment.yaml":          deployment,
+		".gitignore":                         "generated/\n",
+	})
+
+	manifests, err := FindManifests(dest)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"manifests/configmap.yml", "manifests/deployment.yaml"}, manifests)
+}
+
+func TestGenerate(t *testing.T) {
+	additions, err := AdditionsFrom(map[string][]byte{"manifests/deployment.yaml": []byte(generated)})
+	assert.Nil(t, err)
+	assert.Equal(t, &Additions{
+		Labels:    map[string]string{"app": "shopapp", "kubernetes.azure.com/generator": "draft"},
+		PodLabels: map[string]string{"app": "shopapp", "version": "v1"},
+		Container: "shopapp",
+		Image:     "acr.io/shop:v1",
+		Port:      8080,
+	}, additions)
+
+	dest := t.TempDir()
+	writeFiles(t, dest, map[string]string{"manifests/deployment.yaml": deployment})
+	files, err := Generate(dest, []string{"manifests/deployment.yaml"}, additions)
+	assert.Nil(t, err)
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: shop
+  labels:
+    kubernetes.azure.com/generator: draft
+spec:
+  template:
+    metadata:
+      labels:
+        version: v1
+    spec:
+      containers:
+        - name: web
+          image: acr.io/shop:v1
+          livenessProbe:
+            httpGet:
+              path: /
+              port: 8080
+`, string(files["draft-patches/deployment-shop.yaml"]))
+
+	for name, content := range files {
+		writeFiles(t, dest, map[string]string{name: string(content)})
+	}
+	kustomizer := krusty.MakeKustomizer(&krusty.Options{PluginConfig: &types.PluginConfig{}})
+	resources, err := kustomizer.Run(filesys.MakeFsOnDisk(), dest)
+	assert.Nil(t, err)
+	built, err := resources.AsYaml()
+	assert.Nil(t, err)
+	assert.Contains(t, string(built), "kubernetes.azure.com/generator: draft")
+	assert.Contains(t, string(built), "image: acr.io/shop:v1")
+	assert.Contains(t, string(built), "image: envoy")
+	assert.Contains(t, string(built), "tcpSocket:")
+
+	_, err = Generate(dest, []string{"manifests/deployment.yaml"}, additions)
+	assert.ErrorContains(t, err, "already has a kustomization.yaml")
+
+	dest = t.TempDir()
+	writeFiles(t, dest, map[string]string{"service.yaml": "apiVersion: v1\nkind: Service\nmetadata:\n  name: shop\n"})
+	_, err = Generate(dest, []string{"service.yaml"}, additions)
+	assert.ErrorIs(t, err, ErrNoWorkloads)
+}
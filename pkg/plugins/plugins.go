@@ -0,0 +1,178 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
+// Prefix is the executable name prefix that marks a binary on the PATH as a draft plugin.
+// An executable named draft-foo is exposed as the `draft foo` subcommand.
+const Prefix = "draft-"
+
+// ResultFileEnv is the environment variable holding the path of the file a plugin writes its Result to
+const ResultFileEnv = "DRAFT_PLUGIN_RESULT_FILE"
+
+// Plugin is an external executable discovered on the PATH
+type Plugin struct {
+	// Name is the subcommand name, i.e. the executable name without Prefix or extension
+	Name string
+	// Path is the absolute path of the executable
+	Path string
+}
+
+// Context is the shared draft context written as JSON to a plugin's stdin
+type Context struct {
+	Version     string            `json:"version"`
+	Destination string            `json:"destination"`
+	Variables   map[string]string `json:"variables"`
+	DryRun      bool              `json:"dryRun"`
+}
+
+// Result is the JSON document a plugin may write to the file named by ResultFileEnv. Files are written relative to the
+// context destination through the caller's TemplateWriter, so dry runs behave like the builtin commands.
+type Result struct {
+	Files    map[string]string `json:"files,omitempty"`
+	Messages []string          `json:"messages,omitempty"`
+}
+
+// Discover returns the plugins found in the directories of pathList, a list in the format of the PATH
+// environment variable. When the same plugin name occurs more than once, the first directory wins.
+func Discover(pathList string) []Plugin {
+	found := make(map[string]Plugin)
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			log.Debugf("skipping plugin search in %s: %v", dir, err)
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok || entry.IsDir() {
+				continue
+			}
+			if _, ok := found[name]; ok {
+				log.Debugf("plugin %s in %s is shadowed by an earlier PATH entry", name, dir)
+				continue
+			}
+			fullPath := filepath.Join(dir, entry.Name())
+			if !isExecutable(fullPath) {
+				continue
+			}
+			found[name] = Plugin{Name: name, Path: fullPath}
+		}
+	}
+
+	plugins := make([]Plugin, 0, len(found))
+	for _, p := range found {
+		plugins = append(plugins, p)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+func pluginName(fileName string) (string, bool) {
+	if !strings.HasPrefix(fileName, Prefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(fileName, Prefix)
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	if name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(path))
+		return ext == ".exe" || ext == ".bat" || ext == ".cmd"
+	}
+	return info.Mode().Perm()&0111 != 0
+}
+
+// Run executes the plugin with args, passing draftCtx as JSON on stdin. The plugin's stdout and stderr are
+// streamed to stdout and stderr as it writes them. The plugin returns a Result by writing it as JSON to the file
+// named by $DRAFT_PLUGIN_RESULT_FILE; when it leaves the file empty, an empty Result is returned.
+func (p Plugin) Run(ctx context.Context, draftCtx Context, args []string, stdout, stderr io.Writer) (*Result, error) {
+	input, err := json.Marshal(draftCtx)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling plugin context: %w", err)
+	}
+
+	resultFile, err := os.CreateTemp("", "draft-plugin-result-*.json")
+	if err != nil {
+		return nil, fmt.Errorf("creating plugin result file: %w", err)
+	}
+	resultFile.Close()
+	defer os.Remove(resultFile.Name())
+
+	cmd := exec.CommandContext(ctx, p.Path, args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = append(os.Environ(), "DRAFT_PLUGIN_NAME="+p.Name, ResultFileEnv+"="+resultFile.Name())
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("running plugin %s: %w", p.Name, err)
+	}
+
+	out, err := os.ReadFile(resultFile.Name())
+	if err != nil {
+		return nil, fmt.Errorf("reading plugin %s result: %w", p.Name, err)
+	}
+	result := &Result{}
+	trimmed := bytes.TrimSpace(out)
+	if len(trimmed) == 0 {
+		return result, nil
+	}
+	if err := json.Unmarshal(trimmed, result); err != nil {
+		return nil, fmt.Errorf("parsing plugin %s result: %w", p.Name, err)
+	}
+	return result, nil
+}
+
+// WriteResult writes the files of a plugin result relative to dest using templateWriter
+func WriteResult(result *Result, dest string, templateWriter templatewriter.TemplateWriter) error {
+	paths := make([]string, 0, len(result.Files))
+	for p := range result.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		cleanPath := filepath.Clean(p)
+		if filepath.IsAbs(cleanPath) || cleanPath == ".." || strings.HasPrefix(cleanPath, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("plugin file path %s must be relative to the destination", p)
+		}
+		destPath := filepath.Join(dest, cleanPath)
+		if err := templateWriter.EnsureDirectory(filepath.Dir(destPath)); err != nil {
+			return err
+		}
+		if err := templateWriter.WriteFile(destPath, []byte(result.Files[p])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
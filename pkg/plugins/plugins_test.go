@@ -0,0 +1,148 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+func writeScript(t *testing.T, dir, name, content string, mode os.FileMode) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte(content), mode); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestDiscover(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts use a POSIX shell")
+	}
+	first := t.TempDir()
+	second := t.TempDir()
+	writeScript(t, first, "draft-hello", "#!/bin/sh\n", 0755)
+	writeScript(t, first, "draft-notexec", "#!/bin/sh\n", 0644)
+	writeScript(t, first, "kubectl-foo", "#!/bin/sh\n", 0755)
+	shadowed := writeScript(t, second, "draft-hello", "#!/bin/sh\n", 0755)
+	writeScript(t, second, "draft-audit", "#!/bin/sh\n", 0755)
+
+	found := Discover(first + string(os.PathListSeparator) + second + string(os.PathListSeparator) + filepath.Join(first, "missing"))
+
+	assert.Equal(t, 2, len(found))
+	assert.Equal(t, "audit", found[0].Name)
+	assert.Equal(t, "hello", found[1].Name)
+	assert.Equal(t, filepath.Join(first, "draft-hello"), found[1].Path)
+	assert.NotEqual(t, shadowed, found[1].Path)
+}
+
+func TestRunStructuredResult(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts use a POSIX shell")
+	}
+	dir := t.TempDir()
+	script := `#!/bin/sh
+input=$(cat)
+case "$input" in
+  *'"APPNAME":"myapp"'*) ;;
+  *) echo "missing variables" >&2; exit 1 ;;
+esac
+echo "generating"
+echo '{"files":{"extra/config.txt":"hello"},"messages":["done"]}' > "$DRAFT_PLUGIN_RESULT_FILE"
+`
+	p := Plugin{Name: "gen", Path: writeScript(t, dir, "draft-gen", script, 0755)}
+
+	var stdout, stderr bytes.Buffer
+	result, err := p.Run(context.Background(), Context{Destination: ".", Variables: map[string]string{"APPNAME": "myapp"}}, nil, &stdout, &stderr)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", result.Files["extra/config.txt"])
+	assert.Equal(t, []string{"done"}, result.Messages)
+	assert.Equal(t, "generating\n", stdout.String())
+
+	_, err = p.Run(context.Background(), Context{Destination: "."}, nil, &stdout, &stderr)
+	assert.NotNil(t, err)
+	assert.Contains(t, stderr.String(), "missing variables")
+}
+
+func TestRunPassthroughOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts use a POSIX shell")
+	}
+	dir := t.TempDir()
+	p := Plugin{Name: "echo", Path: writeScript(t, dir, "draft-echo", "#!/bin/sh\ncat > /dev/null\necho \"args: $@\"\necho '{\"status\":\"ok\"}'\n", 0755)}
+
+	var stdout, stderr bytes.Buffer
+	result, err := p.Run(context.Background(), Context{}, []string{"a", "b"}, &stdout, &stderr)
+	assert.Nil(t, err)
+	assert.Empty(t, result.Files)
+	assert.Equal(t, "args: a b\n{\"status\":\"ok\"}\n", stdout.String(), "json on stdout is output, not a result")
+
+	invalid := Plugin{Name: "invalid", Path: writeScript(t, dir, "draft-invalid", "#!/bin/sh\necho 'not json' > \"$DRAFT_PLUGIN_RESULT_FILE\"\n", 0755)}
+	_, err = invalid.Run(context.Background(), Context{}, nil, &stdout, &stderr)
+	assert.ErrorContains(t, err, "parsing plugin invalid result")
+}
+
+func TestRunStreamsOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts use a POSIX shell")
+	}
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "continue")
+	script := "#!/bin/sh\necho started\nwhile [ ! -f " + marker + " ]; do sleep 0.01; done\n"
+	p := Plugin{Name: "slow", Path: writeScript(t, dir, "draft-slow", script, 0755)}
+
+	stdout := &lockedBuffer{}
+	done := make(chan error)
+	go func() {
+		_, err := p.Run(context.Background(), Context{}, nil, stdout, io.Discard)
+		done <- err
+	}()
+
+	assert.Eventually(t, func() bool { return stdout.String() == "started\n" }, 5*time.Second, 10*time.Millisecond, "output should be streamed before the plugin exits")
+	assert.Nil(t, os.WriteFile(marker, nil, 0644))
+	assert.Nil(t, <-done)
+}
+
+// lockedBuffer is a bytes.Buffer safe to read while a plugin writes to it
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWriteResult(t *testing.T) {
+	w := &writers.FileMapWriter{}
+	err := WriteResult(&Result{Files: map[string]string{"a/b.yaml": "x"}}, "dest", w)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("x"), w.FileMap[filepath.Join("dest", "a", "b.yaml")])
+
+	err = WriteResult(&Result{Files: map[string]string{"../escape.yaml": "x"}}, "dest", w)
+	assert.NotNil(t, err)
+	err = WriteResult(&Result{Files: map[string]string{"a/../..": "x"}}, "dest", w)
+	assert.NotNil(t, err)
+
+	err = WriteResult(&Result{Files: map[string]string{"..config": "x"}}, "dest", w)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("x"), w.FileMap[filepath.Join("dest", "..config")])
+}
@@ -0,0 +1,161 @@
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// FileEnv is the environment variable holding the path of an organization policy file, which takes precedence over
+// the user config
+const FileEnv = "DRAFT_POLICY_FILE"
+
+// kustomizeGroup is the api group of kustomize's own config files, which aren't Kubernetes resources
+const kustomizeGroup = "kustomize.config.k8s.io"
+
+// Config is the part of a draft user config or organization policy file that applies to generated files
+type Config struct {
+	Metadata    Metadata    `yaml:"metadata"`
+	ImageMirror ImageMirror `yaml:"imageMirror"`
+}
+
+// ImageMirror is the registry mirror the images of generated Dockerfiles and Kubernetes resources are pulled through,
+// for networks that block pulling from public registries such as Docker Hub and MCR
+type ImageMirror struct {
+	// Prefix is the registry host and path the images are pulled from, such as registry.corp/proxy/
+	Prefix string `yaml:"prefix"`
+	// Registries are the registries whose images are pulled through the mirror, the public ones when empty
+	Registries []string `yaml:"registries"`
+}
+
+// Metadata holds the labels and annotations merged into every generated Kubernetes resource, for example a cost
+// center, owning team or compliance tier
+type Metadata struct {
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+// Load reads and merges the config files at paths, with later files taking precedence
+func Load(paths ...string) (*Config, error) {
+	merged := &Config{Metadata: Metadata{Labels: map[string]string{}, Annotations: map[string]string{}}}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading policy config: %w", err)
+		}
+
+		var c Config
+		if err := yaml.Unmarshal(content, &c); err != nil {
+			return nil, fmt.Errorf("parsing policy config %s: %w", path, err)
+		}
+		log.Debugf("loaded %d labels and %d annotations from %s", len(c.Metadata.Labels), len(c.Metadata.Annotations), path)
+		for k, v := range c.Metadata.Labels {
+			merged.Metadata.Labels[k] = v
+		}
+		for k, v := range c.Metadata.Annotations {
+			merged.Metadata.Annotations[k] = v
+		}
+		if c.ImageMirror.Prefix != "" {
+			merged.ImageMirror.Prefix = c.ImageMirror.Prefix
+		}
+		if len(c.ImageMirror.Registries) > 0 {
+			merged.ImageMirror.Registries = c.ImageMirror.Registries
+		}
+	}
+	return merged, nil
+}
+
+// IsEmpty reports whether m has no labels or annotations to merge
+func (m Metadata) IsEmpty() bool {
+	return len(m.Labels) == 0 && len(m.Annotations) == 0
+}
+
+// Apply merges the labels and annotations into the metadata and pod template metadata of every Kubernetes resource
+// in a generated yaml file, overriding values set by the template. Other files, and yaml files that aren't plain
+// Kubernetes resources such as helm chart templates, are returned unchanged.
+func (m Metadata) Apply(path string, content []byte) ([]byte, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if m.IsEmpty() || (ext != ".yaml" && ext != ".yml") {
+		return content, nil
+	}
+
+	var out bytes.Buffer
+	rw := &kio.ByteReadWriter{Reader: bytes.NewReader(content), Writer: &out, PreserveSeqIndent: true}
+	nodes, err := rw.Read()
+	if err != nil {
+		log.Debugf("not applying policy metadata to %s, it isn't plain yaml: %s", path, err)
+		return content, nil
+	}
+
+	changed := false
+	for _, node := range nodes {
+		meta, err := node.GetMeta()
+		if err != nil || meta.APIVersion == "" || meta.Kind == "" || strings.HasPrefix(meta.APIVersion, kustomizeGroup+"/") {
+			continue
+		}
+		if err := m.setMetadata(node); err != nil {
+			return nil, fmt.Errorf("applying policy metadata to %s %s in %s: %w", meta.Kind, meta.Name, path, err)
+		}
+		podTemplate, err := node.Pipe(kyaml.Lookup("spec", "template"))
+		if err != nil {
+			return nil, err
+		}
+		if podTemplate != nil {
+			if err := m.setMetadata(podTemplate); err != nil {
+				return nil, fmt.Errorf("applying policy metadata to the pod template of %s %s in %s: %w", meta.Kind, meta.Name, path, err)
+			}
+		}
+		changed = true
+	}
+	if !changed {
+		return content, nil
+	}
+
+	if err := rw.Write(nodes); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func (m Metadata) setMetadata(node *kyaml.RNode) error {
+	if err := setMetadataFields(node, kyaml.LabelsField, m.Labels); err != nil {
+		return err
+	}
+	return setMetadataFields(node, kyaml.AnnotationsField, m.Annotations)
+}
+
+// setMetadataFields sets values in the metadata field of node, double quoted like the values in draft's templates
+func setMetadataFields(node *kyaml.RNode, field string, values map[string]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	fieldNode, err := node.Pipe(kyaml.PathGetter{Path: []string{kyaml.MetadataField, field}, Create: kyaml.MappingNode})
+	if err != nil {
+		return err
+	}
+	for _, k := range sortedKeys(values) {
+		value := kyaml.NewStringRNode(values[k])
+		value.YNode().Style = kyaml.DoubleQuotedStyle
+		if err := fieldNode.PipeE(kyaml.SetField(k, value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
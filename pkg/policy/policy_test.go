@@ -0,0 +1,116 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	userConfig := filepath.Join(dir, "draft.yaml")
+	assert.Nil(t, os.WriteFile(userConfig, []byte(`metadata:
+  labels:
+    team: web
+    cost-center: "100"
+`), 0644))
+	orgPolicy := filepath.Join(dir, "policy.yaml")
+	assert.Nil(t, os.WriteFile(orgPolicy, []byte(`metadata:
+  labels:
+    cost-center: "200"
+  annotations:
+    example.com/compliance-tier: high
+imageMirror:
+  prefix: registry.corp/proxy/
+`), 0644))
+
+	c, err := Load(userConfig, orgPolicy)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"team": "web", "cost-center": "200"}, c.Metadata.Labels)
+	assert.Equal(t, map[string]string{"example.com/compliance-tier": "high"}, c.Metadata.Annotations)
+	assert.Equal(t, ImageMirror{Prefix: "registry.corp/proxy/"}, c.ImageMirror)
+
+	c, err = Load()
+	assert.Nil(t, err)
+	assert.True(t, c.Metadata.IsEmpty())
+
+	_, err = Load(filepath.Join(dir, "missing.yaml"))
+	assert.NotNil(t, err)
+}
+
+func TestApply(t *testing.T) {
+	m := Metadata{Labels: map[string]string{"team": "web"}, Annotations: map[string]string{"example.com/tier": "high"}}
+
+	out, err := m.Apply("manifests/deployment.yaml", []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    app: app
+    team: old
+spec:
+  selector:
+    matchLabels:
+      app: app
+  template:
+    metadata:
+      labels:
+        app: app
+    spec:
+      containers:
+        - name: app
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    app: app
+    team: web
+  annotations:
+    example.com/tier: "high"
+spec:
+  selector:
+    matchLabels:
+      app: app
+  template:
+    metadata:
+      labels:
+        app: app
+        team: "web"
+      annotations:
+        example.com/tier: "high"
+    spec:
+      containers:
+        - name: app
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+  annotations:
+    example.com/tier: "high"
+  labels:
+    team: "web"
+`, string(out))
+
+	unchanged := map[string]string{
+		"base/kustomization.yaml":       "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n  - deployment.yaml\n",
+		"charts/templates/service.yaml": "apiVersion: v1\nkind: Service\nmetadata:\n  name: {{ include \"app.fullname\" . }}\n  labels:\n    {{- include \"app.labels\" . | nindent 4 }}\n",
+		"charts/values.yaml":            "replicaCount: 1\n",
+		"Dockerfile":                    "FROM scratch\n",
+	}
+	for path, content := range unchanged {
+		out, err := m.Apply(path, []byte(content))
+		assert.Nil(t, err, path)
+		assert.Equal(t, content, string(out), path)
+	}
+}
@@ -0,0 +1,137 @@
+// Package progress draws the overall progress of phases that run concurrently on one line of a terminal, and records
+// how long each phase took.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	barWidth = 30
+	// redrawInterval throttles redrawing the bar for phases that report progress on every file
+	redrawInterval = 100 * time.Millisecond
+)
+
+// Bar is the overall progress of its phases, the average of their progress. A Bar whose output is nil draws nothing
+// and only records the phases' timings.
+type Bar struct {
+	mu       sync.Mutex
+	out      io.Writer
+	label    string
+	phases   []*Phase
+	lastDraw time.Time
+	now      func() time.Time
+}
+
+// Phase is a phase of a Bar, reporting its own progress
+type Phase struct {
+	bar      *Bar
+	name     string
+	fraction float64
+	start    time.Time
+	duration time.Duration
+	done     bool
+}
+
+// Timing is how long a phase took
+type Timing struct {
+	Name     string
+	Duration time.Duration
+}
+
+// New returns a Bar drawn to out, nil to draw nothing, prefixed with label
+func New(out io.Writer, label string) *Bar {
+	return &Bar{out: out, label: label, now: time.Now}
+}
+
+// Phase starts a phase of the bar named name
+func (b *Bar) Phase(name string) *Phase {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	p := &Phase{bar: b, name: name, start: b.now()}
+	b.phases = append(b.phases, p)
+	return p
+}
+
+// Update reports that done out of total units of work of the phase are done
+func (p *Phase) Update(done, total int) {
+	if total <= 0 {
+		return
+	}
+	p.bar.mu.Lock()
+	defer p.bar.mu.Unlock()
+	p.fraction = float64(done) / float64(total)
+	p.bar.draw(false)
+}
+
+// Done ends the phase, recording how long it took
+func (p *Phase) Done() {
+	p.bar.mu.Lock()
+	defer p.bar.mu.Unlock()
+	if p.done {
+		return
+	}
+	p.done = true
+	p.fraction = 1
+	p.duration = p.bar.now().Sub(p.start)
+	p.bar.draw(true)
+}
+
+// Finish clears the bar and returns the timings of its phases in the order they were started
+func (b *Bar) Finish() []Timing {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.out != nil {
+		fmt.Fprint(b.out, "\r\033[K")
+	}
+	timings := make([]Timing, len(b.phases))
+	for i, p := range b.phases {
+		duration := p.duration
+		if !p.done {
+			duration = b.now().Sub(p.start)
+		}
+		timings[i] = Timing{Name: p.name, Duration: duration}
+	}
+	return timings
+}
+
+// draw redraws the bar, at most every redrawInterval unless force is set
+func (b *Bar) draw(force bool) {
+	if b.out == nil {
+		return
+	}
+	now := b.now()
+	if !force && now.Sub(b.lastDraw) < redrawInterval {
+		return
+	}
+	b.lastDraw = now
+
+	var total float64
+	var running []string
+	for _, p := range b.phases {
+		total += p.fraction
+		if !p.done {
+			running = append(running, p.name)
+		}
+	}
+	overall := total / float64(len(b.phases))
+	filled := int(overall * barWidth)
+	cyan := color.New(color.Bold, color.FgCyan).SprintFunc()
+	fmt.Fprintf(b.out, "\r\033[K%s %s [%s%s] %3.0f%% %s", cyan("[Draft]"), b.label,
+		strings.Repeat("#", filled), strings.Repeat(" ", barWidth-filled), overall*100, strings.Join(running, ", "))
+}
+
+// String formats the timings as "name 1.2s, name 0.3s"
+func String(timings []Timing) string {
+	parts := make([]string, len(timings))
+	for i, t := range timings {
+		parts[i] = fmt.Sprintf("%s %s", t.Name, t.Duration.Round(10*time.Millisecond))
+	}
+	return strings.Join(parts, ", ")
+}
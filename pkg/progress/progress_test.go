@@ -0,0 +1,41 @@
+package progress
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBar(t *testing.T) {
+	var out bytes.Buffer
+	now := time.Unix(0, 0)
+	b := New(&out, "Reading")
+	b.now = func() time.Time { return now }
+
+	detection := b.Phase("detection")
+	extraction := b.Phase("extraction")
+	detection.Update(1, 2)
+	assert.Contains(t, out.String(), " 25% detection, extraction")
+
+	now = now.Add(2 * time.Second)
+	extraction.Done()
+	assert.Contains(t, out.String(), " 75% detection")
+
+	now = now.Add(time.Second)
+	detection.Done()
+	assert.Contains(t, out.String(), "100%")
+
+	timings := b.Finish()
+	assert.Equal(t, []Timing{{Name: "detection", Duration: 3 * time.Second}, {Name: "extraction", Duration: 2 * time.Second}}, timings)
+	assert.Equal(t, "detection 3s, extraction 2s", String(timings))
+}
+
+func TestBarWithoutOutput(t *testing.T) {
+	b := New(nil, "Reading")
+	p := b.Phase("detection")
+	p.Update(1, 1)
+	p.Done()
+	assert.Len(t, b.Finish(), 1)
+}
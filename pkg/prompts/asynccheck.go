@@ -0,0 +1,124 @@
+package prompts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/manifoldco/promptui"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/spinner"
+)
+
+// AsyncChecks runs expensive validations, such as az lookups, in the background while the remaining prompts are
+// answered, so input is only checked inline by fast local validators and slow checks don't block typing
+type AsyncChecks struct {
+	// Confirm asks whether to continue after failed warning checks, defaulting to a yes/no select
+	Confirm func(label string) (bool, error)
+
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	results []asyncCheckResult
+}
+
+type asyncCheckResult struct {
+	name string
+	warn bool
+	err  error
+}
+
+// Go runs check in the background. Wait returns an error if it fails.
+func (a *AsyncChecks) Go(name string, check func() error) {
+	a.run(name, false, check)
+}
+
+// GoWarning runs check in the background. If it fails, Wait reports it and asks whether to continue anyway.
+func (a *AsyncChecks) GoWarning(name string, check func() error) {
+	a.run(name, true, check)
+}
+
+func (a *AsyncChecks) run(name string, warn bool, check func() error) {
+	a.mu.Lock()
+	i := len(a.results)
+	a.results = append(a.results, asyncCheckResult{name: name, warn: warn})
+	a.mu.Unlock()
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		log.Debugf("checking %s in the background", name)
+		err := check()
+
+		a.mu.Lock()
+		a.results[i].err = err
+		a.mu.Unlock()
+	}()
+}
+
+// Wait shows msg with a progress indicator until every check has finished. Failed checks are returned as an error,
+// failed warning checks are logged and the user is asked whether to continue.
+func (a *AsyncChecks) Wait(msg string) error {
+	a.mu.Lock()
+	pending := len(a.results)
+	a.mu.Unlock()
+	if pending == 0 {
+		return nil
+	}
+
+	s := spinner.CreateSpinner(msg)
+	s.Start()
+	a.wg.Wait()
+	s.Stop()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var errs, warnings []string
+	for _, result := range a.results {
+		if result.err == nil {
+			continue
+		}
+		failure := fmt.Sprintf("%s: %s", result.name, result.err)
+		if result.warn {
+			warnings = append(warnings, failure)
+		} else {
+			errs = append(errs, failure)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	if len(warnings) == 0 {
+		return nil
+	}
+
+	for _, warning := range warnings {
+		log.Warn(warning)
+	}
+	confirm := a.Confirm
+	if confirm == nil {
+		confirm = confirmContinue
+	}
+	ok, err := confirm(fmt.Sprintf("%d check(s) failed, would you like to continue anyway?", len(warnings)))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("aborted after failed checks: %s", strings.Join(warnings, "; "))
+	}
+	return nil
+}
+
+func confirmContinue(label string) (bool, error) {
+	_, selectResponse, err := RunSelect(&promptui.Select{
+		Label: label,
+		Items: []string{"no", "yes"},
+	})
+	if err != nil {
+		return false, err
+	}
+	return selectResponse == "yes", nil
+}
@@ -0,0 +1,47 @@
+package prompts
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsyncChecks(t *testing.T) {
+	checks := &AsyncChecks{}
+	assert.Nil(t, checks.Wait("nothing to check"))
+
+	started := time.Now()
+	checks.Go("slow", func() error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	checks.Go("also slow", func() error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	// checks don't block the caller
+	assert.Less(t, time.Since(started), 50*time.Millisecond)
+	assert.Nil(t, checks.Wait("checking"))
+
+	checks = &AsyncChecks{}
+	checks.Go("subscription", func() error { return errors.New("subscription not found") })
+	checks.GoWarning("github repo", func() error { return errors.New("not found") })
+	assert.EqualError(t, checks.Wait("checking"), "subscription: subscription not found")
+}
+
+func TestAsyncChecksWarnings(t *testing.T) {
+	var confirmLabel string
+	checks := &AsyncChecks{Confirm: func(label string) (bool, error) {
+		confirmLabel = label
+		return true, nil
+	}}
+	checks.GoWarning("github repo", func() error { return errors.New("not found") })
+	assert.Nil(t, checks.Wait("checking"))
+	assert.Equal(t, "1 check(s) failed, would you like to continue anyway?", confirmLabel)
+
+	checks = &AsyncChecks{Confirm: func(string) (bool, error) { return false, nil }}
+	checks.GoWarning("github repo", func() error { return errors.New("not found") })
+	assert.ErrorContains(t, checks.Wait("checking"), "github repo: not found")
+}
@@ -0,0 +1,206 @@
+package prompts
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"golang.org/x/term"
+)
+
+// RunSelect runs s, falling back to a numbered list answered on stdin when promptui can't render in the terminal, or
+// asks through the prompt protocol set with SetPromptProtocol.
+// It returns the index and string value of the selected item like promptui.Select.Run.
+func RunSelect(s *promptui.Select) (int, string, error) {
+	if nonInteractive {
+		return 0, "", fmt.Errorf("%w: %s", ErrNonInteractive, s.Label)
+	}
+	if protocol != nil {
+		return protocol.runSelect(s)
+	}
+	if !useFallback(s.Stdin) {
+		return s.Run()
+	}
+
+	items, err := itemStrings(s.Items)
+	if err != nil {
+		return 0, "", err
+	}
+	return runFallbackSelect(fmt.Sprint(s.Label), items, s.CursorPos, inputOrStdin(s.Stdin), outputOrStdout(s.Stdout))
+}
+
+// RunPrompt runs p, falling back to reading a plain line from stdin when promptui can't render in the terminal, or
+// asks through the prompt protocol set with SetPromptProtocol.
+func RunPrompt(p *promptui.Prompt) (string, error) {
+	if nonInteractive {
+		return "", fmt.Errorf("%w: %s", ErrNonInteractive, p.Label)
+	}
+	if protocol != nil {
+		return protocol.runPrompt(p)
+	}
+	if !useFallback(p.Stdin) {
+		return p.Run()
+	}
+
+	return runFallbackPrompt(p, inputOrStdin(p.Stdin), outputOrStdout(p.Stdout))
+}
+
+// useFallback reports whether prompts should be plain line input instead of promptui. promptui needs a terminal that
+// understands ANSI escape codes, which TERM=dumb terminals (some IDEs and basic SSH sessions) and non-TTY stdin lack.
+func useFallback(stdin io.Reader) bool {
+	if os.Getenv("TERM") == "dumb" {
+		return true
+	}
+
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+	if f, ok := stdin.(*os.File); ok {
+		return !term.IsTerminal(int(f.Fd()))
+	}
+	// explicit readers such as pipes in tests drive promptui directly
+	return false
+}
+
+func inputOrStdin(in io.ReadCloser) io.Reader {
+	if in == nil {
+		return os.Stdin
+	}
+	return in
+}
+
+func outputOrStdout(out io.WriteCloser) io.Writer {
+	if out == nil {
+		return os.Stdout
+	}
+	return out
+}
+
+func itemStrings(items interface{}) ([]string, error) {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, errors.New("select items must be a slice or array")
+	}
+
+	strs := make([]string, v.Len())
+	for i := range strs {
+		strs[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strs, nil
+}
+
+func runFallbackSelect(label string, items []string, defaultIndex int, in io.Reader, out io.Writer) (int, string, error) {
+	if len(items) == 0 {
+		return 0, "", errors.New("no selection options")
+	}
+	if defaultIndex < 0 || defaultIndex >= len(items) {
+		defaultIndex = 0
+	}
+
+	fmt.Fprintln(out, label)
+	for i, item := range items {
+		fmt.Fprintf(out, "  %d) %s\n", i+1, item)
+	}
+
+	for {
+		fmt.Fprintf(out, "Enter a number (1-%d) [%d]: ", len(items), defaultIndex+1)
+		line, err := readLine(in)
+		if err != nil {
+			return 0, "", err
+		}
+
+		if line == "" {
+			return defaultIndex, items[defaultIndex], nil
+		}
+		if n, err := strconv.Atoi(line); err == nil && n >= 1 && n <= len(items) {
+			return n - 1, items[n-1], nil
+		}
+		for i, item := range items {
+			if strings.EqualFold(item, line) {
+				return i, item, nil
+			}
+		}
+		fmt.Fprintf(out, "%q is not one of the options\n", line)
+	}
+}
+
+func runFallbackPrompt(p *promptui.Prompt, in io.Reader, out io.Writer) (string, error) {
+	label := fmt.Sprint(p.Label)
+
+	if p.IsConfirm {
+		fmt.Fprintf(out, "%s [y/N]: ", label)
+		line, err := readLine(in)
+		if err != nil {
+			return "", err
+		}
+		if answer := strings.ToLower(line); answer == "y" || answer == "yes" {
+			return line, nil
+		}
+		return "", promptui.ErrAbort
+	}
+
+	if p.Default != "" {
+		label += " [" + p.Default + "]"
+	}
+	for {
+		fmt.Fprintf(out, "%s: ", label)
+		line, err := readFallbackInput(in, out, p.Mask != 0)
+		if err != nil {
+			return "", err
+		}
+		if line == "" {
+			line = p.Default
+		}
+
+		if p.Validate != nil {
+			if err := p.Validate(line); err != nil {
+				fmt.Fprintf(out, "invalid input: %s\n", err)
+				continue
+			}
+		}
+		return line, nil
+	}
+}
+
+// readFallbackInput reads a line from in, without echoing it when masked is set and in is a terminal
+func readFallbackInput(in io.Reader, out io.Writer, masked bool) (string, error) {
+	if f, ok := in.(*os.File); ok && masked && term.IsTerminal(int(f.Fd())) {
+		line, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(out)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(line)), nil
+	}
+	return readLine(in)
+}
+
+// readLine reads a single line from in a byte at a time, so input meant for later prompts isn't buffered away
+func readLine(in io.Reader) (string, error) {
+	var sb strings.Builder
+	b := make([]byte, 1)
+	for {
+		n, err := in.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				break
+			}
+			sb.WriteByte(b[0])
+		}
+		if err == io.EOF {
+			if sb.Len() == 0 {
+				return "", promptui.ErrEOF
+			}
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
@@ -0,0 +1,71 @@
+package prompts
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/manifoldco/promptui"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunFallbackSelect(t *testing.T) {
+	items := []string{"helm", "kustomize", "manifests"}
+
+	out := &bytes.Buffer{}
+	i, item, err := runFallbackSelect("Select k8s Deployment Type", items, 0, strings.NewReader("4\nfoo\n2\n"), out)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, i)
+	assert.Equal(t, "kustomize", item)
+	assert.Contains(t, out.String(), "  3) manifests\n")
+	assert.Contains(t, out.String(), `"4" is not one of the options`)
+
+	i, item, err = runFallbackSelect("Select", items, 2, strings.NewReader("\n"), io.Discard)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, i)
+	assert.Equal(t, "manifests", item)
+
+	i, _, err = runFallbackSelect("Select", items, 0, strings.NewReader("HELM\n"), io.Discard)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, i)
+
+	_, _, err = runFallbackSelect("Select", items, 0, strings.NewReader(""), io.Discard)
+	assert.True(t, errors.Is(err, promptui.ErrEOF))
+
+	_, _, err = runFallbackSelect("Select", nil, 0, strings.NewReader("1\n"), io.Discard)
+	assert.NotNil(t, err)
+}
+
+func TestRunFallbackPrompt(t *testing.T) {
+	in := strings.NewReader("\nmy-app\nleftover\n")
+	got, err := runFallbackPrompt(&promptui.Prompt{Label: "Enter app name", Validate: NoBlankStringValidator}, in, io.Discard)
+	assert.Nil(t, err)
+	assert.Equal(t, "my-app", got)
+
+	// the next line is left for the next prompt
+	got, err = runFallbackPrompt(&promptui.Prompt{Label: "Enter port", Default: "80"}, in, io.Discard)
+	assert.Nil(t, err)
+	assert.Equal(t, "leftover", got)
+
+	got, err = runFallbackPrompt(&promptui.Prompt{Label: "Enter port", Default: "80"}, strings.NewReader("\n"), io.Discard)
+	assert.Nil(t, err)
+	assert.Equal(t, "80", got)
+
+	_, err = runFallbackPrompt(&promptui.Prompt{Label: "Continue", IsConfirm: true}, strings.NewReader("n\n"), io.Discard)
+	assert.Equal(t, promptui.ErrAbort, err)
+
+	_, err = runFallbackPrompt(&promptui.Prompt{Label: "Continue", IsConfirm: true}, strings.NewReader("yes\n"), io.Discard)
+	assert.Nil(t, err)
+}
+
+func TestUseFallback(t *testing.T) {
+	t.Setenv("TERM", "dumb")
+	assert.True(t, useFallback(nil))
+
+	t.Setenv("TERM", "xterm-256color")
+	reader, writer := io.Pipe()
+	defer writer.Close()
+	assert.False(t, useFallback(reader))
+}
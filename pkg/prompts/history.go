@@ -0,0 +1,124 @@
+package prompts
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/manifoldco/promptui"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Azure/draft/pkg/config"
+)
+
+// historyLimit is the number of previous values kept for each variable
+const historyLimit = 5
+
+const previousValueLabel = "use previous: "
+
+// History holds the values previously answered to the prompts for each variable, most recent first, which are offered
+// as choices the next time the variable is prompted for. Secret variables are never recorded.
+type History struct {
+	Variables map[string][]string `yaml:"variables"`
+	changed   bool
+}
+
+var history *History
+
+// SetHistory sets the history offered by the prompts and recording their answers, nil disables it
+func SetHistory(h *History) {
+	history = h
+}
+
+// LoadHistory reads the History saved at path, an empty one when there is none
+func LoadHistory(path string) (*History, error) {
+	h := &History{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading prompt history: %w", err)
+	}
+	if err := yaml.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("parsing prompt history %s: %w", path, err)
+	}
+	return h, nil
+}
+
+// Save writes the history to path when values were added to it since it was loaded
+func (h *History) Save(path string) error {
+	if h == nil || !h.changed {
+		return nil
+	}
+	data, err := yaml.Marshal(h)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	h.changed = false
+	return nil
+}
+
+// Values returns the previous values of the variable name, most recent first
+func (h *History) Values(name string) []string {
+	if h == nil {
+		return nil
+	}
+	return h.Variables[name]
+}
+
+// Add records value as the most recent value of the variable name, keeping the historyLimit most recent values
+func (h *History) Add(name, value string) {
+	if h == nil || value == "" {
+		return
+	}
+	values := []string{value}
+	for _, previous := range h.Variables[name] {
+		if previous != value && len(values) < historyLimit {
+			values = append(values, previous)
+		}
+	}
+	if h.Variables == nil {
+		h.Variables = make(map[string][]string)
+	}
+	h.Variables[name] = values
+	h.changed = true
+}
+
+// recordable returns whether the answers to customPrompt may be kept in the history
+func recordable(customPrompt config.BuilderVar) bool {
+	return customPrompt.VarType != "secret"
+}
+
+// PromptWithHistory prompts for customPrompt by selecting one of its previous values, falling back to a string prompt
+// when the user chooses to enter a different value
+func PromptWithHistory(customPrompt config.BuilderVar, defaultValue string, previous []string, Stdin io.ReadCloser, Stdout io.WriteCloser) (string, error) {
+	items := make([]string, 0, len(previous)+1)
+	for _, value := range previous {
+		items = append(items, previousValueLabel+value)
+	}
+	items = append(items, manualEntryLabel)
+
+	i, _, err := RunSelect(&promptui.Select{
+		Label:  "Please select " + customPrompt.Description,
+		Items:  items,
+		Stdin:  Stdin,
+		Stdout: Stdout,
+	})
+	if err != nil {
+		return "", err
+	}
+	if i == len(previous) {
+		return RunDefaultableStringPrompt(customPrompt, defaultValue, nil, Stdin, Stdout)
+	}
+	return previous[i], nil
+}
@@ -0,0 +1,67 @@
+package prompts
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/config"
+)
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".draft", "history.yaml")
+	h, err := LoadHistory(path)
+	assert.Nil(t, err)
+	assert.Nil(t, h.Values("AZURECONTAINERREGISTRY"))
+
+	for _, value := range []string{"first", "second", "third", "fourth", "fifth", "second", "sixth", ""} {
+		h.Add("AZURECONTAINERREGISTRY", value)
+	}
+	assert.Equal(t, []string{"sixth", "second", "fifth", "fourth", "third"}, h.Values("AZURECONTAINERREGISTRY"))
+
+	assert.Nil(t, h.Save(path))
+	loaded, err := LoadHistory(path)
+	assert.Nil(t, err)
+	assert.Equal(t, h.Variables, loaded.Variables)
+
+	// an unchanged history isn't written again
+	assert.Nil(t, os.Remove(path))
+	assert.Nil(t, loaded.Save(path))
+	assert.NoFileExists(t, path)
+
+	assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.Nil(t, os.WriteFile(path, []byte("variables: [\n"), 0644))
+	_, err = LoadHistory(path)
+	assert.NotNil(t, err)
+}
+
+func TestRunPromptsWithHistory(t *testing.T) {
+	t.Setenv("TERM", "dumb")
+	h := &History{Variables: map[string][]string{"REGISTRY": {"myregistry", "otherregistry"}, "TOKEN": {"leaked"}}}
+	SetHistory(h)
+	defer SetHistory(nil)
+
+	cfg := &config.DraftConfig{Variables: []config.BuilderVar{
+		{Name: "REGISTRY", Description: "the registry"},
+		{Name: "APPNAME", Description: "the app name"},
+		{Name: "TOKEN", Description: "the token", VarType: "secret"},
+	}}
+
+	// the second previous registry is selected, the app name typed, and the secret isn't offered its history
+	inputs, err := RunPromptsFromConfigWithSkipsIO(cfg, nil, io.NopCloser(strings.NewReader("2\nmyapp\ns3cret\n")), nopWriteCloser{io.Discard})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"REGISTRY": "otherregistry", "APPNAME": "myapp", "TOKEN": "s3cret"}, inputs)
+	assert.Equal(t, []string{"otherregistry", "myregistry"}, h.Values("REGISTRY"))
+	assert.Equal(t, []string{"myapp"}, h.Values("APPNAME"))
+	assert.Equal(t, []string{"leaked"}, h.Values("TOKEN"))
+
+	// entering a different value falls back to a text prompt
+	inputs, err = RunPromptsFromConfigWithSkipsIO(cfg, []string{"APPNAME", "TOKEN"}, io.NopCloser(strings.NewReader("3\nnewregistry\n")), nopWriteCloser{io.Discard})
+	assert.Nil(t, err)
+	assert.Equal(t, "newregistry", inputs["REGISTRY"])
+	assert.Equal(t, []string{"newregistry", "otherregistry", "myregistry"}, h.Values("REGISTRY"))
+}
@@ -1,6 +1,7 @@
 package prompts
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -12,6 +13,42 @@ import (
 	"github.com/Azure/draft/pkg/config"
 )
 
+var advancedPrompts bool
+
+var nonInteractive bool
+
+// ErrNonInteractive is returned by RunSelect and RunPrompt instead of waiting for input when non-interactive mode is set
+var ErrNonInteractive = errors.New("cannot prompt in non-interactive mode")
+
+// SetAdvanced sets whether variables with the advanced stage are prompted for instead of using their defaults
+func SetAdvanced(advanced bool) {
+	advancedPrompts = advanced
+}
+
+// SetNonInteractive sets whether prompts are replaced by variable defaults, so that nothing waits for input
+func SetNonInteractive(enabled bool) {
+	nonInteractive = enabled
+}
+
+// NonInteractive reports whether non-interactive mode is set
+func NonInteractive() bool {
+	return nonInteractive
+}
+
+// MissingVariablesError is returned in non-interactive mode for the variables that have neither a value nor a default
+type MissingVariablesError struct {
+	Variables []config.BuilderVar
+}
+
+func (e *MissingVariablesError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("missing values for variables without a default:")
+	for _, variable := range e.Variables {
+		fmt.Fprintf(&sb, "\n  %s: %s", variable.Name, variable.Description)
+	}
+	return sb.String()
+}
+
 func RunPromptsFromConfig(config *config.DraftConfig) (map[string]string, error) {
 	return RunPromptsFromConfigWithSkips(config, []string{})
 }
@@ -20,16 +57,55 @@ func RunPromptsFromConfigWithSkips(config *config.DraftConfig, varsToSkip []stri
 	return RunPromptsFromConfigWithSkipsIO(config, varsToSkip, nil, nil)
 }
 
+// RunPromptsFromConfigWithValues runs the prompts for the variables of config that provided, such as the --variable
+// values, has no value for. The defaults referencing another variable are resolved against provided as well as the
+// answers.
+func RunPromptsFromConfigWithValues(config *config.DraftConfig, provided map[string]string) (map[string]string, error) {
+	varsToSkip := make([]string, 0, len(provided))
+	for name := range provided {
+		varsToSkip = append(varsToSkip, name)
+	}
+	return runPrompts(config, varsToSkip, provided, nil, nil)
+}
+
 // RunPromptsFromConfigWithSkipsIO runs the prompts for the given config
 // skipping any variables in varsToSkip or where the BuilderVar.IsPromptDisabled is true.
+// Advanced variables with a default are also skipped unless advanced prompts are enabled with SetAdvanced.
+// In non-interactive mode every variable uses its default, and a MissingVariablesError lists those without one.
+// Variables entered as text are offered their previous values from the History set with SetHistory, and record
+// their answers in it. Answers breaking the validation rules of their variable or rejected by the validators set with
+// SetVariableValidators are asked again.
 // If Stdin or Stdout are nil, the default values will be used.
 func RunPromptsFromConfigWithSkipsIO(config *config.DraftConfig, varsToSkip []string, Stdin io.ReadCloser, Stdout io.WriteCloser) (map[string]string, error) {
+	return runPrompts(config, varsToSkip, nil, Stdin, Stdout)
+}
+
+// runPrompts is RunPromptsFromConfigWithSkipsIO resolving the defaults referencing another variable against provided
+// as well as the answers
+func runPrompts(config *config.DraftConfig, varsToSkip []string, provided map[string]string, Stdin io.ReadCloser, Stdout io.WriteCloser) (map[string]string, error) {
 	skipMap := make(map[string]interface{})
 	for _, v := range varsToSkip {
 		skipMap[v] = interface{}(nil)
 	}
 
 	inputs := make(map[string]string)
+	missing := &MissingVariablesError{}
+	// noneSelected holds the multiselect variables answered with no values, which keep that over their default
+	noneSelected := make(map[string]bool)
+	// typed holds the non-secret variables answered as text, whose answers are recorded in the history
+	var typed []string
+	defer func() { currentVariable = "" }()
+	// values are the provided values and the answers so far, which the defaults referencing a variable read
+	values := func() map[string]string {
+		merged := make(map[string]string, len(provided)+len(inputs))
+		for name, value := range provided {
+			merged[name] = value
+		}
+		for name, value := range inputs {
+			merged[name] = value
+		}
+		return merged
+	}
 
 	for _, customPrompt := range config.Variables {
 		promptVariableName := customPrompt.Name
@@ -39,59 +115,165 @@ func RunPromptsFromConfigWithSkipsIO(config *config.DraftConfig, varsToSkip []st
 		}
 		if GetIsPromptDisabled(customPrompt.Name, config.VariableDefaults) {
 			log.Debugf("Skipping prompt for %s as it has IsPromptDisabled=true", promptVariableName)
-			noPromptDefaultValue := GetVariableDefaultValue(promptVariableName, config.VariableDefaults, inputs)
+			noPromptDefaultValue := GetVariableDefaultValue(promptVariableName, config.VariableDefaults, values())
 			if noPromptDefaultValue == "" {
 				return nil, fmt.Errorf("IsPromptDisabled is true for %s but no default value was found", promptVariableName)
 			}
-			log.Debugf("Using default value %s for %s", noPromptDefaultValue, promptVariableName)
+			log.Debugf("Using default value %s for %s", loggedValue(customPrompt, noPromptDefaultValue), promptVariableName)
 			inputs[promptVariableName] = noPromptDefaultValue
 			continue
 		}
+		skipAdvanced := !advancedPrompts && customPrompt.IsAdvanced() && HasVariableDefault(promptVariableName, config.VariableDefaults)
+		if skipAdvanced || nonInteractive {
+			defaultValue, err := resolveDefault(promptVariableName, config.VariableDefaults, values())
+			if err != nil {
+				if nonInteractive {
+					missing.Variables = append(missing.Variables, customPrompt)
+					continue
+				}
+				return nil, fmt.Errorf("%w, pass it with --variable %s=value or answer it with --advanced", err, promptVariableName)
+			}
+			if skipAdvanced {
+				log.Debugf("Skipping prompt for advanced variable %s, using default value %s", promptVariableName, loggedValue(customPrompt, defaultValue))
+			} else {
+				log.Debugf("Skipping prompt for %s in non-interactive mode, using default value %s", promptVariableName, loggedValue(customPrompt, defaultValue))
+			}
+			inputs[promptVariableName] = defaultValue
+			continue
+		}
 
 		log.Debugf("constructing prompt for: %s", promptVariableName)
+		currentVariable = promptVariableName
 		if customPrompt.VarType == "bool" {
 			input, err := RunBoolPrompt(customPrompt, Stdin, Stdout)
 			if err != nil {
 				return nil, err
 			}
 			inputs[promptVariableName] = input
+		} else if customPrompt.IsMultiSelect() {
+			defaultValue := GetVariableDefaultValue(promptVariableName, config.VariableDefaults, values())
+
+			input, err := RunMultiSelectPrompt(customPrompt, defaultValue, Stdin, Stdout)
+			if err != nil {
+				return nil, err
+			}
+			inputs[promptVariableName] = input
+			if input == "" {
+				noneSelected[promptVariableName] = true
+			}
+		} else if customPrompt.Resource != "" && resourcePicker != nil {
+			defaultValue := GetVariableDefaultValue(promptVariableName, config.VariableDefaults, values())
+
+			input, err := PromptByResource(context.Background(), resourcePicker, customPrompt, defaultValue, Stdin, Stdout)
+			if err != nil {
+				return nil, err
+			}
+			input, err = validateAnswer(customPrompt, input, Stdin, Stdout)
+			if err != nil {
+				return nil, err
+			}
+			inputs[promptVariableName] = input
+		} else if previous := history.Values(promptVariableName); len(previous) > 0 && recordable(customPrompt) {
+			defaultValue := GetVariableDefaultValue(promptVariableName, config.VariableDefaults, values())
+
+			input, err := PromptWithHistory(customPrompt, defaultValue, previous, Stdin, Stdout)
+			if err != nil {
+				return nil, err
+			}
+			input, err = validateAnswer(customPrompt, input, Stdin, Stdout)
+			if err != nil {
+				return nil, err
+			}
+			inputs[promptVariableName] = input
+			typed = append(typed, promptVariableName)
 		} else {
-			defaultValue := GetVariableDefaultValue(promptVariableName, config.VariableDefaults, inputs)
+			defaultValue := GetVariableDefaultValue(promptVariableName, config.VariableDefaults, values())
 
 			stringInput, err := RunDefaultableStringPrompt(customPrompt, defaultValue, nil, Stdin, Stdout)
 			if err != nil {
 				return nil, err
 			}
+			stringInput, err = validateAnswer(customPrompt, stringInput, Stdin, Stdout)
+			if err != nil {
+				return nil, err
+			}
 			inputs[promptVariableName] = stringInput
+			if recordable(customPrompt) {
+				typed = append(typed, promptVariableName)
+			}
 		}
 	}
 
+	if len(missing.Variables) > 0 {
+		return nil, missing
+	}
+
 	// Substitute the default value for variables where the user didn't enter anything
 	for _, variableDefault := range config.VariableDefaults {
-		if inputs[variableDefault.Name] == "" {
+		if inputs[variableDefault.Name] == "" && !noneSelected[variableDefault.Name] {
 			inputs[variableDefault.Name] = variableDefault.Value
 		}
 	}
 
+	for _, name := range typed {
+		history.Add(name, inputs[name])
+	}
+
 	return inputs, nil
 }
 
+// loggedValue returns value as logged for customPrompt, hiding the values of secret variables
+func loggedValue(customPrompt config.BuilderVar, value string) string {
+	if customPrompt.VarType == "secret" {
+		return "<redacted>"
+	}
+	return value
+}
+
 // GetVariableDefaultValue returns the default value for a variable, if one is set in variableDefaults from a ReferenceVar or literal VariableDefault.Value in that order.
 func GetVariableDefaultValue(variableName string, variableDefaults []config.BuilderVarDefault, inputs map[string]string) string {
 	defaultValue := ""
 	for _, variableDefault := range variableDefaults {
 		if variableDefault.Name == variableName {
 			defaultValue = variableDefault.Value
-			log.Debugf("setting default value for %s to %s from variable default rule", variableName, defaultValue)
+			// the callers log the value, hidden for secret variables
+			log.Debugf("setting default value for %s from variable default rule", variableName)
 			if variableDefault.ReferenceVar != "" && inputs[variableDefault.ReferenceVar] != "" {
 				defaultValue = inputs[variableDefault.ReferenceVar]
-				log.Debugf("setting default value for %s to %s from referenceVar %s", variableName, defaultValue, variableDefault.ReferenceVar)
+				log.Debugf("setting default value for %s from referenceVar %s", variableName, variableDefault.ReferenceVar)
 			}
 		}
 	}
 	return defaultValue
 }
 
+// resolveDefault returns the default of a variable used without prompting for it. It fails when the variable has no
+// default, or when its default references a variable without a value and has no value of its own to fall back on,
+// which would otherwise generate an empty value.
+func resolveDefault(variableName string, variableDefaults []config.BuilderVarDefault, values map[string]string) (string, error) {
+	for _, variableDefault := range variableDefaults {
+		if variableDefault.Name != variableName {
+			continue
+		}
+		defaultValue := GetVariableDefaultValue(variableName, variableDefaults, values)
+		if defaultValue == "" && variableDefault.ReferenceVar != "" {
+			return "", fmt.Errorf("%s defaults to the value of %s, which has none", variableName, variableDefault.ReferenceVar)
+		}
+		return defaultValue, nil
+	}
+	return "", fmt.Errorf("%s has no default", variableName)
+}
+
+// HasVariableDefault returns whether variableDefaults defines a default for the variable
+func HasVariableDefault(variableName string, variableDefaults []config.BuilderVarDefault) bool {
+	for _, variableDefault := range variableDefaults {
+		if variableDefault.Name == variableName {
+			return true
+		}
+	}
+	return false
+}
+
 func GetIsPromptDisabled(variableName string, variableDefaults []config.BuilderVarDefault) bool {
 	for _, variableDefault := range variableDefaults {
 		if variableDefault.Name == variableName {
@@ -109,13 +291,72 @@ func RunBoolPrompt(customPrompt config.BuilderVar, Stdin io.ReadCloser, Stdout i
 		Stdout: Stdout,
 	}
 
-	_, input, err := newSelect.Run()
+	_, input, err := RunSelect(newSelect)
 	if err != nil {
 		return "", err
 	}
 	return input, nil
 }
 
+// multiSelectDone is the option that ends a multiselect prompt
+const multiSelectDone = "Done"
+
+// RunMultiSelectPrompt runs a prompt for a multiselect variable, toggling one of its AllowedValues with each selection
+// until Done is selected, and returns the selected values joined by config.MultiSelectSeparator. The values of
+// defaultValue start out selected.
+func RunMultiSelectPrompt(customPrompt config.BuilderVar, defaultValue string, Stdin io.ReadCloser, Stdout io.WriteCloser) (string, error) {
+	if len(customPrompt.AllowedValues) == 0 {
+		return "", fmt.Errorf("multiselect variable %s has no allowed values", customPrompt.Name)
+	}
+	if err := customPrompt.ValidateMultiSelectValue(defaultValue); err != nil {
+		return "", fmt.Errorf("default value: %w", err)
+	}
+
+	label := fmt.Sprintf("Please select %s", customPrompt.Description)
+	if protocol != nil && !nonInteractive {
+		return protocol.runMultiSelect(customPrompt, label, defaultValue)
+	}
+
+	selected := make(map[string]bool)
+	for _, value := range config.SplitMultiSelectValue(defaultValue) {
+		selected[value] = true
+	}
+	for {
+		items := []string{multiSelectDone}
+		for _, value := range customPrompt.AllowedValues {
+			mark := "[ ]"
+			if selected[value] {
+				mark = "[x]"
+			}
+			items = append(items, mark+" "+value)
+		}
+		newSelect := &promptui.Select{
+			Label:  fmt.Sprintf("%s, toggling one option at a time, then %s", label, multiSelectDone),
+			Items:  items,
+			Stdin:  Stdin,
+			Stdout: Stdout,
+		}
+
+		i, _, err := RunSelect(newSelect)
+		if err != nil {
+			return "", err
+		}
+		if i == 0 {
+			break
+		}
+		value := customPrompt.AllowedValues[i-1]
+		selected[value] = !selected[value]
+	}
+
+	var values []string
+	for _, value := range customPrompt.AllowedValues {
+		if selected[value] {
+			values = append(values, value)
+		}
+	}
+	return strings.Join(values, config.MultiSelectSeparator), nil
+}
+
 // AllowAllStringValidator is a string validator that allows any string
 func AllowAllStringValidator(_ string) error {
 	return nil
@@ -131,6 +372,11 @@ func NoBlankStringValidator(s string) error {
 
 // RunDefaultableStringPrompt runs a prompt for a string variable, returning the user string input for the prompt
 func RunDefaultableStringPrompt(customPrompt config.BuilderVar, defaultValue string, validate func(string) error, Stdin io.ReadCloser, Stdout io.WriteCloser) (string, error) {
+	return runDefaultableStringPrompt(customPrompt, defaultValue, "", validate, Stdin, Stdout)
+}
+
+// runDefaultableStringPrompt is RunDefaultableStringPrompt with previous, a rejected answer, filled in for editing
+func runDefaultableStringPrompt(customPrompt config.BuilderVar, defaultValue, previous string, validate func(string) error, Stdin io.ReadCloser, Stdout io.WriteCloser) (string, error) {
 	var validatorFunc func(string) error
 	if validate == nil {
 		validatorFunc = NoBlankStringValidator
@@ -141,6 +387,15 @@ func RunDefaultableStringPrompt(customPrompt config.BuilderVar, defaultValue str
 		validatorFunc = AllowAllStringValidator
 		defaultString = " (default: " + defaultValue + ")"
 	}
+	if customPrompt.IsNumeric() {
+		// an empty input takes the default, which is validated when the templates are rendered
+		validatorFunc = func(s string) error {
+			if s == "" && defaultValue != "" {
+				return nil
+			}
+			return customPrompt.ValidateNumericValue(s)
+		}
+	}
 
 	prompt := &promptui.Prompt{
 		Label:    "Please enter " + customPrompt.Description + defaultString,
@@ -148,8 +403,15 @@ func RunDefaultableStringPrompt(customPrompt config.BuilderVar, defaultValue str
 		Stdin:    Stdin,
 		Stdout:   Stdout,
 	}
+	if customPrompt.VarType == "secret" {
+		prompt.Mask = '*'
+	}
+	if previous != "" {
+		prompt.Default = previous
+		prompt.AllowEdit = true
+	}
 
-	input, err := prompt.Run()
+	input, err := RunPrompt(prompt)
 	if err != nil {
 		return "", err
 	}
@@ -171,7 +433,7 @@ func GetInputFromPrompt(desiredInput string) string {
 		},
 	}
 
-	input, err := prompt.Run()
+	input, err := RunPrompt(prompt)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -233,7 +495,7 @@ func Select[T any](label string, items []T, opt *SelectOpt[T]) (T, error) {
 		Searcher: searcher,
 	}
 
-	i, _, err := p.Run()
+	i, _, err := RunSelect(&p)
 	if err != nil {
 		return *new(T), fmt.Errorf("running select: %w", err)
 	}
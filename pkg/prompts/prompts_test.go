@@ -1,9 +1,16 @@
 package prompts
 
 import (
+	"bytes"
+	"errors"
 	"io"
+	"strings"
 	"testing"
 
+	"github.com/manifoldco/promptui"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
 	"github.com/Azure/draft/pkg/config"
 )
 
@@ -144,6 +151,7 @@ func TestRunPromptsFromConfigWithSkipsIO(t *testing.T) {
 		config       config.DraftConfig
 		userInputs   []string
 		defaultValue string
+		advanced     bool
 		want         map[string]string
 		wantErr      bool
 	}{
@@ -213,10 +221,43 @@ func TestRunPromptsFromConfigWithSkipsIO(t *testing.T) {
 				"var4":           "entered-value-for-4",
 			},
 			wantErr: false,
+		}, {
+			testName: "basicSkipsAdvanced",
+			config: config.DraftConfig{
+				Variables: []config.BuilderVar{
+					{Name: "var1-advanced", Stage: config.StageAdvanced},
+					{Name: "var2", Stage: config.StageBasic},
+				},
+				VariableDefaults: []config.BuilderVarDefault{
+					{Name: "var1-advanced", Value: "defaultValue1"},
+				},
+			},
+			userInputs: []string{"entered-value-for-2\n"},
+			want: map[string]string{
+				"var1-advanced": "defaultValue1",
+				"var2":          "entered-value-for-2",
+			},
+		}, {
+			testName: "advancedPromptsAdvanced",
+			config: config.DraftConfig{
+				Variables: []config.BuilderVar{
+					{Name: "var1-advanced", Stage: config.StageAdvanced},
+				},
+				VariableDefaults: []config.BuilderVarDefault{
+					{Name: "var1-advanced", Value: "defaultValue1"},
+				},
+			},
+			userInputs: []string{"entered-value-for-1\n"},
+			advanced:   true,
+			want: map[string]string{
+				"var1-advanced": "entered-value-for-1",
+			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.testName, func(t *testing.T) {
+			SetAdvanced(tt.advanced)
+			defer SetAdvanced(false)
 			inReader, inWriter := io.Pipe()
 
 			go func() {
@@ -246,3 +287,121 @@ func TestRunPromptsFromConfigWithSkipsIO(t *testing.T) {
 		})
 	}
 }
+
+func TestRunPromptsNonInteractive(t *testing.T) {
+	SetNonInteractive(true)
+	defer SetNonInteractive(false)
+
+	cfg := &config.DraftConfig{
+		Variables: []config.BuilderVar{
+			{Name: "PORT", Description: "the port"},
+			{Name: "ENABLED", Description: "whether it is enabled", VarType: "bool"},
+			{Name: "NAMESPACE", Description: "the namespace"},
+			{Name: "APPNAME", Description: "the app name"},
+			{Name: "SERVICEPORT", Description: "the service port"},
+		},
+		VariableDefaults: []config.BuilderVarDefault{
+			{Name: "PORT", Value: "80"},
+			{Name: "ENABLED", Value: "false"},
+			{Name: "SERVICEPORT", ReferenceVar: "PORT"},
+		},
+	}
+
+	inputs, err := RunPromptsFromConfigWithSkipsIO(cfg, []string{"NAMESPACE"}, nil, nil)
+	var missing *MissingVariablesError
+	assert.True(t, errors.As(err, &missing))
+	assert.Nil(t, inputs)
+	assert.Equal(t, "missing values for variables without a default:\n  APPNAME: the app name", err.Error())
+
+	inputs, err = RunPromptsFromConfigWithSkipsIO(cfg, []string{"NAMESPACE", "APPNAME"}, nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"PORT": "80", "ENABLED": "false", "SERVICEPORT": "80"}, inputs)
+
+	_, _, err = RunSelect(&promptui.Select{Label: "Select Deployment Type", Items: []string{"helm"}})
+	assert.True(t, errors.Is(err, ErrNonInteractive))
+	_, err = RunPrompt(&promptui.Prompt{Label: "Please enter the app name"})
+	assert.True(t, errors.Is(err, ErrNonInteractive))
+}
+
+func TestRunPromptsReferencesProvidedValues(t *testing.T) {
+	cfg := &config.DraftConfig{
+		Variables: []config.BuilderVar{
+			{Name: "PORT", Description: "the port"},
+			{Name: "SERVICEPORT", Description: "the service port", Stage: config.StageAdvanced},
+		},
+		VariableDefaults: []config.BuilderVarDefault{
+			{Name: "SERVICEPORT", ReferenceVar: "PORT"},
+		},
+	}
+
+	// the skipped advanced variable defaults to the provided value
+	inputs, err := RunPromptsFromConfigWithValues(cfg, map[string]string{"PORT": "8080"})
+	assert.Nil(t, err)
+	assert.Equal(t, "8080", inputs["SERVICEPORT"])
+
+	// a reference to a variable without a value doesn't generate an empty value
+	cfg.Variables = cfg.Variables[1:]
+	_, err = RunPromptsFromConfigWithValues(cfg, map[string]string{})
+	assert.EqualError(t, err, "SERVICEPORT defaults to the value of PORT, which has none, pass it with --variable SERVICEPORT=value or answer it with --advanced")
+
+	SetNonInteractive(true)
+	defer SetNonInteractive(false)
+	_, err = RunPromptsFromConfigWithValues(cfg, map[string]string{})
+	var missing *MissingVariablesError
+	assert.True(t, errors.As(err, &missing))
+	assert.Equal(t, "SERVICEPORT", missing.Variables[0].Name)
+}
+
+func TestRunPromptsDoesNotLogSecrets(t *testing.T) {
+	SetNonInteractive(true)
+	defer SetNonInteractive(false)
+	var logs bytes.Buffer
+	output := log.StandardLogger().Out
+	log.SetOutput(&logs)
+	defer log.SetOutput(output)
+	level := log.GetLevel()
+	log.SetLevel(log.DebugLevel)
+	defer log.SetLevel(level)
+
+	cfg := &config.DraftConfig{
+		Variables: []config.BuilderVar{
+			{Name: "TOKEN", Description: "the token", VarType: "secret"},
+			{Name: "REGISTRYTOKEN", Description: "the registry token", VarType: "secret"},
+		},
+		VariableDefaults: []config.BuilderVarDefault{
+			{Name: "TOKEN", Value: "s3cr3t"},
+			{Name: "REGISTRYTOKEN", ReferenceVar: "TOKEN"},
+		},
+	}
+	inputs, err := RunPromptsFromConfigWithSkipsIO(cfg, nil, nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"TOKEN": "s3cr3t", "REGISTRYTOKEN": "s3cr3t"}, inputs)
+	assert.Contains(t, logs.String(), "REGISTRYTOKEN")
+	assert.NotContains(t, logs.String(), "s3cr3t")
+}
+
+func TestRunMultiSelectPrompt(t *testing.T) {
+	// the plain fallback prompts take the number of an option
+	t.Setenv("TERM", "dumb")
+	annotations := config.BuilderVar{
+		Name:          "INGRESSANNOTATIONS",
+		Description:   "the ingress annotations to enable",
+		VarType:       config.VarTypeMultiSelect,
+		AllowedValues: []string{"cors", "rewrite", "ssl-redirect"},
+	}
+
+	got, err := RunMultiSelectPrompt(annotations, "ssl-redirect", io.NopCloser(strings.NewReader("2\n4\n3\n\n")), nopWriteCloser{io.Discard})
+	assert.Nil(t, err)
+	assert.Equal(t, "cors,rewrite", got)
+
+	_, err = RunMultiSelectPrompt(annotations, "cors,hsts", nil, nil)
+	assert.ErrorContains(t, err, `invalid value "hsts" for INGRESSANNOTATIONS`)
+
+	draftConfig := &config.DraftConfig{
+		Variables:        []config.BuilderVar{annotations},
+		VariableDefaults: []config.BuilderVarDefault{{Name: "INGRESSANNOTATIONS", Value: "cors"}},
+	}
+	inputs, err := RunPromptsFromConfigWithSkipsIO(draftConfig, nil, io.NopCloser(strings.NewReader("2\n1\n")), nopWriteCloser{io.Discard})
+	assert.Nil(t, err)
+	assert.Equal(t, "", inputs["INGRESSANNOTATIONS"], "deselecting every option is kept over the default")
+}
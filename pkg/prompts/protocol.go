@@ -0,0 +1,225 @@
+package prompts
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+
+	"github.com/Azure/draft/pkg/config"
+)
+
+// Prompt protocols accepted by SetPromptProtocol
+const (
+	PromptProtocolTerminal = "terminal"
+	PromptProtocolJSONL    = "jsonl"
+)
+
+// Kinds of prompt requests
+const (
+	PromptKindSelect      = "select"
+	PromptKindMultiSelect = "multiselect"
+	PromptKindInput       = "input"
+	PromptKindConfirm     = "confirm"
+)
+
+// Types of the messages written in the jsonl protocol
+const (
+	MessageTypePrompt  = "prompt"
+	MessageTypeInvalid = "invalid"
+)
+
+// ProtocolMessage is written as a json line for each prompt, and again with the invalid type when its answer is
+// rejected, after which another answer for the same prompt is read
+type ProtocolMessage struct {
+	Type string `json:"type"`
+	ID   int    `json:"id"`
+	// Kind is select, multiselect, input or confirm. Multiselect prompts are answered with any number of the options
+	// joined by commas.
+	Kind  string `json:"kind,omitempty"`
+	Label string `json:"label,omitempty"`
+	// Variable is the template variable prompted for, if any
+	Variable string `json:"variable,omitempty"`
+	// Options are the values a select must be answered with
+	Options []string `json:"options,omitempty"`
+	// Default is used when the answer's value is empty
+	Default string `json:"default,omitempty"`
+	// Secret inputs should not be echoed by the client
+	Secret bool `json:"secret,omitempty"`
+	// Message is why the answer of an invalid message was rejected
+	Message string `json:"message,omitempty"`
+}
+
+// ProtocolAnswer is read as a json line for each prompt. Confirm prompts are accepted with a value of y, yes or true.
+type ProtocolAnswer struct {
+	ID    int    `json:"id"`
+	Value string `json:"value"`
+	// Cancel aborts the command like ctrl-c in a terminal
+	Cancel bool `json:"cancel,omitempty"`
+}
+
+type jsonlProtocol struct {
+	in     io.Reader
+	out    io.Writer
+	lastID int
+}
+
+var protocol *jsonlProtocol
+
+// currentVariable is the variable RunPromptsFromConfigWithSkipsIO is prompting for, reported in protocol messages
+var currentVariable string
+
+// SetPromptProtocol sets how RunSelect and RunPrompt ask for input. The terminal protocol, or an empty one, uses
+// promptui. The jsonl protocol writes each prompt as a ProtocolMessage line to out and reads a ProtocolAnswer line from
+// in, so IDEs and wrappers can drive prompts without a pseudo terminal.
+func SetPromptProtocol(name string, in io.Reader, out io.Writer) error {
+	switch name {
+	case "", PromptProtocolTerminal:
+		protocol = nil
+	case PromptProtocolJSONL:
+		protocol = &jsonlProtocol{in: in, out: out}
+	default:
+		return fmt.Errorf("invalid prompt protocol %q, must be %s or %s", name, PromptProtocolTerminal, PromptProtocolJSONL)
+	}
+	return nil
+}
+
+func (p *jsonlProtocol) runSelect(s *promptui.Select) (int, string, error) {
+	items, err := itemStrings(s.Items)
+	if err != nil {
+		return 0, "", err
+	}
+	if len(items) == 0 {
+		return 0, "", fmt.Errorf("no selection options")
+	}
+	defaultIndex := s.CursorPos
+	if defaultIndex < 0 || defaultIndex >= len(items) {
+		defaultIndex = 0
+	}
+
+	msg := ProtocolMessage{Kind: PromptKindSelect, Label: fmt.Sprint(s.Label), Options: items, Default: items[defaultIndex]}
+	var index int
+	err = p.ask(msg, func(value string) error {
+		if value == "" {
+			index = defaultIndex
+			return nil
+		}
+		for i, item := range items {
+			if strings.EqualFold(item, value) {
+				index = i
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not one of the options", value)
+	})
+	if err != nil {
+		return 0, "", err
+	}
+	return index, items[index], nil
+}
+
+func (p *jsonlProtocol) runMultiSelect(customPrompt config.BuilderVar, label, defaultValue string) (string, error) {
+	msg := ProtocolMessage{Kind: PromptKindMultiSelect, Label: label, Options: customPrompt.AllowedValues, Default: defaultValue}
+	var answer string
+	err := p.ask(msg, func(value string) error {
+		if value == "" {
+			value = defaultValue
+		}
+		if err := customPrompt.ValidateMultiSelectValue(value); err != nil {
+			return err
+		}
+		answer = strings.Join(config.SplitMultiSelectValue(value), config.MultiSelectSeparator)
+		return nil
+	})
+	return answer, err
+}
+
+func (p *jsonlProtocol) runPrompt(prompt *promptui.Prompt) (string, error) {
+	label := fmt.Sprint(prompt.Label)
+	if prompt.IsConfirm {
+		var answer string
+		if err := p.ask(ProtocolMessage{Kind: PromptKindConfirm, Label: label}, func(value string) error {
+			answer = value
+			return nil
+		}); err != nil {
+			return "", err
+		}
+		if a := strings.ToLower(answer); a == "y" || a == "yes" || a == "true" {
+			return answer, nil
+		}
+		return "", promptui.ErrAbort
+	}
+
+	msg := ProtocolMessage{Kind: PromptKindInput, Label: label, Default: prompt.Default, Secret: prompt.Mask != 0}
+	var answer string
+	err := p.ask(msg, func(value string) error {
+		if value == "" {
+			value = prompt.Default
+		}
+		if prompt.Validate != nil {
+			if err := prompt.Validate(value); err != nil {
+				return err
+			}
+		}
+		answer = value
+		return nil
+	})
+	return answer, err
+}
+
+// ask writes msg as a prompt and reads answers until accept takes one
+func (p *jsonlProtocol) ask(msg ProtocolMessage, accept func(value string) error) error {
+	p.lastID++
+	msg.Type = MessageTypePrompt
+	msg.ID = p.lastID
+	msg.Variable = currentVariable
+	if err := p.write(msg); err != nil {
+		return err
+	}
+
+	for {
+		line, err := readLine(p.in)
+		if err != nil {
+			return err
+		}
+		if line == "" {
+			continue
+		}
+
+		var answer ProtocolAnswer
+		if err := json.Unmarshal([]byte(line), &answer); err != nil {
+			err = fmt.Errorf("answer is not a json object: %w", err)
+			if writeErr := p.write(ProtocolMessage{Type: MessageTypeInvalid, ID: msg.ID, Message: err.Error()}); writeErr != nil {
+				return writeErr
+			}
+			continue
+		}
+		if answer.ID != msg.ID {
+			if err := p.write(ProtocolMessage{Type: MessageTypeInvalid, ID: msg.ID, Message: fmt.Sprintf("answer is for prompt %d, expected %d", answer.ID, msg.ID)}); err != nil {
+				return err
+			}
+			continue
+		}
+		if answer.Cancel {
+			return promptui.ErrInterrupt
+		}
+		if err := accept(answer.Value); err != nil {
+			if writeErr := p.write(ProtocolMessage{Type: MessageTypeInvalid, ID: msg.ID, Message: err.Error()}); writeErr != nil {
+				return writeErr
+			}
+			continue
+		}
+		return nil
+	}
+}
+
+func (p *jsonlProtocol) write(msg ProtocolMessage) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = p.out.Write(append(b, '\n'))
+	return err
+}
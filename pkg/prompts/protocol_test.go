@@ -0,0 +1,127 @@
+package prompts
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/manifoldco/promptui"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/config"
+)
+
+func readMessages(t *testing.T, out *bytes.Buffer) []ProtocolMessage {
+	var messages []ProtocolMessage
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		var msg ProtocolMessage
+		assert.Nil(t, json.Unmarshal(scanner.Bytes(), &msg), scanner.Text())
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+func TestJSONLProtocol(t *testing.T) {
+	in := strings.NewReader(strings.Join([]string{
+		`{"id":1,"value":"my-app"}`,
+		`not json`,
+		`{"id":2,"value":"maybe"}`,
+		`{"id":2,"value":"false"}`,
+		`{"id":3,"value":""}`,
+	}, "\n") + "\n")
+	out := &bytes.Buffer{}
+	assert.Nil(t, SetPromptProtocol(PromptProtocolJSONL, in, out))
+	defer SetPromptProtocol(PromptProtocolTerminal, nil, nil)
+
+	draftConfig := &config.DraftConfig{
+		Variables: []config.BuilderVar{
+			{Name: "APPNAME", Description: "the name of the application"},
+			{Name: "ENABLED", Description: "whether to enable it", VarType: "bool"},
+			{Name: "PORT", Description: "the port exposed in the application"},
+		},
+		VariableDefaults: []config.BuilderVarDefault{{Name: "PORT", Value: "80"}},
+	}
+	inputs, err := RunPromptsFromConfigWithSkipsIO(draftConfig, nil, nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"APPNAME": "my-app", "ENABLED": "false", "PORT": "80"}, inputs)
+
+	messages := readMessages(t, out)
+	assert.Len(t, messages, 5)
+	assert.Equal(t, ProtocolMessage{Type: MessageTypePrompt, ID: 1, Kind: PromptKindInput, Label: "Please enter the name of the application", Variable: "APPNAME"}, messages[0])
+	assert.Equal(t, MessageTypePrompt, messages[1].Type)
+	assert.Equal(t, PromptKindSelect, messages[1].Kind)
+	assert.Equal(t, []string{"true", "false"}, messages[1].Options)
+	assert.Equal(t, "ENABLED", messages[1].Variable)
+	assert.Equal(t, MessageTypeInvalid, messages[2].Type)
+	assert.Equal(t, 2, messages[2].ID)
+	assert.Contains(t, messages[3].Message, `"maybe" is not one of the options`)
+	assert.Equal(t, "PORT", messages[4].Variable)
+}
+
+func TestJSONLProtocolAnswers(t *testing.T) {
+	out := &bytes.Buffer{}
+	defer SetPromptProtocol(PromptProtocolTerminal, nil, nil)
+
+	assert.Nil(t, SetPromptProtocol(PromptProtocolJSONL, strings.NewReader(`{"id":1,"value":"yes"}`+"\n"+`{"id":2,"value":"no"}`+"\n"), out))
+	_, err := RunPrompt(&promptui.Prompt{Label: "Overwrite Dockerfile", IsConfirm: true})
+	assert.Nil(t, err)
+	_, err = RunPrompt(&promptui.Prompt{Label: "Overwrite Dockerfile", IsConfirm: true})
+	assert.True(t, errors.Is(err, promptui.ErrAbort))
+
+	out.Reset()
+	assert.Nil(t, SetPromptProtocol(PromptProtocolJSONL, strings.NewReader(`{"id":7,"value":""}`+"\n"+`{"id":1,"value":""}`+"\n"+`{"id":1,"value":"s3cret"}`+"\n"), out))
+	got, err := RunPrompt(&promptui.Prompt{Label: "Enter password", Mask: '*', Validate: NoBlankStringValidator})
+	assert.Nil(t, err)
+	assert.Equal(t, "s3cret", got)
+	messages := readMessages(t, out)
+	assert.True(t, messages[0].Secret)
+	assert.Contains(t, messages[1].Message, "answer is for prompt 7, expected 1")
+	assert.Contains(t, messages[2].Message, "input must be greater than 0")
+
+	assert.Nil(t, SetPromptProtocol(PromptProtocolJSONL, strings.NewReader(`{"id":1,"cancel":true}`+"\n"), out))
+	_, _, err = RunSelect(&promptui.Select{Label: "Select", Items: []string{"helm"}})
+	assert.True(t, errors.Is(err, promptui.ErrInterrupt))
+
+	assert.ErrorContains(t, SetPromptProtocol("xml", nil, nil), `invalid prompt protocol "xml"`)
+}
+
+func TestJSONLProtocolMultiSelect(t *testing.T) {
+	out := &bytes.Buffer{}
+	defer SetPromptProtocol(PromptProtocolTerminal, nil, nil)
+	assert.Nil(t, SetPromptProtocol(PromptProtocolJSONL, strings.NewReader(`{"id":1,"value":"cors,hsts"}`+"\n"+`{"id":1,"value":"rewrite, cors"}`+"\n"), out))
+
+	annotations := config.BuilderVar{Name: "INGRESSANNOTATIONS", Description: "the ingress annotations to enable", VarType: config.VarTypeMultiSelect, AllowedValues: []string{"cors", "rewrite"}}
+	got, err := RunMultiSelectPrompt(annotations, "cors", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "rewrite,cors", got)
+
+	messages := readMessages(t, out)
+	assert.Equal(t, PromptKindMultiSelect, messages[0].Kind)
+	assert.Equal(t, []string{"cors", "rewrite"}, messages[0].Options)
+	assert.Equal(t, "cors", messages[0].Default)
+	assert.Contains(t, messages[1].Message, `invalid value "hsts"`)
+}
+
+func TestJSONLProtocolNumericVariable(t *testing.T) {
+	in := strings.NewReader(strings.Join([]string{
+		`{"id":1,"value":"70000"}`,
+		`{"id":1,"value":"8080"}`,
+	}, "\n") + "\n")
+	out := &bytes.Buffer{}
+	assert.Nil(t, SetPromptProtocol(PromptProtocolJSONL, in, out))
+	defer SetPromptProtocol(PromptProtocolTerminal, nil, nil)
+
+	port := config.BuilderVar{Name: "PORT", Description: "the port exposed in the application", VarType: config.VarTypePort}
+	got, err := RunDefaultableStringPrompt(port, "80", nil, nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "8080", got)
+
+	messages := readMessages(t, out)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, MessageTypeInvalid, messages[1].Type)
+	assert.Equal(t, `invalid value "70000" for PORT, must be a port number from 1 to 65535`, messages[1].Message)
+}
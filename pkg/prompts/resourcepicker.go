@@ -0,0 +1,118 @@
+package prompts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/manifoldco/promptui"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Azure/draft/pkg/config"
+)
+
+// Resource types a template variable can name with `resource` in draft.yaml
+const (
+	ResourceContainerRegistry   = "containerRegistry"
+	ResourceContainerRepository = "containerRepository"
+	ResourceKubernetesCluster   = "kubernetesCluster"
+	ResourceGroup               = "resourceGroup"
+	ResourceLocation            = "location"
+)
+
+const manualEntryLabel = "Enter a different value"
+
+// ErrUnsupportedResourceType is returned by a ResourcePicker that cannot list a resource type
+var ErrUnsupportedResourceType = errors.New("unsupported resource type")
+
+// Option is a resource a variable can be set to
+type Option struct {
+	// Value is what the variable is set to when the option is selected
+	Value string `yaml:"value" json:"value"`
+	// Label is shown in the select prompt, defaulting to Value
+	Label string `yaml:"label,omitempty" json:"label,omitempty"`
+}
+
+func (o Option) String() string {
+	if o.Label != "" {
+		return o.Label
+	}
+	return o.Value
+}
+
+// ResourcePicker lists the existing resources of a type, such as the container registries of a cloud account, so
+// variables naming them can be selected instead of typed
+type ResourcePicker interface {
+	List(ctx context.Context, resourceType string) ([]Option, error)
+}
+
+var resourcePicker ResourcePicker
+
+// SetResourcePicker sets the picker used to prompt for variables with a resource type, nil disables resource prompts
+func SetResourcePicker(picker ResourcePicker) {
+	resourcePicker = picker
+}
+
+// StaticPicker is a ResourcePicker with a fixed list of options per resource type
+type StaticPicker map[string][]Option
+
+func (s StaticPicker) List(_ context.Context, resourceType string) ([]Option, error) {
+	options, ok := s[resourceType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedResourceType, resourceType)
+	}
+	return options, nil
+}
+
+// LoadStaticPicker reads a StaticPicker from a yaml or json file mapping resource types to lists of options
+func LoadStaticPicker(path string) (StaticPicker, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading resource file: %w", err)
+	}
+	picker := StaticPicker{}
+	if err := yaml.Unmarshal(data, &picker); err != nil {
+		return nil, fmt.Errorf("parsing resource file %s: %w", path, err)
+	}
+	return picker, nil
+}
+
+// PromptByResource prompts for customPrompt by selecting from the resources picker lists for its resource type. It
+// falls back to a string prompt when nothing can be listed or the user chooses to enter a different value.
+func PromptByResource(ctx context.Context, picker ResourcePicker, customPrompt config.BuilderVar, defaultValue string, Stdin io.ReadCloser, Stdout io.WriteCloser) (string, error) {
+	options, err := picker.List(ctx, customPrompt.Resource)
+	if err != nil {
+		log.Warnf("unable to list %s resources for %s, enter the value instead: %s", customPrompt.Resource, customPrompt.Name, err)
+	}
+	if len(options) == 0 {
+		return RunDefaultableStringPrompt(customPrompt, defaultValue, nil, Stdin, Stdout)
+	}
+
+	items := make([]string, 0, len(options)+1)
+	cursorPos := 0
+	for i, option := range options {
+		items = append(items, option.String())
+		if option.Value == defaultValue {
+			cursorPos = i
+		}
+	}
+	items = append(items, manualEntryLabel)
+
+	i, _, err := RunSelect(&promptui.Select{
+		Label:     "Please select " + customPrompt.Description,
+		Items:     items,
+		CursorPos: cursorPos,
+		Stdin:     Stdin,
+		Stdout:    Stdout,
+	})
+	if err != nil {
+		return "", err
+	}
+	if i == len(options) {
+		return RunDefaultableStringPrompt(customPrompt, defaultValue, nil, Stdin, Stdout)
+	}
+	return options[i].Value, nil
+}
@@ -0,0 +1,70 @@
+package prompts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/config"
+)
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestLoadStaticPicker(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resources.yaml")
+	assert.Nil(t, os.WriteFile(path, []byte(`containerRegistry:
+  - value: myregistry
+    label: myregistry (my-rg)
+kubernetesCluster:
+  - value: mycluster
+`), 0644))
+
+	picker, err := LoadStaticPicker(path)
+	assert.Nil(t, err)
+
+	options, err := picker.List(context.Background(), ResourceContainerRegistry)
+	assert.Nil(t, err)
+	assert.Equal(t, []Option{{Value: "myregistry", Label: "myregistry (my-rg)"}}, options)
+
+	_, err = picker.List(context.Background(), ResourceGroup)
+	assert.True(t, errors.Is(err, ErrUnsupportedResourceType))
+
+	_, err = LoadStaticPicker(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.NotNil(t, err)
+}
+
+func TestPromptByResource(t *testing.T) {
+	t.Setenv("TERM", "dumb")
+	picker := StaticPicker{ResourceContainerRegistry: {{Value: "first"}, {Value: "second", Label: "second (rg)"}}}
+	variable := config.BuilderVar{Name: "AZURECONTAINERREGISTRY", Description: "the Azure container registry name", Resource: ResourceContainerRegistry}
+	out := nopWriteCloser{io.Discard}
+
+	got, err := PromptByResource(context.Background(), picker, variable, "", io.NopCloser(strings.NewReader("2\n")), out)
+	assert.Nil(t, err)
+	assert.Equal(t, "second", got)
+
+	// the default resource is preselected
+	got, err = PromptByResource(context.Background(), picker, variable, "second", io.NopCloser(strings.NewReader("\n")), out)
+	assert.Nil(t, err)
+	assert.Equal(t, "second", got)
+
+	got, err = PromptByResource(context.Background(), picker, variable, "", io.NopCloser(strings.NewReader("3\nother\n")), out)
+	assert.Nil(t, err)
+	assert.Equal(t, "other", got)
+
+	// resource types the picker can't list fall back to a string prompt
+	variable.Resource = ResourceKubernetesCluster
+	got, err = PromptByResource(context.Background(), picker, variable, "", io.NopCloser(strings.NewReader("mycluster\n")), out)
+	assert.Nil(t, err)
+	assert.Equal(t, "mycluster", got)
+}
@@ -0,0 +1,79 @@
+package prompts
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/manifoldco/promptui"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/config"
+)
+
+// maxValidationFailures is the number of times an answer is rejected before skipping its validation is offered
+const maxValidationFailures = 3
+
+var variableValidators map[string]func(string) error
+
+// SetVariableValidators sets the validators the answers to the prompts of the variables they're keyed by are checked
+// with as soon as they're entered, so that a rejected answer is asked again instead of failing the command once every
+// variable is answered
+func SetVariableValidators(validators map[string]func(string) error) {
+	variableValidators = validators
+}
+
+// validateAnswer checks input against the rules customPrompt declares in its draft.yaml and with the validator set
+// for it, asking again with the error and the rejected answer filled in for editing until it's accepted. After
+// maxValidationFailures rejections by the validator the user is also offered to keep the answer without validation;
+// answers breaking the declared rules would fail the render, so they're always asked again. Empty answers, which take
+// the variable's default, aren't checked.
+func validateAnswer(customPrompt config.BuilderVar, input string, Stdin io.ReadCloser, Stdout io.WriteCloser) (string, error) {
+	validate := variableValidators[customPrompt.Name]
+
+	for failures := 1; ; failures++ {
+		if input == "" {
+			return input, nil
+		}
+		validationErr := customPrompt.ValidateValue(input)
+		skippable := false
+		if validationErr == nil && validate != nil {
+			validationErr = validate(input)
+			skippable = true
+		}
+		if validationErr == nil {
+			return input, nil
+		}
+		log.Error(validationErr)
+
+		if skippable && failures >= maxValidationFailures {
+			skip, err := confirmSkipValidation(customPrompt, input, failures, Stdin, Stdout)
+			if err != nil {
+				return "", err
+			}
+			if skip {
+				log.Warnf("using %q for %s without validation: %s", input, customPrompt.Name, validationErr)
+				return input, nil
+			}
+		}
+
+		var err error
+		input, err = runDefaultableStringPrompt(customPrompt, "", input, nil, Stdin, Stdout)
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// confirmSkipValidation asks whether to keep the input for customPrompt after it was rejected failures times
+func confirmSkipValidation(customPrompt config.BuilderVar, input string, failures int, Stdin io.ReadCloser, Stdout io.WriteCloser) (bool, error) {
+	_, selectResponse, err := RunSelect(&promptui.Select{
+		Label:  fmt.Sprintf("%q was rejected %d times, would you like to use it for %s without validation?", input, failures, customPrompt.Name),
+		Items:  []string{"no", "yes"},
+		Stdin:  Stdin,
+		Stdout: Stdout,
+	})
+	if err != nil {
+		return false, err
+	}
+	return selectResponse == "yes", nil
+}
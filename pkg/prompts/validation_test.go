@@ -0,0 +1,68 @@
+package prompts
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/config"
+)
+
+func TestRunPromptsWithValidators(t *testing.T) {
+	t.Setenv("TERM", "dumb")
+	SetVariableValidators(map[string]func(string) error{"HOST": func(s string) error {
+		if strings.Contains(s, "_") {
+			return errors.New("hosts can't contain underscores")
+		}
+		return nil
+	}})
+	defer SetVariableValidators(nil)
+
+	cfg := &config.DraftConfig{Variables: []config.BuilderVar{
+		{Name: "HOST", Description: "the host"},
+		{Name: "APPNAME", Description: "the app name"},
+	}}
+	run := func(answers string) (map[string]string, error) {
+		return RunPromptsFromConfigWithSkipsIO(cfg, nil, io.NopCloser(strings.NewReader(answers)), nopWriteCloser{io.Discard})
+	}
+
+	// the rejected host is asked again instead of failing, without asking the remaining prompts twice
+	inputs, err := run("my_host\nmy-host\nmy_app\n")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"HOST": "my-host", "APPNAME": "my_app"}, inputs)
+
+	// the rejected answer is filled in, and keeping it after the third rejection is offered
+	inputs, err = run("my_host\n\n\n2\napp\n")
+	assert.Nil(t, err)
+	assert.Equal(t, "my_host", inputs["HOST"])
+
+	inputs, err = run("my_host\n\n\n1\nmy-host\napp\n")
+	assert.Nil(t, err)
+	assert.Equal(t, "my-host", inputs["HOST"])
+
+	_, err = run("my_host\n")
+	assert.NotNil(t, err)
+}
+
+func TestRunPromptsWithDeclaredRules(t *testing.T) {
+	t.Setenv("TERM", "dumb")
+	maxLength := 8
+	cfg := &config.DraftConfig{Variables: []config.BuilderVar{
+		{Name: "APPNAME", Description: "the app name", Pattern: "[a-z][a-z0-9-]*", MaxLength: &maxLength},
+		{Name: "TIER", Description: "the tier", AllowedValues: []string{"web", "worker"}},
+	}}
+	run := func(answers string) (map[string]string, error) {
+		return RunPromptsFromConfigWithSkipsIO(cfg, nil, io.NopCloser(strings.NewReader(answers)), nopWriteCloser{io.Discard})
+	}
+
+	inputs, err := run("My_App\nmy-application\nmy-app\nbatch\nworker\n")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"APPNAME": "my-app", "TIER": "worker"}, inputs)
+
+	// answers breaking the declared rules aren't offered to skip validation, they would fail the render
+	_, err = run("My_App\n\n\n\n")
+	assert.NotNil(t, err)
+}
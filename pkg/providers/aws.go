@@ -0,0 +1,88 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	awsRegionRegex      = regexp.MustCompile(`^[a-z]{2}(-gov|-iso[a-z]?)?-[a-z]+-[0-9]+$`)
+	awsRoleArnRegex     = regexp.MustCompile(`^arn:aws(-[a-z]+)*:iam::[0-9]{12}:role/[\w+=,.@/-]{1,512}$`)
+	ecrRegistryRegex    = regexp.MustCompile(`^[0-9]{12}\.dkr\.ecr(-fips)?\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+	ecrRepositoryRegex  = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*(/[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*)*$`)
+	eksClusterNameRegex = regexp.MustCompile(`^[0-9A-Za-z][A-Za-z0-9_-]{0,99}$`)
+)
+
+// awsRun runs the aws commands of the validators checking that resources exist
+var awsRun commandRunner = runCommand
+
+// ValidateAwsRegion checks that region is an AWS region code such as us-east-1, without looking it up
+func ValidateAwsRegion(region string) error {
+	if !awsRegionRegex.MatchString(region) {
+		return fmt.Errorf("invalid AWS region %q, must be a region code such as us-east-1", region)
+	}
+	return nil
+}
+
+// ValidateAwsRoleArn checks that arn is the ARN of an IAM role, such as arn:aws:iam::123456789012:role/deploy, without
+// looking it up
+func ValidateAwsRoleArn(arn string) error {
+	if !awsRoleArnRegex.MatchString(arn) {
+		return fmt.Errorf("invalid IAM role ARN %q, must be like arn:aws:iam::123456789012:role/NAME", arn)
+	}
+	return nil
+}
+
+// ValidateEcrRegistry checks that registry is the host of an Amazon ECR registry, such as
+// 123456789012.dkr.ecr.us-east-1.amazonaws.com, without looking it up
+func ValidateEcrRegistry(registry string) error {
+	if !ecrRegistryRegex.MatchString(registry) {
+		return fmt.Errorf("invalid ECR registry %q, must be like 123456789012.dkr.ecr.us-east-1.amazonaws.com", registry)
+	}
+	return nil
+}
+
+// ValidateEcrRepository checks repository against the ECR repository naming rules and that it exists in the registry
+// of the configured aws cli profile and region. Its existence isn't checked Offline or when aws can't tell, such as when
+// it isn't installed or signed in.
+func ValidateEcrRepository(repository string) error {
+	if len(repository) < 2 || len(repository) > 256 || !ecrRepositoryRegex.MatchString(repository) {
+		return errors.New("ECR repository names are 2-256 lowercase letters and digits separated by ., _, __, - or /")
+	}
+	if offline {
+		return nil
+	}
+	_, err := awsRun(context.Background(), "aws", "ecr", "describe-repositories", "--repository-names", repository, "--output", "json")
+	if err != nil && strings.Contains(err.Error(), "RepositoryNotFoundException") {
+		return fmt.Errorf("ECR repository %s doesn't exist in the current account and region", repository)
+	}
+	if err != nil {
+		log.Debugf("not checking that ECR repository %s exists: %s", repository, err)
+	}
+	return nil
+}
+
+// ValidateEksClusterName checks clusterName against the EKS cluster naming rules and that a cluster of that name
+// exists in the configured aws cli profile and region. Its existence isn't checked Offline or when aws can't tell,
+// such as when it isn't installed or signed in.
+func ValidateEksClusterName(clusterName string) error {
+	if !eksClusterNameRegex.MatchString(clusterName) {
+		return errors.New("EKS cluster names are 1-100 letters, digits, underscores and hyphens and start with a letter or digit")
+	}
+	if offline {
+		return nil
+	}
+	_, err := awsRun(context.Background(), "aws", "eks", "describe-cluster", "--name", clusterName, "--output", "json")
+	if err != nil && strings.Contains(err.Error(), "ResourceNotFoundException") {
+		return fmt.Errorf("EKS cluster %s doesn't exist in the current account and region", clusterName)
+	}
+	if err != nil {
+		log.Debugf("not checking that EKS cluster %s exists: %s", clusterName, err)
+	}
+	return nil
+}
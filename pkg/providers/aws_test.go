@@ -0,0 +1,51 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAwsValidators(t *testing.T) {
+	assert.Nil(t, ValidateAwsRegion("us-east-1"))
+	assert.Nil(t, ValidateAwsRegion("us-gov-west-1"))
+	assert.NotNil(t, ValidateAwsRegion("US East"))
+
+	assert.Nil(t, ValidateAwsRoleArn("arn:aws:iam::123456789012:role/deploy"))
+	assert.Nil(t, ValidateAwsRoleArn("arn:aws-cn:iam::123456789012:role/ci/deploy"))
+	assert.NotNil(t, ValidateAwsRoleArn("arn:aws:iam::123456789012:user/deploy"))
+	assert.NotNil(t, ValidateAwsRoleArn("deploy"))
+
+	assert.Nil(t, ValidateEcrRegistry("123456789012.dkr.ecr.us-east-1.amazonaws.com"))
+	assert.NotNil(t, ValidateEcrRegistry("123456789012.dkr.ecr.us-east-1.amazonaws.com/app"))
+	assert.NotNil(t, ValidateEcrRegistry("myregistry.azurecr.io"))
+}
+
+func TestAwsResourceValidators(t *testing.T) {
+	oldRun := awsRun
+	t.Cleanup(func() { awsRun = oldRun })
+	awsRun = func(_ context.Context, _ string, args ...string) ([]byte, error) {
+		switch args[len(args)-3] {
+		case "team/web", "prod":
+			return []byte("{}"), nil
+		case "missing-repo":
+			return nil, errors.New("An error occurred (RepositoryNotFoundException) when calling the DescribeRepositories operation")
+		case "missing-cluster":
+			return nil, errors.New("An error occurred (ResourceNotFoundException) when calling the DescribeCluster operation")
+		}
+		return nil, errors.New("Unable to locate credentials")
+	}
+
+	assert.Nil(t, ValidateEcrRepository("team/web"))
+	assert.EqualError(t, ValidateEcrRepository("missing-repo"), "ECR repository missing-repo doesn't exist in the current account and region")
+	assert.Nil(t, ValidateEcrRepository("signed-out"))
+	assert.NotNil(t, ValidateEcrRepository("Web"))
+	assert.NotNil(t, ValidateEcrRepository("web-"))
+
+	assert.Nil(t, ValidateEksClusterName("prod"))
+	assert.EqualError(t, ValidateEksClusterName("missing-cluster"), "EKS cluster missing-cluster doesn't exist in the current account and region")
+	assert.Nil(t, ValidateEksClusterName("signed-out"))
+	assert.NotNil(t, ValidateEksClusterName("-prod"))
+}
@@ -26,12 +26,29 @@ type SetUpCmd struct {
 	appObjectId       string
 	spObjectId        string
 	AzClient          AzClient
+
+	// Location is the region the resource group is created in when CreateResourceGroup is set
+	Location            string
+	CreateResourceGroup bool
+
+	// ProjectID is the Google Cloud project of the service account named AppName, with the gcp provider
+	ProjectID string
+
+	// SCM is the source control host whose CI jobs the Azure AD application trusts, github when empty. Repo is a GitLab
+	// project with gitlab and an Azure DevOps organization/project with azdo.
+	SCM string
+	// Branch is the branch whose pipelines are trusted, and GitLabURL the instance issuing their tokens, with gitlab
+	Branch    string
+	GitLabURL string
+	// ServiceConnection is the service connection whose pipelines are trusted, with azdo
+	ServiceConnection  string
+	azdoOrganizationId string
 }
 
 func InitiateAzureOIDCFlow(ctx context.Context, sc *SetUpCmd, s spinner.Spinner) error {
-	log.Debug("Commencing github connection with azure...")
+	log.Debugf("Commencing %s connection with azure...", sc.scm())
 
-	if !HasGhCli() || !IsLoggedInToGh() {
+	if sc.scm() == SCMGitHub && (!HasGhCli() || !IsLoggedInToGh()) {
 		s.Stop()
 		if err := LogInToGh(); err != nil {
 			return err
@@ -39,10 +56,22 @@ func InitiateAzureOIDCFlow(ctx context.Context, sc *SetUpCmd, s spinner.Spinner)
 		s.Start()
 	}
 
+	if sc.CreateResourceGroup {
+		if err := CreateAzResourceGroup(sc.SubscriptionID, sc.ResourceGroupName, sc.Location); err != nil {
+			return err
+		}
+	}
+
 	if err := sc.ValidateSetUpConfig(); err != nil {
 		return err
 	}
 
+	if sc.scm() == SCMAzureDevOps {
+		if err := sc.getAzdoOrganizationId(ctx); err != nil {
+			return err
+		}
+	}
+
 	if AzAppExists(sc.AppName) {
 		return errors.New("app already exists")
 	} else if err := sc.createAzApp(); err != nil {
@@ -71,17 +100,20 @@ func InitiateAzureOIDCFlow(ctx context.Context, sc *SetUpCmd, s spinner.Spinner)
 		}
 	}
 
-	if err := sc.setAzClientId(); err != nil {
-		return err
-	}
-	if err := sc.setAzSubscriptionId(); err != nil {
-		return err
-	}
-	if err := sc.setAzTenantId(); err != nil {
-		return err
+	// only the secrets of github are set, those of the other SCMs are printed from OIDCSettings
+	if sc.scm() == SCMGitHub {
+		if err := sc.setAzClientId(); err != nil {
+			return err
+		}
+		if err := sc.setAzSubscriptionId(); err != nil {
+			return err
+		}
+		if err := sc.setAzTenantId(); err != nil {
+			return err
+		}
 	}
 
-	log.Debug("Github connection with azure completed successfully!")
+	log.Debugf("%s connection with azure completed successfully!", sc.scm())
 	return nil
 }
 
@@ -239,7 +271,7 @@ func (sc *SetUpCmd) ValidateSetUpConfig() error {
 		return errors.New("invalid app name")
 	}
 
-	if err := isValidGhRepo(sc.Repo); err != nil {
+	if err := sc.validateRepo(); err != nil {
 		return err
 	}
 
@@ -275,16 +307,14 @@ func (sc *SetUpCmd) hasFederatedCredentials() bool {
 
 func (sc *SetUpCmd) createFederatedCredentials() error {
 	log.Debug("Creating federated credentials...")
-	fics := &[]string{
-		`{"name":"prfic","subject":"repo:%s:pull_request","issuer":"https://token.actions.githubusercontent.com","description":"pr","audiences":["api://AzureADTokenExchange"]}`,
-		`{"name":"mainfic","subject":"repo:%s:ref:refs/heads/main","issuer":"https://token.actions.githubusercontent.com","description":"main","audiences":["api://AzureADTokenExchange"]}`,
-		`{"name":"masterfic","subject":"repo:%s:ref:refs/heads/master","issuer":"https://token.actions.githubusercontent.com","description":"master","audiences":["api://AzureADTokenExchange"]}`,
-	}
-
-	uri := "https://graph.microsoft.com/beta/applications/%s/federatedIdentityCredentials"
+	uri := fmt.Sprintf("https://graph.microsoft.com/beta/applications/%s/federatedIdentityCredentials", sc.appObjectId)
 
-	for _, fic := range *fics {
-		createFicCmd := exec.Command("az", "rest", "--method", "POST", "--uri", fmt.Sprintf(uri, sc.appObjectId), "--body", fmt.Sprintf(fic, sc.Repo))
+	for _, fic := range sc.federatedCredentials() {
+		body, err := json.Marshal(fic)
+		if err != nil {
+			return err
+		}
+		createFicCmd := exec.Command("az", "rest", "--method", "POST", "--uri", uri, "--body", string(body))
 		out, err := createFicCmd.CombinedOutput()
 		if err != nil {
 			log.Printf("%s\n", out)
@@ -0,0 +1,305 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/subscription/armsubscription"
+
+	"github.com/Azure/draft/pkg/cred"
+	"github.com/Azure/draft/pkg/netconfig"
+)
+
+// SubscriptionIdEnv is the environment variable holding the subscription whose resources are looked up, the default
+// subscription of the az cli profile otherwise
+const SubscriptionIdEnv = "AZURE_SUBSCRIPTION_ID"
+
+// AzureResource is an Azure resource of a subscription
+type AzureResource struct {
+	Name          string
+	ResourceGroup string
+	Location      string
+}
+
+// AzureResources looks up and creates the Azure resources of a subscription, and looks up the subscriptions
+// themselves. It covers the Azure Resource Manager calls of draft; the Microsoft Entra application, service principal,
+// federated credential and role assignment created by setup-oidc, and the az cli version and login checks around
+// them, still go through the az cli.
+type AzureResources interface {
+	ResourceGroups(ctx context.Context) ([]AzureResource, error)
+	ResourceGroupExists(ctx context.Context, name string) (bool, error)
+	CreateResourceGroup(ctx context.Context, name, location string) error
+	ContainerRegistries(ctx context.Context) ([]AzureResource, error)
+	KubernetesClusters(ctx context.Context) ([]AzureResource, error)
+	Locations(ctx context.Context) ([]AzLocation, error)
+	// Subscription returns the subscription the AzureResources belong to
+	Subscription(ctx context.Context) (SubLabel, error)
+	// Subscriptions returns all the subscriptions the credential can access
+	Subscriptions(ctx context.Context) ([]SubLabel, error)
+}
+
+// newAzureResources returns the AzureResources of a subscription, or of the DefaultSubscriptionId when it's empty
+var newAzureResources = NewAzureResources
+
+// NewAzureResources returns the AzureResources of subscriptionId, or of the DefaultSubscriptionId when it's empty,
+// using the Azure SDK with the credentials of the environment, a managed identity or the az cli
+func NewAzureResources(subscriptionId string) (AzureResources, error) {
+	if subscriptionId == "" {
+		var err error
+		if subscriptionId, err = DefaultSubscriptionId(); err != nil {
+			return nil, err
+		}
+	}
+	credential, err := cred.GetCred()
+	if err != nil {
+		return nil, err
+	}
+	return &armResources{
+		subscriptionId: subscriptionId,
+		credential:     credential,
+		options:        &arm.ClientOptions{ClientOptions: azcore.ClientOptions{Transport: netconfig.HTTPClient()}},
+	}, nil
+}
+
+// DefaultSubscriptionId returns the subscription in $AZURE_SUBSCRIPTION_ID, or the default subscription of the az cli
+// profile, which az account set selects
+func DefaultSubscriptionId() (string, error) {
+	if id := os.Getenv(SubscriptionIdEnv); id != "" {
+		return id, nil
+	}
+
+	configDir := os.Getenv("AZURE_CONFIG_DIR")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configDir = filepath.Join(home, ".azure")
+	}
+	content, err := os.ReadFile(filepath.Join(configDir, "azureProfile.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("no Azure subscription selected, set %s or select one with az account set", SubscriptionIdEnv)
+	}
+	if err != nil {
+		return "", err
+	}
+	var profile struct {
+		Subscriptions []struct {
+			Id        string `json:"id"`
+			IsDefault bool   `json:"isDefault"`
+		} `json:"subscriptions"`
+	}
+	// the az cli writes its profile with a byte order mark
+	if err := json.Unmarshal(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")), &profile); err != nil {
+		return "", fmt.Errorf("reading the az cli profile: %w", err)
+	}
+	for _, subscription := range profile.Subscriptions {
+		if subscription.IsDefault {
+			return subscription.Id, nil
+		}
+	}
+	return "", fmt.Errorf("no Azure subscription selected, set %s or select one with az account set", SubscriptionIdEnv)
+}
+
+// armResources are the AzureResources of a subscription, looked up with the Azure SDK
+type armResources struct {
+	subscriptionId string
+	credential     azcore.TokenCredential
+	options        *arm.ClientOptions
+}
+
+func (a *armResources) ResourceGroups(ctx context.Context) ([]AzureResource, error) {
+	client, err := armresources.NewResourceGroupsClient(a.subscriptionId, a.credential, a.options)
+	if err != nil {
+		return nil, err
+	}
+	var groups []AzureResource
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing the resource groups of subscription %s: %w", a.subscriptionId, err)
+		}
+		for _, group := range page.Value {
+			groups = append(groups, AzureResource{Name: stringValue(group.Name), ResourceGroup: stringValue(group.Name), Location: stringValue(group.Location)})
+		}
+	}
+	return sortedResources(groups), nil
+}
+
+func (a *armResources) ResourceGroupExists(ctx context.Context, name string) (bool, error) {
+	client, err := armresources.NewResourceGroupsClient(a.subscriptionId, a.credential, a.options)
+	if err != nil {
+		return false, err
+	}
+	resp, err := client.CheckExistence(ctx, name, nil)
+	if err != nil {
+		return false, fmt.Errorf("looking up resource group %s: %w", name, err)
+	}
+	return resp.Success, nil
+}
+
+func (a *armResources) CreateResourceGroup(ctx context.Context, name, location string) error {
+	client, err := armresources.NewResourceGroupsClient(a.subscriptionId, a.credential, a.options)
+	if err != nil {
+		return err
+	}
+	if _, err := client.CreateOrUpdate(ctx, name, armresources.ResourceGroup{Location: to.Ptr(location)}, nil); err != nil {
+		return fmt.Errorf("creating resource group %q in %q: %w", name, location, err)
+	}
+	return nil
+}
+
+func (a *armResources) ContainerRegistries(ctx context.Context) ([]AzureResource, error) {
+	client, err := armcontainerregistry.NewRegistriesClient(a.subscriptionId, a.credential, a.options)
+	if err != nil {
+		return nil, err
+	}
+	var registries []AzureResource
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing the container registries of subscription %s: %w", a.subscriptionId, err)
+		}
+		for _, registry := range page.Value {
+			registries = append(registries, newAzureResource(registry.ID, registry.Name, registry.Location))
+		}
+	}
+	return sortedResources(registries), nil
+}
+
+func (a *armResources) KubernetesClusters(ctx context.Context) ([]AzureResource, error) {
+	client, err := armcontainerservice.NewManagedClustersClient(a.subscriptionId, a.credential, a.options)
+	if err != nil {
+		return nil, err
+	}
+	var clusters []AzureResource
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing the AKS clusters of subscription %s: %w", a.subscriptionId, err)
+		}
+		for _, cluster := range page.Value {
+			clusters = append(clusters, newAzureResource(cluster.ID, cluster.Name, cluster.Location))
+		}
+	}
+	return sortedResources(clusters), nil
+}
+
+func (a *armResources) Locations(ctx context.Context) ([]AzLocation, error) {
+	client, err := armsubscription.NewSubscriptionsClient(a.credential, a.options)
+	if err != nil {
+		return nil, err
+	}
+	var locations []AzLocation
+	pager := client.NewListLocationsPager(a.subscriptionId, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing locations for subscription %q: %w", a.subscriptionId, err)
+		}
+		for _, location := range page.Value {
+			// the logical regions, such as europe, have no coordinates and can't hold resources
+			if location.Latitude == nil {
+				continue
+			}
+			locations = append(locations, AzLocation{Name: stringValue(location.Name), DisplayName: stringValue(location.DisplayName)})
+		}
+	}
+	return locations, nil
+}
+
+func (a *armResources) Subscription(ctx context.Context) (SubLabel, error) {
+	client, err := armsubscription.NewSubscriptionsClient(a.credential, a.options)
+	if err != nil {
+		return SubLabel{}, err
+	}
+	resp, err := client.Get(ctx, a.subscriptionId, nil)
+	if err != nil {
+		return SubLabel{}, fmt.Errorf("looking up subscription %q: %w", a.subscriptionId, err)
+	}
+	return SubLabel{ID: stringValue(resp.SubscriptionID), Name: stringValue(resp.DisplayName)}, nil
+}
+
+func (a *armResources) Subscriptions(ctx context.Context) ([]SubLabel, error) {
+	client, err := armsubscription.NewSubscriptionsClient(a.credential, a.options)
+	if err != nil {
+		return nil, err
+	}
+	var subscriptions []SubLabel
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing subscriptions: %w", err)
+		}
+		for _, subscription := range page.Value {
+			subscriptions = append(subscriptions, SubLabel{ID: stringValue(subscription.SubscriptionID), Name: stringValue(subscription.DisplayName)})
+		}
+	}
+	return subscriptions, nil
+}
+
+// azResourceGroupExists returns whether resourceGroup exists in the subscription, the default one when it's empty
+func azResourceGroupExists(ctx context.Context, subscriptionId, resourceGroup string) (bool, error) {
+	resources, err := newAzureResources(subscriptionId)
+	if err != nil {
+		return false, err
+	}
+	return resources.ResourceGroupExists(ctx, resourceGroup)
+}
+
+// listAzResources returns the resources list lists in the subscription, the default one when it's empty
+func listAzResources(ctx context.Context, subscriptionId string, list func(AzureResources, context.Context) ([]AzureResource, error)) ([]AzureResource, error) {
+	resources, err := newAzureResources(subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+	return list(resources, ctx)
+}
+
+// hasAzResource returns whether resources has one called name, in resourceGroup unless it's empty
+func hasAzResource(resources []AzureResource, name, resourceGroup string) bool {
+	for _, resource := range resources {
+		if strings.EqualFold(resource.Name, name) && (resourceGroup == "" || strings.EqualFold(resource.ResourceGroup, resourceGroup)) {
+			return true
+		}
+	}
+	return false
+}
+
+// newAzureResource returns the AzureResource of the resource id, in the resource group the id names
+func newAzureResource(id, name, location *string) AzureResource {
+	resource := AzureResource{Name: stringValue(name), Location: stringValue(location)}
+	if parsed, err := arm.ParseResourceID(stringValue(id)); err == nil {
+		resource.ResourceGroup = parsed.ResourceGroupName
+	}
+	return resource
+}
+
+func sortedResources(resources []AzureResource) []AzureResource {
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Name < resources[j].Name })
+	return resources
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,137 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAzureResources are the AzureResources of a subscription holding fixed resources
+type fakeAzureResources struct {
+	groups     []AzureResource
+	registries []AzureResource
+	clusters   []AzureResource
+	locations  []AzLocation
+	subs       []SubLabel
+	err        error
+}
+
+func (f *fakeAzureResources) ResourceGroups(context.Context) ([]AzureResource, error) {
+	return f.groups, f.err
+}
+
+func (f *fakeAzureResources) ResourceGroupExists(_ context.Context, name string) (bool, error) {
+	return hasAzResource(f.groups, name, ""), f.err
+}
+
+func (f *fakeAzureResources) CreateResourceGroup(_ context.Context, name, location string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.groups = append(f.groups, AzureResource{Name: name, ResourceGroup: name, Location: location})
+	return nil
+}
+
+func (f *fakeAzureResources) ContainerRegistries(context.Context) ([]AzureResource, error) {
+	return f.registries, f.err
+}
+
+func (f *fakeAzureResources) KubernetesClusters(context.Context) ([]AzureResource, error) {
+	return f.clusters, f.err
+}
+
+func (f *fakeAzureResources) Locations(context.Context) ([]AzLocation, error) {
+	return f.locations, f.err
+}
+
+func (f *fakeAzureResources) Subscription(context.Context) (SubLabel, error) {
+	if len(f.subs) == 0 {
+		return SubLabel{}, f.err
+	}
+	return f.subs[0], f.err
+}
+
+func (f *fakeAzureResources) Subscriptions(context.Context) ([]SubLabel, error) {
+	return f.subs, f.err
+}
+
+// useFakeAzureResources makes the lookups of the test use resources
+func useFakeAzureResources(t *testing.T, resources AzureResources) {
+	t.Helper()
+	original := newAzureResources
+	newAzureResources = func(string) (AzureResources, error) { return resources, nil }
+	t.Cleanup(func() { newAzureResources = original })
+}
+
+func TestAzureResourceLookups(t *testing.T) {
+	resources := &fakeAzureResources{
+		groups:     []AzureResource{{Name: "my-rg", ResourceGroup: "my-rg", Location: "westus2"}},
+		registries: []AzureResource{{Name: "myregistry", ResourceGroup: "my-rg"}},
+		clusters:   []AzureResource{{Name: "my-cluster", ResourceGroup: "my-rg"}},
+		locations:  []AzLocation{{Name: "westus2", DisplayName: "West US 2"}},
+		subs:       []SubLabel{{ID: "sub", Name: "my subscription"}},
+	}
+	useFakeAzureResources(t, resources)
+
+	exists, err := AzResourceGroupExists("sub", "my-rg")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+	exists, err = AzResourceGroupExists("sub", "other-rg")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+	assert.True(t, AzAcrExists("myregistry"))
+	assert.False(t, AzAcrExists("otherregistry"))
+	assert.True(t, AzAksExists("my-cluster", "my-rg"))
+	assert.False(t, AzAksExists("my-cluster", "other-rg"))
+
+	locations, err := GetAzLocations("sub")
+	assert.Nil(t, err)
+	assert.Equal(t, []AzLocation{{Name: "westus2", DisplayName: "West US 2"}}, locations)
+
+	assert.Nil(t, IsSubscriptionIdValid("sub"))
+
+	assert.Nil(t, CreateAzResourceGroup("sub", "new-rg", "westus2"))
+	exists, err = AzResourceGroupExists("sub", "new-rg")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+
+	resources.err = errors.New("unauthorized")
+	assert.False(t, AzAcrExists("myregistry"))
+	_, err = AzResourceGroupExists("sub", "my-rg")
+	assert.ErrorContains(t, err, "unauthorized", "a failed lookup is not a missing resource group")
+	assert.NotNil(t, IsSubscriptionIdValid("sub"))
+	assert.NotNil(t, CreateAzResourceGroup("sub", "other-rg", "westus2"))
+	_, err = GetAzLocations("sub")
+	assert.NotNil(t, err)
+}
+
+func TestDefaultSubscriptionId(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("AZURE_CONFIG_DIR", configDir)
+	t.Setenv(SubscriptionIdEnv, "")
+
+	_, err := DefaultSubscriptionId()
+	assert.NotNil(t, err)
+
+	profile := "\xef\xbb\xbf" + `{"subscriptions": [{"id": "first", "isDefault": false}, {"id": "second", "isDefault": true}]}`
+	assert.Nil(t, os.WriteFile(filepath.Join(configDir, "azureProfile.json"), []byte(profile), 0644))
+	id, err := DefaultSubscriptionId()
+	assert.Nil(t, err)
+	assert.Equal(t, "second", id)
+
+	t.Setenv(SubscriptionIdEnv, "from-env")
+	id, err = DefaultSubscriptionId()
+	assert.Nil(t, err)
+	assert.Equal(t, "from-env", id)
+}
+
+func TestNewAzureResource(t *testing.T) {
+	id := "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/my-rg/providers/Microsoft.ContainerService/managedClusters/my-cluster"
+	name, location := "my-cluster", "westus2"
+	assert.Equal(t, AzureResource{Name: "my-cluster", ResourceGroup: "my-rg", Location: "westus2"}, newAzureResource(&id, &name, &location))
+	assert.Equal(t, AzureResource{}, newAzureResource(nil, nil, nil))
+}
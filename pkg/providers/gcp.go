@@ -0,0 +1,198 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/spinner"
+)
+
+var (
+	gcpProjectIdRegex        = regexp.MustCompile(`^[a-z][a-z0-9-]{4,28}[a-z0-9]$`)
+	gcpLocationRegex         = regexp.MustCompile(`^[a-z]+-[a-z]+[0-9]+(-[a-z])?$`)
+	gcpServiceAccountIdRegex = regexp.MustCompile(`^[a-z][a-z0-9-]{4,28}[a-z0-9]$`)
+	artifactRegistryRegex    = regexp.MustCompile(`^([a-z0-9-]+)-docker\.pkg\.dev/([a-z][a-z0-9-]{4,28}[a-z0-9])/([a-z]([a-z0-9-]{0,61}[a-z0-9])?)$`)
+	gkeClusterNameRegex      = regexp.MustCompile(`^[a-z]([-a-z0-9]{0,38}[a-z0-9])?$`)
+)
+
+const (
+	// gcpWorkloadIdentityPool is the Workload Identity Federation pool and provider setup-oidc creates for GitHub
+	gcpWorkloadIdentityPool = "github"
+	githubOIDCIssuer        = "https://token.actions.githubusercontent.com"
+)
+
+// gcpServiceAccountRoles are the roles of the service account the GCP workflows impersonate, which push images to
+// Artifact Registry and deploy to GKE
+var gcpServiceAccountRoles = []string{"roles/artifactregistry.writer", "roles/container.developer"}
+
+// gcpRun runs the gcloud commands of the validators checking that resources exist, and the gcloud and gh commands of
+// setting up Workload Identity Federation
+var gcpRun commandRunner = runCommand
+
+// ValidateGcpProjectId checks that projectId is a Google Cloud project ID, without looking it up
+func ValidateGcpProjectId(projectId string) error {
+	if !gcpProjectIdRegex.MatchString(projectId) {
+		return fmt.Errorf("invalid Google Cloud project ID %q, must be 6-30 lowercase letters, digits and hyphens starting with a letter", projectId)
+	}
+	return nil
+}
+
+// ValidateGkeLocation checks that location is a Google Cloud region such as us-central1 or a zone such as
+// us-central1-a, without looking it up
+func ValidateGkeLocation(location string) error {
+	if !gcpLocationRegex.MatchString(location) {
+		return fmt.Errorf("invalid GKE cluster location %q, must be a region such as us-central1 or a zone such as us-central1-a", location)
+	}
+	return nil
+}
+
+// ValidateGcpServiceAccountId checks that id is the name of a Google Cloud service account, the part of its email
+// before the @
+func ValidateGcpServiceAccountId(id string) error {
+	if !gcpServiceAccountIdRegex.MatchString(id) {
+		return errors.New("service account names are 6-30 lowercase letters, digits and hyphens and start with a letter")
+	}
+	return nil
+}
+
+// ValidateArtifactRegistry checks that repository is a Docker repository of Google Artifact Registry, such as
+// us-central1-docker.pkg.dev/my-project/images, and that it exists. Its existence isn't checked Offline or when gcloud
+// can't tell, such as when it isn't installed or signed in.
+func ValidateArtifactRegistry(repository string) error {
+	match := artifactRegistryRegex.FindStringSubmatch(repository)
+	if match == nil {
+		return fmt.Errorf("invalid Artifact Registry repository %q, must be like us-central1-docker.pkg.dev/PROJECT/REPOSITORY", repository)
+	}
+	if offline {
+		return nil
+	}
+	location, project, name := match[1], match[2], match[3]
+	_, err := gcpRun(context.Background(), "gcloud", "artifacts", "repositories", "describe", name, "--project", project, "--location", location, "--format", "json")
+	if err != nil && strings.Contains(err.Error(), "NOT_FOUND") {
+		return fmt.Errorf("Artifact Registry repository %s doesn't exist", repository)
+	}
+	if err != nil {
+		log.Debugf("not checking that Artifact Registry repository %s exists: %s", repository, err)
+	}
+	return nil
+}
+
+// ValidateGkeClusterName checks clusterName against the GKE cluster naming rules and that a cluster of that name
+// exists in the active gcloud project. Its existence isn't checked Offline or when gcloud can't tell, such as when it
+// isn't installed or signed in.
+func ValidateGkeClusterName(clusterName string) error {
+	if !gkeClusterNameRegex.MatchString(clusterName) {
+		return errors.New("GKE cluster names are 1-40 lowercase letters, digits and hyphens, start with a letter and don't end with a hyphen")
+	}
+	if offline {
+		return nil
+	}
+	out, err := gcpRun(context.Background(), "gcloud", "container", "clusters", "list", "--filter", "name="+clusterName, "--format", "value(name)")
+	if err != nil {
+		log.Debugf("not checking that GKE cluster %s exists: %s", clusterName, err)
+		return nil
+	}
+	if strings.TrimSpace(string(out)) == "" {
+		return fmt.Errorf("GKE cluster %s doesn't exist in the current project", clusterName)
+	}
+	return nil
+}
+
+// DefaultGcpProject returns the project gcloud config set project selected, or "" when there is none
+func DefaultGcpProject() string {
+	out, err := gcpRun(context.Background(), "gcloud", "config", "get-value", "project")
+	if err != nil {
+		log.Debugf("getting the gcloud project: %s", err)
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// InitiateGCPOIDCFlow sets up Workload Identity Federation so the Github workflows of sc.Repo impersonate the service
+// account sc.AppName of project sc.ProjectID, and sets the secrets of the repo the GCP workflows authenticate with
+func InitiateGCPOIDCFlow(ctx context.Context, sc *SetUpCmd, s spinner.Spinner) error {
+	log.Debug("Commencing github connection with google cloud...")
+
+	if !HasGhCli() || !IsLoggedInToGh() {
+		s.Stop()
+		if err := LogInToGh(); err != nil {
+			return err
+		}
+		s.Start()
+	}
+
+	if err := sc.setUpGcpWorkloadIdentity(ctx); err != nil {
+		return err
+	}
+
+	log.Debug("Github connection with google cloud completed successfully!")
+	return nil
+}
+
+// setUpGcpWorkloadIdentity creates the service account with the gcpServiceAccountRoles, the GitHub pool and provider
+// trusting the repos of the owner of sc.Repo unless they exist, lets sc.Repo impersonate the service account and sets
+// the GCP_WORKLOAD_IDENTITY_PROVIDER, GCP_SERVICE_ACCOUNT and GCP_PROJECT_ID secrets of sc.Repo
+func (sc *SetUpCmd) setUpGcpWorkloadIdentity(ctx context.Context) error {
+	project := sc.ProjectID
+	out, err := gcpRun(ctx, "gcloud", "projects", "describe", project, "--format", "value(projectNumber)")
+	if err != nil {
+		return fmt.Errorf("looking up project %s: %w", project, err)
+	}
+	projectNumber := strings.TrimSpace(string(out))
+	serviceAccount := fmt.Sprintf("%s@%s.iam.gserviceaccount.com", sc.AppName, project)
+
+	if _, err := gcpRun(ctx, "gcloud", "iam", "service-accounts", "describe", serviceAccount, "--project", project); err != nil {
+		log.Debugf("Creating service account %s...", serviceAccount)
+		if _, err := gcpRun(ctx, "gcloud", "iam", "service-accounts", "create", sc.AppName, "--project", project, "--display-name", "GitHub Actions of "+sc.Repo); err != nil {
+			return fmt.Errorf("creating service account %s: %w", serviceAccount, err)
+		}
+	}
+	for _, role := range gcpServiceAccountRoles {
+		log.Debugf("Granting %s to %s...", role, serviceAccount)
+		if _, err := gcpRun(ctx, "gcloud", "projects", "add-iam-policy-binding", project, "--member", "serviceAccount:"+serviceAccount, "--role", role, "--condition", "None"); err != nil {
+			return fmt.Errorf("granting %s to %s: %w", role, serviceAccount, err)
+		}
+	}
+
+	if _, err := gcpRun(ctx, "gcloud", "iam", "workload-identity-pools", "describe", gcpWorkloadIdentityPool, "--project", project, "--location", "global"); err != nil {
+		log.Debug("Creating workload identity pool...")
+		if _, err := gcpRun(ctx, "gcloud", "iam", "workload-identity-pools", "create", gcpWorkloadIdentityPool, "--project", project, "--location", "global", "--display-name", "GitHub Actions"); err != nil {
+			return fmt.Errorf("creating workload identity pool %s: %w", gcpWorkloadIdentityPool, err)
+		}
+	}
+	if _, err := gcpRun(ctx, "gcloud", "iam", "workload-identity-pools", "providers", "describe", gcpWorkloadIdentityPool, "--project", project, "--location", "global", "--workload-identity-pool", gcpWorkloadIdentityPool); err != nil {
+		log.Debug("Creating workload identity provider...")
+		owner, _, _ := strings.Cut(sc.Repo, "/")
+		if _, err := gcpRun(ctx, "gcloud", "iam", "workload-identity-pools", "providers", "create-oidc", gcpWorkloadIdentityPool,
+			"--project", project, "--location", "global", "--workload-identity-pool", gcpWorkloadIdentityPool,
+			"--display-name", "GitHub", "--issuer-uri", githubOIDCIssuer,
+			"--attribute-mapping", "google.subject=assertion.sub,attribute.repository=assertion.repository,attribute.repository_owner=assertion.repository_owner",
+			"--attribute-condition", fmt.Sprintf("assertion.repository_owner=='%s'", owner)); err != nil {
+			return fmt.Errorf("creating workload identity provider %s: %w", gcpWorkloadIdentityPool, err)
+		}
+	}
+
+	pool := fmt.Sprintf("projects/%s/locations/global/workloadIdentityPools/%s", projectNumber, gcpWorkloadIdentityPool)
+	if _, err := gcpRun(ctx, "gcloud", "iam", "service-accounts", "add-iam-policy-binding", serviceAccount, "--project", project,
+		"--role", "roles/iam.workloadIdentityUser", "--member", fmt.Sprintf("principalSet://iam.googleapis.com/%s/attribute.repository/%s", pool, sc.Repo)); err != nil {
+		return fmt.Errorf("letting %s impersonate %s: %w", sc.Repo, serviceAccount, err)
+	}
+
+	secrets := []struct{ name, value string }{
+		{"GCP_WORKLOAD_IDENTITY_PROVIDER", pool + "/providers/" + gcpWorkloadIdentityPool},
+		{"GCP_SERVICE_ACCOUNT", serviceAccount},
+		{"GCP_PROJECT_ID", project},
+	}
+	for _, secret := range secrets {
+		log.Debugf("Setting %s in github...", secret.name)
+		if _, err := gcpRun(ctx, "gh", "secret", "set", secret.name, "-b", secret.value, "--repo", sc.Repo); err != nil {
+			return fmt.Errorf("setting the %s secret: %w", secret.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func useFakeGcpRun(t *testing.T, run commandRunner) {
+	oldRun := gcpRun
+	t.Cleanup(func() { gcpRun = oldRun })
+	gcpRun = run
+}
+
+func TestGcpValidators(t *testing.T) {
+	assert.Nil(t, ValidateGcpProjectId("my-project-123"))
+	assert.NotNil(t, ValidateGcpProjectId("1project"))
+	assert.NotNil(t, ValidateGcpProjectId("proj"))
+
+	assert.Nil(t, ValidateGkeLocation("us-central1"))
+	assert.Nil(t, ValidateGkeLocation("europe-west4-a"))
+	assert.NotNil(t, ValidateGkeLocation("Central US"))
+
+	assert.Nil(t, ValidateGcpServiceAccountId("github-deployer"))
+	assert.NotNil(t, ValidateGcpServiceAccountId("gh"))
+	assert.NotNil(t, ValidateGcpServiceAccountId("GitHub-Deployer"))
+}
+
+func TestGcpResourceValidators(t *testing.T) {
+	useFakeGcpRun(t, func(_ context.Context, _ string, args ...string) ([]byte, error) {
+		command := strings.Join(args, " ")
+		switch {
+		case strings.Contains(command, "describe images"):
+			return []byte("{}"), nil
+		case strings.Contains(command, "describe missing"):
+			return nil, errors.New("ERROR: (gcloud.artifacts.repositories.describe) NOT_FOUND: Requested entity was not found.")
+		case strings.Contains(command, "name=prod"):
+			return []byte("prod\n"), nil
+		case strings.Contains(command, "name=missing"):
+			return []byte(""), nil
+		}
+		return nil, errors.New("ERROR: (gcloud) You do not currently have an active account selected.")
+	})
+
+	assert.Nil(t, ValidateArtifactRegistry("us-central1-docker.pkg.dev/my-project/images"))
+	assert.EqualError(t, ValidateArtifactRegistry("us-central1-docker.pkg.dev/my-project/missing"), "Artifact Registry repository us-central1-docker.pkg.dev/my-project/missing doesn't exist")
+	assert.Nil(t, ValidateArtifactRegistry("us-central1-docker.pkg.dev/my-project/signed-out"))
+	assert.NotNil(t, ValidateArtifactRegistry("gcr.io/my-project"))
+	assert.NotNil(t, ValidateArtifactRegistry("us-central1-docker.pkg.dev/my-project"))
+
+	assert.Nil(t, ValidateGkeClusterName("prod"))
+	assert.EqualError(t, ValidateGkeClusterName("missing"), "GKE cluster missing doesn't exist in the current project")
+	assert.Nil(t, ValidateGkeClusterName("signed-out"))
+	assert.NotNil(t, ValidateGkeClusterName("Prod"))
+	assert.NotNil(t, ValidateGkeClusterName("prod-"))
+}
+
+func TestSetUpGcpWorkloadIdentity(t *testing.T) {
+	var commands []string
+	useFakeGcpRun(t, func(_ context.Context, name string, args ...string) ([]byte, error) {
+		command := name + " " + strings.Join(args, " ")
+		commands = append(commands, command)
+		switch {
+		case strings.HasPrefix(command, "gcloud projects describe"):
+			return []byte("123456789\n"), nil
+		case strings.HasPrefix(command, "gcloud iam service-accounts describe"):
+			return nil, errors.New("NOT_FOUND: Unknown service account")
+		case strings.HasPrefix(command, "gcloud iam workload-identity-pools describe"):
+			return []byte("{}"), nil
+		case strings.HasPrefix(command, "gcloud iam workload-identity-pools providers describe"):
+			return nil, errors.New("NOT_FOUND: Requested entity was not found.")
+		}
+		return nil, nil
+	})
+
+	sc := &SetUpCmd{AppName: "github-deployer", ProjectID: "my-project", Repo: "octo/app"}
+	assert.Nil(t, sc.setUpGcpWorkloadIdentity(context.Background()))
+
+	assert.Contains(t, commands, "gcloud iam service-accounts create github-deployer --project my-project --display-name GitHub Actions of octo/app")
+	assert.Contains(t, commands, "gcloud projects add-iam-policy-binding my-project --member serviceAccount:github-deployer@my-project.iam.gserviceaccount.com --role roles/artifactregistry.writer --condition None")
+	assert.Contains(t, commands, "gcloud projects add-iam-policy-binding my-project --member serviceAccount:github-deployer@my-project.iam.gserviceaccount.com --role roles/container.developer --condition None")
+	assert.NotContains(t, commands, "gcloud iam workload-identity-pools create github --project my-project --location global --display-name GitHub Actions")
+	assert.Contains(t, strings.Join(commands, "\n"), "--attribute-condition assertion.repository_owner=='octo'")
+	assert.Contains(t, commands, "gcloud iam service-accounts add-iam-policy-binding github-deployer@my-project.iam.gserviceaccount.com --project my-project --role roles/iam.workloadIdentityUser --member principalSet://iam.googleapis.com/projects/123456789/locations/global/workloadIdentityPools/github/attribute.repository/octo/app")
+	assert.Contains(t, commands, "gh secret set GCP_WORKLOAD_IDENTITY_PROVIDER -b projects/123456789/locations/global/workloadIdentityPools/github/providers/github --repo octo/app")
+	assert.Contains(t, commands, "gh secret set GCP_SERVICE_ACCOUNT -b github-deployer@my-project.iam.gserviceaccount.com --repo octo/app")
+	assert.Contains(t, commands, "gh secret set GCP_PROJECT_ID -b my-project --repo octo/app")
+}
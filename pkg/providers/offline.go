@@ -0,0 +1,28 @@
+package providers
+
+import (
+	"os"
+	"strconv"
+)
+
+// OfflineEnv is the environment variable enabling offline mode, like the --offline flag, when set to true
+const OfflineEnv = "DRAFT_OFFLINE"
+
+var offline bool
+
+// SetOffline sets whether draft runs offline, without the cloud clis. Offline, the validators only check the format
+// of the names of resources rather than also looking them up.
+func SetOffline(enabled bool) {
+	offline = enabled
+}
+
+// Offline returns whether draft runs offline
+func Offline() bool {
+	return offline
+}
+
+// OfflineFromEnv returns whether $DRAFT_OFFLINE enables offline mode
+func OfflineFromEnv() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv(OfflineEnv))
+	return enabled
+}
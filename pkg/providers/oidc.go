@@ -0,0 +1,223 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Source control hosts whose CI tokens setup-oidc makes the Azure AD application trust
+const (
+	SCMGitHub      = "github"
+	SCMGitLab      = "gitlab"
+	SCMAzureDevOps = "azdo"
+)
+
+// SCMs are the source control hosts setup-oidc supports
+var SCMs = []string{SCMGitHub, SCMGitLab, SCMAzureDevOps}
+
+// DefaultGitLabURL is the GitLab instance issuing the tokens of GitLab pipelines unless another one is set
+const DefaultGitLabURL = "https://gitlab.com"
+
+// azureADTokenExchange is the audience of the tokens exchanged for Azure AD tokens
+const azureADTokenExchange = "api://AzureADTokenExchange"
+
+// azureDevOpsResource is the Azure AD application of Azure DevOps, whose tokens az rest requests to call its apis
+const azureDevOpsResource = "499b84ac-1321-427f-aa17-267ca6975798"
+
+var (
+	gitLabProjectRegex     = regexp.MustCompile(`^[A-Za-z0-9_.-]+(/[A-Za-z0-9_.-]+)+$`)
+	azdoProjectRegex       = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,48}[A-Za-z0-9])?/[^/\\:*?"<>|]+$`)
+	serviceConnectionRegex = regexp.MustCompile(`^[^/\\"]+$`)
+)
+
+// azdoRun runs the az commands looking up the Azure DevOps organization, replaced in tests
+var azdoRun commandRunner = runCommand
+
+// FederatedCredential is a federated identity credential of the Azure AD application, letting the CI jobs whose tokens
+// Issuer issues for Subject sign in as the application
+type FederatedCredential struct {
+	Name        string   `json:"name"`
+	Issuer      string   `json:"issuer"`
+	Subject     string   `json:"subject"`
+	Description string   `json:"description"`
+	Audiences   []string `json:"audiences"`
+}
+
+// OIDCSetting is a value the CI jobs of an SCM need to sign in through the federated credentials, which setup-oidc
+// prints for the SCMs whose secrets it can't set
+type OIDCSetting struct {
+	Name  string
+	Value string
+}
+
+// ValidateSCM checks that scm is one of the SCMs
+func ValidateSCM(scm string) error {
+	for _, s := range SCMs {
+		if scm == s {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid scm %q, must be one of %s", scm, strings.Join(SCMs, ", "))
+}
+
+// ValidateRepoFormat checks the format of the repo of scm, organization/repoName on GitHub, group/project with any
+// subgroups on GitLab and organization/project on Azure DevOps, without looking it up
+func ValidateRepoFormat(scm, repo string) error {
+	switch scm {
+	case SCMGitLab:
+		if !gitLabProjectRegex.MatchString(repo) {
+			return errors.New("gitlab project must be in the form group/project")
+		}
+	case SCMAzureDevOps:
+		if !azdoProjectRegex.MatchString(repo) {
+			return errors.New("azure devops project must be in the form organization/project")
+		}
+	default:
+		return ValidateGhRepoFormat(repo)
+	}
+	return nil
+}
+
+// ValidateServiceConnectionName checks the name of an Azure DevOps service connection
+func ValidateServiceConnectionName(name string) error {
+	if !serviceConnectionRegex.MatchString(name) {
+		return errors.New("service connection names can't be empty or contain /, \\ or \"")
+	}
+	return nil
+}
+
+// ValidateGitLabURL checks that gitLabURL is the https url of a GitLab instance
+func ValidateGitLabURL(gitLabURL string) error {
+	u, err := url.Parse(gitLabURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("invalid gitlab url %q, must be the https url of the instance such as %s", gitLabURL, DefaultGitLabURL)
+	}
+	return nil
+}
+
+// scm returns the SCM of sc, github when unset
+func (sc *SetUpCmd) scm() string {
+	if sc.SCM == "" {
+		return SCMGitHub
+	}
+	return sc.SCM
+}
+
+// validateRepo checks that the repo exists on GitHub, or the format of the repo of the other SCMs, which have no cli
+// to look it up with
+func (sc *SetUpCmd) validateRepo() error {
+	switch sc.scm() {
+	case SCMGitLab:
+		if err := ValidateGitLabURL(sc.gitLabURL()); err != nil {
+			return err
+		}
+		if sc.Branch == "" {
+			return errors.New("invalid branch")
+		}
+	case SCMAzureDevOps:
+		if err := ValidateServiceConnectionName(sc.ServiceConnection); err != nil {
+			return err
+		}
+	default:
+		return isValidGhRepo(sc.Repo)
+	}
+	return ValidateRepoFormat(sc.scm(), sc.Repo)
+}
+
+func (sc *SetUpCmd) gitLabURL() string {
+	if sc.GitLabURL == "" {
+		return DefaultGitLabURL
+	}
+	return strings.TrimSuffix(sc.GitLabURL, "/")
+}
+
+// federatedCredentials returns the federated credentials trusting the CI jobs of the repo: the pull requests and the
+// main and master branches of a GitHub repo, the pipelines of the Branch of a GitLab project, and the pipelines using
+// the ServiceConnection of an Azure DevOps project
+func (sc *SetUpCmd) federatedCredentials() []FederatedCredential {
+	switch sc.scm() {
+	case SCMGitLab:
+		return []FederatedCredential{{
+			Name:        "gitlabfic",
+			Issuer:      sc.gitLabURL(),
+			Subject:     fmt.Sprintf("project_path:%s:ref_type:branch:ref:%s", sc.Repo, sc.Branch),
+			Description: sc.Branch,
+			Audiences:   []string{azureADTokenExchange},
+		}}
+	case SCMAzureDevOps:
+		return []FederatedCredential{{
+			Name:        "azdofic",
+			Issuer:      sc.azdoIssuer(),
+			Subject:     sc.azdoSubject(),
+			Description: sc.ServiceConnection,
+			Audiences:   []string{azureADTokenExchange},
+		}}
+	}
+
+	issuer := "https://token.actions.githubusercontent.com"
+	return []FederatedCredential{
+		{Name: "prfic", Issuer: issuer, Subject: fmt.Sprintf("repo:%s:pull_request", sc.Repo), Description: "pr", Audiences: []string{azureADTokenExchange}},
+		{Name: "mainfic", Issuer: issuer, Subject: fmt.Sprintf("repo:%s:ref:refs/heads/main", sc.Repo), Description: "main", Audiences: []string{azureADTokenExchange}},
+		{Name: "masterfic", Issuer: issuer, Subject: fmt.Sprintf("repo:%s:ref:refs/heads/master", sc.Repo), Description: "master", Audiences: []string{azureADTokenExchange}},
+	}
+}
+
+func (sc *SetUpCmd) azdoIssuer() string {
+	return "https://vstoken.dev.azure.com/" + sc.azdoOrganizationId
+}
+
+func (sc *SetUpCmd) azdoSubject() string {
+	return fmt.Sprintf("sc://%s/%s", sc.Repo, sc.ServiceConnection)
+}
+
+// getAzdoOrganizationId looks up the id of the Azure DevOps organization of the repo, which issues the tokens of its
+// service connections
+func (sc *SetUpCmd) getAzdoOrganizationId(ctx context.Context) error {
+	organization, _, _ := strings.Cut(sc.Repo, "/")
+	out, err := azdoRun(ctx, "az", "rest", "--method", "GET", "--resource", azureDevOpsResource,
+		"--uri", fmt.Sprintf("https://dev.azure.com/%s/_apis/connectionData", url.PathEscape(organization)))
+	if err != nil {
+		return fmt.Errorf("looking up azure devops organization %q: %w", organization, err)
+	}
+
+	var connectionData struct {
+		InstanceId string `json:"instanceId"`
+	}
+	if err := json.Unmarshal(out, &connectionData); err != nil {
+		return fmt.Errorf("reading azure devops organization %q: %w", organization, err)
+	}
+	if connectionData.InstanceId == "" {
+		return fmt.Errorf("azure devops organization %q not found", organization)
+	}
+	sc.azdoOrganizationId = connectionData.InstanceId
+	return nil
+}
+
+// OIDCSettings returns the CI/CD variables of a GitLab project, or the settings of the manual workload identity
+// federation service connection of an Azure DevOps project, signing in through the federated credentials. GitHub repos
+// have none, their secrets are set by InitiateAzureOIDCFlow.
+func (sc *SetUpCmd) OIDCSettings() []OIDCSetting {
+	switch sc.scm() {
+	case SCMGitLab:
+		return []OIDCSetting{
+			{Name: "AZURE_CLIENT_ID", Value: sc.appId},
+			{Name: "AZURE_TENANT_ID", Value: sc.tenantId},
+			{Name: "AZURE_SUBSCRIPTION_ID", Value: sc.SubscriptionID},
+		}
+	case SCMAzureDevOps:
+		return []OIDCSetting{
+			{Name: "Service connection name", Value: sc.ServiceConnection},
+			{Name: "Issuer", Value: sc.azdoIssuer()},
+			{Name: "Subject identifier", Value: sc.azdoSubject()},
+			{Name: "Subscription Id", Value: sc.SubscriptionID},
+			{Name: "Service Principal Id", Value: sc.appId},
+			{Name: "Tenant ID", Value: sc.tenantId},
+		}
+	}
+	return nil
+}
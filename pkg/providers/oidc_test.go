@@ -0,0 +1,87 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOIDCValidators(t *testing.T) {
+	assert.Nil(t, ValidateSCM("gitlab"))
+	assert.NotNil(t, ValidateSCM("bitbucket"))
+
+	assert.Nil(t, ValidateRepoFormat(SCMGitHub, "octo/app"))
+	assert.NotNil(t, ValidateRepoFormat(SCMGitHub, "octo/team/app"))
+	assert.Nil(t, ValidateRepoFormat(SCMGitLab, "octo/team/app"))
+	assert.NotNil(t, ValidateRepoFormat(SCMGitLab, "app"))
+	assert.Nil(t, ValidateRepoFormat(SCMAzureDevOps, "contoso/Web Shop"))
+	assert.NotNil(t, ValidateRepoFormat(SCMAzureDevOps, "contoso/web/shop"))
+	assert.NotNil(t, ValidateRepoFormat(SCMAzureDevOps, "contoso-/shop"))
+
+	assert.Nil(t, ValidateGitLabURL("https://gitlab.example.com/"))
+	assert.NotNil(t, ValidateGitLabURL("http://gitlab.example.com"))
+	assert.NotNil(t, ValidateGitLabURL("gitlab.example.com"))
+
+	assert.Nil(t, ValidateServiceConnectionName("Azure prod"))
+	assert.NotNil(t, ValidateServiceConnectionName(""))
+	assert.NotNil(t, ValidateServiceConnectionName("azure/prod"))
+}
+
+func TestFederatedCredentials(t *testing.T) {
+	sc := &SetUpCmd{Repo: "octo/app"}
+	var subjects []string
+	for _, fic := range sc.federatedCredentials() {
+		assert.Equal(t, "https://token.actions.githubusercontent.com", fic.Issuer)
+		subjects = append(subjects, fic.Subject)
+	}
+	assert.Equal(t, []string{"repo:octo/app:pull_request", "repo:octo/app:ref:refs/heads/main", "repo:octo/app:ref:refs/heads/master"}, subjects)
+	assert.Nil(t, sc.OIDCSettings())
+
+	sc = &SetUpCmd{SCM: SCMGitLab, Repo: "octo/team/app", Branch: "release", GitLabURL: "https://gitlab.example.com/", appId: "client", tenantId: "tenant", SubscriptionID: "subscription"}
+	assert.Equal(t, []FederatedCredential{{
+		Name:        "gitlabfic",
+		Issuer:      "https://gitlab.example.com",
+		Subject:     "project_path:octo/team/app:ref_type:branch:ref:release",
+		Description: "release",
+		Audiences:   []string{"api://AzureADTokenExchange"},
+	}}, sc.federatedCredentials())
+	assert.Equal(t, []OIDCSetting{
+		{Name: "AZURE_CLIENT_ID", Value: "client"},
+		{Name: "AZURE_TENANT_ID", Value: "tenant"},
+		{Name: "AZURE_SUBSCRIPTION_ID", Value: "subscription"},
+	}, sc.OIDCSettings())
+
+	sc = &SetUpCmd{SCM: SCMGitLab, Repo: "octo/app", Branch: "main"}
+	assert.Equal(t, "https://gitlab.com", sc.federatedCredentials()[0].Issuer)
+}
+
+func TestAzureDevOpsFederatedCredential(t *testing.T) {
+	var commands []string
+	oldRun := azdoRun
+	t.Cleanup(func() { azdoRun = oldRun })
+	azdoRun = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		command := name + " " + strings.Join(args, " ")
+		commands = append(commands, command)
+		if strings.Contains(command, "dev.azure.com/missing/") {
+			return nil, errors.New("az rest: exit status 1: Not Found")
+		}
+		return []byte(`{"authenticatedUser": {}, "instanceId": "6f2a0a0e-2a73-4a1d-8f3a-3c6e6f0d1b2c"}`), nil
+	}
+
+	sc := &SetUpCmd{SCM: SCMAzureDevOps, Repo: "contoso/Web Shop", ServiceConnection: "azure-prod", appId: "client", tenantId: "tenant", SubscriptionID: "subscription"}
+	assert.Nil(t, sc.getAzdoOrganizationId(context.Background()))
+	assert.Equal(t, []string{"az rest --method GET --resource 499b84ac-1321-427f-aa17-267ca6975798 --uri https://dev.azure.com/contoso/_apis/connectionData"}, commands)
+
+	fics := sc.federatedCredentials()
+	assert.Len(t, fics, 1)
+	assert.Equal(t, "https://vstoken.dev.azure.com/6f2a0a0e-2a73-4a1d-8f3a-3c6e6f0d1b2c", fics[0].Issuer)
+	assert.Equal(t, "sc://contoso/Web Shop/azure-prod", fics[0].Subject)
+	assert.Contains(t, sc.OIDCSettings(), OIDCSetting{Name: "Subject identifier", Value: "sc://contoso/Web Shop/azure-prod"})
+	assert.Contains(t, sc.OIDCSettings(), OIDCSetting{Name: "Service Principal Id", Value: "client"})
+
+	sc = &SetUpCmd{SCM: SCMAzureDevOps, Repo: "missing/shop", ServiceConnection: "azure-prod"}
+	assert.NotNil(t, sc.getAzdoOrganizationId(context.Background()))
+}
@@ -1,15 +1,20 @@
 package providers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"regexp"
+	"strings"
 
 	"github.com/hashicorp/go-version"
 	"github.com/manifoldco/promptui"
 	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/prompts"
 )
 
 type SubLabel struct {
@@ -38,7 +43,7 @@ func getAzUpgrade() string {
 		Items: []string{"yes", "no"},
 	}
 
-	_, selectResponse, err := selection.Run()
+	_, selectResponse, err := prompts.RunSelect(selection)
 	if err != nil {
 		return err.Error()
 	}
@@ -149,23 +154,102 @@ func LogInToAz() error {
 	return nil
 }
 
+var (
+	subscriptionIdRegex    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	resourceGroupNameRegex = regexp.MustCompile(`^[-\w.()]{0,89}[-\w()]$`)
+	ghRepoRegex            = regexp.MustCompile(`^[A-Za-z0-9-]+/[A-Za-z0-9_.-]+$`)
+	acrNameRegex           = regexp.MustCompile(`^[A-Za-z0-9]{5,50}$`)
+	aksNameRegex           = regexp.MustCompile(`^[A-Za-z0-9]([-\w]{0,61}[A-Za-z0-9])?$`)
+)
+
+// ValidateSubscriptionIdFormat checks that subscriptionId is a GUID without looking it up, for use as a prompt validator
+func ValidateSubscriptionIdFormat(subscriptionId string) error {
+	if !subscriptionIdRegex.MatchString(subscriptionId) {
+		return errors.New("subscription ID must be a GUID like 00000000-0000-0000-0000-000000000000")
+	}
+	return nil
+}
+
+// ValidateResourceGroupNameFormat checks resourceGroup against the Azure resource group naming rules without looking
+// it up, for use as a prompt validator
+func ValidateResourceGroupNameFormat(resourceGroup string) error {
+	if !resourceGroupNameRegex.MatchString(resourceGroup) {
+		return errors.New("resource group names are 1-90 letters, digits, underscores, hyphens, periods and parentheses and can't end in a period")
+	}
+	return nil
+}
+
+// ValidateGhRepoFormat checks that repo is an organization/repoName pair without looking it up, for use as a prompt validator
+func ValidateGhRepoFormat(repo string) error {
+	if !ghRepoRegex.MatchString(repo) {
+		return errors.New("github repo must be in the form organization/repoName")
+	}
+	return nil
+}
+
+// ValidateAcrNameFormat checks acrName against the Azure Container Registry naming rules without looking it up, for
+// use as a prompt validator
+func ValidateAcrNameFormat(acrName string) error {
+	if !acrNameRegex.MatchString(acrName) {
+		return errors.New("container registry names are 5-50 letters and digits")
+	}
+	return nil
+}
+
+// ValidateAzResourceGroup checks resourceGroup against the Azure resource group naming rules and that it exists in the
+// default subscription. Its existence isn't checked Offline or when Azure can't tell, such as when no credentials are
+// available.
+func ValidateAzResourceGroup(resourceGroup string) error {
+	if err := ValidateResourceGroupNameFormat(resourceGroup); err != nil || offline {
+		return err
+	}
+	exists, err := azResourceGroupExists(context.Background(), "", resourceGroup)
+	if err != nil {
+		log.Debugf("not checking that resource group %s exists: %s", resourceGroup, err)
+		return nil
+	}
+	if !exists {
+		return fmt.Errorf("resource group %s doesn't exist in the current subscription", resourceGroup)
+	}
+	return nil
+}
+
+// ValidateAksClusterName checks clusterName against the AKS cluster naming rules and that a cluster of that name exists
+// in the default subscription. Its existence isn't checked Offline or when Azure can't tell, such as when no
+// credentials are available.
+func ValidateAksClusterName(clusterName string) error {
+	if !aksNameRegex.MatchString(clusterName) {
+		return errors.New("AKS cluster names are 1-63 letters, digits, underscores and hyphens and start and end with a letter or digit")
+	}
+	if offline {
+		return nil
+	}
+	clusters, err := listAzResources(context.Background(), "", AzureResources.KubernetesClusters)
+	if err != nil {
+		log.Debugf("not checking that AKS cluster %s exists: %s", clusterName, err)
+		return nil
+	}
+	if !hasAzResource(clusters, clusterName, "") {
+		return fmt.Errorf("AKS cluster %s doesn't exist in the current subscription", clusterName)
+	}
+	return nil
+}
+
 func IsSubscriptionIdValid(subscriptionId string) error {
 	if subscriptionId == "" {
 		return errors.New("subscriptionId cannot be empty")
 	}
 
-	getSubscriptionIdCmd := exec.Command("az", "account", "show", "-s", subscriptionId, "--query", "id")
-	out, err := getSubscriptionIdCmd.CombinedOutput()
+	resources, err := newAzureResources(subscriptionId)
 	if err != nil {
 		return err
 	}
-
-	var azSubscription string
-	if err = json.Unmarshal(out, &azSubscription); err != nil {
+	azSubscription, err := resources.Subscription(context.Background())
+	if err != nil {
 		return err
 	}
 
-	if azSubscription == "" {
+	if azSubscription.ID == "" {
 		return errors.New("subscription not found")
 	}
 
@@ -180,20 +264,13 @@ func isValidResourceGroup(
 		return errors.New("resource group cannot be empty")
 	}
 
-	query := fmt.Sprintf("[?name=='%s']", resourceGroup)
-	getResourceGroupCmd := exec.Command("az", "group", "list", "--subscription", subscriptionId, "--query", query)
-	out, err := getResourceGroupCmd.CombinedOutput()
+	exists, err := azResourceGroupExists(context.Background(), subscriptionId, resourceGroup)
 	if err != nil {
 		log.Errorf("failed to validate resource group %q from subscription %q: %s", resourceGroup, subscriptionId, err)
 		return err
 	}
 
-	var rg []interface{}
-	if err = json.Unmarshal(out, &rg); err != nil {
-		return err
-	}
-
-	if len(rg) == 0 {
+	if !exists {
 		return fmt.Errorf("resource group %q not found from subscription %q", resourceGroup, subscriptionId)
 	}
 
@@ -210,6 +287,16 @@ func isValidGhRepo(repo string) error {
 	return nil
 }
 
+// CheckGhRepo returns an error if repo can't be viewed with the gh cli
+func CheckGhRepo(repo string) error {
+	viewRepoCmd := exec.Command("gh", "repo", "view", repo, "--json", "name")
+	out, err := viewRepoCmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("github repo %q not found: %s", repo, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
 func AzAppExists(appName string) bool {
 	filter := fmt.Sprintf("displayName eq '%s'", appName)
 	checkAppExistsCmd := exec.Command("az", "ad", "app", "list", "--only-show-errors", "--filter", filter, "--query", "[].appId")
@@ -241,31 +328,21 @@ func (sc *SetUpCmd) ServicePrincipalExists() bool {
 }
 
 func AzAcrExists(acrName string) bool {
-	query := fmt.Sprintf("[?name=='%s']", acrName)
-	checkAcrExistsCmd := exec.Command("az", "acr", "list", "--only-show-errors", "--query", query)
-	out, err := checkAcrExistsCmd.CombinedOutput()
+	registries, err := listAzResources(context.Background(), "", AzureResources.ContainerRegistries)
 	if err != nil {
 		return false
 	}
 
-	var azAcr []interface{}
-	json.Unmarshal(out, &azAcr)
-
-	if len(azAcr) >= 1 {
-		return true
-	}
-
-	return false
+	return hasAzResource(registries, acrName, "")
 }
 
 func AzAksExists(aksName string, resourceGroup string) bool {
-	checkAksExistsCmd := exec.Command("az", "aks", "browse", "-g", resourceGroup, "--name", aksName)
-	_, err := checkAksExistsCmd.CombinedOutput()
+	clusters, err := listAzResources(context.Background(), "", AzureResources.KubernetesClusters)
 	if err != nil {
 		return false
 	}
 
-	return true
+	return hasAzResource(clusters, aksName, resourceGroup)
 }
 
 func GetCurrentAzSubscriptionLabel() (SubLabel, error) {
@@ -276,15 +353,14 @@ func GetCurrentAzSubscriptionLabel() (SubLabel, error) {
 		}
 	}
 
-	getAccountCmd := exec.Command("az", "account", "show", "--query", "{id: id, name: name}")
-	out, err := getAccountCmd.CombinedOutput()
+	// the default subscription of the az cli profile, which az account show returns
+	resources, err := newAzureResources("")
 	if err != nil {
-		log.Fatal(err)
+		return SubLabel{}, err
 	}
-
-	var currentSub SubLabel
-	if err := json.Unmarshal(out, &currentSub); err != nil {
-		return SubLabel{}, fmt.Errorf("failed to unmarshal JSON output: %v", err)
+	currentSub, err := resources.Subscription(context.Background())
+	if err != nil {
+		return SubLabel{}, err
 	} else if currentSub.ID == "" {
 		return SubLabel{}, errors.New("no current subscription found")
 	}
@@ -300,16 +376,13 @@ func GetAzSubscriptionLabels() ([]SubLabel, error) {
 		}
 	}
 
-	getAccountCmd := exec.Command("az", "account", "list", "--all", "--query", "[].{id: id, name: name}")
-
-	out, err := getAccountCmd.CombinedOutput()
+	resources, err := newAzureResources("")
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-
-	var subLabels []SubLabel
-	if err := json.Unmarshal(out, &subLabels); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal JSON output: %v", err)
+	subLabels, err := resources.Subscriptions(context.Background())
+	if err != nil {
+		return nil, err
 	} else if len(subLabels) == 0 {
 		return nil, errors.New("no subscriptions found")
 	}
@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -24,3 +25,51 @@ func TestCheckAzCliInstalled(t *testing.T) {
 func TestHasGhCli(t *testing.T) {
 	assert.True(t, HasGhCli(), "Github CLI is not installed")
 }
+
+func TestPromptValidators(t *testing.T) {
+	assert.Nil(t, ValidateSubscriptionIdFormat("0b1f6471-1bf0-4dda-aec3-cb9272f09590"))
+	assert.NotNil(t, ValidateSubscriptionIdFormat("my-subscription"))
+
+	assert.Nil(t, ValidateResourceGroupNameFormat("my_rg-1.(prod)"))
+	assert.NotNil(t, ValidateResourceGroupNameFormat(""))
+	assert.NotNil(t, ValidateResourceGroupNameFormat("ends.with.period."))
+	assert.NotNil(t, ValidateResourceGroupNameFormat("has space"))
+
+	assert.Nil(t, ValidateGhRepoFormat("Azure/draft"))
+	assert.NotNil(t, ValidateGhRepoFormat("draft"))
+	assert.NotNil(t, ValidateGhRepoFormat("https://github.com/Azure/draft"))
+
+	assert.Nil(t, ValidateAcrNameFormat("myRegistry01"))
+	assert.NotNil(t, ValidateAcrNameFormat("acr"))
+	assert.NotNil(t, ValidateAcrNameFormat("my-registry"))
+	assert.NotNil(t, ValidateAcrNameFormat("myregistry.azurecr.io"))
+}
+
+func TestAzResourceValidators(t *testing.T) {
+	resources := &fakeAzureResources{
+		groups:   []AzureResource{{Name: "my-rg", ResourceGroup: "my-rg"}},
+		clusters: []AzureResource{{Name: "my-cluster", ResourceGroup: "my-rg"}},
+	}
+	useFakeAzureResources(t, resources)
+
+	assert.Nil(t, ValidateAzResourceGroup("my-rg"))
+	assert.EqualError(t, ValidateAzResourceGroup("other-rg"), "resource group other-rg doesn't exist in the current subscription")
+	assert.NotNil(t, ValidateAzResourceGroup("has space"))
+
+	assert.Nil(t, ValidateAksClusterName("my-cluster"))
+	assert.EqualError(t, ValidateAksClusterName("other-cluster"), "AKS cluster other-cluster doesn't exist in the current subscription")
+	assert.NotNil(t, ValidateAksClusterName("-cluster"))
+	assert.NotNil(t, ValidateAksClusterName("my'cluster"))
+
+	// offline only the names are checked
+	SetOffline(true)
+	assert.Nil(t, ValidateAzResourceGroup("other-rg"))
+	assert.Nil(t, ValidateAksClusterName("other-cluster"))
+	assert.NotNil(t, ValidateAksClusterName("-cluster"))
+	SetOffline(false)
+
+	// when Azure can't be reached only the names are checked
+	resources.err = errors.New("no credentials")
+	assert.Nil(t, ValidateAzResourceGroup("other-rg"))
+	assert.Nil(t, ValidateAksClusterName("other-cluster"))
+}
@@ -0,0 +1,72 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// AzLocation is an Azure region available to a subscription
+type AzLocation struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// GetAzLocations returns the physical regions available to the subscription
+func GetAzLocations(subscriptionId string) ([]AzLocation, error) {
+	resources, err := newAzureResources(subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+	locations, err := resources.Locations(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if len(locations) == 0 {
+		return nil, fmt.Errorf("no locations found for subscription %q", subscriptionId)
+	}
+
+	return locations, nil
+}
+
+// ValidateAzLocation checks that location is the name or display name of one of locations and returns its name
+func ValidateAzLocation(location string, locations []AzLocation) (string, error) {
+	if location == "" {
+		return "", errors.New("location cannot be empty")
+	}
+
+	normalized := strings.ToLower(strings.ReplaceAll(location, " ", ""))
+	for _, l := range locations {
+		if strings.ToLower(l.Name) == normalized || strings.ToLower(strings.ReplaceAll(l.DisplayName, " ", "")) == normalized {
+			return l.Name, nil
+		}
+	}
+
+	return "", fmt.Errorf("location %q is not available to this subscription, run `az account list-locations -o table` to see the available locations", location)
+}
+
+// CreateAzResourceGroup creates resourceGroup in location
+func CreateAzResourceGroup(subscriptionId, resourceGroup, location string) error {
+	log.Debugf("Creating resource group %q in %q...", resourceGroup, location)
+	resources, err := newAzureResources(subscriptionId)
+	if err != nil {
+		return err
+	}
+	if err := resources.CreateResourceGroup(context.Background(), resourceGroup, location); err != nil {
+		return err
+	}
+
+	log.Debug("Resource group created successfully!")
+	return nil
+}
+
+// AzResourceGroupExists returns whether resourceGroup exists in the subscription, or the error of looking it up
+func AzResourceGroupExists(subscriptionId, resourceGroup string) (bool, error) {
+	if resourceGroup == "" {
+		return false, errors.New("resource group cannot be empty")
+	}
+	return azResourceGroupExists(context.Background(), subscriptionId, resourceGroup)
+}
@@ -0,0 +1,28 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAzLocation(t *testing.T) {
+	locations := []AzLocation{
+		{Name: "eastus", DisplayName: "East US"},
+		{Name: "westeurope", DisplayName: "West Europe"},
+	}
+
+	name, err := ValidateAzLocation("eastus", locations)
+	assert.Nil(t, err)
+	assert.Equal(t, "eastus", name)
+
+	name, err = ValidateAzLocation("West Europe", locations)
+	assert.Nil(t, err)
+	assert.Equal(t, "westeurope", name)
+
+	_, err = ValidateAzLocation("moon", locations)
+	assert.NotNil(t, err)
+
+	_, err = ValidateAzLocation("", locations)
+	assert.NotNil(t, err)
+}
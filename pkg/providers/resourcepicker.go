@@ -0,0 +1,215 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/Azure/draft/pkg/prompts"
+)
+
+// commandRunner runs a cli command and returns its stdout
+type commandRunner func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return nil, fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return out, err
+}
+
+// NewResourcePicker returns the prompts.ResourcePicker listing resources with the cli of provider, which is azure,
+// aws or gcp
+func NewResourcePicker(provider string) (prompts.ResourcePicker, error) {
+	switch strings.ToLower(provider) {
+	case "azure":
+		return &AzurePicker{resources: newAzureResources}, nil
+	case "aws":
+		return &AWSPicker{run: runCommand}, nil
+	case "gcp":
+		return &GCPPicker{run: runCommand}, nil
+	}
+	return nil, fmt.Errorf("unsupported resource picker provider %q, must be one of: azure, aws, gcp", provider)
+}
+
+// AzurePicker lists Azure resources of the default subscription
+type AzurePicker struct {
+	resources func(subscriptionId string) (AzureResources, error)
+}
+
+func (a *AzurePicker) List(ctx context.Context, resourceType string) ([]prompts.Option, error) {
+	switch resourceType {
+	case prompts.ResourceContainerRegistry, prompts.ResourceKubernetesCluster, prompts.ResourceGroup, prompts.ResourceLocation:
+	default:
+		return nil, fmt.Errorf("%w: %s", prompts.ErrUnsupportedResourceType, resourceType)
+	}
+	resources, err := a.resources("")
+	if err != nil {
+		return nil, err
+	}
+
+	var options []prompts.Option
+	if resourceType == prompts.ResourceLocation {
+		locations, err := resources.Locations(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, location := range locations {
+			options = append(options, prompts.Option{Value: location.Name, Label: fmt.Sprintf("%s (%s)", location.DisplayName, location.Name)})
+		}
+		return sortedOptions(options), nil
+	}
+
+	var found []AzureResource
+	switch resourceType {
+	case prompts.ResourceContainerRegistry:
+		found, err = resources.ContainerRegistries(ctx)
+	case prompts.ResourceKubernetesCluster:
+		found, err = resources.KubernetesClusters(ctx)
+	case prompts.ResourceGroup:
+		found, err = resources.ResourceGroups(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, resource := range found {
+		// resource groups are told apart by their location, other resources by their resource group
+		detail := resource.ResourceGroup
+		if resourceType == prompts.ResourceGroup {
+			detail = resource.Location
+		}
+		options = append(options, prompts.Option{Value: resource.Name, Label: fmt.Sprintf("%s (%s)", resource.Name, detail)})
+	}
+	return sortedOptions(options), nil
+}
+
+// AWSPicker lists AWS resources of the configured aws cli profile and region
+type AWSPicker struct {
+	run commandRunner
+}
+
+func (a *AWSPicker) List(ctx context.Context, resourceType string) ([]prompts.Option, error) {
+	switch resourceType {
+	case prompts.ResourceContainerRegistry:
+		out, err := a.run(ctx, "aws", "ecr", "describe-repositories", "--query", "repositories[].repositoryUri", "--output", "json")
+		if err != nil {
+			return nil, err
+		}
+		var uris []string
+		if err := json.Unmarshal(out, &uris); err != nil {
+			return nil, fmt.Errorf("parsing aws ecr output: %w", err)
+		}
+		// every repository of an account and region shares one registry host
+		registries := make(map[string]bool)
+		var options []prompts.Option
+		for _, uri := range uris {
+			registry, _, _ := strings.Cut(uri, "/")
+			if !registries[registry] {
+				registries[registry] = true
+				options = append(options, prompts.Option{Value: registry})
+			}
+		}
+		return sortedOptions(options), nil
+	case prompts.ResourceContainerRepository:
+		out, err := a.run(ctx, "aws", "ecr", "describe-repositories", "--query", "repositories[].repositoryName", "--output", "json")
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		if err := json.Unmarshal(out, &names); err != nil {
+			return nil, fmt.Errorf("parsing aws ecr output: %w", err)
+		}
+		options := make([]prompts.Option, len(names))
+		for i, name := range names {
+			options[i] = prompts.Option{Value: name}
+		}
+		return sortedOptions(options), nil
+	case prompts.ResourceLocation:
+		out, err := a.run(ctx, "aws", "ec2", "describe-regions", "--query", "Regions[].RegionName", "--output", "json")
+		if err != nil {
+			return nil, err
+		}
+		var regions []string
+		if err := json.Unmarshal(out, &regions); err != nil {
+			return nil, fmt.Errorf("parsing aws ec2 output: %w", err)
+		}
+		options := make([]prompts.Option, len(regions))
+		for i, region := range regions {
+			options[i] = prompts.Option{Value: region}
+		}
+		return sortedOptions(options), nil
+	case prompts.ResourceKubernetesCluster:
+		out, err := a.run(ctx, "aws", "eks", "list-clusters", "--query", "clusters", "--output", "json")
+		if err != nil {
+			return nil, err
+		}
+		var clusters []string
+		if err := json.Unmarshal(out, &clusters); err != nil {
+			return nil, fmt.Errorf("parsing aws eks output: %w", err)
+		}
+		options := make([]prompts.Option, len(clusters))
+		for i, cluster := range clusters {
+			options[i] = prompts.Option{Value: cluster}
+		}
+		return sortedOptions(options), nil
+	}
+	return nil, fmt.Errorf("%w: %s", prompts.ErrUnsupportedResourceType, resourceType)
+}
+
+// GCPPicker lists Google Cloud resources of the active gcloud project
+type GCPPicker struct {
+	run commandRunner
+}
+
+func (g *GCPPicker) List(ctx context.Context, resourceType string) ([]prompts.Option, error) {
+	switch resourceType {
+	case prompts.ResourceContainerRegistry:
+		out, err := g.run(ctx, "gcloud", "artifacts", "repositories", "list", "--filter", "format=DOCKER", "--format", "json(name)")
+		if err != nil {
+			return nil, err
+		}
+		var repositories []struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(out, &repositories); err != nil {
+			return nil, fmt.Errorf("parsing gcloud artifacts output: %w", err)
+		}
+		var options []prompts.Option
+		for _, repository := range repositories {
+			// names have the form projects/PROJECT/locations/LOCATION/repositories/REPOSITORY
+			parts := strings.Split(repository.Name, "/")
+			if len(parts) != 6 {
+				continue
+			}
+			options = append(options, prompts.Option{Value: fmt.Sprintf("%s-docker.pkg.dev/%s/%s", parts[3], parts[1], parts[5])})
+		}
+		return sortedOptions(options), nil
+	case prompts.ResourceKubernetesCluster:
+		out, err := g.run(ctx, "gcloud", "container", "clusters", "list", "--format", "json(name,location)")
+		if err != nil {
+			return nil, err
+		}
+		var clusters []struct {
+			Name     string `json:"name"`
+			Location string `json:"location"`
+		}
+		if err := json.Unmarshal(out, &clusters); err != nil {
+			return nil, fmt.Errorf("parsing gcloud container output: %w", err)
+		}
+		options := make([]prompts.Option, len(clusters))
+		for i, cluster := range clusters {
+			options[i] = prompts.Option{Value: cluster.Name, Label: fmt.Sprintf("%s (%s)", cluster.Name, cluster.Location)}
+		}
+		return sortedOptions(options), nil
+	}
+	return nil, fmt.Errorf("%w: %s", prompts.ErrUnsupportedResourceType, resourceType)
+}
+
+func sortedOptions(options []prompts.Option) []prompts.Option {
+	sort.Slice(options, func(i, j int) bool { return options[i].String() < options[j].String() })
+	return options
+}
@@ -0,0 +1,102 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/prompts"
+)
+
+func fakeRunner(outputs map[string]string) commandRunner {
+	return func(_ context.Context, name string, args ...string) ([]byte, error) {
+		command := name + " " + strings.Join(args[:2], " ")
+		out, ok := outputs[command]
+		if !ok {
+			return nil, errors.New("unexpected command " + command)
+		}
+		return []byte(out), nil
+	}
+}
+
+func TestAzurePickerList(t *testing.T) {
+	picker := &AzurePicker{resources: func(string) (AzureResources, error) {
+		return &fakeAzureResources{
+			registries: []AzureResource{{Name: "zregistry", ResourceGroup: "rg"}, {Name: "aregistry", ResourceGroup: "rg"}},
+			groups:     []AzureResource{{Name: "rg", ResourceGroup: "rg", Location: "westus2"}},
+			locations:  []AzLocation{{Name: "westus2", DisplayName: "West US 2"}},
+		}, nil
+	}}
+
+	options, err := picker.List(context.Background(), prompts.ResourceContainerRegistry)
+	assert.Nil(t, err)
+	assert.Equal(t, []prompts.Option{{Value: "aregistry", Label: "aregistry (rg)"}, {Value: "zregistry", Label: "zregistry (rg)"}}, options)
+
+	options, err = picker.List(context.Background(), prompts.ResourceGroup)
+	assert.Nil(t, err)
+	assert.Equal(t, []prompts.Option{{Value: "rg", Label: "rg (westus2)"}}, options)
+
+	options, err = picker.List(context.Background(), prompts.ResourceLocation)
+	assert.Nil(t, err)
+	assert.Equal(t, []prompts.Option{{Value: "westus2", Label: "West US 2 (westus2)"}}, options)
+
+	_, err = picker.List(context.Background(), "bucket")
+	assert.True(t, errors.Is(err, prompts.ErrUnsupportedResourceType))
+}
+
+func TestAWSPickerList(t *testing.T) {
+	picker := &AWSPicker{run: fakeRunner(map[string]string{
+		"aws ecr describe-repositories": `["123.dkr.ecr.us-east-1.amazonaws.com/api", "123.dkr.ecr.us-east-1.amazonaws.com/web"]`,
+		"aws eks list-clusters":         `["prod", "dev"]`,
+		"aws ec2 describe-regions":      `["us-west-2", "us-east-1"]`,
+	})}
+
+	options, err := picker.List(context.Background(), prompts.ResourceContainerRegistry)
+	assert.Nil(t, err)
+	assert.Equal(t, []prompts.Option{{Value: "123.dkr.ecr.us-east-1.amazonaws.com"}}, options)
+
+	options, err = picker.List(context.Background(), prompts.ResourceKubernetesCluster)
+	assert.Nil(t, err)
+	assert.Equal(t, []prompts.Option{{Value: "dev"}, {Value: "prod"}}, options)
+
+	options, err = picker.List(context.Background(), prompts.ResourceLocation)
+	assert.Nil(t, err)
+	assert.Equal(t, []prompts.Option{{Value: "us-east-1"}, {Value: "us-west-2"}}, options)
+
+	picker = &AWSPicker{run: fakeRunner(map[string]string{
+		"aws ecr describe-repositories": `["web", "api"]`,
+	})}
+	options, err = picker.List(context.Background(), prompts.ResourceContainerRepository)
+	assert.Nil(t, err)
+	assert.Equal(t, []prompts.Option{{Value: "api"}, {Value: "web"}}, options)
+
+	_, err = picker.List(context.Background(), prompts.ResourceGroup)
+	assert.True(t, errors.Is(err, prompts.ErrUnsupportedResourceType))
+}
+
+func TestGCPPickerList(t *testing.T) {
+	picker := &GCPPicker{run: fakeRunner(map[string]string{
+		"gcloud artifacts repositories": `[{"name": "projects/my-project/locations/us-central1/repositories/images"}]`,
+		"gcloud container clusters":     `[{"name": "prod", "location": "us-central1"}]`,
+	})}
+
+	options, err := picker.List(context.Background(), prompts.ResourceContainerRegistry)
+	assert.Nil(t, err)
+	assert.Equal(t, []prompts.Option{{Value: "us-central1-docker.pkg.dev/my-project/images"}}, options)
+
+	options, err = picker.List(context.Background(), prompts.ResourceKubernetesCluster)
+	assert.Nil(t, err)
+	assert.Equal(t, []prompts.Option{{Value: "prod", Label: "prod (us-central1)"}}, options)
+}
+
+func TestNewResourcePicker(t *testing.T) {
+	picker, err := NewResourcePicker("AWS")
+	assert.Nil(t, err)
+	assert.IsType(t, &AWSPicker{}, picker)
+
+	_, err = NewResourcePicker("digitalocean")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,129 @@
+package readers
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/githubrepo"
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+// GitHubReader reads the files of a GitHub repository through the GitHub API, so a repo can be detected without
+// cloning it. The file tree is listed once, on first use, and the files read are cached.
+type GitHubReader struct {
+	// Repo is the repository as OWNER/REPO
+	Repo string
+	// Ref is the branch, tag or commit sha to read, the head of the default branch when empty
+	Ref string
+	// Client calls the GitHub API, githubrepo.NewClient when nil
+	Client *githubrepo.Client
+
+	treeOnce sync.Once
+	tree     []githubrepo.TreeFile
+	treeErr  error
+
+	// mu guards Client and files, since the repo is read by concurrent detections
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+var _ reporeader.RepoReader = &GitHubReader{}
+
+// GetRepoName returns the name of the repository without its owner
+func (r *GitHubReader) GetRepoName() (string, error) {
+	return path.Base(r.Repo), nil
+}
+
+// Files lists the files of the repository with their sizes
+func (r *GitHubReader) Files() ([]githubrepo.TreeFile, error) {
+	r.treeOnce.Do(func() {
+		r.tree, r.treeErr = r.client().Tree(r.Repo, r.Ref)
+	})
+	return r.tree, r.treeErr
+}
+
+func (r *GitHubReader) Exists(filePath string) bool {
+	files, err := r.Files()
+	if err != nil {
+		log.Debugf("listing the files of %s: %s", r.Repo, err)
+		return false
+	}
+	filePath = cleanRepoPath(filePath)
+	for _, file := range files {
+		if file.Path == filePath || strings.HasPrefix(file.Path, filePath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *GitHubReader) ReadFile(filePath string) ([]byte, error) {
+	filePath = cleanRepoPath(filePath)
+	r.mu.Lock()
+	content, ok := r.files[filePath]
+	r.mu.Unlock()
+	if ok {
+		return content, nil
+	}
+
+	content, err := r.client().ReadFile(r.Repo, r.Ref, filePath)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	if r.files == nil {
+		r.files = make(map[string][]byte)
+	}
+	r.files[filePath] = content
+	r.mu.Unlock()
+	return content, nil
+}
+
+func (r *GitHubReader) FindFiles(dir string, patterns []string, maxDepth int) ([]string, error) {
+	files, err := r.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	dir = cleanRepoPath(dir)
+	var found []string
+	for _, file := range files {
+		rel := file.Path
+		if dir != "." {
+			if !strings.HasPrefix(file.Path, dir+"/") {
+				continue
+			}
+			rel = strings.TrimPrefix(file.Path, dir+"/")
+		}
+		if strings.Count(rel, "/") > maxDepth {
+			continue
+		}
+		for _, pattern := range patterns {
+			if matched, err := path.Match(pattern, path.Base(file.Path)); err != nil {
+				return nil, err
+			} else if matched {
+				found = append(found, filepath.FromSlash(file.Path))
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+func (r *GitHubReader) client() *githubrepo.Client {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Client == nil {
+		r.Client = githubrepo.NewClient()
+	}
+	return r.Client
+}
+
+// cleanRepoPath turns p, relative to the repository root, into the slash separated form of the GitHub API
+func cleanRepoPath(p string) string {
+	return path.Clean(strings.TrimPrefix(filepath.ToSlash(p), "/"))
+}
@@ -0,0 +1,54 @@
+package readers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/githubrepo"
+)
+
+func TestGitHubReader(t *testing.T) {
+	var calls []string
+	client := &githubrepo.Client{Run: func(stdin []byte, args ...string) ([]byte, error) {
+		calls = append(calls, args[3])
+		switch args[3] {
+		case "repos/owner/app/git/trees/main?recursive=1":
+			return []byte(`{"tree": [
+				{"path": "go.mod", "type": "blob", "size": 11},
+				{"path": "cmd", "type": "tree"},
+				{"path": "cmd/api/main.go", "type": "blob", "size": 40},
+				{"path": "main.go", "type": "blob", "size": 40}
+			]}`), nil
+		case "repos/owner/app/contents/go.mod?ref=main":
+			return []byte(`{"type": "file", "encoding": "base64", "content": "bW9kdWxlIGFwcAo="}`), nil
+		}
+		return nil, errors.New("gh: Not Found (HTTP 404)")
+	}}
+	r := &GitHubReader{Repo: "owner/app", Ref: "main", Client: client}
+
+	name, err := r.GetRepoName()
+	assert.Nil(t, err)
+	assert.Equal(t, "app", name)
+
+	assert.True(t, r.Exists("go.mod"))
+	assert.True(t, r.Exists("./cmd"))
+	assert.False(t, r.Exists("Dockerfile"))
+
+	found, err := r.FindFiles(".", []string{"*.go"}, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"main.go"}, found)
+	found, err = r.FindFiles("cmd", []string{"main.go"}, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"cmd/api/main.go"}, found)
+
+	for i := 0; i < 2; i++ {
+		content, err := r.ReadFile("go.mod")
+		assert.Nil(t, err)
+		assert.Equal(t, "module app\n", string(content))
+	}
+	_, err = r.ReadFile("Dockerfile")
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"repos/owner/app/git/trees/main?recursive=1", "repos/owner/app/contents/go.mod?ref=main", "repos/owner/app/contents/Dockerfile?ref=main"}, calls, "the tree and files are read once")
+}
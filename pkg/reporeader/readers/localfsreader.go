@@ -7,10 +7,13 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/Azure/draft/pkg/ignore"
 	"github.com/Azure/draft/pkg/reporeader"
 )
 
 type LocalFSReader struct {
+	// Root is the directory paths are relative to, the working directory when empty
+	Root string
 }
 
 // GetRepoName returns the name of the current directory, which is an approximation of the repo name
@@ -27,12 +30,28 @@ type LocalFileFinder struct {
 	Patterns   []string
 	FoundFiles []string
 	MaxDepth   int
+	// root is trimmed from the found paths and not counted in their depth
+	root string
+	// ignore skips the paths ignored by the .gitignore and .draftignore files of root
+	ignore *ignore.Matcher
 }
 
 func (l *LocalFileFinder) walkFunc(path string, info os.DirEntry, err error) error {
 	if err != nil {
 		return err
 	}
+	if l.root != "" {
+		if path, err = filepath.Rel(l.root, path); err != nil {
+			return err
+		}
+	}
+
+	if l.ignore != nil && l.ignore.Match(path, info.IsDir()) {
+		if info.IsDir() {
+			return fs.SkipDir
+		}
+		return nil
+	}
 
 	// Skip directories that are too deep
 	if info.IsDir() && strings.Count(path, string(os.PathSeparator)) > l.MaxDepth {
@@ -58,8 +77,10 @@ func (r *LocalFSReader) FindFiles(path string, patterns []string, maxDepth int)
 	l := LocalFileFinder{
 		Patterns: patterns,
 		MaxDepth: maxDepth,
+		root:     r.Root,
+		ignore:   ignore.NewMatcher(r.Root),
 	}
-	err := filepath.WalkDir(path, l.walkFunc)
+	err := filepath.WalkDir(filepath.Join(r.Root, path), l.walkFunc)
 	if err != nil {
 		return nil, err
 	}
@@ -69,12 +90,12 @@ func (r *LocalFSReader) FindFiles(path string, patterns []string, maxDepth int)
 var _ reporeader.RepoReader = &LocalFSReader{}
 
 func (r *LocalFSReader) Exists(path string) bool {
-	if _, err := os.Stat(path); !os.IsNotExist(err) {
+	if _, err := os.Stat(filepath.Join(r.Root, path)); !os.IsNotExist(err) {
 		return true
 	}
 	return false
 }
 
 func (r *LocalFSReader) ReadFile(path string) ([]byte, error) {
-	return os.ReadFile(path)
+	return os.ReadFile(filepath.Join(r.Root, path))
 }
@@ -0,0 +1,23 @@
+package readers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalFSReaderFindFilesIgnored(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{"package.json", "web/package.json", "dist/package.json", "web/node_modules/lib/package.json"} {
+		assert.Nil(t, os.MkdirAll(filepath.Join(root, filepath.Dir(file)), 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(root, file), []byte("{}"), 0644))
+	}
+	assert.Nil(t, os.WriteFile(filepath.Join(root, ".gitignore"), []byte("dist/\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(root, ".draftignore"), []byte("node_modules/\n"), 0644))
+
+	found, err := (&LocalFSReader{Root: root}).FindFiles(".", []string{"package.json"}, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"package.json", filepath.Join("web", "package.json")}, found)
+}
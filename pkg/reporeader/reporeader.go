@@ -22,6 +22,25 @@ type VariableExtractor interface {
 	GetName() string
 }
 
+// ConfigKey is an application configuration key found in a repo's config files
+type ConfigKey struct {
+	// Name is the key as the config file spells it, such as spring.datasource.url
+	Name string
+	// EnvName is the environment variable the application's framework reads the key from
+	EnvName string
+	Value   string
+	// Group is the section of the config file the key belongs to, such as spring.datasource
+	Group string
+	// Source is the file the key was read from
+	Source string
+}
+
+// ConfigExtractor is an interface that can be implemented for discovering the keys of a repo's application config files
+type ConfigExtractor interface {
+	ReadConfig(r RepoReader) ([]ConfigKey, error)
+	GetName() string
+}
+
 // FakeRepoReader is a RepoReader that can be used for testing, and takes a list of relative file paths with their contents
 type FakeRepoReader struct {
 	Files map[string][]byte
@@ -0,0 +1,61 @@
+package reports
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "<", "&lt;", ">", "&gt;")
+
+// MarkdownTable is a table rendered in a markdown report
+type MarkdownTable struct {
+	Headers []string
+	Rows    [][]string
+}
+
+// WriteMarkdownFindings writes findings as a markdown report with one table row per finding
+func WriteMarkdownFindings(w io.Writer, title string, findings []Finding) error {
+	if _, err := fmt.Fprintf(w, "## %s\n\n", title); err != nil {
+		return err
+	}
+	if len(findings) == 0 {
+		_, err := fmt.Fprintln(w, "✅ No findings.")
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "%d finding(s)\n\n", len(findings)); err != nil {
+		return err
+	}
+	table := MarkdownTable{Headers: []string{"Level", "File", "Object", "Rule", "Message"}}
+	for _, f := range findings {
+		table.Rows = append(table.Rows, []string{f.Level, f.File, f.Object, f.RuleID, f.Message})
+	}
+	return WriteMarkdownTable(w, table)
+}
+
+// WriteMarkdownTable writes table as a GitHub flavored markdown table
+func WriteMarkdownTable(w io.Writer, table MarkdownTable) error {
+	lines := []string{markdownRow(table.Headers)}
+	separators := make([]string, len(table.Headers))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	lines = append(lines, markdownRow(separators))
+	for _, row := range table.Rows {
+		lines = append(lines, markdownRow(row))
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+func markdownRow(cells []string) string {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		// messages such as "Container <app> has no <livenessProbe>" would otherwise be rendered as html tags
+		cell = markdownEscaper.Replace(cell)
+		escaped[i] = strings.ReplaceAll(cell, "\n", "<br>")
+	}
+	return "| " + strings.Join(escaped, " | ") + " |"
+}
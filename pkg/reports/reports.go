@@ -0,0 +1,45 @@
+package reports
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Format is an output format for command results
+type Format string
+
+const (
+	FormatText     Format = "text"
+	FormatJSON     Format = "json"
+	FormatMarkdown Format = "markdown"
+	FormatSARIF    Format = "sarif"
+)
+
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+)
+
+// Finding is a single problem found in a file, such as a manifest violating a safeguard
+type Finding struct {
+	RuleID  string
+	Level   string
+	Message string
+	// File is the path of the file the finding is in, relative to the repository root when uploaded for code scanning
+	File string
+	// Object is the name of the object within the file the finding is about, if any
+	Object string
+}
+
+// ParseFormat returns the Format named by s if it is one of supported
+func ParseFormat(s string, supported ...Format) (Format, error) {
+	names := make([]string, len(supported))
+	for i, f := range supported {
+		if strings.EqualFold(s, string(f)) {
+			return f, nil
+		}
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("unsupported format %q, must be one of: %s", s, strings.Join(names, ", "))
+}
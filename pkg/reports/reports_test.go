@@ -0,0 +1,70 @@
+package reports
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testFindings = []Finding{
+	{RuleID: "K8sAzureV2ContainerEnforceProbes", Level: LevelError, Message: "Container <app> has no <livenessProbe>", File: "manifests/deployment.yaml", Object: "app"},
+	{RuleID: "K8sAzureV1AntiAffinityRules", Level: LevelWarning, Message: "a | b", File: "manifests/deployment.yaml"},
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("SARIF", FormatText, FormatSARIF)
+	assert.Nil(t, err)
+	assert.Equal(t, FormatSARIF, f)
+
+	_, err = ParseFormat("markdown", FormatText, FormatSARIF)
+	assert.ErrorContains(t, err, "text, sarif")
+}
+
+func TestWriteSARIF(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.Nil(t, WriteSARIF(buf, "v1.0.0", testFindings))
+
+	var sarif sarifLog
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &sarif))
+	assert.Equal(t, "2.1.0", sarif.Version)
+	assert.Len(t, sarif.Runs, 1)
+
+	driver := sarif.Runs[0].Tool.Driver
+	assert.Equal(t, "v1.0.0", driver.Version)
+	assert.Equal(t, []sarifRule{
+		{ID: "K8sAzureV1AntiAffinityRules", ShortDescription: sarifMessage{Text: "K8sAzureV1AntiAffinityRules"}},
+		{ID: "K8sAzureV2ContainerEnforceProbes", ShortDescription: sarifMessage{Text: "K8sAzureV2ContainerEnforceProbes"}},
+	}, driver.Rules)
+
+	results := sarif.Runs[0].Results
+	assert.Len(t, results, 2)
+	assert.Equal(t, "error", results[0].Level)
+	assert.Equal(t, "manifests/deployment.yaml", results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 1, results[0].Locations[0].PhysicalLocation.Region.StartLine)
+	assert.Equal(t, []sarifLogicalLocation{{Name: "app"}}, results[0].Locations[0].LogicalLocations)
+	assert.Nil(t, results[1].Locations[0].LogicalLocations)
+
+	buf.Reset()
+	assert.Nil(t, WriteSARIF(buf, "v1.0.0", nil))
+	assert.Contains(t, buf.String(), `"results": []`)
+}
+
+func TestWriteMarkdownFindings(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.Nil(t, WriteMarkdownFindings(buf, "Results", testFindings))
+	assert.Equal(t, `## Results
+
+2 finding(s)
+
+| Level | File | Object | Rule | Message |
+| --- | --- | --- | --- | --- |
+| error | manifests/deployment.yaml | app | K8sAzureV2ContainerEnforceProbes | Container &lt;app&gt; has no &lt;livenessProbe&gt; |
+| warning | manifests/deployment.yaml |  | K8sAzureV1AntiAffinityRules | a \| b |
+`, buf.String())
+
+	buf.Reset()
+	assert.Nil(t, WriteMarkdownFindings(buf, "Results", nil))
+	assert.Equal(t, "## Results\n\n✅ No findings.\n", buf.String())
+}
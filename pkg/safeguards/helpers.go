@@ -245,32 +245,48 @@ func GetManifestFiles(p string) ([]ManifestFile, error) {
 
 // getObjectViolations executes validation on manifests based on loaded constraint templates and returns a map of manifest name to list of objectViolations
 func getObjectViolations(ctx context.Context, c *constraintclient.Client, objects []*unstructured.Unstructured) (map[string][]string, error) {
+	violations, err := getViolations(ctx, c, objects)
+	return groupObjectViolations(violations), err
+}
+
+// groupObjectViolations returns a map of object name to the messages of its violations
+func groupObjectViolations(violations []Violation) map[string][]string {
+	var results = make(map[string][]string) // map of object name to slice of objectViolations
+	for _, v := range violations {
+		results[v.Object] = append(results[v.Object], v.Message)
+	}
+	return results
+}
+
+// getViolations executes validation on manifests based on loaded constraint templates and returns every violation
+// along with the object and constraint it belongs to
+func getViolations(ctx context.Context, c *constraintclient.Client, objects []*unstructured.Unstructured) ([]Violation, error) {
 	// Review makes sure the provided object satisfies all stored constraints.
 	// On error, the responses return value will still be populated so that
 	// partial results can be analyzed.
 
-	var results = make(map[string][]string) // map of object name to slice of objectViolations
+	var violations []Violation
 
 	for _, o := range objects {
-		objectViolations := []string{}
 		log.Debugf("Reviewing %s...", o.GetName())
 		res, err := c.Review(ctx, o)
 		if err != nil {
-			return results, fmt.Errorf("could not review objects: %w", err)
+			return violations, fmt.Errorf("could not review objects: %w", err)
 		}
 
 		for _, v := range res.ByTarget {
 			for _, result := range v.Results {
-				if result.Msg != "" {
-					objectViolations = append(objectViolations, result.Msg)
+				if result.Msg == "" {
+					continue
+				}
+				violation := Violation{Object: o.GetName(), Message: result.Msg}
+				if result.Constraint != nil {
+					violation.Constraint = result.Constraint.GetKind()
 				}
+				violations = append(violations, violation)
 			}
 		}
-
-		if len(objectViolations) > 0 {
-			results[o.GetName()] = objectViolations
-		}
 	}
 
-	return results, nil
+	return violations, nil
 }
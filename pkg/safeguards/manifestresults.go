@@ -81,18 +81,18 @@ func GetManifestResults(ctx context.Context, manifestFiles []ManifestFile) ([]Ma
 	}
 
 	for _, m := range manifestFiles {
-		var objectViolations map[string][]string
-
 		// validation of deployment manifest with constraints, templates loaded
-		objectViolations, err = getObjectViolations(ctx, c, manifestMap[m.Name])
+		violations, err := getViolations(ctx, c, manifestMap[m.Name])
 		if err != nil {
 			log.Errorf("validating objects: %s", err.Error())
 			return manifestResults, err
 		}
+		objectViolations := groupObjectViolations(violations)
 		manifestResults = append(manifestResults, ManifestResult{
 			Name:             m.Name,
 			ObjectViolations: objectViolations,
 			ViolationsCount:  len(objectViolations),
+			Violations:       violations,
 		})
 	}
 
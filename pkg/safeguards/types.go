@@ -22,4 +22,11 @@ type ManifestResult struct {
 	Name             string              // the name of the manifest
 	ObjectViolations map[string][]string // a map of string object names to slice of string objectViolations
 	ViolationsCount  int                 // a count of how many violations are associated with this manifest
+	Violations       []Violation         // every violation with the object and constraint it came from
+}
+
+type Violation struct {
+	Object     string // the name of the object that violates the constraint
+	Constraint string // the kind of the violated constraint
+	Message    string
 }
@@ -0,0 +1,176 @@
+// Package scaffold generates the skeleton of new language and deployment packs, with the golden files their rendered
+// output is tested against, for contributors to fill in.
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/deployments"
+	"github.com/Azure/draft/pkg/languages"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/pkg/yamlfmt"
+)
+
+// Directories of the template directory holding the language and deployment packs
+const (
+	LanguagesDir   = "dockerfiles"
+	DeploymentsDir = "deployments"
+)
+
+var packNameRegex = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
+
+// ValidateName checks that name can name a pack, a directory of the template directory also used as its Go identifier
+func ValidateName(name string) error {
+	if !packNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid pack name %q: use lower case letters, digits and dashes, starting with a letter", name)
+	}
+	return nil
+}
+
+// DisplayName returns the default display name of the pack name, such as "My Lang" for my-lang
+func DisplayName(name string) string {
+	words := strings.Split(name, "-")
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// packData is what the pack skeletons are rendered with
+type packData struct {
+	Name        string
+	DisplayName string
+	// Ident is the Go identifier of the pack, such as MyLang for my-lang
+	Ident   string
+	Package string
+}
+
+func newPackData(name, displayName string) packData {
+	return packData{
+		Name:        name,
+		DisplayName: displayName,
+		Ident:       strings.ReplaceAll(DisplayName(name), " ", ""),
+	}
+}
+
+// LanguagePack returns the files of a language pack skeleton, keyed by their path in the template directory: a
+// draft.yaml with typed PORT and VERSION variables and a Dockerfile and .dockerignore using them
+func LanguagePack(name, displayName string) (map[string][]byte, error) {
+	dir := path.Join(LanguagesDir, name)
+	return renderFiles(newPackData(name, displayName), map[string]string{
+		path.Join(dir, "draft.yaml"):    languageConfig,
+		path.Join(dir, "Dockerfile"):    dockerfile,
+		path.Join(dir, ".dockerignore"): dockerignore,
+	})
+}
+
+// DeploymentPack returns the files of a deployment pack skeleton, keyed by their path in the template directory: a
+// draft.yaml with the variables draft create prompts for and a Deployment and Service using them
+func DeploymentPack(name, displayName string) (map[string][]byte, error) {
+	dir := path.Join(DeploymentsDir, name)
+	return renderFiles(newPackData(name, displayName), map[string]string{
+		path.Join(dir, "draft.yaml"):            deploymentConfig,
+		path.Join(dir, name, "deployment.yaml"): deploymentManifest,
+		path.Join(dir, name, "service.yaml"):    serviceManifest,
+	})
+}
+
+// Extractor returns the Go source of a stub reporeader.VariableExtractor for the language pack name in package pkg,
+// and of its test, keyed by file name
+func Extractor(name, pkg string) (map[string][]byte, error) {
+	data := newPackData(name, DisplayName(name))
+	data.Package = pkg
+	fileName := strings.ReplaceAll(name, "-", "")
+	files, err := renderFiles(data, map[string]string{
+		fileName + ".go":      extractorSource,
+		fileName + "_test.go": extractorTestSource,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for fileName, content := range files {
+		if files[fileName], err = format.Source(content); err != nil {
+			return nil, fmt.Errorf("formatting %s: %w", fileName, err)
+		}
+	}
+	return files, nil
+}
+
+func renderFiles(data packData, templates map[string]string) (map[string][]byte, error) {
+	files := make(map[string][]byte, len(templates))
+	for filePath, text := range templates {
+		tmpl, err := template.New(filePath).Delims("[[", "]]").Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("rendering %s: %w", filePath, err)
+		}
+		files[filePath] = buf.Bytes()
+	}
+	return files, nil
+}
+
+// GoldenInputs returns the variable values golden files are rendered with: each variable's default, or its first
+// example value when it has no default
+func GoldenInputs(draftConfig *config.DraftConfig) map[string]string {
+	inputs := make(map[string]string)
+	for _, variable := range draftConfig.Variables {
+		if len(variable.ExampleValues) > 0 {
+			inputs[variable.Name] = variable.ExampleValues[0]
+		}
+	}
+	for _, variableDefault := range draftConfig.VariableDefaults {
+		if variableDefault.Value != "" {
+			inputs[variableDefault.Name] = variableDefault.Value
+		}
+	}
+	return inputs
+}
+
+// RenderLanguageGolden renders the language pack name of the template directory fsys with its GoldenInputs, keyed by
+// the path of the generated files
+func RenderLanguageGolden(fsys fs.FS, name string) (map[string][]byte, error) {
+	l, err := languages.CreateLanguagesFromFS(fsys, "")
+	if err != nil {
+		return nil, err
+	}
+	draftConfig := l.GetConfig(name)
+	if draftConfig == nil {
+		return nil, fmt.Errorf("language pack %s not found", name)
+	}
+	w := &writers.FileMapWriter{FileMap: make(map[string][]byte)}
+	if err := l.CreateDockerfileForLanguage(name, GoldenInputs(draftConfig), w); err != nil {
+		return nil, err
+	}
+	return w.FileMap, nil
+}
+
+// RenderDeploymentGolden renders the deployment pack name of the template directory fsys with its GoldenInputs, keyed
+// by the path of the generated files
+func RenderDeploymentGolden(fsys fs.FS, name string) (map[string][]byte, error) {
+	d, err := deployments.CreateDeploymentsFromFS(fsys, "")
+	if err != nil {
+		return nil, err
+	}
+	draftConfig, err := d.GetConfig(name)
+	if err != nil {
+		return nil, err
+	}
+	w := &writers.FileMapWriter{FileMap: make(map[string][]byte)}
+	// the yaml files are normalized as the commands write them
+	normalized := &writers.PostRenderWriter{Writer: w, Mutate: yamlfmt.Normalize}
+	if err := d.CopyDeploymentFiles(name, GoldenInputs(draftConfig), normalized); err != nil {
+		return nil, err
+	}
+	return w.FileMap, nil
+}
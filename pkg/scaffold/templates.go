@@ -0,0 +1,189 @@
+package scaffold
+
+// The skeletons are text/templates delimited by [[ ]] so the {{VARIABLE}} placeholders of the packs are left as is
+
+const languageConfig = `language: [[.Name]]
+version: "1.0.0"
+displayName: [[.DisplayName]]
+nameOverrides:
+  - path: "dockerignore"
+    prefix: "."
+variables:
+  - name: "PORT"
+    description: "the port exposed in the application"
+    type: port
+  - name: "VERSION"
+    description: "the version of [[.DisplayName]] used by the application"
+    exampleValues: ["latest"]
+  - name: "HARDENED"
+    description: "whether to build a hardened image, running as a non-root user with a read-only root file system"
+    type: "bool"
+    stage: "advanced"
+variableDefaults:
+  - name: "VERSION"
+    value: "latest"
+  - name: "PORT"
+    value: "80"
+  - name: "HARDENED"
+    value: "true"
+`
+
+const dockerfile = `# TODO: use the base image and build steps of [[.DisplayName]] applications
+FROM [[.Name]]:{{VERSION}} AS runtime
+WORKDIR /app
+COPY . .
+
+FROM runtime AS hardened-false
+
+# TODO: run on a distroless base if [[.DisplayName]] has one, writing only to /tmp
+FROM runtime AS hardened-true
+USER 65532:65532
+
+FROM hardened-{{HARDENED}}
+ENV PORT={{PORT}}
+EXPOSE {{PORT}}
+
+# TODO: start the application
+CMD ["./app"]
+`
+
+const dockerignore = `Dockerfile
+charts/
+manifests/
+`
+
+const deploymentConfig = `version: "1.0.0"
+variables:
+  - name: "PORT"
+    description: "the port exposed in the application"
+    type: "port"
+  - name: "APPNAME"
+    description: "the name of the application"
+    exampleValues: ["my-app"]
+  - name: "NAMESPACE"
+    description: "the namespace to place new resources in"
+  - name: "IMAGENAME"
+    description: "the name of the image to use in the deployment"
+    exampleValues: ["myregistry.azurecr.io/my-app"]
+  - name: "IMAGETAG"
+    description: "the tag of the image to use in the deployment"
+  - name: "GENERATORLABEL"
+    description: "the label to identify who generated the resource"
+variableDefaults:
+  - name: "PORT"
+    value: 80
+  - name: "NAMESPACE"
+    value: default
+  - name: "IMAGETAG"
+    value: "latest"
+  - name: "GENERATORLABEL"
+    value: "draft"
+`
+
+const deploymentManifest = `# TODO: adapt the Deployment to what [[.DisplayName]] deploys
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{APPNAME}}
+  namespace: {{NAMESPACE}}
+  labels:
+    app: {{APPNAME}}
+    kubernetes.azure.com/generator: {{GENERATORLABEL}}
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: {{APPNAME}}
+  template:
+    metadata:
+      labels:
+        app: {{APPNAME}}
+    spec:
+      containers:
+        - name: {{APPNAME}}
+          image: {{IMAGENAME}}:{{IMAGETAG}}
+          ports:
+            - containerPort: {{PORT}}
+`
+
+const serviceManifest = `apiVersion: v1
+kind: Service
+metadata:
+  name: {{APPNAME}}
+  namespace: {{NAMESPACE}}
+  labels:
+    kubernetes.azure.com/generator: {{GENERATORLABEL}}
+spec:
+  type: ClusterIP
+  selector:
+    app: {{APPNAME}}
+  ports:
+    - protocol: TCP
+      port: 80
+      targetPort: {{PORT}}
+`
+
+const extractorSource = `package [[.Package]]
+
+import (
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+// [[.Ident]]Extractor reads the defaults of the [[.Name]] pack's variables from the repo
+type [[.Ident]]Extractor struct {
+}
+
+// GetName implements reporeader.VariableExtractor
+func (*[[.Ident]]Extractor) GetName() string {
+	return "[[.Name]]"
+}
+
+// MatchesLanguage implements reporeader.VariableExtractor
+func (*[[.Ident]]Extractor) MatchesLanguage(lowerlang string) bool {
+	return lowerlang == "[[.Name]]"
+}
+
+// ReadDefaults implements reporeader.VariableExtractor
+func (*[[.Ident]]Extractor) ReadDefaults(r reporeader.RepoReader) (map[string]string, error) {
+	extractedValues := make(map[string]string)
+	// TODO: read VERSION and the pack's other variables from the repo's files, with r.Exists, r.ReadFile and r.FindFiles
+	return extractedValues, nil
+}
+`
+
+const extractorTestSource = `package [[.Package]]
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/draft/pkg/reporeader"
+)
+
+func Test[[.Ident]]Extractor_ReadDefaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string][]byte
+		want  map[string]string
+	}{
+		// TODO: add the repos the extractor reads variables from
+		{
+			name:  "empty repo",
+			files: map[string][]byte{},
+			want:  map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&[[.Ident]]Extractor{}).ReadDefaults(reporeader.FakeRepoReader{Files: tt.files})
+			if err != nil {
+				t.Errorf("ReadDefaults() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadDefaults() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+`
@@ -0,0 +1,160 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"filippo.io/age"
+)
+
+const (
+	// EncryptedPrefix marks a variable value that is encrypted with age
+	EncryptedPrefix = "age:"
+	// IdentityEnv is the environment variable holding the path of the age identity file when no flag is passed
+	IdentityEnv = "DRAFT_AGE_IDENTITY"
+)
+
+// IdentityPath returns flagValue, or the path in IdentityEnv when flagValue is empty
+func IdentityPath(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	return os.Getenv(IdentityEnv)
+}
+
+// LoadIdentity reads the first X25519 identity from an age identity file, such as one created by age-keygen
+func LoadIdentity(path string) (*age.X25519Identity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening age identity file: %w", err)
+	}
+	defer f.Close()
+
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("parsing age identity file %s: %w", path, err)
+	}
+	for _, identity := range identities {
+		if x25519, ok := identity.(*age.X25519Identity); ok {
+			return x25519, nil
+		}
+	}
+	return nil, fmt.Errorf("no X25519 identity found in %s", path)
+}
+
+// IsEncrypted reports whether value was encrypted by Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, EncryptedPrefix)
+}
+
+// Encrypt encrypts value to recipient and returns it base64 encoded with EncryptedPrefix, so it can be stored in
+// json, yaml and toml files
+func Encrypt(value string, recipient age.Recipient) (string, error) {
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipient)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.WriteString(w, value); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return EncryptedPrefix + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// Decrypt decrypts a value returned by Encrypt
+func Decrypt(value string, identity age.Identity) (string, error) {
+	encoded, ok := strings.CutPrefix(value, EncryptedPrefix)
+	if !ok {
+		return "", errors.New("value is not encrypted")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("decoding encrypted value: %w", err)
+	}
+	r, err := age.Decrypt(bytes.NewReader(ciphertext), identity)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// ProtectValues prepares values for saving to disk. The secret variables in names are removed when redact is set and
+// encrypted with the age identity at identityPath otherwise. It fails rather than leave a secret in plain text.
+func ProtectValues(values map[string]string, names []string, identityPath string, redact bool) error {
+	var secretNames []string
+	for _, name := range names {
+		if value, ok := values[name]; ok && value != "" && !IsEncrypted(value) {
+			secretNames = append(secretNames, name)
+		}
+	}
+	if len(secretNames) == 0 {
+		return nil
+	}
+	sort.Strings(secretNames)
+
+	if redact {
+		for _, name := range secretNames {
+			delete(values, name)
+		}
+		return nil
+	}
+	if identityPath == "" {
+		return fmt.Errorf("secret variables %s would be saved in plain text, pass an age identity with --secrets-identity or %s, or use --redact", strings.Join(secretNames, ", "), IdentityEnv)
+	}
+
+	identity, err := LoadIdentity(identityPath)
+	if err != nil {
+		return err
+	}
+	for _, name := range secretNames {
+		encrypted, err := Encrypt(values[name], identity.Recipient())
+		if err != nil {
+			return fmt.Errorf("encrypting %s: %w", name, err)
+		}
+		values[name] = encrypted
+	}
+	return nil
+}
+
+// RevealValues decrypts every encrypted value in values in place with the age identity at identityPath
+func RevealValues(values map[string]string, identityPath string) error {
+	var encryptedNames []string
+	for name, value := range values {
+		if IsEncrypted(value) {
+			encryptedNames = append(encryptedNames, name)
+		}
+	}
+	if len(encryptedNames) == 0 {
+		return nil
+	}
+	sort.Strings(encryptedNames)
+
+	if identityPath == "" {
+		return fmt.Errorf("variables %s are encrypted, pass the age identity they were encrypted with using --secrets-identity or %s", strings.Join(encryptedNames, ", "), IdentityEnv)
+	}
+	identity, err := LoadIdentity(identityPath)
+	if err != nil {
+		return err
+	}
+	for _, name := range encryptedNames {
+		decrypted, err := Decrypt(values[name], identity)
+		if err != nil {
+			return fmt.Errorf("decrypting %s: %w", name, err)
+		}
+		values[name] = decrypted
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeIdentity(t *testing.T) string {
+	identity, err := age.GenerateX25519Identity()
+	assert.Nil(t, err)
+	identityPath := filepath.Join(t.TempDir(), "key.txt")
+	assert.Nil(t, os.WriteFile(identityPath, []byte("# created: test\n"+identity.String()+"\n"), 0600))
+	return identityPath
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	assert.Nil(t, err)
+
+	encrypted, err := Encrypt("hunter2", identity.Recipient())
+	assert.Nil(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.NotContains(t, encrypted, "hunter2")
+
+	decrypted, err := Decrypt(encrypted, identity)
+	assert.Nil(t, err)
+	assert.Equal(t, "hunter2", decrypted)
+
+	other, err := age.GenerateX25519Identity()
+	assert.Nil(t, err)
+	_, err = Decrypt(encrypted, other)
+	assert.NotNil(t, err)
+	_, err = Decrypt("hunter2", identity)
+	assert.NotNil(t, err)
+}
+
+func TestProtectAndRevealValues(t *testing.T) {
+	identityPath := writeIdentity(t)
+	newValues := func() map[string]string {
+		return map[string]string{"APPNAME": "app", "DBPASSWORD": "hunter2", "APIKEY": ""}
+	}
+	names := []string{"DBPASSWORD", "APIKEY"}
+
+	values := newValues()
+	assert.NotNil(t, ProtectValues(values, names, "", false), "secrets must not be saved in plain text")
+
+	values = newValues()
+	assert.Nil(t, ProtectValues(values, names, "", true))
+	assert.Equal(t, map[string]string{"APPNAME": "app", "APIKEY": ""}, values)
+
+	values = newValues()
+	assert.Nil(t, ProtectValues(values, names, identityPath, false))
+	assert.True(t, IsEncrypted(values["DBPASSWORD"]))
+	assert.Equal(t, "app", values["APPNAME"])
+	assert.Equal(t, "", values["APIKEY"])
+
+	assert.NotNil(t, RevealValues(values, ""))
+	assert.Nil(t, RevealValues(values, identityPath))
+	assert.Equal(t, newValues(), values)
+}
+
+func TestIdentityPath(t *testing.T) {
+	t.Setenv(IdentityEnv, "env.txt")
+	assert.Equal(t, "flag.txt", IdentityPath("flag.txt"))
+	assert.Equal(t, "env.txt", IdentityPath(""))
+}
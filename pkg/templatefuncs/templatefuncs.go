@@ -0,0 +1,148 @@
+// Package templatefuncs holds the helper functions available to pack templates for values that break when built by
+// string concatenation, such as image references with a registry port or a digest and the urls of services.
+package templatefuncs
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"text/template"
+)
+
+// ClusterDomain is the default DNS domain of kubernetes clusters
+const ClusterDomain = "cluster.local"
+
+// DefaultRegistry is the registry of image references that don't name one
+const DefaultRegistry = "docker.io"
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+	"grpc":  "80",
+	"grpcs": "443",
+}
+
+// FuncMap returns the helper functions by the names templates call them with
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"portFromURL":   PortFromURL,
+		"serviceDNS":    ServiceDNS,
+		"serviceURL":    ServiceURL,
+		"parseImageRef": ParseImageRef,
+		"joinImageRef":  JoinImageRef,
+	}
+}
+
+// PortFromURL returns the port of rawURL, or the default port of its scheme when it doesn't set one
+func PortFromURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url %q: %w", rawURL, err)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid url %q: missing host", rawURL)
+	}
+	if port := u.Port(); port != "" {
+		return port, nil
+	}
+	if port, ok := defaultPorts[strings.ToLower(u.Scheme)]; ok {
+		return port, nil
+	}
+	return "", fmt.Errorf("url %q has no port and scheme %q has no default port", rawURL, u.Scheme)
+}
+
+// ServiceDNS returns the fully qualified in-cluster DNS name of a service
+func ServiceDNS(service, namespace string) string {
+	if namespace == "" {
+		namespace = "default"
+	}
+	return fmt.Sprintf("%s.%s.svc.%s", service, namespace, ClusterDomain)
+}
+
+// ServiceURL returns the in-cluster url of a service, leaving out the port when it is the default of scheme
+func ServiceURL(scheme, service, namespace, port string) string {
+	host := ServiceDNS(service, namespace)
+	if port != "" && port != defaultPorts[strings.ToLower(scheme)] {
+		host = net.JoinHostPort(host, port)
+	}
+	return (&url.URL{Scheme: scheme, Host: host}).String()
+}
+
+// ImageRef is a container image reference split into its parts
+type ImageRef struct {
+	// Registry is the registry host, with its port if any. Empty when the reference doesn't name one.
+	Registry   string
+	Repository string
+	Tag        string
+	// Digest is the content digest including its algorithm, such as sha256:...
+	Digest string
+}
+
+// ParseImageRef splits ref into its registry, repository, tag and digest. The first path component of ref is the
+// registry when it contains a dot or a port or is localhost, like the docker cli treats it.
+func ParseImageRef(ref string) (ImageRef, error) {
+	var image ImageRef
+	rest := ref
+	if i := strings.Index(rest, "@"); i >= 0 {
+		image.Digest = rest[i+1:]
+		rest = rest[:i]
+		if algorithm, hex, ok := strings.Cut(image.Digest, ":"); !ok || algorithm == "" || hex == "" {
+			return ImageRef{}, fmt.Errorf("invalid image reference %q: invalid digest %q", ref, image.Digest)
+		}
+	}
+	if first, remainder, ok := strings.Cut(rest, "/"); ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		image.Registry = first
+		rest = remainder
+	}
+	// a colon after the last slash separates the tag, any other colon was the registry port
+	if i := strings.LastIndex(rest, ":"); i > strings.LastIndex(rest, "/") {
+		image.Tag = rest[i+1:]
+		rest = rest[:i]
+		if image.Tag == "" {
+			return ImageRef{}, fmt.Errorf("invalid image reference %q: empty tag", ref)
+		}
+	}
+	if rest == "" || strings.HasPrefix(rest, "/") || strings.HasSuffix(rest, "/") || strings.Contains(rest, "//") {
+		return ImageRef{}, fmt.Errorf("invalid image reference %q: invalid repository %q", ref, rest)
+	}
+	image.Repository = rest
+	return image, nil
+}
+
+// String joins the parts of the reference back together
+func (r ImageRef) String() string {
+	ref := r.Repository
+	if r.Registry != "" {
+		ref = strings.TrimSuffix(r.Registry, "/") + "/" + ref
+	}
+	if r.Tag != "" {
+		ref += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		ref += "@" + r.Digest
+	}
+	return ref
+}
+
+// FullRegistry returns the registry of the reference, DefaultRegistry when it doesn't name one
+func (r ImageRef) FullRegistry() string {
+	if r.Registry == "" {
+		return DefaultRegistry
+	}
+	return r.Registry
+}
+
+// JoinImageRef builds an image reference from a registry, repository and tag or digest. A version starting with a
+// digest algorithm such as sha256: is joined as a digest, and an empty version leaves the reference untagged.
+func JoinImageRef(registry, repository, version string) string {
+	r := ImageRef{Registry: registry, Repository: strings.Trim(repository, "/")}
+	if algorithm, _, ok := strings.Cut(version, ":"); ok && strings.HasPrefix(algorithm, "sha") {
+		r.Digest = version
+	} else {
+		r.Tag = version
+	}
+	return r.String()
+}
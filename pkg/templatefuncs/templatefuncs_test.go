@@ -0,0 +1,74 @@
+package templatefuncs
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPortFromURL(t *testing.T) {
+	for rawURL, want := range map[string]string{
+		"http://myapp.default.svc:8080/health": "8080",
+		"https://example.com/path":             "443",
+		"http://[::1]:3000":                    "3000",
+		"grpc://backend":                       "80",
+	} {
+		got, err := PortFromURL(rawURL)
+		assert.Nil(t, err, rawURL)
+		assert.Equal(t, want, got, rawURL)
+	}
+
+	_, err := PortFromURL("tcp://db")
+	assert.ErrorContains(t, err, "has no default port")
+	_, err = PortFromURL("not a url")
+	assert.ErrorContains(t, err, "missing host")
+}
+
+func TestServiceURL(t *testing.T) {
+	assert.Equal(t, "myapp.prod.svc.cluster.local", ServiceDNS("myapp", "prod"))
+	assert.Equal(t, "myapp.default.svc.cluster.local", ServiceDNS("myapp", ""))
+	assert.Equal(t, "http://myapp.prod.svc.cluster.local:8080", ServiceURL("http", "myapp", "prod", "8080"))
+	assert.Equal(t, "https://myapp.prod.svc.cluster.local", ServiceURL("https", "myapp", "prod", "443"))
+}
+
+func TestParseImageRef(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	for ref, want := range map[string]ImageRef{
+		"nginx":                                {Repository: "nginx"},
+		"library/nginx:1.25":                   {Repository: "library/nginx", Tag: "1.25"},
+		"myacr.azurecr.io/app:v1":              {Registry: "myacr.azurecr.io", Repository: "app", Tag: "v1"},
+		"localhost:5000/team/app":              {Registry: "localhost:5000", Repository: "team/app"},
+		"registry.local:5000/app:v2@" + digest: {Registry: "registry.local:5000", Repository: "app", Tag: "v2", Digest: digest},
+		"myacr.azurecr.io/team/app@" + digest:  {Registry: "myacr.azurecr.io", Repository: "team/app", Digest: digest},
+	} {
+		got, err := ParseImageRef(ref)
+		assert.Nil(t, err, ref)
+		assert.Equal(t, want, got, ref)
+		assert.Equal(t, ref, got.String(), ref)
+	}
+
+	image, _ := ParseImageRef("nginx")
+	assert.Equal(t, DefaultRegistry, image.FullRegistry())
+
+	for _, ref := range []string{"", "app:", "app@sha256", "myacr.azurecr.io/", "a//b"} {
+		_, err := ParseImageRef(ref)
+		assert.ErrorContains(t, err, "invalid image reference", ref)
+	}
+}
+
+func TestJoinImageRef(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("b", 64)
+	assert.Equal(t, "localhost:5000/app:v1", JoinImageRef("localhost:5000", "app", "v1"))
+	assert.Equal(t, "myacr.azurecr.io/app@"+digest, JoinImageRef("myacr.azurecr.io/", "/app", digest))
+	assert.Equal(t, "app", JoinImageRef("", "app", ""))
+}
+
+func TestFuncMap(t *testing.T) {
+	tmpl := template.Must(template.New("").Funcs(FuncMap()).Parse(
+		`{{ (parseImageRef .Image).Registry }} {{ joinImageRef "localhost:5000" "app" "v1" }} {{ portFromURL .URL }}`))
+	var out strings.Builder
+	assert.Nil(t, tmpl.Execute(&out, map[string]string{"Image": "myacr.azurecr.io:443/app:v1", "URL": "https://example.com"}))
+	assert.Equal(t, "myacr.azurecr.io:443 localhost:5000/app:v1 443", out.String())
+}
@@ -0,0 +1,320 @@
+// Package templatelint checks that the draft.yaml of each pack of a template directory and its template files agree:
+// every declared variable is used by a file, and every placeholder of a file is declared, so that drift between the
+// two is caught before a pack ships instead of when draft create leaves a placeholder unsubstituted.
+package templatelint
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Azure/draft/pkg/addons"
+	"github.com/Azure/draft/pkg/config"
+	"github.com/Azure/draft/pkg/deployments"
+	"github.com/Azure/draft/pkg/languages"
+	"github.com/Azure/draft/pkg/templaterender"
+	"github.com/Azure/draft/pkg/workflows"
+)
+
+// Kinds of problems
+const (
+	// Unused is a variable declared in draft.yaml that no template file or other variable refers to
+	Unused = "unused"
+	// Undeclared is a placeholder or template field of a file that draft.yaml doesn't declare
+	Undeclared = "undeclared"
+	// Render is a file that fails to render with the pack's defaults
+	Render = "render"
+)
+
+// configFile is the file declaring the variables of a pack
+const configFile = "draft.yaml"
+
+// placeholderRegex matches the {{VARIABLE}} placeholders of the files, not the helm template actions they generate
+var placeholderRegex = regexp.MustCompile(`{{([A-Za-z_][A-Za-z0-9_-]*)}}`)
+
+// Problem is a disagreement between the draft.yaml of a pack and its files
+type Problem struct {
+	// Pack is the directory of the pack in the template directory, such as deployments/helm
+	Pack     string
+	Kind     string
+	Variable string
+	// File and Line locate the placeholder of an Undeclared or Render problem, relative to the pack
+	File    string
+	Line    int
+	Message string
+}
+
+func (p Problem) String() string {
+	location := p.Pack
+	if p.File != "" {
+		location = path.Join(p.Pack, p.File)
+		if p.Line > 0 {
+			location = fmt.Sprintf("%s:%d", location, p.Line)
+		}
+	}
+	switch p.Kind {
+	case Unused:
+		return fmt.Sprintf("%s: variable %s is declared in %s but never used", location, p.Variable, configFile)
+	case Undeclared:
+		return fmt.Sprintf("%s: variable %s is used but not declared in %s", location, p.Variable, configFile)
+	}
+	return fmt.Sprintf("%s: %s", location, p.Message)
+}
+
+// Options tune which variables are reported
+type Options struct {
+	// Provided are variables set by draft itself rather than declared by packs, never reported as undeclared
+	Provided []string
+	// Consumed are variables read by draft itself rather than by the files of packs, never reported as unused
+	Consumed []string
+}
+
+// DraftOptions returns the Options of the packs of draft, whose commands set and read some of their variables
+func DraftOptions() Options {
+	var opts Options
+	opts.Provided = append(opts.Provided, deployments.ProvidedVariables...)
+	opts.Provided = append(opts.Provided, languages.ProvidedVariables...)
+	opts.Provided = append(opts.Provided, workflows.ProvidedVariables...)
+	opts.Consumed = append(opts.Consumed, deployments.ConsumedVariables...)
+	opts.Consumed = append(opts.Consumed, languages.ConsumedVariables...)
+	return opts
+}
+
+// LintDir lints every pack of the template directory fsys, a directory holding a draft.yaml, in path order. The
+// name@version snapshots of older versions of packs are skipped, since they can't change.
+func LintDir(fsys fs.FS, opts Options) ([]Problem, error) {
+	var problems []Problem
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if strings.Contains(d.Name(), "@") {
+			return fs.SkipDir
+		}
+		if _, err := fs.Stat(fsys, path.Join(p, configFile)); err != nil {
+			return nil
+		}
+		packProblems, err := Lint(fsys, p, opts)
+		if err != nil {
+			return err
+		}
+		problems = append(problems, packProblems...)
+		return fs.SkipDir
+	})
+	return problems, err
+}
+
+// Lint lints the pack in dir of fsys. It reports the variables of its draft.yaml that neither its files, its optional
+// files nor the defaults of other variables refer to, the {{VARIABLE}} placeholders and template fields of its files
+// that draft.yaml doesn't declare, and the files that fail to render with the defaults of the pack.
+func Lint(fsys fs.FS, dir string, opts Options) ([]Problem, error) {
+	content, err := fs.ReadFile(fsys, path.Join(dir, configFile))
+	if err != nil {
+		return nil, err
+	}
+	// the draft.yaml of addons also declares the variables read from the deployment files as references
+	var addonConfig addons.AddonConfig
+	if err := yaml.Unmarshal(content, &addonConfig); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path.Join(dir, configFile), err)
+	}
+	draftConfig := addonConfig.DraftConfig
+	if len(draftConfig.TemplateDelimiters) > 0 {
+		if err := templaterender.ValidateDelimiters(draftConfig.TemplateDelimiters); err != nil {
+			return nil, fmt.Errorf("%s: %w", path.Join(dir, configFile), err)
+		}
+	}
+
+	declared := make(map[string]bool)
+	for _, name := range draftConfig.VariableNames() {
+		declared[name] = true
+	}
+	for _, name := range opts.Provided {
+		declared[name] = true
+	}
+	var references []string
+	for _, resources := range addonConfig.ReferenceComponents {
+		for _, resource := range resources {
+			declared[resource.Name] = true
+			references = append(references, resource.Name)
+		}
+	}
+	used := make(map[string]bool)
+	for _, name := range opts.Consumed {
+		used[name] = true
+	}
+	for _, optionalFile := range draftConfig.OptionalFiles {
+		used[optionalFile.Variable] = true
+	}
+	for _, variableDefault := range draftConfig.VariableDefaults {
+		if variableDefault.ReferenceVar != "" {
+			used[variableDefault.ReferenceVar] = true
+		}
+	}
+
+	var problems []Problem
+	inputs := renderInputs(&draftConfig, append(references, opts.Provided...))
+	err = fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || p == path.Join(dir, configFile) {
+			return err
+		}
+		file := strings.TrimPrefix(p, dir+"/")
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+
+		references := placeholders(string(content))
+		if len(draftConfig.TemplateDelimiters) > 0 {
+			fields, err := templateFields(file, string(content), draftConfig.TemplateDelimiters)
+			if err != nil {
+				problems = append(problems, Problem{Pack: dir, Kind: Render, File: file, Message: err.Error()})
+				return nil
+			}
+			references = append(references, fields...)
+		}
+		for _, ref := range references {
+			used[ref.name] = true
+			if !declared[ref.name] {
+				problems = append(problems, Problem{Pack: dir, Kind: Undeclared, Variable: ref.name, File: file, Line: ref.line})
+			}
+		}
+
+		if _, err := templaterender.Render(file, content, inputs, draftConfig.TemplateDelimiters); err != nil {
+			problems = append(problems, Problem{Pack: dir, Kind: Render, File: file, Message: err.Error()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range draftConfig.VariableNames() {
+		if !used[name] {
+			problems = append(problems, Problem{Pack: dir, Kind: Unused, Variable: name})
+		}
+	}
+	return problems, nil
+}
+
+// reference is a variable a file refers to, at a line
+type reference struct {
+	name string
+	line int
+}
+
+// placeholders returns the {{VARIABLE}} placeholders of content
+func placeholders(content string) []reference {
+	var refs []reference
+	for i, line := range strings.Split(content, "\n") {
+		for _, match := range placeholderRegex.FindAllStringSubmatch(line, -1) {
+			refs = append(refs, reference{name: match[1], line: i + 1})
+		}
+	}
+	return refs
+}
+
+// templateFields returns the fields of the variables the template actions of content refer to, such as PORT for
+// [[ .PORT ]], in every branch of its conditionals
+func templateFields(name, content string, delimiters []string) ([]reference, error) {
+	tmpl, err := template.New(name).Delims(delimiters[0], delimiters[1]).Funcs(templaterender.FuncMap()).Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	var refs []reference
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		walkFields(t.Tree.Root, func(node *parse.FieldNode) {
+			line, _ := t.Tree.ErrorContext(node)
+			refs = append(refs, reference{name: node.Ident[0], line: lineOf(line)})
+		})
+	}
+	sort.SliceStable(refs, func(i, j int) bool { return refs[i].line < refs[j].line })
+	return refs, nil
+}
+
+// lineOf returns the line of the location of ErrorContext, such as 3 for name:3:14
+func lineOf(location string) int {
+	parts := strings.Split(location, ":")
+	if len(parts) < 3 {
+		return 0
+	}
+	var line int
+	fmt.Sscanf(parts[len(parts)-2], "%d", &line)
+	return line
+}
+
+// walkFields calls fn with every field node under node
+func walkFields(node parse.Node, fn func(*parse.FieldNode)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkFields(child, fn)
+		}
+	case *parse.ActionNode:
+		walkFields(n.Pipe, fn)
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.TemplateNode:
+		walkFields(n.Pipe, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkFields(cmd, fn)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkFields(arg, fn)
+		}
+	case *parse.ChainNode:
+		walkFields(n.Node, fn)
+	case *parse.FieldNode:
+		fn(n)
+	}
+}
+
+func walkBranch(n *parse.BranchNode, fn func(*parse.FieldNode)) {
+	walkFields(n.Pipe, fn)
+	walkFields(n.List, fn)
+	walkFields(n.ElseList, fn)
+}
+
+// renderInputs returns the values the files of a pack are rendered with: the default of each variable, its first
+// example value or else a placeholder value, so every variable is set
+func renderInputs(draftConfig *config.DraftConfig, provided []string) map[string]string {
+	inputs := make(map[string]string)
+	for _, name := range append(draftConfig.VariableNames(), provided...) {
+		inputs[name] = "draft-lint"
+	}
+	for _, variable := range draftConfig.Variables {
+		if len(variable.ExampleValues) > 0 {
+			inputs[variable.Name] = variable.ExampleValues[0]
+		}
+	}
+	for _, variableDefault := range draftConfig.VariableDefaults {
+		if variableDefault.Value != "" {
+			inputs[variableDefault.Name] = variableDefault.Value
+		}
+	}
+	return inputs
+}
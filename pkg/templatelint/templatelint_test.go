@@ -0,0 +1,76 @@
+package templatelint
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dockerfiles/go/draft.yaml": {Data: []byte(`language: go
+variables:
+  - name: "PORT"
+  - name: "VERSION"
+  - name: "CACHE"
+optionalFiles:
+  - path: ".dockerignore"
+    variable: "CACHE"
+variableDefaults:
+  - name: "VERSION"
+    value: "1.22"
+`)},
+		"dockerfiles/go/Dockerfile":    {Data: []byte("FROM golang:{{VERSION}}\nEXPOSE {{PORT}}\nCMD [\"{{BINARY}}\"]\n")},
+		"dockerfiles/go/.dockerignore": {Data: []byte("bin\n")},
+		"dockerfiles/go@1.0.0/draft.yaml": {Data: []byte(`variables:
+  - name: "UNUSED"
+`)},
+		"addons/routing/draft.yaml": {Data: []byte(`variables:
+  - name: "host"
+references:
+  service:
+    - name: "service-name"
+      path: "metadata.name"
+`)},
+		"addons/routing/ingress.yaml": {Data: []byte("host: {{host}}\nservice: {{service-name}}\nimage: {{imageTag}}\n")},
+	}
+
+	problems, err := LintDir(fsys, Options{Provided: []string{"imageTag"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []Problem{
+		{Pack: "dockerfiles/go", Kind: Undeclared, Variable: "BINARY", File: "Dockerfile", Line: 3},
+	}, problems)
+
+	problems, err = Lint(fsys, "dockerfiles/go@1.0.0", Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, []Problem{{Pack: "dockerfiles/go@1.0.0", Kind: Unused, Variable: "UNUSED"}}, problems)
+	assert.Equal(t, "dockerfiles/go@1.0.0: variable UNUSED is declared in draft.yaml but never used", problems[0].String())
+}
+
+func TestLintTemplateDelimiters(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pack/draft.yaml": {Data: []byte(`templateDelimiters: ["[[", "]]"]
+variables:
+  - name: "PORT"
+  - name: "TLS"
+  - name: "REPLICAS"
+variableDefaults:
+  - name: "TLS"
+    value: "false"
+`)},
+		"pack/service.yaml": {Data: []byte("port: [[ .PORT ]]\n[[- if eq .TLS \"true\" ]]\ntls: [[ .CERT ]]\n[[- end ]]\n")},
+		"pack/broken.yaml":  {Data: []byte("[[ if .TLS ]]\n")},
+		"pack/failing.yaml": {Data: []byte("[[ index .TLS 5 ]]\n")},
+	}
+
+	problems, err := Lint(fsys, "pack", Options{Consumed: []string{"REPLICAS"}})
+	assert.Nil(t, err)
+	assert.Len(t, problems, 3)
+	assert.Equal(t, Render, problems[0].Kind)
+	assert.Equal(t, "broken.yaml", problems[0].File)
+	assert.Equal(t, Render, problems[1].Kind)
+	assert.Equal(t, "failing.yaml", problems[1].File)
+	assert.Equal(t, Problem{Pack: "pack", Kind: Undeclared, Variable: "CERT", File: "service.yaml", Line: 3}, problems[2])
+	assert.Equal(t, "pack/service.yaml:3: variable CERT is used but not declared in draft.yaml", problems[2].String())
+}
@@ -0,0 +1,117 @@
+// Package templaterender renders the files of packs. Every file gets the {{VARIABLE}} placeholders of its variables
+// substituted. The files of packs that set templateDelimiters in their draft.yaml are first executed as text/templates
+// with those delimiters, for the conditionals, loops and defaults flat substitution can't express. Packs keep the
+// default {{ }} delimiters free for the placeholders and for the helm templates they generate.
+package templaterender
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/Azure/draft/pkg/templatefuncs"
+)
+
+// Render renders content, the file name of a pack, with variables. When delimiters holds the left and right
+// delimiters of the pack's template actions, content is executed as a text/template with the variables as its data,
+// such as [[ .PORT ]], and the functions of FuncMap. The {{VARIABLE}} placeholders of the result are then substituted.
+// An action referring to a variable that isn't set fails the render.
+func Render(name string, content []byte, variables map[string]string, delimiters []string) ([]byte, error) {
+	if len(delimiters) > 0 {
+		if err := ValidateDelimiters(delimiters); err != nil {
+			return nil, err
+		}
+		tmpl, err := template.New(name).Delims(delimiters[0], delimiters[1]).Funcs(FuncMap()).Option("missingkey=error").Parse(string(content))
+		if err != nil {
+			return nil, err
+		}
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, variables); err != nil {
+			return nil, err
+		}
+		content = out.Bytes()
+	}
+	return []byte(Substitute(string(content), variables)), nil
+}
+
+// ValidateDelimiters checks delimiters holds a left and a right delimiter, neither of them the {{ }} of the
+// placeholders
+func ValidateDelimiters(delimiters []string) error {
+	if len(delimiters) != 2 || delimiters[0] == "" || delimiters[1] == "" {
+		return fmt.Errorf("invalid templateDelimiters %q, must be a left and a right delimiter such as [\"[[\", \"]]\"]", delimiters)
+	}
+	if delimiters[0] == "{{" || delimiters[1] == "}}" {
+		return fmt.Errorf("invalid templateDelimiters %q, {{ and }} are kept for the {{VARIABLE}} placeholders and helm templates", delimiters)
+	}
+	return nil
+}
+
+// Substitute replaces the {{VARIABLE}} placeholders of variables in content. Variables are replaced in sorted order,
+// so values holding other placeholders render the same on every run.
+func Substitute(content string, variables map[string]string) string {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		content = strings.ReplaceAll(content, "{{"+name+"}}", variables[name])
+	}
+	return content
+}
+
+// FuncMap returns the functions available to the templates of packs: those of templatefuncs, and helpers for the
+// string values of variables
+func FuncMap() template.FuncMap {
+	funcs := template.FuncMap{
+		"default":   defaultValue,
+		"isTrue":    isTrue,
+		"lower":     strings.ToLower,
+		"upper":     strings.ToUpper,
+		"trim":      strings.TrimSpace,
+		"quote":     strconv.Quote,
+		"split":     split,
+		"join":      join,
+		"contains":  func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix": func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix": func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"replace":   func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	}
+	for name, f := range templatefuncs.FuncMap() {
+		funcs[name] = f
+	}
+	return funcs
+}
+
+// defaultValue returns value, or def when value is empty. Its arguments are in the order of pipelines such as
+// .TAG | default "latest".
+func defaultValue(def, value string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// isTrue reports whether value is a boolean variable set to true
+func isTrue(value string) bool {
+	b, _ := strconv.ParseBool(value)
+	return b
+}
+
+// split returns the trimmed, non-empty elements of s separated by sep, for ranging over list variables
+func split(sep, s string) []string {
+	var elements []string
+	for _, element := range strings.Split(s, sep) {
+		if element = strings.TrimSpace(element); element != "" {
+			elements = append(elements, element)
+		}
+	}
+	return elements
+}
+
+func join(sep string, elements []string) string {
+	return strings.Join(elements, sep)
+}
@@ -0,0 +1,51 @@
+package templaterender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	delimiters := []string{"[[", "]]"}
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"placeholders", "FROM {{IMAGE}}:{{TAG}}", "FROM golang:1.22"},
+		{"variable", "EXPOSE [[ .PORT ]]", "EXPOSE 8080"},
+		{"default", "[[ .EMPTY | default \"none\" ]] [[ .TAG | default \"latest\" ]]", "none 1.22"},
+		{"conditional", "[[ if isTrue .ENABLED ]]on[[ else ]]off[[ end ]]", "on"},
+		{"loop", "[[ range split \",\" .ENVIRONMENTS ]]- [[ . ]]\n[[ end ]]", "- dev\n- prod\n"},
+		{"functions", "[[ upper .IMAGE ]] [[ join \"+\" (split \",\" .ENVIRONMENTS) ]] [[ quote .TAG ]]", "GOLANG dev+prod \"1.22\""},
+		{"templatefuncs", "[[ serviceDNS \"api\" \"web\" ]]", "api.web.svc.cluster.local"},
+		{"placeholder in output", "[[ if isTrue .ENABLED ]]{{IMAGE}}[[ end ]]", "golang"},
+		{"helm", "{{ .Values.image }} {{- include \"app.labels\" . }}", "{{ .Values.image }} {{- include \"app.labels\" . }}"},
+	}
+	variables := map[string]string{"IMAGE": "golang", "TAG": "1.22", "PORT": "8080", "EMPTY": "", "ENABLED": "true", "ENVIRONMENTS": "dev, prod,"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Render("file", []byte(tt.content), variables, delimiters)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, string(out))
+		})
+	}
+
+	out, err := Render("file", []byte("EXPOSE [[ .PORT ]] {{PORT}}"), variables, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "EXPOSE [[ .PORT ]] 8080", string(out), "files of packs without delimiters are only substituted")
+
+	_, err = Render("file", []byte("[[ .MISSING ]]"), variables, delimiters)
+	assert.ErrorContains(t, err, `map has no entry for key "MISSING"`)
+	_, err = Render("file", []byte("[[ if ]]"), variables, delimiters)
+	assert.ErrorContains(t, err, "template: file:1")
+}
+
+func TestValidateDelimiters(t *testing.T) {
+	assert.Nil(t, ValidateDelimiters([]string{"[[", "]]"}))
+	assert.Nil(t, ValidateDelimiters([]string{"<%", "%>"}))
+	assert.ErrorContains(t, ValidateDelimiters([]string{"[["}), "must be a left and a right delimiter")
+	assert.ErrorContains(t, ValidateDelimiters([]string{"", "]]"}), "must be a left and a right delimiter")
+	assert.ErrorContains(t, ValidateDelimiters([]string{"{{", "}}"}), "kept for the {{VARIABLE}} placeholders")
+}
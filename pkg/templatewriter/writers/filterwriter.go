@@ -0,0 +1,25 @@
+package writers
+
+import (
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
+// FilterWriter writes with Writer the files and directories for which Skip returns false, dropping the others
+type FilterWriter struct {
+	Writer templatewriter.TemplateWriter
+	Skip   func(path string) bool
+}
+
+func (w *FilterWriter) WriteFile(path string, data []byte) error {
+	if w.Skip(path) {
+		return nil
+	}
+	return w.Writer.WriteFile(path, data)
+}
+
+func (w *FilterWriter) EnsureDirectory(path string) error {
+	if w.Skip(path) {
+		return nil
+	}
+	return w.Writer.EnsureDirectory(path)
+}
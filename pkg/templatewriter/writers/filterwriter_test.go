@@ -0,0 +1,20 @@
+package writers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterWriter(t *testing.T) {
+	files := &FileMapWriter{}
+	w := &FilterWriter{Writer: files, Skip: func(path string) bool {
+		return strings.HasPrefix(path, "skipped/")
+	}}
+
+	assert.Nil(t, w.EnsureDirectory("skipped/dir"))
+	assert.Nil(t, w.WriteFile("skipped/dir/file", []byte("data")))
+	assert.Nil(t, w.WriteFile("kept/file", []byte("data")))
+	assert.Equal(t, map[string][]byte{"kept/file": []byte("data")}, files.FileMap)
+}
@@ -0,0 +1,86 @@
+package writers
+
+import (
+	"sort"
+	"sync"
+)
+
+// InMemoryWriter accumulates the generated files in memory instead of writing them to disk. Unlike FileMapWriter it
+// keeps its own copy of every file and is safe for concurrent use, so library consumers and tests can capture output
+// with it.
+type InMemoryWriter struct {
+	mu    sync.Mutex
+	files map[string][]byte
+	dirs  map[string]bool
+}
+
+// NewInMemoryWriter returns an empty InMemoryWriter
+func NewInMemoryWriter() *InMemoryWriter {
+	return &InMemoryWriter{}
+}
+
+func (w *InMemoryWriter) WriteFile(path string, data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.files == nil {
+		w.files = map[string][]byte{}
+	}
+	w.files[path] = append([]byte(nil), data...)
+	return nil
+}
+
+func (w *InMemoryWriter) EnsureDirectory(path string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.dirs == nil {
+		w.dirs = map[string]bool{}
+	}
+	w.dirs[path] = true
+	return nil
+}
+
+// Files returns a copy of the written files keyed by path
+func (w *InMemoryWriter) Files() map[string][]byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	files := make(map[string][]byte, len(w.files))
+	for path, data := range w.files {
+		files[path] = append([]byte(nil), data...)
+	}
+	return files
+}
+
+// File returns the contents written to path, and whether a file was written there
+func (w *InMemoryWriter) File(path string) ([]byte, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	data, ok := w.files[path]
+	if !ok {
+		return nil, false
+	}
+	return append([]byte(nil), data...), true
+}
+
+// Paths returns the paths of the written files, sorted
+func (w *InMemoryWriter) Paths() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	paths := make([]string, 0, len(w.files))
+	for path := range w.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Directories returns the directories ensured while writing, sorted
+func (w *InMemoryWriter) Directories() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	dirs := make([]string, 0, len(w.dirs))
+	for dir := range w.dirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
@@ -0,0 +1,57 @@
+package writers
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/osutil"
+	"github.com/Azure/draft/pkg/templatewriter"
+	"github.com/Azure/draft/template"
+)
+
+var _ templatewriter.TemplateWriter = &InMemoryWriter{}
+
+func TestInMemoryWriter(t *testing.T) {
+	w := NewInMemoryWriter()
+	err := osutil.CopyDir(template.Dockerfiles, "dockerfiles/javascript", "/test/dir", nil, map[string]string{
+		"PORT":     "8080",
+		"VERSION":  "14",
+		"HARDENED": "true",
+	}, w)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/test/dir/.dockerignore", "/test/dir/Dockerfile"}, w.Paths())
+	dockerfile, ok := w.File("/test/dir/Dockerfile")
+	assert.True(t, ok)
+	assert.Contains(t, string(dockerfile), "EXPOSE 8080")
+	_, ok = w.File("/test/dir/missing")
+	assert.False(t, ok)
+
+	assert.Nil(t, w.EnsureDirectory("/test/dir/charts"))
+	assert.Equal(t, []string{"/test/dir/charts"}, w.Directories())
+
+	data := []byte("a")
+	assert.Nil(t, w.WriteFile("file", data))
+	data[0] = 'b'
+	files := w.Files()
+	assert.Equal(t, "a", string(files["file"]), "the writer keeps its own copy")
+	files["file"][0] = 'c'
+	file, _ := w.File("file")
+	assert.Equal(t, "a", string(file), "the accessors return copies")
+}
+
+func TestInMemoryWriterConcurrent(t *testing.T) {
+	w := &InMemoryWriter{}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.Nil(t, w.WriteFile(fmt.Sprintf("file%d", i), []byte("data")))
+		}(i)
+	}
+	wg.Wait()
+	assert.Len(t, w.Files(), 20)
+}
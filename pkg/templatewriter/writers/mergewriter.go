@@ -0,0 +1,57 @@
+package writers
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/merge"
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
+// MergeWriter merges the changes between the previously generated version of a file in Base and the data written
+// into the existing file on disk, keeping the edits made to it since. Existing files without a generated version in
+// Base are kept as they are.
+type MergeWriter struct {
+	Writer templatewriter.TemplateWriter
+	// Base holds the files as they were generated before, keyed by cleaned path. Files are written as they are while
+	// it is nil.
+	Base map[string][]byte
+	// Conflicted lists the files written with conflict markers
+	Conflicted []string
+}
+
+func (w *MergeWriter) WriteFile(path string, data []byte) error {
+	if w.Base == nil {
+		return w.Writer.WriteFile(path, data)
+	}
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err != nil || bytes.Equal(existing, data) {
+		return w.Writer.WriteFile(path, data)
+	}
+
+	base, ok := w.Base[filepath.Clean(path)]
+	if !ok {
+		log.Warnf("--> No generated version of %s is recorded to merge with, keeping it", path)
+		return nil
+	}
+	result := merge.ThreeWay(base, existing, data, "existing", "template")
+	if result.Conflicts > 0 {
+		log.Warnf("--> %s has %d conflicting changes marked for resolving", path, result.Conflicts)
+		w.Conflicted = append(w.Conflicted, path)
+	} else {
+		log.Infof("--> Merged the template changes into %s", path)
+	}
+	return w.Writer.WriteFile(path, result.Content)
+}
+
+func (w *MergeWriter) EnsureDirectory(path string) error {
+	return w.Writer.EnsureDirectory(path)
+}
@@ -0,0 +1,46 @@
+package writers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeWriter(t *testing.T) {
+	dir := t.TempDir()
+	edited := filepath.Join(dir, "Dockerfile")
+	untracked := filepath.Join(dir, "untracked")
+	assert.Nil(t, os.WriteFile(edited, []byte("FROM golang:1.20\nWORKDIR /app\nENV GIN_MODE=release\nEXPOSE 80\n"), 0644))
+	assert.Nil(t, os.WriteFile(untracked, []byte("mine\n"), 0644))
+
+	files := &FileMapWriter{}
+	w := &MergeWriter{Writer: files, Base: map[string][]byte{
+		edited: []byte("FROM golang:1.20\nWORKDIR /app\nEXPOSE 80\n"),
+	}}
+
+	assert.Nil(t, w.EnsureDirectory(dir))
+	assert.Nil(t, w.WriteFile(filepath.Join(dir, "new"), []byte("new")))
+	assert.Nil(t, w.WriteFile(edited, []byte("FROM golang:1.22\nWORKDIR /app\nEXPOSE 80\n")))
+	assert.Nil(t, w.WriteFile(untracked, []byte("template\n")))
+
+	assert.Equal(t, []byte("new"), files.FileMap[filepath.Join(dir, "new")])
+	assert.Equal(t, "FROM golang:1.22\nWORKDIR /app\nENV GIN_MODE=release\nEXPOSE 80\n", string(files.FileMap[edited]))
+	assert.NotContains(t, files.FileMap, untracked)
+	assert.Empty(t, w.Conflicted)
+
+	assert.Nil(t, w.WriteFile(edited, []byte("FROM golang:1.22\nWORKDIR /app\nENV GIN_MODE=debug\nEXPOSE 80\n")))
+	assert.Equal(t, []string{edited}, w.Conflicted)
+}
+
+func TestMergeWriterWithoutBase(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "Dockerfile")
+	assert.Nil(t, os.WriteFile(existing, []byte("mine\n"), 0644))
+
+	files := &FileMapWriter{}
+	w := &MergeWriter{Writer: files}
+	assert.Nil(t, w.WriteFile(existing, []byte("template\n")))
+	assert.Equal(t, []byte("template\n"), files.FileMap[existing])
+}
@@ -0,0 +1,26 @@
+package writers
+
+import "github.com/Azure/draft/pkg/templatewriter"
+
+// MultiWriter duplicates every write to all of its Writers, stopping at the first error
+type MultiWriter struct {
+	Writers []templatewriter.TemplateWriter
+}
+
+func (w *MultiWriter) WriteFile(path string, data []byte) error {
+	for _, writer := range w.Writers {
+		if err := writer.WriteFile(path, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *MultiWriter) EnsureDirectory(path string) error {
+	for _, writer := range w.Writers {
+		if err := writer.EnsureDirectory(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package writers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
+func TestMultiWriter(t *testing.T) {
+	first := &FileMapWriter{}
+	second := &FileMapWriter{}
+	w := &MultiWriter{Writers: []templatewriter.TemplateWriter{first, second}}
+
+	assert.Nil(t, w.EnsureDirectory("dir"))
+	assert.Nil(t, w.WriteFile("dir/file", []byte("data")))
+	assert.Equal(t, []byte("data"), first.FileMap["dir/file"])
+	assert.Equal(t, []byte("data"), second.FileMap["dir/file"])
+}
@@ -0,0 +1,44 @@
+package writers
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
+// OverwriteWriter asks Confirm before Writer replaces a file on disk with different content, and skips the file when
+// Confirm declines
+type OverwriteWriter struct {
+	Writer  templatewriter.TemplateWriter
+	Confirm func(path string) (bool, error)
+	// InPlace reports the files draft edits in place rather than generates, which are written without asking, nil for
+	// none
+	InPlace func(path string) bool
+}
+
+func (w *OverwriteWriter) WriteFile(path string, data []byte) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err == nil && !bytes.Equal(existing, data) && (w.InPlace == nil || !w.InPlace(path)) {
+		overwrite, err := w.Confirm(path)
+		if err != nil {
+			return err
+		}
+		if !overwrite {
+			log.Infof("--> Keeping existing %s", path)
+			return nil
+		}
+	}
+	return w.Writer.WriteFile(path, data)
+}
+
+func (w *OverwriteWriter) EnsureDirectory(path string) error {
+	return w.Writer.EnsureDirectory(path)
+}
@@ -0,0 +1,46 @@
+package writers
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverwriteWriter(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing")
+	unchanged := filepath.Join(dir, "unchanged")
+	assert.Nil(t, os.WriteFile(existing, []byte("old"), 0644))
+	assert.Nil(t, os.WriteFile(unchanged, []byte("same"), 0644))
+
+	var confirmed []string
+	answer := false
+	files := &FileMapWriter{}
+	w := &OverwriteWriter{Writer: files, Confirm: func(path string) (bool, error) {
+		confirmed = append(confirmed, path)
+		return answer, nil
+	}}
+
+	assert.Nil(t, w.EnsureDirectory(dir))
+	assert.Nil(t, w.WriteFile(filepath.Join(dir, "new"), []byte("new")))
+	assert.Nil(t, w.WriteFile(unchanged, []byte("same")))
+	assert.Nil(t, w.WriteFile(existing, []byte("changed")))
+	assert.Equal(t, []string{existing}, confirmed)
+	assert.Contains(t, files.FileMap, filepath.Join(dir, "new"))
+	assert.Contains(t, files.FileMap, unchanged)
+	assert.NotContains(t, files.FileMap, existing)
+
+	answer = true
+	assert.Nil(t, w.WriteFile(existing, []byte("changed")))
+	assert.Equal(t, []byte("changed"), files.FileMap[existing])
+
+	w.Confirm = func(string) (bool, error) { return false, errors.New("exists") }
+	assert.EqualError(t, w.WriteFile(existing, []byte("changed again")), "exists")
+
+	w.InPlace = func(path string) bool { return path == existing }
+	assert.Nil(t, w.WriteFile(existing, []byte("edited")))
+	assert.Equal(t, []byte("edited"), files.FileMap[existing])
+}
@@ -0,0 +1,25 @@
+package writers
+
+import (
+	"fmt"
+
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
+// PostRenderWriter passes every file through Mutate before writing it with Writer
+type PostRenderWriter struct {
+	Writer templatewriter.TemplateWriter
+	Mutate func(path string, data []byte) ([]byte, error)
+}
+
+func (w *PostRenderWriter) WriteFile(path string, data []byte) error {
+	mutated, err := w.Mutate(path, data)
+	if err != nil {
+		return fmt.Errorf("post-rendering %s: %w", path, err)
+	}
+	return w.Writer.WriteFile(path, mutated)
+}
+
+func (w *PostRenderWriter) EnsureDirectory(path string) error {
+	return w.Writer.EnsureDirectory(path)
+}
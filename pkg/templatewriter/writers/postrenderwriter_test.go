@@ -0,0 +1,25 @@
+package writers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostRenderWriter(t *testing.T) {
+	files := &FileMapWriter{}
+	w := &PostRenderWriter{Writer: files, Mutate: func(path string, data []byte) ([]byte, error) {
+		if path == "bad" {
+			return nil, errors.New("invalid")
+		}
+		return append(data, '!'), nil
+	}}
+
+	assert.Nil(t, w.EnsureDirectory("dir"))
+	assert.Nil(t, w.WriteFile("dir/file", []byte("data")))
+	assert.Equal(t, []byte("data!"), files.FileMap["dir/file"])
+
+	assert.EqualError(t, w.WriteFile("bad", []byte("data")), "post-rendering bad: invalid")
+	assert.NotContains(t, files.FileMap, "bad")
+}
@@ -0,0 +1,62 @@
+package writers
+
+import (
+	"sort"
+
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
+// StagedWriter holds back the files and directories written to it until Commit writes them to Writer in the order they
+// were written, so the generated files can be reviewed before any of them reaches the disk
+type StagedWriter struct {
+	Writer templatewriter.TemplateWriter
+	writes []stagedWrite
+}
+
+// stagedWrite is a file or directory written to a StagedWriter
+type stagedWrite struct {
+	path string
+	data []byte
+	dir  bool
+}
+
+func (w *StagedWriter) WriteFile(path string, data []byte) error {
+	w.writes = append(w.writes, stagedWrite{path: path, data: append([]byte(nil), data...)})
+	return nil
+}
+
+func (w *StagedWriter) EnsureDirectory(path string) error {
+	w.writes = append(w.writes, stagedWrite{path: path, dir: true})
+	return nil
+}
+
+// Paths returns the paths of the staged files, sorted
+func (w *StagedWriter) Paths() []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, write := range w.writes {
+		if !write.dir && !seen[write.path] {
+			seen[write.path] = true
+			paths = append(paths, write.path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Commit writes the staged files and directories to Writer, stopping at the first error
+func (w *StagedWriter) Commit() error {
+	for _, write := range w.writes {
+		var err error
+		if write.dir {
+			err = w.Writer.EnsureDirectory(write.path)
+		} else {
+			err = w.Writer.WriteFile(write.path, write.data)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	w.writes = nil
+	return nil
+}
@@ -0,0 +1,36 @@
+package writers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
+var _ templatewriter.TemplateWriter = &StagedWriter{}
+
+func TestStagedWriter(t *testing.T) {
+	files := NewInMemoryWriter()
+	w := &StagedWriter{Writer: files}
+
+	assert.Nil(t, w.EnsureDirectory("/dest/charts"))
+	data := []byte("a")
+	assert.Nil(t, w.WriteFile("/dest/charts/values.yaml", data))
+	assert.Nil(t, w.WriteFile("/dest/Dockerfile", nil))
+	data[0] = 'b'
+
+	assert.Equal(t, []string{"/dest/Dockerfile", "/dest/charts/values.yaml"}, w.Paths())
+	assert.Empty(t, files.Paths(), "nothing is written before Commit")
+	assert.Empty(t, files.Directories())
+
+	assert.Nil(t, w.Commit())
+	assert.Equal(t, []string{"/dest/Dockerfile", "/dest/charts/values.yaml"}, files.Paths())
+	assert.Equal(t, []string{"/dest/charts"}, files.Directories())
+	values, _ := files.File("/dest/charts/values.yaml")
+	assert.Equal(t, "a", string(values), "the writer keeps its own copy")
+	dockerfile, ok := files.File("/dest/Dockerfile")
+	assert.True(t, ok)
+	assert.Empty(t, dockerfile)
+	assert.Empty(t, w.Paths())
+}
@@ -0,0 +1,37 @@
+package writers
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/Azure/draft/pkg/templatewriter"
+)
+
+// UncommittedWriter calls OnUncommitted before Writer replaces a file that has uncommitted changes in git with
+// different content, and doesn't write the file when OnUncommitted returns an error
+type UncommittedWriter struct {
+	Writer templatewriter.TemplateWriter
+	// Files holds the absolute paths of the files with uncommitted changes
+	Files         map[string]bool
+	OnUncommitted func(path string) error
+}
+
+func (w *UncommittedWriter) WriteFile(path string, data []byte) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if w.Files[absPath] {
+		if existing, err := os.ReadFile(path); err != nil || !bytes.Equal(existing, data) {
+			if err := w.OnUncommitted(path); err != nil {
+				return err
+			}
+		}
+	}
+	return w.Writer.WriteFile(path, data)
+}
+
+func (w *UncommittedWriter) EnsureDirectory(path string) error {
+	return w.Writer.EnsureDirectory(path)
+}
@@ -0,0 +1,35 @@
+package writers
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUncommittedWriter(t *testing.T) {
+	dir := t.TempDir()
+	dirty := filepath.Join(dir, "dirty")
+	assert.Nil(t, os.WriteFile(dirty, []byte("local edits"), 0644))
+
+	var reported []string
+	files := &FileMapWriter{}
+	w := &UncommittedWriter{Writer: files, Files: map[string]bool{dirty: true}, OnUncommitted: func(path string) error {
+		reported = append(reported, path)
+		return errors.New("uncommitted")
+	}}
+
+	assert.Nil(t, w.WriteFile(filepath.Join(dir, "clean"), []byte("new")))
+	assert.Nil(t, w.WriteFile(dirty, []byte("local edits")))
+	assert.Empty(t, reported)
+
+	assert.ErrorContains(t, w.WriteFile(dirty, []byte("generated")), "uncommitted")
+	assert.Equal(t, []string{dirty}, reported)
+	assert.Equal(t, []byte("local edits"), files.FileMap[dirty])
+
+	w.OnUncommitted = func(string) error { return nil }
+	assert.Nil(t, w.WriteFile(dirty, []byte("generated")))
+	assert.Equal(t, []byte("generated"), files.FileMap[dirty])
+}
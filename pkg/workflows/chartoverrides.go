@@ -0,0 +1,151 @@
+package workflows
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ChartOverridesVariable is the helm workflow variable holding the rendered k8s-bake overrides
+	ChartOverridesVariable = "CHARTOVERRIDES"
+
+	// ChartOverrideFormatSet renders chart overrides as k8s-bake overrides, which bake passes to helm as --set arguments
+	ChartOverrideFormatSet = "set"
+	// ChartOverrideFormatFile merges chart overrides into the chart override values file used by the workflow
+	ChartOverrideFormatFile = "file"
+
+	// bakeOverridesIndent is the indentation of the overrides block in the helm workflow template
+	bakeOverridesIndent = "            "
+)
+
+var chartOverrideSegmentRegex = regexp.MustCompile(`^([A-Za-z0-9_-]+)(\[(\d+)\])?$`)
+
+// ChartOverride is a helm value set by the generated workflow at deploy time
+type ChartOverride struct {
+	Path  string
+	Value string
+}
+
+// ParseChartOverrides parses key=value chart overrides such as image.tag=abc, validating each key as a values path
+func ParseChartOverrides(overrides []string) ([]ChartOverride, error) {
+	parsed := make([]ChartOverride, 0, len(overrides))
+	for _, override := range overrides {
+		key, value, ok := strings.Cut(override, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid chart override %q, expected key=value", override)
+		}
+		if err := ValidateChartOverridePath(key); err != nil {
+			return nil, err
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("invalid chart override %q: value cannot span multiple lines", override)
+		}
+		parsed = append(parsed, ChartOverride{Path: key, Value: value})
+	}
+	return parsed, nil
+}
+
+// ValidateChartOverridePath checks that path is a dot separated helm values path whose keys may be followed by a list
+// index, for example image.tag or ingress.hosts[0].host
+func ValidateChartOverridePath(path string) error {
+	if path == "" {
+		return fmt.Errorf("chart override key cannot be empty")
+	}
+	for _, segment := range strings.Split(path, ".") {
+		if !chartOverrideSegmentRegex.MatchString(segment) {
+			return fmt.Errorf("invalid chart override key %q: %q is not a valid values key", path, segment)
+		}
+	}
+	return nil
+}
+
+// FormatChartOverrides renders overrides as the lines of the k8s-bake overrides block in the helm workflow
+func FormatChartOverrides(overrides []ChartOverride) string {
+	lines := make([]string, len(overrides))
+	for i, override := range overrides {
+		lines[i] = override.Path + ":" + override.Value
+	}
+	return strings.Join(lines, "\n"+bakeOverridesIndent)
+}
+
+// ChartOverrideValues returns overrides as a nested values tree like the one helm builds from --set arguments
+func ChartOverrideValues(overrides []ChartOverride) map[string]interface{} {
+	values := make(map[string]interface{})
+	for _, override := range overrides {
+		node := values
+		segments := strings.Split(override.Path, ".")
+		for _, segment := range segments[:len(segments)-1] {
+			child, _ := getChartOverrideSegment(node, segment).(map[string]interface{})
+			if child == nil {
+				child = make(map[string]interface{})
+				setChartOverrideSegment(node, segment, child)
+			}
+			node = child
+		}
+		setChartOverrideSegment(node, segments[len(segments)-1], parseChartOverrideValue(override.Value))
+	}
+	return values
+}
+
+func getChartOverrideSegment(node map[string]interface{}, segment string) interface{} {
+	match := chartOverrideSegmentRegex.FindStringSubmatch(segment)
+	if match[2] == "" {
+		return node[match[1]]
+	}
+	index, _ := strconv.Atoi(match[3])
+	list, _ := node[match[1]].([]interface{})
+	if index >= len(list) {
+		return nil
+	}
+	return list[index]
+}
+
+// setChartOverrideSegment sets segment of node to value, growing the list when segment has an index past its end
+func setChartOverrideSegment(node map[string]interface{}, segment string, value interface{}) {
+	match := chartOverrideSegmentRegex.FindStringSubmatch(segment)
+	if match[2] == "" {
+		node[match[1]] = value
+		return
+	}
+	index, _ := strconv.Atoi(match[3])
+	list, _ := node[match[1]].([]interface{})
+	for len(list) <= index {
+		list = append(list, nil)
+	}
+	list[index] = value
+	node[match[1]] = list
+}
+
+// parseChartOverrideValue converts value to a bool, number or null like helm --set does, keeping anything else a string
+func parseChartOverrideValue(value string) interface{} {
+	var parsed interface{}
+	if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+		return value
+	}
+	switch parsed.(type) {
+	case bool, int, float64:
+		return parsed
+	case nil:
+		if value == "null" {
+			return nil
+		}
+	}
+	return value
+}
+
+// mergeValues deep merges src into dst, with src taking precedence
+func mergeValues(dst, src map[string]interface{}) {
+	for key, srcVal := range src {
+		srcMap, srcIsMap := srcVal.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeValues(dstMap, srcMap)
+			continue
+		}
+		dst[key] = srcVal
+	}
+}
@@ -0,0 +1,95 @@
+package workflows
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
+)
+
+func TestParseChartOverrides(t *testing.T) {
+	overrides, err := ParseChartOverrides([]string{"image.tag=abc", "ingress.hosts[0].host=a=b", "replicaCount=2"})
+	assert.Nil(t, err)
+	assert.Equal(t, []ChartOverride{
+		{Path: "image.tag", Value: "abc"},
+		{Path: "ingress.hosts[0].host", Value: "a=b"},
+		{Path: "replicaCount", Value: "2"},
+	}, overrides)
+
+	for _, invalid := range []string{"replicas:2", "=abc", "image..tag=abc", "image.tag.=abc", "image tag=abc", "hosts[x]=abc", "image.tag=a\nb"} {
+		_, err := ParseChartOverrides([]string{invalid})
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestChartOverrideValues(t *testing.T) {
+	values := ChartOverrideValues([]ChartOverride{
+		{Path: "image.tag", Value: "abc"},
+		{Path: "replicaCount", Value: "2"},
+		{Path: "ingress.enabled", Value: "true"},
+		{Path: "ingress.hosts[1].host", Value: "example.com"},
+		{Path: "ingress.hosts[1].paths[0]", Value: "/"},
+		{Path: "version", Value: "1.0.0"},
+	})
+
+	assert.Equal(t, map[string]interface{}{
+		"image":        map[string]interface{}{"tag": "abc"},
+		"replicaCount": 2,
+		"ingress": map[string]interface{}{
+			"enabled": true,
+			"hosts": []interface{}{
+				nil,
+				map[string]interface{}{"host": "example.com", "paths": []interface{}{"/"}},
+			},
+		},
+		"version": "1.0.0",
+	}, values)
+}
+
+func TestFormatChartOverrides(t *testing.T) {
+	assert.Equal(t, "", FormatChartOverrides(nil))
+	assert.Equal(t, "image.tag:abc\n"+bakeOverridesIndent+"replicaCount:2", FormatChartOverrides([]ChartOverride{
+		{Path: "image.tag", Value: "abc"},
+		{Path: "replicaCount", Value: "2"},
+	}))
+}
+
+func TestCreateWorkflowFilesChartOverrides(t *testing.T) {
+	newInputs := func() map[string]string {
+		return map[string]string{"AZURECONTAINERREGISTRY": "testAcr", "CONTAINERNAME": "testContainer", "RESOURCEGROUP": "testRG", "CLUSTERNAME": "testCluster", "BRANCHNAME": "testBranch", "BUILDCONTEXTPATH": "."}
+	}
+	overrides := []ChartOverride{{Path: "image.tag", Value: "abc"}, {Path: "replicaCount", Value: "3"}}
+
+	w := CreateWorkflowsFromEmbedFS(template.Workflows, "")
+	assert.NotNil(t, w.SetChartOverrides(overrides, "values"))
+	assert.Nil(t, w.SetChartOverrides(overrides, ChartOverrideFormatSet))
+	files, err := w.RenderWorkflowFiles("helm", newInputs())
+	assert.Nil(t, err)
+	workflow := string(files[".github/workflows/azure-kubernetes-service-helm.yml"])
+	assert.Contains(t, workflow, "          overrides: |\n            image.tag:abc\n            replicaCount:3\n")
+
+	assert.NotNil(t, w.CreateWorkflowFiles("manifests", newInputs(), &writers.FileMapWriter{}))
+
+	dest := t.TempDir()
+	assert.Nil(t, os.Mkdir(dest+"/charts", 0755))
+	prodValues, err := os.ReadFile("../../test/templates/helm/charts/production.yaml")
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(dest+"/charts/production.yaml", prodValues, 0644))
+
+	w = CreateWorkflowsFromEmbedFS(template.Workflows, dest)
+	assert.Nil(t, w.SetChartOverrides(overrides, ChartOverrideFormatFile))
+	fileMapWriter := &writers.FileMapWriter{}
+	assert.Nil(t, w.CreateWorkflowFiles("helm", newInputs(), fileMapWriter))
+
+	workflow = string(fileMapWriter.FileMap[dest+"/.github/workflows/azure-kubernetes-service-helm.yml"])
+	assert.Contains(t, workflow, "          overrides: |\n            \n")
+
+	var values map[string]interface{}
+	assert.Nil(t, yaml.Unmarshal(fileMapWriter.FileMap[dest+"/charts/production.yaml"], &values))
+	assert.Equal(t, map[string]interface{}{"repository": "testAcr.azurecr.io/testContainer", "pullPolicy": "Always", "tag": "abc"}, values["image"])
+	assert.Equal(t, 3, values["replicaCount"])
+}
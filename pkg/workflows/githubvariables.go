@@ -0,0 +1,131 @@
+package workflows
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Azure/draft/pkg/templatewriter"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+)
+
+// GitHubVariablesScriptPath is the script setting the GitHub variables read by the workflows generated with
+// SetGitHubVariables, relative to the project directory
+const GitHubVariablesScriptPath = ".github/set-workflow-variables.sh"
+
+// gitHubVariables are the GitHub variables holding the values of the workflow variables naming the resources the
+// workflows deploy to, which change without the workflows changing
+var gitHubVariables = map[string]string{
+	"AZURECONTAINERREGISTRY":  "AZURE_CONTAINER_REGISTRY",
+	"CONTAINERNAME":           "CONTAINER_NAME",
+	"RESOURCEGROUP":           "RESOURCE_GROUP",
+	"CLUSTERNAME":             "CLUSTER_NAME",
+	"AZUREAPPNAME":            "AZURE_APP_NAME",
+	"AWSREGION":               "AWS_REGION",
+	"AWSROLEARN":              "AWS_ROLE_ARN",
+	ECRRegistryVariable:       "ECR_REGISTRY",
+	"GCPPROJECT":              "GCP_PROJECT",
+	"GKELOCATION":             "GKE_LOCATION",
+	ArtifactRegistryVariable:  "ARTIFACT_REGISTRY",
+	ContainerRegistryVariable: "CONTAINER_REGISTRY",
+}
+
+var (
+	workflowJobRegex       = regexp.MustCompile(`^  [A-Za-z0-9_-]+:\s*$`)
+	gitHubEnvironmentRegex = regexp.MustCompile(`^[^\s'"` + "`" + `,;\\]{1,255}$`)
+	topLevelYAMLKeyRegex   = regexp.MustCompile(`^[A-Za-z]`)
+)
+
+// SetGitHubVariables makes the generated Github workflows read the values of the workflow variables naming the
+// resources they deploy to from GitHub variables, such as vars.CLUSTER_NAME, instead of holding them, and writes a
+// GitHubVariablesScriptPath setting those variables with the gh cli. With an environment, the variables are those of
+// that GitHub environment, which every job of the workflows deploys to.
+func (w *Workflows) SetGitHubVariables(environment string) error {
+	if w.parentDir == gitlabParentDirName || w.parentDir == azdoParentDirName {
+		return fmt.Errorf("GitHub variables are only read by %s workflows", ProviderGitHub)
+	}
+	if environment != "" && !gitHubEnvironmentRegex.MatchString(environment) {
+		return fmt.Errorf("invalid GitHub environment name %q", environment)
+	}
+	w.gitHubVariables = true
+	w.gitHubEnvironment = environment
+	return nil
+}
+
+// gitHubVariableInputs returns customInputs with the values of the variables named in gitHubVariables replaced by
+// references to the GitHub variables holding them, and the GitHub variables to set, keyed by name
+func gitHubVariableInputs(customInputs map[string]string) (map[string]string, map[string]string) {
+	inputs := make(map[string]string, len(customInputs))
+	variables := make(map[string]string)
+	for name, value := range customInputs {
+		inputs[name] = value
+		if gitHubVariable, ok := gitHubVariables[name]; ok && value != "" {
+			inputs[name] = fmt.Sprintf("${{ vars.%s }}", gitHubVariable)
+			variables[gitHubVariable] = value
+		}
+	}
+	return inputs, variables
+}
+
+// withGitHubEnvironment returns a writer setting the environment of every job of the workflows it writes
+func withGitHubEnvironment(templateWriter templatewriter.TemplateWriter, environment string) templatewriter.TemplateWriter {
+	return &writers.PostRenderWriter{Writer: templateWriter, Mutate: func(filePath string, data []byte) ([]byte, error) {
+		if ext := path.Ext(filePath); ext != ".yml" && ext != ".yaml" {
+			return data, nil
+		}
+		return SetJobsEnvironment(data, environment), nil
+	}}
+}
+
+// SetJobsEnvironment adds environment to every job of the Github workflow, after its name
+func SetJobsEnvironment(workflow []byte, environment string) []byte {
+	var out bytes.Buffer
+	inJobs := false
+	scanner := bufio.NewScanner(bytes.NewReader(workflow))
+	for scanner.Scan() {
+		line := scanner.Text()
+		out.WriteString(line)
+		out.WriteByte('\n')
+		switch {
+		case line == "jobs:":
+			inJobs = true
+		case topLevelYAMLKeyRegex.MatchString(line):
+			inJobs = false
+		case inJobs && workflowJobRegex.MatchString(line):
+			fmt.Fprintf(&out, "    environment: %s\n", environment)
+		}
+	}
+	return out.Bytes()
+}
+
+// GitHubVariablesScript returns the script setting variables, keyed by name, with the gh cli, in environment unless
+// it's empty
+func GitHubVariablesScript(variables map[string]string, environment string) []byte {
+	var script strings.Builder
+	script.WriteString("#!/bin/sh\n")
+	script.WriteString("# Sets the GitHub variables the workflows generated by draft read the resources they deploy to from.\n")
+	script.WriteString("# Run it again with new values to deploy to other resources without regenerating the workflows.\n")
+	script.WriteString("set -e\n\n")
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&script, "gh variable set %s --body %s", name, shellQuote(variables[name]))
+		if environment != "" {
+			fmt.Fprintf(&script, " --env %s", shellQuote(environment))
+		}
+		script.WriteString("\n")
+	}
+	return []byte(script.String())
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
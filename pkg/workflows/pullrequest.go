@@ -0,0 +1,133 @@
+package workflows
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxPullRequestBodyLength keeps the generated body below GitHub's 65536 character limit
+const maxPullRequestBodyLength = 60000
+
+// PullRequestOptions configures the branch, commit and pull request created for generated workflow files
+type PullRequestOptions struct {
+	Dest   string
+	Branch string
+	Base   string
+	Title  string
+	// Files maps the generated file paths to their rendered contents, used for the pull request preview
+	Files map[string][]byte
+	// ExtraPaths are paths modified outside of the template writer that should be part of the commit
+	ExtraPaths []string
+}
+
+// CreatePullRequestBranch creates and checks out a new branch in the git repository containing dest. It returns the
+// branch, or the commit when HEAD is detached, that was checked out before so AbandonPullRequestBranch can restore it.
+func CreatePullRequestBranch(dest, branch string) (string, error) {
+	if branch == "" {
+		return "", fmt.Errorf("pull request branch name cannot be empty")
+	}
+	if _, err := runGit(dest, "rev-parse", "--show-toplevel"); err != nil {
+		return "", fmt.Errorf("%s is not inside a git repository: %w", dest, err)
+	}
+	original, err := runGit(dest, "symbolic-ref", "--short", "-q", "HEAD")
+	if err != nil {
+		if original, err = runGit(dest, "rev-parse", "HEAD"); err != nil {
+			return "", fmt.Errorf("finding the current branch: %w", err)
+		}
+	}
+	if _, err := runGit(dest, "checkout", "-b", branch); err != nil {
+		return "", fmt.Errorf("creating branch %s: %w", branch, err)
+	}
+	return strings.TrimSpace(original), nil
+}
+
+// AbandonPullRequestBranch checks out the original branch again and deletes the branch created by
+// CreatePullRequestBranch, used when generating or opening the pull request fails
+func AbandonPullRequestBranch(dest, branch, original string) error {
+	if _, err := runGit(dest, "checkout", original); err != nil {
+		return fmt.Errorf("switching back to %s: %w", original, err)
+	}
+	if _, err := runGit(dest, "branch", "-D", branch); err != nil {
+		return fmt.Errorf("deleting branch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// OpenPullRequest commits the generated files on the current branch, pushes it and opens a pull request with the
+// gh cli. It returns the url of the new pull request.
+func OpenPullRequest(opts PullRequestOptions) (string, error) {
+	paths := make([]string, 0, len(opts.Files)+len(opts.ExtraPaths))
+	for p := range opts.Files {
+		paths = append(paths, p)
+	}
+	paths = append(paths, opts.ExtraPaths...)
+	if len(paths) == 0 {
+		return "", fmt.Errorf("no generated files to commit")
+	}
+
+	absPaths := make([]string, 0, len(paths))
+	for _, p := range paths {
+		absPath, err := filepath.Abs(p)
+		if err != nil {
+			return "", err
+		}
+		if _, err := runGit(opts.Dest, "add", "--", absPath); err != nil {
+			return "", fmt.Errorf("staging %s: %w", p, err)
+		}
+		absPaths = append(absPaths, absPath)
+	}
+
+	// limit the commit to the generated paths so changes the user already staged aren't part of the pull request
+	if _, err := runGit(opts.Dest, append([]string{"commit", "-m", opts.Title, "--"}, absPaths...)...); err != nil {
+		return "", fmt.Errorf("committing generated workflow: %w", err)
+	}
+	if _, err := runGit(opts.Dest, "push", "--set-upstream", "origin", opts.Branch); err != nil {
+		return "", fmt.Errorf("pushing branch %s: %w", opts.Branch, err)
+	}
+
+	args := []string{"pr", "create", "--title", opts.Title, "--body", PullRequestBody(opts.Files), "--head", opts.Branch}
+	if opts.Base != "" {
+		args = append(args, "--base", opts.Base)
+	}
+	ghCmd := exec.Command("gh", args...)
+	ghCmd.Dir = opts.Dest
+	out, err := ghCmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("creating pull request: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// PullRequestBody renders a markdown preview of the generated files, truncating it to fit GitHub's body size limit
+func PullRequestBody(files map[string][]byte) string {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var sb strings.Builder
+	sb.WriteString("This pull request was generated by `draft generate-workflow`.\n\n")
+	for _, p := range paths {
+		section := fmt.Sprintf("<details>\n<summary><code>%s</code></summary>\n\n```yaml\n%s\n```\n</details>\n\n", filepath.ToSlash(p), strings.TrimRight(string(files[p]), "\n"))
+		if sb.Len()+len(section) > maxPullRequestBodyLength {
+			sb.WriteString(fmt.Sprintf("_Preview of `%s` omitted because the body exceeds GitHub's size limit._\n\n", filepath.ToSlash(p)))
+			continue
+		}
+		sb.WriteString(section)
+	}
+	return sb.String()
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	gitCmd := exec.Command("git", args...)
+	gitCmd.Dir = dir
+	out, err := gitCmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
@@ -0,0 +1,101 @@
+package workflows
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullRequestBody(t *testing.T) {
+	body := PullRequestBody(map[string][]byte{
+		".github/workflows/b.yml": []byte("name: b\n"),
+		".github/workflows/a.yml": []byte("name: a\n"),
+	})
+	assert.Contains(t, body, "```yaml\nname: a\n```")
+	assert.Less(t, strings.Index(body, "a.yml"), strings.Index(body, "b.yml"))
+
+	large := PullRequestBody(map[string][]byte{
+		"big.yml":   []byte(strings.Repeat("x", maxPullRequestBodyLength)),
+		"small.yml": []byte("name: small"),
+	})
+	assert.LessOrEqual(t, len(large), maxPullRequestBodyLength)
+	assert.Contains(t, large, "Preview of `big.yml` omitted")
+	assert.Contains(t, large, "name: small")
+}
+
+func TestCreatePullRequestBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	_, err := CreatePullRequestBranch(dir, "draft/test")
+	assert.NotNil(t, err, "should fail outside of a git repository")
+
+	initTestRepo(t, dir)
+	_, err = CreatePullRequestBranch(dir, "")
+	assert.NotNil(t, err)
+	original, err := CreatePullRequestBranch(dir, "draft/test")
+	assert.Nil(t, err)
+	assert.Equal(t, "main", original)
+
+	out, err := runGit(dir, "symbolic-ref", "--short", "HEAD")
+	assert.Nil(t, err)
+	assert.Equal(t, "draft/test", strings.TrimSpace(out))
+
+	assert.Nil(t, AbandonPullRequestBranch(dir, "draft/test", original))
+	out, err = runGit(dir, "symbolic-ref", "--short", "HEAD")
+	assert.Nil(t, err)
+	assert.Equal(t, "main", strings.TrimSpace(out))
+	_, err = runGit(dir, "rev-parse", "--verify", "draft/test")
+	assert.NotNil(t, err, "the abandoned branch should be deleted")
+}
+
+func TestOpenPullRequestCommitsOnlyGeneratedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	initTestRepo(t, dir)
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "staged.txt"), []byte("staged by the user"), 0644))
+	_, err := runGit(dir, "add", "staged.txt")
+	assert.Nil(t, err)
+
+	_, err = CreatePullRequestBranch(dir, "draft/test")
+	assert.Nil(t, err)
+	workflowPath := filepath.Join(dir, "workflow.yml")
+	assert.Nil(t, os.WriteFile(workflowPath, []byte("name: test\n"), 0644))
+
+	// there is no origin remote, so the push fails after the commit
+	_, err = OpenPullRequest(PullRequestOptions{
+		Dest:   dir,
+		Branch: "draft/test",
+		Title:  "Add workflow",
+		Files:  map[string][]byte{workflowPath: []byte("name: test\n")},
+	})
+	assert.ErrorContains(t, err, "pushing branch draft/test")
+	assert.ErrorContains(t, err, "origin", "the git output should be part of the error")
+
+	out, err := runGit(dir, "show", "--name-only", "--format=", "HEAD")
+	assert.Nil(t, err)
+	assert.Equal(t, "workflow.yml", strings.TrimSpace(out))
+	out, err = runGit(dir, "diff", "--cached", "--name-only")
+	assert.Nil(t, err)
+	assert.Equal(t, "staged.txt", strings.TrimSpace(out), "changes staged by the user should stay staged")
+}
+
+func initTestRepo(t *testing.T, dir string) {
+	t.Helper()
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"config", "user.email", "draft@example.com"},
+		{"config", "user.name", "draft"},
+		{"commit", "--allow-empty", "-m", "initial commit"},
+	} {
+		_, err := runGit(dir, args...)
+		assert.Nil(t, err)
+	}
+}
@@ -0,0 +1,27 @@
+package workflows
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RebuildScheduleVariable is the workflow variable holding the cron schedule of the redeploy on base image update
+// workflow, which is only generated when it is set
+const RebuildScheduleVariable = "REBUILDSCHEDULE"
+
+var cronFieldRegex = regexp.MustCompile(`^[0-9A-Za-z*/,-]+$`)
+
+// ValidateCronSchedule checks that schedule is a five field cron expression as accepted by GitHub Actions schedules
+func ValidateCronSchedule(schedule string) error {
+	fields := strings.Fields(schedule)
+	if len(fields) != 5 {
+		return fmt.Errorf("invalid schedule %q: expected 5 fields (minute hour day-of-month month day-of-week), got %d", schedule, len(fields))
+	}
+	for _, field := range fields {
+		if !cronFieldRegex.MatchString(field) {
+			return fmt.Errorf("invalid schedule %q: %q is not a valid cron field", schedule, field)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package workflows
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/template"
+)
+
+func TestValidateCronSchedule(t *testing.T) {
+	for _, valid := range []string{"0 6 * * 1", "*/15 * * * *", "0 0 1,15 * MON-FRI"} {
+		assert.Nil(t, ValidateCronSchedule(valid), valid)
+	}
+	for _, invalid := range []string{"", "@daily", "0 6 * *", "0 6 * * 1 2", `0 6 * * "1"`} {
+		assert.NotNil(t, ValidateCronSchedule(invalid), invalid)
+	}
+}
+
+func TestRenderRebuildScheduleWorkflow(t *testing.T) {
+	workflowFiles := map[string]string{
+		"helm":         "azure-kubernetes-service-helm.yml",
+		"kustomize":    "azure-kubernetes-service-kustomize.yml",
+		"manifests":    "azure-kubernetes-service.yml",
+		"containerapp": "azure-container-apps.yml",
+		"appservice":   "azure-app-service.yml",
+	}
+	for deployType, deployWorkflow := range workflowFiles {
+		newInputs := func() map[string]string {
+			return map[string]string{"AZURECONTAINERREGISTRY": "testAcr", "CONTAINERNAME": "testContainer", "RESOURCEGROUP": "testRG", "CLUSTERNAME": "testCluster", "AZUREAPPNAME": "testApp", "BRANCHNAME": "main", "BUILDCONTEXTPATH": "."}
+		}
+		w := CreateWorkflowsFromEmbedFS(template.Workflows, "")
+
+		files, err := w.RenderWorkflowFiles(deployType, newInputs())
+		assert.Nil(t, err)
+		assert.NotContains(t, files, ".github/workflows/redeploy-on-base-image-update.yml")
+
+		inputs := newInputs()
+		inputs[RebuildScheduleVariable] = "0 6 * * 1"
+		files, err = w.RenderWorkflowFiles(deployType, inputs)
+		assert.Nil(t, err)
+		redeploy := string(files[".github/workflows/redeploy-on-base-image-update.yml"])
+		assert.Contains(t, redeploy, `- cron: "0 6 * * 1"`)
+		assert.Contains(t, redeploy, "DEPLOY_WORKFLOW: "+deployWorkflow)
+		assert.Contains(t, redeploy, `gh workflow run "${{ env.DEPLOY_WORKFLOW }}" --ref main`)
+		assert.Contains(t, files, ".github/workflows/"+deployWorkflow)
+	}
+}
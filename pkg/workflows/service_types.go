@@ -34,6 +34,7 @@ type image struct {
 	Repository string `yaml:"repository"`
 	PullPolicy string `yaml:"pullPolicy"`
 	Tag        string `yaml:"tag"`
+	Digest     string `yaml:"digest,omitempty"`
 }
 
 func (hpy *HelmProductionYaml) SetAnnotations(annotations map[string]string) {
@@ -0,0 +1,89 @@
+package workflows
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// containerNameRegex matches the repository names of the OCI distribution spec, lowercase path components separated
+// by slashes
+var containerNameRegex = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*(/[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*)*$`)
+
+// containerRegistryRegex matches a registry host with an optional port, followed by optional repository path
+// components such as the namespace of an organization
+var containerRegistryRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]*[a-z0-9])?(:[0-9]+)?(/[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*)*$`)
+
+// ValidateContainerRegistry checks that registry is a registry host, optionally with a port and a namespace, such as
+// registry.example.com:5000 or ghcr.io/my-org
+func ValidateContainerRegistry(registry string) error {
+	if !containerRegistryRegex.MatchString(registry) {
+		return fmt.Errorf("invalid container registry %q, must be a registry host such as registry.example.com:5000, optionally followed by a namespace such as ghcr.io/my-org", registry)
+	}
+	return nil
+}
+
+// ValidateContainerName checks that name is an image repository name as registries accept it, such as myapp or
+// team/myapp, without a registry or tag
+func ValidateContainerName(name string) error {
+	if len(name) > 255 || !containerNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid container name %q, must be lowercase letters and digits separated by ., _, __ or - and / between path components", name)
+	}
+	return nil
+}
+
+// DirectoryValidator returns a check that the value of the variable name is the path of a directory of the project in
+// dest, such as the build context of the workflows
+func DirectoryValidator(name, dest string) func(string) error {
+	return func(path string) error {
+		if filepath.IsAbs(path) {
+			return fmt.Errorf("invalid %s %q, must be relative to the project directory", name, path)
+		}
+		info, err := os.Stat(filepath.Join(dest, path))
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("invalid %s %q, no such directory in %s", name, path, dest)
+		}
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("invalid %s %q, not a directory", name, path)
+		}
+		return nil
+	}
+}
+
+// BranchValidator returns a check that a branch exists in the git repository of dest, locally or, unless offline, on
+// its origin remote. Any branch is accepted when git can't tell, such as outside of a git repository or without network
+// access.
+func BranchValidator(dest string, offline bool) func(string) error {
+	return func(branch string) error {
+		if err := exec.Command("git", "check-ref-format", "--branch", branch).Run(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				return fmt.Errorf("invalid branch name %q", branch)
+			}
+			log.Debugf("not checking branch %s: %s", branch, err)
+			return nil
+		}
+		if exec.Command("git", "-C", dest, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch).Run() == nil || offline {
+			return nil
+		}
+
+		// ls-remote exits with 2 when the remote has no matching ref
+		err := exec.Command("git", "-C", dest, "ls-remote", "--exit-code", "--heads", "origin", "refs/heads/"+branch).Run()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 2 {
+			return fmt.Errorf("branch %q exists neither in %s nor on its origin remote", branch, dest)
+		}
+		if err != nil {
+			log.Debugf("not checking branch %s on the origin remote: %s", branch, err)
+		}
+		return nil
+	}
+}
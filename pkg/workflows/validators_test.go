@@ -0,0 +1,68 @@
+package workflows
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateContainerName(t *testing.T) {
+	for _, valid := range []string{"myapp", "team/my-app", "my_app.v2", "a__b"} {
+		assert.Nil(t, ValidateContainerName(valid), valid)
+	}
+	for _, invalid := range []string{"", "MyApp", "myapp:latest", "-myapp", "team//myapp", "myapp/", "myregistry.azurecr.io:443/myapp"} {
+		assert.NotNil(t, ValidateContainerName(invalid), invalid)
+	}
+}
+
+func TestValidateContainerRegistry(t *testing.T) {
+	for _, valid := range []string{"ghcr.io", "ghcr.io/my-org", "registry.example.com:5000", "localhost:5000/team/apps"} {
+		assert.Nil(t, ValidateContainerRegistry(valid), valid)
+	}
+	for _, invalid := range []string{"", "https://ghcr.io", "Registry.example.com", "ghcr.io/", "ghcr.io/my-org:latest", "registry:port"} {
+		assert.NotNil(t, ValidateContainerRegistry(invalid), invalid)
+	}
+}
+
+func TestDirectoryValidator(t *testing.T) {
+	dest := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(dest, "src", "app"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dest, "Dockerfile"), nil, 0644))
+	validate := DirectoryValidator("BUILDCONTEXTPATH", dest)
+
+	assert.Nil(t, validate("."))
+	assert.Nil(t, validate("src/app"))
+	assert.EqualError(t, validate("missing"), `invalid BUILDCONTEXTPATH "missing", no such directory in `+dest)
+	assert.EqualError(t, validate("Dockerfile"), `invalid BUILDCONTEXTPATH "Dockerfile", not a directory`)
+	assert.NotNil(t, validate(filepath.Join(dest, "src")))
+}
+
+func TestBranchValidator(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	git := func(dir string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		assert.Nil(t, err, string(out))
+	}
+	origin := t.TempDir()
+	git(origin, "init", "--quiet", "--bare")
+	dest := t.TempDir()
+	git(dest, "init", "--quiet", "--initial-branch", "main")
+	git(dest, "commit", "--quiet", "--allow-empty", "-m", "initial")
+	git(dest, "remote", "add", "origin", origin)
+	git(dest, "push", "--quiet", "origin", "main:release")
+	validate := BranchValidator(dest, false)
+
+	assert.Nil(t, validate("main"), "local branches exist")
+	assert.Nil(t, validate("release"), "branches of the origin remote exist")
+	assert.EqualError(t, validate("develop"), `branch "develop" exists neither in `+dest+` nor on its origin remote`)
+	assert.EqualError(t, validate("bad..name"), `invalid branch name "bad..name"`)
+
+	assert.Nil(t, BranchValidator(dest, true)("develop"), "the origin remote isn't checked offline")
+	assert.Nil(t, BranchValidator(t.TempDir(), false)("develop"), "branches aren't checked outside of a git repository")
+}
@@ -1,18 +1,37 @@
 package workflows
 
 type WorkflowConfig struct {
+	// Cloud is the cloud the workflow deploys to, CloudAzure, CloudAWS or CloudGCP, which sets the variable of the
+	// registry name
+	Cloud string
+	// Provider is the CI provider the workflow is generated for, the registry name being that of any registry for
+	// ProviderGeneric
+	Provider          string
 	AcrName           string
 	ContainerName     string
 	ResourceGroupName string
 	AksClusterName    string
+	AppName           string
 	BranchName        string
 	BuildContextPath  string
+	RebuildSchedule   string
+	// ImageTagStrategy is the imagetag strategy the workflow tags the images it builds with
+	ImageTagStrategy string
 }
 
 func (config *WorkflowConfig) SetFlagValuesToMap() map[string]string {
 	flagValuesMap := make(map[string]string)
 	if config.AcrName != "" {
-		flagValuesMap["AZURECONTAINERREGISTRY"] = config.AcrName
+		switch {
+		case config.Provider == ProviderGeneric:
+			flagValuesMap[ContainerRegistryVariable] = config.AcrName
+		case config.Cloud == CloudAWS:
+			flagValuesMap[ECRRegistryVariable] = config.AcrName
+		case config.Cloud == CloudGCP:
+			flagValuesMap[ArtifactRegistryVariable] = config.AcrName
+		default:
+			flagValuesMap["AZURECONTAINERREGISTRY"] = config.AcrName
+		}
 	}
 
 	if config.ContainerName != "" {
@@ -27,6 +46,10 @@ func (config *WorkflowConfig) SetFlagValuesToMap() map[string]string {
 		flagValuesMap["CLUSTERNAME"] = config.AksClusterName
 	}
 
+	if config.AppName != "" {
+		flagValuesMap["AZUREAPPNAME"] = config.AppName
+	}
+
 	if config.BranchName != "" {
 		flagValuesMap["BRANCHNAME"] = config.BranchName
 	}
@@ -35,5 +58,13 @@ func (config *WorkflowConfig) SetFlagValuesToMap() map[string]string {
 		flagValuesMap["BUILDCONTEXTPATH"] = config.BuildContextPath
 	}
 
+	if config.RebuildSchedule != "" {
+		flagValuesMap[RebuildScheduleVariable] = config.RebuildSchedule
+	}
+
+	if config.ImageTagStrategy != "" {
+		flagValuesMap[ImageTagStrategyVariable] = config.ImageTagStrategy
+	}
+
 	return flagValuesMap
 }
@@ -8,7 +8,10 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
 
+	"golang.org/x/exp/maps"
 	"gopkg.in/yaml.v3"
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/cli-runtime/pkg/printers"
@@ -18,35 +21,142 @@ import (
 
 	"github.com/Azure/draft/pkg/config"
 	"github.com/Azure/draft/pkg/embedutils"
+	"github.com/Azure/draft/pkg/imagetag"
 	"github.com/Azure/draft/pkg/osutil"
+	"github.com/Azure/draft/pkg/templatefuncs"
 	"github.com/Azure/draft/pkg/templatewriter"
+	"github.com/Azure/draft/pkg/templatewriter/writers"
+	"github.com/Azure/draft/template"
 )
 
 const (
-	parentDirName  = "workflows"
-	configFileName = "/draft.yaml"
+	parentDirName        = "workflows"
+	gitlabParentDirName  = "gitlab"
+	azdoParentDirName    = "azdo"
+	awsParentDirName     = "aws"
+	gcpParentDirName     = "gcp"
+	genericParentDirName = "generic"
+	configFileName       = "/draft.yaml"
 )
 
+// CI providers that workflows can be generated for
+const (
+	ProviderGitHub      = "github"
+	ProviderGitLab      = "gitlab"
+	ProviderAzureDevOps = "azdo"
+	// ProviderGeneric generates a Github workflow pushing to any registry and deploying to any cluster, with the
+	// credentials of repository secrets rather than of a cloud
+	ProviderGeneric = "generic"
+)
+
+// Clouds that workflows can deploy to
+const (
+	CloudAzure = "azure"
+	CloudAWS   = "aws"
+	CloudGCP   = "gcp"
+)
+
+// ECRRegistryVariable is the workflow variable holding the Amazon ECR registry host the AWS workflows push to
+const ECRRegistryVariable = "ECRREGISTRY"
+
+// ArtifactRegistryVariable is the workflow variable holding the Google Artifact Registry repository the GCP workflows
+// push to
+const ArtifactRegistryVariable = "ARTIFACTREGISTRY"
+
+// ImageTagStrategyVariable is the workflow variable naming the imagetag strategy the workflows tag the images they build
+// with, which the production deployment files are tagged with too
+const ImageTagStrategyVariable = "IMAGETAGSTRATEGY"
+
+// imageTagScriptVariable is the workflow variable holding the shell command of the strategy of
+// ImageTagStrategyVariable, which the CI jobs set their IMAGE_TAG with
+const imageTagScriptVariable = "IMAGETAGSCRIPT"
+
+// ProvidedVariables are the variables draft sets from the others when rendering workflows, which the workflow templates
+// use without declaring
+var ProvidedVariables = []string{imageTagScriptVariable}
+
+// ContainerRegistryVariable is the workflow variable holding the registry, and optionally namespace, the generic
+// workflows push to
+const ContainerRegistryVariable = "CONTAINERREGISTRY"
+
 type Workflows struct {
 	workflows         map[string]fs.DirEntry
 	configs           map[string]*config.DraftConfig
 	dest              string
 	workflowTemplates fs.FS
+	// parentDir is the directory of workflowTemplates holding a template directory per deploy type
+	parentDir string
+
+	chartOverrides      []ChartOverride
+	chartOverrideFormat string
+
+	// gitHubVariables makes the workflows read the resources they deploy to from the GitHub variables of
+	// gitHubEnvironment, or of the repo when it's empty
+	gitHubVariables   bool
+	gitHubEnvironment string
 }
 
-func updateProductionDeployments(deployType, dest string, flagValuesMap map[string]string, templateWriter templatewriter.TemplateWriter) error {
-	productionImage := fmt.Sprintf("%s.azurecr.io/%s", flagValuesMap["AZURECONTAINERREGISTRY"], flagValuesMap["CONTAINERNAME"])
+// ProductionDeploymentPath returns the path of the deployment file that generate-workflow updates with the
+// production image for the given deploy type, or an empty string for deploy types whose workflow sets the image itself
+func ProductionDeploymentPath(deployType, dest string) string {
 	switch deployType {
 	case "helm":
-		return setHelmContainerImage(dest+"/charts/production.yaml", productionImage, templateWriter)
+		return filepath.Join(dest, "charts", "production.yaml")
 	case "kustomize":
-		return setDeploymentContainerImage(dest+"/overlays/production/deployment.yaml", productionImage)
+		return filepath.Join(dest, "overlays", "production", "deployment.yaml")
 	case "manifests":
-		return setDeploymentContainerImage(dest+"/manifests/deployment.yaml", productionImage)
+		return filepath.Join(dest, "manifests", "deployment.yaml")
+	}
+	return ""
+}
+
+// IsProductionDeployment reports whether path is the production deployment file of one of the deployment types in dest,
+// whose image UpdateProductionDeployments sets in place
+func IsProductionDeployment(dest, path string) bool {
+	for _, deployType := range []string{"helm", "kustomize", "manifests"} {
+		if filepath.Clean(path) == filepath.Clean(ProductionDeploymentPath(deployType, dest)) {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateProductionDeployments sets the production container image of the existing deployment files in dest
+// to the image pushed by the generated workflow
+func UpdateProductionDeployments(deployType, dest string, flagValuesMap map[string]string, templateWriter templatewriter.TemplateWriter) error {
+	tag, err := productionImageTag(flagValuesMap, dest)
+	if err != nil {
+		return err
+	}
+	switch deployType {
+	case "helm":
+		return setHelmContainerImage(ProductionDeploymentPath(deployType, dest), productionImage(flagValuesMap, ""), tag, templateWriter)
+	case "kustomize", "manifests":
+		return setDeploymentContainerImage(ProductionDeploymentPath(deployType, dest), productionImage(flagValuesMap, tag))
 	}
 	return nil
 }
 
+// productionImage returns the image pushed to the registry by the generated workflow, with tag unless it's empty
+func productionImage(flagValuesMap map[string]string, tag string) string {
+	for _, variable := range []string{ECRRegistryVariable, ArtifactRegistryVariable, ContainerRegistryVariable} {
+		if registry := flagValuesMap[variable]; registry != "" {
+			return templatefuncs.JoinImageRef(registry, flagValuesMap["CONTAINERNAME"], tag)
+		}
+	}
+	return templatefuncs.JoinImageRef(flagValuesMap["AZURECONTAINERREGISTRY"]+".azurecr.io", flagValuesMap["CONTAINERNAME"], tag)
+}
+
+// productionImageTag returns the tag the generated workflow pushes the image of the commit checked out in dest with,
+// by the strategy of ImageTagStrategyVariable
+func productionImageTag(flagValuesMap map[string]string, dest string) (string, error) {
+	strategy, err := imagetag.Get(flagValuesMap[ImageTagStrategyVariable])
+	if err != nil {
+		return "", err
+	}
+	return imagetag.TagForDir(strategy, dest), nil
+}
+
 func setDeploymentContainerImage(filePath, productionImage string) error {
 
 	decode := scheme.Codecs.UniversalDeserializer().Decode
@@ -72,7 +182,7 @@ func setDeploymentContainerImage(filePath, productionImage string) error {
 
 	printer := printers.YAMLPrinter{}
 
-	out, err := os.OpenFile(filePath, os.O_RDWR, 0755)
+	out, err := os.OpenFile(filePath, os.O_RDWR|os.O_TRUNC, 0755)
 	if err != nil {
 		return nil
 	}
@@ -85,7 +195,13 @@ func setDeploymentContainerImage(filePath, productionImage string) error {
 	return printer.PrintObj(deploy, out)
 }
 
-func setHelmContainerImage(filePath, productionImage string, templateWriter templatewriter.TemplateWriter) error {
+func setHelmContainerImage(filePath, productionImage, tag string, templateWriter templatewriter.TemplateWriter) error {
+	return updateHelmProductionValues(filePath, productionImage, tag, nil, templateWriter)
+}
+
+// updateHelmProductionValues sets the production image, and its tag unless it's empty, in the helm values file at
+// filePath and merges overrides into it
+func updateHelmProductionValues(filePath, productionImage, tag string, overrides map[string]interface{}, templateWriter templatewriter.TemplateWriter) error {
 	file, err := ioutil.ReadFile(filePath)
 	if err != nil {
 		return err
@@ -98,22 +214,71 @@ func setHelmContainerImage(filePath, productionImage string, templateWriter temp
 	}
 
 	deploy.Image.Repository = productionImage
+	if tag != "" {
+		deploy.Image.Tag = tag
+	}
 
 	out, err := yaml.Marshal(deploy)
 	if err != nil {
 		return err
 	}
 
+	if len(overrides) > 0 {
+		values := make(map[string]interface{})
+		if err := yaml.Unmarshal(out, &values); err != nil {
+			return err
+		}
+		mergeValues(values, overrides)
+		if out, err = yaml.Marshal(values); err != nil {
+			return err
+		}
+	}
+
 	return templateWriter.WriteFile(filePath, out)
 }
 
+// DeployTypes returns the sorted deploy types that have workflow templates
+func (w *Workflows) DeployTypes() []string {
+	names := maps.Keys(w.workflows)
+	sort.Strings(names)
+	return names
+}
+
+// Versions returns the embedded versions of the workflow template for deployType, newest first
+func (w *Workflows) Versions(deployType string) ([]string, error) {
+	if _, ok := w.workflows[deployType]; !ok {
+		return nil, fmt.Errorf("deploy type %s unsupported", deployType)
+	}
+	return embedutils.TemplateVersions(w.workflowTemplates, w.parentDir, deployType, w.configs[deployType].Version)
+}
+
+// UseVersion makes deployType generate its workflow from the embedded template at templateVersion
+func (w *Workflows) UseVersion(deployType, templateVersion string) error {
+	val, ok := w.workflows[deployType]
+	if !ok {
+		return fmt.Errorf("deploy type %s unsupported", deployType)
+	}
+	dir, err := embedutils.ResolveVersionedDir(w.workflowTemplates, w.parentDir, deployType, val, w.configs[deployType].Version, templateVersion)
+	if err != nil {
+		return err
+	}
+
+	w.workflows[deployType] = dir
+	draftConfig, err := w.loadConfig(deployType)
+	if err != nil {
+		return fmt.Errorf("loading version %s of the %s workflow: %w", templateVersion, deployType, err)
+	}
+	w.configs[deployType] = draftConfig
+	return nil
+}
+
 func (w *Workflows) loadConfig(deployType string) (*config.DraftConfig, error) {
 	val, ok := w.workflows[deployType]
 	if !ok {
 		return nil, fmt.Errorf("deploy type %s unsupported", deployType)
 	}
 
-	configPath := path.Join(parentDirName, val.Name(), configFileName)
+	configPath := path.Join(w.parentDir, val.Name(), configFileName)
 	configBytes, err := fs.ReadFile(w.workflowTemplates, configPath)
 	if err != nil {
 		return nil, err
@@ -136,7 +301,75 @@ func (w *Workflows) GetConfig(deployType string) (*config.DraftConfig, error) {
 }
 
 func CreateWorkflowsFromEmbedFS(workflowTemplates embed.FS, dest string) *Workflows {
-	deployMap, err := embedutils.EmbedFStoMap(workflowTemplates, parentDirName)
+	return createWorkflows(embedutils.WithTemplateDir(workflowTemplates), parentDirName, dest)
+}
+
+// CreateGitLabPipelinesFromEmbedFS returns the GitLab CI pipeline templates of gitlabTemplates, which generate a
+// .gitlab-ci.yml from the same variables as the GitHub workflow templates
+func CreateGitLabPipelinesFromEmbedFS(gitlabTemplates embed.FS, dest string) *Workflows {
+	return createWorkflows(embedutils.WithTemplateDir(gitlabTemplates), gitlabParentDirName, dest)
+}
+
+// CreateAzurePipelinesFromEmbedFS returns the Azure Pipelines templates of azdoTemplates, which generate an
+// azure-pipelines.yml from the same variables as the GitHub workflow templates
+func CreateAzurePipelinesFromEmbedFS(azdoTemplates embed.FS, dest string) *Workflows {
+	return createWorkflows(embedutils.WithTemplateDir(azdoTemplates), azdoParentDirName, dest)
+}
+
+// CreateAWSWorkflowsFromEmbedFS returns the Github workflow templates of awsTemplates, which push to Amazon ECR and
+// deploy to Amazon EKS with the IAM role they assume through GitHub's OIDC provider
+func CreateAWSWorkflowsFromEmbedFS(awsTemplates embed.FS, dest string) *Workflows {
+	return createWorkflows(embedutils.WithTemplateDir(awsTemplates), awsParentDirName, dest)
+}
+
+// CreateGCPWorkflowsFromEmbedFS returns the Github workflow templates of gcpTemplates, which push to Google Artifact
+// Registry and deploy to GKE with the service account they impersonate through Workload Identity Federation
+func CreateGCPWorkflowsFromEmbedFS(gcpTemplates embed.FS, dest string) *Workflows {
+	return createWorkflows(embedutils.WithTemplateDir(gcpTemplates), gcpParentDirName, dest)
+}
+
+// CreateGenericWorkflowsFromEmbedFS returns the Github workflow templates of genericTemplates, which push to any
+// registry with the username and password of repository secrets and deploy with the kubeconfig of another
+func CreateGenericWorkflowsFromEmbedFS(genericTemplates embed.FS, dest string) *Workflows {
+	return createWorkflows(embedutils.WithTemplateDir(genericTemplates), genericParentDirName, dest)
+}
+
+// CreateWorkflowsForCloud returns the embedded workflow templates of the CI provider deploying to cloud, CloudAzure,
+// CloudAWS or CloudGCP. AWS and Google Cloud are only deployed to by Github workflows.
+func CreateWorkflowsForCloud(provider, cloud, dest string) (*Workflows, error) {
+	switch cloud {
+	case "", CloudAzure:
+		return CreateWorkflowsForProvider(provider, dest)
+	case CloudAWS, CloudGCP:
+		if provider != ProviderGitHub {
+			return nil, fmt.Errorf("only %s workflows deploy to %s, not %s pipelines", ProviderGitHub, cloud, provider)
+		}
+		if cloud == CloudGCP {
+			return CreateGCPWorkflowsFromEmbedFS(template.GCPWorkflows, dest), nil
+		}
+		return CreateAWSWorkflowsFromEmbedFS(template.AWSWorkflows, dest), nil
+	}
+	return nil, fmt.Errorf("invalid cloud %q, must be %s, %s or %s", cloud, CloudAzure, CloudAWS, CloudGCP)
+}
+
+// CreateWorkflowsForProvider returns the embedded workflow templates of the CI provider, ProviderGitHub,
+// ProviderGitLab, ProviderAzureDevOps or ProviderGeneric
+func CreateWorkflowsForProvider(provider, dest string) (*Workflows, error) {
+	switch provider {
+	case ProviderGitHub:
+		return CreateWorkflowsFromEmbedFS(template.Workflows, dest), nil
+	case ProviderGitLab:
+		return CreateGitLabPipelinesFromEmbedFS(template.GitLabPipelines, dest), nil
+	case ProviderAzureDevOps:
+		return CreateAzurePipelinesFromEmbedFS(template.AzurePipelines, dest), nil
+	case ProviderGeneric:
+		return CreateGenericWorkflowsFromEmbedFS(template.GenericWorkflows, dest), nil
+	}
+	return nil, fmt.Errorf("invalid provider %q, must be %s, %s, %s or %s", provider, ProviderGitHub, ProviderGitLab, ProviderAzureDevOps, ProviderGeneric)
+}
+
+func createWorkflows(workflowTemplates fs.FS, parentDir, dest string) *Workflows {
+	deployMap, err := embedutils.EmbedFStoMap(workflowTemplates, parentDir)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -146,6 +379,7 @@ func CreateWorkflowsFromEmbedFS(workflowTemplates embed.FS, dest string) *Workfl
 		dest:              dest,
 		configs:           make(map[string]*config.DraftConfig),
 		workflowTemplates: workflowTemplates,
+		parentDir:         parentDir,
 	}
 	w.populateConfigs()
 
@@ -163,12 +397,61 @@ func (w *Workflows) populateConfigs() {
 	}
 }
 
+// SetChartOverrides sets the helm values the generated helm workflow overrides at deploy time. With
+// ChartOverrideFormatSet they are passed to k8s-bake as overrides, with ChartOverrideFormatFile they are merged into
+// the chart override values file.
+func (w *Workflows) SetChartOverrides(overrides []ChartOverride, format string) error {
+	if format != ChartOverrideFormatSet && format != ChartOverrideFormatFile {
+		return fmt.Errorf("invalid chart override format %q, must be %s or %s", format, ChartOverrideFormatSet, ChartOverrideFormatFile)
+	}
+	if format == ChartOverrideFormatSet && w.parentDir != parentDirName {
+		return fmt.Errorf("GitLab and Azure DevOps pipelines and the AWS, GCP and generic workflows only support the %s chart override format", ChartOverrideFormatFile)
+	}
+	w.chartOverrides = overrides
+	w.chartOverrideFormat = format
+	return nil
+}
+
+// CreateWorkflowFiles updates the production deployment files in dest and writes the rendered workflow files
 func (w *Workflows) CreateWorkflowFiles(deployType string, customInputs map[string]string, templateWriter templatewriter.TemplateWriter) error {
+	if _, ok := w.workflows[deployType]; !ok {
+		return fmt.Errorf("deployment type: %s is not currently supported", deployType)
+	}
+	if len(w.chartOverrides) > 0 && deployType != "helm" {
+		return fmt.Errorf("chart overrides are only supported for the helm deployment type, not %s", deployType)
+	}
+
+	if w.chartOverrideFormat == ChartOverrideFormatFile && len(w.chartOverrides) > 0 {
+		tag, err := productionImageTag(customInputs, w.dest)
+		if err != nil {
+			return err
+		}
+		if err := updateHelmProductionValues(ProductionDeploymentPath(deployType, w.dest), productionImage(customInputs, ""), tag, ChartOverrideValues(w.chartOverrides), templateWriter); err != nil {
+			return fmt.Errorf("update production deployments: %w", err)
+		}
+	} else if err := UpdateProductionDeployments(deployType, w.dest, customInputs, templateWriter); err != nil {
+		return fmt.Errorf("update production deployments: %w", err)
+	}
+
+	return w.writeWorkflowFiles(deployType, w.dest, customInputs, templateWriter)
+}
+
+// RenderWorkflowFiles renders the workflow files for deployType into a map of relative file paths to contents
+// without touching disk or the production deployment files
+func (w *Workflows) RenderWorkflowFiles(deployType string, customInputs map[string]string) (map[string][]byte, error) {
+	fileMapWriter := &writers.FileMapWriter{FileMap: make(map[string][]byte)}
+	if err := w.writeWorkflowFiles(deployType, "", customInputs, fileMapWriter); err != nil {
+		return nil, err
+	}
+	return fileMapWriter.FileMap, nil
+}
+
+func (w *Workflows) writeWorkflowFiles(deployType, dest string, customInputs map[string]string, templateWriter templatewriter.TemplateWriter) error {
 	val, ok := w.workflows[deployType]
 	if !ok {
 		return fmt.Errorf("deployment type: %s is not currently supported", deployType)
 	}
-	srcDir := path.Join(parentDirName, val.Name())
+	srcDir := path.Join(w.parentDir, val.Name())
 	log.Debugf("source directory for workflow template: %s", srcDir)
 	workflowConfig, ok := w.configs[deployType]
 	if !ok {
@@ -176,14 +459,39 @@ func (w *Workflows) CreateWorkflowFiles(deployType string, customInputs map[stri
 	} else {
 		workflowConfig.ApplyDefaultVariables(customInputs)
 	}
+	if w.chartOverrideFormat == ChartOverrideFormatSet && len(w.chartOverrides) > 0 {
+		customInputs[ChartOverridesVariable] = FormatChartOverrides(w.chartOverrides)
+	}
+	strategy, err := imagetag.Get(customInputs[ImageTagStrategyVariable])
+	if err != nil {
+		return err
+	}
+	customInputs[imageTagScriptVariable] = strategy.Script()
 
-	if err := updateProductionDeployments(deployType, w.dest, customInputs, templateWriter); err != nil {
-		return fmt.Errorf("update production deployments: %w", err)
+	if !w.gitHubVariables {
+		return osutil.CopyDir(w.workflowTemplates, srcDir, dest, workflowConfig, customInputs, templateWriter)
 	}
 
-	if err := osutil.CopyDir(w.workflowTemplates, srcDir, w.dest, workflowConfig, customInputs, templateWriter); err != nil {
+	inputs, variables := gitHubVariableInputs(customInputs)
+	workflowWriter := templateWriter
+	if w.gitHubEnvironment != "" {
+		workflowWriter = withGitHubEnvironment(templateWriter, w.gitHubEnvironment)
+	}
+	if err := osutil.CopyDir(w.workflowTemplates, srcDir, dest, workflowConfig, inputs, workflowWriter); err != nil {
 		return err
 	}
+	return templateWriter.WriteFile(path.Join(dest, GitHubVariablesScriptPath), GitHubVariablesScript(variables, w.gitHubEnvironment))
+}
+
+// RenderWorkflow renders the embedded workflow templates for deployType using the variable values set on cfg.
+// It returns the rendered files keyed by their path relative to the project root and never writes to disk.
+func RenderWorkflow(deployType string, cfg *config.DraftConfig) (map[string][]byte, error) {
+	if cfg == nil {
+		return nil, errors.New("draft config cannot be nil")
+	}
+	customInputs := cfg.VariableValues()
+	cfg.ApplyDefaultVariables(customInputs)
 
-	return nil
+	w := CreateWorkflowsFromEmbedFS(template.Workflows, "")
+	return w.RenderWorkflowFiles(deployType, customInputs)
 }
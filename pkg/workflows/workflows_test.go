@@ -7,9 +7,14 @@ import (
 	"io/fs"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 	"testing/fstest"
 
+	"golang.org/x/exp/maps"
+	"gopkg.in/yaml.v3"
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 
@@ -17,12 +22,13 @@ import (
 
 	"github.com/Azure/draft/pkg/config"
 	"github.com/Azure/draft/pkg/embedutils"
+	"github.com/Azure/draft/pkg/imagetag"
 	"github.com/Azure/draft/pkg/templatewriter/writers"
 	"github.com/Azure/draft/template"
 )
 
 func TestCreateWorkflows(t *testing.T) {
-	dest := "."
+	dest := t.TempDir()
 	deployType := "helm"
 	templatewriter := &writers.LocalFSWriter{}
 	flagValuesMap := map[string]string{"AZURECONTAINERREGISTRY": "testAcr", "CONTAINERNAME": "testContainer", "RESOURCEGROUP": "testRG", "CLUSTERNAME": "testCluster", "BRANCHNAME": "testBranch", "BUILDCONTEXTPATH": "."}
@@ -104,7 +110,7 @@ func TestCreateWorkflows(t *testing.T) {
 
 	for _, tt := range tests {
 
-		err := createTempDeploymentFile("charts", "charts/production.yaml", "../../test/templates/helm/charts/production.yaml")
+		err := createTempDeploymentFile(filepath.Join(dest, "charts"), filepath.Join(dest, "charts", "production.yaml"), "../../test/templates/helm/charts/production.yaml")
 		assert.Nil(t, err)
 
 		workflows := CreateWorkflowsFromEmbedFS(template.Workflows, dest)
@@ -128,11 +134,12 @@ func TestUpdateProductionDeploymentsValid(t *testing.T) {
 	helmFileName, _ := createTempManifest("../../test/templates/helm_prod_values.yaml")
 	defer os.Remove(helmFileName)
 
-	assert.Nil(t, setHelmContainerImage(helmFileName, "testImage", testTemplateWriter))
+	assert.Nil(t, setHelmContainerImage(helmFileName, "testImage", "v1", testTemplateWriter))
 
 	helmDeploy := &HelmProductionYaml{}
 	assert.Nil(t, helmDeploy.LoadFromFile(helmFileName))
 	assert.Equal(t, "testImage", helmDeploy.Image.Repository)
+	assert.Equal(t, "v1", helmDeploy.Image.Tag)
 
 	//test for valid deployment file
 	deploymentFileName, _ := createTempManifest("../../test/templates/deployment.yaml")
@@ -163,7 +170,7 @@ func TestUpdateProductionDeploymentsInvalid(t *testing.T) {
 	assert.Nil(t, err)
 	err = tempFile.Close()
 	assert.Nil(t, err)
-	assert.NotNil(t, setHelmContainerImage(tempFile.Name(), "testImage", testTemplateWriter))
+	assert.NotNil(t, setHelmContainerImage(tempFile.Name(), "testImage", "", testTemplateWriter))
 
 	//test for invalid deployment file
 	assert.NotNil(t, setDeploymentContainerImage(tempFile.Name(), "testImage"))
@@ -182,10 +189,10 @@ func TestUpdateProductionDeploymentsMissing(t *testing.T) {
 	flagValuesMap := map[string]string{"AZURECONTAINERREGISTRY": "testRegistry", "CONTAINERNAME": "testContainer"}
 	testTemplateWriter := &writers.LocalFSWriter{}
 	//test for missing deploy type
-	assert.Nil(t, updateProductionDeployments("", ".", flagValuesMap, testTemplateWriter))
+	assert.Nil(t, UpdateProductionDeployments("", ".", flagValuesMap, testTemplateWriter))
 
 	//test for missing helm deployment file
-	assert.NotNil(t, setHelmContainerImage("", "testImage", testTemplateWriter))
+	assert.NotNil(t, setHelmContainerImage("", "testImage", "", testTemplateWriter))
 
 	//test for missing deployment file
 	assert.NotNil(t, setDeploymentContainerImage("", "testImage"))
@@ -225,13 +232,13 @@ func TestPopulateConfigs(t *testing.T) {
 	assert.Nil(t, err)
 
 	w.populateConfigs()
-	assert.Equal(t, 5, len(w.configs)) // includes emptyDir and corrupted so 2 additional configs
+	assert.Equal(t, 7, len(w.configs)) // includes emptyDir and corrupted so 2 additional configs
 
 	w, err = createTestWorkflowEmbed("workflows")
 	assert.Nil(t, err)
 
 	w.populateConfigs()
-	assert.Equal(t, 3, len(w.configs))
+	assert.Equal(t, 5, len(w.configs))
 }
 
 func TestCreateWorkflowFiles(t *testing.T) {
@@ -247,21 +254,20 @@ func TestCreateWorkflowFiles(t *testing.T) {
 	assert.Nil(t, err)
 
 	mockWF.populateConfigs()
+	mockWF.dest = t.TempDir()
+	assert.Nil(t, createTempDeploymentFile(filepath.Join(mockWF.dest, "charts"), filepath.Join(mockWF.dest, "charts", "production.yaml"), "../../test/templates/helm/charts/production.yaml"))
 
 	err = mockWF.CreateWorkflowFiles("fakeDeployType", customInputs, templatewriter)
 	assert.NotNil(t, err)
 
 	err = mockWF.CreateWorkflowFiles("helm", customInputs, templatewriter)
 	assert.Nil(t, err)
-	os.RemoveAll(".github")
 
 	err = mockWF.CreateWorkflowFiles("helm", customInputsNoRoot, templatewriter)
 	assert.Nil(t, err)
-	os.RemoveAll(".github")
 
 	err = mockWF.CreateWorkflowFiles("helm", badInputs, templatewriter)
 	assert.NotNil(t, err)
-	os.RemoveAll(".github")
 }
 
 type loadConfTestCase struct {
@@ -357,6 +363,7 @@ func createMockWorkflow(dirPath string, mockWorkflowTemplates fs.FS) (*Workflows
 		dest:              dest,
 		configs:           make(map[string]*config.DraftConfig),
 		workflowTemplates: mockWorkflowTemplates,
+		parentDir:         parentDirName,
 	}
 
 	return w, nil
@@ -375,6 +382,7 @@ func createTestWorkflowEmbed(dirPath string) (*Workflows, error) {
 		dest:              dest,
 		configs:           make(map[string]*config.DraftConfig),
 		workflowTemplates: template.Workflows,
+		parentDir:         parentDirName,
 	}
 
 	return w, nil
@@ -389,10 +397,533 @@ func fsToMap(fsFS fs.FS, path string) (map[string]fs.DirEntry, error) {
 	mapping := make(map[string]fs.DirEntry)
 
 	for _, f := range files {
-		if f.IsDir() {
+		if f.IsDir() && !strings.Contains(f.Name(), embedutils.VersionSeparator) {
 			mapping[f.Name()] = f
 		}
 	}
 
 	return mapping, nil
 }
+
+func TestRenderWorkflow(t *testing.T) {
+	cfg := &config.DraftConfig{
+		Variables: []config.BuilderVar{
+			{Name: "AZURECONTAINERREGISTRY", Value: "testAcr"},
+			{Name: "CONTAINERNAME", Value: "testContainer"},
+			{Name: "RESOURCEGROUP", Value: "testRG"},
+			{Name: "CLUSTERNAME", Value: "testCluster"},
+			{Name: "AZUREAPPNAME", Value: "testApp"},
+			{Name: "BRANCHNAME", Value: "testBranch"},
+		},
+	}
+
+	for _, deployType := range []string{"helm", "kustomize", "manifests", "containerapp", "appservice"} {
+		files, err := RenderWorkflow(deployType, cfg)
+		assert.Nil(t, err)
+		assert.NotEmpty(t, files)
+		for filePath, content := range files {
+			assert.True(t, strings.HasPrefix(filePath, ".github/workflows/"), filePath)
+			assert.Contains(t, string(content), "testAcr")
+		}
+	}
+	_, err := RenderWorkflow("invalid", cfg)
+	assert.NotNil(t, err)
+	_, err = RenderWorkflow("helm", &config.DraftConfig{})
+	assert.NotNil(t, err, "missing variables should fail rendering")
+	_, err = RenderWorkflow("helm", nil)
+	assert.NotNil(t, err)
+}
+
+func TestRenderWorkflowKubelogin(t *testing.T) {
+	cfg := &config.DraftConfig{
+		Variables: []config.BuilderVar{
+			{Name: "AZURECONTAINERREGISTRY", Value: "testAcr"},
+			{Name: "CONTAINERNAME", Value: "testContainer"},
+			{Name: "RESOURCEGROUP", Value: "testRG"},
+			{Name: "CLUSTERNAME", Value: "testCluster"},
+			{Name: "BRANCHNAME", Value: "testBranch"},
+		},
+	}
+
+	files, err := RenderWorkflow("manifests", cfg)
+	assert.Nil(t, err)
+	workflow := string(files[".github/workflows/azure-kubernetes-service.yml"])
+	assert.Contains(t, workflow, "KUBELOGIN_ENABLED: true\n")
+	assert.Contains(t, workflow, "KUBELOGIN_VERSION: v0.0.25\n")
+	assert.Contains(t, workflow, "KUBELOGIN_LOGIN_MODE: azurecli\n")
+	assert.Contains(t, workflow, "kubelogin convert-kubeconfig -l ${{ env.KUBELOGIN_LOGIN_MODE }}")
+
+	cfg.Variables = append(cfg.Variables, config.BuilderVar{Name: "KUBELOGINENABLED", Value: "false"}, config.BuilderVar{Name: "KUBELOGINLOGINMODE", Value: "spn"})
+	files, err = RenderWorkflow("manifests", cfg)
+	assert.Nil(t, err)
+	workflow = string(files[".github/workflows/azure-kubernetes-service.yml"])
+	assert.Contains(t, workflow, "KUBELOGIN_ENABLED: false\n")
+	assert.Contains(t, workflow, "KUBELOGIN_LOGIN_MODE: spn\n")
+}
+
+func TestRenderWorkflowRelease(t *testing.T) {
+	cfg := &config.DraftConfig{
+		Variables: []config.BuilderVar{
+			{Name: "AZURECONTAINERREGISTRY", Value: "testAcr"},
+			{Name: "CONTAINERNAME", Value: "testContainer"},
+			{Name: "RESOURCEGROUP", Value: "testRG"},
+			{Name: "CLUSTERNAME", Value: "testCluster"},
+			{Name: "BRANCHNAME", Value: "main"},
+		},
+	}
+
+	files, err := RenderWorkflow("helm", cfg)
+	assert.Nil(t, err)
+	workflow := string(files[".github/workflows/azure-kubernetes-service-helm.yml"])
+	assert.Contains(t, workflow, "if: false && github.ref_name == 'main'\n")
+	assert.Contains(t, workflow, "needs: [deploy]\n")
+	assert.Contains(t, workflow, "RELEASE_TAG_SCHEME: semver\n")
+	assert.Contains(t, workflow, `"$CHART_PATH/Chart.yaml"`)
+
+	cfg.Variables = append(cfg.Variables, config.BuilderVar{Name: "RELEASEENABLED", Value: "true"}, config.BuilderVar{Name: "RELEASETAGSCHEME", Value: "calver"})
+	files, err = RenderWorkflow("manifests", cfg)
+	assert.Nil(t, err)
+	workflow = string(files[".github/workflows/azure-kubernetes-service.yml"])
+	assert.Contains(t, workflow, "if: true && github.ref_name == 'main'\n")
+	assert.Contains(t, workflow, "RELEASE_TAG_SCHEME: calver\n")
+	assert.NotContains(t, workflow, "Chart.yaml")
+}
+
+func TestRenderWorkflowDigestPinning(t *testing.T) {
+	cfg := &config.DraftConfig{
+		Variables: []config.BuilderVar{
+			{Name: "AZURECONTAINERREGISTRY", Value: "testAcr"},
+			{Name: "CONTAINERNAME", Value: "testContainer"},
+			{Name: "RESOURCEGROUP", Value: "testRG"},
+			{Name: "CLUSTERNAME", Value: "testCluster"},
+			{Name: "BRANCHNAME", Value: "main"},
+		},
+	}
+
+	tests := []struct {
+		deployType, workflowPath, pinStep string
+	}{
+		{"helm", ".github/workflows/azure-kubernetes-service-helm.yml", `.image.digest = strenv(IMAGE_DIGEST)' "$CHART_OVERRIDE_PATH"`},
+		{"kustomize", ".github/workflows/azure-kubernetes-service-kustomize.yml", `"digest": strenv(IMAGE_DIGEST)}]' "$KUSTOMIZE_PATH/kustomization.yaml"`},
+		{"manifests", ".github/workflows/azure-kubernetes-service.yml", `find "$DEPLOYMENT_MANIFEST_PATH"`},
+	}
+	for _, tt := range tests {
+		files, err := RenderWorkflow(tt.deployType, cfg)
+		assert.Nil(t, err)
+		workflow := string(files[tt.workflowPath])
+		assert.Contains(t, workflow, "DIGEST_PINNING: false\n")
+		assert.Contains(t, workflow, "az acr repository show --name ${{ env.AZURE_CONTAINER_REGISTRY }} --image ${{ env.CONTAINER_NAME }}:${{ env.IMAGE_TAG }} --query digest -o tsv")
+		assert.Contains(t, workflow, `reference="$repository@$PUSHED_DIGEST"`)
+		assert.Contains(t, workflow, tt.pinStep)
+		assert.Contains(t, workflow, "images: |\n            ${{ env.IMAGE_REFERENCE }}\n")
+	}
+
+	cfg.Variables = append(cfg.Variables, config.BuilderVar{Name: "DIGESTPINNING", Value: "true"})
+	files, err := RenderWorkflow("kustomize", cfg)
+	assert.Nil(t, err)
+	assert.Contains(t, string(files[".github/workflows/azure-kubernetes-service-kustomize.yml"]), "DIGEST_PINNING: true\n")
+}
+
+func TestGitLabPipelines(t *testing.T) {
+	w, err := CreateWorkflowsForProvider(ProviderGitLab, "")
+	assert.Nil(t, err)
+	deployTypes := w.DeployTypes()
+	sort.Strings(deployTypes)
+	assert.Equal(t, []string{"helm", "kustomize", "manifests"}, deployTypes)
+
+	deploySteps := map[string]string{
+		"helm":      `helm upgrade --install "$CONTAINER_NAME" "$CHART_PATH" -f "$CHART_OVERRIDE_PATH" --set image.tag="$IMAGE_TAG"`,
+		"kustomize": `kubectl kustomize "$KUSTOMIZE_PATH" | sed -E`,
+		"manifests": `kubectl apply -f "$DEPLOYMENT_MANIFEST_PATH"`,
+	}
+	for deployType, deployStep := range deploySteps {
+		workflowConfig, err := w.GetConfig(deployType)
+		assert.Nil(t, err)
+		customInputs := map[string]string{
+			"AZURECONTAINERREGISTRY": "testAcr",
+			"CONTAINERNAME":          "testContainer",
+			"RESOURCEGROUP":          "testRG",
+			"CLUSTERNAME":            "testCluster",
+			"BRANCHNAME":             "main",
+		}
+		workflowConfig.ApplyDefaultVariables(customInputs)
+		files, err := w.RenderWorkflowFiles(deployType, customInputs)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{".gitlab-ci.yml"}, maps.Keys(files))
+
+		pipeline := string(files[".gitlab-ci.yml"])
+		assert.NotContains(t, pipeline, "{{")
+		assert.Contains(t, pipeline, `- if: $CI_COMMIT_BRANCH == "main"`)
+		assert.Contains(t, pipeline, "AZURE_CONTAINER_REGISTRY: testAcr\n")
+		assert.Contains(t, pipeline, `az acr build --image "$AZURE_CONTAINER_REGISTRY.azurecr.io/$CONTAINER_NAME:$IMAGE_TAG"`)
+		assert.Contains(t, pipeline, `if [ -n "$KUBECONFIG" ]; then`)
+		assert.Contains(t, pipeline, deployStep)
+
+		var parsed map[string]interface{}
+		assert.Nil(t, yaml.Unmarshal(files[".gitlab-ci.yml"], &parsed))
+		assert.Contains(t, parsed, "build")
+		assert.Contains(t, parsed, "deploy")
+	}
+
+	assert.ErrorContains(t, w.SetChartOverrides([]ChartOverride{{Path: "image.tag", Value: "abc"}}, ChartOverrideFormatSet), "only support the file chart override format")
+	assert.Nil(t, w.SetChartOverrides([]ChartOverride{{Path: "image.tag", Value: "abc"}}, ChartOverrideFormatFile))
+
+	_, err = CreateWorkflowsForProvider("jenkins", "")
+	assert.ErrorContains(t, err, `invalid provider "jenkins"`)
+}
+
+func TestAzurePipelines(t *testing.T) {
+	w, err := CreateWorkflowsForProvider(ProviderAzureDevOps, "")
+	assert.Nil(t, err)
+	deployTypes := w.DeployTypes()
+	sort.Strings(deployTypes)
+	assert.Equal(t, []string{"helm", "kustomize", "manifests"}, deployTypes)
+
+	deploySteps := map[string]string{
+		"helm":      `helm upgrade --install "$CONTAINER_NAME" "$CHART_PATH" -f "$CHART_OVERRIDE_PATH" --set image.tag="$IMAGE_TAG"`,
+		"kustomize": `kubectl kustomize "$KUSTOMIZE_PATH" | sed -E`,
+		"manifests": `kubectl apply -f "$DEPLOYMENT_MANIFEST_PATH"`,
+	}
+	for deployType, deployStep := range deploySteps {
+		workflowConfig, err := w.GetConfig(deployType)
+		assert.Nil(t, err)
+		customInputs := map[string]string{
+			"AZURESERVICECONNECTION": "testConnection",
+			"AZURECONTAINERREGISTRY": "testAcr",
+			"CONTAINERNAME":          "testContainer",
+			"RESOURCEGROUP":          "testRG",
+			"CLUSTERNAME":            "testCluster",
+			"BRANCHNAME":             "main",
+		}
+		workflowConfig.ApplyDefaultVariables(customInputs)
+		files, err := w.RenderWorkflowFiles(deployType, customInputs)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"azure-pipelines.yml"}, maps.Keys(files))
+
+		pipeline := string(files["azure-pipelines.yml"])
+		assert.NotRegexp(t, `\{\{[A-Z]+\}\}`, pipeline)
+		assert.Contains(t, pipeline, "AZURE_SERVICE_CONNECTION: testConnection\n")
+		assert.Contains(t, pipeline, "azureSubscription: ${{ variables.AZURE_SERVICE_CONNECTION }}")
+		assert.Contains(t, pipeline, `az acr build --image "$AZURE_CONTAINER_REGISTRY.azurecr.io/$CONTAINER_NAME:$IMAGE_TAG"`)
+		assert.Contains(t, pipeline, `az aks get-credentials --resource-group "$RESOURCE_GROUP" --name "$CLUSTER_NAME"`)
+		assert.Contains(t, pipeline, deployStep)
+
+		var parsed struct {
+			Trigger struct {
+				Branches struct {
+					Include []string `yaml:"include"`
+				} `yaml:"branches"`
+			} `yaml:"trigger"`
+			Stages []struct {
+				Stage string `yaml:"stage"`
+			} `yaml:"stages"`
+		}
+		assert.Nil(t, yaml.Unmarshal(files["azure-pipelines.yml"], &parsed))
+		assert.Equal(t, []string{"main"}, parsed.Trigger.Branches.Include)
+		assert.Len(t, parsed.Stages, 2)
+		assert.Equal(t, "build", parsed.Stages[0].Stage)
+		assert.Equal(t, "deploy", parsed.Stages[1].Stage)
+	}
+
+	assert.ErrorContains(t, w.SetChartOverrides([]ChartOverride{{Path: "image.tag", Value: "abc"}}, ChartOverrideFormatSet), "only support the file chart override format")
+}
+
+func TestAWSWorkflows(t *testing.T) {
+	w, err := CreateWorkflowsForCloud(ProviderGitHub, CloudAWS, "")
+	assert.Nil(t, err)
+	deployTypes := w.DeployTypes()
+	sort.Strings(deployTypes)
+	assert.Equal(t, []string{"helm", "kustomize", "manifests"}, deployTypes)
+
+	deploySteps := map[string]string{
+		"helm":      "helm upgrade --install ${{ env.CONTAINER_NAME }} ${{ env.CHART_PATH }} -f ${{ env.CHART_OVERRIDE_PATH }} --set image.tag=${{ env.IMAGE_TAG }}",
+		"kustomize": "kubectl kustomize ${{ env.KUSTOMIZE_PATH }} | sed -E",
+		"manifests": "kubectl apply -f ${{ env.DEPLOYMENT_MANIFEST_PATH }}",
+	}
+	for deployType, deployStep := range deploySteps {
+		workflowConfig, err := w.GetConfig(deployType)
+		assert.Nil(t, err)
+		customInputs := map[string]string{
+			"AWSREGION":     "us-east-1",
+			"AWSROLEARN":    "arn:aws:iam::123456789012:role/deploy",
+			"ECRREGISTRY":   "123456789012.dkr.ecr.us-east-1.amazonaws.com",
+			"CONTAINERNAME": "test-container",
+			"CLUSTERNAME":   "test-cluster",
+			"BRANCHNAME":    "main",
+		}
+		workflowConfig.ApplyDefaultVariables(customInputs)
+		files, err := w.RenderWorkflowFiles(deployType, customInputs)
+		assert.Nil(t, err)
+		assert.Len(t, files, 1)
+
+		for _, content := range files {
+			workflow := string(content)
+			assert.NotRegexp(t, `\{\{[A-Z]+\}\}`, workflow)
+			assert.Contains(t, workflow, "AWS_ROLE_ARN: arn:aws:iam::123456789012:role/deploy\n")
+			assert.Contains(t, workflow, "role-to-assume: ${{ env.AWS_ROLE_ARN }}")
+			assert.Contains(t, workflow, "id-token: write")
+			assert.Contains(t, workflow, "uses: aws-actions/amazon-ecr-login@v2")
+			assert.Contains(t, workflow, "aws eks update-kubeconfig --region ${{ env.AWS_REGION }} --name ${{ env.CLUSTER_NAME }}")
+			assert.Contains(t, workflow, deployStep)
+
+			var parsed struct {
+				Jobs map[string]interface{} `yaml:"jobs"`
+			}
+			assert.Nil(t, yaml.Unmarshal(content, &parsed))
+			assert.Contains(t, parsed.Jobs, "buildImage")
+			assert.Contains(t, parsed.Jobs, "deploy")
+		}
+	}
+
+	assert.ErrorContains(t, w.SetChartOverrides([]ChartOverride{{Path: "image.tag", Value: "abc"}}, ChartOverrideFormatSet), "only support the file chart override format")
+	assert.Equal(t, "123456789012.dkr.ecr.us-east-1.amazonaws.com/test-container", productionImage(map[string]string{
+		"ECRREGISTRY":   "123456789012.dkr.ecr.us-east-1.amazonaws.com",
+		"CONTAINERNAME": "test-container",
+	}, ""))
+
+	_, err = CreateWorkflowsForCloud(ProviderGitLab, CloudAWS, "")
+	assert.ErrorContains(t, err, "only github workflows deploy to aws")
+	_, err = CreateWorkflowsForCloud(ProviderGitHub, "digitalocean", "")
+	assert.ErrorContains(t, err, `invalid cloud "digitalocean"`)
+}
+
+func TestGCPWorkflows(t *testing.T) {
+	w, err := CreateWorkflowsForCloud(ProviderGitHub, CloudGCP, "")
+	assert.Nil(t, err)
+	deployTypes := w.DeployTypes()
+	sort.Strings(deployTypes)
+	assert.Equal(t, []string{"helm", "kustomize", "manifests"}, deployTypes)
+
+	deploySteps := map[string]string{
+		"helm":      "helm upgrade --install ${{ env.CONTAINER_NAME }} ${{ env.CHART_PATH }} -f ${{ env.CHART_OVERRIDE_PATH }} --set image.tag=${{ env.IMAGE_TAG }}",
+		"kustomize": "kubectl kustomize ${{ env.KUSTOMIZE_PATH }} | sed -E",
+		"manifests": "kubectl apply -f ${{ env.DEPLOYMENT_MANIFEST_PATH }}",
+	}
+	for deployType, deployStep := range deploySteps {
+		workflowConfig, err := w.GetConfig(deployType)
+		assert.Nil(t, err)
+		customInputs := map[string]string{
+			"GCPPROJECT":       "my-project",
+			"GKELOCATION":      "us-central1",
+			"ARTIFACTREGISTRY": "us-central1-docker.pkg.dev/my-project/images",
+			"CONTAINERNAME":    "test-container",
+			"CLUSTERNAME":      "test-cluster",
+			"BRANCHNAME":       "main",
+		}
+		workflowConfig.ApplyDefaultVariables(customInputs)
+		files, err := w.RenderWorkflowFiles(deployType, customInputs)
+		assert.Nil(t, err)
+		assert.Len(t, files, 1)
+
+		for _, content := range files {
+			workflow := string(content)
+			assert.NotRegexp(t, `\{\{[A-Z]+\}\}`, workflow)
+			assert.Contains(t, workflow, "ARTIFACT_REGISTRY: us-central1-docker.pkg.dev/my-project/images\n")
+			assert.Contains(t, workflow, "workload_identity_provider: ${{ secrets.GCP_WORKLOAD_IDENTITY_PROVIDER }}")
+			assert.Contains(t, workflow, "id-token: write")
+			assert.Contains(t, workflow, "docker push ${{ env.ARTIFACT_REGISTRY }}/${{ env.CONTAINER_NAME }}:${{ env.IMAGE_TAG }}")
+			assert.Contains(t, workflow, "uses: google-github-actions/get-gke-credentials@v2")
+			assert.Contains(t, workflow, deployStep)
+
+			var parsed struct {
+				Jobs map[string]interface{} `yaml:"jobs"`
+			}
+			assert.Nil(t, yaml.Unmarshal(content, &parsed))
+			assert.Contains(t, parsed.Jobs, "buildImage")
+			assert.Contains(t, parsed.Jobs, "deploy")
+		}
+	}
+
+	assert.ErrorContains(t, w.SetChartOverrides([]ChartOverride{{Path: "image.tag", Value: "abc"}}, ChartOverrideFormatSet), "only support the file chart override format")
+	assert.Equal(t, "us-central1-docker.pkg.dev/my-project/images/test-container", productionImage(map[string]string{
+		"ARTIFACTREGISTRY": "us-central1-docker.pkg.dev/my-project/images",
+		"CONTAINERNAME":    "test-container",
+	}, ""))
+
+	_, err = CreateWorkflowsForCloud(ProviderAzureDevOps, CloudGCP, "")
+	assert.ErrorContains(t, err, "only github workflows deploy to gcp")
+}
+
+func TestGenericWorkflows(t *testing.T) {
+	w, err := CreateWorkflowsForProvider(ProviderGeneric, "")
+	assert.Nil(t, err)
+	deployTypes := w.DeployTypes()
+	sort.Strings(deployTypes)
+	assert.Equal(t, []string{"helm", "kustomize", "manifests"}, deployTypes)
+
+	deploySteps := map[string]string{
+		"helm":      "helm upgrade --install ${{ env.CONTAINER_NAME }} ${{ env.CHART_PATH }} -f ${{ env.CHART_OVERRIDE_PATH }} --set image.tag=${{ env.IMAGE_TAG }}",
+		"kustomize": "kubectl kustomize ${{ env.KUSTOMIZE_PATH }} | sed -E",
+		"manifests": "kubectl apply -f ${{ env.DEPLOYMENT_MANIFEST_PATH }}",
+	}
+	for deployType, deployStep := range deploySteps {
+		workflowConfig, err := w.GetConfig(deployType)
+		assert.Nil(t, err)
+		customInputs := map[string]string{
+			"CONTAINERREGISTRY": "registry.example.com:5000/team",
+			"CONTAINERNAME":     "test-container",
+			"BRANCHNAME":        "main",
+		}
+		workflowConfig.ApplyDefaultVariables(customInputs)
+		files, err := w.RenderWorkflowFiles(deployType, customInputs)
+		assert.Nil(t, err)
+		assert.Len(t, files, 1)
+
+		for _, content := range files {
+			workflow := string(content)
+			assert.NotRegexp(t, `\{\{[A-Z]+\}\}`, workflow)
+			assert.Contains(t, workflow, "CONTAINER_REGISTRY: registry.example.com:5000/team\n")
+			assert.Contains(t, workflow, "uses: docker/setup-buildx-action@v3")
+			assert.Contains(t, workflow, "password: ${{ secrets.REGISTRY_PASSWORD }}")
+			assert.Contains(t, workflow, "tags: ${{ env.CONTAINER_REGISTRY }}/${{ env.CONTAINER_NAME }}:${{ env.IMAGE_TAG }}")
+			assert.Contains(t, workflow, "KUBECONFIG_CONTENT: ${{ secrets.KUBECONFIG }}")
+			assert.NotContains(t, workflow, "id-token: write")
+			assert.Contains(t, workflow, deployStep)
+
+			var parsed struct {
+				Jobs map[string]interface{} `yaml:"jobs"`
+			}
+			assert.Nil(t, yaml.Unmarshal(content, &parsed))
+			assert.Contains(t, parsed.Jobs, "buildImage")
+			assert.Contains(t, parsed.Jobs, "deploy")
+		}
+	}
+
+	assert.ErrorContains(t, w.SetChartOverrides([]ChartOverride{{Path: "image.tag", Value: "abc"}}, ChartOverrideFormatSet), "only support the file chart override format")
+	assert.Equal(t, "registry.example.com:5000/team/test-container", productionImage(map[string]string{
+		"CONTAINERREGISTRY": "registry.example.com:5000/team",
+		"CONTAINERNAME":     "test-container",
+	}, ""))
+	assert.Equal(t, map[string]string{"CONTAINERREGISTRY": "ghcr.io/octo"}, (&WorkflowConfig{Provider: ProviderGeneric, Cloud: CloudAzure, AcrName: "ghcr.io/octo"}).SetFlagValuesToMap())
+
+	_, err = CreateWorkflowsForCloud(ProviderGeneric, CloudAWS, "")
+	assert.ErrorContains(t, err, "only github workflows deploy to aws")
+}
+
+func TestGitHubVariables(t *testing.T) {
+	w, err := CreateWorkflowsForProvider(ProviderGitHub, "")
+	assert.Nil(t, err)
+	assert.Nil(t, w.SetGitHubVariables("production"))
+	customInputs := map[string]string{
+		"AZURECONTAINERREGISTRY": "testAcr",
+		"CONTAINERNAME":          "test-container",
+		"RESOURCEGROUP":          "test-rg",
+		"CLUSTERNAME":            "it's-a-cluster",
+		"BRANCHNAME":             "main",
+	}
+	files, err := w.RenderWorkflowFiles("helm", customInputs)
+	assert.Nil(t, err)
+
+	workflow := string(files[".github/workflows/azure-kubernetes-service-helm.yml"])
+	assert.Contains(t, workflow, "CLUSTER_NAME: ${{ vars.CLUSTER_NAME }}\n")
+	assert.Contains(t, workflow, "AZURE_CONTAINER_REGISTRY: ${{ vars.AZURE_CONTAINER_REGISTRY }}\n")
+	assert.NotContains(t, workflow, "test-rg")
+	assert.Contains(t, workflow, "  buildImage:\n    environment: production\n")
+	assert.Contains(t, workflow, "  deploy:\n    environment: production\n")
+	var parsed struct {
+		Jobs map[string]struct {
+			Environment string `yaml:"environment"`
+		} `yaml:"jobs"`
+	}
+	assert.Nil(t, yaml.Unmarshal([]byte(workflow), &parsed))
+	assert.Equal(t, "production", parsed.Jobs["deploy"].Environment)
+
+	script := string(files[GitHubVariablesScriptPath])
+	assert.Contains(t, script, "gh variable set CLUSTER_NAME --body 'it'\\''s-a-cluster' --env 'production'\n")
+	assert.Contains(t, script, "gh variable set RESOURCE_GROUP --body 'test-rg' --env 'production'\n")
+	assert.NotContains(t, script, "BRANCH")
+
+	assert.Equal(t, "#!/bin/sh\n", string(GitHubVariablesScript(map[string]string{"A": "b"}, ""))[:len("#!/bin/sh\n")])
+	assert.Contains(t, string(GitHubVariablesScript(map[string]string{"A": "b"}, "")), "gh variable set A --body 'b'\n")
+	assert.NotNil(t, w.SetGitHubVariables("prod env"))
+
+	gitlab, err := CreateWorkflowsForProvider(ProviderGitLab, "")
+	assert.Nil(t, err)
+	assert.ErrorContains(t, gitlab.SetGitHubVariables(""), "only read by github workflows")
+}
+
+func TestImageTagStrategy(t *testing.T) {
+	branchSHA, err := imagetag.Get(imagetag.BranchSHA)
+	assert.Nil(t, err)
+	w, err := CreateWorkflowsForProvider(ProviderGitHub, "")
+	assert.Nil(t, err)
+	for _, deployType := range []string{"helm", "kustomize", "manifests", "containerapp", "appservice"} {
+		customInputs := map[string]string{
+			"AZURECONTAINERREGISTRY": "testAcr",
+			"CONTAINERNAME":          "testContainer",
+			"RESOURCEGROUP":          "testRG",
+			"CLUSTERNAME":            "testCluster",
+			"AZUREAPPNAME":           "testApp",
+			"BRANCHNAME":             "main",
+			ImageTagStrategyVariable: imagetag.BranchSHA,
+		}
+		files, err := w.RenderWorkflowFiles(deployType, customInputs)
+		assert.Nil(t, err)
+		for name, content := range files {
+			workflow := string(content)
+			assert.NotRegexp(t, `\{\{[A-Z]+\}\}`, workflow, name)
+			assert.NotContains(t, workflow, ":${{ github.sha }}", name)
+			assert.Contains(t, workflow, "IMAGE_TAG_BRANCH: ${{ github.ref_name }}", name)
+			assert.Contains(t, workflow, `echo "IMAGE_TAG=$(`+branchSHA.Script()+`)" >> "$GITHUB_ENV"`, name)
+			assert.Contains(t, workflow, ":${{ env.IMAGE_TAG }}", name)
+		}
+	}
+
+	_, err = w.RenderWorkflowFiles("helm", map[string]string{ImageTagStrategyVariable: "build-number"})
+	assert.ErrorContains(t, err, `invalid image tag strategy "build-number"`)
+
+	tag, err := productionImageTag(map[string]string{}, t.TempDir())
+	assert.Nil(t, err)
+	assert.Equal(t, imagetag.FallbackTag, tag)
+	_, err = productionImageTag(map[string]string{ImageTagStrategyVariable: "build-number"}, t.TempDir())
+	assert.NotNil(t, err)
+}
+
+func TestRenderWorkflowNotifications(t *testing.T) {
+	cfg := &config.DraftConfig{
+		Variables: []config.BuilderVar{
+			{Name: "AZURECONTAINERREGISTRY", Value: "testAcr"},
+			{Name: "CONTAINERNAME", Value: "testContainer"},
+			{Name: "RESOURCEGROUP", Value: "testRG"},
+			{Name: "CLUSTERNAME", Value: "testCluster"},
+			{Name: "BRANCHNAME", Value: "main"},
+			{Name: "AZUREAPPNAME", Value: "testApp"},
+		},
+	}
+
+	files, err := RenderWorkflow("manifests", cfg)
+	assert.Nil(t, err)
+	workflow := string(files[".github/workflows/azure-kubernetes-service.yml"])
+	assert.Contains(t, workflow, "if: always() && (false || false)\n")
+	assert.Contains(t, workflow, "WEBHOOK_URL: ${{ secrets.SLACK_WEBHOOK_URL }}\n")
+	assert.Contains(t, workflow, "WEBHOOK_URL: ${{ secrets.TEAMS_WEBHOOK_URL }}\n")
+
+	cfg.Variables = append(cfg.Variables,
+		config.BuilderVar{Name: "NOTIFYSLACK", Value: "true"},
+		config.BuilderVar{Name: "NOTIFYTEAMSSECRET", Value: "DEPLOY_TEAMS_WEBHOOK"},
+	)
+	tests := []struct {
+		deployType, workflowPath string
+	}{
+		{"helm", ".github/workflows/azure-kubernetes-service-helm.yml"},
+		{"kustomize", ".github/workflows/azure-kubernetes-service-kustomize.yml"},
+		{"manifests", ".github/workflows/azure-kubernetes-service.yml"},
+		{"containerapp", ".github/workflows/azure-container-apps.yml"},
+		{"appservice", ".github/workflows/azure-app-service.yml"},
+	}
+	for _, tt := range tests {
+		files, err := RenderWorkflow(tt.deployType, cfg)
+		assert.Nil(t, err)
+		workflow := string(files[tt.workflowPath])
+		assert.Contains(t, workflow, "if: always() && (true || false)\n")
+		assert.Contains(t, workflow, "RESULT: ${{ needs.deploy.result }}\n")
+		assert.Contains(t, workflow, "WEBHOOK_URL: ${{ secrets.DEPLOY_TEAMS_WEBHOOK }}\n")
+		assert.Contains(t, workflow, `"application/vnd.microsoft.card.adaptive"`)
+	}
+}
+
+func TestIsProductionDeployment(t *testing.T) {
+	assert.True(t, IsProductionDeployment("./langtest/", "./langtest//charts/production.yaml"))
+	assert.True(t, IsProductionDeployment(".", "manifests/deployment.yaml"))
+	assert.False(t, IsProductionDeployment(".", "charts/values.yaml"))
+}
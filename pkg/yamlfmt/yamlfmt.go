@@ -0,0 +1,115 @@
+// Package yamlfmt normalizes the formatting of generated yaml files, so they pass strict parsers and linters such as
+// yamllint whatever the layout of the templates they were rendered from.
+package yamlfmt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// Indent is the number of spaces each nested mapping and sequence is indented by
+const Indent = 2
+
+// Normalize re-emits the yaml file path with Indent spaces of indentation, block style collections, double quoted
+// strings and no trailing whitespace. Comments are kept. It is a PostRenderWriter mutation: files that aren't yaml,
+// the templates of helm charts, and files that don't parse as yaml are returned unchanged.
+func Normalize(filePath string, content []byte) ([]byte, error) {
+	if !IsYAML(filePath) || isChartTemplate(filePath) {
+		return content, nil
+	}
+
+	var documents []*yaml.Node
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		document := &yaml.Node{}
+		if err := decoder.Decode(document); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			log.Debugf("not normalizing %s, it isn't plain yaml: %s", filePath, err)
+			return content, nil
+		}
+		resetStyle(document)
+		documents = append(documents, document)
+	}
+	if len(documents) == 0 {
+		return content, nil
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(Indent)
+	for _, document := range documents {
+		if err := encoder.Encode(document); err != nil {
+			return nil, fmt.Errorf("normalizing %s: %w", filePath, err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("normalizing %s: %w", filePath, err)
+	}
+	return trimTrailingWhitespace(out.Bytes()), nil
+}
+
+// IsYAML reports whether filePath is a yaml file
+func IsYAML(filePath string) bool {
+	ext := strings.ToLower(path.Ext(filePath))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// isChartTemplate reports whether filePath is in the templates directory of a helm chart, whose files are go
+// templates helm renders rather than yaml
+func isChartTemplate(filePath string) bool {
+	for _, dir := range strings.Split(path.Dir(strings.ReplaceAll(filePath, "\\", "/")), "/") {
+		if dir == "templates" {
+			return true
+		}
+	}
+	return false
+}
+
+// resetStyle sets the block style of the collections of node and its children, and the double quotes of their quoted
+// scalars. Plain scalars stay plain, the encoder quoting those that would otherwise be read as another type, and
+// quoted values stay quoted, as a yaml 1.1 parser could read them as another type, such as the boolean yes. The
+// quotes of mapping keys, such as those of json objects, are dropped when they're not needed.
+func resetStyle(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Style&yaml.SingleQuotedStyle != 0 {
+			node.Style = node.Style&^yaml.SingleQuotedStyle | yaml.DoubleQuotedStyle
+		}
+		node.Style &^= yaml.FlowStyle
+	case yaml.MappingNode:
+		node.Style &^= yaml.FlowStyle
+		for i := 0; i < len(node.Content); i += 2 {
+			if key := node.Content[i]; key.Kind == yaml.ScalarNode && !yaml11Bools[key.Value] {
+				key.Style &^= yaml.SingleQuotedStyle | yaml.DoubleQuotedStyle
+			}
+		}
+	case yaml.SequenceNode:
+		node.Style &^= yaml.FlowStyle
+	}
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+// yaml11Bools are the strings yaml 1.1 parsers read as booleans
+var yaml11Bools = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true, "n": true, "N": true, "no": true, "No": true, "NO": true,
+	"on": true, "On": true, "ON": true, "off": true, "Off": true, "OFF": true,
+}
+
+func trimTrailingWhitespace(content []byte) []byte {
+	lines := bytes.Split(content, []byte("\n"))
+	for i, line := range lines {
+		lines[i] = bytes.TrimRight(line, " \t")
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
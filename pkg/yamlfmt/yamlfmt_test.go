@@ -0,0 +1,80 @@
+package yamlfmt
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// TestNormalizeGolden normalizes each yaml file of testdata and compares it to its .golden file
+func TestNormalizeGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "*.y*ml"))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, inputs)
+	for _, input := range inputs {
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			content, err := os.ReadFile(input)
+			assert.Nil(t, err)
+			normalized, err := Normalize(input, content)
+			assert.Nil(t, err)
+
+			goldenPath := input + ".golden"
+			if *update {
+				assert.Nil(t, os.WriteFile(goldenPath, normalized, 0644))
+				return
+			}
+			golden, err := os.ReadFile(goldenPath)
+			assert.Nil(t, err)
+			assert.Equal(t, string(golden), string(normalized), "rerun with -update after changing the normalization on purpose")
+
+			// the documents hold the same data, and normalizing again changes nothing
+			assert.Equal(t, decodeAll(t, content), decodeAll(t, normalized))
+			again, err := Normalize(input, normalized)
+			assert.Nil(t, err)
+			assert.Equal(t, string(normalized), string(again))
+			for _, line := range strings.Split(string(normalized), "\n") {
+				assert.Equal(t, strings.TrimRight(line, " \t"), line)
+			}
+		})
+	}
+}
+
+func TestNormalizeUnchanged(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+	}{
+		{name: "not yaml", path: "Dockerfile", content: "FROM  golang  \n"},
+		{name: "chart template", path: "charts/templates/service.yaml", content: "metadata:\n    name: {{ include \"myapp.fullname\" . }}\n"},
+		{name: "invalid yaml", path: "broken.yaml", content: "key: [unclosed\n"},
+		{name: "empty", path: "empty.yaml", content: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, err := Normalize(tt.path, []byte(tt.content))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.content, string(normalized))
+		})
+	}
+}
+
+func decodeAll(t *testing.T, content []byte) []interface{} {
+	var documents []interface{}
+	decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+	for {
+		var document interface{}
+		if err := decoder.Decode(&document); err != nil {
+			break
+		}
+		documents = append(documents, document)
+	}
+	return documents
+}
@@ -0,0 +1,8 @@
+package template
+
+import "embed"
+
+var (
+	//go:embed all:aws
+	AWSWorkflows embed.FS
+)
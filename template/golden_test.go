@@ -0,0 +1,74 @@
+package template_test
+
+import (
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Azure/draft/pkg/scaffold"
+	"github.com/Azure/draft/template"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the packs in testdata/golden")
+
+var goldenDir = filepath.Join("testdata", "golden")
+
+// TestGoldenPacks renders each pack that has golden files with its defaults and compares the output to them
+func TestGoldenPacks(t *testing.T) {
+	tests := []struct {
+		dir    string
+		fsys   fs.FS
+		render func(fs.FS, string) (map[string][]byte, error)
+	}{
+		{dir: scaffold.LanguagesDir, fsys: template.Dockerfiles, render: scaffold.RenderLanguageGolden},
+		{dir: scaffold.DeploymentsDir, fsys: template.Deployments, render: scaffold.RenderDeploymentGolden},
+	}
+	for _, tt := range tests {
+		packs, err := os.ReadDir(filepath.Join(goldenDir, tt.dir))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		assert.Nil(t, err)
+		for _, pack := range packs {
+			t.Run(tt.dir+"/"+pack.Name(), func(t *testing.T) {
+				packGoldenDir := filepath.Join(goldenDir, tt.dir, pack.Name())
+				rendered, err := tt.render(tt.fsys, pack.Name())
+				assert.Nil(t, err)
+				if *update {
+					assert.Nil(t, os.RemoveAll(packGoldenDir))
+					for name, content := range rendered {
+						goldenPath := filepath.Join(packGoldenDir, name)
+						assert.Nil(t, os.MkdirAll(filepath.Dir(goldenPath), 0755))
+						assert.Nil(t, os.WriteFile(goldenPath, content, 0644))
+					}
+					return
+				}
+
+				golden := make(map[string]string)
+				err = filepath.WalkDir(packGoldenDir, func(p string, d fs.DirEntry, err error) error {
+					if err != nil || d.IsDir() {
+						return err
+					}
+					content, err := os.ReadFile(p)
+					if err != nil {
+						return err
+					}
+					rel, err := filepath.Rel(packGoldenDir, p)
+					golden[filepath.ToSlash(rel)] = string(content)
+					return err
+				})
+				assert.Nil(t, err)
+				got := make(map[string]string, len(rendered))
+				for name, content := range rendered {
+					got[filepath.ToSlash(name)] = string(content)
+				}
+				assert.Equal(t, golden, got, "rerun with -update after changing the templates on purpose")
+			})
+		}
+	}
+}
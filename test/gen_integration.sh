@@ -242,7 +242,7 @@ languageVariables:
           repository: $repo
           path: ./langtest
       - run: rm -rf ./langtest/manifests && rm -f ./langtest/Dockerfile ./langtest/.dockerignore
-      - run: ./draft -v create -c ./test/integration/$lang/helm.yaml -d ./langtest/
+      - run: ./draft -v create -c ./test/integration/$lang/helm.yaml -d ./langtest/ --yes
       - name: start minikube
         id: minikube
         uses: medyagh/setup-minikube@master
@@ -399,7 +399,7 @@ languageVariables:
           repository: $repo
           path: ./langtest
       - run: rm -rf ./langtest/manifests && rm -f ./langtest/Dockerfile ./langtest/.dockerignore
-      - run: ./draft -v create -c ./test/integration/$lang/kustomize.yaml -d ./langtest/
+      - run: ./draft -v create -c ./test/integration/$lang/kustomize.yaml -d ./langtest/ --yes
       - name: start minikube
         id: minikube
         uses: medyagh/setup-minikube@master
@@ -547,7 +547,7 @@ languageVariables:
           repository: $repo
           path: ./langtest
       - run: rm -rf ./langtest/manifests && rm -f ./langtest/Dockerfile ./langtest/.dockerignore
-      - run: ./draft -v create -c ./test/integration/$lang/manifest.yaml -d ./langtest/
+      - run: ./draft -v create -c ./test/integration/$lang/manifest.yaml -d ./langtest/ --yes
       - name: print manifests
         run: cat ./langtest/manifests/*
       - name: Add docker.local host to /etc/hosts
@@ -683,7 +683,7 @@ languageVariables:
       - run: Remove-Item ./langtest/manifests -Recurse -Force -ErrorAction Ignore
       - run: Remove-Item ./langtest/Dockerfile -ErrorAction Ignore
       - run: Remove-Item ./langtest/.dockerignore -ErrorAction Ignore
-      - run: ./draft.exe -v create -c ./test/integration/$lang/helm.yaml -d ./langtest/
+      - run: ./draft.exe -v create -c ./test/integration/$lang/helm.yaml -d ./langtest/ --yes
       - uses: actions/download-artifact@v3
         with:
           name: check_windows_helm
@@ -735,7 +735,7 @@ languageVariables:
       - run: Remove-Item ./langtest/manifests -Recurse -Force -ErrorAction Ignore
       - run: Remove-Item ./langtest/Dockerfile -ErrorAction Ignore
       - run: Remove-Item ./langtest/.dockerignore -ErrorAction Ignore
-      - run: ./draft.exe -v create -c ./test/integration/$lang/kustomize.yaml -d ./langtest/
+      - run: ./draft.exe -v create -c ./test/integration/$lang/kustomize.yaml -d ./langtest/ --yes
       - uses: actions/download-artifact@v3
         with:
           name: check_windows_kustomize